@@ -2,12 +2,25 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"time"
 
 	"github.com/kodflow/superviz.io/internal/cli"
+	"github.com/kodflow/superviz.io/internal/cli/commands/build"
+	"github.com/kodflow/superviz.io/internal/cli/commands/ctl"
+	"github.com/kodflow/superviz.io/internal/cli/commands/graph"
 	"github.com/kodflow/superviz.io/internal/cli/commands/install"
+	"github.com/kodflow/superviz.io/internal/cli/commands/preflight"
+	runcmd "github.com/kodflow/superviz.io/internal/cli/commands/run"
+	"github.com/kodflow/superviz.io/internal/cli/commands/selfupdate"
+	"github.com/kodflow/superviz.io/internal/cli/commands/status"
+	"github.com/kodflow/superviz.io/internal/cli/commands/upgrade"
+	"github.com/kodflow/superviz.io/internal/cli/commands/verify"
 	"github.com/kodflow/superviz.io/internal/cli/commands/version"
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing"
 	"github.com/spf13/cobra"
 )
 
@@ -31,15 +44,66 @@ func run(cmd *cobra.Command, args []string) int {
 	return 0
 }
 
+// tracingShutdownTimeout bounds the export of the last spans when the CLI exits
+const tracingShutdownTimeout = 5 * time.Second
+
+// setupTracing installs the default tracer when the OpenTelemetry environment
+// variables configure an OTLP endpoint.
+//
+// An invalid configuration is reported to stderr and leaves tracing disabled
+// rather than failing the command.
+//
+// Parameters:
+//   - getenv: func(string) string environment lookup such as os.Getenv
+//   - stderr: io.Writer receiving configuration and export errors
+//
+// Returns:
+//   - shutdown: func() exporting the remaining spans, to call before exiting
+func setupTracing(getenv func(string) string, stderr io.Writer) (shutdown func()) {
+	tracer, err := tracing.FromEnv(getenv, func(err error) {
+		fmt.Fprintln(stderr, "Warning: trace export:", err)
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, "Warning: tracing disabled:", err)
+		return func() {}
+	}
+	if tracer == nil {
+		return func() {}
+	}
+
+	tracing.SetDefault(tracer)
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := tracer.Shutdown(ctx); err != nil {
+			fmt.Fprintln(stderr, "Warning:", err)
+		}
+		tracing.SetDefault(nil)
+	}
+}
+
 // main is the CLI entry point for the superviz.io application.
 //
 // main initializes the root command with all available subcommands
-// and executes the CLI with the provided command-line arguments.
+// and executes the CLI with the provided command-line arguments, tracing
+// the operations when OTEL_EXPORTER_OTLP_ENDPOINT is set.
 func main() {
 	rootCmd := cli.NewRootCommand(
 		version.GetCommand(),
 		install.GetCommand(),
+		preflight.GetCommand(),
+		status.GetCommand(),
+		upgrade.GetCommand(),
+		selfupdate.GetCommand(),
+		runcmd.GetCommand(),
+		build.GetCommand(),
+		graph.GetCommand(),
+		ctl.GetCommand(),
+		verify.GetCommand(),
 	)
 
-	os.Exit(run(rootCmd, os.Args[1:]))
+	shutdownTracing := setupTracing(os.Getenv, os.Stderr)
+	code := run(rootCmd, os.Args[1:])
+	shutdownTracing()
+	os.Exit(code)
 }
@@ -2,12 +2,18 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
+	"sync/atomic"
 	"testing"
 
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing"
+
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -41,6 +47,36 @@ func TestRun_KO(t *testing.T) {
 	assert.Equal(t, 1, exit)
 }
 
+func TestSetupTracing(t *testing.T) {
+	var stderr bytes.Buffer
+	setupTracing(func(string) string { return "" }, &stderr)()
+	assert.Nil(t, tracing.Default())
+	assert.Empty(t, stderr.String())
+
+	setupTracing(func(key string) string {
+		return map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318", "OTEL_EXPORTER_OTLP_PROTOCOL": "grpc"}[key]
+	}, &stderr)()
+	assert.Nil(t, tracing.Default())
+	assert.Contains(t, stderr.String(), "Warning: tracing disabled: unsupported OTLP protocol")
+
+	var exported atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			exported.Add(1)
+		}
+	}))
+	defer collector.Close()
+	shutdown := setupTracing(func(key string) string {
+		return map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": collector.URL}[key]
+	}, &stderr)
+	require.NotNil(t, tracing.Default())
+	_, span := tracing.Start(context.Background(), "test")
+	span.End(nil)
+	shutdown()
+	assert.Nil(t, tracing.Default())
+	assert.Equal(t, int32(1), exported.Load(), "the spans are exported before exiting")
+}
+
 func TestMain(t *testing.T) {
 	if os.Getenv("TEST_MAIN") == "1" {
 		main() // Appelle os.Exit(0)
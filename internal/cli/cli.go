@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"github.com/kodflow/superviz.io/internal/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -8,6 +9,9 @@ import (
 //
 // NewRootCommand initializes the main CLI command with common configuration
 // and attaches all provided subcommands to create a complete command tree.
+// Before any subcommand runs, flags left unset on the command line are filled
+// from /etc/superviz/cli.yaml, ~/.config/superviz/cli.yaml, the selected
+// --profile and SVZ_* environment variables, in increasing precedence.
 //
 // Example:
 //
@@ -28,8 +32,19 @@ func NewRootCommand(subcommands ...*cobra.Command) *cobra.Command {
 		DisableAutoGenTag:     true,
 		DisableFlagsInUseLine: true,
 		SilenceErrors:         true,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if err := applyCLIConfig(cmd); err != nil {
+				return err
+			}
+			_, err := utils.OutputFormatFromCommand(cmd)
+			return err
+		},
 	}
 
+	// Global output format shared by all subcommands
+	cmd.PersistentFlags().StringP(utils.OutputFlag, "o", string(utils.OutputText), "Output format: text, json or yaml")
+	cmd.PersistentFlags().String(ProfileFlag, "", "Configuration profile to apply (also SVZ_PROFILE)")
+
 	// Hide default help command
 	cmd.SetHelpCommand(&cobra.Command{Hidden: true})
 
@@ -2,18 +2,23 @@ package cli_test
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/kodflow/superviz.io/internal/cli"
 	"github.com/kodflow/superviz.io/internal/cli/commands/install"
 	"github.com/kodflow/superviz.io/internal/cli/commands/version"
+	"github.com/kodflow/superviz.io/internal/services"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func newTestRootCommand(buf *bytes.Buffer, args ...string) *cobra.Command {
-	cmd := cli.NewRootCommand(version.GetCommand())
+	// A fresh version command keeps inherited flag state from leaking between tests
+	cmd := cli.NewRootCommand(version.NewVersionCommand(services.NewVersionService(nil)))
 	cmd.SetOut(buf)
 	cmd.SetErr(buf)
 	cmd.SetArgs(args)
@@ -85,6 +90,28 @@ func TestVersionCommandOutput(t *testing.T) {
 	assert.Contains(t, output, "Go version:")
 }
 
+func TestVersionCommandJSONOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	cmd := newTestRootCommand(buf, "--output", "json", "version")
+
+	err := cmd.Execute()
+	assert.NoError(t, err)
+
+	output := buf.String()
+	assert.True(t, strings.HasPrefix(output, "{"))
+	assert.Contains(t, output, `"version":`)
+	assert.Contains(t, output, `"go_version":`)
+}
+
+func TestInvalidOutputFormatReturnsError(t *testing.T) {
+	buf := &bytes.Buffer{}
+	cmd := newTestRootCommand(buf, "-o", "xml", "version")
+
+	err := cmd.Execute()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid output format")
+}
+
 func TestUnknownCommandReturnsError(t *testing.T) {
 	buf := &bytes.Buffer{}
 	cmd := newTestRootCommand(buf, "doesnotexist")
@@ -94,3 +121,150 @@ func TestUnknownCommandReturnsError(t *testing.T) {
 	output := buf.String()
 	assert.Contains(t, output, "")
 }
+
+// withUserConfig points the per-user configuration file at a temporary file.
+func withUserConfig(t *testing.T, content string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "superviz"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "superviz", "cli.yaml"), []byte(content), 0o600))
+}
+
+// newPortCommand returns a subcommand recording its --ssh-port value.
+func newPortCommand(port *int) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "connect",
+		RunE: func(*cobra.Command, []string) error { return nil },
+	}
+	cmd.Flags().IntVarP(port, "ssh-port", "p", 22, "SSH port")
+	return cmd
+}
+
+func TestConfigFileSetsGlobalFlags(t *testing.T) {
+	withUserConfig(t, "output: json\n")
+
+	buf := &bytes.Buffer{}
+	cmd := newTestRootCommand(buf, "version")
+
+	require.NoError(t, cmd.Execute())
+	assert.True(t, strings.HasPrefix(buf.String(), "{"))
+}
+
+func TestEnvironmentOverridesConfigFile(t *testing.T) {
+	withUserConfig(t, "output: json\n")
+	t.Setenv("SVZ_OUTPUT", "yaml")
+
+	buf := &bytes.Buffer{}
+	cmd := newTestRootCommand(buf, "version")
+
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "version: ")
+}
+
+func TestFlagOverridesConfiguration(t *testing.T) {
+	withUserConfig(t, "ssh-port: 2200\nprofiles:\n  staging:\n    ssh-port: 2222\n")
+
+	tests := []struct {
+		name string
+		env  string
+		args []string
+		want int
+	}{
+		{name: "file", args: []string{"connect"}, want: 2200},
+		{name: "profile flag", args: []string{"connect", "--profile", "staging"}, want: 2222},
+		{name: "profile env", env: "staging", args: []string{"connect"}, want: 2222},
+		{name: "explicit flag", args: []string{"connect", "--profile", "staging", "-p", "22"}, want: 22},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SVZ_PROFILE", tt.env)
+
+			var port int
+			cmd := cli.NewRootCommand(newPortCommand(&port))
+			cmd.SetArgs(tt.args)
+
+			require.NoError(t, cmd.Execute())
+			assert.Equal(t, tt.want, port)
+		})
+	}
+}
+
+func TestUnknownProfileReturnsError(t *testing.T) {
+	withUserConfig(t, "profiles:\n  staging: {}\n")
+
+	buf := &bytes.Buffer{}
+	cmd := newTestRootCommand(buf, "--profile", "prod", "version")
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, `unknown profile "prod", available profiles: staging`)
+}
+
+func TestMalformedConfigIsIgnored(t *testing.T) {
+	withUserConfig(t, "output: [json\n")
+
+	var stdout, stderr bytes.Buffer
+	cmd := cli.NewRootCommand(version.NewVersionCommand(services.NewVersionService(nil)))
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"version"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, stdout.String(), "Version:")
+	assert.Contains(t, stderr.String(), "warning: ignoring CLI configuration: ")
+}
+
+func TestMalformedConfigKeepsEnvironment(t *testing.T) {
+	withUserConfig(t, "ssh-port: [\n")
+	t.Setenv("SVZ_SSH_PORT", "2200")
+
+	var port int
+	var stderr bytes.Buffer
+	cmd := cli.NewRootCommand(newPortCommand(&port))
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"connect"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, 2200, port)
+	assert.Contains(t, stderr.String(), "warning: ignoring CLI configuration: ")
+}
+
+func TestMalformedConfigWithExplicitProfileReturnsError(t *testing.T) {
+	withUserConfig(t, "profiles:\n  staging: [\n")
+
+	tests := []struct {
+		name string
+		env  string
+		args []string
+	}{
+		{name: "profile flag", args: []string{"connect", "--profile", "staging"}},
+		{name: "profile env", env: "staging", args: []string{"connect"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SVZ_PROFILE", tt.env)
+
+			var port int
+			cmd := cli.NewRootCommand(newPortCommand(&port))
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `cannot apply profile "staging": failed to parse `)
+		})
+	}
+}
+
+func TestInvalidConfiguredValueReturnsError(t *testing.T) {
+	withUserConfig(t, "ssh-port: abc\n")
+
+	var port int
+	cmd := cli.NewRootCommand(newPortCommand(&port))
+	cmd.SetArgs([]string{"connect"})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid value "abc" for --ssh-port from `)
+}
@@ -0,0 +1,106 @@
+// Package build provides CLI command functionality for provisioning a superviz.yaml file ahead of runtime
+package build
+
+import (
+	"sync"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/kodflow/superviz.io/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// defaultService holds the singleton build service instance
+	defaultService *services.BuildService
+	// defaultCmd holds the singleton build command instance
+	defaultCmd *cobra.Command
+	// once ensures the default instances are initialized only once
+	once sync.Once
+)
+
+// initDefaults initializes the default service and command instances once.
+//
+// initDefaults creates the singleton instances of the build service and
+// command, ensuring they are created only once for the lifetime of the application.
+func initDefaults() {
+	defaultService = services.NewBuildService(nil)
+	defaultCmd = createBuildCommand(defaultService)
+}
+
+// GetCommand returns the singleton Cobra command for pre-runtime provisioning.
+//
+// GetCommand provides access to the default build command instance, initializing
+// it if necessary using sync.Once for thread safety.
+//
+// Returns:
+//   - Cobra command instance configured for pre-runtime provisioning
+func GetCommand() *cobra.Command {
+	once.Do(initDefaults)
+	return defaultCmd
+}
+
+// GetCommandWithService returns a Cobra command with a custom build service.
+//
+// GetCommandWithService allows injection of a custom build service while
+// falling back to the singleton command if service is nil.
+//
+// Parameters:
+//   - service: Custom build service instance (nil for default)
+//
+// Returns:
+//   - Cobra command instance with the specified or default service
+func GetCommandWithService(service *services.BuildService) *cobra.Command {
+	if service == nil {
+		return GetCommand()
+	}
+	return NewBuildCommand(service)
+}
+
+// NewBuildCommand creates a new build command with the given service.
+//
+// NewBuildCommand constructs a fresh build command instance with the
+// provided service, bypassing the singleton pattern for testing or special cases.
+//
+// Parameters:
+//   - service: Build service instance to use for the command
+//
+// Returns:
+//   - New Cobra command instance configured with the provided service
+func NewBuildCommand(service *services.BuildService) *cobra.Command {
+	return createBuildCommand(service)
+}
+
+// createBuildCommand creates the cobra command with all flags and validation.
+//
+// Parameters:
+//   - service: Build service instance provisioning the configuration
+//
+// Returns:
+//   - Configured Cobra command ready for execution
+func createBuildCommand(service *services.BuildService) *cobra.Command {
+	var configPath string
+
+	cmd := &cobra.Command{
+		Use:   "build [flags]",
+		Short: "Provision a superviz.yaml file without starting its services",
+		Long: "Run the provisioning phase of a superviz.yaml file and exit: download, verify and install the artifacts, " +
+			"then check the integrity files. The build is recorded in .superviz/build.lock next to the configuration, keyed " +
+			"by a hash of the artifacts and integrity sections, so that a Dockerfile RUN svz build layer bakes the binaries " +
+			"into the image and a later svz run skips the installation. Running it again with an unchanged configuration " +
+			"and installed artifacts does nothing. The command fails when an artifact cannot be installed or a file fails " +
+			"its integrity check, unless on_failure is alert.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			format, err := utils.OutputFormatFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			return service.Build(cmd.Context(), cmd.OutOrStdout(), configPath, format)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", providers.DefaultSupervizConfigPath, "Path to the superviz.yaml file")
+
+	return cmd
+}
@@ -0,0 +1,76 @@
+package build_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/cli/commands/build"
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCommand(t *testing.T) {
+	cmd := build.GetCommand()
+	require.NotNil(t, cmd)
+	require.Equal(t, "build [flags]", cmd.Use)
+	require.NotEmpty(t, cmd.Long)
+	require.Same(t, cmd, build.GetCommand(), "GetCommand should return the same instance")
+}
+
+func TestGetCommandWithService(t *testing.T) {
+	require.Same(t, build.GetCommand(), build.GetCommandWithService(nil))
+
+	cmd := build.GetCommandWithService(services.NewBuildService(nil))
+	require.NotSame(t, build.GetCommand(), cmd)
+}
+
+func TestBuildCommandFlags(t *testing.T) {
+	cmd := build.NewBuildCommand(services.NewBuildService(nil))
+	flag := cmd.Flags().Lookup("config")
+	require.NotNil(t, flag)
+	require.Equal(t, "c", flag.Shorthand)
+	require.Equal(t, "superviz.yaml", flag.DefValue)
+}
+
+func TestBuildCommand_Provision(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app"), []byte("app"), 0o600))
+	path := filepath.Join(dir, "superviz.yaml")
+	sum := sha256.Sum256([]byte("app"))
+	require.NoError(t, os.WriteFile(path, []byte("integrity:\n  files: [{path: app, sha256: "+hex.EncodeToString(sum[:])+"}]\nservices:\n  app: {command: app}\n"), 0o600))
+
+	var out bytes.Buffer
+	cmd := build.NewBuildCommand(services.NewBuildService(nil))
+	cmd.SetArgs([]string{"-c", path})
+	cmd.SetOut(&out)
+	require.NoError(t, cmd.Execute())
+	require.Contains(t, out.String(), "integrity: "+filepath.Join(dir, "app")+": ok\n")
+	require.FileExists(t, filepath.Join(dir, ".superviz", "build.lock"))
+}
+
+func TestBuildCommand_InvalidInvocations(t *testing.T) {
+	invalid := filepath.Join(t.TempDir(), "superviz.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("services:\n  web: {}\n"), 0o600))
+
+	tests := map[string][]string{
+		"missing config":  {"--config", filepath.Join(t.TempDir(), "missing.yaml")},
+		"invalid config":  {"-c", invalid},
+		"extra arguments": {"web"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := build.NewBuildCommand(services.NewBuildService(nil))
+			cmd.SetArgs(args)
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			require.Error(t, cmd.Execute())
+		})
+	}
+}
@@ -0,0 +1,278 @@
+// Package ctl provides CLI command functionality for driving a running supervisor through its control API
+package ctl
+
+import (
+	"sync"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/kodflow/superviz.io/internal/services/control"
+	"github.com/kodflow/superviz.io/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// defaultService holds the singleton ctl service instance
+	defaultService *services.CtlService
+	// defaultCmd holds the singleton ctl command instance
+	defaultCmd *cobra.Command
+	// once ensures the default instances are initialized only once
+	once sync.Once
+)
+
+// initDefaults initializes the default service and command instances once.
+//
+// initDefaults creates the singleton instances of the ctl service and
+// command, ensuring they are created only once for the lifetime of the application.
+func initDefaults() {
+	defaultService = services.NewCtlService()
+	defaultCmd = createCtlCommand(defaultService)
+}
+
+// GetCommand returns the singleton Cobra command for the control client.
+//
+// GetCommand provides access to the default ctl command instance, initializing
+// it if necessary using sync.Once for thread safety.
+//
+// Returns:
+//   - Cobra command instance configured for the control client
+func GetCommand() *cobra.Command {
+	once.Do(initDefaults)
+	return defaultCmd
+}
+
+// GetCommandWithService returns a Cobra command with a custom ctl service.
+//
+// GetCommandWithService allows injection of a custom ctl service while
+// falling back to the singleton command if service is nil.
+//
+// Parameters:
+//   - service: Custom ctl service instance (nil for default)
+//
+// Returns:
+//   - Cobra command instance with the specified or default service
+func GetCommandWithService(service *services.CtlService) *cobra.Command {
+	if service == nil {
+		return GetCommand()
+	}
+	return NewCtlCommand(service)
+}
+
+// NewCtlCommand creates a new ctl command with the given service.
+//
+// NewCtlCommand constructs a fresh ctl command instance with the
+// provided service, bypassing the singleton pattern for testing or special cases.
+//
+// Parameters:
+//   - service: Ctl service instance to use for the command
+//
+// Returns:
+//   - New Cobra command instance configured with the provided service
+func NewCtlCommand(service *services.CtlService) *cobra.Command {
+	return createCtlCommand(service)
+}
+
+// createCtlCommand creates the cobra command with its subcommands and connection flags.
+//
+// Parameters:
+//   - service: Ctl service instance calling the control API
+//
+// Returns:
+//   - Configured Cobra command ready for execution
+func createCtlCommand(service *services.CtlService) *cobra.Command {
+	opts := &control.ClientOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "ctl <command> [flags]",
+		Short: "Control the services of a running svz run",
+		Long: "List, start, stop, restart and signal the services of a running svz run, read their output, reload its " +
+			"configuration and read its reconciliation events through the control API enabled by the control section of superviz.yaml. The API is served on " +
+			"a Unix socket whose permissions decide who may connect; --socket defaults to superviz.sock in the current " +
+			"directory, where svz run creates it for a superviz.yaml in that directory. Use --address with --cert, --key " +
+			"and --ca to reach the mutual TLS listener instead. Use -o json or -o yaml for scripting.",
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&opts.Socket, "socket", providers.DefaultControlSocket, "Path to the control socket")
+	flags.StringVar(&opts.Address, "address", "", "host:port of the mutual TLS listener, instead of the socket")
+	flags.StringVar(&opts.Cert, "cert", "", "Client certificate for --address")
+	flags.StringVar(&opts.Key, "key", "", "Client private key for --address")
+	flags.StringVar(&opts.CA, "ca", "", "CA bundle the server certificate must chain to, default the system roots")
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "List every service with its state",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, _ []string) error {
+				format, err := utils.OutputFormatFromCommand(cmd)
+				if err != nil {
+					return err
+				}
+				return service.List(cmd.Context(), cmd.OutOrStdout(), opts, format)
+			},
+		},
+		&cobra.Command{
+			Use:   "status [service...]",
+			Short: "Show the state of services, every service by default",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				format, err := utils.OutputFormatFromCommand(cmd)
+				if err != nil {
+					return err
+				}
+				return service.Status(cmd.Context(), cmd.OutOrStdout(), opts, args, format)
+			},
+		},
+		actionCommand(service, opts, services.CtlStart, "Start stopped services, after their dependencies"),
+		actionCommand(service, opts, services.CtlStop, "Stop services; they stay stopped until started again"),
+		actionCommand(service, opts, services.CtlRestart, "Stop services if they run, then start them"),
+		&cobra.Command{
+			Use:   "signal <signal> <service>...",
+			Short: "Send a signal such as HUP or USR1 to the process group of services",
+			Args:  cobra.MinimumNArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				format, err := utils.OutputFormatFromCommand(cmd)
+				if err != nil {
+					return err
+				}
+				return service.Signal(cmd.Context(), cmd.OutOrStdout(), opts, args[0], args[1:], format)
+			},
+		},
+		logsCommand(service, opts),
+		reloadCommand(service, opts),
+		eventsCommand(service, opts),
+	)
+
+	return cmd
+}
+
+// actionCommand creates the start, stop or restart subcommand.
+//
+// Parameters:
+//   - service: Ctl service instance calling the control API
+//   - opts: Connection settings filled by the persistent flags
+//   - action: Operation applied to the services
+//   - short: One-line description
+//
+// Returns:
+//   - Configured Cobra subcommand
+func actionCommand(service *services.CtlService, opts *control.ClientOptions, action services.CtlAction, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   string(action) + " <service>...",
+		Short: short,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := utils.OutputFormatFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			return service.Apply(cmd.Context(), cmd.OutOrStdout(), opts, action, args, format)
+		},
+	}
+}
+
+// reloadCommand creates the reload subcommand.
+//
+// Parameters:
+//   - service: Ctl service instance calling the control API
+//   - opts: Connection settings filled by the persistent flags
+//
+// Returns:
+//   - Configured Cobra subcommand
+func reloadCommand(service *services.CtlService, opts *control.ClientOptions) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "reload [flags]",
+		Short: "Apply the configuration again",
+		Long: "Make the supervisor read superviz.yaml again, like SIGHUP does, and apply the changes: added services " +
+			"start, removed services stop, services changed in their command, arguments, environment, working " +
+			"directory, user, group or logs restart, and services changed only in their restart, depends_on, probes " +
+			"or stop settings keep their process. An invalid configuration is rejected and the running one is kept. " +
+			"With --dry-run, only print the changes.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			format, err := utils.OutputFormatFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			return service.Reload(cmd.Context(), cmd.OutOrStdout(), opts, dryRun, format)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the changes without applying them")
+
+	return cmd
+}
+
+// logsCommand creates the logs subcommand.
+//
+// Parameters:
+//   - service: Ctl service instance calling the control API
+//   - opts: Connection settings filled by the persistent flags
+//
+// Returns:
+//   - Configured Cobra subcommand
+func logsCommand(service *services.CtlService, opts *control.ClientOptions) *cobra.Command {
+	var lines int
+	var follow bool
+
+	cmd := &cobra.Command{
+		Use:   "logs [flags] <service>",
+		Short: "Print the recent output of a service",
+		Long: "Print the recent standard output and error of a service, prefixed with its name. With -f, keep printing " +
+			"new lines until interrupted or the supervisor stops. Structured formats print one log line per JSON line " +
+			"or YAML document.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := utils.OutputFormatFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			return service.Logs(cmd.Context(), cmd.OutOrStdout(), opts, args[0], lines, follow, format)
+		},
+	}
+
+	cmd.Flags().IntVarP(&lines, "lines", "n", 0, "Number of past lines to print, every kept line when 0")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep printing new lines")
+
+	return cmd
+}
+
+// eventsCommand creates the events subcommand.
+//
+// Parameters:
+//   - service: Ctl service instance calling the control API
+//   - opts: Connection settings filled by the persistent flags
+//
+// Returns:
+//   - Configured Cobra subcommand
+func eventsCommand(service *services.CtlService, opts *control.ClientOptions) *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "events [flags] [resource]",
+		Short: "Print the divergences and corrective actions of the reconciler",
+		Long: "Print the events recorded by the reconciler enabled by the reconcile section of superviz.yaml, oldest " +
+			"first: each divergence from the declared state, each corrective action and its outcome, the actions " +
+			"postponed by the rate limit and the resources converging again. Give a resource such as service/web, " +
+			"env/web, files or artifact/vault to print only its events.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := utils.OutputFormatFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			resource := ""
+			if len(args) == 1 {
+				resource = args[0]
+			}
+			return service.Events(cmd.Context(), cmd.OutOrStdout(), opts, resource, limit, format)
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 0, "Number of latest events to print, every kept event when 0")
+
+	return cmd
+}
@@ -0,0 +1,176 @@
+package ctl_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/cli/commands/ctl"
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/kodflow/superviz.io/internal/services/control"
+	"github.com/kodflow/superviz.io/internal/services/supervisor"
+	"github.com/stretchr/testify/require"
+)
+
+// stubBackend is a supervisor with a single running service
+type stubBackend struct{}
+
+func (stubBackend) Services() []supervisor.ServiceStatus {
+	return []supervisor.ServiceStatus{{Name: "web", State: supervisor.StateRunning, PID: 42}}
+}
+
+func (b stubBackend) Service(name string) (supervisor.ServiceStatus, error) {
+	if name != "web" {
+		return supervisor.ServiceStatus{}, supervisor.ErrUnknownService
+	}
+	return b.Services()[0], nil
+}
+
+func (stubBackend) Start(string) error                 { return supervisor.ErrServiceActive }
+func (stubBackend) Stop(string) error                  { return nil }
+func (stubBackend) Restart(string) error               { return nil }
+func (stubBackend) SignalService(string, string) error { return nil }
+
+func (stubBackend) Logs(string, int) ([]supervisor.LogLine, error) {
+	return []supervisor.LogLine{{Service: "web", Stream: supervisor.StreamStdout, Text: "hello"}}, nil
+}
+
+func (b stubBackend) FollowLogs(_ context.Context, name string, n int) (<-chan supervisor.LogLine, error) {
+	tail, _ := b.Logs(name, n)
+	lines := make(chan supervisor.LogLine, len(tail))
+	for _, line := range tail {
+		lines <- line
+	}
+	close(lines)
+	return lines, nil
+}
+
+// serveStub serves stubBackend on a fresh socket until the test ends and returns its path
+func serveStub(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "svz")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	config := &providers.ControlConfig{Socket: filepath.Join(dir, "ctl.sock"), SocketMode: providers.DefaultControlSocketMode}
+	listener, err := control.ListenUnix(config)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- control.NewServer(stubBackend{}, nil).Serve(ctx, listener) }()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+	return config.Socket
+}
+
+// execute runs the ctl command with args and returns its output
+func execute(args ...string) (string, error) {
+	var out bytes.Buffer
+	cmd := ctl.NewCtlCommand(services.NewCtlService())
+	cmd.SetArgs(args)
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestGetCommand(t *testing.T) {
+	cmd := ctl.GetCommand()
+	require.NotNil(t, cmd)
+	require.Equal(t, "ctl <command> [flags]", cmd.Use)
+	require.NotEmpty(t, cmd.Long)
+	require.Same(t, cmd, ctl.GetCommand(), "GetCommand should return the same instance")
+}
+
+func TestGetCommandWithService(t *testing.T) {
+	require.Same(t, ctl.GetCommand(), ctl.GetCommandWithService(nil))
+
+	cmd := ctl.GetCommandWithService(services.NewCtlService())
+	require.NotSame(t, ctl.GetCommand(), cmd)
+}
+
+func TestCtlCommandFlags(t *testing.T) {
+	cmd := ctl.NewCtlCommand(services.NewCtlService())
+	flags := cmd.PersistentFlags()
+
+	socket := flags.Lookup("socket")
+	require.NotNil(t, socket)
+	require.Equal(t, "superviz.sock", socket.DefValue)
+	for _, name := range []string{"address", "cert", "key", "ca"} {
+		require.NotNil(t, flags.Lookup(name), name)
+	}
+
+	for _, name := range []string{"list", "status", "start", "stop", "restart", "signal", "logs", "reload", "events"} {
+		sub, _, err := cmd.Find([]string{name})
+		require.NoError(t, err)
+		require.Equal(t, name, sub.Name())
+	}
+
+	logs, _, err := cmd.Find([]string{"logs"})
+	require.NoError(t, err)
+	require.Equal(t, "f", logs.Flags().Lookup("follow").Shorthand)
+	require.Equal(t, "n", logs.Flags().Lookup("lines").Shorthand)
+
+	reload, _, err := cmd.Find([]string{"reload"})
+	require.NoError(t, err)
+	require.NotNil(t, reload.Flags().Lookup("dry-run"))
+
+	events, _, err := cmd.Find([]string{"events"})
+	require.NoError(t, err)
+	require.Equal(t, "n", events.Flags().Lookup("limit").Shorthand)
+}
+
+func TestCtlCommand_Operations(t *testing.T) {
+	socket := serveStub(t)
+
+	out, err := execute("list", "--socket", socket)
+	require.NoError(t, err)
+	require.Contains(t, out, "web   running  42")
+
+	out, err = execute("logs", "--socket", socket, "-f", "web")
+	require.NoError(t, err)
+	require.Equal(t, "web | hello\n", out)
+
+	_, err = execute("stop", "--socket", socket, "web")
+	require.NoError(t, err)
+
+	_, err = execute("start", "--socket", socket, "web")
+	require.ErrorContains(t, err, "web: service is already running")
+
+	_, err = execute("reload", "--socket", socket)
+	require.ErrorContains(t, err, "reload is not supported")
+
+	_, err = execute("reload", "--dry-run", "--socket", socket)
+	require.ErrorContains(t, err, "reload is not supported")
+
+	_, err = execute("events", "--socket", socket, "service/web")
+	require.ErrorContains(t, err, "reconciliation is not enabled")
+}
+
+func TestCtlCommand_InvalidInvocations(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.sock")
+	tests := map[string][]string{
+		"unreachable socket": {"list", "--socket", missing},
+		"list arguments":     {"list", "web", "--socket", missing},
+		"start no service":   {"start", "--socket", missing},
+		"signal no service":  {"signal", "HUP", "--socket", missing},
+		"logs two services":  {"logs", "web", "db", "--socket", missing},
+		"reload arguments":   {"reload", "now", "--socket", missing},
+		"events arguments":   {"events", "files", "service/web", "--socket", missing},
+		"no socket":          {"list", "--socket", ""},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := execute(args...)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Package graph provides CLI command functionality for printing the service dependency graph of superviz.yaml
+package graph
+
+import (
+	"sync"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/kodflow/superviz.io/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// defaultService holds the singleton graph service instance
+	defaultService *services.GraphService
+	// defaultCmd holds the singleton graph command instance
+	defaultCmd *cobra.Command
+	// once ensures the default instances are initialized only once
+	once sync.Once
+)
+
+// initDefaults initializes the default service and command instances once.
+//
+// initDefaults creates the singleton instances of the graph service and
+// command, ensuring they are created only once for the lifetime of the application.
+func initDefaults() {
+	defaultService = services.NewGraphService()
+	defaultCmd = createGraphCommand(defaultService)
+}
+
+// GetCommand returns the singleton Cobra command for the dependency graph.
+//
+// GetCommand provides access to the default graph command instance, initializing
+// it if necessary using sync.Once for thread safety.
+//
+// Returns:
+//   - Cobra command instance configured for the dependency graph
+func GetCommand() *cobra.Command {
+	once.Do(initDefaults)
+	return defaultCmd
+}
+
+// GetCommandWithService returns a Cobra command with a custom graph service.
+//
+// GetCommandWithService allows injection of a custom graph service while
+// falling back to the singleton command if service is nil.
+//
+// Parameters:
+//   - service: Custom graph service instance (nil for default)
+//
+// Returns:
+//   - Cobra command instance with the specified or default service
+func GetCommandWithService(service *services.GraphService) *cobra.Command {
+	if service == nil {
+		return GetCommand()
+	}
+	return NewGraphCommand(service)
+}
+
+// NewGraphCommand creates a new graph command with the given service.
+//
+// NewGraphCommand constructs a fresh graph command instance with the
+// provided service, bypassing the singleton pattern for testing or special cases.
+//
+// Parameters:
+//   - service: Graph service instance to use for the command
+//
+// Returns:
+//   - New Cobra command instance configured with the provided service
+func NewGraphCommand(service *services.GraphService) *cobra.Command {
+	return createGraphCommand(service)
+}
+
+// createGraphCommand creates the cobra command with all flags and validation.
+//
+// Parameters:
+//   - service: Graph service instance rendering the graph
+//
+// Returns:
+//   - Configured Cobra command ready for execution
+func createGraphCommand(service *services.GraphService) *cobra.Command {
+	var configPath string
+	var dot bool
+
+	cmd := &cobra.Command{
+		Use:   "graph [flags]",
+		Short: "Print the service dependency graph of a superviz.yaml file",
+		Long: "Print the services of a superviz.yaml file grouped by start level, with the dependencies and conditions " +
+			"each one waits for. Services of one level start in parallel and stop in reverse order. Use --dot for a " +
+			"Graphviz DOT graph, e.g. svz graph --dot | dot -Tsvg > graph.svg.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			format, err := utils.OutputFormatFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			return service.Graph(cmd.OutOrStdout(), configPath, format, dot)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", providers.DefaultSupervizConfigPath, "Path to the superviz.yaml file")
+	cmd.Flags().BoolVar(&dot, "dot", false, "Print the graph in Graphviz DOT format")
+
+	return cmd
+}
@@ -0,0 +1,69 @@
+package graph_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/cli/commands/graph"
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCommand(t *testing.T) {
+	cmd := graph.GetCommand()
+	require.NotNil(t, cmd)
+	require.Equal(t, "graph [flags]", cmd.Use)
+	require.NotEmpty(t, cmd.Long)
+	require.Same(t, cmd, graph.GetCommand(), "GetCommand should return the same instance")
+}
+
+func TestGetCommandWithService(t *testing.T) {
+	require.Same(t, graph.GetCommand(), graph.GetCommandWithService(nil))
+
+	cmd := graph.GetCommandWithService(services.NewGraphService())
+	require.NotSame(t, graph.GetCommand(), cmd)
+}
+
+func TestGraphCommandFlags(t *testing.T) {
+	cmd := graph.NewGraphCommand(services.NewGraphService())
+	flags := cmd.Flags()
+
+	require.NotNil(t, flags.Lookup("dot"))
+	config := flags.Lookup("config")
+	require.NotNil(t, config)
+	require.Equal(t, "c", config.Shorthand)
+	require.Equal(t, "superviz.yaml", config.DefValue)
+}
+
+func TestGraphCommand_Dot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "superviz.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("services:\n  db: {command: db}\n  web: {command: web, depends_on: [db]}\n"), 0o600))
+
+	var out bytes.Buffer
+	cmd := graph.NewGraphCommand(services.NewGraphService())
+	cmd.SetArgs([]string{"-c", path, "--dot"})
+	cmd.SetOut(&out)
+	require.NoError(t, cmd.Execute())
+	require.Contains(t, out.String(), `"db" -> "web" [label="started"];`)
+}
+
+func TestGraphCommand_InvalidInvocations(t *testing.T) {
+	tests := map[string][]string{
+		"missing config":  {"--config", filepath.Join(t.TempDir(), "missing.yaml")},
+		"extra arguments": {"web"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := graph.NewGraphCommand(services.NewGraphService())
+			cmd.SetArgs(args)
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			require.Error(t, cmd.Execute())
+		})
+	}
+}
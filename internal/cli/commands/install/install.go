@@ -91,13 +91,14 @@ func createInstallCommand(service *services.InstallService) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "install user@host [flags]",
 		Short: "Setup superviz.io repository on remote system",
-		Long:  "Setup superviz.io package repository on the remote system so you can install superviz.io using the system package manager (apt, apk, yum, etc.).",
-		Args:  utils.RequireOneTarget,
+		Long: "Setup superviz.io package repository on the remote system so you can install superviz.io using the system package manager (apt, apk, yum, etc.). " +
+			"Agents outside the versions supported by this CLI are refused; pin a supported release with --version.",
+		Args: utils.RequireOneTarget,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return service.ValidateAndPrepareConfig(opts, args)
 		},
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			return service.Install(cmd.Context(), cmd.OutOrStdout(), opts)
+			return runInstall(cmd, service, opts)
 		},
 	}
 
@@ -107,6 +108,38 @@ func createInstallCommand(service *services.InstallService) *cobra.Command {
 	cmd.Flags().DurationVarP(&opts.Timeout, "timeout", "t", 300*time.Second, "Connection timeout (e.g. 30s, 5m)")
 	cmd.Flags().BoolVarP(&opts.Force, "force", "f", false, "Force installation even if components already exist")
 	cmd.Flags().BoolVar(&opts.SkipHostKeyCheck, "skip-host-key-check", false, "Skip host key verification (development only)")
+	cmd.Flags().BoolVar(&opts.SkipPreflight, "skip-preflight", false, "Skip remote preflight checks before setup")
+	cmd.Flags().StringVarP(&opts.JumpHost, "jump-host", "J", "", "Connect through a bastion host ([user@]host[:port])")
+	cmd.Flags().StringVar(&opts.RepoMirror, "repo-mirror", "", "Repository mirror replacing https://repo.superviz.io")
+	cmd.Flags().StringVar(&opts.Version, "version", "", "Agent package version to install (default: the repository's latest)")
 
 	return cmd
 }
+
+// runInstall executes the installation in the output format selected for cmd.
+//
+// Text output prints human-readable progress; json and yaml output stream
+// one event per record and finish with a summary record.
+//
+// Parameters:
+//   - cmd: *cobra.Command command being executed
+//   - service: *services.InstallService install service
+//   - opts: *providers.InstallConfig validated installation configuration
+//
+// Returns:
+//   - err: error if the output format is invalid or installation fails
+func runInstall(cmd *cobra.Command, service *services.InstallService, opts *providers.InstallConfig) error {
+	format, err := utils.OutputFormatFromCommand(cmd)
+	if err != nil {
+		return err
+	}
+	if format == utils.OutputText {
+		return service.Install(cmd.Context(), cmd.OutOrStdout(), opts)
+	}
+
+	reporter, err := services.NewStructuredInstallReporter(cmd.OutOrStdout(), format)
+	if err != nil {
+		return err
+	}
+	return service.InstallWithReporter(cmd.Context(), reporter, opts)
+}
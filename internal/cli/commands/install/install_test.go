@@ -91,6 +91,27 @@ func TestCreateInstallCommandFlags(t *testing.T) {
 	skipFlag := flags.Lookup("skip-host-key-check")
 	require.NotNil(t, skipFlag)
 	require.Equal(t, "false", skipFlag.DefValue)
+
+	// Skip preflight flag
+	preflightFlag := flags.Lookup("skip-preflight")
+	require.NotNil(t, preflightFlag)
+	require.Equal(t, "false", preflightFlag.DefValue)
+
+	// Jump host flag
+	jumpFlag := flags.Lookup("jump-host")
+	require.NotNil(t, jumpFlag)
+	require.Equal(t, "J", jumpFlag.Shorthand)
+	require.Equal(t, "", jumpFlag.DefValue)
+
+	// Repository mirror flag
+	mirrorFlag := flags.Lookup("repo-mirror")
+	require.NotNil(t, mirrorFlag)
+	require.Equal(t, "", mirrorFlag.DefValue)
+
+	// Agent version flag
+	versionFlag := flags.Lookup("version")
+	require.NotNil(t, versionFlag)
+	require.Equal(t, "", versionFlag.DefValue)
 }
 
 func TestInstallCommandValidation(t *testing.T) {
@@ -0,0 +1,115 @@
+// Package preflight provides CLI command functionality for remote host preflight checks
+package preflight
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/kodflow/superviz.io/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// defaultService holds the singleton preflight service instance
+	defaultService *services.PreflightService
+	// defaultCmd holds the singleton preflight command instance
+	defaultCmd *cobra.Command
+	// once ensures the default instances are initialized only once
+	once sync.Once
+)
+
+// initDefaults initializes the default service and command instances once.
+//
+// initDefaults creates the singleton instances of the preflight service and
+// command, ensuring they are created only once for the lifetime of the application.
+func initDefaults() {
+	defaultService = services.NewPreflightService(nil)
+	defaultCmd = createPreflightCommand(defaultService)
+}
+
+// GetCommand returns the singleton Cobra command for preflight checks.
+//
+// GetCommand provides access to the default preflight command instance, initializing
+// it if necessary using sync.Once for thread safety.
+//
+// Returns:
+//   - Cobra command instance configured for preflight checks
+func GetCommand() *cobra.Command {
+	once.Do(initDefaults)
+	return defaultCmd
+}
+
+// GetCommandWithService returns a Cobra command with a custom preflight service.
+//
+// GetCommandWithService allows injection of a custom preflight service while
+// falling back to the singleton command if service is nil.
+//
+// Parameters:
+//   - service: Custom preflight service instance (nil for default)
+//
+// Returns:
+//   - Cobra command instance with the specified or default service
+func GetCommandWithService(service *services.PreflightService) *cobra.Command {
+	if service == nil {
+		return GetCommand()
+	}
+	return NewPreflightCommand(service)
+}
+
+// NewPreflightCommand creates a new preflight command with the given service.
+//
+// NewPreflightCommand constructs a fresh preflight command instance with the
+// provided service, bypassing the singleton pattern for testing or special cases.
+//
+// Parameters:
+//   - service: Preflight service instance to use for the command
+//
+// Returns:
+//   - New Cobra command instance configured with the provided service
+func NewPreflightCommand(service *services.PreflightService) *cobra.Command {
+	return createPreflightCommand(service)
+}
+
+// createPreflightCommand creates the cobra command with all flags and validation.
+//
+// Parameters:
+//   - service: Preflight service instance to run the checks
+//
+// Returns:
+//   - Configured Cobra command ready for execution
+func createPreflightCommand(service *services.PreflightService) *cobra.Command {
+	opts := &providers.InstallConfig{
+		Port:    22,
+		Timeout: 60 * time.Second,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "preflight user@host [flags]",
+		Short: "Check that a remote system is ready for installation",
+		Long: "Check free disk space, required binaries, clock skew, repository reachability, " +
+			"init system and package manager locks on the remote system. Each check reports pass, warn or fail.",
+		Args: utils.RequireOneTarget,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return service.ValidateAndPrepareConfig(opts, args)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			format, err := utils.OutputFormatFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			return service.PreflightAs(cmd.Context(), cmd.OutOrStdout(), opts, format)
+		},
+	}
+
+	// Configure command flags for SSH connection
+	cmd.Flags().StringVarP(&opts.KeyPath, "ssh-key", "i", "", "Path to SSH private key file")
+	cmd.Flags().IntVarP(&opts.Port, "ssh-port", "p", 22, "SSH port")
+	cmd.Flags().DurationVarP(&opts.Timeout, "timeout", "t", 60*time.Second, "Connection timeout (e.g. 30s, 5m)")
+	cmd.Flags().BoolVar(&opts.SkipHostKeyCheck, "skip-host-key-check", false, "Skip host key verification (development only)")
+	cmd.Flags().StringVarP(&opts.JumpHost, "jump-host", "J", "", "Connect through a bastion host ([user@]host[:port])")
+	cmd.Flags().StringVar(&opts.RepoMirror, "repo-mirror", "", "Repository mirror to probe instead of https://repo.superviz.io")
+
+	return cmd
+}
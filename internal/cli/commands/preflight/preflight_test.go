@@ -0,0 +1,43 @@
+package preflight_test
+
+import (
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/cli/commands/preflight"
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCommand(t *testing.T) {
+	cmd := preflight.GetCommand()
+	require.NotNil(t, cmd)
+	require.Equal(t, "preflight user@host [flags]", cmd.Use)
+	require.NotEmpty(t, cmd.Long)
+	require.Same(t, cmd, preflight.GetCommand(), "GetCommand should return the same instance")
+}
+
+func TestGetCommandWithService(t *testing.T) {
+	require.Same(t, preflight.GetCommand(), preflight.GetCommandWithService(nil))
+
+	cmd := preflight.GetCommandWithService(services.NewPreflightService(nil))
+	require.NotSame(t, preflight.GetCommand(), cmd)
+}
+
+func TestPreflightCommandFlags(t *testing.T) {
+	cmd := preflight.NewPreflightCommand(services.NewPreflightService(nil))
+	flags := cmd.Flags()
+
+	for _, name := range []string{"ssh-key", "ssh-port", "timeout", "skip-host-key-check", "jump-host", "repo-mirror"} {
+		require.NotNil(t, flags.Lookup(name), "missing flag %s", name)
+	}
+	require.Equal(t, "22", flags.Lookup("ssh-port").DefValue)
+	require.Equal(t, "1m0s", flags.Lookup("timeout").DefValue)
+}
+
+func TestPreflightCommand_InvalidTarget(t *testing.T) {
+	cmd := preflight.NewPreflightCommand(services.NewPreflightService(nil))
+	cmd.SetArgs([]string{"not-a-target"})
+	cmd.SilenceUsage = true
+
+	require.Error(t, cmd.Execute())
+}
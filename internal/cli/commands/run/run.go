@@ -0,0 +1,114 @@
+// Package run provides CLI command functionality for supervising local processes from superviz.yaml
+package run
+
+import (
+	"sync"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/kodflow/superviz.io/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// defaultService holds the singleton run service instance
+	defaultService *services.RunService
+	// defaultCmd holds the singleton run command instance
+	defaultCmd *cobra.Command
+	// once ensures the default instances are initialized only once
+	once sync.Once
+)
+
+// initDefaults initializes the default service and command instances once.
+//
+// initDefaults creates the singleton instances of the run service and
+// command, ensuring they are created only once for the lifetime of the application.
+func initDefaults() {
+	defaultService = services.NewRunService(nil)
+	defaultCmd = createRunCommand(defaultService)
+}
+
+// GetCommand returns the singleton Cobra command for process supervision.
+//
+// GetCommand provides access to the default run command instance, initializing
+// it if necessary using sync.Once for thread safety.
+//
+// Returns:
+//   - Cobra command instance configured for process supervision
+func GetCommand() *cobra.Command {
+	once.Do(initDefaults)
+	return defaultCmd
+}
+
+// GetCommandWithService returns a Cobra command with a custom run service.
+//
+// GetCommandWithService allows injection of a custom run service while
+// falling back to the singleton command if service is nil.
+//
+// Parameters:
+//   - service: Custom run service instance (nil for default)
+//
+// Returns:
+//   - Cobra command instance with the specified or default service
+func GetCommandWithService(service *services.RunService) *cobra.Command {
+	if service == nil {
+		return GetCommand()
+	}
+	return NewRunCommand(service)
+}
+
+// NewRunCommand creates a new run command with the given service.
+//
+// NewRunCommand constructs a fresh run command instance with the
+// provided service, bypassing the singleton pattern for testing or special cases.
+//
+// Parameters:
+//   - service: Run service instance to use for the command
+//
+// Returns:
+//   - New Cobra command instance configured with the provided service
+func NewRunCommand(service *services.RunService) *cobra.Command {
+	return createRunCommand(service)
+}
+
+// createRunCommand creates the cobra command with all flags and validation.
+//
+// Parameters:
+//   - service: Run service instance supervising the processes
+//
+// Returns:
+//   - Configured Cobra command ready for execution
+func createRunCommand(service *services.RunService) *cobra.Command {
+	var configPath string
+
+	cmd := &cobra.Command{
+		Use:   "run [flags]",
+		Short: "Start and supervise the services of a superviz.yaml file",
+		Long: "Start the services declared in a superviz.yaml file and restart them according to their restart mode and " +
+			"restart_policy, with exponential backoff. A service that crash-loops or exhausts its retries becomes fatal and " +
+			"the on_fatal action applies: continue, exit or hook. Startup, liveness and readiness probes (exec, http, tcp or grpc) " +
+			"mark a service healthy or unhealthy; a failing liveness or startup probe restarts it. " +
+			"A service starts once its depends_on conditions are met (started, healthy or completed_successfully), so " +
+			"independent services start in parallel. Each service runs in its own process group. On SIGINT or SIGTERM " +
+			"every service is stopped after its dependents, with its stop signal and, past its stop_timeout, SIGKILL; a second signal kills " +
+			"them at once. SIGUSR1 and SIGUSR2 are forwarded to every service. SIGHUP reloads superviz.yaml: added services " +
+			"start, removed ones stop and only the services whose process must change restart; an invalid file is " +
+			"reported and the running configuration kept. Run as PID 1 in a container, svz " +
+			"also reaps orphaned processes. State transitions are printed as they happen. With a control section, svz run " +
+			"serves the control API used by svz ctl and keeps running after its services end so they can be started again. " +
+			"With a reconcile section, fatal services, invalid environments, failing integrity files and missing artifacts " +
+			"are checked every interval and corrected within a rate limit; svz ctl events lists what was found and done.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			format, err := utils.OutputFormatFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			return service.Run(cmd.Context(), cmd.OutOrStdout(), configPath, format)
+		},
+	}
+
+	cmd.Flags().StringVarP(&configPath, "config", "c", providers.DefaultSupervizConfigPath, "Path to the superviz.yaml file")
+
+	return cmd
+}
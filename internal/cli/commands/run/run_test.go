@@ -0,0 +1,58 @@
+package run_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/cli/commands/run"
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCommand(t *testing.T) {
+	cmd := run.GetCommand()
+	require.NotNil(t, cmd)
+	require.Equal(t, "run [flags]", cmd.Use)
+	require.NotEmpty(t, cmd.Long)
+	require.Same(t, cmd, run.GetCommand(), "GetCommand should return the same instance")
+}
+
+func TestGetCommandWithService(t *testing.T) {
+	require.Same(t, run.GetCommand(), run.GetCommandWithService(nil))
+
+	cmd := run.GetCommandWithService(services.NewRunService(nil))
+	require.NotSame(t, run.GetCommand(), cmd)
+}
+
+func TestRunCommandFlags(t *testing.T) {
+	cmd := run.NewRunCommand(services.NewRunService(nil))
+	flag := cmd.Flags().Lookup("config")
+	require.NotNil(t, flag)
+	require.Equal(t, "c", flag.Shorthand)
+	require.Equal(t, "superviz.yaml", flag.DefValue)
+}
+
+func TestRunCommand_InvalidInvocations(t *testing.T) {
+	invalid := filepath.Join(t.TempDir(), "superviz.yaml")
+	require.NoError(t, os.WriteFile(invalid, []byte("services:\n  web: {}\n"), 0o600))
+
+	tests := map[string][]string{
+		"missing config":  {"--config", filepath.Join(t.TempDir(), "missing.yaml")},
+		"invalid config":  {"-c", invalid},
+		"extra arguments": {"web"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := run.NewRunCommand(services.NewRunService(nil))
+			cmd.SetArgs(args)
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			require.Error(t, cmd.Execute())
+		})
+	}
+}
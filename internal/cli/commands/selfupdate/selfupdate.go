@@ -0,0 +1,117 @@
+// Package selfupdate provides CLI command functionality for updating the svz binary in place
+package selfupdate
+
+import (
+	"sync"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/kodflow/superviz.io/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// defaultService holds the singleton self-update service instance
+	defaultService *services.SelfUpdateService
+	// defaultCmd holds the singleton self-update command instance
+	defaultCmd *cobra.Command
+	// once ensures the default instances are initialized only once
+	once sync.Once
+)
+
+// initDefaults initializes the default service and command instances once.
+//
+// initDefaults creates the singleton instances of the self-update service and
+// command, ensuring they are created only once for the lifetime of the application.
+func initDefaults() {
+	defaultService = services.NewSelfUpdateService(nil)
+	defaultCmd = createSelfUpdateCommand(defaultService)
+}
+
+// GetCommand returns the singleton Cobra command for self-updates.
+//
+// GetCommand provides access to the default self-update command instance, initializing
+// it if necessary using sync.Once for thread safety.
+//
+// Returns:
+//   - Cobra command instance configured for self-updates
+func GetCommand() *cobra.Command {
+	once.Do(initDefaults)
+	return defaultCmd
+}
+
+// GetCommandWithService returns a Cobra command with a custom self-update service.
+//
+// GetCommandWithService allows injection of a custom self-update service while
+// falling back to the singleton command if service is nil.
+//
+// Parameters:
+//   - service: Custom self-update service instance (nil for default)
+//
+// Returns:
+//   - Cobra command instance with the specified or default service
+func GetCommandWithService(service *services.SelfUpdateService) *cobra.Command {
+	if service == nil {
+		return GetCommand()
+	}
+	return NewSelfUpdateCommand(service)
+}
+
+// NewSelfUpdateCommand creates a new self-update command with the given service.
+//
+// NewSelfUpdateCommand constructs a fresh self-update command instance with the
+// provided service, bypassing the singleton pattern for testing or special cases.
+//
+// Parameters:
+//   - service: Self-update service instance to use for the command
+//
+// Returns:
+//   - New Cobra command instance configured with the provided service
+func NewSelfUpdateCommand(service *services.SelfUpdateService) *cobra.Command {
+	return createSelfUpdateCommand(service)
+}
+
+// createSelfUpdateCommand creates the cobra command with all flags and validation.
+//
+// Parameters:
+//   - service: Self-update service instance replacing the binary
+//
+// Returns:
+//   - Configured Cobra command ready for execution
+func createSelfUpdateCommand(service *services.SelfUpdateService) *cobra.Command {
+	opts := services.SelfUpdateOptions{}
+	var rollback bool
+
+	cmd := &cobra.Command{
+		Use:   "self-update [flags]",
+		Short: "Update the svz binary to the latest release",
+		Long: "Download the svz release for this platform from the release index, verify its SHA-256 digest and " +
+			"Ed25519 signature, and atomically replace the running binary. The previous binary is kept next to " +
+			"the new one and can be restored with --rollback. Follow the stable or beta --channel, or pin an " +
+			"exact --version to downgrade.",
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return opts.Validate()
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			format, err := utils.OutputFormatFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			if rollback {
+				return service.Rollback(cmd.OutOrStdout(), format)
+			}
+			return service.SelfUpdate(cmd.Context(), cmd.OutOrStdout(), opts, format)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Channel, "channel", providers.ChannelStable, "Release channel to follow (stable or beta)")
+	cmd.Flags().StringVar(&opts.Version, "version", "", "Exact release to install instead of the channel's latest")
+	cmd.Flags().StringVar(&opts.IndexURL, "index-url", "", "Release index location (default "+providers.DefaultReleaseIndexURL+")")
+	cmd.Flags().BoolVar(&opts.CheckOnly, "check", false, "Report the available release without installing it")
+	cmd.Flags().BoolVar(&rollback, "rollback", false, "Restore the binary replaced by the last self-update")
+	cmd.MarkFlagsMutuallyExclusive("rollback", "check")
+	cmd.MarkFlagsMutuallyExclusive("rollback", "version")
+
+	return cmd
+}
@@ -0,0 +1,74 @@
+package selfupdate_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/cli/commands/selfupdate"
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCommand(t *testing.T) {
+	cmd := selfupdate.GetCommand()
+	require.NotNil(t, cmd)
+	require.Equal(t, "self-update [flags]", cmd.Use)
+	require.NotEmpty(t, cmd.Long)
+	require.Same(t, cmd, selfupdate.GetCommand(), "GetCommand should return the same instance")
+}
+
+func TestGetCommandWithService(t *testing.T) {
+	require.Same(t, selfupdate.GetCommand(), selfupdate.GetCommandWithService(nil))
+
+	cmd := selfupdate.GetCommandWithService(services.NewSelfUpdateService(nil))
+	require.NotSame(t, selfupdate.GetCommand(), cmd)
+}
+
+func TestSelfUpdateCommandFlags(t *testing.T) {
+	cmd := selfupdate.NewSelfUpdateCommand(services.NewSelfUpdateService(nil))
+	flags := cmd.Flags()
+
+	for _, name := range []string{"channel", "version", "index-url", "check", "rollback"} {
+		require.NotNil(t, flags.Lookup(name), "missing flag %s", name)
+	}
+	require.Equal(t, "stable", flags.Lookup("channel").DefValue)
+}
+
+func TestSelfUpdateCommand_Rollback(t *testing.T) {
+	exe := filepath.Join(t.TempDir(), "svz")
+	require.NoError(t, os.WriteFile(exe, []byte("new"), 0o755))
+	require.NoError(t, os.WriteFile(exe+".old", []byte("old"), 0o755))
+
+	cmd := selfupdate.NewSelfUpdateCommand(services.NewSelfUpdateService(&services.SelfUpdateServiceOptions{Executable: exe}))
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--rollback"})
+
+	require.NoError(t, cmd.Execute())
+	require.Contains(t, out.String(), "Restored the previous binary")
+	data, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	require.Equal(t, "old", string(data))
+}
+
+func TestSelfUpdateCommand_InvalidArguments(t *testing.T) {
+	tests := map[string][]string{
+		"unknown channel":       {"--channel", "nightly"},
+		"positional argument":   {"1.4.0"},
+		"rollback with check":   {"--rollback", "--check"},
+		"rollback with version": {"--rollback", "--version", "1.4.0"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := selfupdate.NewSelfUpdateCommand(services.NewSelfUpdateService(nil))
+			cmd.SetArgs(args)
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			require.Error(t, cmd.Execute())
+		})
+	}
+}
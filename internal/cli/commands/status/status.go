@@ -0,0 +1,127 @@
+// Package status provides CLI command functionality for querying superviz state on remote hosts
+package status
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/kodflow/superviz.io/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// defaultService holds the singleton status service instance
+	defaultService *services.StatusService
+	// defaultCmd holds the singleton status command instance
+	defaultCmd *cobra.Command
+	// once ensures the default instances are initialized only once
+	once sync.Once
+)
+
+// initDefaults initializes the default service and command instances once.
+//
+// initDefaults creates the singleton instances of the status service and
+// command, ensuring they are created only once for the lifetime of the application.
+func initDefaults() {
+	defaultService = services.NewStatusService(nil)
+	defaultCmd = createStatusCommand(defaultService)
+}
+
+// GetCommand returns the singleton Cobra command for status queries.
+//
+// GetCommand provides access to the default status command instance, initializing
+// it if necessary using sync.Once for thread safety.
+//
+// Returns:
+//   - Cobra command instance configured for status queries
+func GetCommand() *cobra.Command {
+	once.Do(initDefaults)
+	return defaultCmd
+}
+
+// GetCommandWithService returns a Cobra command with a custom status service.
+//
+// GetCommandWithService allows injection of a custom status service while
+// falling back to the singleton command if service is nil.
+//
+// Parameters:
+//   - service: Custom status service instance (nil for default)
+//
+// Returns:
+//   - Cobra command instance with the specified or default service
+func GetCommandWithService(service *services.StatusService) *cobra.Command {
+	if service == nil {
+		return GetCommand()
+	}
+	return NewStatusCommand(service)
+}
+
+// NewStatusCommand creates a new status command with the given service.
+//
+// NewStatusCommand constructs a fresh status command instance with the
+// provided service, bypassing the singleton pattern for testing or special cases.
+//
+// Parameters:
+//   - service: Status service instance to use for the command
+//
+// Returns:
+//   - New Cobra command instance configured with the provided service
+func NewStatusCommand(service *services.StatusService) *cobra.Command {
+	return createStatusCommand(service)
+}
+
+// createStatusCommand creates the cobra command with all flags and validation.
+//
+// Parameters:
+//   - service: Status service instance to query the hosts
+//
+// Returns:
+//   - Configured Cobra command ready for execution
+func createStatusCommand(service *services.StatusService) *cobra.Command {
+	opts := &providers.InstallConfig{
+		Port:    22,
+		Timeout: 60 * time.Second,
+	}
+	var inventory string
+	var repair bool
+	var configs []*providers.InstallConfig
+
+	cmd := &cobra.Command{
+		Use:   "status [user@host] [flags]",
+		Short: "Show superviz state on remote systems",
+		Long: "Report the detected distribution, whether the superviz.io repository and signing key are configured, " +
+			"the installed package version against the repository candidate, and whether the superviz service is running. " +
+			"Query a single user@host or every host of an --inventory file. " +
+			"With --repair, the repository of each host is reconciled first: a missing package source or signing key is configured again.",
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			configs, err = services.ResolveHosts(opts, args, inventory)
+			return err
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			format, err := utils.OutputFormatFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			if repair {
+				return service.Repair(cmd.Context(), cmd.OutOrStdout(), configs, format)
+			}
+			return service.Status(cmd.Context(), cmd.OutOrStdout(), configs, format)
+		},
+	}
+
+	// Configure command flags for SSH connection
+	cmd.Flags().StringVarP(&inventory, "inventory", "I", "", "Inventory file listing the hosts to query")
+	cmd.Flags().BoolVar(&repair, "repair", false, "Configure the repository again where its source or signing key is missing")
+	cmd.Flags().StringVarP(&opts.KeyPath, "ssh-key", "i", "", "Path to SSH private key file")
+	cmd.Flags().IntVarP(&opts.Port, "ssh-port", "p", 22, "SSH port")
+	cmd.Flags().DurationVarP(&opts.Timeout, "timeout", "t", 60*time.Second, "Connection timeout (e.g. 30s, 5m)")
+	cmd.Flags().BoolVar(&opts.SkipHostKeyCheck, "skip-host-key-check", false, "Skip host key verification (development only)")
+	cmd.Flags().StringVarP(&opts.JumpHost, "jump-host", "J", "", "Connect through a bastion host ([user@]host[:port])")
+	cmd.Flags().StringVar(&opts.RepoMirror, "repo-mirror", "", "Repository mirror configured instead of https://repo.superviz.io")
+
+	return cmd
+}
@@ -0,0 +1,60 @@
+package status_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/cli/commands/status"
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCommand(t *testing.T) {
+	cmd := status.GetCommand()
+	require.NotNil(t, cmd)
+	require.Equal(t, "status [user@host] [flags]", cmd.Use)
+	require.NotEmpty(t, cmd.Long)
+	require.Same(t, cmd, status.GetCommand(), "GetCommand should return the same instance")
+}
+
+func TestGetCommandWithService(t *testing.T) {
+	require.Same(t, status.GetCommand(), status.GetCommandWithService(nil))
+
+	cmd := status.GetCommandWithService(services.NewStatusService(nil))
+	require.NotSame(t, status.GetCommand(), cmd)
+}
+
+func TestStatusCommandFlags(t *testing.T) {
+	cmd := status.NewStatusCommand(services.NewStatusService(nil))
+	flags := cmd.Flags()
+
+	for _, name := range []string{"inventory", "ssh-key", "ssh-port", "timeout", "skip-host-key-check", "jump-host", "repo-mirror", "repair"} {
+		require.NotNil(t, flags.Lookup(name), "missing flag %s", name)
+	}
+	require.Equal(t, "I", flags.Lookup("inventory").Shorthand)
+}
+
+func TestStatusCommand_InvalidTargets(t *testing.T) {
+	inventory := filepath.Join(t.TempDir(), "hosts.yaml")
+	require.NoError(t, os.WriteFile(inventory, []byte("hosts:\n  - admin@web-1\n"), 0o600))
+
+	tests := map[string][]string{
+		"missing target":       {},
+		"malformed target":     {"not-a-target"},
+		"target and inventory": {"admin@web-1", "--inventory", inventory},
+		"missing inventory":    {"--inventory", filepath.Join(t.TempDir(), "missing.yaml")},
+		"too many arguments":   {"a@b", "c@d"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := status.NewStatusCommand(services.NewStatusService(nil))
+			cmd.SetArgs(args)
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			require.Error(t, cmd.Execute())
+		})
+	}
+}
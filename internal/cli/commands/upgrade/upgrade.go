@@ -0,0 +1,134 @@
+// Package upgrade provides CLI command functionality for rolling superviz upgrades across hosts
+package upgrade
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/kodflow/superviz.io/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// defaultService holds the singleton upgrade service instance
+	defaultService *services.UpgradeService
+	// defaultCmd holds the singleton upgrade command instance
+	defaultCmd *cobra.Command
+	// once ensures the default instances are initialized only once
+	once sync.Once
+)
+
+// initDefaults initializes the default service and command instances once.
+//
+// initDefaults creates the singleton instances of the upgrade service and
+// command, ensuring they are created only once for the lifetime of the application.
+func initDefaults() {
+	defaultService = services.NewUpgradeService(nil)
+	defaultCmd = createUpgradeCommand(defaultService)
+}
+
+// GetCommand returns the singleton Cobra command for upgrades.
+//
+// GetCommand provides access to the default upgrade command instance, initializing
+// it if necessary using sync.Once for thread safety.
+//
+// Returns:
+//   - Cobra command instance configured for upgrades
+func GetCommand() *cobra.Command {
+	once.Do(initDefaults)
+	return defaultCmd
+}
+
+// GetCommandWithService returns a Cobra command with a custom upgrade service.
+//
+// GetCommandWithService allows injection of a custom upgrade service while
+// falling back to the singleton command if service is nil.
+//
+// Parameters:
+//   - service: Custom upgrade service instance (nil for default)
+//
+// Returns:
+//   - Cobra command instance with the specified or default service
+func GetCommandWithService(service *services.UpgradeService) *cobra.Command {
+	if service == nil {
+		return GetCommand()
+	}
+	return NewUpgradeCommand(service)
+}
+
+// NewUpgradeCommand creates a new upgrade command with the given service.
+//
+// NewUpgradeCommand constructs a fresh upgrade command instance with the
+// provided service, bypassing the singleton pattern for testing or special cases.
+//
+// Parameters:
+//   - service: Upgrade service instance to use for the command
+//
+// Returns:
+//   - New Cobra command instance configured with the provided service
+func NewUpgradeCommand(service *services.UpgradeService) *cobra.Command {
+	return createUpgradeCommand(service)
+}
+
+// createUpgradeCommand creates the cobra command with all flags and validation.
+//
+// Parameters:
+//   - service: Upgrade service instance to upgrade the hosts
+//
+// Returns:
+//   - Configured Cobra command ready for execution
+func createUpgradeCommand(service *services.UpgradeService) *cobra.Command {
+	opts := &providers.InstallConfig{
+		Port:    22,
+		Timeout: 60 * time.Second,
+	}
+	rollout := services.UpgradeOptions{}
+	var inventory string
+	var configs []*providers.InstallConfig
+
+	cmd := &cobra.Command{
+		Use:   "upgrade [user@host] --version VERSION [flags]",
+		Short: "Upgrade superviz on remote systems with a canary rollout",
+		Long: "Upgrade the superviz package to an exact version on a single user@host or every host of an --inventory file. " +
+			"The first --canary hosts are upgraded and health checked before the rest of the fleet follows in waves of " +
+			"--wave-percent hosts. The rollout halts when a canary fails or the failure rate exceeds --max-failure-percent. " +
+			"Each host reports its version before and after the upgrade.",
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := rollout.Validate(); err != nil {
+				return err
+			}
+			var err error
+			configs, err = services.ResolveHosts(opts, args, inventory)
+			return err
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			format, err := utils.OutputFormatFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			return service.Upgrade(cmd.Context(), cmd.OutOrStdout(), configs, rollout, format)
+		},
+	}
+
+	// Configure rollout flags
+	cmd.Flags().StringVar(&rollout.Version, "version", "", "Exact package version to install (e.g. 1.3.0-1)")
+	cmd.Flags().IntVar(&rollout.Canary, "canary", 1, "Number of hosts upgraded and health checked first")
+	cmd.Flags().IntVar(&rollout.WavePercent, "wave-percent", 25, "Share of hosts upgraded per wave after the canary")
+	cmd.Flags().Float64Var(&rollout.MaxFailurePercent, "max-failure-percent", 10, "Halt when the failure rate exceeds this percentage")
+	cmd.Flags().DurationVar(&rollout.HealthTimeout, "health-timeout", 2*time.Minute, "Time allowed for an upgraded host to report healthy")
+	cmd.Flags().DurationVar(&rollout.WavePause, "wave-pause", 0, "Pause between two waves")
+
+	// Configure command flags for SSH connection
+	cmd.Flags().StringVarP(&inventory, "inventory", "I", "", "Inventory file listing the hosts to upgrade")
+	cmd.Flags().StringVarP(&opts.KeyPath, "ssh-key", "i", "", "Path to SSH private key file")
+	cmd.Flags().IntVarP(&opts.Port, "ssh-port", "p", 22, "SSH port")
+	cmd.Flags().DurationVarP(&opts.Timeout, "timeout", "t", 60*time.Second, "Connection timeout (e.g. 30s, 5m)")
+	cmd.Flags().BoolVar(&opts.SkipHostKeyCheck, "skip-host-key-check", false, "Skip host key verification (development only)")
+	cmd.Flags().StringVarP(&opts.JumpHost, "jump-host", "J", "", "Connect through a bastion host ([user@]host[:port])")
+	cmd.Flags().StringVar(&opts.RepoMirror, "repo-mirror", "", "Repository mirror configured instead of https://repo.superviz.io")
+
+	return cmd
+}
@@ -0,0 +1,59 @@
+package upgrade_test
+
+import (
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/cli/commands/upgrade"
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCommand(t *testing.T) {
+	cmd := upgrade.GetCommand()
+	require.NotNil(t, cmd)
+	require.Equal(t, "upgrade [user@host] --version VERSION [flags]", cmd.Use)
+	require.NotEmpty(t, cmd.Long)
+	require.Same(t, cmd, upgrade.GetCommand(), "GetCommand should return the same instance")
+}
+
+func TestGetCommandWithService(t *testing.T) {
+	require.Same(t, upgrade.GetCommand(), upgrade.GetCommandWithService(nil))
+
+	cmd := upgrade.GetCommandWithService(services.NewUpgradeService(nil))
+	require.NotSame(t, upgrade.GetCommand(), cmd)
+}
+
+func TestUpgradeCommandFlags(t *testing.T) {
+	cmd := upgrade.NewUpgradeCommand(services.NewUpgradeService(nil))
+	flags := cmd.Flags()
+
+	for _, name := range []string{
+		"version", "canary", "wave-percent", "max-failure-percent", "health-timeout", "wave-pause",
+		"inventory", "ssh-key", "ssh-port", "timeout", "skip-host-key-check", "jump-host", "repo-mirror",
+	} {
+		require.NotNil(t, flags.Lookup(name), "missing flag %s", name)
+	}
+	require.Equal(t, "1", flags.Lookup("canary").DefValue)
+	require.Equal(t, "25", flags.Lookup("wave-percent").DefValue)
+	require.Equal(t, "10", flags.Lookup("max-failure-percent").DefValue)
+}
+
+func TestUpgradeCommand_InvalidArguments(t *testing.T) {
+	tests := map[string][]string{
+		"missing version":      {"admin@web-1"},
+		"missing target":       {"--version", "1.3.0"},
+		"malformed target":     {"web-1", "--version", "1.3.0"},
+		"invalid wave percent": {"admin@web-1", "--version", "1.3.0", "--wave-percent", "150"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := upgrade.NewUpgradeCommand(services.NewUpgradeService(nil))
+			cmd.SetArgs(args)
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			require.Error(t, cmd.Execute())
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Package verify provides CLI command functionality for checking the files of an integrity manifest
+package verify
+
+import (
+	"sync"
+
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/kodflow/superviz.io/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// defaultService holds the singleton verify service instance
+	defaultService *services.VerifyService
+	// defaultCmd holds the singleton verify command instance
+	defaultCmd *cobra.Command
+	// once ensures the default instances are initialized only once
+	once sync.Once
+)
+
+// initDefaults initializes the default service and command instances once.
+//
+// initDefaults creates the singleton instances of the verify service and
+// command, ensuring they are created only once for the lifetime of the application.
+func initDefaults() {
+	defaultService = services.NewVerifyService()
+	defaultCmd = createVerifyCommand(defaultService)
+}
+
+// GetCommand returns the singleton Cobra command for manifest verification.
+//
+// GetCommand provides access to the default verify command instance, initializing
+// it if necessary using sync.Once for thread safety.
+//
+// Returns:
+//   - Cobra command instance configured for manifest verification
+func GetCommand() *cobra.Command {
+	once.Do(initDefaults)
+	return defaultCmd
+}
+
+// GetCommandWithService returns a Cobra command with a custom verify service.
+//
+// GetCommandWithService allows injection of a custom verify service while
+// falling back to the singleton command if service is nil.
+//
+// Parameters:
+//   - service: Custom verify service instance (nil for default)
+//
+// Returns:
+//   - Cobra command instance with the specified or default service
+func GetCommandWithService(service *services.VerifyService) *cobra.Command {
+	if service == nil {
+		return GetCommand()
+	}
+	return NewVerifyCommand(service)
+}
+
+// NewVerifyCommand creates a new verify command with the given service.
+//
+// NewVerifyCommand constructs a fresh verify command instance with the
+// provided service, bypassing the singleton pattern for testing or special cases.
+//
+// Parameters:
+//   - service: Verify service instance to use for the command
+//
+// Returns:
+//   - New Cobra command instance configured with the provided service
+func NewVerifyCommand(service *services.VerifyService) *cobra.Command {
+	return createVerifyCommand(service)
+}
+
+// createVerifyCommand creates the cobra command with all flags and validation.
+//
+// Parameters:
+//   - service: Verify service instance checking the manifest
+//
+// Returns:
+//   - Configured Cobra command ready for execution
+func createVerifyCommand(service *services.VerifyService) *cobra.Command {
+	var opts services.VerifyOptions
+
+	cmd := &cobra.Command{
+		Use:   "verify <manifest> [flags]",
+		Short: "Check the files listed in an integrity manifest",
+		Long: "Check the SHA-256 or SHA-512 digest of every file listed in an integrity manifest, e.g. in CI before " +
+			"an image is published. Relative paths are resolved against the manifest directory and a glob checks every " +
+			"matching file. With --signature and --public-key, the manifest itself must carry a valid Ed25519 signature. " +
+			"The command fails when the manifest cannot be verified or a file is missing or differs.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := utils.OutputFormatFromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			return service.Verify(cmd.OutOrStdout(), args[0], opts, format)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Signature, "signature", "", "File holding the base64 Ed25519 signature of the manifest")
+	cmd.Flags().StringVar(&opts.PublicKey, "public-key", "", "Base64 Ed25519 public key verifying the signature")
+
+	return cmd
+}
@@ -0,0 +1,73 @@
+package verify_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/cli/commands/verify"
+	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCommand(t *testing.T) {
+	cmd := verify.GetCommand()
+	require.NotNil(t, cmd)
+	require.Equal(t, "verify <manifest> [flags]", cmd.Use)
+	require.NotEmpty(t, cmd.Long)
+	require.Same(t, cmd, verify.GetCommand(), "GetCommand should return the same instance")
+}
+
+func TestGetCommandWithService(t *testing.T) {
+	require.Same(t, verify.GetCommand(), verify.GetCommandWithService(nil))
+
+	cmd := verify.GetCommandWithService(services.NewVerifyService())
+	require.NotSame(t, verify.GetCommand(), cmd)
+}
+
+func TestVerifyCommandFlags(t *testing.T) {
+	cmd := verify.NewVerifyCommand(services.NewVerifyService())
+	flags := cmd.Flags()
+
+	require.NotNil(t, flags.Lookup("signature"))
+	require.NotNil(t, flags.Lookup("public-key"))
+}
+
+func TestVerifyCommand_Manifest(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app"), []byte("app"), 0o600))
+	sum := sha256.Sum256([]byte("app"))
+	manifest := filepath.Join(dir, "manifest.yaml")
+	require.NoError(t, os.WriteFile(manifest, []byte("files: [{path: app, sha256: "+hex.EncodeToString(sum[:])+"}]\n"), 0o600))
+
+	var out bytes.Buffer
+	cmd := verify.NewVerifyCommand(services.NewVerifyService())
+	cmd.SetArgs([]string{manifest})
+	cmd.SetOut(&out)
+	require.NoError(t, cmd.Execute())
+	require.Contains(t, out.String(), "1 checked, 0 failed")
+}
+
+func TestVerifyCommand_InvalidInvocations(t *testing.T) {
+	tests := map[string][]string{
+		"missing manifest": {filepath.Join(t.TempDir(), "missing.yaml")},
+		"no arguments":     {},
+		"extra arguments":  {"a.yaml", "b.yaml"},
+		"signature only":   {"a.yaml", "--signature", "a.yaml.sig"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := verify.NewVerifyCommand(services.NewVerifyService())
+			cmd.SetArgs(args)
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			require.Error(t, cmd.Execute())
+		})
+	}
+}
@@ -2,9 +2,12 @@
 package version
 
 import (
+	"fmt"
 	"sync"
 
+	"github.com/kodflow/superviz.io/internal/providers"
 	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/kodflow/superviz.io/internal/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -33,13 +36,7 @@ var (
 //   - None (initializes global variables)
 func initDefaults() {
 	defaultService = services.NewVersionService(nil)
-	defaultCmd = &cobra.Command{
-		Use:   "version",
-		Short: "Print version information",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return defaultService.DisplayVersion(cmd.OutOrStdout())
-		},
-	}
+	defaultCmd = createVersionCommand(defaultService)
 }
 
 // GetCommand returns the singleton Cobra command for displaying version information.
@@ -105,11 +102,110 @@ func GetCommandWithService(service *services.VersionService) *cobra.Command {
 //   - cmd: *cobra.Command new Cobra command instance configured with the provided service
 func NewVersionCommand(service *services.VersionService) *cobra.Command {
 	// Don't use default service here - use the provided one directly
-	return &cobra.Command{
+	return createVersionCommand(service)
+}
+
+// createVersionCommand creates the cobra command with its update check flags.
+//
+// Parameters:
+//   - service: *services.VersionService version service to use
+//
+// Returns:
+//   - cmd: *cobra.Command configured version command
+func createVersionCommand(service *services.VersionService) *cobra.Command {
+	var check, verbose, sbom bool
+	var channel, sbomFormat string
+
+	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Print version information",
+		Long: "Print the build information of this binary. With --verbose, also show the Go build provenance: " +
+			"dependency module versions, VCS revision and modified flag, build tags, CGO and GOAMD64 level. " +
+			"With --sbom, emit a CycloneDX or SPDX software bill of materials for this exact binary. " +
+			"With --check, query the release index and report whether a newer release exists on the selected " +
+			"--channel, along with the agent versions this CLI supports.",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			if cmd.Flags().Changed("channel") && !check {
+				return fmt.Errorf("--channel requires --check")
+			}
+			if cmd.Flags().Changed("sbom-format") && !sbom {
+				return fmt.Errorf("--sbom-format requires --sbom")
+			}
+			if _, err := providers.ParseSBOMFormat(sbomFormat); err != nil {
+				return err
+			}
+			return services.SelfUpdateOptions{Channel: channel}.Validate()
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return service.DisplayVersion(cmd.OutOrStdout())
+			switch {
+			case check:
+				return runVersionCheck(cmd, service, channel)
+			case sbom:
+				format, _ := providers.ParseSBOMFormat(sbomFormat) //nolint:errcheck // validated in PreRunE
+				return service.WriteSBOM(cmd.OutOrStdout(), format)
+			case verbose:
+				return runVerboseVersion(cmd, service)
+			default:
+				return runVersion(cmd, service)
+			}
 		},
 	}
+
+	cmd.Flags().BoolVar(&check, "check", false, "Report whether a newer release is available")
+	cmd.Flags().StringVar(&channel, "channel", providers.ChannelStable, "Release channel checked by --check (stable or beta)")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Include dependencies and build settings")
+	cmd.Flags().BoolVar(&sbom, "sbom", false, "Emit a software bill of materials for this binary")
+	cmd.Flags().StringVar(&sbomFormat, "sbom-format", string(providers.SBOMCycloneDX), "SBOM standard emitted by --sbom (cyclonedx or spdx)")
+	cmd.MarkFlagsMutuallyExclusive("check", "verbose", "sbom")
+
+	return cmd
+}
+
+// runVersion displays version information in the format selected by --output.
+//
+// Parameters:
+//   - cmd: *cobra.Command command being executed
+//   - service: *services.VersionService version service to use
+//
+// Returns:
+//   - err: error if the output format is invalid or writing fails
+func runVersion(cmd *cobra.Command, service *services.VersionService) error {
+	format, err := utils.OutputFormatFromCommand(cmd)
+	if err != nil {
+		return err
+	}
+	return service.DisplayVersionAs(cmd.OutOrStdout(), format)
+}
+
+// runVersionCheck reports whether a newer release exists in the format selected by --output.
+//
+// Parameters:
+//   - cmd: *cobra.Command command being executed
+//   - service: *services.VersionService version service to use
+//   - channel: string release channel to check
+//
+// Returns:
+//   - err: error if the output format is invalid, the release index is unreachable or writing fails
+func runVersionCheck(cmd *cobra.Command, service *services.VersionService, channel string) error {
+	format, err := utils.OutputFormatFromCommand(cmd)
+	if err != nil {
+		return err
+	}
+	return service.DisplayUpdateCheck(cmd.Context(), cmd.OutOrStdout(), channel, format)
+}
+
+// runVerboseVersion displays version information and build provenance in the format selected by --output.
+//
+// Parameters:
+//   - cmd: *cobra.Command command being executed
+//   - service: *services.VersionService version service to use
+//
+// Returns:
+//   - err: error if the output format is invalid or writing fails
+func runVerboseVersion(cmd *cobra.Command, service *services.VersionService) error {
+	format, err := utils.OutputFormatFromCommand(cmd)
+	if err != nil {
+		return err
+	}
+	return service.DisplayVerboseVersion(cmd.OutOrStdout(), format)
 }
@@ -2,6 +2,7 @@ package version_test
 
 import (
 	"bytes"
+	"context"
 	"strings"
 	"testing"
 
@@ -282,3 +283,102 @@ type mockVersionProvider struct {
 func (m *mockVersionProvider) GetVersionInfo() providers.VersionInfo {
 	return m.info
 }
+
+// fakeReleaseSource returns fixed channel versions.
+type fakeReleaseSource map[string]string
+
+func (f fakeReleaseSource) LatestRelease(_ context.Context, channel string) (string, error) {
+	return f[channel], nil
+}
+
+func TestVersionCommand_Check(t *testing.T) {
+	service := services.NewVersionServiceWithOptions(&services.VersionServiceOptions{
+		Provider: &mockVersionProvider{info: providers.VersionInfo{Version: "1.3.0"}},
+		Releases: fakeReleaseSource{"stable": "1.3.0", "beta": "1.4.0-rc.1"},
+	})
+
+	var buf bytes.Buffer
+	cmd := version.NewVersionCommand(service)
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--check", "--channel", "beta"})
+
+	require.NoError(t, cmd.Execute())
+	require.Equal(t, "svz 1.4.0-rc.1 is available on the beta channel (running 1.3.0), run svz self-update\n"+
+		"Supported agent versions: 1.2.x to 1.4.x\n", buf.String())
+}
+
+func TestVersionCommand_CheckInvalidFlags(t *testing.T) {
+	for name, args := range map[string][]string{
+		"unknown channel":       {"--check", "--channel", "nightly"},
+		"channel without check": {"--channel", "beta"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cmd := version.NewVersionCommand(services.NewVersionService(nil))
+			cmd.SetArgs(args)
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			require.Error(t, cmd.Execute())
+		})
+	}
+}
+
+func newBuildVersionCommand() *cobra.Command {
+	return version.NewVersionCommand(services.NewVersionServiceWithOptions(&services.VersionServiceOptions{
+		Provider: &mockVersionProvider{info: providers.VersionInfo{Version: "1.3.0"}},
+		Build: func() (providers.BuildProvenance, bool) {
+			return providers.BuildProvenance{
+				Main:         providers.Module{Path: "github.com/kodflow/superviz.io", Version: "(devel)"},
+				Dependencies: []providers.Module{{Path: "github.com/spf13/cobra", Version: "v1.9.1"}},
+			}, true
+		},
+	}))
+}
+
+func TestVersionCommand_Verbose(t *testing.T) {
+	var buf bytes.Buffer
+	cmd := newBuildVersionCommand()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--verbose"})
+
+	require.NoError(t, cmd.Execute())
+	require.Contains(t, buf.String(), "Version:       1.3.0\n")
+	require.Contains(t, buf.String(), "Dependencies:\n  github.com/spf13/cobra v1.9.1\n")
+}
+
+func TestVersionCommand_SBOM(t *testing.T) {
+	for format, marker := range map[string]string{"": `"bomFormat": "CycloneDX"`, "spdx": `"spdxVersion": "SPDX-2.3"`} {
+		t.Run(format, func(t *testing.T) {
+			args := []string{"--sbom"}
+			if format != "" {
+				args = append(args, "--sbom-format", format)
+			}
+
+			var buf bytes.Buffer
+			cmd := newBuildVersionCommand()
+			cmd.SetOut(&buf)
+			cmd.SetArgs(args)
+
+			require.NoError(t, cmd.Execute())
+			require.Contains(t, buf.String(), marker)
+			require.Contains(t, buf.String(), "pkg:golang/github.com/spf13/cobra@v1.9.1")
+		})
+	}
+}
+
+func TestVersionCommand_ProvenanceInvalidFlags(t *testing.T) {
+	for name, args := range map[string][]string{
+		"unknown sbom format":      {"--sbom", "--sbom-format", "swid"},
+		"sbom format without flag": {"--sbom-format", "spdx"},
+		"verbose with sbom":        {"--verbose", "--sbom"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cmd := newBuildVersionCommand()
+			cmd.SetArgs(args)
+			cmd.SilenceUsage = true
+			cmd.SilenceErrors = true
+
+			require.Error(t, cmd.Execute())
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ProfileFlag is the name of the global configuration profile flag.
+const ProfileFlag = providers.ProfileKey
+
+// loadCLIConfig loads the configuration layers; replaced in tests.
+var loadCLIConfig = func() (*providers.CLIConfig, error) {
+	return providers.LoadCLIConfig(&providers.CLIConfigOptions{SkipInvalid: true})
+}
+
+// applyCLIConfig fills flags not given on the command line from the
+// configuration files, the selected profile and SVZ_* environment variables.
+//
+// Settings that do not match a flag of cmd are ignored, so one file can hold
+// values for every command. Flags set from configuration are not marked as
+// changed.
+//
+// A configuration file that cannot be read or parsed is skipped with a
+// warning, so that it does not break commands such as version, and the other
+// layers still apply. It is an error when a profile was selected explicitly
+// with --profile or SVZ_PROFILE, since the profile may live in that file.
+//
+// Parameters:
+//   - cmd: *cobra.Command command being executed
+//
+// Returns:
+//   - err: error if configuration cannot be loaded, a file is invalid while a
+//     profile is selected explicitly, the profile is unknown or a value is
+//     invalid for its flag
+func applyCLIConfig(cmd *cobra.Command) error {
+	config, err := loadCLIConfig()
+	if err != nil {
+		return err
+	}
+
+	profile := config.Profile()
+	explicit := config.EnvProfile() != ""
+	if flag := cmd.Flags().Lookup(ProfileFlag); flag != nil && flag.Changed {
+		profile = flag.Value.String()
+		explicit = true
+	}
+
+	if skipped := config.Skipped(); len(skipped) > 0 {
+		if explicit {
+			return fmt.Errorf("cannot apply profile %q: %w", profile, errors.Join(skipped...))
+		}
+		for _, err := range skipped {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: ignoring CLI configuration: %v\n", err) //nolint:errcheck // output is best effort
+		}
+	}
+
+	settings, err := config.Settings(profile)
+	if err != nil {
+		return err
+	}
+
+	var applyErr error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if applyErr != nil || flag.Changed || flag.Name == ProfileFlag {
+			return
+		}
+		setting, ok := settings[flag.Name]
+		if !ok {
+			return
+		}
+		if err := flag.Value.Set(setting.Value); err != nil {
+			applyErr = fmt.Errorf("invalid value %q for --%s from %s: %w", setting.Value, flag.Name, setting.Source, err)
+		}
+	})
+	return applyErr
+}
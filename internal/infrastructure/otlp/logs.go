@@ -0,0 +1,61 @@
+// internal/infrastructure/otlp/logs.go - OTLP logs data model
+package otlp
+
+// Severity is the OTLP severity number of a log record.
+type Severity int
+
+// Base severity numbers, each level spans four numbers.
+const (
+	// SeverityUnspecified is used when the level is unknown
+	SeverityUnspecified Severity = 0
+	// SeverityTrace is the most verbose level
+	SeverityTrace Severity = 1
+	// SeverityDebug is for debugging
+	SeverityDebug Severity = 5
+	// SeverityInfo is for informational messages
+	SeverityInfo Severity = 9
+	// SeverityWarn is for warnings
+	SeverityWarn Severity = 13
+	// SeverityError is for errors
+	SeverityError Severity = 17
+	// SeverityFatal is for fatal errors
+	SeverityFatal Severity = 21
+)
+
+// LogsData is the body of a logs export request.
+type LogsData struct {
+	// ResourceLogs groups the records by resource
+	ResourceLogs []ResourceLogs `json:"resourceLogs"`
+}
+
+// ResourceLogs holds the records of one resource.
+type ResourceLogs struct {
+	// Resource is the producer of the records
+	Resource Resource `json:"resource"`
+	// ScopeLogs groups the records by instrumentation scope
+	ScopeLogs []ScopeLogs `json:"scopeLogs"`
+}
+
+// ScopeLogs holds the records of one instrumentation scope.
+type ScopeLogs struct {
+	// Scope is the instrumentation
+	Scope Scope `json:"scope"`
+	// LogRecords are the records
+	LogRecords []LogRecord `json:"logRecords"`
+}
+
+// LogRecord is one log entry.
+type LogRecord struct {
+	// TimeUnixNano is when the event occurred, see UnixNano
+	TimeUnixNano string `json:"timeUnixNano"`
+	// ObservedTimeUnixNano is when the record was collected
+	ObservedTimeUnixNano string `json:"observedTimeUnixNano"`
+	// SeverityNumber is the normalized level
+	SeverityNumber Severity `json:"severityNumber,omitempty"`
+	// SeverityText is the level as written by the producer
+	SeverityText string `json:"severityText,omitempty"`
+	// Body is the message
+	Body AnyValue `json:"body"`
+	// Attributes are the structured fields of the record
+	Attributes []KeyValue `json:"attributes,omitempty"`
+}
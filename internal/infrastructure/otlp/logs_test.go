@@ -0,0 +1,42 @@
+package otlp
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogsData_JSON(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := LogsData{ResourceLogs: []ResourceLogs{{
+		Resource: Resource{Attributes: []KeyValue{Attribute("service.name", "web")}},
+		ScopeLogs: []ScopeLogs{{
+			Scope: Scope{Name: "superviz"},
+			LogRecords: []LogRecord{{
+				TimeUnixNano:         UnixNano(at),
+				ObservedTimeUnixNano: UnixNano(at),
+				SeverityNumber:       SeverityWarn,
+				SeverityText:         "warn",
+				Body:                 StringValue("disk almost full"),
+				Attributes:           []KeyValue{Attribute("process.pid", 42)},
+			}},
+		}},
+	}}}
+
+	encoded, err := json.Marshal(data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"resourceLogs":[{
+		"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"web"}}]},
+		"scopeLogs":[{"scope":{"name":"superviz"},"logRecords":[{
+			"timeUnixNano":"1767225600000000000",
+			"observedTimeUnixNano":"1767225600000000000",
+			"severityNumber":13,
+			"severityText":"warn",
+			"body":{"stringValue":"disk almost full"},
+			"attributes":[{"key":"process.pid","value":{"intValue":"42"}}]
+		}]}]
+	}]}`, string(encoded))
+}
@@ -0,0 +1,77 @@
+// internal/infrastructure/otlp/metrics.go - OTLP metrics data model
+package otlp
+
+// AggregationTemporality tells how the points of a sum relate to each other.
+type AggregationTemporality int
+
+// Aggregation temporalities.
+const (
+	// TemporalityDelta points cover the time since the previous point
+	TemporalityDelta AggregationTemporality = 1
+	// TemporalityCumulative points cover the time since the start time
+	TemporalityCumulative AggregationTemporality = 2
+)
+
+// MetricsData is the body of a metrics export request.
+type MetricsData struct {
+	// ResourceMetrics groups the metrics by resource
+	ResourceMetrics []ResourceMetrics `json:"resourceMetrics"`
+}
+
+// ResourceMetrics holds the metrics of one resource.
+type ResourceMetrics struct {
+	// Resource is the producer of the metrics
+	Resource Resource `json:"resource"`
+	// ScopeMetrics groups the metrics by instrumentation scope
+	ScopeMetrics []ScopeMetrics `json:"scopeMetrics"`
+}
+
+// ScopeMetrics holds the metrics of one instrumentation scope.
+type ScopeMetrics struct {
+	// Scope is the instrumentation
+	Scope Scope `json:"scope"`
+	// Metrics are the metrics
+	Metrics []Metric `json:"metrics"`
+}
+
+// Metric is one named metric, holding either a gauge or a sum.
+type Metric struct {
+	// Name is the metric name
+	Name string `json:"name"`
+	// Description tells what the metric measures
+	Description string `json:"description,omitempty"`
+	// Unit is the UCUM unit of the values
+	Unit string `json:"unit,omitempty"`
+	// Gauge holds sampled values
+	Gauge *Gauge `json:"gauge,omitempty"`
+	// Sum holds counted values
+	Sum *Sum `json:"sum,omitempty"`
+}
+
+// Gauge is a metric whose points are sampled values.
+type Gauge struct {
+	// DataPoints are the values
+	DataPoints []NumberDataPoint `json:"dataPoints"`
+}
+
+// Sum is a metric whose points are sums.
+type Sum struct {
+	// DataPoints are the values
+	DataPoints []NumberDataPoint `json:"dataPoints"`
+	// AggregationTemporality tells whether the points are deltas or totals
+	AggregationTemporality AggregationTemporality `json:"aggregationTemporality"`
+	// IsMonotonic is true for values that only increase
+	IsMonotonic bool `json:"isMonotonic"`
+}
+
+// NumberDataPoint is one value of a metric.
+type NumberDataPoint struct {
+	// Attributes tell the series of the point apart
+	Attributes []KeyValue `json:"attributes,omitempty"`
+	// StartTimeUnixNano is when a cumulative sum started counting, see UnixNano
+	StartTimeUnixNano string `json:"startTimeUnixNano,omitempty"`
+	// TimeUnixNano is when the value was observed
+	TimeUnixNano string `json:"timeUnixNano"`
+	// AsDouble is the value
+	AsDouble float64 `json:"asDouble"`
+}
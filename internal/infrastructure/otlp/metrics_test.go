@@ -0,0 +1,55 @@
+package otlp
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsData_JSON(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := start.Add(time.Second)
+	data := MetricsData{ResourceMetrics: []ResourceMetrics{{
+		Resource: Resource{Attributes: []KeyValue{Attribute("service.name", "superviz")}},
+		ScopeMetrics: []ScopeMetrics{{
+			Scope: Scope{Name: "superviz"},
+			Metrics: []Metric{
+				{Name: "up", Gauge: &Gauge{DataPoints: []NumberDataPoint{{
+					Attributes:   []KeyValue{Attribute("service", "web")},
+					TimeUnixNano: UnixNano(at),
+					AsDouble:     1,
+				}}}},
+				{Name: "restarts", Unit: "1", Sum: &Sum{
+					DataPoints: []NumberDataPoint{{
+						StartTimeUnixNano: UnixNano(start),
+						TimeUnixNano:      UnixNano(at),
+						AsDouble:          3,
+					}},
+					AggregationTemporality: TemporalityCumulative,
+					IsMonotonic:            true,
+				}},
+			},
+		}},
+	}}}
+
+	encoded, err := json.Marshal(data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"resourceMetrics":[{
+		"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"superviz"}}]},
+		"scopeMetrics":[{"scope":{"name":"superviz"},"metrics":[
+			{"name":"up","gauge":{"dataPoints":[{
+				"attributes":[{"key":"service","value":{"stringValue":"web"}}],
+				"timeUnixNano":"1767225601000000000",
+				"asDouble":1
+			}]}},
+			{"name":"restarts","unit":"1","sum":{"dataPoints":[{
+				"startTimeUnixNano":"1767225600000000000",
+				"timeUnixNano":"1767225601000000000",
+				"asDouble":3
+			}],"aggregationTemporality":2,"isMonotonic":true}}
+		]}]
+	}]}`, string(encoded))
+}
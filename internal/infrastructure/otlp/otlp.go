@@ -0,0 +1,307 @@
+// internal/infrastructure/otlp/otlp.go - OTLP/HTTP exporter with the JSON encoding
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout bounds one export request
+	DefaultTimeout = 10 * time.Second
+	// maxErrorBody is the part of a rejected request's response quoted in the error
+	maxErrorBody = 512
+)
+
+// Signals exported over OTLP, each posted to /v1/<signal>.
+const (
+	// SignalLogs is the logs signal
+	SignalLogs = "logs"
+	// SignalMetrics is the metrics signal
+	SignalMetrics = "metrics"
+	// SignalTraces is the traces signal
+	SignalTraces = "traces"
+)
+
+// ClientOptions configures a Client.
+type ClientOptions struct {
+	// Endpoint is the collector base URL such as http://collector:4318, to which
+	// /v1/<signal> is appended, or a signal URL when it has a path
+	Endpoint string
+	// Headers are added to every request, e.g. for authentication
+	Headers map[string]string
+	// Timeout bounds each request, default 10s
+	Timeout time.Duration
+	// HTTPClient sends the requests, default a client with Timeout
+	HTTPClient *http.Client
+}
+
+// Client posts OTLP payloads encoded as JSON to a collector.
+type Client struct {
+	// base is the endpoint without a path, empty when url is a signal URL
+	base string
+	// url is the signal URL given as endpoint
+	url string
+	// headers are added to every request
+	headers map[string]string
+	// http sends the requests
+	http *http.Client
+}
+
+// NewClient creates an OTLP/HTTP client.
+//
+// Parameters:
+//   - opts: *ClientOptions collector settings
+//
+// Returns:
+//   - client: *Client ready to export
+//   - err: error if the endpoint is not an http or https URL
+func NewClient(opts *ClientOptions) (*Client, error) {
+	if opts == nil {
+		return nil, errors.New("otlp client options cannot be nil")
+	}
+	endpoint, err := url.Parse(opts.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q: must be an http or https URL", opts.Endpoint)
+	}
+
+	c := &Client{headers: opts.Headers, http: opts.HTTPClient}
+	if strings.Trim(endpoint.Path, "/") == "" {
+		c.base = strings.TrimSuffix(endpoint.String(), "/")
+	} else {
+		c.url = endpoint.String()
+	}
+	if c.http == nil {
+		timeout := opts.Timeout
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		c.http = &http.Client{Timeout: timeout}
+	}
+	return c, nil
+}
+
+// URL returns where a signal is posted.
+//
+// Parameters:
+//   - signal: string SignalLogs, SignalMetrics or SignalTraces
+//
+// Returns:
+//   - url: string base endpoint followed by /v1/<signal>, or the signal URL
+func (c *Client) URL(signal string) string {
+	if c.url != "" {
+		return c.url
+	}
+	return c.base + "/v1/" + signal
+}
+
+// Export posts a payload such as LogsData to the URL of its signal.
+//
+// Parameters:
+//   - ctx: context.Context for cancellation
+//   - signal: string SignalLogs, SignalMetrics or SignalTraces
+//   - payload: any export request encoded as JSON
+//
+// Returns:
+//   - err: error if the request fails or the collector does not answer 2xx
+func (c *Client) Export(ctx context.Context, signal string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode OTLP %s: %w", signal, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL(signal), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to export OTLP %s: %w", signal, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export OTLP %s: %w", signal, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // only read
+	if resp.StatusCode/100 != 2 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody)) //nolint:errcheck // best effort detail
+		return fmt.Errorf("failed to export OTLP %s: collector answered %s: %s", signal, resp.Status, strings.TrimSpace(string(data)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body) //nolint:errcheck // drained for connection reuse
+	return nil
+}
+
+// AnyValue is an attribute or body value; exactly one field is set.
+type AnyValue struct {
+	// StringValue is a string
+	StringValue *string `json:"stringValue,omitempty"`
+	// BoolValue is a boolean
+	BoolValue *bool `json:"boolValue,omitempty"`
+	// IntValue is a 64-bit integer, encoded as a decimal string
+	IntValue *string `json:"intValue,omitempty"`
+	// DoubleValue is a floating point number
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	// ArrayValue is a list of values
+	ArrayValue *ArrayValue `json:"arrayValue,omitempty"`
+	// KvlistValue is a nested set of attributes
+	KvlistValue *KeyValueList `json:"kvlistValue,omitempty"`
+}
+
+// ArrayValue is a list of values.
+type ArrayValue struct {
+	// Values are the elements
+	Values []AnyValue `json:"values"`
+}
+
+// KeyValueList is a nested set of attributes.
+type KeyValueList struct {
+	// Values are the attributes
+	Values []KeyValue `json:"values"`
+}
+
+// KeyValue is a named attribute.
+type KeyValue struct {
+	// Key is the attribute name
+	Key string `json:"key"`
+	// Value is the attribute value
+	Value AnyValue `json:"value"`
+}
+
+// Resource describes the entity producing the telemetry.
+type Resource struct {
+	// Attributes identify the resource, e.g. service.name
+	Attributes []KeyValue `json:"attributes"`
+}
+
+// Scope names the instrumentation producing the telemetry.
+type Scope struct {
+	// Name is the instrumentation name
+	Name string `json:"name"`
+	// Version is the instrumentation version
+	Version string `json:"version,omitempty"`
+}
+
+// StringValue returns a string value.
+//
+// Parameters:
+//   - value: string content
+//
+// Returns:
+//   - value: AnyValue holding the string
+func StringValue(value string) AnyValue {
+	return AnyValue{StringValue: &value}
+}
+
+// IntValue returns an integer value.
+//
+// Parameters:
+//   - value: int64 content
+//
+// Returns:
+//   - value: AnyValue holding the integer
+func IntValue(value int64) AnyValue {
+	s := strconv.FormatInt(value, 10)
+	return AnyValue{IntValue: &s}
+}
+
+// Value converts a decoded JSON value or a Go scalar to an AnyValue.
+//
+// Integers, json.Number holding an integer included, become int values;
+// maps become key-value lists sorted by key; nil and unknown types become
+// their string form.
+//
+// Parameters:
+//   - value: any string, bool, number, json.Number, []any or map[string]any
+//
+// Returns:
+//   - value: AnyValue encoding value
+func Value(value any) AnyValue {
+	switch v := value.(type) {
+	case string:
+		return StringValue(v)
+	case bool:
+		return AnyValue{BoolValue: &v}
+	case int:
+		return IntValue(int64(v))
+	case int64:
+		return IntValue(v)
+	case float64:
+		return AnyValue{DoubleValue: &v}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return IntValue(n)
+		}
+		if f, err := v.Float64(); err == nil {
+			return AnyValue{DoubleValue: &f}
+		}
+		return StringValue(v.String())
+	case []any:
+		values := make([]AnyValue, 0, len(v))
+		for _, item := range v {
+			values = append(values, Value(item))
+		}
+		return AnyValue{ArrayValue: &ArrayValue{Values: values}}
+	case map[string]any:
+		return AnyValue{KvlistValue: &KeyValueList{Values: Attributes(v)}}
+	case nil:
+		return StringValue("")
+	default:
+		return StringValue(fmt.Sprint(v))
+	}
+}
+
+// Attributes converts a map to attributes sorted by key.
+//
+// Parameters:
+//   - values: map[string]any attribute values, see Value
+//
+// Returns:
+//   - attributes: []KeyValue sorted by key
+func Attributes(values map[string]any) []KeyValue {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	attributes := make([]KeyValue, 0, len(keys))
+	for _, key := range keys {
+		attributes = append(attributes, KeyValue{Key: key, Value: Value(values[key])})
+	}
+	return attributes
+}
+
+// Attribute returns a named attribute.
+//
+// Parameters:
+//   - key: string attribute name
+//   - value: any attribute value, see Value
+//
+// Returns:
+//   - attribute: KeyValue
+func Attribute(key string, value any) KeyValue {
+	return KeyValue{Key: key, Value: Value(value)}
+}
+
+// UnixNano encodes a time as the decimal string of its Unix nanoseconds.
+//
+// Parameters:
+//   - t: time.Time instant
+//
+// Returns:
+//   - nanos: string such as "1767225600000000000", "0" for the zero time
+func UnixNano(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,98 @@
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClient_URL(t *testing.T) {
+	tests := map[string]string{
+		"http://collector:4318":          "http://collector:4318/v1/logs",
+		"http://collector:4318/":         "http://collector:4318/v1/logs",
+		"https://otel.example.com/v1/in": "https://otel.example.com/v1/in",
+	}
+	for endpoint, want := range tests {
+		client, err := NewClient(&ClientOptions{Endpoint: endpoint})
+		require.NoError(t, err, endpoint)
+		assert.Equal(t, want, client.URL(SignalLogs), endpoint)
+	}
+
+	for _, endpoint := range []string{"", "collector:4318", "ftp://collector", "http://"} {
+		_, err := NewClient(&ClientOptions{Endpoint: endpoint})
+		assert.Error(t, err, endpoint)
+	}
+	_, err := NewClient(nil)
+	assert.Error(t, err)
+}
+
+func TestClient_Export(t *testing.T) {
+	var got struct {
+		path, contentType, auth string
+		body                    map[string]any
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.path, got.contentType, got.auth = r.URL.Path, r.Header.Get("Content-Type"), r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &got.body)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&ClientOptions{Endpoint: server.URL, Headers: map[string]string{"Authorization": "Bearer t"}})
+	require.NoError(t, err)
+	require.NoError(t, client.Export(context.Background(), SignalMetrics, map[string]any{"resourceMetrics": []any{}}))
+
+	assert.Equal(t, "/v1/metrics", got.path)
+	assert.Equal(t, "application/json", got.contentType)
+	assert.Equal(t, "Bearer t", got.auth)
+	assert.Contains(t, got.body, "resourceMetrics")
+}
+
+func TestClient_ExportRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad payload", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&ClientOptions{Endpoint: server.URL})
+	require.NoError(t, err)
+	err = client.Export(context.Background(), SignalLogs, LogsData{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400 Bad Request: bad payload")
+
+	server.Close()
+	err = client.Export(context.Background(), SignalLogs, LogsData{})
+	assert.ErrorContains(t, err, "failed to export OTLP logs")
+}
+
+func TestValue(t *testing.T) {
+	encode := func(value any) string {
+		data, err := json.Marshal(Value(value))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	assert.JSONEq(t, `{"stringValue":"x"}`, encode("x"))
+	assert.JSONEq(t, `{"boolValue":false}`, encode(false))
+	assert.JSONEq(t, `{"intValue":"42"}`, encode(42))
+	assert.JSONEq(t, `{"intValue":"7"}`, encode(json.Number("7")))
+	assert.JSONEq(t, `{"doubleValue":1.5}`, encode(json.Number("1.5")))
+	assert.JSONEq(t, `{"doubleValue":0.25}`, encode(0.25))
+	assert.JSONEq(t, `{"stringValue":""}`, encode(nil))
+	assert.JSONEq(t, `{"arrayValue":{"values":[{"intValue":"1"},{"stringValue":"a"}]}}`, encode([]any{1, "a"}))
+	assert.JSONEq(t, `{"kvlistValue":{"values":[{"key":"a","value":{"intValue":"1"}},{"key":"b","value":{"stringValue":"x"}}]}}`,
+		encode(map[string]any{"b": "x", "a": 1}))
+	assert.JSONEq(t, `{"stringValue":"1s"}`, encode(time.Second))
+}
+
+func TestUnixNano(t *testing.T) {
+	assert.Equal(t, "0", UnixNano(time.Time{}))
+	assert.Equal(t, "1767225600000000001", UnixNano(time.Date(2026, 1, 1, 0, 0, 0, 1, time.UTC)))
+}
@@ -0,0 +1,92 @@
+// internal/infrastructure/otlp/traces.go - OTLP traces data model
+package otlp
+
+// SpanKind tells the role of a span in a trace.
+type SpanKind int
+
+// Span kinds.
+const (
+	// SpanKindInternal is an operation within the process
+	SpanKindInternal SpanKind = 1
+	// SpanKindServer handles a remote request
+	SpanKindServer SpanKind = 2
+	// SpanKindClient calls a remote service
+	SpanKindClient SpanKind = 3
+)
+
+// StatusCode is the outcome of a span.
+type StatusCode int
+
+// Status codes.
+const (
+	// StatusUnset is the default, the operation completed without a known error
+	StatusUnset StatusCode = 0
+	// StatusOK marks an operation explicitly validated as successful
+	StatusOK StatusCode = 1
+	// StatusError marks a failed operation
+	StatusError StatusCode = 2
+)
+
+// TracesData is the body of a traces export request.
+type TracesData struct {
+	// ResourceSpans groups the spans by resource
+	ResourceSpans []ResourceSpans `json:"resourceSpans"`
+}
+
+// ResourceSpans holds the spans of one resource.
+type ResourceSpans struct {
+	// Resource is the producer of the spans
+	Resource Resource `json:"resource"`
+	// ScopeSpans groups the spans by instrumentation scope
+	ScopeSpans []ScopeSpans `json:"scopeSpans"`
+}
+
+// ScopeSpans holds the spans of one instrumentation scope.
+type ScopeSpans struct {
+	// Scope is the instrumentation
+	Scope Scope `json:"scope"`
+	// Spans are the spans
+	Spans []Span `json:"spans"`
+}
+
+// Span is one timed operation of a trace.
+type Span struct {
+	// TraceID is the hex encoded 16 byte identifier of the trace
+	TraceID string `json:"traceId"`
+	// SpanID is the hex encoded 8 byte identifier of the span
+	SpanID string `json:"spanId"`
+	// ParentSpanID is the identifier of the parent span, empty for a root span
+	ParentSpanID string `json:"parentSpanId,omitempty"`
+	// Name describes the operation
+	Name string `json:"name"`
+	// Kind is the role of the span
+	Kind SpanKind `json:"kind,omitempty"`
+	// StartTimeUnixNano is when the operation started, see UnixNano
+	StartTimeUnixNano string `json:"startTimeUnixNano"`
+	// EndTimeUnixNano is when the operation ended
+	EndTimeUnixNano string `json:"endTimeUnixNano"`
+	// Attributes describe the operation
+	Attributes []KeyValue `json:"attributes,omitempty"`
+	// Events are the points in time recorded during the operation
+	Events []SpanEvent `json:"events,omitempty"`
+	// Status is the outcome
+	Status Status `json:"status"`
+}
+
+// SpanEvent is a named point in time of a span.
+type SpanEvent struct {
+	// TimeUnixNano is when the event occurred
+	TimeUnixNano string `json:"timeUnixNano"`
+	// Name describes the event
+	Name string `json:"name"`
+	// Attributes describe the event
+	Attributes []KeyValue `json:"attributes,omitempty"`
+}
+
+// Status is the outcome of a span.
+type Status struct {
+	// Code is unset, ok or error
+	Code StatusCode `json:"code,omitempty"`
+	// Message describes the error
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,55 @@
+package otlp
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracesData_JSON(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Second)
+	data := TracesData{ResourceSpans: []ResourceSpans{{
+		Resource: Resource{Attributes: []KeyValue{Attribute("service.name", "svz")}},
+		ScopeSpans: []ScopeSpans{{
+			Scope: Scope{Name: "superviz"},
+			Spans: []Span{{
+				TraceID:           "0102030405060708090a0b0c0d0e0f10",
+				SpanID:            "0102030405060708",
+				ParentSpanID:      "1112131415161718",
+				Name:              "ssh.execute",
+				Kind:              SpanKindClient,
+				StartTimeUnixNano: UnixNano(start),
+				EndTimeUnixNano:   UnixNano(end),
+				Attributes:        []KeyValue{Attribute("ssh.command", "true")},
+				Events:            []SpanEvent{{TimeUnixNano: UnixNano(end), Name: "exception"}},
+				Status:            Status{Code: StatusError, Message: "exit status 1"},
+			}},
+		}},
+	}}}
+
+	encoded, err := json.Marshal(data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"resourceSpans":[{
+		"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"svz"}}]},
+		"scopeSpans":[{"scope":{"name":"superviz"},"spans":[{
+			"traceId":"0102030405060708090a0b0c0d0e0f10",
+			"spanId":"0102030405060708",
+			"parentSpanId":"1112131415161718",
+			"name":"ssh.execute",
+			"kind":3,
+			"startTimeUnixNano":"1767225600000000000",
+			"endTimeUnixNano":"1767225601000000000",
+			"attributes":[{"key":"ssh.command","value":{"stringValue":"true"}}],
+			"events":[{"timeUnixNano":"1767225601000000000","name":"exception"}],
+			"status":{"code":2,"message":"exit status 1"}
+		}]}]
+	}]}`, string(encoded))
+
+	encoded, err = json.Marshal(Span{Name: "ok"})
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"status":{}`, "an unset status is an empty object")
+}
@@ -77,6 +77,56 @@ func Detect() (Manager, error) {
 	return nil, fmt.Errorf("unable to detect package manager")
 }
 
+// ForDistro returns the package manager used by a distribution.
+//
+// ForDistro is used when the distribution was detected remotely, e.g. over SSH,
+// and the local environment must not be inspected.
+//
+// Parameters:
+//   - distro: Distribution ID as found in /etc/os-release (e.g. "ubuntu", "alpine")
+//
+// Returns:
+//   - Manager instance for the distribution
+//   - Error if the distribution is not supported
+func ForDistro(distro string) (Manager, error) {
+	bin, ok := distroToPkgManager[distro]
+	if !ok {
+		return nil, fmt.Errorf("unsupported distribution: %s", distro)
+	}
+	return DetectFromBin(bin)
+}
+
+// PackageSpec returns the install argument selecting a specific package version.
+//
+// The result is meant to be passed to Manager.Install. Pacman repositories
+// serve a single version, so the plain package name is returned and callers
+// must verify the installed version afterwards.
+//
+// Parameters:
+//   - m: Manager the argument is built for
+//   - pkg: Package name
+//   - version: Exact version as published in the repository (e.g. "1.3.0-1")
+//
+// Returns:
+//   - Install argument such as "superviz=1.3.0-1" or "superviz-1.3.0"
+//   - Error if the version is malformed or pinning is not supported
+func PackageSpec(m Manager, pkg, version string) (string, error) {
+	if version == "" || strings.Trim(version, "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.+~:_-") != "" {
+		return "", fmt.Errorf("invalid package version: %q", version)
+	}
+
+	switch m.Name() {
+	case "apt", "apk", "zypper":
+		return pkg + "=" + version, nil
+	case "dnf", "yum":
+		return pkg + "-" + version, nil
+	case "pacman":
+		return pkg, nil
+	default:
+		return "", fmt.Errorf("installing a specific version is not supported with %s", m.Name())
+	}
+}
+
 // DetectFromBin returns a Manager instance based on the binary name.
 //
 // Parameters:
@@ -67,3 +67,66 @@ func TestDetectFromBin_UnsupportedBinary(t *testing.T) {
 		})
 	}
 }
+
+func TestForDistro(t *testing.T) {
+	testCases := []struct {
+		distro   string
+		expected string
+	}{
+		{"ubuntu", "apt"},
+		{"debian", "apt"},
+		{"alpine", "apk"},
+		{"centos", "yum"},
+		{"fedora", "dnf"},
+		{"arch", "pacman"},
+		{"opensuse", "zypper"},
+		{"gentoo", "emerge"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.distro, func(t *testing.T) {
+			mgr, err := pkgmanager.ForDistro(tc.distro)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, mgr.Name())
+		})
+	}
+
+	mgr, err := pkgmanager.ForDistro("unknown")
+	assert.Error(t, err)
+	assert.Nil(t, mgr)
+}
+
+func TestPackageSpec(t *testing.T) {
+	testCases := []struct {
+		bin      string
+		expected string
+	}{
+		{"apt", "superviz=1.3.0-1"},
+		{"apk", "superviz=1.3.0-1"},
+		{"zypper", "superviz=1.3.0-1"},
+		{"dnf", "superviz-1.3.0-1"},
+		{"yum", "superviz-1.3.0-1"},
+		{"pacman", "superviz"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.bin, func(t *testing.T) {
+			mgr, err := pkgmanager.DetectFromBin(tc.bin)
+			assert.NoError(t, err)
+
+			spec, err := pkgmanager.PackageSpec(mgr, "superviz", "1.3.0-1")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, spec)
+		})
+	}
+
+	emerge, _ := pkgmanager.DetectFromBin("emerge")
+	_, err := pkgmanager.PackageSpec(emerge, "superviz", "1.3.0")
+	assert.ErrorContains(t, err, "not supported with emerge")
+
+	apt, _ := pkgmanager.DetectFromBin("apt")
+	for _, version := range []string{"", "1.0;reboot", "1.0 2.0", "$(id)"} {
+		_, err := pkgmanager.PackageSpec(apt, "superviz", version)
+		assert.ErrorContains(t, err, "invalid package version", version)
+	}
+}
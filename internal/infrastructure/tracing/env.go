@@ -0,0 +1,114 @@
+// internal/infrastructure/tracing/env.go - Tracer configuration from the OpenTelemetry environment variables
+package tracing
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FromEnv creates a tracer from the standard OpenTelemetry variables.
+//
+// Tracing is enabled by OTEL_EXPORTER_OTLP_ENDPOINT, the collector base URL
+// to which /v1/traces is appended, or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT,
+// used as is. OTEL_EXPORTER_OTLP_HEADERS, OTEL_EXPORTER_OTLP_TIMEOUT (in
+// milliseconds), their TRACES variants, OTEL_SERVICE_NAME and
+// OTEL_RESOURCE_ATTRIBUTES are honored. OTEL_SDK_DISABLED=true or
+// OTEL_TRACES_EXPORTER=none disable tracing. Only the http/json protocol is
+// supported.
+//
+// Parameters:
+//   - getenv: func(string) string such as os.Getenv
+//   - report: func(error) receiving the export failures, may be nil
+//
+// Returns:
+//   - tracer: *Tracer exporting the spans, nil when tracing is not configured
+//   - err: error if a variable is invalid
+func FromEnv(getenv func(string) string, report func(error)) (*Tracer, error) {
+	if strings.EqualFold(getenv("OTEL_SDK_DISABLED"), "true") || getenv("OTEL_TRACES_EXPORTER") == "none" {
+		return nil, nil
+	}
+	endpoint := getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		base := getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		if base == "" {
+			return nil, nil
+		}
+		endpoint = strings.TrimRight(base, "/") + "/v1/traces"
+	}
+
+	protocol := signalEnv(getenv, "PROTOCOL")
+	if protocol != "" && protocol != "http/json" {
+		return nil, fmt.Errorf("unsupported OTLP protocol %q: only http/json is supported", protocol)
+	}
+
+	headers, err := parsePairs(getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_HEADERS: %w", err)
+	}
+	traceHeaders, err := parsePairs(getenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_TRACES_HEADERS: %w", err)
+	}
+	for key, value := range traceHeaders {
+		headers[key] = value
+	}
+
+	var timeout time.Duration
+	if value := signalEnv(getenv, "TIMEOUT"); value != "" {
+		ms, err := strconv.Atoi(value)
+		if err != nil || ms < 0 {
+			return nil, fmt.Errorf("invalid OTLP timeout %q: must be milliseconds", value)
+		}
+		timeout = time.Duration(ms) * time.Millisecond
+	}
+
+	attributes, err := parsePairs(getenv("OTEL_RESOURCE_ATTRIBUTES"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTEL_RESOURCE_ATTRIBUTES: %w", err)
+	}
+	resource := make(map[string]any, len(attributes))
+	for key, value := range attributes {
+		resource[key] = value
+	}
+
+	return New(&Options{
+		Endpoint:    endpoint,
+		Headers:     headers,
+		Timeout:     timeout,
+		ServiceName: getenv("OTEL_SERVICE_NAME"),
+		Resource:    resource,
+		Report:      report,
+	})
+}
+
+// signalEnv returns OTEL_EXPORTER_OTLP_TRACES_<name>, or OTEL_EXPORTER_OTLP_<name> when unset
+func signalEnv(getenv func(string) string, name string) string {
+	if value := getenv("OTEL_EXPORTER_OTLP_TRACES_" + name); value != "" {
+		return value
+	}
+	return getenv("OTEL_EXPORTER_OTLP_" + name)
+}
+
+// parsePairs parses a comma separated list of URL encoded key=value pairs
+func parsePairs(list string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, item := range strings.Split(list, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%q is not a key=value pair", item)
+		}
+		decoded, err := url.QueryUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %s: %w", key, err)
+		}
+		pairs[key] = decoded
+	}
+	return pairs, nil
+}
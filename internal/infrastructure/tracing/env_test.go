@@ -0,0 +1,95 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// env returns a getenv reading vars
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func TestFromEnv_Disabled(t *testing.T) {
+	for name, vars := range map[string]map[string]string{
+		"unset":    {},
+		"sdk":      {"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318", "OTEL_SDK_DISABLED": "true"},
+		"exporter": {"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318", "OTEL_TRACES_EXPORTER": "none"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tracer, err := tracing.FromEnv(env(vars), nil)
+			require.NoError(t, err)
+			assert.Nil(t, tracer)
+		})
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	c := newCollector(t)
+	tracer, err := tracing.FromEnv(env(map[string]string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT":       c.URL + "/",
+		"OTEL_EXPORTER_OTLP_HEADERS":        "X-Token=a%20b, X-Other=1",
+		"OTEL_EXPORTER_OTLP_TRACES_HEADERS": "X-Other=2",
+		"OTEL_EXPORTER_OTLP_TIMEOUT":        "2000",
+		"OTEL_EXPORTER_OTLP_PROTOCOL":       "http/json",
+		"OTEL_SERVICE_NAME":                 "installer",
+		"OTEL_RESOURCE_ATTRIBUTES":          "deployment.environment=prod,service.name=ignored",
+	}), nil)
+	require.NoError(t, err)
+	require.NotNil(t, tracer)
+
+	_, span := tracer.Start(context.Background(), "install")
+	span.End(nil)
+	require.NoError(t, tracer.Shutdown(context.Background()))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	require.Len(t, c.requests, 1)
+	assert.Equal(t, "a b", c.headers[0].Get("X-Token"))
+	assert.Equal(t, "2", c.headers[0].Get("X-Other"))
+	attributes := c.requests[0].ResourceSpans[0].Resource.Attributes
+	assert.Contains(t, attributes, otlp.Attribute("service.name", "installer"))
+	assert.Contains(t, attributes, otlp.Attribute("deployment.environment", "prod"))
+}
+
+func TestFromEnv_TracesEndpoint(t *testing.T) {
+	c := newCollector(t)
+	tracer, err := tracing.FromEnv(env(map[string]string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT":        "http://unused:4318",
+		"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": c.URL + "/v1/traces",
+	}), nil)
+	require.NoError(t, err)
+
+	_, span := tracer.Start(context.Background(), "install")
+	span.End(nil)
+	require.NoError(t, tracer.Shutdown(context.Background()))
+	assert.Len(t, c.spans(), 1)
+}
+
+func TestFromEnv_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		vars map[string]string
+		want string
+	}{
+		"protocol": {map[string]string{"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc"}, `unsupported OTLP protocol "grpc"`},
+		"headers":  {map[string]string{"OTEL_EXPORTER_OTLP_HEADERS": "novalue"}, "invalid OTEL_EXPORTER_OTLP_HEADERS"},
+		"timeout":  {map[string]string{"OTEL_EXPORTER_OTLP_TIMEOUT": "10s"}, `invalid OTLP timeout "10s"`},
+		"resource": {map[string]string{"OTEL_RESOURCE_ATTRIBUTES": "a=%zz"}, "invalid OTEL_RESOURCE_ATTRIBUTES"},
+		"endpoint": {map[string]string{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "collector"}, `invalid OTLP endpoint "collector"`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tt.vars["OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"] == "" {
+				tt.vars["OTEL_EXPORTER_OTLP_ENDPOINT"] = "http://collector:4318"
+			}
+			_, err := tracing.FromEnv(env(tt.vars), nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
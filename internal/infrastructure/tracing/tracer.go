@@ -0,0 +1,247 @@
+// internal/infrastructure/tracing/tracer.go - Span creation and batched OTLP export
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+)
+
+const (
+	// DefaultServiceName is the service.name resource attribute when none is configured
+	DefaultServiceName = "svz"
+	// queueSize is the number of ended spans waiting for export before spans are dropped
+	queueSize = 2048
+	// exportBatch is the largest number of spans exported at once
+	exportBatch = 512
+	// exportInterval is the longest an ended span waits for its batch
+	exportInterval = time.Second
+	// scopeName names the instrumentation of the exported spans
+	scopeName = "superviz"
+)
+
+// Options configures a Tracer.
+type Options struct {
+	// Endpoint is the collector base URL, or the traces URL when it has a path
+	Endpoint string
+	// Headers are added to every export request
+	Headers map[string]string
+	// Timeout bounds one export, default otlp.DefaultTimeout
+	Timeout time.Duration
+	// ServiceName is the service.name resource attribute, default svz
+	ServiceName string
+	// Resource holds more resource attributes; host.name is added when missing
+	Resource map[string]any
+	// Report receives the export failures and drops, once per streak
+	Report func(error)
+}
+
+// Tracer creates spans and exports them in batches from one goroutine, so
+// a slow collector never delays the traced operations.
+type Tracer struct {
+	// client posts the batches
+	client *otlp.Client
+	// timeout bounds one export
+	timeout time.Duration
+	// resource describes the producer of the spans
+	resource otlp.Resource
+	// report receives the failures
+	report func(error)
+	// spans is the export queue
+	spans chan otlp.Span
+	// mu guards closed against sends on the closed queue
+	mu sync.RWMutex
+	// closed is set once Shutdown closed the queue
+	closed bool
+	// dropping is set while spans are dropped
+	dropping atomic.Bool
+	// ctx is cancelled when the shutdown deadline expires
+	ctx context.Context
+	// cancel aborts the export in progress
+	cancel context.CancelFunc
+	// done is closed once the export goroutine returned
+	done chan struct{}
+}
+
+// New creates a tracer exporting to a collector and starts its export goroutine.
+//
+// Parameters:
+//   - opts: *Options collector and resource settings
+//
+// Returns:
+//   - tracer: *Tracer to install with SetDefault and stop with Shutdown
+//   - err: error if the endpoint is invalid
+func New(opts *Options) (*Tracer, error) {
+	if opts == nil {
+		return nil, errors.New("tracing options cannot be nil")
+	}
+	client, err := otlp.NewClient(&otlp.ClientOptions{Endpoint: opts.Endpoint, Headers: opts.Headers, Timeout: opts.Timeout})
+	if err != nil {
+		return nil, err
+	}
+
+	resource := make(map[string]any, len(opts.Resource)+2)
+	for key, value := range opts.Resource {
+		resource[key] = value
+	}
+	if opts.ServiceName != "" {
+		resource["service.name"] = opts.ServiceName
+	}
+	if resource["service.name"] == nil {
+		resource["service.name"] = DefaultServiceName
+	}
+	if resource["host.name"] == nil {
+		if host, err := os.Hostname(); err == nil {
+			resource["host.name"] = host
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &Tracer{
+		client:   client,
+		timeout:  opts.Timeout,
+		resource: otlp.Resource{Attributes: otlp.Attributes(resource)},
+		report:   opts.Report,
+		spans:    make(chan otlp.Span, queueSize),
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	if t.timeout <= 0 {
+		t.timeout = otlp.DefaultTimeout
+	}
+	if t.report == nil {
+		t.report = func(error) {}
+	}
+	go t.run()
+	return t, nil
+}
+
+// Start starts a span, child of the span carried by ctx if any.
+//
+// Parameters:
+//   - ctx: context.Context whose span becomes the parent
+//   - name: string operation name
+//   - attrs: ...otlp.KeyValue attributes of the span
+//
+// Returns:
+//   - ctx: context.Context carrying the new span
+//   - span: *Span to end, nil when t is nil
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...otlp.KeyValue) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	span := &Span{tracer: t, data: otlp.Span{
+		SpanID:            newID(8),
+		Name:              name,
+		Kind:              otlp.SpanKindInternal,
+		StartTimeUnixNano: otlp.UnixNano(time.Now()),
+		Attributes:        attrs,
+	}}
+	if parent := FromContext(ctx); parent != nil {
+		span.data.TraceID = parent.data.TraceID
+		span.data.ParentSpanID = parent.data.SpanID
+	} else {
+		span.data.TraceID = newID(16)
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Shutdown exports the ended spans and stops the tracer; spans ended later are dropped.
+//
+// Parameters:
+//   - ctx: context.Context bounding the final export
+//
+// Returns:
+//   - err: error if ctx expired before the queue was exported
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	if !t.closed {
+		t.closed = true
+		close(t.spans)
+	}
+	t.mu.Unlock()
+
+	select {
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		t.cancel()
+		<-t.done
+		return fmt.Errorf("trace export interrupted: %w", ctx.Err())
+	}
+}
+
+// enqueue queues an ended span, dropping it while the queue is full
+func (t *Tracer) enqueue(span otlp.Span) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.closed {
+		return
+	}
+	select {
+	case t.spans <- span:
+		t.dropping.Store(false)
+	default:
+		if !t.dropping.Swap(true) {
+			t.report(errors.New("trace export queue is full, dropping spans"))
+		}
+	}
+}
+
+// run exports the queued spans in batches until the queue is closed and drained
+func (t *Tracer) run() {
+	defer close(t.done)
+	defer t.cancel()
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	failing := false
+	batch := make([]otlp.Span, 0, exportBatch)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(t.ctx, t.timeout)
+		err := t.client.Export(ctx, otlp.SignalTraces, t.tracesData(batch))
+		cancel()
+		if err != nil && !failing {
+			t.report(err)
+		}
+		failing = err != nil
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case span, ok := <-t.spans:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, span)
+			if len(batch) == exportBatch {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// tracesData wraps spans, in the order they ended, in an export request
+func (t *Tracer) tracesData(spans []otlp.Span) otlp.TracesData {
+	return otlp.TracesData{ResourceSpans: []otlp.ResourceSpans{{
+		Resource:   t.resource,
+		ScopeSpans: []otlp.ScopeSpans{{Scope: otlp.Scope{Name: scopeName}, Spans: spans}},
+	}}}
+}
@@ -0,0 +1,196 @@
+package tracing_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collector is an in-process stand-in for an OTLP/HTTP collector receiving traces
+type collector struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []otlp.TracesData
+	headers  []http.Header
+	status   int
+}
+
+// newCollector starts a collector decoding the traces export requests
+func newCollector(t *testing.T) *collector {
+	t.Helper()
+	c := &collector{status: http.StatusOK}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			http.Error(w, "unexpected path", http.StatusNotFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var data otlp.TracesData
+		if err := json.Unmarshal(body, &data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.requests = append(c.requests, data)
+		c.headers = append(c.headers, r.Header.Clone())
+		w.WriteHeader(c.status)
+	}))
+	t.Cleanup(c.Close)
+	return c
+}
+
+// spans returns every span received
+func (c *collector) spans() []otlp.Span {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var spans []otlp.Span
+	for _, data := range c.requests {
+		for _, resource := range data.ResourceSpans {
+			for _, scope := range resource.ScopeSpans {
+				spans = append(spans, scope.Spans...)
+			}
+		}
+	}
+	return spans
+}
+
+// newTracer returns a tracer exporting to c, shut down at the end of the test
+func newTracer(t *testing.T, c *collector, report func(error)) *tracing.Tracer {
+	t.Helper()
+	tracer, err := tracing.New(&tracing.Options{Endpoint: c.URL, Headers: map[string]string{"X-Token": "secret"}, Report: report})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tracer.Shutdown(context.Background()) })
+	return tracer
+}
+
+func TestTracer_ExportsOnShutdown(t *testing.T) {
+	c := newCollector(t)
+	tracer := newTracer(t, c, nil)
+
+	ctx, parent := tracer.Start(context.Background(), "install", tracing.Attr("host", "web1"))
+	_, child := tracer.Start(ctx, "ssh.execute")
+	child.SetKind(otlp.SpanKindClient)
+	child.End(errors.New("exit status 1"))
+	parent.End(nil)
+	require.NoError(t, tracer.Shutdown(context.Background()))
+
+	c.mu.Lock()
+	require.Len(t, c.requests, 1)
+	resource := c.requests[0].ResourceSpans[0]
+	assert.Equal(t, "secret", c.headers[0].Get("X-Token"))
+	c.mu.Unlock()
+	assert.Contains(t, resource.Resource.Attributes, otlp.Attribute("service.name", tracing.DefaultServiceName))
+	assert.Equal(t, "superviz", resource.ScopeSpans[0].Scope.Name)
+
+	spans := c.spans()
+	require.Len(t, spans, 2)
+	got, root := spans[0], spans[1]
+	assert.Equal(t, "ssh.execute", got.Name)
+	assert.Equal(t, otlp.SpanKindClient, got.Kind)
+	assert.Equal(t, root.TraceID, got.TraceID)
+	assert.Equal(t, root.SpanID, got.ParentSpanID)
+	assert.Equal(t, otlp.Status{Code: otlp.StatusError, Message: "exit status 1"}, got.Status)
+	require.Len(t, got.Events, 1)
+	assert.Equal(t, "exception", got.Events[0].Name)
+
+	assert.Equal(t, "install", root.Name)
+	assert.Empty(t, root.ParentSpanID)
+	assert.Len(t, root.TraceID, 32)
+	assert.Len(t, root.SpanID, 16)
+	assert.Equal(t, otlp.Status{}, root.Status)
+	assert.Equal(t, []otlp.KeyValue{otlp.Attribute("host", "web1")}, root.Attributes)
+	assert.NotEqual(t, "0", root.EndTimeUnixNano)
+}
+
+func TestTracer_ExportsPeriodically(t *testing.T) {
+	c := newCollector(t)
+	tracer := newTracer(t, c, nil)
+
+	_, span := tracer.Start(context.Background(), "detect")
+	span.End(nil)
+	span.End(errors.New("ignored"))
+	require.Eventually(t, func() bool { return len(c.spans()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, otlp.Status{}, c.spans()[0].Status, "only the first End counts")
+}
+
+func TestTracer_ReportsFailuresOncePerStreak(t *testing.T) {
+	c := newCollector(t)
+	c.status = http.StatusServiceUnavailable
+	var mu sync.Mutex
+	var reports []error
+	tracer := newTracer(t, c, func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, err)
+	})
+
+	for range 2 {
+		_, span := tracer.Start(context.Background(), "step")
+		span.End(nil)
+		require.Eventually(t, func() bool {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return len(c.requests) > 0
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+	_, span := tracer.Start(context.Background(), "step")
+	span.End(nil)
+	require.NoError(t, tracer.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, reports, 1)
+	assert.Contains(t, reports[0].Error(), "503")
+}
+
+func TestTracer_ShutdownDropsLaterSpans(t *testing.T) {
+	c := newCollector(t)
+	tracer := newTracer(t, c, nil)
+	require.NoError(t, tracer.Shutdown(context.Background()))
+	require.NoError(t, tracer.Shutdown(context.Background()), "Shutdown is idempotent")
+
+	_, span := tracer.Start(context.Background(), "late")
+	span.End(nil)
+	assert.Empty(t, c.spans())
+}
+
+func TestTracer_ShutdownTimeout(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+	defer close(release)
+	tracer, err := tracing.New(&tracing.Options{Endpoint: slow.URL})
+	require.NoError(t, err)
+
+	_, span := tracer.Start(context.Background(), "stuck")
+	span.End(nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = tracer.Shutdown(ctx)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestNew_Errors(t *testing.T) {
+	_, err := tracing.New(nil)
+	require.Error(t, err)
+	_, err = tracing.New(&tracing.Options{Endpoint: "collector:4318"})
+	require.Error(t, err)
+}
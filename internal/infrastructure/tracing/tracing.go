@@ -0,0 +1,187 @@
+// Package tracing records spans of the svz operations and exports them over OTLP.
+//
+// Spans are created from the default tracer installed by the CLI when
+// OTEL_EXPORTER_OTLP_ENDPOINT is set; without it Start returns a nil span
+// whose methods do nothing, so instrumented code never checks whether
+// tracing is enabled.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+)
+
+// defaultTracer is the tracer used by Start, nil when tracing is disabled
+var defaultTracer atomic.Pointer[Tracer]
+
+// spanKey is the context key of the current span
+type spanKey struct{}
+
+// SetDefault installs the tracer used by Start.
+//
+// Parameters:
+//   - tracer: *Tracer to use, nil to disable tracing
+func SetDefault(tracer *Tracer) {
+	defaultTracer.Store(tracer)
+}
+
+// Default returns the tracer used by Start.
+//
+// Returns:
+//   - tracer: *Tracer installed by SetDefault, nil when tracing is disabled
+func Default() *Tracer {
+	return defaultTracer.Load()
+}
+
+// Start starts a span with the default tracer.
+//
+//	ctx, span := tracing.Start(ctx, "ssh.connect", tracing.Attr("server.address", host))
+//	err := connect(ctx)
+//	span.End(err)
+//
+// Parameters:
+//   - ctx: context.Context whose span becomes the parent
+//   - name: string operation name
+//   - attrs: ...otlp.KeyValue attributes of the span
+//
+// Returns:
+//   - ctx: context.Context carrying the new span
+//   - span: *Span to end, nil when tracing is disabled
+func Start(ctx context.Context, name string, attrs ...otlp.KeyValue) (context.Context, *Span) {
+	return Default().Start(ctx, name, attrs...)
+}
+
+// Attr returns a span attribute.
+//
+// Parameters:
+//   - key: string attribute name, such as server.address
+//   - value: any string, bool, integer, float or slice
+//
+// Returns:
+//   - attr: otlp.KeyValue attribute
+func Attr(key string, value any) otlp.KeyValue {
+	return otlp.Attribute(key, value)
+}
+
+// FromContext returns the span carried by ctx.
+//
+// Parameters:
+//   - ctx: context.Context from Start
+//
+// Returns:
+//   - span: *Span current span, nil when there is none
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span) //nolint:errcheck // a missing span is nil
+	return span
+}
+
+// Span is a timed operation, exported once ended.
+//
+// All methods are safe for concurrent use and do nothing on a nil span.
+type Span struct {
+	// tracer exports the span once ended
+	tracer *Tracer
+	// mu guards data and ended
+	mu sync.Mutex
+	// data is the span being recorded
+	data otlp.Span
+	// ended is set once End was called
+	ended bool
+}
+
+// TraceID returns the hex encoded identifier of the trace of the span.
+//
+// Returns:
+//   - id: string trace identifier, empty for a nil span
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return s.data.TraceID
+}
+
+// SetKind sets the role of the span, internal by default.
+//
+// Parameters:
+//   - kind: otlp.SpanKind such as otlp.SpanKindClient
+func (s *Span) SetKind(kind otlp.SpanKind) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Kind = kind
+}
+
+// SetAttributes adds attributes to the span.
+//
+// Parameters:
+//   - attrs: ...otlp.KeyValue attributes, see Attr
+func (s *Span) SetAttributes(attrs ...otlp.KeyValue) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+}
+
+// AddEvent records a point in time of the span.
+//
+// Parameters:
+//   - name: string event name
+//   - attrs: ...otlp.KeyValue attributes of the event
+func (s *Span) AddEvent(name string, attrs ...otlp.KeyValue) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Events = append(s.data.Events, otlp.SpanEvent{TimeUnixNano: otlp.UnixNano(time.Now()), Name: name, Attributes: attrs})
+}
+
+// End ends the span and queues it for export; later calls do nothing.
+//
+// A non-nil err sets the error status with its message and records an
+// exception event.
+//
+// Parameters:
+//   - err: error the operation failed with, nil on success
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	now := otlp.UnixNano(time.Now())
+	if err != nil {
+		s.data.Status = otlp.Status{Code: otlp.StatusError, Message: err.Error()}
+		s.data.Events = append(s.data.Events, otlp.SpanEvent{
+			TimeUnixNano: now,
+			Name:         "exception",
+			Attributes:   []otlp.KeyValue{otlp.Attribute("exception.message", err.Error())},
+		})
+	}
+	s.data.EndTimeUnixNano = now
+	data := s.data
+	s.mu.Unlock()
+
+	s.tracer.enqueue(data)
+}
+
+// newID returns size random bytes hex encoded
+func newID(size int) string {
+	id := make([]byte, size)
+	_, _ = rand.Read(id) //nolint:errcheck // never fails
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,52 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStart_Disabled(t *testing.T) {
+	tracing.SetDefault(nil)
+	ctx := context.Background()
+	got, span := tracing.Start(ctx, "noop", tracing.Attr("k", "v"))
+	assert.Equal(t, ctx, got)
+	assert.Nil(t, span)
+	assert.Nil(t, tracing.FromContext(got))
+
+	// Every method is safe on the nil span
+	span.SetKind(otlp.SpanKindClient)
+	span.SetAttributes(tracing.Attr("k", "v"))
+	span.AddEvent("event")
+	span.End(errors.New("ignored"))
+	assert.Empty(t, span.TraceID())
+}
+
+func TestStart_Default(t *testing.T) {
+	c := newCollector(t)
+	tracer := newTracer(t, c, nil)
+	tracing.SetDefault(tracer)
+	t.Cleanup(func() { tracing.SetDefault(nil) })
+	assert.Same(t, tracer, tracing.Default())
+
+	ctx, span := tracing.Start(context.Background(), "service web")
+	require.NotNil(t, span)
+	assert.Same(t, span, tracing.FromContext(ctx))
+	span.SetAttributes(tracing.Attr("pid", 42))
+	span.AddEvent("running", tracing.Attr("state", "running"))
+	span.End(nil)
+	require.NoError(t, tracer.Shutdown(context.Background()))
+
+	spans := c.spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, span.TraceID(), spans[0].TraceID)
+	assert.Equal(t, []otlp.KeyValue{otlp.Attribute("pid", 42)}, spans[0].Attributes)
+	require.Len(t, spans[0].Events, 1)
+	assert.Equal(t, "running", spans[0].Events[0].Name)
+	assert.Equal(t, []otlp.KeyValue{otlp.Attribute("state", "running")}, spans[0].Events[0].Attributes)
+}
@@ -0,0 +1,96 @@
+// Package tracingtest records the spans of the code under test.
+package tracingtest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing"
+)
+
+// Recorder is an in-process OTLP collector receiving the spans of the default tracer.
+type Recorder struct {
+	// tracer is the default tracer while the test runs
+	tracer *tracing.Tracer
+	// mu guards spans
+	mu sync.Mutex
+	// spans are the spans received
+	spans []otlp.Span
+}
+
+// Install starts a collector and installs a default tracer exporting to it
+// until the end of the test.
+//
+// Parameters:
+//   - t: *testing.T test owning the recorder
+//
+// Returns:
+//   - recorder: *Recorder holding the exported spans
+func Install(t *testing.T) *Recorder {
+	t.Helper()
+	r := &Recorder{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body) //nolint:errcheck // a short body fails to decode
+		var data otlp.TracesData
+		if err := json.Unmarshal(body, &data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, resource := range data.ResourceSpans {
+			for _, scope := range resource.ScopeSpans {
+				r.spans = append(r.spans, scope.Spans...)
+			}
+		}
+	}))
+
+	tracer, err := tracing.New(&tracing.Options{Endpoint: server.URL})
+	if err != nil {
+		server.Close()
+		t.Fatalf("failed to create tracer: %v", err)
+	}
+	r.tracer = tracer
+	tracing.SetDefault(tracer)
+	t.Cleanup(func() {
+		tracing.SetDefault(nil)
+		_ = tracer.Shutdown(context.Background()) //nolint:errcheck // the test is over
+		server.Close()
+	})
+	return r
+}
+
+// Spans exports the ended spans and returns every span received, in the order they ended.
+//
+// The tracer stops: call Spans once, after the traced operations.
+//
+// Returns:
+//   - spans: []otlp.Span received by the collector
+func (r *Recorder) Spans() []otlp.Span {
+	tracing.SetDefault(nil)
+	_ = r.tracer.Shutdown(context.Background()) //nolint:errcheck // the spans received so far are returned
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]otlp.Span(nil), r.spans...)
+}
+
+// Names returns the names of spans.
+//
+// Parameters:
+//   - spans: []otlp.Span from Spans
+//
+// Returns:
+//   - names: []string span names in order
+func Names(spans []otlp.Span) []string {
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name)
+	}
+	return names
+}
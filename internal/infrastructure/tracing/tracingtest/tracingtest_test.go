@@ -0,0 +1,29 @@
+package tracingtest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing"
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing/tracingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	recorder := tracingtest.Install(t)
+	require.NotNil(t, tracing.Default())
+
+	ctx, parent := tracing.Start(context.Background(), "parent")
+	_, child := tracing.Start(ctx, "child")
+	child.End(errors.New("failed"))
+	parent.End(nil)
+
+	spans := recorder.Spans()
+	assert.Nil(t, tracing.Default(), "Spans stops the tracer")
+	assert.Equal(t, []string{"child", "parent"}, tracingtest.Names(spans))
+	assert.Equal(t, spans[1].SpanID, spans[0].ParentSpanID)
+	assert.Equal(t, otlp.StatusError, spans[0].Status.Code)
+}
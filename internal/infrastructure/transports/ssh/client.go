@@ -5,7 +5,11 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
 
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -90,41 +94,122 @@ func NewClient(opts *ClientOptions) Client {
 //
 // Returns:
 //   - Error if connection establishment fails
-func (c *client) Connect(ctx context.Context, config *Config) error {
+func (c *client) Connect(ctx context.Context, config *Config) (err error) {
 	// Validate and cache config
 	if err := config.Validate(); err != nil {
 		return err
 	}
 	c.config = config
 
+	ctx, span := tracing.Start(ctx, "ssh.connect",
+		tracing.Attr("server.address", config.Host),
+		tracing.Attr("server.port", config.Port),
+		tracing.Attr("ssh.user", config.User))
+	span.SetKind(otlp.SpanKindClient)
+	if config.JumpHost != "" {
+		span.SetAttributes(tracing.Attr("ssh.jump_host", config.JumpHost))
+	}
+	defer func() { span.End(err) }()
+
+	// Establish connection, tunneled through the jump host when configured
+	dial := c.dial
+	if config.JumpHost != "" {
+		dial = c.dialJump
+	}
+
+	conn, err := dial(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	c.conn = conn
+	return nil
+}
+
+// clientConfig builds the SSH handshake configuration for config.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - config: *Config validated connection configuration
+//
+// Returns:
+//   - sshConfig: *ssh.ClientConfig with authentication and host key verification
+//   - err: error if host key or authentication setup fails
+func (c *client) clientConfig(ctx context.Context, config *Config) (*ssh.ClientConfig, error) {
 	// Get host key callback
 	hostKeyCallback, err := c.hostKeyManager.GetHostKeyCallback(ctx, config)
 	if err != nil {
-		return WrapError(ErrHostKeyRejected, err)
+		return nil, WrapError(ErrHostKeyRejected, err)
 	}
 
 	// Get authentication methods
 	authMethods, err := c.authenticator.GetAuthMethods(ctx, config)
 	if err != nil {
-		return WrapError(ErrAuthFailed, err)
+		return nil, WrapError(ErrAuthFailed, err)
 	}
 
-	// Create SSH client configuration
-	sshConfig := &ssh.ClientConfig{
+	return &ssh.ClientConfig{
 		User:            config.User,
 		Auth:            authMethods,
 		HostKeyCallback: hostKeyCallback,
 		Timeout:         config.Timeout,
+	}, nil
+}
+
+// dial establishes a direct connection to the host described by config.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - config: *Config validated connection configuration
+//
+// Returns:
+//   - conn: Connection established connection
+//   - err: error if connection establishment fails
+func (c *client) dial(ctx context.Context, config *Config) (Connection, error) {
+	sshConfig, err := c.clientConfig(ctx, config)
+	if err != nil {
+		return nil, err
 	}
 
 	// Establish connection
-	conn, err := c.dialer.DialContext(ctx, "tcp", config.Address(), sshConfig)
+	return c.dialer.DialContext(ctx, "tcp", config.Address(), sshConfig) // Already wrapped by dialer
+}
+
+// dialJump connects to the target through the configured jump host.
+//
+// The jump host is dialed with the regular dialer, then the target handshake
+// runs over a direct-tcpip channel opened on the jump connection.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - config: *Config validated target configuration with JumpHost set
+//
+// Returns:
+//   - conn: Connection to the target, closing it also closes the jump connection
+//   - err: error if either connection fails
+func (c *client) dialJump(ctx context.Context, config *Config) (Connection, error) {
+	jumpConfig, err := config.JumpConfig()
 	if err != nil {
-		return err // Already wrapped by dialer
+		return nil, WrapError(ErrInvalidConfig, err)
 	}
 
-	c.conn = conn
-	return nil
+	jump, err := c.dial(ctx, jumpConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	sshConfig, err := c.clientConfig(ctx, config)
+	if err != nil {
+		jump.Close() //nolint:errcheck
+		return nil, err
+	}
+
+	conn, err := dialThrough(ctx, jump, config.Address(), sshConfig)
+	if err != nil {
+		jump.Close() //nolint:errcheck
+		return nil, err
+	}
+	return conn, nil
 }
 
 // Execute runs a command on the remote SSH server.
@@ -140,13 +225,65 @@ func (c *client) Connect(ctx context.Context, config *Config) error {
 // Returns:
 //   - Error if command execution fails or times out
 func (c *client) Execute(ctx context.Context, command string) error {
+	_, err := c.run(ctx, command, func(session Session) ([]byte, error) {
+		return nil, session.Run(command)
+	})
+	return err
+}
+
+// ExecuteOutput runs a command on the remote SSH server and captures its output.
+//
+// ExecuteOutput behaves like Execute but returns the command's standard output
+// with surrounding whitespace trimmed.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - command: Command string to execute on the remote server
+//
+// Returns:
+//   - Trimmed standard output of the command
+//   - Error if command execution fails or times out
+func (c *client) ExecuteOutput(ctx context.Context, command string) (string, error) {
+	out, err := c.run(ctx, command, func(session Session) ([]byte, error) {
+		return session.Output(command)
+	})
+	return strings.TrimSpace(string(out)), err
+}
+
+// urlUserinfo matches the credentials of the URLs in a command
+var urlUserinfo = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)[^/\s@'"]+@`)
+
+// redactCommand strips the userinfo of the URLs in command, so that no
+// credential reaches the tracing backend
+func redactCommand(command string) string {
+	return urlUserinfo.ReplaceAllString(command, "$1")
+}
+
+// run opens a session, executes fn in it and closes the session.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - command: string command run by fn, recorded on the trace span
+//   - fn: func(Session) ([]byte, error) command execution within the session
+//
+// Returns:
+//   - Output produced by fn
+//   - Error if the session cannot be created, fn fails or ctx expires
+func (c *client) run(ctx context.Context, command string, fn func(Session) ([]byte, error)) (out []byte, err error) {
 	if c.conn == nil {
-		return ErrNotConnected
+		return nil, ErrNotConnected
+	}
+
+	_, span := tracing.Start(ctx, "ssh.execute", tracing.Attr("ssh.command", redactCommand(command)))
+	span.SetKind(otlp.SpanKindClient)
+	if c.config != nil {
+		span.SetAttributes(tracing.Attr("server.address", c.config.Host))
 	}
+	defer func() { span.End(err) }()
 
 	session, err := c.conn.NewSession()
 	if err != nil {
-		return WrapError(ErrSessionCreation, err)
+		return nil, WrapError(ErrSessionCreation, err)
 	}
 
 	defer func() {
@@ -156,19 +293,24 @@ func (c *client) Execute(ctx context.Context, command string) error {
 	}()
 
 	// Execute command with context
-	done := make(chan error, 1)
+	type result struct {
+		out []byte
+		err error
+	}
+	done := make(chan result, 1)
 	go func() {
-		done <- session.Run(command)
+		out, err := fn(session)
+		done <- result{out: out, err: err}
 	}()
 
 	select {
 	case <-ctx.Done():
-		return WrapError(ErrCommandTimeout, ctx.Err())
-	case err := <-done:
-		if err != nil {
-			return WrapError(ErrCommandFailed, err)
+		return nil, WrapError(ErrCommandTimeout, ctx.Err())
+	case res := <-done:
+		if res.err != nil {
+			return res.out, WrapError(ErrCommandFailed, res.err)
 		}
-		return nil
+		return res.out, nil
 	}
 }
 
@@ -4,6 +4,9 @@ package ssh
 import (
 	"errors"
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -26,6 +29,8 @@ type Config struct {
 	SkipHostKeyCheck bool
 	// AcceptNewHostKey automatically accepts unknown host keys
 	AcceptNewHostKey bool
+	// JumpHost is an optional [user@]host[:port] bastion the connection is tunneled through
+	JumpHost string
 	// address is a cached formatted address string (private field)
 	address string // Cached address
 }
@@ -62,6 +67,11 @@ func (c *Config) Validate() error {
 	if c.Timeout <= 0 {
 		return errors.New("timeout must be positive")
 	}
+	if c.JumpHost != "" {
+		if _, err := c.JumpConfig(); err != nil {
+			return err
+		}
+	}
 
 	// Pre-compute address
 	c.address = fmt.Sprintf("%s:%d", c.Host, c.Port)
@@ -81,3 +91,52 @@ func (c *Config) Address() string {
 	}
 	return c.address
 }
+
+// JumpConfig returns the connection configuration of the jump host.
+//
+// JumpConfig parses JumpHost as [user@]host[:port]. The user defaults to the
+// target user and the port to 22; authentication, timeout and host key
+// settings are inherited from the target configuration.
+//
+// Example:
+//
+//	cfg := &Config{Host: "10.0.0.5", User: "deploy", JumpHost: "ops@bastion:2222"}
+//	jump, err := cfg.JumpConfig()
+//	// jump.User == "ops", jump.Host == "bastion", jump.Port == 2222
+//
+// Returns:
+//   - jump: *Config configuration for the jump host, nil when JumpHost is empty
+//   - err: error if JumpHost is malformed
+func (c *Config) JumpConfig() (*Config, error) {
+	if c.JumpHost == "" {
+		return nil, nil
+	}
+
+	user, hostPort := c.User, c.JumpHost
+	if at := strings.LastIndex(hostPort, "@"); at >= 0 {
+		user, hostPort = hostPort[:at], hostPort[at+1:]
+	}
+
+	host, port := hostPort, 22
+	if h, p, err := net.SplitHostPort(hostPort); err == nil {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 1 || n > 65535 {
+			return nil, fmt.Errorf("invalid jump host port: %q", p)
+		}
+		host, port = h, n
+	}
+
+	if user == "" || host == "" {
+		return nil, fmt.Errorf("invalid jump host %q, expected [user@]host[:port]", c.JumpHost)
+	}
+
+	return &Config{
+		Host:             host,
+		User:             user,
+		Port:             port,
+		KeyPath:          c.KeyPath,
+		Timeout:          c.Timeout,
+		SkipHostKeyCheck: c.SkipHostKeyCheck,
+		AcceptNewHostKey: c.AcceptNewHostKey,
+	}, nil
+}
@@ -227,3 +227,58 @@ func TestConfig_ValidateAddressCaching(t *testing.T) {
 	addr := config.Address()
 	require.Equal(t, "example.com:22", addr)
 }
+
+func TestConfig_JumpConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		jumpHost string
+		wantUser string
+		wantHost string
+		wantPort int
+		wantErr  bool
+	}{
+		{name: "host only", jumpHost: "bastion", wantUser: "deploy", wantHost: "bastion", wantPort: 22},
+		{name: "user and host", jumpHost: "ops@bastion", wantUser: "ops", wantHost: "bastion", wantPort: 22},
+		{name: "user host port", jumpHost: "ops@bastion:2222", wantUser: "ops", wantHost: "bastion", wantPort: 2222},
+		{name: "ipv6 with port", jumpHost: "[::1]:2200", wantUser: "deploy", wantHost: "::1", wantPort: 2200},
+		{name: "invalid port", jumpHost: "bastion:99999", wantErr: true},
+		{name: "empty user", jumpHost: "@bastion", wantErr: true},
+		{name: "empty host", jumpHost: "ops@", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Host:             "10.0.0.5",
+				User:             "deploy",
+				Port:             22,
+				KeyPath:          "/keys/id",
+				Timeout:          10 * time.Second,
+				SkipHostKeyCheck: true,
+				JumpHost:         tt.jumpHost,
+			}
+
+			jump, err := cfg.JumpConfig()
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Error(t, cfg.Validate())
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, cfg.Validate())
+			require.Equal(t, tt.wantUser, jump.User)
+			require.Equal(t, tt.wantHost, jump.Host)
+			require.Equal(t, tt.wantPort, jump.Port)
+			require.Equal(t, "/keys/id", jump.KeyPath)
+			require.Equal(t, 10*time.Second, jump.Timeout)
+			require.True(t, jump.SkipHostKeyCheck)
+			require.Empty(t, jump.JumpHost)
+		})
+	}
+}
+
+func TestConfig_JumpConfig_Empty(t *testing.T) {
+	jump, err := (&Config{User: "u", Host: "h"}).JumpConfig()
+	require.NoError(t, err)
+	require.Nil(t, jump)
+}
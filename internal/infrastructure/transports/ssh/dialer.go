@@ -3,6 +3,7 @@ package ssh
 
 import (
 	"context"
+	"errors"
 	"net"
 	"strings"
 	"time"
@@ -98,6 +99,64 @@ func (d *defaultDialer) wrapError(err error, addr string) error {
 	return WrapError(ErrConnectionFailed, err).WithContext("address", addr)
 }
 
+// tunnel is implemented by connections able to open TCP channels through
+// the remote server (direct-tcpip forwarding).
+type tunnel interface {
+	// DialContext opens a forwarded connection to addr from the remote server
+	DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// dialThrough performs the SSH handshake with addr over a channel of jump.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - jump: Connection to the jump host, must support tunneling
+//   - addr: string target address as host:port
+//   - config: *ssh.ClientConfig target handshake configuration
+//
+// Returns:
+//   - conn: Connection to the target that also owns jump
+//   - err: error if forwarding or the handshake fails
+func dialThrough(ctx context.Context, jump Connection, addr string, config *ssh.ClientConfig) (Connection, error) {
+	t, ok := jump.(tunnel)
+	if !ok {
+		return nil, NewError(ErrConnectionFailed, "jump host connection does not support tunneling").
+			WithContext("address", addr)
+	}
+
+	conn, err := t.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, WrapError(ErrConnectionFailed, err).WithContext("address", addr).WithContext("via", "jump host")
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close() //nolint:errcheck
+		return nil, (&defaultDialer{}).wrapError(err, addr)
+	}
+
+	return &jumpConnection{
+		Connection: &sshConnection{client: ssh.NewClient(sshConn, chans, reqs)},
+		jump:       jump,
+	}, nil
+}
+
+// jumpConnection is a target connection tunneled through a jump host.
+type jumpConnection struct {
+	// Connection is the tunneled connection to the target
+	Connection
+	// jump is the underlying connection to the jump host
+	jump Connection
+}
+
+// Close closes the target connection, then the jump host connection.
+//
+// Returns:
+//   - err: joined errors from both closures
+func (c *jumpConnection) Close() error {
+	return errors.Join(c.Connection.Close(), c.jump.Close())
+}
+
 // sshConnection wraps an ssh.Client to implement the Connection interface
 type sshConnection struct {
 	client *ssh.Client
@@ -112,6 +171,11 @@ func (c *sshConnection) NewSession() (Session, error) {
 	return &sshSession{session: session}, nil
 }
 
+// DialContext opens a forwarded TCP connection from the remote server
+func (c *sshConnection) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return c.client.DialContext(ctx, network, addr)
+}
+
 // Close closes the SSH connection
 func (c *sshConnection) Close() error {
 	return c.client.Close()
@@ -127,6 +191,11 @@ func (s *sshSession) Run(cmd string) error {
 	return s.session.Run(cmd)
 }
 
+// Output executes a command and returns its standard output
+func (s *sshSession) Output(cmd string) ([]byte, error) {
+	return s.session.Output(cmd)
+}
+
 // Close closes the SSH session
 func (s *sshSession) Close() error {
 	return s.session.Close()
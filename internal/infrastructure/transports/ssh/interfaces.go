@@ -23,6 +23,16 @@ type Session interface {
 	//   - Error if command execution fails
 	Run(cmd string) error
 
+	// Output executes a command and returns its standard output.
+	//
+	// Parameters:
+	//   - cmd: Command string to execute
+	//
+	// Returns:
+	//   - Standard output of the command
+	//   - Error if command execution fails
+	Output(cmd string) ([]byte, error)
+
 	// Close terminates the SSH session.
 	//
 	// Returns:
@@ -205,3 +215,20 @@ type Client interface {
 	//   - Error if connection closure fails
 	Close() error
 }
+
+// OutputExecutor runs commands and captures their standard output.
+//
+// OutputExecutor is implemented by the default Client and is used by read-only
+// operations such as status queries that need command results, not just exit codes.
+type OutputExecutor interface {
+	// ExecuteOutput runs a command on the connected SSH server.
+	//
+	// Parameters:
+	//   - ctx: context.Context for timeout and cancellation
+	//   - command: Command string to execute
+	//
+	// Returns:
+	//   - Standard output of the command
+	//   - Error if command execution fails
+	ExecuteOutput(ctx context.Context, command string) (string, error)
+}
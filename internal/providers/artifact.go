@@ -0,0 +1,302 @@
+// internal/providers/artifact.go - Binaries downloaded and installed before the services start
+package providers
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Artifact defaults.
+const (
+	// DefaultArtifactCacheDir holds the downloads and install receipts, relative to the configuration directory
+	DefaultArtifactCacheDir = ".superviz/cache"
+	// DefaultArtifactMode is the permission of an installed artifact
+	DefaultArtifactMode FileMode = 0o755
+	// AnyPlatform keys the digest shared by every platform
+	AnyPlatform = "*"
+)
+
+// ArtifactFormat is how a download is unpacked.
+type ArtifactFormat string
+
+// Supported artifact formats.
+const (
+	// ArtifactTarGz is a gzip-compressed tarball
+	ArtifactTarGz ArtifactFormat = "tar.gz"
+	// ArtifactZip is a zip archive
+	ArtifactZip ArtifactFormat = "zip"
+	// ArtifactRaw is the file itself
+	ArtifactRaw ArtifactFormat = "raw"
+)
+
+// artifactPlaceholder matches the {{...}} placeholders of a URL template
+var artifactPlaceholder = regexp.MustCompile(`{{\s*([^}]*?)\s*}}`)
+
+// PlatformDigests maps an os/arch pair, such as linux/amd64, to a hex SHA-256
+// digest. A single digest applies to every platform.
+type PlatformDigests map[string]string
+
+// UnmarshalYAML accepts either a single digest or a mapping per platform.
+//
+// Parameters:
+//   - node: *yaml.Node digest entry
+//
+// Returns:
+//   - err: error if the entry is neither a scalar nor a mapping of scalars
+func (d *PlatformDigests) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*d = PlatformDigests{AnyPlatform: node.Value}
+		return nil
+	}
+
+	type plain PlatformDigests
+	return node.Decode((*plain)(d))
+}
+
+// For returns the digest of a platform.
+//
+// Parameters:
+//   - goos: string operating system, such as linux
+//   - goarch: string architecture, such as amd64
+//
+// Returns:
+//   - digest: string lower-case hex digest, empty when none applies
+func (d PlatformDigests) For(goos, goarch string) string {
+	if digest, ok := d[goos+"/"+goarch]; ok {
+		return strings.ToLower(digest)
+	}
+	return strings.ToLower(d[AnyPlatform])
+}
+
+// ArtifactSignature verifies a download with a detached Ed25519 signature.
+type ArtifactSignature struct {
+	// URL is the template of the base64 signature location, with the placeholders of the artifact URL
+	URL string `yaml:"url"`
+	// PublicKey is the base64 Ed25519 key verifying the signature
+	PublicKey string `yaml:"public_key"`
+}
+
+// ArtifactConfig declares a binary downloaded, verified and installed before
+// the services depending on it start.
+type ArtifactConfig struct {
+	// Name is the key of the artifact in the artifacts map
+	Name string `yaml:"-"`
+	// Version is substituted for {{version}}; changing it reinstalls the artifact
+	Version string `yaml:"version"`
+	// URL is the HTTPS download location, with {{name}}, {{version}}, {{os}} and {{arch}} placeholders
+	URL string `yaml:"url"`
+	// Format is tar.gz, zip or raw, guessed from the URL when empty
+	Format ArtifactFormat `yaml:"format,omitempty"`
+	// Extract is the file to install from an archive, default the artifact name
+	Extract string `yaml:"extract,omitempty"`
+	// Path is where the file is installed, relative to the configuration directory
+	Path string `yaml:"path"`
+	// Mode is the permission of the installed file, default 0755
+	Mode FileMode `yaml:"mode,omitempty"`
+	// SHA256 is the digest of the download, one for every platform or one per os/arch
+	SHA256 PlatformDigests `yaml:"sha256,omitempty"`
+	// Signature verifies the download with an Ed25519 signature
+	Signature *ArtifactSignature `yaml:"signature,omitempty"`
+	// Services wait for the artifact and are refused when it cannot be installed, every service when empty
+	Services []string `yaml:"services,omitempty"`
+}
+
+// Expand substitutes the placeholders of a URL template.
+//
+// Parameters:
+//   - template: string URL or signature URL of the artifact
+//   - goos: string substituted for {{os}}
+//   - goarch: string substituted for {{arch}}
+//
+// Returns:
+//   - url: string template with every placeholder substituted
+func (a *ArtifactConfig) Expand(template, goos, goarch string) string {
+	return artifactPlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		switch artifactPlaceholder.FindStringSubmatch(placeholder)[1] {
+		case "name":
+			return a.Name
+		case "version":
+			return a.Version
+		case "os":
+			return goos
+		case "arch":
+			return goarch
+		default:
+			return placeholder
+		}
+	})
+}
+
+// applyDefaults guesses the format and fills the extracted file, the mode and the absolute path
+func (a *ArtifactConfig) applyDefaults(name, dir string) {
+	a.Name = name
+	if a.Format == "" {
+		switch u := strings.ToLower(a.URL); {
+		case strings.HasSuffix(u, ".tar.gz"), strings.HasSuffix(u, ".tgz"):
+			a.Format = ArtifactTarGz
+		case strings.HasSuffix(u, ".zip"):
+			a.Format = ArtifactZip
+		default:
+			a.Format = ArtifactRaw
+		}
+	}
+	if a.Extract == "" && a.Format != ArtifactRaw {
+		a.Extract = name
+	}
+	if a.Mode == 0 {
+		a.Mode = DefaultArtifactMode
+	}
+	a.Path = resolvePath(dir, a.Path)
+}
+
+// validate checks the URL templates, the format and the verification method
+func (a *ArtifactConfig) validate(c *SupervizConfig) error {
+	if !validServiceName(a.Name) {
+		return errors.New("name must only contain letters, digits, '.', '_' and '-'")
+	}
+	if a.Version == "" {
+		return errors.New("version is required")
+	}
+	if a.Path == "" {
+		return errors.New("path is required")
+	}
+	if err := checkArtifactURL(a.URL); err != nil {
+		return fmt.Errorf("url: %w", err)
+	}
+	switch a.Format {
+	case ArtifactTarGz, ArtifactZip:
+	case ArtifactRaw:
+		if a.Extract != "" {
+			return errors.New("extract requires a tar.gz or zip format")
+		}
+	default:
+		return fmt.Errorf("invalid format %q: must be %s, %s or %s", a.Format, ArtifactTarGz, ArtifactZip, ArtifactRaw)
+	}
+
+	if len(a.SHA256) == 0 && a.Signature == nil {
+		return errors.New("sha256 or signature is required")
+	}
+	for _, platform := range sortedKeys(a.SHA256) {
+		if platform != AnyPlatform && strings.Count(platform, "/") != 1 {
+			return fmt.Errorf("sha256: invalid platform %q: must be os/arch", platform)
+		}
+		if digest := a.SHA256[platform]; len(digest) != 64 || !isHex(digest) {
+			return fmt.Errorf("sha256: %s must be 64 hex digits", platform)
+		}
+	}
+	if s := a.Signature; s != nil {
+		if err := checkArtifactURL(s.URL); err != nil {
+			return fmt.Errorf("signature: url: %w", err)
+		}
+		if _, err := ParsePublicKey(s.PublicKey); err != nil {
+			return fmt.Errorf("signature: %w", err)
+		}
+	}
+
+	for _, name := range a.Services {
+		if c.Services[name] == nil {
+			return fmt.Errorf("undefined service %s", name)
+		}
+	}
+	return nil
+}
+
+// checkArtifactURL checks that a template only uses known placeholders and expands to an HTTPS URL
+func checkArtifactURL(template string) error {
+	if template == "" {
+		return errors.New("is required")
+	}
+	for _, match := range artifactPlaceholder.FindAllStringSubmatch(template, -1) {
+		switch match[1] {
+		case "name", "version", "os", "arch":
+		default:
+			return fmt.Errorf("unknown placeholder %s", match[0])
+		}
+	}
+	u, err := url.Parse(artifactPlaceholder.ReplaceAllString(template, "x"))
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%s must be an HTTPS URL", template)
+	}
+	return nil
+}
+
+// isHex reports whether s only holds hex digits
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// sortedKeys returns the keys of a string map in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Artifacts maps an artifact name to its declaration.
+//
+// Example:
+//
+//	artifacts:
+//	  vault:
+//	    version: 1.15.4
+//	    url: https://releases.hashicorp.com/vault/{{version}}/vault_{{version}}_{{os}}_{{arch}}.zip
+//	    path: /usr/local/bin/vault
+//	    sha256:
+//	      linux/amd64: f42f550...
+//	      linux/arm64: 7fbc8e2...
+//	    services: [vault]
+type Artifacts map[string]*ArtifactConfig
+
+// Names returns the artifact names in order.
+//
+// Returns:
+//   - names: []string sorted artifact names
+func (a Artifacts) Names() []string {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyDefaults fills every artifact
+func (a Artifacts) applyDefaults(dir string) error {
+	for name, artifact := range a {
+		if artifact == nil {
+			return fmt.Errorf("artifact %s has no definition", name)
+		}
+		artifact.applyDefaults(name, dir)
+	}
+	return nil
+}
+
+// validate checks every artifact and that no two are installed at the same path
+func (a Artifacts) validate(c *SupervizConfig) error {
+	paths := make(map[string]string, len(a))
+	for _, name := range a.Names() {
+		artifact := a[name]
+		if err := artifact.validate(c); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if other, ok := paths[artifact.Path]; ok {
+			return fmt.Errorf("%s: path %s is already used by %s", name, artifact.Path, other)
+		}
+		paths[artifact.Path] = name
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package providers
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSupervizConfig_Artifacts(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(make([]byte, ed25519.PublicKeySize))
+	config, err := ParseSupervizConfig([]byte(`
+artifacts:
+  vault:
+    version: 1.15.4
+    url: https://releases.example.com/vault/{{version}}/vault_{{version}}_{{os}}_{{arch}}.zip
+    path: bin/vault
+    sha256:
+      linux/amd64: `+strings.ToUpper(testSHA256)+`
+    services: [a]
+  jq:
+    version: "1.7"
+    url: https://example.com/{{name}}-{{ os }}-{{arch}}
+    path: /usr/local/bin/jq
+    mode: 0750
+    sha256: `+testSHA256+`
+  tool:
+    version: "2"
+    url: https://example.com/tool.tgz
+    extract: dist/tool
+    path: tool
+    signature:
+      url: https://example.com/tool.tgz.sig
+      public_key: `+key+`
+services:
+  a: {command: x}
+`), "/srv")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jq", "tool", "vault"}, config.Artifacts.Names())
+
+	vault := config.Artifacts["vault"]
+	assert.Equal(t, &ArtifactConfig{
+		Name:     "vault",
+		Version:  "1.15.4",
+		URL:      "https://releases.example.com/vault/{{version}}/vault_{{version}}_{{os}}_{{arch}}.zip",
+		Format:   ArtifactZip,
+		Extract:  "vault",
+		Path:     "/srv/bin/vault",
+		Mode:     DefaultArtifactMode,
+		SHA256:   PlatformDigests{"linux/amd64": strings.ToUpper(testSHA256)},
+		Services: []string{"a"},
+	}, vault)
+	assert.Equal(t, "https://releases.example.com/vault/1.15.4/vault_1.15.4_linux_arm64.zip", vault.Expand(vault.URL, "linux", "arm64"))
+	assert.Equal(t, testSHA256, vault.SHA256.For("linux", "amd64"), "digests compare in lower case")
+	assert.Empty(t, vault.SHA256.For("darwin", "arm64"))
+
+	jq := config.Artifacts["jq"]
+	assert.Equal(t, ArtifactRaw, jq.Format)
+	assert.Empty(t, jq.Extract)
+	assert.Equal(t, FileMode(0o750), jq.Mode)
+	assert.Equal(t, testSHA256, jq.SHA256.For("windows", "amd64"), "a single digest applies to every platform")
+	assert.Equal(t, "https://example.com/jq-windows-amd64", jq.Expand(jq.URL, "windows", "amd64"))
+
+	tool := config.Artifacts["tool"]
+	assert.Equal(t, ArtifactTarGz, tool.Format)
+	assert.Equal(t, "/srv/tool", tool.Path)
+}
+
+func TestParseSupervizConfig_InvalidArtifacts(t *testing.T) {
+	const valid = "version: '1', path: /bin/a, sha256: "
+	tests := map[string]struct {
+		artifact string
+		want     string
+	}{
+		"empty":         {"~", "artifact a has no definition"},
+		"version":       {"{url: https://x/a, path: /bin/a, sha256: " + testSHA256 + "}", "artifacts: a: version is required"},
+		"path":          {"{version: '1', url: https://x/a, sha256: " + testSHA256 + "}", "artifacts: a: path is required"},
+		"url":           {"{" + valid + testSHA256 + "}", "artifacts: a: url: is required"},
+		"http":          {"{url: http://x/a, " + valid + testSHA256 + "}", "url: http://x/a must be an HTTPS URL"},
+		"placeholder":   {"{url: 'https://x/{{platform}}', " + valid + testSHA256 + "}", "url: unknown placeholder {{platform}}"},
+		"format":        {"{url: https://x/a, format: rar, " + valid + testSHA256 + "}", `invalid format "rar"`},
+		"raw extract":   {"{url: https://x/a, extract: a, " + valid + testSHA256 + "}", "extract requires a tar.gz or zip format"},
+		"no check":      {"{url: https://x/a, version: '1', path: /bin/a}", "sha256 or signature is required"},
+		"digest":        {"{url: https://x/a, " + valid + "abcd}", "sha256: * must be 64 hex digits"},
+		"platform":      {"{url: https://x/a, version: '1', path: /bin/a, sha256: {linux: " + testSHA256 + "}}", `sha256: invalid platform "linux"`},
+		"signature url": {"{url: https://x/a, version: '1', path: /bin/a, signature: {public_key: AAAA}}", "signature: url: is required"},
+		"signature key": {"{url: https://x/a, version: '1', path: /bin/a, signature: {url: https://x/a.sig, public_key: AAAA}}", "signature: invalid public key"},
+		"service":       {"{url: https://x/a, services: [b], " + valid + testSHA256 + "}", "artifacts: a: undefined service b"},
+		"mode":          {"{url: https://x/a, mode: 999, " + valid + testSHA256 + "}", `invalid file mode "999"`},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSupervizConfig([]byte("artifacts:\n  a: "+tt.artifact+"\nservices:\n  a: {command: x}\n"), "/srv")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+
+	_, err := ParseSupervizConfig([]byte(`
+artifacts:
+  a: {version: '1', url: https://x/a, path: /bin/x, sha256: `+testSHA256+`}
+  b: {version: '1', url: https://x/b, path: /bin/x, sha256: `+testSHA256+`}
+services:
+  a: {command: x}
+`), "/srv")
+	assert.ErrorContains(t, err, "artifacts: b: path /bin/x is already used by a")
+}
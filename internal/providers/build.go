@@ -0,0 +1,149 @@
+// internal/providers/build.go - Lock file recording a pre-runtime build
+package providers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Build lock defaults.
+const (
+	// DefaultBuildLockPath records the last build, relative to the configuration directory
+	DefaultBuildLockPath = ".superviz/build.lock"
+	// BuildLockVersion is the supported lock file schema version
+	BuildLockVersion = 1
+)
+
+// BuildLockArtifact is an artifact installed by a build.
+type BuildLockArtifact struct {
+	// Name is the artifact name
+	Name string `json:"name"`
+	// Version is the installed version
+	Version string `json:"version"`
+	// Path is the installed file
+	Path string `json:"path"`
+	// SHA256 is the hex digest of the download
+	SHA256 string `json:"sha256"`
+}
+
+// BuildLock records the provisioning of a configuration, so that svz run
+// can skip it.
+type BuildLock struct {
+	// Version is the schema version, 1
+	Version int `json:"version"`
+	// Hash is the ProvisioningHash of the configuration built
+	Hash string `json:"hash"`
+	// OS is the operating system built for
+	OS string `json:"os"`
+	// Arch is the architecture built for
+	Arch string `json:"arch"`
+	// BuiltAt is when the build completed
+	BuiltAt time.Time `json:"built_at"`
+	// Artifacts are the artifacts installed
+	Artifacts []BuildLockArtifact `json:"artifacts,omitempty"`
+}
+
+// LoadBuildLock reads a lock file.
+//
+// Parameters:
+//   - path: string lock file location
+//
+// Returns:
+//   - lock: *BuildLock recorded build
+//   - err: error if the file cannot be read, is malformed or has another version
+func LoadBuildLock(path string) (*BuildLock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read build lock: %w", err)
+	}
+	var lock BuildLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse build lock %s: %w", path, err)
+	}
+	if lock.Version != BuildLockVersion {
+		return nil, fmt.Errorf("unsupported build lock version %d, expected %d", lock.Version, BuildLockVersion)
+	}
+	return &lock, nil
+}
+
+// Write stores the lock atomically, creating its directory.
+//
+// Parameters:
+//   - path: string lock file location
+//
+// Returns:
+//   - err: error if the file cannot be written
+func (l *BuildLock) Write(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to write build lock: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to write build lock: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // gone once renamed
+
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write build lock: %w", err)
+	}
+	return nil
+}
+
+// Satisfies reports whether the lock records a build of the configuration
+// for the platform.
+//
+// Parameters:
+//   - hash: string ProvisioningHash of the configuration
+//   - goos: string operating system
+//   - goarch: string architecture
+//
+// Returns:
+//   - satisfied: bool true when hash and platform match
+func (l *BuildLock) Satisfies(hash, goos, goarch string) bool {
+	return l.Hash == hash && l.OS == goos && l.Arch == goarch
+}
+
+// ProvisioningHash digests the sections provisioned by svz build, so that a
+// change to the services alone keeps the build valid. Only the artifacts and
+// integrity sections are provisioned, there are no generated configs to hash.
+//
+// Parameters:
+//   - goos: string operating system the artifacts are installed for
+//   - goarch: string architecture the artifacts are installed for
+//
+// Returns:
+//   - hash: string hex SHA-256 digest of the artifacts and integrity sections
+//   - err: error if the sections cannot be encoded
+func (c *SupervizConfig) ProvisioningHash(goos, goarch string) (string, error) {
+	if c == nil {
+		return "", errors.New("config cannot be nil")
+	}
+	data, err := json.Marshal(struct {
+		OS        string
+		Arch      string
+		Artifacts Artifacts
+		Integrity *IntegrityConfig
+	}{goos, goarch, c.Artifacts, c.Integrity})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
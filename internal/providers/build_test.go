@@ -0,0 +1,74 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildLock_WriteLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".superviz", "build.lock")
+	lock := &BuildLock{
+		Version: BuildLockVersion, Hash: "abc", OS: "linux", Arch: "amd64",
+		BuiltAt:   time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+		Artifacts: []BuildLockArtifact{{Name: "vault", Version: "1.15.4", Path: "/usr/local/bin/vault", SHA256: testSHA256}},
+	}
+	require.NoError(t, lock.Write(path))
+
+	loaded, err := LoadBuildLock(path)
+	require.NoError(t, err)
+	assert.Equal(t, lock, loaded)
+	assert.True(t, loaded.Satisfies("abc", "linux", "amd64"))
+	assert.False(t, loaded.Satisfies("abd", "linux", "amd64"))
+	assert.False(t, loaded.Satisfies("abc", "linux", "arm64"))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary file is left behind")
+}
+
+func TestLoadBuildLock_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	_, err := LoadBuildLock(filepath.Join(dir, "missing.lock"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	path := filepath.Join(dir, "build.lock")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	_, err = LoadBuildLock(path)
+	assert.ErrorContains(t, err, "failed to parse build lock")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 2}`), 0o600))
+	_, err = LoadBuildLock(path)
+	assert.EqualError(t, err, "unsupported build lock version 2, expected 1")
+}
+
+func TestSupervizConfig_ProvisioningHash(t *testing.T) {
+	parse := func(content string) *SupervizConfig {
+		config, err := ParseSupervizConfig([]byte(content), "/srv")
+		require.NoError(t, err)
+		return config
+	}
+	base := parse("artifacts:\n  jq: {version: '1.7', url: https://x/jq, path: bin/jq, sha256: " + testSHA256 + "}\nservices:\n  a: {command: x}\n")
+	hash, err := base.ProvisioningHash("linux", "amd64")
+	require.NoError(t, err)
+	assert.Len(t, hash, 64)
+
+	same, err := parse("artifacts:\n  jq: {version: '1.7', url: https://x/jq, path: bin/jq, sha256: "+testSHA256+"}\nservices:\n  b: {command: y}\n").ProvisioningHash("linux", "amd64")
+	require.NoError(t, err)
+	assert.Equal(t, hash, same, "the services do not change the build")
+
+	other, err := parse("artifacts:\n  jq: {version: '1.8', url: https://x/jq, path: bin/jq, sha256: "+testSHA256+"}\nservices:\n  a: {command: x}\n").ProvisioningHash("linux", "amd64")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+
+	platform, err := base.ProvisioningHash("linux", "arm64")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, platform)
+
+	_, err = (*SupervizConfig)(nil).ProvisioningHash("linux", "amd64")
+	assert.Error(t, err)
+}
@@ -0,0 +1,193 @@
+// internal/providers/buildinfo.go - Build provenance read from the Go build information
+package providers
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// Module identifies a Go module linked into the binary.
+type Module struct {
+	// Path is the module path
+	Path string `json:"path" yaml:"path"`
+	// Version is the module version, (devel) for the main module of a local build
+	Version string `json:"version" yaml:"version"`
+	// Sum is the go.sum hash of the module, empty for the main module
+	Sum string `json:"sum,omitempty" yaml:"sum,omitempty"`
+	// Replace is the module replacing this one, nil when not replaced
+	Replace *Module `json:"replace,omitempty" yaml:"replace,omitempty"`
+}
+
+// VCSInfo describes the source checkout the binary was built from.
+type VCSInfo struct {
+	// System is the version control system, e.g. git
+	System string `json:"system,omitempty" yaml:"system,omitempty"`
+	// Revision is the commit the binary was built from
+	Revision string `json:"revision,omitempty" yaml:"revision,omitempty"`
+	// Time is the commit time in RFC 3339 format
+	Time string `json:"time,omitempty" yaml:"time,omitempty"`
+	// Modified is true when the checkout had uncommitted changes
+	Modified bool `json:"modified" yaml:"modified"`
+}
+
+// BuildProvenance is the build information the Go toolchain embeds in the binary.
+type BuildProvenance struct {
+	// GoVersion is the toolchain that built the binary
+	GoVersion string `json:"go_version" yaml:"go_version"`
+	// Main is the main module
+	Main Module `json:"main" yaml:"main"`
+	// Dependencies lists the linked modules sorted by path
+	Dependencies []Module `json:"dependencies" yaml:"dependencies"`
+	// VCS describes the source checkout, zero when built outside one
+	VCS VCSInfo `json:"vcs" yaml:"vcs"`
+	// Tags lists the build tags passed with -tags
+	Tags []string `json:"tags" yaml:"tags"`
+	// CGOEnabled reports whether cgo was enabled
+	CGOEnabled bool `json:"cgo_enabled" yaml:"cgo_enabled"`
+	// GOOS and GOARCH are the target platform
+	GOOS   string `json:"goos" yaml:"goos"`
+	GOARCH string `json:"goarch" yaml:"goarch"`
+	// GOAMD64 is the amd64 microarchitecture level, empty on other architectures
+	GOAMD64 string `json:"goamd64,omitempty" yaml:"goamd64,omitempty"`
+}
+
+// ReadBuildProvenance returns the build information of the running binary.
+//
+// Returns:
+//   - provenance: BuildProvenance decoded build information
+//   - ok: bool false if the binary was built without module support
+func ReadBuildProvenance() (BuildProvenance, bool) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return BuildProvenance{}, false
+	}
+	return NewBuildProvenance(info), true
+}
+
+// NewBuildProvenance decodes the build information embedded by the Go toolchain.
+//
+// Parameters:
+//   - info: *debug.BuildInfo build information, typically from debug.ReadBuildInfo
+//
+// Returns:
+//   - provenance: BuildProvenance decoded build information
+func NewBuildProvenance(info *debug.BuildInfo) BuildProvenance {
+	p := BuildProvenance{
+		GoVersion:    info.GoVersion,
+		Main:         newModule(&info.Main),
+		Dependencies: make([]Module, 0, len(info.Deps)),
+		Tags:         []string{},
+	}
+	for _, dep := range info.Deps {
+		p.Dependencies = append(p.Dependencies, newModule(dep))
+	}
+
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "-tags":
+			if setting.Value != "" {
+				p.Tags = strings.Split(setting.Value, ",")
+			}
+		case "CGO_ENABLED":
+			p.CGOEnabled = setting.Value == "1"
+		case "GOOS":
+			p.GOOS = setting.Value
+		case "GOARCH":
+			p.GOARCH = setting.Value
+		case "GOAMD64":
+			p.GOAMD64 = setting.Value
+		case "vcs":
+			p.VCS.System = setting.Value
+		case "vcs.revision":
+			p.VCS.Revision = setting.Value
+		case "vcs.time":
+			p.VCS.Time = setting.Value
+		case "vcs.modified":
+			p.VCS.Modified = setting.Value == "true"
+		}
+	}
+	return p
+}
+
+// newModule converts a debug.Module, following replacements
+func newModule(m *debug.Module) Module {
+	module := Module{Path: m.Path, Version: m.Version, Sum: m.Sum}
+	if m.Replace != nil {
+		replace := newModule(m.Replace)
+		module.Replace = &replace
+	}
+	return module
+}
+
+// Effective returns the module actually linked, the replacement when there is one.
+//
+// Returns:
+//   - module: Module linked into the binary
+func (m Module) Effective() Module {
+	if m.Replace != nil {
+		return *m.Replace
+	}
+	return m
+}
+
+// Format returns a human-readable listing of the build provenance.
+//
+// Example:
+//
+//	Module:        github.com/kodflow/superviz.io (devel)
+//	VCS:           git 1a2b3c4 (modified)
+//	VCS time:      2025-06-01T10:00:00Z
+//	Build tags:    none
+//	CGO:           disabled
+//	GOAMD64:       v1
+//	Dependencies:
+//	  github.com/spf13/cobra v1.9.1
+//
+// Returns:
+//   - formatted: string multi-line listing ending with a newline
+func (p BuildProvenance) Format() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Module:        %s %s\n", p.Main.Path, p.Main.Version)
+
+	vcs := "unknown"
+	if p.VCS.Revision != "" {
+		vcs = p.VCS.System + " " + p.VCS.Revision
+		if p.VCS.Modified {
+			vcs += " (modified)"
+		}
+	}
+	fmt.Fprintf(&b, "VCS:           %s\n", vcs)
+	if p.VCS.Time != "" {
+		fmt.Fprintf(&b, "VCS time:      %s\n", p.VCS.Time)
+	}
+
+	tags := "none"
+	if len(p.Tags) > 0 {
+		tags = strings.Join(p.Tags, ",")
+	}
+	fmt.Fprintf(&b, "Build tags:    %s\n", tags)
+
+	cgo := "disabled"
+	if p.CGOEnabled {
+		cgo = "enabled"
+	}
+	fmt.Fprintf(&b, "CGO:           %s\n", cgo)
+	if p.GOAMD64 != "" {
+		fmt.Fprintf(&b, "GOAMD64:       %s\n", p.GOAMD64)
+	}
+
+	b.WriteString("Dependencies:\n")
+	if len(p.Dependencies) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, dep := range p.Dependencies {
+		fmt.Fprintf(&b, "  %s %s", dep.Path, dep.Version)
+		if dep.Replace != nil {
+			fmt.Fprintf(&b, " => %s %s", dep.Replace.Path, dep.Replace.Version)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package providers
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBuildInfo() *debug.BuildInfo {
+	return &debug.BuildInfo{
+		GoVersion: "go1.24.3",
+		Main:      debug.Module{Path: "github.com/kodflow/superviz.io", Version: "v1.3.0"},
+		Deps: []*debug.Module{
+			{Path: "github.com/spf13/cobra", Version: "v1.9.1", Sum: "h1:cobra="},
+			{Path: "golang.org/x/crypto", Version: "v0.39.0", Sum: "h1:crypto=",
+				Replace: &debug.Module{Path: "example.com/fork/crypto", Version: "v0.39.1", Sum: "h1:fork="}},
+		},
+		Settings: []debug.BuildSetting{
+			{Key: "-tags", Value: "netgo,osusergo"},
+			{Key: "CGO_ENABLED", Value: "0"},
+			{Key: "GOARCH", Value: "amd64"},
+			{Key: "GOOS", Value: "linux"},
+			{Key: "GOAMD64", Value: "v3"},
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "1a2b3c4d"},
+			{Key: "vcs.time", Value: "2025-06-01T10:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+}
+
+func TestNewBuildProvenance(t *testing.T) {
+	p := NewBuildProvenance(testBuildInfo())
+
+	assert.Equal(t, "go1.24.3", p.GoVersion)
+	assert.Equal(t, Module{Path: "github.com/kodflow/superviz.io", Version: "v1.3.0"}, p.Main)
+	assert.Equal(t, []string{"netgo", "osusergo"}, p.Tags)
+	assert.False(t, p.CGOEnabled)
+	assert.Equal(t, "linux", p.GOOS)
+	assert.Equal(t, "amd64", p.GOARCH)
+	assert.Equal(t, "v3", p.GOAMD64)
+	assert.Equal(t, VCSInfo{System: "git", Revision: "1a2b3c4d", Time: "2025-06-01T10:00:00Z", Modified: true}, p.VCS)
+
+	require.Len(t, p.Dependencies, 2)
+	assert.Nil(t, p.Dependencies[0].Replace)
+	assert.Equal(t, "example.com/fork/crypto", p.Dependencies[1].Effective().Path)
+	assert.Equal(t, "github.com/spf13/cobra", p.Dependencies[0].Effective().Path)
+}
+
+func TestNewBuildProvenance_Minimal(t *testing.T) {
+	p := NewBuildProvenance(&debug.BuildInfo{Main: debug.Module{Path: "m", Version: "(devel)"}})
+
+	assert.Empty(t, p.Tags)
+	assert.NotNil(t, p.Tags, "tags encode as an empty list")
+	assert.NotNil(t, p.Dependencies)
+	assert.Contains(t, p.Format(), "VCS:           unknown\nBuild tags:    none\nCGO:           disabled\nDependencies:\n  none\n")
+}
+
+func TestBuildProvenance_Format(t *testing.T) {
+	assert.Equal(t, `Module:        github.com/kodflow/superviz.io v1.3.0
+VCS:           git 1a2b3c4d (modified)
+VCS time:      2025-06-01T10:00:00Z
+Build tags:    netgo,osusergo
+CGO:           disabled
+GOAMD64:       v3
+Dependencies:
+  github.com/spf13/cobra v1.9.1
+  golang.org/x/crypto v0.39.0 => example.com/fork/crypto v0.39.1
+`, NewBuildProvenance(testBuildInfo()).Format())
+}
+
+func TestReadBuildProvenance(t *testing.T) {
+	p, ok := ReadBuildProvenance()
+	require.True(t, ok, "test binaries carry build information")
+	assert.NotEmpty(t, p.GoVersion)
+}
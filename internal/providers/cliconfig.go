@@ -0,0 +1,361 @@
+// internal/providers/cliconfig.go - Layered CLI configuration
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Well-known CLI configuration locations and keys.
+const (
+	// SystemCLIConfigPath is the machine-wide configuration file
+	SystemCLIConfigPath = "/etc/superviz/cli.yaml"
+	// CLIEnvPrefix prefixes environment variables overriding flags (SVZ_SSH_KEY -> ssh-key)
+	CLIEnvPrefix = "SVZ_"
+	// ProfileKey selects the named profile, as a file key, flag or SVZ_PROFILE
+	ProfileKey = "profile"
+	// profilesKey holds the named profiles in a configuration file
+	profilesKey = "profiles"
+)
+
+// CLISetting is a configured flag value together with its origin.
+type CLISetting struct {
+	// Value is the raw flag value
+	Value string
+	// Source describes where the value came from, for error messages
+	Source string
+}
+
+// CLIConfigOptions controls where configuration layers are read from.
+//
+// All fields are optional; nil or empty values fall back to the real
+// system locations and process environment.
+type CLIConfigOptions struct {
+	// SystemPath overrides SystemCLIConfigPath
+	SystemPath string
+	// UserPath overrides the per-user file (~/.config/superviz/cli.yaml)
+	UserPath string
+	// Environ overrides os.Environ
+	Environ []string
+	// ReadFile overrides os.ReadFile
+	ReadFile func(path string) ([]byte, error)
+	// SkipInvalid skips unreadable or malformed files, reported by Skipped, instead of failing
+	SkipInvalid bool
+}
+
+// cliConfigFile is one parsed configuration file.
+type cliConfigFile struct {
+	// path is the file location
+	path string
+	// settings are the top-level flag defaults
+	settings map[string]string
+	// profiles are the named profile sections
+	profiles map[string]map[string]string
+}
+
+// CLIConfig holds every configuration layer below command-line flags.
+//
+// Precedence, lowest to highest: system file, user file, the selected
+// profile of the system file, the selected profile of the user file,
+// SVZ_* environment variables. Flags given on the command line win over all.
+//
+// Example:
+//
+//	# ~/.config/superviz/cli.yaml
+//	ssh-key: ~/.ssh/id_ed25519
+//	timeout: 5m
+//	profiles:
+//	  staging:
+//	    repo-mirror: https://mirror.staging.example.com/superviz
+//	    jump-host: ops@bastion.staging.example.com
+type CLIConfig struct {
+	// files are the configuration files found, lowest precedence first
+	files []cliConfigFile
+	// env maps flag names to SVZ_* environment values
+	env map[string]CLISetting
+	// envProfile is the value of SVZ_PROFILE
+	envProfile string
+	// skipped are the errors of the files left out with SkipInvalid
+	skipped []error
+}
+
+// LoadCLIConfig reads the configuration files and environment.
+//
+// Missing files are skipped; unreadable or malformed files are errors,
+// unless opts.SkipInvalid is set.
+// Values starting with "~/" are expanded to the user's home directory.
+//
+// Example:
+//
+//	config, err := LoadCLIConfig(nil)
+//	settings, err := config.Settings(config.Profile())
+//
+// Parameters:
+//   - opts: *CLIConfigOptions optional overrides, nil for system defaults
+//
+// Returns:
+//   - config: *CLIConfig loaded layers
+//   - err: error if a file cannot be read or parsed
+func LoadCLIConfig(opts *CLIConfigOptions) (*CLIConfig, error) {
+	if opts == nil {
+		opts = &CLIConfigOptions{}
+	}
+
+	systemPath := opts.SystemPath
+	if systemPath == "" {
+		systemPath = SystemCLIConfigPath
+	}
+	userPath := opts.UserPath
+	if userPath == "" {
+		userPath = userCLIConfigPath()
+	}
+	environ := opts.Environ
+	if environ == nil {
+		environ = os.Environ()
+	}
+	readFile := opts.ReadFile
+	if readFile == nil {
+		readFile = os.ReadFile
+	}
+
+	config := &CLIConfig{env: make(map[string]CLISetting)}
+
+	for _, path := range []string{systemPath, userPath} {
+		if path == "" {
+			continue
+		}
+		data, err := readFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		var file *cliConfigFile
+		if err == nil {
+			file, err = parseCLIConfigFile(path, data)
+		}
+		if err != nil && opts.SkipInvalid {
+			config.skipped = append(config.skipped, err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		config.files = append(config.files, *file)
+	}
+
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, CLIEnvPrefix) || value == "" {
+			continue
+		}
+		key := strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(name, CLIEnvPrefix), "_", "-"))
+		if key == ProfileKey {
+			config.envProfile = value
+			continue
+		}
+		config.env[key] = CLISetting{Value: expandHome(value), Source: "environment variable " + name}
+	}
+
+	return config, nil
+}
+
+// Profile returns the profile selected by configuration.
+//
+// SVZ_PROFILE wins over a top-level "profile" key; the user file wins over
+// the system file. The --profile flag, when given, overrides this choice.
+//
+// Returns:
+//   - name: string selected profile, empty when none
+func (c *CLIConfig) Profile() string {
+	if c.envProfile != "" {
+		return c.envProfile
+	}
+	for i := len(c.files) - 1; i >= 0; i-- {
+		if name := c.files[i].settings[ProfileKey]; name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// EnvProfile returns the profile selected by SVZ_PROFILE.
+//
+// Returns:
+//   - name: string SVZ_PROFILE value, empty when unset
+func (c *CLIConfig) EnvProfile() string {
+	return c.envProfile
+}
+
+// Skipped returns the errors of the files left out by SkipInvalid.
+//
+// Returns:
+//   - errs: []error one error per unreadable or malformed file
+func (c *CLIConfig) Skipped() []error {
+	return c.skipped
+}
+
+// Settings merges all layers for the given profile.
+//
+// Parameters:
+//   - profile: string profile name, empty for no profile
+//
+// Returns:
+//   - settings: map[string]CLISetting flag values keyed by flag name
+//   - err: error if the profile is not defined in any file
+func (c *CLIConfig) Settings(profile string) (map[string]CLISetting, error) {
+	settings := make(map[string]CLISetting)
+
+	for _, file := range c.files {
+		for key, value := range file.settings {
+			if key == ProfileKey {
+				continue
+			}
+			settings[key] = CLISetting{Value: value, Source: file.path}
+		}
+	}
+
+	if profile != "" {
+		found := false
+		for _, file := range c.files {
+			values, ok := file.profiles[profile]
+			if !ok {
+				continue
+			}
+			found = true
+			for key, value := range values {
+				settings[key] = CLISetting{Value: value, Source: fmt.Sprintf("%s (profile %s)", file.path, profile)}
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown profile %q, available profiles: %s", profile, c.profileList())
+		}
+	}
+
+	for key, setting := range c.env {
+		settings[key] = setting
+	}
+
+	return settings, nil
+}
+
+// profileList returns the defined profile names for error messages.
+func (c *CLIConfig) profileList() string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, file := range c.files {
+		for name := range file.profiles {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// parseCLIConfigFile parses a YAML configuration file.
+//
+// Parameters:
+//   - path: string file location, used in error messages
+//   - data: []byte file content
+//
+// Returns:
+//   - file: *cliConfigFile parsed settings and profiles
+//   - err: error if the YAML is invalid or values are not scalars
+func parseCLIConfigFile(path string, data []byte) (*cliConfigFile, error) {
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	file := &cliConfigFile{
+		path:     path,
+		settings: make(map[string]string),
+		profiles: make(map[string]map[string]string),
+	}
+
+	for key, value := range raw {
+		if key != profilesKey {
+			s, err := scalarValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s %w", path, key, err)
+			}
+			file.settings[key] = s
+			continue
+		}
+
+		profiles, ok := value.(map[string]any)
+		if !ok && value != nil {
+			return nil, fmt.Errorf("%s: %s must be a mapping of profile names", path, profilesKey)
+		}
+		for name, section := range profiles {
+			values, ok := section.(map[string]any)
+			if !ok && section != nil {
+				return nil, fmt.Errorf("%s: profile %s must be a mapping", path, name)
+			}
+			file.profiles[name] = make(map[string]string, len(values))
+			for key, v := range values {
+				s, err := scalarValue(v)
+				if err != nil {
+					return nil, fmt.Errorf("%s: profile %s: %s %w", path, name, key, err)
+				}
+				file.profiles[name][key] = s
+			}
+		}
+	}
+
+	return file, nil
+}
+
+// scalarValue converts a decoded YAML scalar to a flag value.
+func scalarValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case map[string]any, []any:
+		return "", errors.New("must be a scalar value")
+	case string:
+		return expandHome(v), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// expandHome replaces a leading "~/" with the user's home directory.
+func expandHome(value string) string {
+	if !strings.HasPrefix(value, "~/") {
+		return value
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return value
+	}
+	return filepath.Join(home, value[2:])
+}
+
+// userCLIConfigPath returns the per-user configuration file location.
+//
+// $XDG_CONFIG_HOME is honored; otherwise ~/.config is used on every platform.
+func userCLIConfigPath() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "superviz", "cli.yaml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "superviz", "cli.yaml")
+}
@@ -0,0 +1,188 @@
+package providers
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSystemConfig = `
+ssh-port: 2222
+timeout: 2m
+skip-host-key-check: false
+profiles:
+  staging:
+    repo-mirror: https://mirror.system.example.com
+    jump-host: ops@bastion
+`
+
+const testUserConfig = `
+ssh-key: ~/.ssh/deploy
+timeout: 5m
+profiles:
+  staging:
+    jump-host: me@bastion:2200
+  prod:
+    repo-mirror: https://mirror.prod.example.com
+`
+
+// newTestCLIConfig loads config from in-memory files.
+func newTestCLIConfig(t *testing.T, files map[string]string, environ []string) *CLIConfig {
+	t.Helper()
+	config, err := LoadCLIConfig(&CLIConfigOptions{
+		SystemPath: "/system.yaml",
+		UserPath:   "/user.yaml",
+		Environ:    environ,
+		ReadFile: func(path string) ([]byte, error) {
+			content, ok := files[path]
+			if !ok {
+				return nil, fs.ErrNotExist
+			}
+			return []byte(content), nil
+		},
+	})
+	require.NoError(t, err)
+	return config
+}
+
+func TestLoadCLIConfig_Layering(t *testing.T) {
+	config := newTestCLIConfig(t, map[string]string{
+		"/system.yaml": testSystemConfig,
+		"/user.yaml":   testUserConfig,
+	}, []string{"SVZ_SSH_PORT=2022", "HOME_UNRELATED=1", "SVZ_EMPTY="})
+
+	settings, err := config.Settings("")
+	require.NoError(t, err)
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	assert.Equal(t, CLISetting{Value: "2022", Source: "environment variable SVZ_SSH_PORT"}, settings["ssh-port"])
+	assert.Equal(t, CLISetting{Value: "5m", Source: "/user.yaml"}, settings["timeout"])
+	assert.Equal(t, filepath.Join(home, ".ssh/deploy"), settings["ssh-key"].Value)
+	assert.Equal(t, "false", settings["skip-host-key-check"].Value)
+	assert.NotContains(t, settings, "repo-mirror")
+	assert.NotContains(t, settings, "empty")
+}
+
+func TestLoadCLIConfig_Profile(t *testing.T) {
+	config := newTestCLIConfig(t, map[string]string{
+		"/system.yaml": testSystemConfig,
+		"/user.yaml":   testUserConfig,
+	}, nil)
+
+	settings, err := config.Settings("staging")
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://mirror.system.example.com", settings["repo-mirror"].Value)
+	assert.Equal(t, CLISetting{Value: "me@bastion:2200", Source: "/user.yaml (profile staging)"}, settings["jump-host"])
+	assert.Equal(t, "2222", settings["ssh-port"].Value)
+
+	_, err = config.Settings("qa")
+	assert.EqualError(t, err, `unknown profile "qa", available profiles: prod, staging`)
+}
+
+func TestLoadCLIConfig_ProfileSelection(t *testing.T) {
+	files := map[string]string{
+		"/system.yaml": "profile: staging\nprofiles:\n  staging: {}\n",
+		"/user.yaml":   "profile: prod\n",
+	}
+
+	config := newTestCLIConfig(t, files, nil)
+	assert.Equal(t, "prod", config.Profile())
+
+	config = newTestCLIConfig(t, files, []string{"SVZ_PROFILE=staging"})
+	assert.Equal(t, "staging", config.Profile())
+
+	settings, err := config.Settings("staging")
+	require.NoError(t, err)
+	assert.NotContains(t, settings, ProfileKey)
+
+	assert.Empty(t, newTestCLIConfig(t, nil, nil).Profile())
+}
+
+func TestLoadCLIConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "invalid yaml", content: "ssh-port: [", wantErr: "failed to parse /user.yaml"},
+		{name: "nested value", content: "ssh-key:\n  path: x\n", wantErr: "ssh-key must be a scalar value"},
+		{name: "profiles not a mapping", content: "profiles: [a]\n", wantErr: "profiles must be a mapping"},
+		{name: "profile not a mapping", content: "profiles:\n  a: b\n", wantErr: "profile a must be a mapping"},
+		{name: "profile nested value", content: "profiles:\n  a:\n    timeout: [1]\n", wantErr: "profile a: timeout must be a scalar value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadCLIConfig(&CLIConfigOptions{
+				SystemPath: "/missing.yaml",
+				UserPath:   "/user.yaml",
+				Environ:    []string{},
+				ReadFile: func(path string) ([]byte, error) {
+					if path == "/user.yaml" {
+						return []byte(tt.content), nil
+					}
+					return nil, fs.ErrNotExist
+				},
+			})
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadCLIConfig_ReadError(t *testing.T) {
+	_, err := LoadCLIConfig(&CLIConfigOptions{
+		SystemPath: "/system.yaml",
+		UserPath:   "/user.yaml",
+		Environ:    []string{},
+		ReadFile: func(string) ([]byte, error) {
+			return nil, fs.ErrPermission
+		},
+	})
+	assert.ErrorIs(t, err, fs.ErrPermission)
+}
+
+func TestLoadCLIConfig_SkipInvalid(t *testing.T) {
+	config, err := LoadCLIConfig(&CLIConfigOptions{
+		SystemPath:  "/system.yaml",
+		UserPath:    "/user.yaml",
+		Environ:     []string{"SVZ_PROFILE=staging", "SVZ_SSH_PORT=2200"},
+		SkipInvalid: true,
+		ReadFile: func(path string) ([]byte, error) {
+			if path == "/user.yaml" {
+				return []byte("ssh-port: ["), nil
+			}
+			return []byte("timeout: 5m\n"), nil
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, config.Skipped(), 1)
+	assert.ErrorContains(t, config.Skipped()[0], "failed to parse /user.yaml")
+	assert.Equal(t, "staging", config.EnvProfile())
+
+	settings, err := config.Settings("")
+	require.NoError(t, err)
+	assert.Equal(t, "5m", settings["timeout"].Value)
+	assert.Equal(t, "2200", settings["ssh-port"].Value)
+}
+
+func TestLoadCLIConfig_RealFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "superviz"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "superviz", "cli.yaml"), []byte("ssh-port: 2200\n"), 0o600))
+
+	config, err := LoadCLIConfig(&CLIConfigOptions{SystemPath: filepath.Join(dir, "none.yaml"), Environ: []string{}})
+	require.NoError(t, err)
+
+	settings, err := config.Settings("")
+	require.NoError(t, err)
+	assert.Equal(t, "2200", settings["ssh-port"].Value)
+}
@@ -0,0 +1,127 @@
+// internal/providers/control.go - Control API settings of superviz.yaml
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Control API defaults.
+const (
+	// DefaultControlSocket is the control socket, relative to the configuration directory
+	DefaultControlSocket = "superviz.sock"
+	// DefaultControlSocketMode restricts the control socket to its owner
+	DefaultControlSocketMode FileMode = 0o600
+)
+
+// FileMode is a permission mode written in octal, e.g. 0660 or "0o660".
+type FileMode os.FileMode
+
+// UnmarshalYAML parses the octal notation.
+//
+// Parameters:
+//   - node: *yaml.Node scalar mode
+//
+// Returns:
+//   - err: error if the value is not an octal permission mode
+func (m *FileMode) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return errors.New("file mode must be an octal number such as 0660")
+	}
+	value := strings.TrimPrefix(strings.TrimPrefix(node.Value, "0o"), "0O")
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || mode > 0o777 {
+		return fmt.Errorf("invalid file mode %q: must be an octal number such as 0660", node.Value)
+	}
+	*m = FileMode(mode)
+	return nil
+}
+
+// String returns the mode in octal.
+//
+// Returns:
+//   - mode: string such as "0660"
+func (m FileMode) String() string {
+	return fmt.Sprintf("%04o", uint32(m))
+}
+
+// ControlTCPConfig exposes the control API over TCP with mutual TLS.
+type ControlTCPConfig struct {
+	// Address is the host:port listened on
+	Address string `yaml:"address"`
+	// Cert is the PEM server certificate, relative to the configuration directory
+	Cert string `yaml:"cert"`
+	// Key is the PEM server private key
+	Key string `yaml:"key"`
+	// ClientCA is the PEM bundle client certificates must chain to
+	ClientCA string `yaml:"client_ca"`
+}
+
+// ControlConfig enables the control API used by svz ctl.
+//
+// Example:
+//
+//	control:
+//	  socket: /run/superviz.sock
+//	  socket_mode: 0660
+//	  socket_group: superviz
+//	  tcp:
+//	    address: 0.0.0.0:7443
+//	    cert: tls/server.pem
+//	    key: tls/server-key.pem
+//	    client_ca: tls/clients.pem
+type ControlConfig struct {
+	// Socket is the Unix socket path, relative to the configuration directory, default superviz.sock
+	Socket string `yaml:"socket,omitempty"`
+	// SocketMode is the permission of the socket, default 0600
+	SocketMode FileMode `yaml:"socket_mode,omitempty"`
+	// SocketGroup owns the socket, so its members can connect with mode 0660 (Unix only)
+	SocketGroup string `yaml:"socket_group,omitempty"`
+	// TCP additionally listens on TCP with mutual TLS
+	TCP *ControlTCPConfig `yaml:"tcp,omitempty"`
+}
+
+// applyDefaults fills the socket and resolves the paths against dir
+func (c *ControlConfig) applyDefaults(dir string) {
+	if c.Socket == "" {
+		c.Socket = DefaultControlSocket
+	}
+	if c.SocketMode == 0 {
+		c.SocketMode = DefaultControlSocketMode
+	}
+	c.Socket = resolvePath(dir, c.Socket)
+	if c.TCP != nil {
+		c.TCP.Cert = resolvePath(dir, c.TCP.Cert)
+		c.TCP.Key = resolvePath(dir, c.TCP.Key)
+		c.TCP.ClientCA = resolvePath(dir, c.TCP.ClientCA)
+	}
+}
+
+// validate checks the TCP listener, which requires every TLS file
+func (c *ControlConfig) validate() error {
+	if c.TCP == nil {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(c.TCP.Address); err != nil {
+		return fmt.Errorf("invalid tcp.address %q: must be host:port", c.TCP.Address)
+	}
+	if c.TCP.Cert == "" || c.TCP.Key == "" || c.TCP.ClientCA == "" {
+		return errors.New("tcp requires cert, key and client_ca")
+	}
+	return nil
+}
+
+// resolvePath makes a relative path absolute against dir, leaving empty paths empty
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) || dir == "" {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
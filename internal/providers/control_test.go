@@ -0,0 +1,80 @@
+package providers
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseSupervizConfig_Control(t *testing.T) {
+	config, err := ParseSupervizConfig([]byte("services:\n  a: {command: x}\n"), "/srv")
+	require.NoError(t, err)
+	assert.Nil(t, config.Control, "the control API is opt-in")
+
+	config, err = ParseSupervizConfig([]byte("control: {}\nservices:\n  a: {command: x}\n"), "/srv")
+	require.NoError(t, err)
+	require.NotNil(t, config.Control)
+	assert.Equal(t, filepath.Join("/srv", DefaultControlSocket), config.Control.Socket)
+	assert.Equal(t, DefaultControlSocketMode, config.Control.SocketMode)
+
+	config, err = ParseSupervizConfig([]byte(`
+control:
+  socket: /run/superviz.sock
+  socket_mode: 0660
+  socket_group: superviz
+  tcp:
+    address: 0.0.0.0:7443
+    cert: tls/server.pem
+    key: /etc/superviz/server-key.pem
+    client_ca: tls/clients.pem
+services:
+  a: {command: x}
+`), "/srv")
+	require.NoError(t, err)
+	assert.Equal(t, "/run/superviz.sock", config.Control.Socket)
+	assert.Equal(t, FileMode(0o660), config.Control.SocketMode)
+	assert.Equal(t, "superviz", config.Control.SocketGroup)
+	assert.Equal(t, &ControlTCPConfig{
+		Address:  "0.0.0.0:7443",
+		Cert:     filepath.Join("/srv", "tls/server.pem"),
+		Key:      "/etc/superviz/server-key.pem",
+		ClientCA: filepath.Join("/srv", "tls/clients.pem"),
+	}, config.Control.TCP)
+}
+
+func TestParseSupervizConfig_InvalidControl(t *testing.T) {
+	tests := map[string]struct {
+		control string
+		want    string
+	}{
+		"mode":        {"{socket_mode: 0999}", `invalid file mode "0999"`},
+		"mode bits":   {"{socket_mode: 01777}", `invalid file mode "01777"`},
+		"mode list":   {"{socket_mode: [1]}", "file mode must be an octal number"},
+		"tcp address": {"{tcp: {address: nowhere, cert: c, key: k, client_ca: ca}}", `control: invalid tcp.address "nowhere"`},
+		"tcp tls":     {"{tcp: {address: ':7443', cert: c}}", "control: tcp requires cert, key and client_ca"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSupervizConfig([]byte("control: "+tt.control+"\nservices:\n  a: {command: x}\n"), "/srv")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestFileMode(t *testing.T) {
+	var modes struct {
+		Plain  FileMode `yaml:"plain"`
+		Quoted FileMode `yaml:"quoted"`
+		Prefix FileMode `yaml:"prefix"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte("plain: 0640\nquoted: \"660\"\nprefix: 0o600\n"), &modes))
+	assert.Equal(t, FileMode(0o640), modes.Plain)
+	assert.Equal(t, FileMode(0o660), modes.Quoted)
+	assert.Equal(t, FileMode(0o600), modes.Prefix)
+	assert.Equal(t, "0640", modes.Plain.String())
+}
@@ -0,0 +1,379 @@
+// internal/providers/env.go - Typed environment variables of the services
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvType is the type a variable value is checked against.
+type EnvType string
+
+// Supported variable types.
+const (
+	// EnvString accepts any value, the default
+	EnvString EnvType = "string"
+	// EnvInt accepts a base 10 integer
+	EnvInt EnvType = "int"
+	// EnvBool accepts the values understood by strconv.ParseBool
+	EnvBool EnvType = "bool"
+	// EnvURL accepts an absolute URL with a scheme and a host
+	EnvURL EnvType = "url"
+	// EnvDuration accepts the values understood by time.ParseDuration
+	EnvDuration EnvType = "duration"
+	// EnvEnum accepts one of the declared values
+	EnvEnum EnvType = "enum"
+	// EnvRegex accepts the values fully matching the declared pattern
+	EnvRegex EnvType = "regex"
+)
+
+// envTypes lists the type names accepted by type
+var envTypes = []string{string(EnvString), string(EnvInt), string(EnvBool), string(EnvURL), string(EnvDuration), string(EnvEnum), string(EnvRegex)}
+
+// envFilePrefix introduces a file reference in an interpolated value
+const envFilePrefix = "FILE:"
+
+// envNamePattern matches the variable names a value can reference
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EnvCondition injects a variable only when every listed test holds; the
+// tests see the inherited environment and the other injected variables.
+type EnvCondition struct {
+	// Set requires this variable to be set to a non-empty value
+	Set string `yaml:"set,omitempty"`
+	// Unset requires this variable to be unset or empty
+	Unset string `yaml:"unset,omitempty"`
+	// Equals requires each variable to hold the given value
+	Equals map[string]string `yaml:"equals,omitempty"`
+}
+
+// Met evaluates the condition.
+//
+// Parameters:
+//   - lookup: func(name string) string returning the value of a variable, empty when unset
+//
+// Returns:
+//   - met: true when the variable must be injected
+func (c *EnvCondition) Met(lookup func(name string) string) bool {
+	if c == nil {
+		return true
+	}
+	if c.Set != "" && lookup(c.Set) == "" {
+		return false
+	}
+	if c.Unset != "" && lookup(c.Unset) != "" {
+		return false
+	}
+	for name, want := range c.Equals {
+		if lookup(name) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// names returns the variables the condition reads
+func (c *EnvCondition) names() []string {
+	if c == nil {
+		return nil
+	}
+	var names []string
+	for _, name := range []string{c.Set, c.Unset} {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	for name := range c.Equals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EnvVar declares one variable of a service.
+//
+// It is written either as a plain value or as a mapping declaring its type
+// and where its value comes from. Value and Default may reference other
+// variables as ${NAME} and files as ${FILE:/run/secrets/token}; $${ writes a
+// literal ${.
+//
+//	env:
+//	  LOG_LEVEL: info
+//	  PORT:
+//	    type: int
+//	    default: "8080"
+//	  DATABASE_URL:
+//	    type: url
+//	    required: true
+//	  DB_PASSWORD:
+//	    value: ${FILE:/run/secrets/db_password}
+//	    secret: true
+//	  TLS_KEY_FILE:
+//	    value: /etc/tls/key.pem
+//	    when:
+//	      set: TLS_CERT_FILE
+type EnvVar struct {
+	// Value is the value of the variable, interpolated when the service starts
+	Value string `yaml:"value,omitempty"`
+	// Type checks the value, default string
+	Type EnvType `yaml:"type,omitempty"`
+	// Required fails the start when the variable ends up empty
+	Required bool `yaml:"required,omitempty"`
+	// Default is used when neither the value nor the inherited environment set the variable
+	Default string `yaml:"default,omitempty"`
+	// Secret masks the value in the service logs and in the error reports
+	Secret bool `yaml:"secret,omitempty"`
+	// Values lists the values accepted by the enum type
+	Values []string `yaml:"values,omitempty"`
+	// Pattern is the regular expression the regex type must fully match
+	Pattern string `yaml:"pattern,omitempty"`
+	// When injects the variable only if the condition holds, always when absent
+	When *EnvCondition `yaml:"when,omitempty"`
+}
+
+// UnmarshalYAML accepts either a plain value or a mapping.
+//
+// Parameters:
+//   - node: *yaml.Node variable entry
+//
+// Returns:
+//   - err: error if the entry is neither a scalar nor a valid mapping
+func (v *EnvVar) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		v.Value = node.Value
+		return nil
+	}
+
+	type plain EnvVar
+	return node.Decode((*plain)(v))
+}
+
+// Check tests a non-empty value against the type of the variable.
+//
+// The error does not quote the value, so that secrets are not leaked.
+//
+// Parameters:
+//   - value: string value to check
+//
+// Returns:
+//   - err: error explaining why the value does not fit the type
+func (v *EnvVar) Check(value string) error {
+	switch v.Type {
+	case EnvInt:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return errors.New("must be an int")
+		}
+	case EnvBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return errors.New("must be a bool")
+		}
+	case EnvURL:
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			return errors.New("must be an absolute URL")
+		}
+	case EnvDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return errors.New("must be a duration")
+		}
+	case EnvEnum:
+		if !containsString(v.Values, value) {
+			return fmt.Errorf("must be one of %s", strings.Join(v.Values, ", "))
+		}
+	case EnvRegex:
+		pattern, err := regexp.Compile(`^(?:` + v.Pattern + `)$`)
+		if err != nil || !pattern.MatchString(value) {
+			return fmt.Errorf("must match %s", v.Pattern)
+		}
+	}
+	return nil
+}
+
+// validate checks the declaration and its literal value and default
+func (v *EnvVar) validate() error {
+	if v.Type != "" && !containsString(envTypes, string(v.Type)) {
+		return fmt.Errorf("invalid type %q: must be one of %s", v.Type, strings.Join(envTypes, ", "))
+	}
+	switch {
+	case v.Type == EnvEnum && len(v.Values) == 0:
+		return errors.New("values are required by the enum type")
+	case v.Type != EnvEnum && len(v.Values) > 0:
+		return errors.New("values are only allowed with the enum type")
+	case v.Type == EnvRegex && v.Pattern == "":
+		return errors.New("pattern is required by the regex type")
+	case v.Type != EnvRegex && v.Pattern != "":
+		return errors.New("pattern is only allowed with the regex type")
+	}
+	if v.Type == EnvRegex {
+		if _, err := regexp.Compile(v.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+
+	for _, field := range []struct{ name, value string }{{"value", v.Value}, {"default", v.Default}} {
+		refs, err := EnvReferences(field.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.name, err)
+		}
+		if field.value == "" || len(refs) > 0 || strings.Contains(field.value, "$${") {
+			continue
+		}
+		if err := v.Check(field.value); err != nil {
+			if v.Secret {
+				return fmt.Errorf("%s %w", field.name, err)
+			}
+			return fmt.Errorf("%s %q %w", field.name, field.value, err)
+		}
+	}
+
+	if v.When != nil && v.When.Set == "" && v.When.Unset == "" && len(v.When.Equals) == 0 {
+		return errors.New("when needs set, unset or equals")
+	}
+	return nil
+}
+
+// dependencies returns the variables the value, the default and the condition read
+func (v *EnvVar) dependencies() []string {
+	names := v.When.names()
+	for _, value := range []string{v.Value, v.Default} {
+		refs, _ := EnvReferences(value) //nolint:errcheck // validate reports the malformed references
+		for _, ref := range refs {
+			if !strings.HasPrefix(ref, envFilePrefix) {
+				names = append(names, ref)
+			}
+		}
+	}
+	return names
+}
+
+// EnvVars maps the variable names of a service to their declaration.
+type EnvVars map[string]*EnvVar
+
+// validate checks the names, the declarations and the references between them
+func (e EnvVars) validate() error {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == "" || strings.ContainsAny(name, "=\x00") {
+			return fmt.Errorf("invalid environment variable name %q", name)
+		}
+		if v := e[name]; v != nil {
+			if err := v.validate(); err != nil {
+				return fmt.Errorf("env %s: %w", name, err)
+			}
+		}
+	}
+
+	// Depth-first search over the references to declared variables
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	marks := make(map[string]int, len(e))
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch marks[name] {
+		case visiting:
+			return fmt.Errorf("env %s: reference cycle %s", name, strings.Join(append(path, name), " -> "))
+		case visited:
+			return nil
+		}
+		marks[name] = visiting
+		if v := e[name]; v != nil {
+			for _, dep := range v.dependencies() {
+				if _, declared := e[dep]; declared {
+					if err := visit(dep, append(path, name)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		marks[name] = visited
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Interpolate replaces the ${NAME} and ${FILE:path} references of value.
+//
+// Parameters:
+//   - value: string text holding the references, $${ writes a literal ${
+//   - lookup: func(ref string) (string, error) returning the replacement of a reference, e.g. "PORT" or "FILE:/run/secrets/x"
+//
+// Returns:
+//   - result: string value with every reference replaced
+//   - err: error if a reference is malformed or lookup fails
+func Interpolate(value string, lookup func(ref string) (string, error)) (string, error) {
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(value, "${")
+		if i < 0 {
+			b.WriteString(value)
+			return b.String(), nil
+		}
+		if i > 0 && value[i-1] == '$' {
+			b.WriteString(value[:i-1])
+			b.WriteString("${")
+			value = value[i+2:]
+			continue
+		}
+		b.WriteString(value[:i])
+
+		end := strings.IndexByte(value[i+2:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated reference in %q", value[i:])
+		}
+		ref := value[i+2 : i+2+end]
+		if path, ok := strings.CutPrefix(ref, envFilePrefix); ok {
+			if path == "" {
+				return "", errors.New("empty file reference ${FILE:}")
+			}
+		} else if !envNamePattern.MatchString(ref) {
+			return "", fmt.Errorf("invalid reference ${%s}", ref)
+		}
+
+		replacement, err := lookup(ref)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(replacement)
+		value = value[i+2+end+1:]
+	}
+}
+
+// EnvReferences lists the references of a value, in order.
+//
+// Parameters:
+//   - value: string text holding the references
+//
+// Returns:
+//   - refs: []string variable names and FILE:path references
+//   - err: error if a reference is malformed
+func EnvReferences(value string) ([]string, error) {
+	var refs []string
+	_, err := Interpolate(value, func(ref string) (string, error) {
+		refs = append(refs, ref)
+		return "", nil
+	})
+	return refs, err
+}
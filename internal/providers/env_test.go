@@ -0,0 +1,157 @@
+package providers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSupervizConfig_Env(t *testing.T) {
+	config, err := ParseSupervizConfig([]byte(`
+version: 1
+services:
+  web:
+    command: ./web
+    env:
+      LOG_LEVEL: info
+      EMPTY:
+      PORT:
+        type: int
+        default: "8080"
+      MODE:
+        type: enum
+        values: [dev, prod]
+        required: true
+      DB_PASSWORD:
+        value: ${FILE:/run/secrets/db}
+        secret: true
+      TLS_KEY:
+        value: /etc/tls/key.pem
+        when:
+          set: TLS_CERT
+`), "/srv")
+	require.NoError(t, err)
+
+	env := config.Services["web"].Env
+	assert.Equal(t, &EnvVar{Value: "info"}, env["LOG_LEVEL"])
+	assert.Equal(t, &EnvVar{}, env["EMPTY"], "a null entry is an empty value")
+	assert.Equal(t, &EnvVar{Type: EnvInt, Default: "8080"}, env["PORT"])
+	assert.Equal(t, &EnvVar{Type: EnvEnum, Values: []string{"dev", "prod"}, Required: true}, env["MODE"])
+	assert.Equal(t, &EnvVar{Value: "${FILE:/run/secrets/db}", Secret: true}, env["DB_PASSWORD"])
+	assert.Equal(t, &EnvVar{Value: "/etc/tls/key.pem", When: &EnvCondition{Set: "TLS_CERT"}}, env["TLS_KEY"])
+}
+
+func TestEnvVar_Check(t *testing.T) {
+	tests := []struct {
+		name  string
+		v     EnvVar
+		value string
+		err   string
+	}{
+		{"string", EnvVar{}, "anything", ""},
+		{"int", EnvVar{Type: EnvInt}, "-42", ""},
+		{"not an int", EnvVar{Type: EnvInt}, "4.2", "must be an int"},
+		{"bool", EnvVar{Type: EnvBool}, "true", ""},
+		{"not a bool", EnvVar{Type: EnvBool}, "yes", "must be a bool"},
+		{"url", EnvVar{Type: EnvURL}, "postgres://db:5432/app", ""},
+		{"relative url", EnvVar{Type: EnvURL}, "/app", "must be an absolute URL"},
+		{"duration", EnvVar{Type: EnvDuration}, "1m30s", ""},
+		{"not a duration", EnvVar{Type: EnvDuration}, "90", "must be a duration"},
+		{"enum", EnvVar{Type: EnvEnum, Values: []string{"dev", "prod"}}, "prod", ""},
+		{"not in enum", EnvVar{Type: EnvEnum, Values: []string{"dev", "prod"}}, "test", "must be one of dev, prod"},
+		{"regex", EnvVar{Type: EnvRegex, Pattern: "[a-z]+-[0-9]+"}, "web-1", ""},
+		{"partial match", EnvVar{Type: EnvRegex, Pattern: "[a-z]+-[0-9]+"}, "web-1a", "must match [a-z]+-[0-9]+"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.v.Check(tt.value)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestEnvVars_Validate(t *testing.T) {
+	valid := EnvVars{
+		"PLAIN":  {Value: "x"},
+		"NIL":    nil,
+		"PORT":   {Type: EnvInt, Default: "8080"},
+		"URL":    {Type: EnvURL, Value: "http://${HOST}:${PORT}"},
+		"HOST":   {Default: "localhost", When: &EnvCondition{Unset: "SOCKET"}},
+		"SECRET": {Value: "${FILE:secret}", Secret: true},
+		"LEVEL":  {Type: EnvEnum, Values: []string{"info", "debug"}, When: &EnvCondition{Equals: map[string]string{"PLAIN": "x"}}},
+		"ESCAPE": {Type: EnvInt, Value: "$${NOT_A_REF}"},
+	}
+	require.NoError(t, valid.validate())
+
+	tests := []struct {
+		name string
+		vars EnvVars
+		err  string
+	}{
+		{"bad name", EnvVars{"A=B": {}}, `invalid environment variable name "A=B"`},
+		{"bad type", EnvVars{"A": {Type: "float"}}, `env A: invalid type "float": must be one of string, int, bool, url, duration, enum, regex`},
+		{"enum without values", EnvVars{"A": {Type: EnvEnum}}, "env A: values are required by the enum type"},
+		{"values without enum", EnvVars{"A": {Values: []string{"x"}}}, "env A: values are only allowed with the enum type"},
+		{"regex without pattern", EnvVars{"A": {Type: EnvRegex}}, "env A: pattern is required by the regex type"},
+		{"pattern without regex", EnvVars{"A": {Pattern: "x"}}, "env A: pattern is only allowed with the regex type"},
+		{"bad pattern", EnvVars{"A": {Type: EnvRegex, Pattern: "("}}, "env A: invalid pattern: error parsing regexp: missing closing ): `(`"},
+		{"bad value", EnvVars{"A": {Type: EnvInt, Value: "x"}}, `env A: value "x" must be an int`},
+		{"bad default", EnvVars{"A": {Type: EnvBool, Default: "maybe"}}, `env A: default "maybe" must be a bool`},
+		{"secret default", EnvVars{"A": {Type: EnvInt, Default: "hunter2", Secret: true}}, "env A: default must be an int"},
+		{"unterminated reference", EnvVars{"A": {Value: "${B"}}, `env A: value: unterminated reference in "${B"`},
+		{"bad reference", EnvVars{"A": {Default: "${B C}"}}, "env A: default: invalid reference ${B C}"},
+		{"empty file", EnvVars{"A": {Value: "${FILE:}"}}, "env A: value: empty file reference ${FILE:}"},
+		{"empty when", EnvVars{"A": {When: &EnvCondition{}}}, "env A: when needs set, unset or equals"},
+		{"cycle", EnvVars{"A": {Value: "${B}"}, "B": {When: &EnvCondition{Set: "A"}}}, "env A: reference cycle A -> B -> A"},
+		{"self reference", EnvVars{"A": {Value: "x${A}"}}, "env A: reference cycle A -> A"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualError(t, tt.vars.validate(), tt.err)
+		})
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	values := map[string]string{"HOST": "db", "PORT": "5432", "FILE:/run/secrets/pw": "s3cret"}
+	lookup := func(ref string) (string, error) {
+		if ref == "FAIL" {
+			return "", errors.New("lookup failed")
+		}
+		return values[ref], nil
+	}
+
+	got, err := Interpolate("postgres://app:${FILE:/run/secrets/pw}@${HOST}:${PORT}/app", lookup)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://app:s3cret@db:5432/app", got)
+
+	got, err = Interpolate("price: $5, literal $${HOST}, ${MISSING}end", lookup)
+	require.NoError(t, err)
+	assert.Equal(t, "price: $5, literal ${HOST}, end", got)
+
+	_, err = Interpolate("${FAIL}", lookup)
+	assert.EqualError(t, err, "lookup failed")
+
+	refs, err := EnvReferences("${A}-${FILE:b}-$${C}")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "FILE:b"}, refs)
+}
+
+func TestEnvCondition_Met(t *testing.T) {
+	env := map[string]string{"TLS_CERT": "/etc/cert.pem", "MODE": "prod"}
+	lookup := func(name string) string { return env[name] }
+
+	assert.True(t, (*EnvCondition)(nil).Met(lookup))
+	assert.True(t, (&EnvCondition{Set: "TLS_CERT"}).Met(lookup))
+	assert.False(t, (&EnvCondition{Set: "TLS_KEY"}).Met(lookup))
+	assert.True(t, (&EnvCondition{Unset: "DEBUG"}).Met(lookup))
+	assert.False(t, (&EnvCondition{Unset: "MODE"}).Met(lookup))
+	assert.True(t, (&EnvCondition{Set: "TLS_CERT", Equals: map[string]string{"MODE": "prod"}}).Met(lookup))
+	assert.False(t, (&EnvCondition{Set: "TLS_CERT", Equals: map[string]string{"MODE": "dev"}}).Met(lookup))
+}
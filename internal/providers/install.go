@@ -2,6 +2,7 @@
 package providers
 
 import (
+	"strings"
 	"sync"
 	"time"
 )
@@ -27,6 +28,14 @@ type InstallConfig struct {
 	Target string // parsed from user@host
 	// SkipHostKeyCheck bypasses host key verification (development only)
 	SkipHostKeyCheck bool // Skip host key verification (development only)
+	// SkipPreflight bypasses the remote preflight checks before installation
+	SkipPreflight bool
+	// JumpHost is an optional [user@]host[:port] bastion the SSH connection is tunneled through
+	JumpHost string
+	// RepoMirror replaces the superviz.io repository root when set
+	RepoMirror string
+	// Version pins the agent package version, empty for the repository's latest
+	Version string
 }
 
 // InstallInfo contains metadata about superviz.io installation operations.
@@ -285,3 +294,45 @@ func DefaultInstallProvider() InstallProvider {
 func NewInstallProvider() InstallProvider {
 	return &installProvider{}
 }
+
+// mirrorInstallProvider overrides the repository URL of another provider.
+type mirrorInstallProvider struct {
+	InstallProvider
+	// url is the mirror repository root
+	url string
+}
+
+// GetInstallInfo returns the wrapped installation information with the mirror URL.
+//
+// Returns:
+//   - info: InstallInfo with RepositoryURL set to the mirror
+func (p *mirrorInstallProvider) GetInstallInfo() InstallInfo {
+	info := p.InstallProvider.GetInstallInfo()
+	info.RepositoryURL = p.url
+	return info
+}
+
+// GetRepositoryURL returns the mirror repository root.
+//
+// Returns:
+//   - url: string mirror URL
+func (p *mirrorInstallProvider) GetRepositoryURL() string {
+	return p.url
+}
+
+// NewMirrorInstallProvider wraps a provider so that it reports a repository mirror.
+//
+// Example:
+//
+//	provider := NewMirrorInstallProvider(NewInstallProvider(), "https://mirror.example.com/superviz")
+//	url := provider.GetRepositoryURL() // "https://mirror.example.com/superviz"
+//
+// Parameters:
+//   - base: InstallProvider provider supplying package and key information
+//   - url: string mirror repository root, a trailing slash is removed
+//
+// Returns:
+//   - provider: InstallProvider reporting the mirror URL
+func NewMirrorInstallProvider(base InstallProvider, url string) InstallProvider {
+	return &mirrorInstallProvider{InstallProvider: base, url: strings.TrimRight(url, "/")}
+}
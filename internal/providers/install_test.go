@@ -184,3 +184,14 @@ func TestInitInstallInfo_IsCalledOnce(t *testing.T) {
 	keyID2 := provider.GetGPGKeyID()
 	assert.Equal(t, keyID1, keyID2)
 }
+
+func TestNewMirrorInstallProvider(t *testing.T) {
+	base := NewInstallProvider()
+	provider := NewMirrorInstallProvider(base, "https://mirror.example.com/superviz/")
+
+	assert.Equal(t, "https://mirror.example.com/superviz", provider.GetRepositoryURL())
+	assert.Equal(t, "https://mirror.example.com/superviz", provider.GetInstallInfo().RepositoryURL)
+	assert.Equal(t, base.GetPackageName(), provider.GetPackageName())
+	assert.Equal(t, base.GetGPGKeyID(), provider.GetGPGKeyID())
+	assert.Equal(t, base.GetInstallInfo().Version, provider.GetInstallInfo().Version)
+}
@@ -0,0 +1,337 @@
+// internal/providers/integrity.go - File integrity settings of superviz.yaml and integrity manifests
+package providers
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Integrity defaults.
+const (
+	// DefaultIntegrityInterval is the period of the integrity checks after startup
+	DefaultIntegrityInterval = 5 * time.Minute
+	// IntegrityManifestVersion is the supported manifest schema version
+	IntegrityManifestVersion = 1
+)
+
+// IntegrityAction is what happens when a file fails its check.
+type IntegrityAction string
+
+// Supported integrity actions; every failure is also reported.
+const (
+	// IntegrityRefuse refuses to start the services depending on the file, the default
+	IntegrityRefuse IntegrityAction = "refuse"
+	// IntegrityAlert only reports the failure
+	IntegrityAlert IntegrityAction = "alert"
+	// IntegrityRestore copies the file back from its source, then refuses when it still fails
+	IntegrityRestore IntegrityAction = "restore"
+)
+
+// Digest algorithms.
+const (
+	// DigestSHA256 is SHA-256, 64 hex digits
+	DigestSHA256 = "sha256"
+	// DigestSHA512 is SHA-512, 128 hex digits
+	DigestSHA512 = "sha512"
+)
+
+// IntegrityFile declares the expected content of a file, or of every file
+// matching a glob.
+//
+// A file without a digest must be listed in the manifest; with a glob, this
+// detects files added next to the expected ones.
+type IntegrityFile struct {
+	// Path is the file or glob, relative to the configuration file or manifest
+	Path string `yaml:"path"`
+	// SHA256 is the expected hex SHA-256 digest
+	SHA256 string `yaml:"sha256,omitempty"`
+	// SHA512 is the expected hex SHA-512 digest
+	SHA512 string `yaml:"sha512,omitempty"`
+	// Source is the known-good copy restored over the file with on_failure: restore
+	Source string `yaml:"source,omitempty"`
+	// Services are refused while the file fails, every service when empty
+	Services []string `yaml:"services,omitempty"`
+}
+
+// Digest returns the algorithm and the expected digest.
+//
+// Returns:
+//   - algorithm: string DigestSHA256 or DigestSHA512, empty without digest
+//   - digest: string lower-case hex digest
+func (f *IntegrityFile) Digest() (string, string) {
+	switch {
+	case f.SHA512 != "":
+		return DigestSHA512, strings.ToLower(f.SHA512)
+	case f.SHA256 != "":
+		return DigestSHA256, strings.ToLower(f.SHA256)
+	default:
+		return "", ""
+	}
+}
+
+// IsGlob reports whether the path is a pattern
+func (f *IntegrityFile) IsGlob() bool {
+	return strings.ContainsAny(f.Path, "*?[")
+}
+
+// validate checks the path, the digest and the source
+func (f *IntegrityFile) validate() error {
+	if f.Path == "" {
+		return errors.New("path is required")
+	}
+	if _, err := filepath.Match(f.Path, ""); err != nil {
+		return fmt.Errorf("invalid path %q: %w", f.Path, err)
+	}
+	if f.SHA256 != "" && f.SHA512 != "" {
+		return fmt.Errorf("%s: sha256 and sha512 are mutually exclusive", f.Path)
+	}
+	for _, digest := range []struct {
+		name, value string
+		size        int
+	}{{DigestSHA256, f.SHA256, 64}, {DigestSHA512, f.SHA512, 128}} {
+		if digest.value == "" {
+			continue
+		}
+		if _, err := hex.DecodeString(digest.value); err != nil || len(digest.value) != digest.size {
+			return fmt.Errorf("%s: %s must be %d hex digits", f.Path, digest.name, digest.size)
+		}
+	}
+	if f.Source != "" && f.IsGlob() {
+		return fmt.Errorf("%s: source cannot restore a glob", f.Path)
+	}
+	return nil
+}
+
+// IntegrityManifestSource is a manifest of digests, optionally signed.
+type IntegrityManifestSource struct {
+	// Path is the manifest file, relative to the configuration file
+	Path string `yaml:"path"`
+	// Signature is the file holding the base64 Ed25519 signature of the manifest
+	Signature string `yaml:"signature,omitempty"`
+	// PublicKey is the base64 Ed25519 key verifying the signature
+	PublicKey string `yaml:"public_key,omitempty"`
+	// Services are refused while a listed file fails, every service when empty
+	Services []string `yaml:"services,omitempty"`
+}
+
+// Key decodes the public key.
+//
+// Returns:
+//   - key: ed25519.PublicKey decoded key, nil when the manifest is not signed
+//   - err: error if the key is malformed
+func (m *IntegrityManifestSource) Key() (ed25519.PublicKey, error) {
+	if m.PublicKey == "" {
+		return nil, nil
+	}
+	return ParsePublicKey(m.PublicKey)
+}
+
+// ParsePublicKey decodes a base64 Ed25519 public key.
+//
+// Parameters:
+//   - encoded: string base64 standard encoding of the 32-byte key
+//
+// Returns:
+//   - key: ed25519.PublicKey decoded key
+//   - err: error if the key is malformed
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key: expected %d bytes, got %d", ed25519.PublicKeySize, len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// IntegrityConfig checks critical files at startup and periodically.
+//
+// Example:
+//
+//	integrity:
+//	  on_failure: restore
+//	  files:
+//	    - path: /usr/local/bin/vault
+//	      sha256: 5f1c...
+//	      source: /opt/golden/vault
+//	      services: [vault]
+//	    - path: /usr/local/bin/*
+//	  manifest:
+//	    path: manifest.yaml
+//	    signature: manifest.yaml.sig
+//	    public_key: 3n8YpS0...
+type IntegrityConfig struct {
+	// Files are checked against their digest, or against the manifest when they have none
+	Files []IntegrityFile `yaml:"files,omitempty"`
+	// Manifest lists more files and their digests
+	Manifest *IntegrityManifestSource `yaml:"manifest,omitempty"`
+	// Interval is the period of the checks after startup, default 5m
+	Interval time.Duration `yaml:"interval,omitempty"`
+	// OnFailure is refuse, alert or restore, default refuse
+	OnFailure IntegrityAction `yaml:"on_failure,omitempty"`
+}
+
+// applyDefaults fills the interval and the action and makes the paths absolute against dir
+func (i *IntegrityConfig) applyDefaults(dir string) {
+	if i.Interval == 0 {
+		i.Interval = DefaultIntegrityInterval
+	}
+	if i.OnFailure == "" {
+		i.OnFailure = IntegrityRefuse
+	}
+	for j := range i.Files {
+		i.Files[j].Path = resolvePath(dir, i.Files[j].Path)
+		i.Files[j].Source = resolvePath(dir, i.Files[j].Source)
+	}
+	if i.Manifest != nil {
+		i.Manifest.Path = resolvePath(dir, i.Manifest.Path)
+		i.Manifest.Signature = resolvePath(dir, i.Manifest.Signature)
+	}
+}
+
+// validate checks the files, the manifest, the action and the services refused
+func (i *IntegrityConfig) validate(c *SupervizConfig) error {
+	if len(i.Files) == 0 && i.Manifest == nil {
+		return errors.New("files or manifest is required")
+	}
+	switch i.OnFailure {
+	case "", IntegrityRefuse, IntegrityAlert, IntegrityRestore:
+	default:
+		return fmt.Errorf("invalid on_failure %q: must be %s, %s or %s", i.OnFailure, IntegrityRefuse, IntegrityAlert, IntegrityRestore)
+	}
+	if i.Interval < 0 {
+		return errors.New("interval cannot be negative")
+	}
+
+	checkServices := func(services []string) error {
+		for _, name := range services {
+			if c.Services[name] == nil {
+				return fmt.Errorf("undefined service %s", name)
+			}
+		}
+		return nil
+	}
+	for j := range i.Files {
+		file := &i.Files[j]
+		if err := file.validate(); err != nil {
+			return fmt.Errorf("files: %w", err)
+		}
+		if algorithm, _ := file.Digest(); algorithm == "" && i.Manifest == nil {
+			return fmt.Errorf("files: %s: sha256 or sha512 is required without manifest", file.Path)
+		}
+		if err := checkServices(file.Services); err != nil {
+			return fmt.Errorf("files: %s: %w", file.Path, err)
+		}
+	}
+
+	if m := i.Manifest; m != nil {
+		if m.Path == "" {
+			return errors.New("manifest: path is required")
+		}
+		if (m.Signature == "") != (m.PublicKey == "") {
+			return errors.New("manifest: signature and public_key go together")
+		}
+		if _, err := m.Key(); err != nil {
+			return fmt.Errorf("manifest: %w", err)
+		}
+		if err := checkServices(m.Services); err != nil {
+			return fmt.Errorf("manifest: %w", err)
+		}
+	}
+	return nil
+}
+
+// IntegrityManifest lists files and their digests.
+//
+// Example:
+//
+//	version: 1
+//	files:
+//	  - path: bin/vault
+//	    sha256: 5f1c...
+//	  - path: /etc/vault/vault.hcl
+//	    sha512: 9b71...
+type IntegrityManifest struct {
+	// Version is the schema version, 1
+	Version int `yaml:"version"`
+	// Files are the files and their digests, relative paths are resolved against the manifest directory
+	Files []IntegrityFile `yaml:"files"`
+}
+
+// LoadIntegrityManifest reads and validates a manifest file.
+//
+// Parameters:
+//   - path: string manifest location
+//
+// Returns:
+//   - manifest: *IntegrityManifest validated manifest with absolute paths
+//   - data: []byte raw content, the signature is computed over it
+//   - err: error if the file cannot be read or is invalid
+func LoadIntegrityManifest(path string) (*IntegrityManifest, []byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve manifest directory: %w", err)
+	}
+	manifest, err := ParseIntegrityManifest(data, dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return manifest, data, nil
+}
+
+// ParseIntegrityManifest parses and validates manifest content.
+//
+// Parameters:
+//   - data: []byte YAML manifest
+//   - dir: string directory relative paths are resolved against
+//
+// Returns:
+//   - manifest: *IntegrityManifest validated manifest with absolute paths
+//   - err: error if the YAML is invalid or an entry has no valid digest
+func ParseIntegrityManifest(data []byte, dir string) (*IntegrityManifest, error) {
+	var manifest IntegrityManifest
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if manifest.Version == 0 {
+		manifest.Version = IntegrityManifestVersion
+	}
+	if manifest.Version != IntegrityManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d, expected %d", manifest.Version, IntegrityManifestVersion)
+	}
+	if len(manifest.Files) == 0 {
+		return nil, errors.New("manifest lists no files")
+	}
+
+	for i := range manifest.Files {
+		file := &manifest.Files[i]
+		file.Path = resolvePath(dir, file.Path)
+		if err := file.validate(); err != nil {
+			return nil, err
+		}
+		switch algorithm, _ := file.Digest(); {
+		case algorithm == "":
+			return nil, fmt.Errorf("%s: sha256 or sha512 is required", file.Path)
+		case file.Source != "" || len(file.Services) > 0:
+			return nil, fmt.Errorf("%s: source and services belong to superviz.yaml", file.Path)
+		}
+	}
+	return &manifest, nil
+}
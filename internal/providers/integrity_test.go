@@ -0,0 +1,143 @@
+package providers
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSHA256 is a well-formed SHA-256 digest
+var testSHA256 = strings.Repeat("ab", 32)
+
+func TestParseSupervizConfig_Integrity(t *testing.T) {
+	config, err := ParseSupervizConfig([]byte("services:\n  a: {command: x}\n"), "/srv")
+	require.NoError(t, err)
+	assert.Nil(t, config.Integrity, "integrity checks are opt-in")
+
+	config, err = ParseSupervizConfig([]byte(`
+integrity:
+  files:
+    - path: bin/vault
+      sha256: `+strings.ToUpper(testSHA256)+`
+      source: golden/vault
+      services: [a]
+    - path: /usr/local/bin/*
+  manifest:
+    path: manifest.yaml
+services:
+  a: {command: x}
+`), "/srv")
+	require.NoError(t, err)
+	assert.Equal(t, &IntegrityConfig{
+		Files: []IntegrityFile{
+			{Path: "/srv/bin/vault", SHA256: strings.ToUpper(testSHA256), Source: "/srv/golden/vault", Services: []string{"a"}},
+			{Path: "/usr/local/bin/*"},
+		},
+		Manifest:  &IntegrityManifestSource{Path: "/srv/manifest.yaml"},
+		Interval:  DefaultIntegrityInterval,
+		OnFailure: IntegrityRefuse,
+	}, config.Integrity)
+
+	algorithm, digest := config.Integrity.Files[0].Digest()
+	assert.Equal(t, DigestSHA256, algorithm)
+	assert.Equal(t, testSHA256, digest, "digests compare in lower case")
+	assert.False(t, config.Integrity.Files[0].IsGlob())
+	assert.True(t, config.Integrity.Files[1].IsGlob())
+}
+
+func TestParseSupervizConfig_InvalidIntegrity(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(make([]byte, ed25519.PublicKeySize))
+	tests := map[string]struct {
+		integrity string
+		want      string
+	}{
+		"empty":          {"{}", "integrity: files or manifest is required"},
+		"action":         {"{on_failure: panic, manifest: {path: m.yaml}}", `integrity: invalid on_failure "panic"`},
+		"interval":       {"{interval: -1s, manifest: {path: m.yaml}}", "integrity: interval cannot be negative"},
+		"no path":        {"{files: [{sha256: " + testSHA256 + "}]}", "integrity: files: path is required"},
+		"bad glob":       {"{files: [{path: '/bin/[', sha256: " + testSHA256 + "}]}", `integrity: files: invalid path "/bin/["`},
+		"short digest":   {"{files: [{path: /bin/sh, sha256: abcd}]}", "integrity: files: /bin/sh: sha256 must be 64 hex digits"},
+		"not hex":        {"{files: [{path: /bin/sh, sha512: " + strings.Repeat("zz", 64) + "}]}", "sha512 must be 128 hex digits"},
+		"two digests":    {"{files: [{path: /bin/sh, sha256: " + testSHA256 + ", sha512: " + testSHA256 + "}]}", "sha256 and sha512 are mutually exclusive"},
+		"no digest":      {"{files: [{path: /bin/sh}]}", "integrity: files: /bin/sh: sha256 or sha512 is required without manifest"},
+		"glob source":    {"{files: [{path: '/bin/*', sha256: " + testSHA256 + ", source: /golden/sh}]}", "source cannot restore a glob"},
+		"service":        {"{files: [{path: /bin/sh, sha256: " + testSHA256 + ", services: [b]}]}", "integrity: files: /bin/sh: undefined service b"},
+		"manifest path":  {"{manifest: {signature: m.sig}}", "integrity: manifest: path is required"},
+		"signature only": {"{manifest: {path: m.yaml, signature: m.sig}}", "integrity: manifest: signature and public_key go together"},
+		"bad key":        {"{manifest: {path: m.yaml, signature: m.sig, public_key: AAAA}}", "integrity: manifest: invalid public key: expected 32 bytes, got 3"},
+		"manifest svc":   {"{manifest: {path: m.yaml, signature: m.sig, public_key: '" + key + "', services: [b]}}", "integrity: manifest: undefined service b"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSupervizConfig([]byte("integrity: "+tt.integrity+"\nservices:\n  a: {command: x}\n"), "/srv")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestLoadIntegrityManifest(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "manifest.yaml")
+	content := "version: 1\nfiles:\n  - path: bin/vault\n    sha256: " + testSHA256 + "\n  - path: /etc/*.conf\n    sha512: " + strings.Repeat("cd", 64) + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	manifest, data, err := LoadIntegrityManifest(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+	assert.Equal(t, IntegrityManifestVersion, manifest.Version)
+	assert.Equal(t, []IntegrityFile{
+		{Path: filepath.Join(dir, "bin/vault"), SHA256: testSHA256},
+		{Path: "/etc/*.conf", SHA512: strings.Repeat("cd", 64)},
+	}, manifest.Files)
+
+	_, _, err = LoadIntegrityManifest(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read manifest")
+}
+
+func TestParseIntegrityManifest_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		manifest string
+		want     string
+	}{
+		"yaml":      {"files: {", "failed to parse manifest"},
+		"unknown":   {"files: []\nsigned: true\n", "field signed not found"},
+		"version":   {"version: 2\nfiles: [{path: a, sha256: " + testSHA256 + "}]\n", "unsupported manifest version 2, expected 1"},
+		"empty":     {"version: 1\n", "manifest lists no files"},
+		"no digest": {"files: [{path: a}]\n", "/m/a: sha256 or sha512 is required"},
+		"services":  {"files: [{path: a, sha256: " + testSHA256 + ", services: [web]}]\n", "/m/a: source and services belong to superviz.yaml"},
+		"digest":    {"files: [{path: a, sha256: nope}]\n", "/m/a: sha256 must be 64 hex digits"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseIntegrityManifest([]byte(tt.manifest), "/m")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	public, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	key, err := ParsePublicKey(" " + base64.StdEncoding.EncodeToString(public) + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, public, key)
+
+	_, err = ParsePublicKey("not base64!")
+	assert.ErrorContains(t, err, "invalid public key")
+
+	source := &IntegrityManifestSource{Path: "m.yaml"}
+	key, err = source.Key()
+	require.NoError(t, err)
+	assert.Nil(t, key, "unsigned manifest")
+}
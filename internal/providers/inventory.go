@@ -0,0 +1,139 @@
+// internal/providers/inventory.go - Host inventory files
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// InventoryHost is one host entry of an inventory file.
+//
+// Connection fields left empty fall back to the command-line flags.
+type InventoryHost struct {
+	// Target is the user@host to connect to
+	Target string `yaml:"target"`
+	// Port overrides --ssh-port for this host
+	Port int `yaml:"ssh-port,omitempty"`
+	// KeyPath overrides --ssh-key for this host
+	KeyPath string `yaml:"ssh-key,omitempty"`
+	// JumpHost overrides --jump-host for this host
+	JumpHost string `yaml:"jump-host,omitempty"`
+}
+
+// UnmarshalYAML accepts either a plain user@host string or a mapping.
+//
+// Parameters:
+//   - node: *yaml.Node host entry
+//
+// Returns:
+//   - err: error if the entry is neither a string nor a valid mapping
+func (h *InventoryHost) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		h.Target = node.Value
+		return nil
+	}
+
+	type plain InventoryHost
+	return node.Decode((*plain)(h))
+}
+
+// Inventory lists the hosts a command runs against, in order.
+//
+// Example:
+//
+//	hosts:
+//	  - admin@web-1
+//	  - target: admin@web-2
+//	    ssh-port: 2222
+//	    jump-host: ops@bastion.example.com
+type Inventory struct {
+	// Hosts are the inventory entries in file order
+	Hosts []InventoryHost `yaml:"hosts"`
+}
+
+// LoadInventory reads and validates an inventory file.
+//
+// Parameters:
+//   - path: string inventory file location
+//
+// Returns:
+//   - inventory: *Inventory parsed hosts
+//   - err: error if the file cannot be read or is invalid
+func LoadInventory(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory: %w", err)
+	}
+
+	inventory, err := ParseInventory(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return inventory, nil
+}
+
+// ParseInventory parses and validates inventory content.
+//
+// Parameters:
+//   - data: []byte YAML inventory
+//
+// Returns:
+//   - inventory: *Inventory parsed hosts
+//   - err: error if the YAML is invalid, empty or lists a host twice
+func ParseInventory(data []byte) (*Inventory, error) {
+	var inventory Inventory
+	if err := yaml.Unmarshal(data, &inventory); err != nil {
+		return nil, fmt.Errorf("failed to parse inventory: %w", err)
+	}
+
+	if len(inventory.Hosts) == 0 {
+		return nil, errors.New("inventory lists no hosts")
+	}
+
+	seen := make(map[string]bool, len(inventory.Hosts))
+	for i := range inventory.Hosts {
+		host := &inventory.Hosts[i]
+		if host.Target == "" {
+			return nil, fmt.Errorf("host %d has no target", i+1)
+		}
+		if seen[host.Target] {
+			return nil, fmt.Errorf("host %s is listed twice", host.Target)
+		}
+		seen[host.Target] = true
+		host.KeyPath = expandHome(host.KeyPath)
+	}
+
+	return &inventory, nil
+}
+
+// Configs returns one configuration per host, derived from base.
+//
+// Target is set from the entry and per-host connection overrides replace the
+// base values; Host and User are left for the caller to parse from Target.
+//
+// Parameters:
+//   - base: *InstallConfig settings shared by all hosts
+//
+// Returns:
+//   - configs: []*InstallConfig independent copies in inventory order
+func (inv *Inventory) Configs(base *InstallConfig) []*InstallConfig {
+	configs := make([]*InstallConfig, 0, len(inv.Hosts))
+	for _, host := range inv.Hosts {
+		config := *base
+		config.Target = host.Target
+		if host.Port != 0 {
+			config.Port = host.Port
+		}
+		if host.KeyPath != "" {
+			config.KeyPath = host.KeyPath
+		}
+		if host.JumpHost != "" {
+			config.JumpHost = host.JumpHost
+		}
+		configs = append(configs, &config)
+	}
+	return configs
+}
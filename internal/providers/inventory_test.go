@@ -0,0 +1,88 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testInventory = `
+hosts:
+  - admin@web-1
+  - target: admin@web-2
+    ssh-port: 2222
+    ssh-key: /keys/web2
+    jump-host: ops@bastion
+`
+
+func TestParseInventory(t *testing.T) {
+	inventory, err := ParseInventory([]byte(testInventory))
+	require.NoError(t, err)
+
+	assert.Equal(t, []InventoryHost{
+		{Target: "admin@web-1"},
+		{Target: "admin@web-2", Port: 2222, KeyPath: "/keys/web2", JumpHost: "ops@bastion"},
+	}, inventory.Hosts)
+}
+
+func TestParseInventory_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		want string
+	}{
+		"malformed":   {data: "hosts: [", want: "failed to parse inventory"},
+		"empty":       {data: "hosts: []", want: "inventory lists no hosts"},
+		"no target":   {data: "hosts:\n  - ssh-port: 22", want: "host 1 has no target"},
+		"duplicate":   {data: "hosts:\n  - a@h\n  - target: a@h", want: "host a@h is listed twice"},
+		"wrong shape": {data: "hosts:\n  - [a@h]", want: "failed to parse inventory"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseInventory([]byte(tt.data))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestParseInventory_ExpandsHome(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	inventory, err := ParseInventory([]byte("hosts:\n  - target: a@h\n    ssh-key: ~/.ssh/id"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".ssh/id"), inventory.Hosts[0].KeyPath)
+}
+
+func TestLoadInventory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testInventory), 0o600))
+
+	inventory, err := LoadInventory(path)
+	require.NoError(t, err)
+	assert.Len(t, inventory.Hosts, 2)
+
+	_, err = LoadInventory(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read inventory")
+
+	require.NoError(t, os.WriteFile(path, []byte("hosts: []"), 0o600))
+	_, err = LoadInventory(path)
+	assert.ErrorContains(t, err, path)
+}
+
+func TestInventory_Configs(t *testing.T) {
+	inventory, err := ParseInventory([]byte(testInventory))
+	require.NoError(t, err)
+
+	base := &InstallConfig{Port: 22, KeyPath: "/keys/default", Timeout: time.Minute, JumpHost: "ops@default"}
+	configs := inventory.Configs(base)
+
+	require.Len(t, configs, 2)
+	assert.Equal(t, &InstallConfig{Target: "admin@web-1", Port: 22, KeyPath: "/keys/default", Timeout: time.Minute, JumpHost: "ops@default"}, configs[0])
+	assert.Equal(t, &InstallConfig{Target: "admin@web-2", Port: 2222, KeyPath: "/keys/web2", Timeout: time.Minute, JumpHost: "ops@bastion"}, configs[1])
+	assert.Empty(t, base.Target, "base must not be modified")
+}
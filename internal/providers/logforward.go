@@ -0,0 +1,165 @@
+// internal/providers/logforward.go - Log forwarding settings of superviz.yaml
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Log forwarding defaults.
+const (
+	// DefaultSyslogNetwork sends syslog messages over UDP
+	DefaultSyslogNetwork = "udp"
+	// DefaultSyslogSocket is the local syslog socket used by the unix network
+	DefaultSyslogSocket = "/dev/log"
+	// DefaultSyslogFacility is the facility of the forwarded messages
+	DefaultSyslogFacility = "daemon"
+	// DefaultOTLPTimeout bounds one OTLP export request
+	DefaultOTLPTimeout = 10 * time.Second
+)
+
+// syslogFacilities maps the RFC 5424 facility names to their code
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// SyslogConfig forwards the service lines to a syslog server as RFC 5424 messages.
+type SyslogConfig struct {
+	// Network is udp (default), tcp or unix
+	Network string `yaml:"network,omitempty"`
+	// Address is host:port for udp and tcp, or a socket path for unix, default /dev/log
+	Address string `yaml:"address,omitempty"`
+	// Facility is the facility name such as daemon (default), user or local0
+	Facility string `yaml:"facility,omitempty"`
+	// Hostname identifies the machine in the messages, default the host name
+	Hostname string `yaml:"hostname,omitempty"`
+}
+
+// FacilityCode returns the numeric facility.
+//
+// Returns:
+//   - code: int RFC 5424 facility code, daemon when the name is unknown
+func (s *SyslogConfig) FacilityCode() int {
+	if code, ok := syslogFacilities[s.Facility]; ok {
+		return code
+	}
+	return syslogFacilities[DefaultSyslogFacility]
+}
+
+// applyDefaults fills the network, the unix socket and the facility
+func (s *SyslogConfig) applyDefaults() {
+	if s.Network == "" {
+		s.Network = DefaultSyslogNetwork
+	}
+	if s.Network == "unix" && s.Address == "" {
+		s.Address = DefaultSyslogSocket
+	}
+	if s.Facility == "" {
+		s.Facility = DefaultSyslogFacility
+	}
+	s.Facility = strings.ToLower(s.Facility)
+}
+
+// validate checks the network, the address and the facility
+func (s *SyslogConfig) validate() error {
+	switch s.Network {
+	case "udp", "tcp":
+		if _, _, err := net.SplitHostPort(s.Address); err != nil {
+			return fmt.Errorf("invalid address %q: must be host:port", s.Address)
+		}
+	case "unix":
+	default:
+		return fmt.Errorf("unsupported network %q: must be udp, tcp or unix", s.Network)
+	}
+	if _, ok := syslogFacilities[s.Facility]; !ok {
+		names := make([]string, 0, len(syslogFacilities))
+		for name := range syslogFacilities {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown facility %q: must be one of %s", s.Facility, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// OTLPConfig is an OpenTelemetry collector reached over OTLP/HTTP with the JSON encoding.
+type OTLPConfig struct {
+	// Endpoint is the collector base URL such as http://collector:4318, or a signal URL when it has a path
+	Endpoint string `yaml:"endpoint"`
+	// Headers are added to every request, e.g. for authentication
+	Headers map[string]string `yaml:"headers,omitempty"`
+	// Timeout bounds each export request, default 10s
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// applyDefaults fills the timeout
+func (o *OTLPConfig) applyDefaults() {
+	if o.Timeout == 0 {
+		o.Timeout = DefaultOTLPTimeout
+	}
+}
+
+// validate checks the endpoint URL and the timeout
+func (o *OTLPConfig) validate() error {
+	endpoint, err := url.Parse(o.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return fmt.Errorf("invalid endpoint %q: must be an http or https URL", o.Endpoint)
+	}
+	if o.Timeout < 0 {
+		return errors.New("timeout cannot be negative")
+	}
+	return nil
+}
+
+// LogForwardConfig sends the lines of every service, unless its logs set
+// forward: false, to remote destinations.
+//
+// Example:
+//
+//	log_forward:
+//	  syslog:
+//	    network: tcp
+//	    address: logs.example.com:514
+//	    facility: local0
+//	  otlp:
+//	    endpoint: http://collector:4318
+//	    headers:
+//	      Authorization: Bearer token
+type LogForwardConfig struct {
+	// Syslog forwards to a syslog server, disabled when absent
+	Syslog *SyslogConfig `yaml:"syslog,omitempty"`
+	// OTLP exports to an OpenTelemetry collector, disabled when absent
+	OTLP *OTLPConfig `yaml:"otlp,omitempty"`
+}
+
+// applyDefaults fills the defaults of the configured destinations
+func (f *LogForwardConfig) applyDefaults() {
+	if f.Syslog != nil {
+		f.Syslog.applyDefaults()
+	}
+	if f.OTLP != nil {
+		f.OTLP.applyDefaults()
+	}
+}
+
+// validate checks the configured destinations
+func (f *LogForwardConfig) validate() error {
+	if f.Syslog != nil {
+		if err := f.Syslog.validate(); err != nil {
+			return fmt.Errorf("syslog: %w", err)
+		}
+	}
+	if f.OTLP != nil {
+		if err := f.OTLP.validate(); err != nil {
+			return fmt.Errorf("otlp: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSupervizConfig_LogForward(t *testing.T) {
+	config, err := ParseSupervizConfig([]byte("services:\n  a: {command: x}\n"), "/srv")
+	require.NoError(t, err)
+	assert.Nil(t, config.LogForward, "forwarding is opt-in")
+
+	config, err = ParseSupervizConfig([]byte(`
+log_forward:
+  syslog:
+    network: unix
+    facility: LOCAL3
+  otlp:
+    endpoint: http://collector:4318
+    headers: {Authorization: Bearer t}
+services:
+  a: {command: x}
+`), "/srv")
+	require.NoError(t, err)
+
+	syslog := config.LogForward.Syslog
+	assert.Equal(t, "unix", syslog.Network)
+	assert.Equal(t, DefaultSyslogSocket, syslog.Address)
+	assert.Equal(t, 19, syslog.FacilityCode())
+
+	otlp := config.LogForward.OTLP
+	assert.Equal(t, "http://collector:4318", otlp.Endpoint)
+	assert.Equal(t, "Bearer t", otlp.Headers["Authorization"])
+	assert.Equal(t, DefaultOTLPTimeout, otlp.Timeout)
+
+	config, err = ParseSupervizConfig([]byte("log_forward:\n  syslog: {address: 'logs:514'}\nservices:\n  a: {command: x}\n"), "/srv")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSyslogNetwork, config.LogForward.Syslog.Network)
+	assert.Equal(t, 3, config.LogForward.Syslog.FacilityCode(), "daemon by default")
+}
+
+func TestParseSupervizConfig_InvalidLogForward(t *testing.T) {
+	tests := map[string]struct {
+		forward string
+		want    string
+	}{
+		"network":  {"syslog: {network: sctp, address: 'x:1'}", `log_forward: syslog: unsupported network "sctp"`},
+		"address":  {"syslog: {network: tcp}", `syslog: invalid address ""`},
+		"facility": {"syslog: {address: 'x:514', facility: mars}", `unknown facility "mars": must be one of auth, authpriv`},
+		"endpoint": {"otlp: {endpoint: 'collector:4318'}", `log_forward: otlp: invalid endpoint "collector:4318"`},
+		"timeout":  {"otlp: {endpoint: 'http://c', timeout: -1s}", "otlp: timeout cannot be negative"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSupervizConfig([]byte("log_forward: {"+tt.forward+"}\nservices:\n  a: {command: x}\n"), "/srv")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestSyslogConfig_FacilityCode(t *testing.T) {
+	assert.Equal(t, 16, (&SyslogConfig{Facility: "local0"}).FacilityCode())
+	assert.Equal(t, 3, (&SyslogConfig{}).FacilityCode())
+}
@@ -0,0 +1,316 @@
+// internal/providers/logs.go - Service output capture settings of superviz.yaml
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Log file defaults.
+const (
+	// DefaultLogMaxSize rotates a log file once it would grow past 10 MiB
+	DefaultLogMaxSize ByteSize = 10 << 20
+	// DefaultLogMaxFiles is the number of rotated files kept per service
+	DefaultLogMaxFiles = 5
+	// DefaultMultilineStart starts a new entry on every line not beginning with a space or tab
+	DefaultMultilineStart = `^\S`
+	// DefaultMultilineMaxLines bounds the lines joined into one entry
+	DefaultMultilineMaxLines = 500
+	// DefaultMultilineTimeout ends an entry when no line follows for this long
+	DefaultMultilineTimeout = 500 * time.Millisecond
+)
+
+// LogFormat is how captured lines are written to the svz output and to log files.
+type LogFormat string
+
+// Supported log formats.
+const (
+	// LogFormatText writes the raw lines, prefixed with the service name on the svz output
+	LogFormatText LogFormat = "text"
+	// LogFormatJSON writes one JSON object per line with the time, service,
+	// pid, stream, level, message and the fields parsed from JSON or logfmt lines
+	LogFormatJSON LogFormat = "json"
+)
+
+// byteUnits maps the lowercase size suffixes to their multiplier
+var byteUnits = map[string]int64{
+	"": 1, "b": 1,
+	"k": 1 << 10, "ki": 1 << 10, "kib": 1 << 10, "kb": 1000,
+	"m": 1 << 20, "mi": 1 << 20, "mib": 1 << 20, "mb": 1000 * 1000,
+	"g": 1 << 30, "gi": 1 << 30, "gib": 1 << 30, "gb": 1000 * 1000 * 1000,
+}
+
+// ByteSize is a size in bytes written as a number with an optional unit,
+// e.g. 1048576, 512k, 10MiB or 100MB; K, M and G alone are binary units.
+type ByteSize int64
+
+// ParseByteSize parses a size such as 10MiB.
+//
+// Parameters:
+//   - value: string number with an optional B, K, KiB, KB, M, MiB, MB, G, GiB or GB unit
+//
+// Returns:
+//   - size: ByteSize number of bytes
+//   - err: error if the value is not a non-negative size
+func ParseByteSize(value string) (ByteSize, error) {
+	trimmed := strings.TrimSpace(value)
+	i := len(trimmed)
+	for i > 0 && (trimmed[i-1] < '0' || trimmed[i-1] > '9') {
+		i--
+	}
+	unit, ok := byteUnits[strings.ToLower(strings.TrimSpace(trimmed[i:]))]
+	n, err := strconv.ParseInt(trimmed[:i], 10, 64)
+	if !ok || err != nil || n < 0 || n > (1<<62)/unit {
+		return 0, fmt.Errorf("invalid size %q: must be a number with an optional unit such as 512k or 10MiB", value)
+	}
+	return ByteSize(n * unit), nil
+}
+
+// UnmarshalYAML parses a number of bytes with an optional unit.
+//
+// Parameters:
+//   - node: *yaml.Node scalar size
+//
+// Returns:
+//   - err: error if the value is not a size
+func (b *ByteSize) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return errors.New("size must be a number with an optional unit such as 10MiB")
+	}
+	size, err := ParseByteSize(node.Value)
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// String returns the size with the largest binary unit dividing it.
+//
+// Returns:
+//   - size: string such as "10MiB" or "1500B"
+func (b ByteSize) String() string {
+	for _, unit := range []struct {
+		suffix string
+		size   ByteSize
+	}{{"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}} {
+		if b != 0 && b%unit.size == 0 {
+			return strconv.FormatInt(int64(b/unit.size), 10) + unit.suffix
+		}
+	}
+	return strconv.FormatInt(int64(b), 10) + "B"
+}
+
+// LogConfig captures the output of services.
+//
+// The top-level logs section applies to every service; a service logs
+// section overrides the fields it sets.
+//
+// Example:
+//
+//	logs:
+//	  dir: /var/log/superviz
+//	  format: json
+//	  max_size: 50MiB
+//	  rotate_every: 24h
+//	  max_files: 7
+//	  max_age: 720h
+//	services:
+//	  web:
+//	    command: ./bin/web
+//	    logs:
+//	      mirror: false
+//	      multiline:
+//	        start: '^\d{4}-\d{2}-\d{2}'
+type LogConfig struct {
+	// Dir receives one <service>.log file per service, relative to the configuration directory; no files when empty
+	Dir string `yaml:"dir,omitempty"`
+	// MaxSize rotates the file before it grows past this size, default 10MiB
+	MaxSize ByteSize `yaml:"max_size,omitempty"`
+	// RotateEvery also rotates the file when a period of this length starts, e.g. every day at 00:00 UTC for 24h
+	RotateEvery time.Duration `yaml:"rotate_every,omitempty"`
+	// MaxFiles is the number of rotated files kept, default 5
+	MaxFiles int `yaml:"max_files,omitempty"`
+	// MaxAge removes rotated files older than this, kept regardless of age when 0
+	MaxAge time.Duration `yaml:"max_age,omitempty"`
+	// Compress gzips the rotated files, default true
+	Compress *bool `yaml:"compress,omitempty"`
+	// Mirror copies each line to the svz output prefixed with the service name, default true
+	Mirror *bool `yaml:"mirror,omitempty"`
+	// Format is text (default) or json, for the svz output and the log file
+	Format LogFormat `yaml:"format,omitempty"`
+	// Multiline joins the continuation lines of an entry, such as a stack trace, disabled when absent
+	Multiline *MultilineConfig `yaml:"multiline,omitempty"`
+	// Forward sends the lines to the log_forward destinations, default true
+	Forward *bool `yaml:"forward,omitempty"`
+}
+
+// MultilineConfig joins the lines of a multi-line entry such as a stack trace.
+//
+// A line matching start begins a new entry; any other line is appended to
+// the entry in progress of the same stream.
+type MultilineConfig struct {
+	// Start is the regular expression of the first line of an entry, default ^\S
+	Start string `yaml:"start,omitempty"`
+	// MaxLines ends an entry once it holds this many lines, default 500
+	MaxLines int `yaml:"max_lines,omitempty"`
+	// Timeout ends an entry when no line follows for this long, default 500ms
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// StartPattern compiles the start expression.
+//
+// Returns:
+//   - pattern: *regexp.Regexp matching the first line of an entry
+//   - err: error if start is not a valid regular expression
+func (m *MultilineConfig) StartPattern() (*regexp.Regexp, error) {
+	start := m.Start
+	if start == "" {
+		start = DefaultMultilineStart
+	}
+	pattern, err := regexp.Compile(start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid multiline.start: %w", err)
+	}
+	return pattern, nil
+}
+
+// applyDefaults fills the unset limits
+func (m *MultilineConfig) applyDefaults() {
+	if m.Start == "" {
+		m.Start = DefaultMultilineStart
+	}
+	if m.MaxLines == 0 {
+		m.MaxLines = DefaultMultilineMaxLines
+	}
+	if m.Timeout == 0 {
+		m.Timeout = DefaultMultilineTimeout
+	}
+}
+
+// validate checks the start expression and the limits
+func (m *MultilineConfig) validate() error {
+	if _, err := m.StartPattern(); err != nil {
+		return err
+	}
+	if m.MaxLines < 0 {
+		return errors.New("multiline.max_lines cannot be negative")
+	}
+	if m.Timeout < 0 {
+		return errors.New("multiline.timeout cannot be negative")
+	}
+	return nil
+}
+
+// CompressEnabled reports whether rotated files are compressed.
+//
+// Returns:
+//   - enabled: bool true unless compress is false
+func (l *LogConfig) CompressEnabled() bool {
+	return l.Compress == nil || *l.Compress
+}
+
+// MirrorEnabled reports whether lines are copied to the svz output.
+//
+// Returns:
+//   - enabled: bool true unless mirror is false
+func (l *LogConfig) MirrorEnabled() bool {
+	return l.Mirror == nil || *l.Mirror
+}
+
+// JSONEnabled reports whether lines are written as JSON objects.
+//
+// Returns:
+//   - enabled: bool true when format is json
+func (l *LogConfig) JSONEnabled() bool {
+	return l.Format == LogFormatJSON
+}
+
+// ForwardEnabled reports whether lines are sent to the log_forward destinations.
+//
+// Returns:
+//   - enabled: bool true unless forward is false
+func (l *LogConfig) ForwardEnabled() bool {
+	return l.Forward == nil || *l.Forward
+}
+
+// inherit fills the fields unset in l from base
+func (l *LogConfig) inherit(base *LogConfig) {
+	if l.Dir == "" {
+		l.Dir = base.Dir
+	}
+	if l.MaxSize == 0 {
+		l.MaxSize = base.MaxSize
+	}
+	if l.RotateEvery == 0 {
+		l.RotateEvery = base.RotateEvery
+	}
+	if l.MaxFiles == 0 {
+		l.MaxFiles = base.MaxFiles
+	}
+	if l.MaxAge == 0 {
+		l.MaxAge = base.MaxAge
+	}
+	if l.Compress == nil {
+		l.Compress = base.Compress
+	}
+	if l.Mirror == nil {
+		l.Mirror = base.Mirror
+	}
+	if l.Format == "" {
+		l.Format = base.Format
+	}
+	if l.Multiline == nil && base.Multiline != nil {
+		multiline := *base.Multiline
+		l.Multiline = &multiline
+	}
+	if l.Forward == nil {
+		l.Forward = base.Forward
+	}
+}
+
+// applyDefaults fills the format, size and count limits and resolves the directory against dir
+func (l *LogConfig) applyDefaults(dir string) {
+	if l.Format == "" {
+		l.Format = LogFormatText
+	}
+	if l.Multiline != nil {
+		l.Multiline.applyDefaults()
+	}
+	if l.MaxSize == 0 {
+		l.MaxSize = DefaultLogMaxSize
+	}
+	if l.MaxFiles == 0 {
+		l.MaxFiles = DefaultLogMaxFiles
+	}
+	l.Dir = resolvePath(dir, l.Dir)
+}
+
+// validate rejects unknown formats, negative limits and invalid multiline settings
+func (l *LogConfig) validate() error {
+	if l.Format != "" && l.Format != LogFormatText && l.Format != LogFormatJSON {
+		return fmt.Errorf("unsupported format %q: must be text or json", l.Format)
+	}
+	if l.Multiline != nil {
+		if err := l.Multiline.validate(); err != nil {
+			return err
+		}
+	}
+	switch {
+	case l.MaxSize < 0:
+		return errors.New("max_size cannot be negative")
+	case l.MaxFiles < 0:
+		return errors.New("max_files cannot be negative")
+	case l.RotateEvery < 0:
+		return errors.New("rotate_every cannot be negative")
+	case l.MaxAge < 0:
+		return errors.New("max_age cannot be negative")
+	}
+	return nil
+}
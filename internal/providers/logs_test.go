@@ -0,0 +1,145 @@
+package providers
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseByteSize(t *testing.T) {
+	valid := map[string]ByteSize{
+		"0":        0,
+		"1500":     1500,
+		"1500B":    1500,
+		"512k":     512 << 10,
+		"512KiB":   512 << 10,
+		"2KB":      2000,
+		"10M":      10 << 20,
+		"10 MiB":   10 << 20,
+		"100mb":    100 * 1000 * 1000,
+		"1G":       1 << 30,
+		"2GB":      2 * 1000 * 1000 * 1000,
+		" 3gib  ":  3 << 30,
+		"1048576b": 1 << 20,
+	}
+	for value, want := range valid {
+		size, err := ParseByteSize(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, size, value)
+	}
+
+	for _, value := range []string{"", "MiB", "-1", "1.5M", "10TB", "10 M B", "99999999999G"} {
+		_, err := ParseByteSize(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestByteSize_YAML(t *testing.T) {
+	var size ByteSize
+	require.NoError(t, yaml.Unmarshal([]byte("50MiB"), &size))
+	assert.Equal(t, ByteSize(50<<20), size)
+
+	require.Error(t, yaml.Unmarshal([]byte("[1]"), &size))
+	require.Error(t, yaml.Unmarshal([]byte("lots"), &size))
+}
+
+func TestByteSize_String(t *testing.T) {
+	assert.Equal(t, "10MiB", DefaultLogMaxSize.String())
+	assert.Equal(t, "2GiB", ByteSize(2<<30).String())
+	assert.Equal(t, "3KiB", ByteSize(3<<10).String())
+	assert.Equal(t, "1500B", ByteSize(1500).String())
+	assert.Equal(t, "0B", ByteSize(0).String())
+}
+
+func TestParseSupervizConfig_Logs(t *testing.T) {
+	config, err := ParseSupervizConfig([]byte("services:\n  a: {command: x}\n"), "/srv")
+	require.NoError(t, err)
+	logs := config.Services["a"].Logs
+	assert.Empty(t, logs.Dir, "log files are opt-in")
+	assert.Equal(t, DefaultLogMaxSize, logs.MaxSize)
+	assert.Equal(t, DefaultLogMaxFiles, logs.MaxFiles)
+	assert.True(t, logs.CompressEnabled())
+	assert.True(t, logs.MirrorEnabled())
+	assert.Equal(t, LogFormatText, logs.Format)
+	assert.Nil(t, logs.Multiline)
+	assert.True(t, logs.ForwardEnabled())
+
+	config, err = ParseSupervizConfig([]byte(`
+logs:
+  dir: logs
+  max_size: 50MiB
+  rotate_every: 24h
+  max_files: 7
+  max_age: 720h
+  compress: false
+  format: json
+  multiline: {max_lines: 50}
+services:
+  a: {command: x}
+  b:
+    command: y
+    logs:
+      dir: /var/log/b
+      max_files: 2
+      mirror: false
+      forward: false
+      multiline: {start: '^\[', timeout: 2s}
+`), "/srv")
+	require.NoError(t, err)
+
+	a := config.Services["a"].Logs
+	assert.Equal(t, filepath.Join("/srv", "logs"), a.Dir)
+	assert.Equal(t, ByteSize(50<<20), a.MaxSize)
+	assert.Equal(t, 24*time.Hour, a.RotateEvery)
+	assert.Equal(t, 7, a.MaxFiles)
+	assert.Equal(t, 720*time.Hour, a.MaxAge)
+	assert.False(t, a.CompressEnabled())
+	assert.True(t, a.MirrorEnabled())
+	assert.True(t, a.JSONEnabled())
+	assert.True(t, a.ForwardEnabled())
+	assert.Equal(t, &MultilineConfig{Start: DefaultMultilineStart, MaxLines: 50, Timeout: DefaultMultilineTimeout}, a.Multiline)
+
+	b := config.Services["b"].Logs
+	assert.Equal(t, "/var/log/b", b.Dir)
+	assert.Equal(t, 2, b.MaxFiles, "a service overrides the fields it sets")
+	assert.Equal(t, ByteSize(50<<20), b.MaxSize, "and inherits the others")
+	assert.False(t, b.CompressEnabled())
+	assert.False(t, b.MirrorEnabled())
+	assert.False(t, b.ForwardEnabled())
+	assert.True(t, b.JSONEnabled())
+	assert.Equal(t, &MultilineConfig{Start: `^\[`, MaxLines: DefaultMultilineMaxLines, Timeout: 2 * time.Second}, b.Multiline,
+		"a service multiline section replaces the top-level one")
+
+	pattern, err := b.Multiline.StartPattern()
+	require.NoError(t, err)
+	assert.True(t, pattern.MatchString("[INFO] ready"))
+	assert.False(t, pattern.MatchString("  at Main.java:3"))
+}
+
+func TestParseSupervizConfig_InvalidLogs(t *testing.T) {
+	tests := map[string]struct {
+		config string
+		want   string
+	}{
+		"size":         {"logs: {max_size: huge}\nservices:\n  a: {command: x}\n", `invalid size "huge"`},
+		"files":        {"logs: {max_files: -1}\nservices:\n  a: {command: x}\n", "logs: max_files cannot be negative"},
+		"rotate":       {"logs: {rotate_every: -1h}\nservices:\n  a: {command: x}\n", "logs: rotate_every cannot be negative"},
+		"age":          {"services:\n  a: {command: x, logs: {max_age: -1h}}\n", "service a: logs: max_age cannot be negative"},
+		"service size": {"services:\n  a: {command: x, logs: {max_size: -5}}\n", `invalid size "-5"`},
+		"format":       {"logs: {format: xml}\nservices:\n  a: {command: x}\n", `logs: unsupported format "xml"`},
+		"multiline":    {"services:\n  a: {command: x, logs: {multiline: {start: '('}}}\n", "service a: logs: invalid multiline.start"},
+		"max lines":    {"logs: {multiline: {max_lines: -1}}\nservices:\n  a: {command: x}\n", "multiline.max_lines cannot be negative"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSupervizConfig([]byte(tt.config), "/srv")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
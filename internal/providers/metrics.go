@@ -0,0 +1,77 @@
+// internal/providers/metrics.go - Metrics export settings of superviz.yaml
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Metrics defaults.
+const (
+	// DefaultMetricsPath is where the Prometheus metrics are served
+	DefaultMetricsPath = "/metrics"
+	// DefaultMetricsInterval is the period of the OTLP exports
+	DefaultMetricsInterval = 15 * time.Second
+)
+
+// MetricsConfig exports the service metrics: state, health, restarts, exit
+// codes, uptime, probe latency and the CPU, memory and descriptors of each
+// process.
+//
+// Example:
+//
+//	metrics:
+//	  listen: 127.0.0.1:9090
+//	  otlp:
+//	    endpoint: http://collector:4318
+//	  interval: 30s
+type MetricsConfig struct {
+	// Listen is the host:port serving the Prometheus text format, disabled when empty
+	Listen string `yaml:"listen,omitempty"`
+	// Path is the HTTP path of the Prometheus metrics, default /metrics
+	Path string `yaml:"path,omitempty"`
+	// OTLP pushes the metrics to an OpenTelemetry collector, disabled when absent
+	OTLP *OTLPConfig `yaml:"otlp,omitempty"`
+	// Interval is the period of the OTLP exports, default 15s
+	Interval time.Duration `yaml:"interval,omitempty"`
+}
+
+// applyDefaults fills the path, the interval and the OTLP timeout
+func (m *MetricsConfig) applyDefaults() {
+	if m.Path == "" {
+		m.Path = DefaultMetricsPath
+	}
+	if m.Interval == 0 {
+		m.Interval = DefaultMetricsInterval
+	}
+	if m.OTLP != nil {
+		m.OTLP.applyDefaults()
+	}
+}
+
+// validate requires a destination and checks the listen address, the path and the interval
+func (m *MetricsConfig) validate() error {
+	if m.Listen == "" && m.OTLP == nil {
+		return errors.New("listen or otlp is required")
+	}
+	if m.Listen != "" {
+		if _, _, err := net.SplitHostPort(m.Listen); err != nil {
+			return fmt.Errorf("invalid listen %q: must be host:port", m.Listen)
+		}
+	}
+	if !strings.HasPrefix(m.Path, "/") {
+		return fmt.Errorf("invalid path %q: must start with /", m.Path)
+	}
+	if m.Interval < 0 {
+		return errors.New("interval cannot be negative")
+	}
+	if m.OTLP != nil {
+		if err := m.OTLP.validate(); err != nil {
+			return fmt.Errorf("otlp: %w", err)
+		}
+	}
+	return nil
+}
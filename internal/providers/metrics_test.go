@@ -0,0 +1,56 @@
+package providers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSupervizConfig_Metrics(t *testing.T) {
+	config, err := ParseSupervizConfig([]byte("services:\n  a: {command: x}\n"), "/srv")
+	require.NoError(t, err)
+	assert.Nil(t, config.Metrics, "metrics are opt-in")
+
+	config, err = ParseSupervizConfig([]byte(`
+metrics:
+  listen: 127.0.0.1:9090
+  otlp: {endpoint: http://collector:4318}
+services:
+  a: {command: x}
+`), "/srv")
+	require.NoError(t, err)
+	assert.Equal(t, &MetricsConfig{
+		Listen:   "127.0.0.1:9090",
+		Path:     DefaultMetricsPath,
+		OTLP:     &OTLPConfig{Endpoint: "http://collector:4318", Timeout: DefaultOTLPTimeout},
+		Interval: DefaultMetricsInterval,
+	}, config.Metrics)
+
+	config, err = ParseSupervizConfig([]byte("metrics: {listen: ':9100', path: /prom, interval: 1m}\nservices:\n  a: {command: x}\n"), "/srv")
+	require.NoError(t, err)
+	assert.Equal(t, "/prom", config.Metrics.Path)
+	assert.Equal(t, time.Minute, config.Metrics.Interval)
+}
+
+func TestParseSupervizConfig_InvalidMetrics(t *testing.T) {
+	tests := map[string]struct {
+		metrics string
+		want    string
+	}{
+		"empty":    {"{}", "metrics: listen or otlp is required"},
+		"listen":   {"{listen: '9090'}", `metrics: invalid listen "9090"`},
+		"path":     {"{listen: ':9090', path: metrics}", `invalid path "metrics"`},
+		"interval": {"{listen: ':9090', interval: -1s}", "metrics: interval cannot be negative"},
+		"otlp":     {"{otlp: {endpoint: collector}}", `metrics: otlp: invalid endpoint "collector"`},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSupervizConfig([]byte("metrics: "+tt.metrics+"\nservices:\n  a: {command: x}\n"), "/srv")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
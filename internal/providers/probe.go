@@ -0,0 +1,212 @@
+// internal/providers/probe.go - Health probe definitions of superviz.yaml services
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+)
+
+// Probe defaults.
+const (
+	// DefaultProbeInterval is the pause between two checks
+	DefaultProbeInterval = 10 * time.Second
+	// DefaultProbeTimeout bounds one check
+	DefaultProbeTimeout = time.Second
+	// DefaultProbeSuccessThreshold is the number of consecutive successes that make a probe pass
+	DefaultProbeSuccessThreshold = 1
+	// DefaultProbeFailureThreshold is the number of consecutive failures that make a probe fail
+	DefaultProbeFailureThreshold = 3
+)
+
+// ExecProbe passes when a command exits with status 0.
+type ExecProbe struct {
+	// Command is the executable, run in the service working directory, environment and user
+	Command string `json:"command" yaml:"command"`
+	// Args are passed to the command
+	Args []string `json:"args,omitempty" yaml:"args,omitempty"`
+}
+
+// HTTPProbe passes when a GET request returns the expected response.
+type HTTPProbe struct {
+	// URL is the http or https address requested
+	URL string `json:"url" yaml:"url"`
+	// Status is the expected status code, default any 2xx or 3xx
+	Status int `json:"status,omitempty" yaml:"status,omitempty"`
+	// Body is a substring the response body must contain
+	Body string `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// TCPProbe passes when a TCP connection can be opened.
+type TCPProbe struct {
+	// Address is the host:port connected to
+	Address string `json:"address" yaml:"address"`
+}
+
+// GRPCProbe passes when the gRPC health protocol reports SERVING.
+type GRPCProbe struct {
+	// Address is the host:port of the plaintext gRPC server
+	Address string `json:"address" yaml:"address"`
+	// Service is the name checked, empty for the whole server
+	Service string `json:"service,omitempty" yaml:"service,omitempty"`
+}
+
+// Probe checks a service periodically with exactly one of exec, http, tcp or grpc.
+//
+// The first check runs after initial_delay, the next ones every interval.
+// The probe passes after success_threshold consecutive successes and fails
+// after failure_threshold consecutive failures.
+type Probe struct {
+	// Exec runs a command
+	Exec *ExecProbe `json:"exec,omitempty" yaml:"exec,omitempty"`
+	// HTTP sends a GET request
+	HTTP *HTTPProbe `json:"http,omitempty" yaml:"http,omitempty"`
+	// TCP opens a connection
+	TCP *TCPProbe `json:"tcp,omitempty" yaml:"tcp,omitempty"`
+	// GRPC calls grpc.health.v1.Health/Check
+	GRPC *GRPCProbe `json:"grpc,omitempty" yaml:"grpc,omitempty"`
+	// InitialDelay is the pause between the process start and the first check
+	InitialDelay time.Duration `json:"initial_delay,omitempty" yaml:"initial_delay,omitempty"`
+	// Interval is the pause between two checks, default 10s
+	Interval time.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+	// Timeout bounds one check, default 1s
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// SuccessThreshold is the number of consecutive successes to pass, default 1
+	SuccessThreshold int `json:"success_threshold,omitempty" yaml:"success_threshold,omitempty"`
+	// FailureThreshold is the number of consecutive failures to fail, default 3
+	FailureThreshold int `json:"failure_threshold,omitempty" yaml:"failure_threshold,omitempty"`
+}
+
+// Probes groups the health probes of a service.
+//
+// Example:
+//
+//	probes:
+//	  startup:
+//	    tcp: {address: "127.0.0.1:5432"}
+//	    interval: 1s
+//	    failure_threshold: 30
+//	  readiness:
+//	    http: {url: "http://127.0.0.1:8080/ready", status: 200}
+//	  liveness:
+//	    exec: {command: pg_isready}
+type Probes struct {
+	// Startup holds the other probes back until it passes, and restarts the service when it fails
+	Startup *Probe `json:"startup,omitempty" yaml:"startup,omitempty"`
+	// Liveness restarts the service when it fails
+	Liveness *Probe `json:"liveness,omitempty" yaml:"liveness,omitempty"`
+	// Readiness marks the service healthy or unhealthy
+	Readiness *Probe `json:"readiness,omitempty" yaml:"readiness,omitempty"`
+}
+
+// Kind returns the probe type.
+//
+// Returns:
+//   - kind: string exec, http, tcp or grpc, empty when none is set
+func (p *Probe) Kind() string {
+	switch {
+	case p.Exec != nil:
+		return "exec"
+	case p.HTTP != nil:
+		return "http"
+	case p.TCP != nil:
+		return "tcp"
+	case p.GRPC != nil:
+		return "grpc"
+	}
+	return ""
+}
+
+// applyDefaults fills the unset probe settings
+func (p *Probes) applyDefaults() {
+	for _, probe := range []*Probe{p.Startup, p.Liveness, p.Readiness} {
+		if probe == nil {
+			continue
+		}
+		if probe.Interval == 0 {
+			probe.Interval = DefaultProbeInterval
+		}
+		if probe.Timeout == 0 {
+			probe.Timeout = DefaultProbeTimeout
+		}
+		if probe.SuccessThreshold == 0 {
+			probe.SuccessThreshold = DefaultProbeSuccessThreshold
+		}
+		if probe.FailureThreshold == 0 {
+			probe.FailureThreshold = DefaultProbeFailureThreshold
+		}
+	}
+}
+
+// validate checks every probe; liveness and startup pass on the first success
+func (p *Probes) validate() error {
+	probes := []struct {
+		name  string
+		probe *Probe
+	}{{"startup", p.Startup}, {"liveness", p.Liveness}, {"readiness", p.Readiness}}
+
+	for _, entry := range probes {
+		if entry.probe == nil {
+			continue
+		}
+		if err := entry.probe.validate(); err != nil {
+			return fmt.Errorf("%s: %w", entry.name, err)
+		}
+		if entry.name != "readiness" && entry.probe.SuccessThreshold != 1 {
+			return fmt.Errorf("%s: success_threshold must be 1", entry.name)
+		}
+	}
+	return nil
+}
+
+// validate checks the probe type and its settings
+func (p *Probe) validate() error {
+	set := 0
+	for _, kind := range []bool{p.Exec != nil, p.HTTP != nil, p.TCP != nil, p.GRPC != nil} {
+		if kind {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("exactly one of exec, http, tcp or grpc is required")
+	}
+
+	switch {
+	case p.InitialDelay < 0:
+		return errors.New("initial_delay cannot be negative")
+	case p.Interval <= 0:
+		return errors.New("interval must be positive")
+	case p.Timeout <= 0:
+		return errors.New("timeout must be positive")
+	case p.SuccessThreshold < 1:
+		return errors.New("success_threshold must be at least 1")
+	case p.FailureThreshold < 1:
+		return errors.New("failure_threshold must be at least 1")
+	}
+
+	switch {
+	case p.Exec != nil:
+		if p.Exec.Command == "" {
+			return errors.New("exec.command is required")
+		}
+	case p.HTTP != nil:
+		u, err := url.Parse(p.HTTP.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid http.url %q: must be an absolute http or https URL", p.HTTP.URL)
+		}
+		if p.HTTP.Status != 0 && (p.HTTP.Status < 100 || p.HTTP.Status > 599) {
+			return fmt.Errorf("invalid http.status %d", p.HTTP.Status)
+		}
+	case p.TCP != nil:
+		if _, _, err := net.SplitHostPort(p.TCP.Address); err != nil {
+			return fmt.Errorf("invalid tcp.address %q: must be host:port", p.TCP.Address)
+		}
+	case p.GRPC != nil:
+		if _, _, err := net.SplitHostPort(p.GRPC.Address); err != nil {
+			return fmt.Errorf("invalid grpc.address %q: must be host:port", p.GRPC.Address)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package providers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSupervizConfig_Probes(t *testing.T) {
+	config, err := ParseSupervizConfig([]byte(`
+services:
+  db:
+    command: postgres
+    probes:
+      startup:
+        tcp: {address: "127.0.0.1:5432"}
+        interval: 1s
+        failure_threshold: 30
+      liveness:
+        exec: {command: pg_isready, args: [-q]}
+      readiness:
+        grpc: {address: "127.0.0.1:9090", service: db}
+        initial_delay: 2s
+        success_threshold: 2
+  web:
+    command: web
+    probes:
+      readiness:
+        http: {url: "http://127.0.0.1:8080/healthz", status: 204, body: ok}
+        timeout: 3s
+`), "/srv")
+	require.NoError(t, err)
+
+	db := config.Services["db"].Probes
+	require.NotNil(t, db.Startup)
+	assert.Equal(t, "tcp", db.Startup.Kind())
+	assert.Equal(t, time.Second, db.Startup.Interval)
+	assert.Equal(t, 30, db.Startup.FailureThreshold)
+	assert.Equal(t, DefaultProbeTimeout, db.Startup.Timeout)
+	assert.Equal(t, &ExecProbe{Command: "pg_isready", Args: []string{"-q"}}, db.Liveness.Exec)
+	assert.Equal(t, DefaultProbeInterval, db.Liveness.Interval)
+	assert.Equal(t, DefaultProbeSuccessThreshold, db.Liveness.SuccessThreshold)
+	assert.Equal(t, DefaultProbeFailureThreshold, db.Liveness.FailureThreshold)
+	assert.Equal(t, &GRPCProbe{Address: "127.0.0.1:9090", Service: "db"}, db.Readiness.GRPC)
+	assert.Equal(t, 2*time.Second, db.Readiness.InitialDelay)
+	assert.Equal(t, 2, db.Readiness.SuccessThreshold)
+
+	web := config.Services["web"].Probes
+	assert.Nil(t, web.Startup)
+	assert.Nil(t, web.Liveness)
+	assert.Equal(t, &HTTPProbe{URL: "http://127.0.0.1:8080/healthz", Status: 204, Body: "ok"}, web.Readiness.HTTP)
+	assert.Equal(t, 3*time.Second, web.Readiness.Timeout)
+}
+
+func TestParseSupervizConfig_InvalidProbes(t *testing.T) {
+	tests := map[string]struct {
+		probes string
+		want   string
+	}{
+		"no type":            {"readiness: {interval: 1s}", "probes: readiness: exactly one of exec, http, tcp or grpc is required"},
+		"two types":          {"readiness: {tcp: {address: ':1'}, grpc: {address: ':2'}}", "exactly one of"},
+		"exec command":       {"liveness: {exec: {command: ''}}", "exec.command is required"},
+		"http url":           {"readiness: {http: {url: '/healthz'}}", `invalid http.url "/healthz"`},
+		"http scheme":        {"readiness: {http: {url: 'ftp://host/'}}", "must be an absolute http or https URL"},
+		"http status":        {"readiness: {http: {url: 'http://host/', status: 42}}", "invalid http.status 42"},
+		"tcp address":        {"readiness: {tcp: {address: localhost}}", `invalid tcp.address "localhost"`},
+		"grpc address":       {"readiness: {grpc: {address: ''}}", `invalid grpc.address ""`},
+		"negative delay":     {"readiness: {tcp: {address: ':1'}, initial_delay: -1s}", "initial_delay cannot be negative"},
+		"negative interval":  {"readiness: {tcp: {address: ':1'}, interval: -1s}", "interval must be positive"},
+		"negative timeout":   {"readiness: {tcp: {address: ':1'}, timeout: -1s}", "timeout must be positive"},
+		"failure threshold":  {"readiness: {tcp: {address: ':1'}, failure_threshold: -1}", "failure_threshold must be at least 1"},
+		"liveness successes": {"liveness: {tcp: {address: ':1'}, success_threshold: 2}", "probes: liveness: success_threshold must be 1"},
+		"startup successes":  {"startup: {tcp: {address: ':1'}, success_threshold: 3}", "probes: startup: success_threshold must be 1"},
+		"unknown field":      {"readiness: {tcp: {address: ':1'}, retries: 3}", "field retries not found"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSupervizConfig([]byte("services:\n  web:\n    command: web\n    probes: {"+tt.probes+"}\n"), "/srv")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestProbe_Kind(t *testing.T) {
+	assert.Equal(t, "exec", (&Probe{Exec: &ExecProbe{}}).Kind())
+	assert.Equal(t, "http", (&Probe{HTTP: &HTTPProbe{}}).Kind())
+	assert.Equal(t, "tcp", (&Probe{TCP: &TCPProbe{}}).Kind())
+	assert.Equal(t, "grpc", (&Probe{GRPC: &GRPCProbe{}}).Kind())
+	assert.Empty(t, (&Probe{}).Kind())
+}
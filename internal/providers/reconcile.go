@@ -0,0 +1,80 @@
+// internal/providers/reconcile.go - Desired-state reconciliation settings of superviz.yaml
+package providers
+
+import (
+	"errors"
+	"time"
+)
+
+// Reconciliation defaults.
+const (
+	// DefaultReconcileInterval is the period of the reconciliation passes
+	DefaultReconcileInterval = 30 * time.Second
+	// DefaultReconcileMaxActions caps the corrective actions taken per window
+	DefaultReconcileMaxActions = 10
+	// DefaultReconcileWindow is the period the action cap applies to
+	DefaultReconcileWindow = time.Minute
+	// DefaultReconcileBackoff is the delay before acting again on the same resource
+	DefaultReconcileBackoff = 10 * time.Second
+	// DefaultReconcileMaxBackoff caps the delay between actions on the same resource
+	DefaultReconcileMaxBackoff = 5 * time.Minute
+)
+
+// ReconcileConfig periodically compares the declared state with the observed
+// one: fatal services, failing files, missing artifacts and invalid
+// environments, and corrects what can be corrected.
+//
+// Example:
+//
+//	reconcile:
+//	  interval: 1m
+//	  max_actions: 5
+//	  window: 10m
+type ReconcileConfig struct {
+	// Interval is the period of the passes, default 30s
+	Interval time.Duration `yaml:"interval,omitempty"`
+	// MaxActions caps the corrective actions taken per window, default 10
+	MaxActions int `yaml:"max_actions,omitempty"`
+	// Window is the period MaxActions applies to, default 1m
+	Window time.Duration `yaml:"window,omitempty"`
+	// Backoff is the delay before a second action on a resource still diverging, doubled at each action, default 10s
+	Backoff time.Duration `yaml:"backoff,omitempty"`
+	// MaxBackoff caps the delay between actions on one resource, default 5m
+	MaxBackoff time.Duration `yaml:"max_backoff,omitempty"`
+}
+
+// applyDefaults fills the unset settings
+func (r *ReconcileConfig) applyDefaults() {
+	if r.Interval == 0 {
+		r.Interval = DefaultReconcileInterval
+	}
+	if r.MaxActions == 0 {
+		r.MaxActions = DefaultReconcileMaxActions
+	}
+	if r.Window == 0 {
+		r.Window = DefaultReconcileWindow
+	}
+	if r.Backoff == 0 {
+		r.Backoff = DefaultReconcileBackoff
+	}
+	if r.MaxBackoff == 0 {
+		r.MaxBackoff = DefaultReconcileMaxBackoff
+	}
+}
+
+// validate checks the durations and the action cap
+func (r *ReconcileConfig) validate() error {
+	switch {
+	case r.Interval < 0:
+		return errors.New("interval cannot be negative")
+	case r.MaxActions < 0:
+		return errors.New("max_actions cannot be negative")
+	case r.Window < 0:
+		return errors.New("window cannot be negative")
+	case r.Backoff < 0:
+		return errors.New("backoff cannot be negative")
+	case r.MaxBackoff < r.Backoff:
+		return errors.New("max_backoff cannot be less than backoff")
+	}
+	return nil
+}
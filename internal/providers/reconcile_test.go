@@ -0,0 +1,51 @@
+package providers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSupervizConfig_Reconcile(t *testing.T) {
+	config, err := ParseSupervizConfig([]byte("services:\n  a: {command: x}\n"), "/srv")
+	require.NoError(t, err)
+	assert.Nil(t, config.Reconcile, "reconciliation is opt-in")
+
+	config, err = ParseSupervizConfig([]byte("reconcile: {}\nservices:\n  a: {command: x}\n"), "/srv")
+	require.NoError(t, err)
+	assert.Equal(t, &ReconcileConfig{
+		Interval:   DefaultReconcileInterval,
+		MaxActions: DefaultReconcileMaxActions,
+		Window:     DefaultReconcileWindow,
+		Backoff:    DefaultReconcileBackoff,
+		MaxBackoff: DefaultReconcileMaxBackoff,
+	}, config.Reconcile)
+
+	config, err = ParseSupervizConfig([]byte("reconcile: {interval: 1m, max_actions: 3, window: 10m, backoff: 1s, max_backoff: 1m}\nservices:\n  a: {command: x}\n"), "/srv")
+	require.NoError(t, err)
+	assert.Equal(t, &ReconcileConfig{Interval: time.Minute, MaxActions: 3, Window: 10 * time.Minute, Backoff: time.Second, MaxBackoff: time.Minute}, config.Reconcile)
+}
+
+func TestParseSupervizConfig_InvalidReconcile(t *testing.T) {
+	tests := map[string]struct {
+		reconcile string
+		want      string
+	}{
+		"interval":    {"{interval: -1s}", "reconcile: interval cannot be negative"},
+		"max_actions": {"{max_actions: -1}", "reconcile: max_actions cannot be negative"},
+		"window":      {"{window: -1s}", "reconcile: window cannot be negative"},
+		"backoff":     {"{backoff: -1s}", "reconcile: backoff cannot be negative"},
+		"max_backoff": {"{backoff: 1m, max_backoff: 10s}", "reconcile: max_backoff cannot be less than backoff"},
+		"unknown":     {"{every: 1m}", "field every not found"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSupervizConfig([]byte("reconcile: "+tt.reconcile+"\nservices:\n  a: {command: x}\n"), "/srv")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
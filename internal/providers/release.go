@@ -0,0 +1,154 @@
+// internal/providers/release.go - Release index and signing key for self-update
+package providers
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Release channels and index location.
+const (
+	// DefaultReleaseIndexURL is the release index consulted by self-update
+	DefaultReleaseIndexURL = "https://superviz.io/download/index.json"
+	// ChannelStable receives production releases
+	ChannelStable = "stable"
+	// ChannelBeta receives release candidates ahead of stable
+	ChannelBeta = "beta"
+)
+
+// releasePublicKey is the base64 Ed25519 key verifying release artifacts (set via ldflags)
+var releasePublicKey = ""
+
+// ReleasePublicKey returns the key release artifacts are signed with.
+//
+// Returns:
+//   - key: ed25519.PublicKey embedded at build time
+//   - err: error if the binary was built without a key or the key is malformed
+func ReleasePublicKey() (ed25519.PublicKey, error) {
+	return ParseReleasePublicKey(releasePublicKey)
+}
+
+// ParseReleasePublicKey decodes a base64 Ed25519 public key.
+//
+// Parameters:
+//   - encoded: string base64 standard encoding of the 32-byte key
+//
+// Returns:
+//   - key: ed25519.PublicKey decoded key
+//   - err: error if the key is empty or malformed
+func ParseReleasePublicKey(encoded string) (ed25519.PublicKey, error) {
+	if encoded == "" {
+		return nil, errors.New("no release signing key embedded in this build")
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid release signing key: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid release signing key: expected %d bytes, got %d", ed25519.PublicKeySize, len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// ReleaseArtifact is a downloadable binary for one platform.
+type ReleaseArtifact struct {
+	// OS is the GOOS the binary was built for
+	OS string `json:"os"`
+	// Arch is the GOARCH the binary was built for
+	Arch string `json:"arch"`
+	// URL is the download location of the raw binary
+	URL string `json:"url"`
+	// SHA256 is the hex digest of the binary
+	SHA256 string `json:"sha256"`
+	// Signature is the base64 Ed25519 signature of the binary
+	Signature string `json:"signature"`
+}
+
+// Release is one published version.
+type Release struct {
+	// Version is the release version without a leading "v"
+	Version string `json:"version"`
+	// Channel is the channel the release was published to
+	Channel string `json:"channel"`
+	// Artifacts lists the binaries per platform
+	Artifacts []ReleaseArtifact `json:"artifacts"`
+}
+
+// ReleaseIndex lists the published releases and the current version of each channel.
+//
+// Example:
+//
+//	{
+//	  "channels": {"stable": "1.4.0", "beta": "1.5.0-rc.1"},
+//	  "releases": [{"version": "1.4.0", "channel": "stable", "artifacts": [
+//	    {"os": "linux", "arch": "amd64", "url": "https://...", "sha256": "...", "signature": "..."}
+//	  ]}]
+//	}
+type ReleaseIndex struct {
+	// Channels maps a channel name to its current version
+	Channels map[string]string `json:"channels"`
+	// Releases lists every published release
+	Releases []Release `json:"releases"`
+}
+
+// Resolve returns the release selected by an explicit version or a channel.
+//
+// Parameters:
+//   - channel: string channel name, used when version is empty
+//   - version: string exact version, with or without a leading "v"
+//
+// Returns:
+//   - release: *Release selected release
+//   - err: error if the channel or version is unknown
+func (idx *ReleaseIndex) Resolve(channel, version string) (*Release, error) {
+	if version == "" {
+		current, ok := idx.Channels[channel]
+		if !ok {
+			return nil, fmt.Errorf("unknown release channel %q, available channels: %s", channel, idx.channelList())
+		}
+		version = current
+	}
+	version = strings.TrimPrefix(version, "v")
+
+	for i := range idx.Releases {
+		if idx.Releases[i].Version == version {
+			return &idx.Releases[i], nil
+		}
+	}
+	return nil, fmt.Errorf("release %s not found in the release index", version)
+}
+
+// channelList returns the channel names for error messages
+func (idx *ReleaseIndex) channelList() string {
+	names := make([]string, 0, len(idx.Channels))
+	for name := range idx.Channels {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Artifact returns the binary built for a platform.
+//
+// Parameters:
+//   - goos: string target operating system
+//   - goarch: string target architecture
+//
+// Returns:
+//   - artifact: *ReleaseArtifact matching binary
+//   - err: error if the release has no binary for the platform
+func (r *Release) Artifact(goos, goarch string) (*ReleaseArtifact, error) {
+	for i := range r.Artifacts {
+		if r.Artifacts[i].OS == goos && r.Artifacts[i].Arch == goarch {
+			return &r.Artifacts[i], nil
+		}
+	}
+	return nil, fmt.Errorf("release %s has no binary for %s/%s", r.Version, goos, goarch)
+}
@@ -0,0 +1,74 @@
+package providers
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReleaseIndex() *ReleaseIndex {
+	return &ReleaseIndex{
+		Channels: map[string]string{ChannelStable: "1.4.0", ChannelBeta: "1.5.0-rc.1"},
+		Releases: []Release{
+			{Version: "1.4.0", Channel: ChannelStable, Artifacts: []ReleaseArtifact{
+				{OS: "linux", Arch: "amd64", URL: "https://example.com/svz_linux_amd64"},
+				{OS: "darwin", Arch: "arm64", URL: "https://example.com/svz_darwin_arm64"},
+			}},
+			{Version: "1.5.0-rc.1", Channel: ChannelBeta},
+		},
+	}
+}
+
+func TestReleaseIndex_Resolve(t *testing.T) {
+	idx := testReleaseIndex()
+
+	release, err := idx.Resolve(ChannelStable, "")
+	require.NoError(t, err)
+	assert.Equal(t, "1.4.0", release.Version)
+
+	release, err = idx.Resolve(ChannelStable, "v1.5.0-rc.1")
+	require.NoError(t, err)
+	assert.Equal(t, ChannelBeta, release.Channel)
+
+	_, err = idx.Resolve("nightly", "")
+	assert.EqualError(t, err, `unknown release channel "nightly", available channels: beta, stable`)
+
+	_, err = idx.Resolve(ChannelStable, "9.9.9")
+	assert.EqualError(t, err, "release 9.9.9 not found in the release index")
+
+	_, err = (&ReleaseIndex{}).Resolve(ChannelStable, "")
+	assert.ErrorContains(t, err, "available channels: none")
+}
+
+func TestRelease_Artifact(t *testing.T) {
+	release := &testReleaseIndex().Releases[0]
+
+	artifact, err := release.Artifact("darwin", "arm64")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/svz_darwin_arm64", artifact.URL)
+
+	_, err = release.Artifact("windows", "amd64")
+	assert.EqualError(t, err, "release 1.4.0 has no binary for windows/amd64")
+}
+
+func TestParseReleasePublicKey(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	key, err := ParseReleasePublicKey(base64.StdEncoding.EncodeToString(pub))
+	require.NoError(t, err)
+	assert.Equal(t, pub, key)
+
+	_, err = ParseReleasePublicKey("")
+	assert.ErrorContains(t, err, "no release signing key")
+	_, err = ParseReleasePublicKey("not base64!")
+	assert.ErrorContains(t, err, "invalid release signing key")
+	_, err = ParseReleasePublicKey(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.ErrorContains(t, err, "expected 32 bytes, got 5")
+
+	_, err = ReleasePublicKey()
+	assert.Error(t, err, "test builds embed no key")
+}
@@ -0,0 +1,197 @@
+// internal/providers/reload.go - Differences between two configurations for a hot reload
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ServiceChange names a changed service and the fields that differ.
+type ServiceChange struct {
+	// Service is the service name
+	Service string `json:"service" yaml:"service"`
+	// Fields are the YAML names of the changed fields
+	Fields []string `json:"fields" yaml:"fields"`
+}
+
+// ConfigDiff lists what a reload changes in a running configuration.
+//
+// Services are sorted by name in every list.
+type ConfigDiff struct {
+	// Added are the services to create and start
+	Added []string `json:"added,omitempty" yaml:"added,omitempty"`
+	// Removed are the services to stop and forget
+	Removed []string `json:"removed,omitempty" yaml:"removed,omitempty"`
+	// Restart are the services whose process must be started again for the change to apply
+	Restart []ServiceChange `json:"restart,omitempty" yaml:"restart,omitempty"`
+	// Update are the services changed only in fields applied to the running process
+	Update []ServiceChange `json:"update,omitempty" yaml:"update,omitempty"`
+	// Settings are the top-level sections applied in place, such as on_fatal
+	Settings []string `json:"settings,omitempty" yaml:"settings,omitempty"`
+	// Unapplied are the top-level sections that only take effect when svz restarts
+	Unapplied []string `json:"unapplied,omitempty" yaml:"unapplied,omitempty"`
+}
+
+// serviceField reads one field of a service definition for comparison.
+type serviceField struct {
+	// name is the YAML name of the field
+	name string
+	// value returns the field of a service
+	value func(*ServiceConfig) any
+}
+
+// restartFields change the process itself, the service is restarted to apply them
+var restartFields = []serviceField{
+	{"command", func(s *ServiceConfig) any { return s.Command }},
+	{"args", func(s *ServiceConfig) any { return s.Args }},
+	{"env", func(s *ServiceConfig) any { return s.Env }},
+	{"working_dir", func(s *ServiceConfig) any { return s.WorkingDir }},
+	{"user", func(s *ServiceConfig) any { return s.User }},
+	{"group", func(s *ServiceConfig) any { return s.Group }},
+	{"logs", func(s *ServiceConfig) any { return s.Logs }},
+}
+
+// reloadableFields are read by the supervisor as needed, the running process is kept
+var reloadableFields = []serviceField{
+	{"restart", func(s *ServiceConfig) any { return s.Restart }},
+	{"restart_policy", func(s *ServiceConfig) any { return s.RestartPolicy }},
+	{"depends_on", func(s *ServiceConfig) any { return s.DependsOn }},
+	{"probes", func(s *ServiceConfig) any { return s.Probes }},
+	{"stop_signal", func(s *ServiceConfig) any { return s.StopSignal }},
+	{"stop_timeout", func(s *ServiceConfig) any { return s.StopTimeout }},
+}
+
+// Diff compares the configuration with the one replacing it.
+//
+// A service whose command, arguments, environment, working directory, user,
+// group or logs change is restarted; a service changed only in its restart
+// mode and policy, dependencies, probes or stop settings keeps its process.
+// The control, metrics, log_forward, integrity, artifacts and reconcile
+// sections are reported as unapplied: svz must be restarted for them to
+// change.
+//
+// Parameters:
+//   - next: *SupervizConfig validated configuration replacing c
+//
+// Returns:
+//   - diff: *ConfigDiff changes from c to next
+//   - err: error if either configuration is nil
+func (c *SupervizConfig) Diff(next *SupervizConfig) (*ConfigDiff, error) {
+	if c == nil || next == nil {
+		return nil, errors.New("config cannot be nil")
+	}
+	diff := &ConfigDiff{}
+	for _, name := range next.Names() {
+		current, ok := c.Services[name]
+		if !ok {
+			diff.Added = append(diff.Added, name)
+			continue
+		}
+		if fields := changedFields(restartFields, current, next.Services[name]); len(fields) > 0 {
+			diff.Restart = append(diff.Restart, ServiceChange{Service: name, Fields: fields})
+		} else if fields := changedFields(reloadableFields, current, next.Services[name]); len(fields) > 0 {
+			diff.Update = append(diff.Update, ServiceChange{Service: name, Fields: fields})
+		}
+	}
+	for _, name := range c.Names() {
+		if _, ok := next.Services[name]; !ok {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+
+	if !reflect.DeepEqual(c.OnFatal, next.OnFatal) {
+		diff.Settings = append(diff.Settings, "on_fatal")
+	}
+	sections := []struct {
+		name          string
+		current, next any
+	}{
+		{"control", c.Control, next.Control},
+		{"metrics", c.Metrics, next.Metrics},
+		{"log_forward", c.LogForward, next.LogForward},
+		{"integrity", c.Integrity, next.Integrity},
+		{"artifacts", c.Artifacts, next.Artifacts},
+		{"reconcile", c.Reconcile, next.Reconcile},
+	}
+	for _, section := range sections {
+		if !reflect.DeepEqual(section.current, section.next) {
+			diff.Unapplied = append(diff.Unapplied, section.name)
+		}
+	}
+	return diff, nil
+}
+
+// changedFields returns the names of the fields differing between two definitions
+func changedFields(fields []serviceField, current, next *ServiceConfig) []string {
+	var changed []string
+	for _, field := range fields {
+		if !reflect.DeepEqual(field.value(current), field.value(next)) {
+			changed = append(changed, field.name)
+		}
+	}
+	return changed
+}
+
+// Empty reports whether the reload changes nothing.
+//
+// Returns:
+//   - empty: bool true when every list is empty
+func (d *ConfigDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Restart) == 0 && len(d.Update) == 0 &&
+		len(d.Settings) == 0 && len(d.Unapplied) == 0
+}
+
+// Format describes the changes, one line per service or section.
+//
+// Returns:
+//   - formatted: string lines such as "restart web: command, env", each ending with a newline
+func (d *ConfigDiff) Format() string {
+	var b strings.Builder
+	for _, name := range d.Added {
+		fmt.Fprintf(&b, "add %s\n", name)
+	}
+	for _, name := range d.Removed {
+		fmt.Fprintf(&b, "remove %s\n", name)
+	}
+	for _, change := range d.Restart {
+		fmt.Fprintf(&b, "restart %s: %s\n", change.Service, strings.Join(change.Fields, ", "))
+	}
+	for _, change := range d.Update {
+		fmt.Fprintf(&b, "update %s: %s\n", change.Service, strings.Join(change.Fields, ", "))
+	}
+	for _, section := range d.Settings {
+		fmt.Fprintf(&b, "update %s\n", section)
+	}
+	for _, section := range d.Unapplied {
+		fmt.Fprintf(&b, "unapplied %s: restart svz to apply\n", section)
+	}
+	return b.String()
+}
+
+// Summary counts the changes.
+//
+// Returns:
+//   - summary: string such as "1 added, 2 restarted", or "nothing changed"
+func (d *ConfigDiff) Summary() string {
+	var parts []string
+	for _, count := range []struct {
+		n    int
+		verb string
+	}{
+		{len(d.Added), "added"},
+		{len(d.Removed), "removed"},
+		{len(d.Restart), "restarted"},
+		{len(d.Update) + len(d.Settings), "updated"},
+		{len(d.Unapplied), "unapplied"},
+	} {
+		if count.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count.n, count.verb))
+		}
+	}
+	if len(parts) == 0 {
+		return "nothing changed"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,74 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupervizConfig_Diff(t *testing.T) {
+	parse := func(content string) *SupervizConfig {
+		config, err := ParseSupervizConfig([]byte(content), "/srv")
+		require.NoError(t, err)
+		return config
+	}
+	current := parse(`services:
+  db: {command: postgres}
+  web: {command: web, depends_on: [db], env: {PORT: "8080"}}
+  worker: {command: worker}
+  cron: {command: cron}
+`)
+
+	diff, err := current.Diff(current)
+	require.NoError(t, err)
+	assert.True(t, diff.Empty())
+	assert.Equal(t, "nothing changed", diff.Summary())
+	assert.Empty(t, diff.Format())
+
+	next := parse(`on_fatal: {action: exit}
+metrics: {listen: ":9090"}
+reconcile: {}
+services:
+  db: {command: postgres, stop_timeout: 30s, restart: always}
+  web: {command: web, depends_on: [db], env: {PORT: "8081"}, args: [--v2]}
+  worker: {command: worker}
+  api: {command: api, depends_on: [db]}
+`)
+	diff, err = current.Diff(next)
+	require.NoError(t, err)
+	assert.Equal(t, &ConfigDiff{
+		Added:     []string{"api"},
+		Removed:   []string{"cron"},
+		Restart:   []ServiceChange{{Service: "web", Fields: []string{"args", "env"}}},
+		Update:    []ServiceChange{{Service: "db", Fields: []string{"restart", "stop_timeout"}}},
+		Settings:  []string{"on_fatal"},
+		Unapplied: []string{"metrics", "reconcile"},
+	}, diff)
+	assert.False(t, diff.Empty())
+	assert.Equal(t, "add api\n"+
+		"remove cron\n"+
+		"restart web: args, env\n"+
+		"update db: restart, stop_timeout\n"+
+		"update on_fatal\n"+
+		"unapplied metrics: restart svz to apply\n"+
+		"unapplied reconcile: restart svz to apply\n", diff.Format())
+	assert.Equal(t, "1 added, 1 removed, 1 restarted, 2 updated, 2 unapplied", diff.Summary())
+
+	_, err = current.Diff(nil)
+	assert.Error(t, err)
+	_, err = (*SupervizConfig)(nil).Diff(current)
+	assert.Error(t, err)
+}
+
+func TestSupervizConfig_DiffRestartWinsOverUpdate(t *testing.T) {
+	current, err := ParseSupervizConfig([]byte("services:\n  web: {command: web}\n"), "/srv")
+	require.NoError(t, err)
+	next, err := ParseSupervizConfig([]byte("services:\n  web: {command: web2, stop_signal: INT}\n"), "/srv")
+	require.NoError(t, err)
+
+	diff, err := current.Diff(next)
+	require.NoError(t, err)
+	assert.Equal(t, []ServiceChange{{Service: "web", Fields: []string{"command"}}}, diff.Restart)
+	assert.Empty(t, diff.Update, "the restart applies the stop signal too")
+}
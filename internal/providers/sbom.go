@@ -0,0 +1,331 @@
+// internal/providers/sbom.go - CycloneDX and SPDX software bills of materials for the svz binary
+package providers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SBOMFormat selects the software bill of materials standard.
+type SBOMFormat string
+
+// Supported SBOM formats.
+const (
+	// SBOMCycloneDX emits a CycloneDX 1.5 JSON document
+	SBOMCycloneDX SBOMFormat = "cyclonedx"
+	// SBOMSPDX emits an SPDX 2.3 JSON document
+	SBOMSPDX SBOMFormat = "spdx"
+)
+
+// ParseSBOMFormat validates an SBOM format name.
+//
+// Parameters:
+//   - s: string format name, cyclonedx or spdx
+//
+// Returns:
+//   - format: SBOMFormat parsed format
+//   - err: error if the format is not supported
+func ParseSBOMFormat(s string) (SBOMFormat, error) {
+	switch f := SBOMFormat(strings.ToLower(s)); f {
+	case SBOMCycloneDX, SBOMSPDX:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported SBOM format %q: must be %s or %s", s, SBOMCycloneDX, SBOMSPDX)
+	}
+}
+
+// SBOMSubject is the binary an SBOM describes.
+type SBOMSubject struct {
+	// Version is the ldflags version information of the binary
+	Version VersionInfo
+	// Build is the build information embedded by the Go toolchain
+	Build BuildProvenance
+	// SHA256 is the hex digest of the binary file, empty if it could not be read
+	SHA256 string
+}
+
+// version returns the most precise version of the subject
+func (s SBOMSubject) version() string {
+	if s.Version.Version != "" && s.Version.Version != "dev" {
+		return s.Version.Version
+	}
+	if s.Build.Main.Version != "(devel)" {
+		return s.Build.Main.Version
+	}
+	return s.Version.Version
+}
+
+// purl returns the package URL of the subject's main module
+func (s SBOMSubject) purl() string {
+	return goPURL(Module{Path: s.Build.Main.Path, Version: s.version()})
+}
+
+// goPURL returns the package URL of a Go module (https://github.com/package-url/purl-spec)
+func goPURL(m Module) string {
+	purl := "pkg:golang/" + m.Path
+	if m.Version != "" && m.Version != "(devel)" && m.Version != "dev" {
+		purl += "@" + m.Version
+	}
+	return purl
+}
+
+// buildProperties returns the build settings as name/value pairs
+func (s SBOMSubject) buildProperties() [][2]string {
+	cgo := "0"
+	if s.Build.CGOEnabled {
+		cgo = "1"
+	}
+	props := [][2]string{
+		{"golang:go_version", s.Build.GoVersion},
+		{"golang:build:GOOS", s.Build.GOOS},
+		{"golang:build:GOARCH", s.Build.GOARCH},
+		{"golang:build:CGO_ENABLED", cgo},
+		{"golang:build:tags", strings.Join(s.Build.Tags, ",")},
+		{"vcs:revision", s.Build.VCS.Revision},
+		{"vcs:time", s.Build.VCS.Time},
+		{"vcs:modified", fmt.Sprint(s.Build.VCS.Modified)},
+		{"superviz:commit", s.Version.Commit},
+		{"superviz:built_by", s.Version.BuiltBy},
+	}
+	if s.Build.GOAMD64 != "" {
+		props = append(props, [2]string{"golang:build:GOAMD64", s.Build.GOAMD64})
+	}
+	return props
+}
+
+// CycloneDX document types, limited to the fields svz emits.
+type (
+	// CycloneDXBOM is a CycloneDX 1.5 JSON document
+	CycloneDXBOM struct {
+		BOMFormat    string                `json:"bomFormat"`
+		SpecVersion  string                `json:"specVersion"`
+		SerialNumber string                `json:"serialNumber"`
+		Version      int                   `json:"version"`
+		Metadata     CycloneDXMetadata     `json:"metadata"`
+		Components   []CycloneDXComponent  `json:"components"`
+		Dependencies []CycloneDXDependency `json:"dependencies"`
+	}
+	// CycloneDXMetadata describes the document and its subject
+	CycloneDXMetadata struct {
+		Timestamp string             `json:"timestamp"`
+		Tools     CycloneDXTools     `json:"tools"`
+		Component CycloneDXComponent `json:"component"`
+	}
+	// CycloneDXTools lists the tools that produced the document
+	CycloneDXTools struct {
+		Components []CycloneDXComponent `json:"components"`
+	}
+	// CycloneDXComponent is an application or library
+	CycloneDXComponent struct {
+		Type       string              `json:"type"`
+		BOMRef     string              `json:"bom-ref,omitempty"`
+		Name       string              `json:"name"`
+		Version    string              `json:"version,omitempty"`
+		PURL       string              `json:"purl,omitempty"`
+		Hashes     []CycloneDXHash     `json:"hashes,omitempty"`
+		Properties []CycloneDXProperty `json:"properties,omitempty"`
+	}
+	// CycloneDXHash is a file digest
+	CycloneDXHash struct {
+		Alg     string `json:"alg"`
+		Content string `json:"content"`
+	}
+	// CycloneDXProperty is a name/value annotation
+	CycloneDXProperty struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	// CycloneDXDependency lists the direct dependencies of a component
+	CycloneDXDependency struct {
+		Ref       string   `json:"ref"`
+		DependsOn []string `json:"dependsOn"`
+	}
+)
+
+// NewCycloneDXBOM builds a CycloneDX document describing the binary and its linked modules.
+//
+// Parameters:
+//   - subject: SBOMSubject binary to describe
+//   - serial: string RFC 4122 UUID identifying the document
+//   - created: time.Time document creation time
+//
+// Returns:
+//   - bom: *CycloneDXBOM document ready to be encoded as JSON
+func NewCycloneDXBOM(subject SBOMSubject, serial string, created time.Time) *CycloneDXBOM {
+	main := CycloneDXComponent{
+		Type:    "application",
+		BOMRef:  subject.purl(),
+		Name:    "svz",
+		Version: subject.version(),
+		PURL:    subject.purl(),
+	}
+	if subject.SHA256 != "" {
+		main.Hashes = []CycloneDXHash{{Alg: "SHA-256", Content: subject.SHA256}}
+	}
+	for _, prop := range subject.buildProperties() {
+		main.Properties = append(main.Properties, CycloneDXProperty{Name: prop[0], Value: prop[1]})
+	}
+
+	bom := &CycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + serial,
+		Version:      1,
+		Metadata: CycloneDXMetadata{
+			Timestamp: created.UTC().Format(time.RFC3339),
+			Tools:     CycloneDXTools{Components: []CycloneDXComponent{{Type: "application", Name: "svz", Version: subject.version()}}},
+			Component: main,
+		},
+		Components:   []CycloneDXComponent{},
+		Dependencies: []CycloneDXDependency{{Ref: main.BOMRef, DependsOn: []string{}}},
+	}
+
+	for _, dep := range subject.Build.Dependencies {
+		linked := dep.Effective()
+		component := CycloneDXComponent{
+			Type:    "library",
+			BOMRef:  goPURL(linked),
+			Name:    linked.Path,
+			Version: linked.Version,
+			PURL:    goPURL(linked),
+		}
+		if linked.Sum != "" {
+			component.Properties = append(component.Properties, CycloneDXProperty{Name: "golang:sum", Value: linked.Sum})
+		}
+		if dep.Replace != nil {
+			component.Properties = append(component.Properties, CycloneDXProperty{Name: "golang:replaces", Value: dep.Path + "@" + dep.Version})
+		}
+		bom.Components = append(bom.Components, component)
+		bom.Dependencies[0].DependsOn = append(bom.Dependencies[0].DependsOn, component.BOMRef)
+	}
+	return bom
+}
+
+// SPDX document types, limited to the fields svz emits.
+type (
+	// SPDXDocument is an SPDX 2.3 JSON document
+	SPDXDocument struct {
+		SPDXVersion       string             `json:"spdxVersion"`
+		DataLicense       string             `json:"dataLicense"`
+		SPDXID            string             `json:"SPDXID"`
+		Name              string             `json:"name"`
+		DocumentNamespace string             `json:"documentNamespace"`
+		CreationInfo      SPDXCreationInfo   `json:"creationInfo"`
+		Packages          []SPDXPackage      `json:"packages"`
+		Relationships     []SPDXRelationship `json:"relationships"`
+	}
+	// SPDXCreationInfo records who created the document and when
+	SPDXCreationInfo struct {
+		Created  string   `json:"created"`
+		Creators []string `json:"creators"`
+	}
+	// SPDXPackage is the binary or a linked module
+	SPDXPackage struct {
+		Name             string            `json:"name"`
+		SPDXID           string            `json:"SPDXID"`
+		VersionInfo      string            `json:"versionInfo,omitempty"`
+		DownloadLocation string            `json:"downloadLocation"`
+		FilesAnalyzed    bool              `json:"filesAnalyzed"`
+		Checksums        []SPDXChecksum    `json:"checksums,omitempty"`
+		ExternalRefs     []SPDXExternalRef `json:"externalRefs,omitempty"`
+		Comment          string            `json:"comment,omitempty"`
+	}
+	// SPDXChecksum is a file digest
+	SPDXChecksum struct {
+		Algorithm     string `json:"algorithm"`
+		ChecksumValue string `json:"checksumValue"`
+	}
+	// SPDXExternalRef links a package to an external identifier such as a purl
+	SPDXExternalRef struct {
+		ReferenceCategory string `json:"referenceCategory"`
+		ReferenceType     string `json:"referenceType"`
+		ReferenceLocator  string `json:"referenceLocator"`
+	}
+	// SPDXRelationship links two SPDX elements
+	SPDXRelationship struct {
+		SPDXElementID      string `json:"spdxElementId"`
+		RelationshipType   string `json:"relationshipType"`
+		RelatedSPDXElement string `json:"relatedSpdxElement"`
+	}
+)
+
+// NewSPDXDocument builds an SPDX document describing the binary and its linked modules.
+//
+// Parameters:
+//   - subject: SBOMSubject binary to describe
+//   - id: string RFC 4122 UUID making the document namespace unique
+//   - created: time.Time document creation time
+//
+// Returns:
+//   - doc: *SPDXDocument document ready to be encoded as JSON
+func NewSPDXDocument(subject SBOMSubject, id string, created time.Time) *SPDXDocument {
+	name := "svz-" + subject.version()
+	main := SPDXPackage{
+		Name:             "svz",
+		SPDXID:           "SPDXRef-Package-svz",
+		VersionInfo:      subject.version(),
+		DownloadLocation: "NOASSERTION",
+		ExternalRefs:     []SPDXExternalRef{purlRef(subject.purl())},
+	}
+	if subject.SHA256 != "" {
+		main.Checksums = []SPDXChecksum{{Algorithm: "SHA256", ChecksumValue: subject.SHA256}}
+	}
+	settings := make([]string, 0, len(subject.buildProperties()))
+	for _, prop := range subject.buildProperties() {
+		settings = append(settings, prop[0]+"="+prop[1])
+	}
+	main.Comment = "Build settings: " + strings.Join(settings, " ")
+
+	doc := &SPDXDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              name,
+		DocumentNamespace: "https://superviz.io/spdx/" + name + "-" + id,
+		CreationInfo: SPDXCreationInfo{
+			Created:  created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + name},
+		},
+		Packages: []SPDXPackage{main},
+		Relationships: []SPDXRelationship{
+			{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: main.SPDXID},
+		},
+	}
+
+	for _, dep := range subject.Build.Dependencies {
+		linked := dep.Effective()
+		pkg := SPDXPackage{
+			Name:             linked.Path,
+			SPDXID:           "SPDXRef-Package-" + spdxIDPart(linked.Path+"-"+linked.Version),
+			VersionInfo:      linked.Version,
+			DownloadLocation: "NOASSERTION",
+			ExternalRefs:     []SPDXExternalRef{purlRef(goPURL(linked))},
+		}
+		if dep.Replace != nil {
+			pkg.Comment = "Replaces " + dep.Path + "@" + dep.Version
+		}
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, SPDXRelationship{
+			SPDXElementID: main.SPDXID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: pkg.SPDXID,
+		})
+	}
+	return doc
+}
+
+// purlRef returns an SPDX external reference to a package URL
+func purlRef(purl string) SPDXExternalRef {
+	return SPDXExternalRef{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: purl}
+}
+
+// spdxIDPart replaces the characters SPDX identifiers do not allow with "-"
+func spdxIDPart(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		default:
+			return '-'
+		}
+	}, s)
+}
@@ -0,0 +1,91 @@
+package providers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sbomCreated = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func testSBOMSubject() SBOMSubject {
+	return SBOMSubject{
+		Version: VersionInfo{Version: "1.3.0", Commit: "1a2b3c4", BuiltBy: "goreleaser"},
+		Build:   NewBuildProvenance(testBuildInfo()),
+		SHA256:  "0123abcd",
+	}
+}
+
+func TestParseSBOMFormat(t *testing.T) {
+	f, err := ParseSBOMFormat("CycloneDX")
+	require.NoError(t, err)
+	assert.Equal(t, SBOMCycloneDX, f)
+
+	f, err = ParseSBOMFormat("spdx")
+	require.NoError(t, err)
+	assert.Equal(t, SBOMSPDX, f)
+
+	_, err = ParseSBOMFormat("swid")
+	assert.ErrorContains(t, err, `unsupported SBOM format "swid"`)
+}
+
+func TestNewCycloneDXBOM(t *testing.T) {
+	bom := NewCycloneDXBOM(testSBOMSubject(), "8f5e0a4c-1d1c-4c57-9a43-3c8e1f0b5d2a", sbomCreated)
+
+	assert.Equal(t, "CycloneDX", bom.BOMFormat)
+	assert.Equal(t, "1.5", bom.SpecVersion)
+	assert.Equal(t, "urn:uuid:8f5e0a4c-1d1c-4c57-9a43-3c8e1f0b5d2a", bom.SerialNumber)
+	assert.Equal(t, "2025-06-01T12:00:00Z", bom.Metadata.Timestamp)
+
+	main := bom.Metadata.Component
+	assert.Equal(t, "pkg:golang/github.com/kodflow/superviz.io@1.3.0", main.PURL)
+	assert.Equal(t, []CycloneDXHash{{Alg: "SHA-256", Content: "0123abcd"}}, main.Hashes)
+	assert.Contains(t, main.Properties, CycloneDXProperty{Name: "golang:build:GOAMD64", Value: "v3"})
+	assert.Contains(t, main.Properties, CycloneDXProperty{Name: "vcs:modified", Value: "true"})
+	assert.Contains(t, main.Properties, CycloneDXProperty{Name: "golang:build:tags", Value: "netgo,osusergo"})
+
+	require.Len(t, bom.Components, 2)
+	assert.Equal(t, "pkg:golang/github.com/spf13/cobra@v1.9.1", bom.Components[0].PURL)
+	assert.Equal(t, "example.com/fork/crypto", bom.Components[1].Name)
+	assert.Contains(t, bom.Components[1].Properties, CycloneDXProperty{Name: "golang:replaces", Value: "golang.org/x/crypto@v0.39.0"})
+	assert.Equal(t, []CycloneDXDependency{{
+		Ref:       main.BOMRef,
+		DependsOn: []string{"pkg:golang/github.com/spf13/cobra@v1.9.1", "pkg:golang/example.com/fork/crypto@v0.39.1"},
+	}}, bom.Dependencies)
+
+	_, err := json.Marshal(bom)
+	require.NoError(t, err)
+}
+
+func TestNewSPDXDocument(t *testing.T) {
+	doc := NewSPDXDocument(testSBOMSubject(), "8f5e0a4c-1d1c-4c57-9a43-3c8e1f0b5d2a", sbomCreated)
+
+	assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	assert.Equal(t, "svz-1.3.0", doc.Name)
+	assert.Equal(t, "https://superviz.io/spdx/svz-1.3.0-8f5e0a4c-1d1c-4c57-9a43-3c8e1f0b5d2a", doc.DocumentNamespace)
+	assert.Equal(t, "2025-06-01T12:00:00Z", doc.CreationInfo.Created)
+
+	require.Len(t, doc.Packages, 3)
+	main := doc.Packages[0]
+	assert.Equal(t, []SPDXChecksum{{Algorithm: "SHA256", ChecksumValue: "0123abcd"}}, main.Checksums)
+	assert.Contains(t, main.Comment, "golang:build:CGO_ENABLED=0")
+	assert.Equal(t, "SPDXRef-Package-github.com-spf13-cobra-v1.9.1", doc.Packages[1].SPDXID)
+	assert.Equal(t, "Replaces golang.org/x/crypto@v0.39.0", doc.Packages[2].Comment)
+
+	assert.Equal(t, SPDXRelationship{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: main.SPDXID}, doc.Relationships[0])
+	assert.Len(t, doc.Relationships, 3)
+}
+
+func TestSBOMSubject_DevelopmentBuild(t *testing.T) {
+	subject := SBOMSubject{Version: VersionInfo{Version: "dev"}, Build: BuildProvenance{Main: Module{Path: "github.com/kodflow/superviz.io", Version: "(devel)"}}}
+
+	bom := NewCycloneDXBOM(subject, "id", sbomCreated)
+	assert.Equal(t, "pkg:golang/github.com/kodflow/superviz.io", bom.Metadata.Component.PURL)
+	assert.Empty(t, bom.Metadata.Component.Hashes)
+
+	subject.Build.Main.Version = "v0.0.0-20250601120000-1a2b3c4d5e6f"
+	assert.Equal(t, "v0.0.0-20250601120000-1a2b3c4d5e6f", subject.version(), "go install builds carry a pseudo-version")
+}
@@ -0,0 +1,272 @@
+// internal/providers/semver.go - Semantic versions and the agent compatibility window
+package providers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AgentMinorSkew is how many minor versions an agent may lag or lead the CLI.
+const AgentMinorSkew = 1
+
+// SemVer is a parsed semantic version (https://semver.org).
+type SemVer struct {
+	// Major is incremented for incompatible changes
+	Major uint64
+	// Minor is incremented for backward compatible features
+	Minor uint64
+	// Patch is incremented for backward compatible fixes
+	Patch uint64
+	// Prerelease holds the dot-separated identifiers after "-", empty for releases
+	Prerelease []string
+	// Build holds the metadata after "+", ignored for precedence
+	Build string
+}
+
+// ParseSemVer parses a semantic version, with or without a leading "v".
+//
+// Example:
+//
+//	v, _ := ParseSemVer("v1.5.0-rc.1+build.7")
+//	fmt.Println(v.Minor, v.IsPrerelease()) // 5 true
+//
+// Parameters:
+//   - s: string version such as 1.2.3, v1.2.3 or 1.3.0-beta.2
+//
+// Returns:
+//   - version: SemVer parsed version
+//   - err: error if s is not a valid semantic version
+func ParseSemVer(s string) (SemVer, error) {
+	var v SemVer
+	rest := strings.TrimPrefix(s, "v")
+
+	if i := strings.IndexByte(rest, '+'); i >= 0 {
+		v.Build = rest[i+1:]
+		rest = rest[:i]
+		if err := validIdentifiers(v.Build, false); err != nil {
+			return SemVer{}, fmt.Errorf("invalid version %q: build metadata %w", s, err)
+		}
+	}
+	if i := strings.IndexByte(rest, '-'); i >= 0 {
+		pre := rest[i+1:]
+		rest = rest[:i]
+		if err := validIdentifiers(pre, true); err != nil {
+			return SemVer{}, fmt.Errorf("invalid version %q: prerelease %w", s, err)
+		}
+		v.Prerelease = strings.Split(pre, ".")
+	}
+
+	parts := strings.Split(rest, ".")
+	if len(parts) != 3 {
+		return SemVer{}, fmt.Errorf("invalid version %q: expected MAJOR.MINOR.PATCH", s)
+	}
+	numbers := [3]*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := parseNumericIdentifier(part)
+		if err != nil {
+			return SemVer{}, fmt.Errorf("invalid version %q: %w", s, err)
+		}
+		*numbers[i] = n
+	}
+	return v, nil
+}
+
+// ParsePackageVersion parses the version reported by a package manager.
+//
+// ParsePackageVersion drops the epoch ("1:") and the distribution revision
+// ("-1", "-r0", "-1.el9") and maps the Debian prerelease marker "~" to "-",
+// so 1:1.5.0~rc.1-1 reads as 1.5.0-rc.1.
+//
+// Parameters:
+//   - s: string package version
+//
+// Returns:
+//   - version: SemVer upstream version
+//   - err: error if the upstream version is not a valid semantic version
+func ParsePackageVersion(s string) (SemVer, error) {
+	upstream := s
+	if i := strings.IndexByte(upstream, ':'); i >= 0 {
+		upstream = upstream[i+1:]
+	}
+	if i := strings.LastIndexByte(upstream, '-'); i >= 0 && isPackageRevision(upstream[i+1:]) {
+		upstream = upstream[:i]
+	}
+	return ParseSemVer(strings.ReplaceAll(upstream, "~", "-"))
+}
+
+// isPackageRevision reports whether s looks like a distribution revision (1, 1.el9, r0)
+func isPackageRevision(s string) bool {
+	s = strings.TrimPrefix(s, "r")
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
+
+// validIdentifiers checks dot-separated prerelease or build identifiers
+func validIdentifiers(s string, prerelease bool) error {
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return fmt.Errorf("has an empty identifier")
+		}
+		numeric := true
+		for _, r := range id {
+			switch {
+			case r >= '0' && r <= '9':
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '-':
+				numeric = false
+			default:
+				return fmt.Errorf("identifier %q has invalid character %q", id, r)
+			}
+		}
+		if prerelease && numeric && len(id) > 1 && id[0] == '0' {
+			return fmt.Errorf("identifier %q has a leading zero", id)
+		}
+	}
+	return nil
+}
+
+// parseNumericIdentifier parses a version number without leading zeros
+func parseNumericIdentifier(s string) (uint64, error) {
+	if len(s) > 1 && s[0] == '0' {
+		return 0, fmt.Errorf("number %q has a leading zero", s)
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	return n, nil
+}
+
+// String returns the canonical form without a leading "v".
+//
+// Returns:
+//   - version: string such as 1.5.0-rc.1+build.7
+func (v SemVer) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// IsPrerelease reports whether v has prerelease identifiers.
+//
+// Returns:
+//   - prerelease: bool true for versions such as 1.5.0-rc.1
+func (v SemVer) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+// Compare orders two versions by semantic version precedence.
+//
+// Build metadata is ignored and a prerelease sorts before its release,
+// so 1.5.0-beta.2 < 1.5.0-rc.1 < 1.5.0 < 1.5.1.
+//
+// Parameters:
+//   - other: SemVer version to compare against
+//
+// Returns:
+//   - order: int -1 if v < other, 0 if equal, +1 if v > other
+func (v SemVer) Compare(other SemVer) int {
+	for _, pair := range [3][2]uint64{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if c := compareUint(pair[0], pair[1]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case len(v.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		if c := compareIdentifier(v.Prerelease[i], other.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(v.Prerelease)), uint64(len(other.Prerelease)))
+}
+
+// compareIdentifier orders prerelease identifiers: numeric ones numerically and before alphanumeric ones
+func compareIdentifier(a, b string) int {
+	na, errA := strconv.ParseUint(a, 10, 64)
+	nb, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		return compareUint(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// compareUint returns -1, 0 or +1
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// CompatibilityWindow is the range of agent versions a CLI release supports.
+type CompatibilityWindow struct {
+	// Min is the oldest supported agent version, inclusive
+	Min SemVer
+	// Max is the first unsupported newer agent version, exclusive
+	Max SemVer
+}
+
+// CompatibilityWindowFor returns the agent versions supported by a CLI version.
+//
+// Agents must share the CLI's major version and be at most AgentMinorSkew
+// minor versions older or newer, prereleases included: CLI 1.3.x manages
+// agents from 1.2.0-0 up to, but excluding, 1.5.0-0.
+//
+// Parameters:
+//   - cli: SemVer version of the CLI
+//
+// Returns:
+//   - window: CompatibilityWindow supported agent versions
+func CompatibilityWindowFor(cli SemVer) CompatibilityWindow {
+	lowest := []string{"0"}
+	minMinor := uint64(0)
+	if cli.Minor > AgentMinorSkew {
+		minMinor = cli.Minor - AgentMinorSkew
+	}
+	return CompatibilityWindow{
+		Min: SemVer{Major: cli.Major, Minor: minMinor, Prerelease: lowest},
+		Max: SemVer{Major: cli.Major, Minor: cli.Minor + AgentMinorSkew + 1, Prerelease: lowest},
+	}
+}
+
+// Contains reports whether an agent version falls inside the window.
+//
+// Parameters:
+//   - v: SemVer agent version
+//
+// Returns:
+//   - ok: bool true if Min <= v < Max
+func (w CompatibilityWindow) Contains(v SemVer) bool {
+	return v.Compare(w.Min) >= 0 && v.Compare(w.Max) < 0
+}
+
+// String returns the window as a minor version range.
+//
+// Returns:
+//   - window: string such as "1.2.x to 1.4.x"
+func (w CompatibilityWindow) String() string {
+	return fmt.Sprintf("%d.%d.x to %d.%d.x", w.Min.Major, w.Min.Minor, w.Max.Major, w.Max.Minor-1)
+}
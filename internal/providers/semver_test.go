@@ -0,0 +1,108 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSemVer(t *testing.T) {
+	v, err := ParseSemVer("v1.5.0-rc.1+build.7")
+	require.NoError(t, err)
+	assert.Equal(t, SemVer{Major: 1, Minor: 5, Patch: 0, Prerelease: []string{"rc", "1"}, Build: "build.7"}, v)
+	assert.Equal(t, "1.5.0-rc.1+build.7", v.String())
+	assert.True(t, v.IsPrerelease())
+
+	v, err = ParseSemVer("10.20.30")
+	require.NoError(t, err)
+	assert.Equal(t, "10.20.30", v.String())
+	assert.False(t, v.IsPrerelease())
+}
+
+func TestParseSemVer_Invalid(t *testing.T) {
+	for _, s := range []string{
+		"", "dev", "latest", "1", "1.2", "1.2.3.4", "01.2.3", "1.02.3", "1.2.x",
+		"1.2.3-", "1.2.3-rc..1", "1.2.3-01", "1.2.3-rc_1", "1.2.3+", "1.2.3+a..b",
+	} {
+		_, err := ParseSemVer(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestSemVer_Compare(t *testing.T) {
+	// Ordered by precedence, see https://semver.org/#spec-item-11
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "1.10.0", "2.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, err := ParseSemVer(ordered[i])
+			require.NoError(t, err)
+			b, err := ParseSemVer(ordered[j])
+			require.NoError(t, err)
+
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			assert.Equal(t, want, a.Compare(b), "%s vs %s", ordered[i], ordered[j])
+		}
+	}
+
+	a, _ := ParseSemVer("1.0.0+linux")  //nolint:errcheck
+	b, _ := ParseSemVer("1.0.0+darwin") //nolint:errcheck
+	assert.Equal(t, 0, a.Compare(b), "build metadata is ignored")
+}
+
+func TestParsePackageVersion(t *testing.T) {
+	tests := map[string]string{
+		"1.3.0":         "1.3.0",
+		"1.3.0-1":       "1.3.0",
+		"1:1.3.0-1":     "1.3.0",
+		"1.3.0-r0":      "1.3.0",
+		"1.3.0-1.el9":   "1.3.0",
+		"1.5.0~rc.1-1":  "1.5.0-rc.1",
+		"1.5.0-rc.1":    "1.5.0-rc.1",
+		"1.5.0-beta.2":  "1.5.0-beta.2",
+		"v1.5.0~beta.2": "1.5.0-beta.2",
+	}
+	for in, want := range tests {
+		v, err := ParsePackageVersion(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, v.String(), in)
+	}
+
+	_, err := ParsePackageVersion("latest")
+	assert.Error(t, err)
+}
+
+func TestCompatibilityWindowFor(t *testing.T) {
+	cli, err := ParseSemVer("1.3.2")
+	require.NoError(t, err)
+	window := CompatibilityWindowFor(cli)
+	assert.Equal(t, "1.2.x to 1.4.x", window.String())
+
+	for version, want := range map[string]bool{
+		"1.1.9":      false,
+		"1.2.0-rc.1": true,
+		"1.2.0":      true,
+		"1.3.0":      true,
+		"1.4.7":      true,
+		"1.5.0-rc.1": false,
+		"1.5.0":      false,
+		"2.3.0":      false,
+		"0.3.0":      false,
+	} {
+		v, err := ParseSemVer(version)
+		require.NoError(t, err)
+		assert.Equal(t, want, window.Contains(v), version)
+	}
+
+	first, err := ParseSemVer("0.1.0")
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.x to 0.2.x", CompatibilityWindowFor(first).String())
+}
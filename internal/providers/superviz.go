@@ -0,0 +1,718 @@
+// internal/providers/superviz.go - superviz.yaml service definitions
+package providers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supervisor configuration defaults.
+const (
+	// DefaultSupervizConfigPath is the configuration svz run reads when -c is not given
+	DefaultSupervizConfigPath = "superviz.yaml"
+	// SupervizConfigVersion is the only supported schema version
+	SupervizConfigVersion = 1
+	// DefaultStopTimeout is how long a service may take to exit after its stop signal
+	DefaultStopTimeout = 10 * time.Second
+	// DefaultStopSignal is sent to a service to request a graceful stop
+	DefaultStopSignal = "TERM"
+	// DefaultBackoff is the delay before the first restart
+	DefaultBackoff = time.Second
+	// DefaultMaxBackoff caps the delay between restarts
+	DefaultMaxBackoff = 30 * time.Second
+	// DefaultBackoffMultiplier grows the delay after each consecutive restart
+	DefaultBackoffMultiplier = 2.0
+	// DefaultBackoffJitter randomizes each delay by up to ±10%
+	DefaultBackoffJitter = 0.1
+	// DefaultResetAfter is how long a service must run for its restart count to reset
+	DefaultResetAfter = time.Minute
+	// DefaultCrashLoopWindow is the crash-loop window when only failures is set
+	DefaultCrashLoopWindow = time.Minute
+	// DefaultHookTimeout bounds the on_fatal hook
+	DefaultHookTimeout = 30 * time.Second
+)
+
+// RestartMode decides whether a service is started again after it exits.
+type RestartMode string
+
+// Supported restart modes.
+const (
+	// RestartAlways restarts the service whatever its exit status
+	RestartAlways RestartMode = "always"
+	// RestartOnFailure restarts the service when it exits with a non-zero status
+	RestartOnFailure RestartMode = "on-failure"
+	// RestartNever leaves the service exited
+	RestartNever RestartMode = "never"
+	// RestartUnlessStopped restarts the service like always, unless an operator stopped it
+	RestartUnlessStopped RestartMode = "unless-stopped"
+)
+
+// CrashLoopPolicy marks a service fatal when it fails too often.
+type CrashLoopPolicy struct {
+	// Failures is the number of failed exits that makes the service fatal, 0 disables detection
+	Failures int `json:"failures,omitempty" yaml:"failures,omitempty"`
+	// Window is the sliding period the failures are counted over, default 1m
+	Window time.Duration `json:"window,omitempty" yaml:"window,omitempty"`
+}
+
+// RestartPolicy tunes how a service is restarted.
+//
+// The delay before the n-th consecutive restart is backoff * multiplier^(n-1),
+// capped at max_backoff and randomized by ±jitter. A run lasting reset_after
+// resets the count.
+type RestartPolicy struct {
+	// MaxRetries is the number of consecutive restarts before the service is fatal, 0 for unlimited
+	MaxRetries int `json:"max_retries,omitempty" yaml:"max_retries,omitempty"`
+	// Backoff is the delay before the first restart, default 1s
+	Backoff time.Duration `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+	// MaxBackoff caps the delay, default 30s
+	MaxBackoff time.Duration `json:"max_backoff,omitempty" yaml:"max_backoff,omitempty"`
+	// Multiplier grows the delay after each consecutive restart, default 2
+	Multiplier float64 `json:"multiplier,omitempty" yaml:"multiplier,omitempty"`
+	// Jitter is the random fraction added to or removed from each delay, default 0.1, 0 disables it
+	Jitter *float64 `json:"jitter,omitempty" yaml:"jitter,omitempty"`
+	// ResetAfter is how long a run must last to reset the consecutive restarts, default 1m
+	ResetAfter time.Duration `json:"reset_after,omitempty" yaml:"reset_after,omitempty"`
+	// CrashLoop marks the service fatal after repeated failures
+	CrashLoop CrashLoopPolicy `json:"crash_loop,omitempty" yaml:"crash_loop,omitempty"`
+}
+
+// FatalAction is what the supervisor does when a service becomes fatal.
+type FatalAction string
+
+// Supported fatal actions.
+const (
+	// FatalContinue keeps the other services running
+	FatalContinue FatalAction = "continue"
+	// FatalExit stops every service and exits with an error, so an orchestrator sees the failure
+	FatalExit FatalAction = "exit"
+	// FatalHook runs a command and keeps the other services running
+	FatalHook FatalAction = "hook"
+)
+
+// OnFatalConfig is the action applied when a service becomes fatal.
+//
+// The hook receives the service name in SVZ_SERVICE and the reason in SVZ_REASON.
+type OnFatalConfig struct {
+	// Action is continue (default), exit or hook
+	Action FatalAction `yaml:"action,omitempty"`
+	// Command is the hook executable, required by the hook action
+	Command string `yaml:"command,omitempty"`
+	// Args are passed to the hook
+	Args []string `yaml:"args,omitempty"`
+	// Timeout bounds the hook, default 30s
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// DependencyCondition is what a service waits for before it starts.
+type DependencyCondition string
+
+// Supported dependency conditions.
+const (
+	// ConditionStarted waits until the dependency is running
+	ConditionStarted DependencyCondition = "started"
+	// ConditionHealthy waits until the dependency reports healthy
+	ConditionHealthy DependencyCondition = "healthy"
+	// ConditionCompletedSuccessfully waits until the dependency exits with status 0
+	ConditionCompletedSuccessfully DependencyCondition = "completed_successfully"
+)
+
+// Dependency is one entry of depends_on.
+type Dependency struct {
+	// Service is the name of the service depended on
+	Service string `json:"service" yaml:"service"`
+	// Condition is what is waited for, default started
+	Condition DependencyCondition `json:"condition" yaml:"condition"`
+}
+
+// Dependencies is the depends_on list of a service.
+//
+// It is written either as a list of names, which wait for the services to
+// be started, or as a mapping from name to condition:
+//
+//	depends_on: [db, cache]
+//
+//	depends_on:
+//	  migrate:
+//	    condition: completed_successfully
+//	  db:
+//	    condition: healthy
+type Dependencies []Dependency
+
+// UnmarshalYAML decodes the list and mapping forms of depends_on.
+//
+// Parameters:
+//   - node: *yaml.Node depends_on value
+//
+// Returns:
+//   - err: error if the value is neither a list of names nor a mapping of conditions
+func (d *Dependencies) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var names []string
+		if err := node.Decode(&names); err != nil {
+			return err
+		}
+		deps := make(Dependencies, 0, len(names))
+		for _, name := range names {
+			deps = append(deps, Dependency{Service: name})
+		}
+		*d = deps
+		return nil
+	case yaml.MappingNode:
+		deps := make(Dependencies, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			dep := Dependency{Service: node.Content[i].Value}
+			options := node.Content[i+1]
+			if options.Kind != yaml.MappingNode && options.Tag != "!!null" {
+				return fmt.Errorf("line %d: depends_on.%s must be a mapping", options.Line, dep.Service)
+			}
+			for j := 0; j+1 < len(options.Content); j += 2 {
+				key := options.Content[j]
+				if key.Value != "condition" {
+					return fmt.Errorf("line %d: field %s not found in depends_on.%s", key.Line, key.Value, dep.Service)
+				}
+				dep.Condition = DependencyCondition(options.Content[j+1].Value)
+			}
+			deps = append(deps, dep)
+		}
+		*d = deps
+		return nil
+	default:
+		return fmt.Errorf("line %d: depends_on must be a list of services or a mapping of conditions", node.Line)
+	}
+}
+
+// Names returns the names of the services depended on.
+//
+// Returns:
+//   - names: []string service names in declaration order
+func (d Dependencies) Names() []string {
+	names := make([]string, 0, len(d))
+	for _, dep := range d {
+		names = append(names, dep.Service)
+	}
+	return names
+}
+
+// stopSignals lists the signal names accepted by stop_signal
+var stopSignals = []string{"TERM", "INT", "QUIT", "HUP", "KILL", "USR1", "USR2"}
+
+// ServiceConfig declares one supervised process.
+type ServiceConfig struct {
+	// Name is the key of the service in the services mapping
+	Name string `yaml:"-"`
+	// Command is the executable, looked up in PATH when it has no slash
+	Command string `yaml:"command"`
+	// Args are passed to the command
+	Args []string `yaml:"args,omitempty"`
+	// Env is added to the environment inherited from svz, checked and interpolated at each start
+	Env EnvVars `yaml:"env,omitempty"`
+	// WorkingDir is the process working directory, relative to the configuration file
+	WorkingDir string `yaml:"working_dir,omitempty"`
+	// User runs the process as this user name or uid (Unix only)
+	User string `yaml:"user,omitempty"`
+	// Group runs the process with this group name or gid, default the user's primary group (Unix only)
+	Group string `yaml:"group,omitempty"`
+	// Restart is the restart mode, default on-failure
+	Restart RestartMode `yaml:"restart,omitempty"`
+	// RestartPolicy tunes retries, backoff and crash-loop detection
+	RestartPolicy RestartPolicy `yaml:"restart_policy,omitempty"`
+	// DependsOn lists the services started before this one and stopped after it
+	DependsOn Dependencies `yaml:"depends_on,omitempty"`
+	// Probes check the startup, liveness and readiness of the service
+	Probes Probes `yaml:"probes,omitempty"`
+	// StopSignal requests a graceful stop, default TERM
+	StopSignal string `yaml:"stop_signal,omitempty"`
+	// StopTimeout is the delay before the process is killed, default 10s
+	StopTimeout time.Duration `yaml:"stop_timeout,omitempty"`
+	// Logs overrides the top-level logs settings for this service
+	Logs LogConfig `yaml:"logs,omitempty"`
+}
+
+// SupervizConfig is the content of a superviz.yaml file.
+//
+// Example:
+//
+//	version: 1
+//	services:
+//	  db:
+//	    command: /usr/bin/postgres
+//	    args: ["-D", "/var/lib/postgres"]
+//	    user: postgres
+//	  web:
+//	    command: ./bin/web
+//	    env:
+//	      PORT: "8080"
+//	    working_dir: /srv/web
+//	    restart: always
+//	    restart_policy:
+//	      max_retries: 10
+//	      crash_loop: {failures: 5, window: 1m}
+//	    probes:
+//	      readiness:
+//	        http: {url: "http://127.0.0.1:8080/healthz"}
+//	    depends_on:
+//	      db:
+//	        condition: started
+//	on_fatal:
+//	  action: exit
+//	control:
+//	  socket_mode: 0660
+//	logs:
+//	  dir: logs
+//	  max_size: 50MiB
+//	log_forward:
+//	  syslog: {network: udp, address: "logs:514"}
+//	metrics:
+//	  listen: 127.0.0.1:9090
+//	integrity:
+//	  manifest: {path: manifest.yaml}
+//	artifacts:
+//	  vault:
+//	    version: 1.15.4
+//	    url: https://releases.hashicorp.com/vault/{{version}}/vault_{{version}}_{{os}}_{{arch}}.zip
+//	    path: bin/vault
+//	    sha256: {linux/amd64: f42f550...}
+//	reconcile:
+//	  interval: 1m
+type SupervizConfig struct {
+	// Version is the schema version, 1
+	Version int `yaml:"version"`
+	// OnFatal is applied when a service becomes fatal
+	OnFatal OnFatalConfig `yaml:"on_fatal,omitempty"`
+	// Control enables the control API, disabled when absent
+	Control *ControlConfig `yaml:"control,omitempty"`
+	// Logs configures the output capture of every service
+	Logs LogConfig `yaml:"logs,omitempty"`
+	// LogForward sends the service lines to syslog or OTLP, disabled when absent
+	LogForward *LogForwardConfig `yaml:"log_forward,omitempty"`
+	// Metrics exports the service metrics, disabled when absent
+	Metrics *MetricsConfig `yaml:"metrics,omitempty"`
+	// Integrity checks critical files before the services start, disabled when absent
+	Integrity *IntegrityConfig `yaml:"integrity,omitempty"`
+	// Artifacts are downloaded and installed before the services depending on them start
+	Artifacts Artifacts `yaml:"artifacts,omitempty"`
+	// Reconcile corrects the divergences from the declared state periodically, disabled when absent
+	Reconcile *ReconcileConfig `yaml:"reconcile,omitempty"`
+	// Services maps a service name to its definition
+	Services map[string]*ServiceConfig `yaml:"services"`
+	// Dir is the directory of the configuration file, relative paths are resolved against it
+	Dir string `yaml:"-"`
+}
+
+// LoadSupervizConfig reads and validates a superviz.yaml file.
+//
+// Parameters:
+//   - path: string configuration file location
+//
+// Returns:
+//   - config: *SupervizConfig validated configuration with defaults applied
+//   - err: error if the file cannot be read or is invalid
+func LoadSupervizConfig(path string) (*SupervizConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration: %w", err)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve configuration directory: %w", err)
+	}
+
+	config, err := ParseSupervizConfig(data, dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// ParseSupervizConfig parses and validates superviz.yaml content.
+//
+// Parameters:
+//   - data: []byte YAML configuration
+//   - dir: string directory relative working directories are resolved against
+//
+// Returns:
+//   - config: *SupervizConfig validated configuration with defaults applied
+//   - err: error if the YAML is invalid or a service definition is inconsistent
+func ParseSupervizConfig(data []byte, dir string) (*SupervizConfig, error) {
+	var config SupervizConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+	config.Dir = dir
+
+	if err := config.applyDefaults(); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// applyDefaults fills names, defaults and absolute working directories
+func (c *SupervizConfig) applyDefaults() error {
+	if c.Version == 0 {
+		c.Version = SupervizConfigVersion
+	}
+	if c.OnFatal.Action == "" {
+		c.OnFatal.Action = FatalContinue
+	}
+	if c.OnFatal.Timeout == 0 {
+		c.OnFatal.Timeout = DefaultHookTimeout
+	}
+	if c.Control != nil {
+		c.Control.applyDefaults(c.Dir)
+	}
+	c.Logs.applyDefaults(c.Dir)
+	if c.LogForward != nil {
+		c.LogForward.applyDefaults()
+	}
+	if c.Metrics != nil {
+		c.Metrics.applyDefaults()
+	}
+	if c.Integrity != nil {
+		c.Integrity.applyDefaults(c.Dir)
+	}
+	if err := c.Artifacts.applyDefaults(c.Dir); err != nil {
+		return err
+	}
+	if c.Reconcile != nil {
+		c.Reconcile.applyDefaults()
+	}
+	for name, service := range c.Services {
+		if service == nil {
+			return fmt.Errorf("service %s has no definition", name)
+		}
+		service.Name = name
+		if service.Restart == "" {
+			service.Restart = RestartOnFailure
+		}
+		if service.StopSignal == "" {
+			service.StopSignal = DefaultStopSignal
+		}
+		service.StopSignal = strings.TrimPrefix(strings.ToUpper(service.StopSignal), "SIG")
+		for i := range service.DependsOn {
+			if service.DependsOn[i].Condition == "" {
+				service.DependsOn[i].Condition = ConditionStarted
+			}
+		}
+		if service.StopTimeout == 0 {
+			service.StopTimeout = DefaultStopTimeout
+		}
+		for key, v := range service.Env {
+			if v == nil {
+				service.Env[key] = &EnvVar{}
+			}
+		}
+		service.RestartPolicy.applyDefaults()
+		service.Probes.applyDefaults()
+		service.Logs.inherit(&c.Logs)
+		service.Logs.applyDefaults(c.Dir)
+		if service.WorkingDir == "" {
+			service.WorkingDir = c.Dir
+		} else if !filepath.IsAbs(service.WorkingDir) && c.Dir != "" {
+			service.WorkingDir = filepath.Join(c.Dir, service.WorkingDir)
+		}
+	}
+	return nil
+}
+
+// applyDefaults fills the unset restart policy fields
+func (p *RestartPolicy) applyDefaults() {
+	if p.Backoff == 0 {
+		p.Backoff = DefaultBackoff
+	}
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = DefaultMaxBackoff
+	}
+	if p.Multiplier == 0 {
+		p.Multiplier = DefaultBackoffMultiplier
+	}
+	if p.Jitter == nil {
+		jitter := DefaultBackoffJitter
+		p.Jitter = &jitter
+	}
+	if p.ResetAfter == 0 {
+		p.ResetAfter = DefaultResetAfter
+	}
+	if p.CrashLoop.Failures > 0 && p.CrashLoop.Window == 0 {
+		p.CrashLoop.Window = DefaultCrashLoopWindow
+	}
+}
+
+// Validate checks the configuration for unsupported values and broken dependencies.
+//
+// Returns:
+//   - err: error describing the first problem found
+func (c *SupervizConfig) Validate() error {
+	if c.Version != SupervizConfigVersion {
+		return fmt.Errorf("unsupported configuration version %d, expected %d", c.Version, SupervizConfigVersion)
+	}
+	if len(c.Services) == 0 {
+		return errors.New("configuration declares no services")
+	}
+	if err := c.OnFatal.validate(); err != nil {
+		return fmt.Errorf("on_fatal: %w", err)
+	}
+	if c.Control != nil {
+		if err := c.Control.validate(); err != nil {
+			return fmt.Errorf("control: %w", err)
+		}
+	}
+	if err := c.Logs.validate(); err != nil {
+		return fmt.Errorf("logs: %w", err)
+	}
+	if c.LogForward != nil {
+		if err := c.LogForward.validate(); err != nil {
+			return fmt.Errorf("log_forward: %w", err)
+		}
+	}
+	if c.Metrics != nil {
+		if err := c.Metrics.validate(); err != nil {
+			return fmt.Errorf("metrics: %w", err)
+		}
+	}
+	if c.Integrity != nil {
+		if err := c.Integrity.validate(c); err != nil {
+			return fmt.Errorf("integrity: %w", err)
+		}
+	}
+	if err := c.Artifacts.validate(c); err != nil {
+		return fmt.Errorf("artifacts: %w", err)
+	}
+	if c.Reconcile != nil {
+		if err := c.Reconcile.validate(); err != nil {
+			return fmt.Errorf("reconcile: %w", err)
+		}
+	}
+
+	for _, name := range c.Names() {
+		if err := c.Services[name].validate(c); err != nil {
+			return fmt.Errorf("service %s: %w", name, err)
+		}
+	}
+
+	_, err := c.StartOrder()
+	return err
+}
+
+// validate checks one service definition against the whole configuration
+func (s *ServiceConfig) validate(c *SupervizConfig) error {
+	if !validServiceName(s.Name) {
+		return errors.New("name must only contain letters, digits, '.', '_' and '-'")
+	}
+	if s.Command == "" {
+		return errors.New("command is required")
+	}
+
+	switch s.Restart {
+	case RestartAlways, RestartOnFailure, RestartNever, RestartUnlessStopped:
+	default:
+		return fmt.Errorf("invalid restart mode %q: must be %s, %s, %s or %s",
+			s.Restart, RestartAlways, RestartOnFailure, RestartNever, RestartUnlessStopped)
+	}
+	if err := s.RestartPolicy.validate(); err != nil {
+		return fmt.Errorf("restart_policy: %w", err)
+	}
+	if err := s.Probes.validate(); err != nil {
+		return fmt.Errorf("probes: %w", err)
+	}
+	if err := s.Logs.validate(); err != nil {
+		return fmt.Errorf("logs: %w", err)
+	}
+
+	if !containsString(stopSignals, s.StopSignal) {
+		return fmt.Errorf("invalid stop_signal %q: must be one of %s", s.StopSignal, strings.Join(stopSignals, ", "))
+	}
+	if s.StopTimeout < 0 {
+		return errors.New("stop_timeout cannot be negative")
+	}
+
+	if err := s.Env.validate(); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(s.DependsOn))
+	for _, dep := range s.DependsOn {
+		switch {
+		case dep.Service == s.Name:
+			return errors.New("cannot depend on itself")
+		case c.Services[dep.Service] == nil:
+			return fmt.Errorf("depends on undefined service %s", dep.Service)
+		case seen[dep.Service]:
+			return fmt.Errorf("lists dependency %s twice", dep.Service)
+		}
+		switch dep.Condition {
+		case ConditionStarted, ConditionHealthy, ConditionCompletedSuccessfully:
+		default:
+			return fmt.Errorf("invalid condition %q for dependency %s: must be %s, %s or %s",
+				dep.Condition, dep.Service, ConditionStarted, ConditionHealthy, ConditionCompletedSuccessfully)
+		}
+		seen[dep.Service] = true
+	}
+	return nil
+}
+
+// validate checks the restart policy bounds
+func (p *RestartPolicy) validate() error {
+	switch {
+	case p.MaxRetries < 0:
+		return errors.New("max_retries cannot be negative")
+	case p.Backoff < 0:
+		return errors.New("backoff cannot be negative")
+	case p.MaxBackoff < p.Backoff:
+		return errors.New("max_backoff cannot be lower than backoff")
+	case p.Multiplier < 1:
+		return errors.New("multiplier must be at least 1")
+	case p.Jitter != nil && (*p.Jitter < 0 || *p.Jitter > 1):
+		return errors.New("jitter must be between 0 and 1")
+	case p.ResetAfter < 0:
+		return errors.New("reset_after cannot be negative")
+	case p.CrashLoop.Failures < 0:
+		return errors.New("crash_loop.failures cannot be negative")
+	case p.CrashLoop.Window < 0:
+		return errors.New("crash_loop.window cannot be negative")
+	}
+	return nil
+}
+
+// validate checks the fatal action and its hook
+func (o *OnFatalConfig) validate() error {
+	switch o.Action {
+	case FatalContinue, FatalExit:
+		if o.Command != "" {
+			return fmt.Errorf("command requires action %s", FatalHook)
+		}
+	case FatalHook:
+		if o.Command == "" {
+			return errors.New("command is required by the hook action")
+		}
+	default:
+		return fmt.Errorf("invalid action %q: must be %s, %s or %s", o.Action, FatalContinue, FatalExit, FatalHook)
+	}
+	if o.Timeout < 0 {
+		return errors.New("timeout cannot be negative")
+	}
+	return nil
+}
+
+// validServiceName reports whether name is usable in paths, logs and the control API
+func validServiceName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Names returns the service names in alphabetical order.
+//
+// Returns:
+//   - names: []string sorted service names
+func (c *SupervizConfig) Names() []string {
+	names := make([]string, 0, len(c.Services))
+	for name := range c.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StartOrder returns the services ordered so that dependencies come first.
+//
+// Services without an ordering constraint between them are sorted by name,
+// so the order is stable across runs.
+//
+// Returns:
+//   - order: []string service names, dependencies before dependents
+//   - err: error naming the services caught in or behind a dependency cycle
+func (c *SupervizConfig) StartOrder() ([]string, error) {
+	pending := make(map[string]int, len(c.Services))
+	dependents := make(map[string][]string, len(c.Services))
+	for _, name := range c.Names() {
+		service := c.Services[name]
+		pending[name] = len(service.DependsOn)
+		for _, dep := range service.DependsOn {
+			dependents[dep.Service] = append(dependents[dep.Service], name)
+		}
+	}
+
+	var ready, order []string
+	for _, name := range c.Names() {
+		if pending[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+	for len(ready) > 0 {
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+		for _, dependent := range dependents[name] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+				sort.Strings(ready)
+			}
+		}
+	}
+
+	if len(order) < len(c.Services) {
+		var cycle []string
+		for _, name := range c.Names() {
+			if pending[name] > 0 {
+				cycle = append(cycle, name)
+			}
+		}
+		return nil, fmt.Errorf("dependency cycle involving services %s", strings.Join(cycle, ", "))
+	}
+	return order, nil
+}
+
+// Dependents returns the services that depend on a service.
+//
+// Parameters:
+//   - name: string service name
+//
+// Returns:
+//   - dependents: []string sorted names of the services listing name in depends_on
+func (c *SupervizConfig) Dependents(name string) []string {
+	var dependents []string
+	for _, other := range c.Names() {
+		for _, dep := range c.Services[other].DependsOn {
+			if dep.Service == name {
+				dependents = append(dependents, other)
+				break
+			}
+		}
+	}
+	return dependents
+}
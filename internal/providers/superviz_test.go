@@ -0,0 +1,200 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSupervizYAML = `
+version: 1
+services:
+  db:
+    command: /usr/bin/postgres
+    args: ["-D", "/var/lib/postgres"]
+    user: postgres
+    stop_signal: SIGINT
+  web:
+    command: ./bin/web
+    env:
+      PORT: "8080"
+    working_dir: web
+    restart: always
+    depends_on: [db, cache]
+    stop_timeout: 30s
+  cache:
+    command: redis-server
+    restart: never
+`
+
+func TestParseSupervizConfig(t *testing.T) {
+	config, err := ParseSupervizConfig([]byte(testSupervizYAML), "/srv")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, config.Version)
+	assert.Equal(t, []string{"cache", "db", "web"}, config.Names())
+
+	db := config.Services["db"]
+	assert.Equal(t, "db", db.Name)
+	assert.Equal(t, []string{"-D", "/var/lib/postgres"}, db.Args)
+	assert.Equal(t, "postgres", db.User)
+	assert.Equal(t, RestartOnFailure, db.Restart, "default restart mode")
+	assert.Equal(t, "INT", db.StopSignal, "SIG prefix is dropped")
+	assert.Equal(t, DefaultStopTimeout, db.StopTimeout)
+	assert.Equal(t, "/srv", db.WorkingDir, "defaults to the configuration directory")
+
+	web := config.Services["web"]
+	assert.Equal(t, EnvVars{"PORT": {Value: "8080"}}, web.Env)
+	assert.Equal(t, filepath.Join("/srv", "web"), web.WorkingDir)
+	assert.Equal(t, RestartAlways, web.Restart)
+	assert.Equal(t, 30*time.Second, web.StopTimeout)
+	assert.Equal(t, "TERM", web.StopSignal)
+	assert.Equal(t, Dependencies{{Service: "db", Condition: ConditionStarted}, {Service: "cache", Condition: ConditionStarted}}, web.DependsOn)
+	assert.Equal(t, []string{"db", "cache"}, web.DependsOn.Names())
+
+	order, err := config.StartOrder()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cache", "db", "web"}, order)
+}
+
+func TestParseSupervizConfig_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unsupported configuration version 2":         "version: 2\nservices:\n  a: {command: x}\n",
+		"declares no services":                        "version: 1\n",
+		"service a has no definition":                 "services:\n  a:\n",
+		"command is required":                         "services:\n  a: {args: [x]}\n",
+		"name must only contain":                      "services:\n  a/b: {command: x}\n",
+		`invalid restart mode "sometimes"`:            "services:\n  a: {command: x, restart: sometimes}\n",
+		`invalid stop_signal "STOP"`:                  "services:\n  a: {command: x, stop_signal: STOP}\n",
+		"stop_timeout cannot be negative":             "services:\n  a: {command: x, stop_timeout: -1s}\n",
+		"depends on undefined service b":              "services:\n  a: {command: x, depends_on: [b]}\n",
+		"cannot depend on itself":                     "services:\n  a: {command: x, depends_on: [a]}\n",
+		"lists dependency b twice":                    "services:\n  a: {command: x, depends_on: [b, b]}\n  b: {command: x}\n",
+		`invalid environment variable name "A=B"`:     "services:\n  a: {command: x, env: {\"A=B\": x}}\n",
+		"field comand not found":                      "services:\n  a: {comand: x}\n",
+		"dependency cycle involving services a, b, c": "services:\n  a: {command: x, depends_on: [b]}\n  b: {command: x, depends_on: [a]}\n  c: {command: x, depends_on: [a]}\n",
+		`invalid condition "ready" for dependency b`:  "services:\n  a: {command: x, depends_on: {b: {condition: ready}}}\n  b: {command: x}\n",
+		"field timeout not found in depends_on.b":     "services:\n  a: {command: x, depends_on: {b: {timeout: 1s}}}\n  b: {command: x}\n",
+		"depends_on.b must be a mapping":              "services:\n  a: {command: x, depends_on: {b: healthy}}\n  b: {command: x}\n",
+		"depends_on must be a list":                   "services:\n  a: {command: x, depends_on: b}\n  b: {command: x}\n",
+		"restart_policy: max_retries cannot be":       "services:\n  a: {command: x, restart_policy: {max_retries: -1}}\n",
+		"restart_policy: max_backoff cannot be lower": "services:\n  a: {command: x, restart_policy: {backoff: 1m, max_backoff: 1s}}\n",
+		"restart_policy: multiplier must be at least": "services:\n  a: {command: x, restart_policy: {multiplier: 0.5}}\n",
+		"restart_policy: jitter must be between":      "services:\n  a: {command: x, restart_policy: {jitter: 2}}\n",
+		"restart_policy: crash_loop.failures cannot":  "services:\n  a: {command: x, restart_policy: {crash_loop: {failures: -1}}}\n",
+		`on_fatal: invalid action "panic"`:            "on_fatal: {action: panic}\nservices:\n  a: {command: x}\n",
+		"on_fatal: command is required by the hook":   "on_fatal: {action: hook}\nservices:\n  a: {command: x}\n",
+		"on_fatal: command requires action hook":      "on_fatal: {command: alert}\nservices:\n  a: {command: x}\n",
+	}
+
+	for want, yaml := range tests {
+		t.Run(want, func(t *testing.T) {
+			_, err := ParseSupervizConfig([]byte(yaml), "/srv")
+			assert.ErrorContains(t, err, want)
+		})
+	}
+}
+
+func TestParseSupervizConfig_DependencyConditions(t *testing.T) {
+	config, err := ParseSupervizConfig([]byte(`
+services:
+  vault-agent: {command: vault}
+  migrate: {command: migrate, restart: never}
+  consul-template:
+    command: consul-template
+    depends_on:
+      vault-agent:
+        condition: healthy
+  app:
+    command: app
+    depends_on:
+      consul-template:
+      migrate:
+        condition: completed_successfully
+`), "/srv")
+	require.NoError(t, err)
+
+	assert.Equal(t, Dependencies{{Service: "vault-agent", Condition: ConditionHealthy}}, config.Services["consul-template"].DependsOn)
+	assert.Equal(t, Dependencies{
+		{Service: "consul-template", Condition: ConditionStarted},
+		{Service: "migrate", Condition: ConditionCompletedSuccessfully},
+	}, config.Services["app"].DependsOn)
+
+	order, err := config.StartOrder()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"migrate", "vault-agent", "consul-template", "app"}, order)
+
+	assert.Equal(t, []string{"consul-template"}, config.Dependents("vault-agent"))
+	assert.Equal(t, []string{"app"}, config.Dependents("migrate"))
+	assert.Empty(t, config.Dependents("app"))
+}
+
+func TestParseSupervizConfig_RestartPolicy(t *testing.T) {
+	config, err := ParseSupervizConfig([]byte(`
+on_fatal:
+  action: hook
+  command: /usr/local/bin/alert
+  args: [--page]
+services:
+  defaults: {command: x}
+  tuned:
+    command: x
+    restart: unless-stopped
+    restart_policy:
+      max_retries: 3
+      backoff: 500ms
+      max_backoff: 5s
+      multiplier: 1.5
+      jitter: 0
+      reset_after: 10s
+      crash_loop: {failures: 4}
+`), "/srv")
+	require.NoError(t, err)
+
+	assert.Equal(t, OnFatalConfig{Action: FatalHook, Command: "/usr/local/bin/alert", Args: []string{"--page"}, Timeout: DefaultHookTimeout}, config.OnFatal)
+
+	defaults := config.Services["defaults"].RestartPolicy
+	require.NotNil(t, defaults.Jitter)
+	assert.Equal(t, DefaultBackoffJitter, *defaults.Jitter)
+	assert.Equal(t, 0, defaults.MaxRetries, "unlimited by default")
+	assert.Equal(t, DefaultBackoff, defaults.Backoff)
+	assert.Equal(t, DefaultMaxBackoff, defaults.MaxBackoff)
+	assert.Equal(t, DefaultBackoffMultiplier, defaults.Multiplier)
+	assert.Equal(t, DefaultResetAfter, defaults.ResetAfter)
+	assert.Equal(t, CrashLoopPolicy{}, defaults.CrashLoop, "crash-loop detection is opt-in")
+
+	tuned := config.Services["tuned"]
+	assert.Equal(t, RestartUnlessStopped, tuned.Restart)
+	require.NotNil(t, tuned.RestartPolicy.Jitter)
+	assert.Zero(t, *tuned.RestartPolicy.Jitter, "an explicit 0 disables jitter")
+	assert.Equal(t, 3, tuned.RestartPolicy.MaxRetries)
+	assert.Equal(t, 500*time.Millisecond, tuned.RestartPolicy.Backoff)
+	assert.Equal(t, 1.5, tuned.RestartPolicy.Multiplier)
+	assert.Equal(t, CrashLoopPolicy{Failures: 4, Window: DefaultCrashLoopWindow}, tuned.RestartPolicy.CrashLoop)
+
+	config, err = ParseSupervizConfig([]byte("services:\n  a: {command: x}\n"), "/srv")
+	require.NoError(t, err)
+	assert.Equal(t, FatalContinue, config.OnFatal.Action)
+}
+
+func TestLoadSupervizConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "superviz.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("services:\n  app: {command: app, working_dir: run}\n"), 0o600))
+
+	config, err := LoadSupervizConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, dir, config.Dir)
+	assert.Equal(t, filepath.Join(dir, "run"), config.Services["app"].WorkingDir)
+
+	_, err = LoadSupervizConfig(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read configuration")
+
+	require.NoError(t, os.WriteFile(path, []byte("services: {}\n"), 0o600))
+	_, err = LoadSupervizConfig(path)
+	assert.ErrorContains(t, err, path+": configuration declares no services")
+}
@@ -31,17 +31,17 @@ var (
 // including version numbers, build details, and runtime environment.
 type VersionInfo struct {
 	// Version is the application version string
-	Version string
+	Version string `json:"version" yaml:"version"`
 	// Commit is the git commit hash of the build
-	Commit string
+	Commit string `json:"commit" yaml:"commit"`
 	// BuiltAt is the timestamp when the binary was built
-	BuiltAt string
+	BuiltAt string `json:"built_at" yaml:"built_at"`
 	// BuiltBy identifies the build system or user who created the binary
-	BuiltBy string
+	BuiltBy string `json:"built_by" yaml:"built_by"`
 	// GoVersion is the Go compiler version used for the build
-	GoVersion string
+	GoVersion string `json:"go_version" yaml:"go_version"`
 	// OSArch is the target operating system and architecture
-	OSArch string
+	OSArch string `json:"os_arch" yaml:"os_arch"`
 }
 
 var (
@@ -0,0 +1,158 @@
+// internal/services/artifact/archive.go - Extraction and atomic installation of a download
+package artifact
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+// errNotFound reports that the file to extract is not in the archive
+var errNotFound = errors.New("not found in the archive")
+
+// installFile extracts the declared file from a download and moves it in
+// place atomically.
+//
+// Parameters:
+//   - source: string verified download in the cache
+//   - artifact: *providers.ArtifactConfig format, extracted file, path and mode
+//
+// Returns:
+//   - digest: string hex SHA-256 digest of the installed file
+//   - err: error if the file cannot be extracted or installed
+func installFile(source string, artifact *providers.ArtifactConfig) (string, error) {
+	dir := filepath.Dir(artifact.Path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(artifact.Path)+".install-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // gone once renamed
+
+	h := sha256.New()
+	if err := extract(source, artifact.Format, artifact.Extract, io.MultiWriter(tmp, h)); err != nil {
+		_ = tmp.Close() //nolint:errcheck // already failing
+		return "", err
+	}
+	if err := tmp.Chmod(os.FileMode(artifact.Mode)); err != nil {
+		_ = tmp.Close() //nolint:errcheck // already failing
+		return "", err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close() //nolint:errcheck // already failing
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), artifact.Path); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// extract copies one file of a download to w.
+//
+// Parameters:
+//   - source: string download location
+//   - format: providers.ArtifactFormat tar.gz, zip or raw
+//   - name: string path of the file inside the archive, unused for raw
+//   - w: io.Writer destination
+//
+// Returns:
+//   - err: error if the archive is corrupt or does not hold a regular file named name
+func extract(source string, format providers.ArtifactFormat, name string, w io.Writer) error {
+	switch format {
+	case providers.ArtifactTarGz:
+		return extractTarGz(source, name, w)
+	case providers.ArtifactZip:
+		return extractZip(source, name, w)
+	default:
+		f, err := os.Open(source) //nolint:gosec // the download staged in the cache
+		if err != nil {
+			return err
+		}
+		defer f.Close() //nolint:errcheck // read-only file
+		_, err = io.Copy(w, f)
+		return err
+	}
+}
+
+// extractTarGz copies the file named name of a gzip-compressed tarball to w
+func extractTarGz(source, name string, w io.Writer) error {
+	f, err := os.Open(source) //nolint:gosec // the download staged in the cache
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck // read-only file
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("invalid tar.gz archive: %w", err)
+	}
+	defer gz.Close() //nolint:errcheck // read-only stream
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s %w", name, errNotFound)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid tar.gz archive: %w", err)
+		}
+		if !sameEntry(header.Name, name) {
+			continue
+		}
+		if header.Typeflag != tar.TypeReg {
+			return fmt.Errorf("%s is not a regular file in the archive", name)
+		}
+		_, err = io.Copy(w, io.LimitReader(tr, maxDownloadSize)) //nolint:gosec // bounded by maxDownloadSize
+		return err
+	}
+}
+
+// extractZip copies the file named name of a zip archive to w
+func extractZip(source, name string, w io.Writer) error {
+	zr, err := zip.OpenReader(source)
+	if err != nil {
+		return fmt.Errorf("invalid zip archive: %w", err)
+	}
+	defer zr.Close() //nolint:errcheck // read-only archive
+
+	for _, file := range zr.File {
+		if !sameEntry(file.Name, name) {
+			continue
+		}
+		if !file.Mode().IsRegular() {
+			return fmt.Errorf("%s is not a regular file in the archive", name)
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("invalid zip archive: %w", err)
+		}
+		defer rc.Close() //nolint:errcheck // read-only entry
+
+		_, err = io.Copy(w, io.LimitReader(rc, maxDownloadSize)) //nolint:gosec // bounded by maxDownloadSize
+		return err
+	}
+	return fmt.Errorf("%s %w", name, errNotFound)
+}
+
+// sameEntry compares an archive entry with the declared name, ignoring a leading ./
+func sameEntry(entry, name string) bool {
+	return path.Clean(strings.TrimPrefix(entry, "./")) == path.Clean(strings.TrimPrefix(name, "./"))
+}
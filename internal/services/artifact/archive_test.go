@@ -0,0 +1,118 @@
+package artifact
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sortedNames returns the names of files in order, so that archives are reproducible
+func sortedNames(files map[string]string) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// tarGzArchive builds a gzip-compressed tarball holding files, names ending with / are directories
+func tarGzArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range sortedNames(files) {
+		header := &tar.Header{Name: name, Mode: 0o755, Size: int64(len(files[name])), Typeflag: tar.TypeReg}
+		if name[len(name)-1] == '/' {
+			header.Typeflag, header.Size = tar.TypeDir, 0
+		}
+		require.NoError(t, tw.WriteHeader(header))
+		_, err := tw.Write([]byte(files[name]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+// zipArchive builds a zip archive holding files, names ending with / are directories
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range sortedNames(files) {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(files[name]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+// writeArchive stores data in a temporary file and returns its path
+func writeArchive(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "download")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestExtract(t *testing.T) {
+	files := map[string]string{"./bin/": "", "./bin/app": "app", "README": "readme"}
+	tgz := writeArchive(t, tarGzArchive(t, files))
+	zipped := writeArchive(t, zipArchive(t, files))
+
+	for format, source := range map[providers.ArtifactFormat]string{providers.ArtifactTarGz: tgz, providers.ArtifactZip: zipped} {
+		t.Run(string(format), func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, extract(source, format, "bin/app", &out))
+			assert.Equal(t, "app", out.String())
+
+			require.ErrorIs(t, extract(source, format, "app", &bytes.Buffer{}), errNotFound, "entries match by path")
+			assert.EqualError(t, extract(source, format, "bin", &bytes.Buffer{}), "bin is not a regular file in the archive")
+		})
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, extract(tgz, providers.ArtifactRaw, "", &out))
+	assert.Equal(t, tarGzArchive(t, files), out.Bytes(), "raw downloads are installed as is")
+
+	assert.ErrorContains(t, extract(zipped, providers.ArtifactTarGz, "app", &bytes.Buffer{}), "invalid tar.gz archive")
+	assert.ErrorContains(t, extract(tgz, providers.ArtifactZip, "app", &bytes.Buffer{}), "invalid zip archive")
+}
+
+func TestInstallFile(t *testing.T) {
+	dir := t.TempDir()
+	source := writeArchive(t, []byte("binary"))
+	artifact := &providers.ArtifactConfig{Format: providers.ArtifactRaw, Path: filepath.Join(dir, "bin", "app"), Mode: 0o700}
+	require.NoError(t, os.MkdirAll(filepath.Dir(artifact.Path), 0o755))
+	require.NoError(t, os.WriteFile(artifact.Path, []byte("old"), 0o644))
+
+	digest, err := installFile(source, artifact)
+	require.NoError(t, err)
+	assert.Equal(t, sum([]byte("binary")), digest)
+	info, err := os.Stat(artifact.Path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+
+	artifact.Format, artifact.Extract = providers.ArtifactZip, "app"
+	_, err = installFile(source, artifact)
+	require.Error(t, err)
+	data, err := os.ReadFile(artifact.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "binary", string(data), "a failed installation keeps the previous file")
+	entries, err := os.ReadDir(filepath.Dir(artifact.Path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary file is left behind")
+}
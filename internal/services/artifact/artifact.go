@@ -0,0 +1,476 @@
+// Package artifact downloads the binaries declared in superviz.yaml into a
+// content-addressed cache, verifies them and installs them atomically.
+package artifact
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+// Download limits.
+const (
+	// DefaultTimeout bounds each download
+	DefaultTimeout = 10 * time.Minute
+	// maxDownloadSize caps a download
+	maxDownloadSize = 1 << 30
+	// maxSignatureSize caps a signature file
+	maxSignatureSize = 4 << 10
+)
+
+// Status is the outcome of the installation of one artifact.
+type Status string
+
+// Installation outcomes.
+const (
+	// StatusInstalled means the artifact was downloaded or taken from the cache and installed
+	StatusInstalled Status = "installed"
+	// StatusPresent means the declared version was already installed
+	StatusPresent Status = "present"
+	// StatusFailed means the artifact could not be downloaded, verified or installed
+	StatusFailed Status = "failed"
+)
+
+// Result is the installation of one artifact.
+type Result struct {
+	// Name is the artifact name
+	Name string `json:"name" yaml:"name"`
+	// Version is the declared version
+	Version string `json:"version" yaml:"version"`
+	// Path is the installed file
+	Path string `json:"path" yaml:"path"`
+	// Status is the outcome
+	Status Status `json:"status" yaml:"status"`
+	// Digest is the hex SHA-256 digest of the download
+	Digest string `json:"digest,omitempty" yaml:"digest,omitempty"`
+	// Cached is true when the download was taken from the cache
+	Cached bool `json:"cached,omitempty" yaml:"cached,omitempty"`
+	// Message explains a failure
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	// Services are refused when the artifact failed, every service when empty
+	Services []string `json:"services,omitempty" yaml:"services,omitempty"`
+}
+
+// OK reports whether the artifact is in place.
+//
+// Returns:
+//   - ok: true unless the installation failed
+func (r Result) OK() bool {
+	return r.Status != StatusFailed
+}
+
+// Format describes the result on one line.
+//
+// Returns:
+//   - line: string such as "vault 1.15.4: installed to /usr/local/bin/vault"
+func (r Result) Format() string {
+	prefix := r.Name + " " + r.Version + ": "
+	switch r.Status {
+	case StatusInstalled:
+		if r.Cached {
+			return prefix + "installed to " + r.Path + " from cache"
+		}
+		return prefix + "installed to " + r.Path
+	case StatusPresent:
+		return prefix + "already installed at " + r.Path
+	default:
+		return prefix + r.Message
+	}
+}
+
+// Report is the installation of every declared artifact.
+type Report struct {
+	// Results are sorted by artifact name
+	Results []Result `json:"results" yaml:"results"`
+}
+
+// Failed returns the artifacts that could not be installed.
+//
+// Returns:
+//   - failed: []Result results in name order
+func (r *Report) Failed() []Result {
+	var failed []Result
+	for _, result := range r.Results {
+		if !result.OK() {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Format lists the results followed by a summary line.
+//
+// Returns:
+//   - formatted: string multi-line report ending with a newline
+func (r *Report) Format() string {
+	var b strings.Builder
+	for _, result := range r.Results {
+		b.WriteString(result.Format())
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(&b, "%d artifacts, %d failed\n", len(r.Results), len(r.Failed()))
+	return b.String()
+}
+
+// Allow tells whether a service may start, as the supervisor asks before
+// every start attempt.
+//
+// Parameters:
+//   - service: string service about to start
+//
+// Returns:
+//   - err: error naming the first failed artifact the service depends on
+func (r *Report) Allow(service string) error {
+	for _, result := range r.Failed() {
+		if len(result.Services) == 0 || containsString(result.Services, service) {
+			return fmt.Errorf("artifact %s not installed: %s", result.Name, result.Message)
+		}
+	}
+	return nil
+}
+
+// InstallerOptions configures an Installer.
+//
+// All fields are optional.
+type InstallerOptions struct {
+	// HTTPClient downloads the artifacts (default client with DefaultTimeout)
+	HTTPClient *http.Client
+	// OS is substituted for {{os}} (default runtime.GOOS)
+	OS string
+	// Arch is substituted for {{arch}} (default runtime.GOARCH)
+	Arch string
+}
+
+// Installer installs artifacts for one platform.
+type Installer struct {
+	// cacheDir holds the downloads and install receipts
+	cacheDir string
+	// httpClient downloads the artifacts
+	httpClient *http.Client
+	// goos is the target operating system
+	goos string
+	// goarch is the target architecture
+	goarch string
+}
+
+// NewInstaller creates an installer.
+//
+// Parameters:
+//   - cacheDir: string directory holding the downloads and install receipts
+//   - opts: *InstallerOptions overrides, nil for defaults
+//
+// Returns:
+//   - installer: *Installer ready for use
+func NewInstaller(cacheDir string, opts *InstallerOptions) *Installer {
+	if opts == nil {
+		opts = &InstallerOptions{}
+	}
+	i := &Installer{cacheDir: cacheDir, httpClient: opts.HTTPClient, goos: opts.OS, goarch: opts.Arch}
+	if i.httpClient == nil {
+		i.httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	if i.goos == "" {
+		i.goos = runtime.GOOS
+	}
+	if i.goarch == "" {
+		i.goarch = runtime.GOARCH
+	}
+	return i
+}
+
+// Install installs every artifact in name order.
+//
+// An artifact whose declared version is already installed, unchanged, is
+// skipped; a download whose digest is known is taken from the cache when
+// present. A failure does not stop the other installations.
+//
+// Parameters:
+//   - ctx: context.Context cancelling the downloads
+//   - artifacts: providers.Artifacts validated declarations
+//
+// Returns:
+//   - report: *Report one result per artifact
+func (i *Installer) Install(ctx context.Context, artifacts providers.Artifacts) *Report {
+	report := &Report{}
+	for _, name := range artifacts.Names() {
+		artifact := artifacts[name]
+		result := Result{Name: name, Version: artifact.Version, Path: artifact.Path, Services: artifact.Services}
+		if err := i.install(ctx, artifact, &result); err != nil {
+			result.Status, result.Message = StatusFailed, err.Error()
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// Installed reports whether every artifact is installed at its declared version.
+//
+// Parameters:
+//   - artifacts: providers.Artifacts validated declarations
+//
+// Returns:
+//   - installed: bool false as soon as one artifact needs work
+func (i *Installer) Installed(artifacts providers.Artifacts) bool {
+	for _, name := range artifacts.Names() {
+		if !i.present(artifacts[name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// install brings one artifact in place and fills result
+func (i *Installer) install(ctx context.Context, artifact *providers.ArtifactConfig, result *Result) error {
+	expected := artifact.SHA256.For(i.goos, i.goarch)
+	if expected == "" && artifact.Signature == nil {
+		return fmt.Errorf("no sha256 for %s/%s", i.goos, i.goarch)
+	}
+	if i.present(artifact) {
+		result.Status = StatusPresent
+		result.Digest = i.readReceipt(artifact.Name).Digest
+		return nil
+	}
+
+	source, digest := "", expected
+	if expected != "" {
+		if actual, err := fileDigest(i.cachePath(expected)); err == nil && actual == expected {
+			source, result.Cached = i.cachePath(expected), true
+		}
+	}
+	if source == "" {
+		var err error
+		if source, digest, err = i.download(ctx, artifact, expected); err != nil {
+			return err
+		}
+	}
+
+	installed, err := installFile(source, artifact)
+	if err != nil {
+		return fmt.Errorf("failed to install %s: %w", artifact.Path, err)
+	}
+	receipt := i.receiptFor(artifact)
+	receipt.Digest, receipt.Installed = digest, installed
+	if err := i.writeReceipt(artifact.Name, receipt); err != nil {
+		return err
+	}
+	result.Status, result.Digest = StatusInstalled, digest
+	return nil
+}
+
+// download fetches the artifact into the cache, verifies it and returns its cache path and digest
+func (i *Installer) download(ctx context.Context, artifact *providers.ArtifactConfig, expected string) (string, string, error) {
+	rawURL := artifact.Expand(artifact.URL, i.goos, i.goarch)
+	dir := filepath.Join(i.cacheDir, "sha256")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", fmt.Errorf("failed to create cache: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".download-*")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create cache: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // gone once renamed
+
+	h := sha256.New()
+	err = i.get(ctx, rawURL, maxDownloadSize, io.MultiWriter(tmp, h))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to download %s: %w", rawURL, err)
+	}
+
+	digest := hex.EncodeToString(h.Sum(nil))
+	if expected != "" && digest != expected {
+		return "", "", fmt.Errorf("checksum mismatch for %s: expected %s, got %s", rawURL, expected, digest)
+	}
+	if artifact.Signature != nil {
+		if err := i.verifySignature(ctx, artifact, tmp.Name()); err != nil {
+			return "", "", err
+		}
+	}
+
+	path := i.cachePath(digest)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", "", fmt.Errorf("failed to store %s in the cache: %w", rawURL, err)
+	}
+	return path, digest, nil
+}
+
+// verifySignature checks the detached signature of a download
+func (i *Installer) verifySignature(ctx context.Context, artifact *providers.ArtifactConfig, path string) error {
+	sigURL := artifact.Expand(artifact.Signature.URL, i.goos, i.goarch)
+	key, err := providers.ParsePublicKey(artifact.Signature.PublicKey)
+	if err != nil {
+		return err
+	}
+	var encoded strings.Builder
+	if err := i.get(ctx, sigURL, maxSignatureSize, &encoded); err != nil {
+		return fmt.Errorf("failed to download signature %s: %w", sigURL, err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded.String()))
+	if err != nil {
+		return fmt.Errorf("invalid signature %s: %w", sigURL, err)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // the download staged in the cache
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, data, signature) {
+		return fmt.Errorf("signature verification failed for %s", artifact.Expand(artifact.URL, i.goos, i.goarch))
+	}
+	return nil
+}
+
+// get performs an HTTP GET and copies at most limit bytes of body to w
+func (i *Installer) get(ctx context.Context, rawURL string, limit int64, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := i.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only body
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+	n, err := io.Copy(w, io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		return fmt.Errorf("response exceeds %d bytes", limit)
+	}
+	return nil
+}
+
+// cachePath returns the cache location of a download
+func (i *Installer) cachePath(digest string) string {
+	return filepath.Join(i.cacheDir, "sha256", digest)
+}
+
+// receipt records what was installed for an artifact
+type receipt struct {
+	// Version is the installed version
+	Version string `json:"version"`
+	// URL is the expanded download location
+	URL string `json:"url"`
+	// Format is the archive format
+	Format providers.ArtifactFormat `json:"format"`
+	// Extract is the file installed from the archive
+	Extract string `json:"extract,omitempty"`
+	// Path is the installed file
+	Path string `json:"path"`
+	// Mode is the permission of the installed file
+	Mode providers.FileMode `json:"mode"`
+	// Digest is the hex SHA-256 digest of the download
+	Digest string `json:"digest"`
+	// Installed is the hex SHA-256 digest of the installed file
+	Installed string `json:"installed"`
+}
+
+// receiptFor returns the receipt of the declared artifact, without digests
+func (i *Installer) receiptFor(artifact *providers.ArtifactConfig) receipt {
+	return receipt{
+		Version: artifact.Version,
+		URL:     artifact.Expand(artifact.URL, i.goos, i.goarch),
+		Format:  artifact.Format,
+		Extract: artifact.Extract,
+		Path:    artifact.Path,
+		Mode:    artifact.Mode,
+	}
+}
+
+// present reports whether the declared artifact is installed and unmodified
+func (i *Installer) present(artifact *providers.ArtifactConfig) bool {
+	installed := i.readReceipt(artifact.Name)
+	want := i.receiptFor(artifact)
+	want.Digest, want.Installed = installed.Digest, installed.Installed
+	if installed.Installed == "" || installed != want {
+		return false
+	}
+	if expected := artifact.SHA256.For(i.goos, i.goarch); expected != "" && expected != installed.Digest {
+		return false
+	}
+	info, err := os.Stat(artifact.Path)
+	if err != nil {
+		return false
+	}
+	// Windows only keeps the read-only bit of a mode
+	if runtime.GOOS != "windows" && info.Mode().Perm() != os.FileMode(artifact.Mode).Perm() {
+		return false
+	}
+	actual, err := fileDigest(artifact.Path)
+	return err == nil && actual == installed.Installed
+}
+
+// receiptPath returns the receipt location of an artifact
+func (i *Installer) receiptPath(name string) string {
+	return filepath.Join(i.cacheDir, "installed", name+".json")
+}
+
+// readReceipt returns the receipt of an artifact, empty when it was never installed
+func (i *Installer) readReceipt(name string) receipt {
+	var r receipt
+	data, err := os.ReadFile(i.receiptPath(name))
+	if err == nil {
+		_ = json.Unmarshal(data, &r) //nolint:errcheck // a corrupt receipt reinstalls the artifact
+	}
+	return r
+}
+
+// writeReceipt records the installation of an artifact
+func (i *Installer) writeReceipt(name string, r receipt) error {
+	path := i.receiptPath(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to record the installation: %w", err)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to record the installation: %w", err)
+	}
+	return nil
+}
+
+// fileDigest computes the hex SHA-256 digest of a file
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path) //nolint:gosec // the path comes from the operator's configuration
+	if err != nil {
+		return "", err
+	}
+	defer f.Close() //nolint:errcheck // read-only file
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// containsString reports whether list holds s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
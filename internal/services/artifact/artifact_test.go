@@ -0,0 +1,240 @@
+package artifact
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// releaseServer serves downloads over TLS and counts the requests per path
+type releaseServer struct {
+	*httptest.Server
+	mu    sync.Mutex
+	files map[string][]byte
+	hits  map[string]int
+}
+
+// newReleaseServer starts a server serving files
+func newReleaseServer(t *testing.T, files map[string][]byte) *releaseServer {
+	t.Helper()
+	s := &releaseServer{files: files, hits: make(map[string]int)}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.hits[r.URL.Path]++
+		data, ok := s.files[r.URL.Path]
+		s.mu.Unlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data) //nolint:errcheck // test server
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// count returns the requests made for path
+func (s *releaseServer) count(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[path]
+}
+
+// set replaces the content served at path
+func (s *releaseServer) set(path string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[path] = data
+}
+
+// sum returns the hex SHA-256 digest of data
+func sum(data []byte) string {
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:])
+}
+
+// formats returns the formatted results of a report
+func formats(report *Report) []string {
+	var lines []string
+	for _, result := range report.Results {
+		lines = append(lines, result.Format())
+	}
+	return lines
+}
+
+func TestInstaller_Install(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	vaultZip := zipArchive(t, map[string]string{"LICENSE": "MPL", "vault": "vault 1.0"})
+	toolTgz := tarGzArchive(t, map[string]string{"./dist/tool": "tool"})
+	server := newReleaseServer(t, map[string][]byte{
+		"/vault/1.0/vault_linux_amd64.zip": vaultZip,
+		"/tool.tgz":                        toolTgz,
+		"/tool.tgz.sig":                    []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, toolTgz)) + "\n"),
+		"/jq-linux":                        []byte("jq"),
+	})
+
+	dir := t.TempDir()
+	artifacts := providers.Artifacts{
+		"vault": {
+			Name: "vault", Version: "1.0", URL: server.URL + "/vault/{{version}}/vault_{{os}}_{{arch}}.zip",
+			Format: providers.ArtifactZip, Extract: "vault", Path: filepath.Join(dir, "bin", "vault"), Mode: 0o750,
+			SHA256: providers.PlatformDigests{"linux/amd64": sum(vaultZip), "darwin/arm64": strings.Repeat("0", 64)},
+		},
+		"tool": {
+			Name: "tool", Version: "2", URL: server.URL + "/tool.tgz",
+			Format: providers.ArtifactTarGz, Extract: "dist/tool", Path: filepath.Join(dir, "bin", "tool"), Mode: 0o755,
+			Signature: &providers.ArtifactSignature{URL: server.URL + "/tool.tgz.sig", PublicKey: base64.StdEncoding.EncodeToString(public)},
+		},
+		"jq": {
+			Name: "jq", Version: "1.7", URL: server.URL + "/{{name}}-{{os}}",
+			Format: providers.ArtifactRaw, Path: filepath.Join(dir, "bin", "jq"), Mode: 0o755,
+			SHA256: providers.PlatformDigests{providers.AnyPlatform: sum([]byte("jq"))},
+		},
+	}
+	installer := NewInstaller(filepath.Join(dir, "cache"), &InstallerOptions{HTTPClient: server.Client(), OS: "linux", Arch: "amd64"})
+	assert.False(t, installer.Installed(artifacts))
+
+	report := installer.Install(context.Background(), artifacts)
+	assert.Equal(t, []string{
+		"jq 1.7: installed to " + filepath.Join(dir, "bin", "jq"),
+		"tool 2: installed to " + filepath.Join(dir, "bin", "tool"),
+		"vault 1.0: installed to " + filepath.Join(dir, "bin", "vault"),
+	}, formats(report))
+	assert.Empty(t, report.Failed())
+	for name, content := range map[string]string{"jq": "jq", "tool": "tool", "vault": "vault 1.0"} {
+		data, err := os.ReadFile(filepath.Join(dir, "bin", name))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	}
+	info, err := os.Stat(filepath.Join(dir, "bin", "vault"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o750), info.Mode().Perm())
+	assert.FileExists(t, filepath.Join(dir, "cache", "sha256", sum(vaultZip)), "downloads are addressed by digest")
+	assert.Equal(t, sum(toolTgz), report.Results[1].Digest)
+
+	// Nothing is downloaded again while the versions are installed
+	assert.True(t, installer.Installed(artifacts))
+	report = installer.Install(context.Background(), artifacts)
+	assert.Equal(t, "vault 1.0: already installed at "+filepath.Join(dir, "bin", "vault"), report.Results[2].Format())
+	assert.Equal(t, sum(vaultZip), report.Results[2].Digest)
+	assert.Equal(t, 1, server.count("/vault/1.0/vault_linux_amd64.zip"))
+	assert.Equal(t, 1, server.count("/tool.tgz.sig"))
+
+	// A modified file is installed again from the cache
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bin", "vault"), []byte("tampered"), 0o750))
+	assert.False(t, installer.Installed(artifacts))
+	report = installer.Install(context.Background(), artifacts)
+	assert.Equal(t, "vault 1.0: installed to "+filepath.Join(dir, "bin", "vault")+" from cache", report.Results[2].Format())
+	assert.Equal(t, 1, server.count("/vault/1.0/vault_linux_amd64.zip"))
+
+	// A new version is downloaded
+	vault2 := zipArchive(t, map[string]string{"vault": "vault 2.0"})
+	server.set("/vault/2.0/vault_linux_amd64.zip", vault2)
+	artifacts["vault"].Version = "2.0"
+	artifacts["vault"].SHA256 = providers.PlatformDigests{"linux/amd64": sum(vault2)}
+	report = installer.Install(context.Background(), artifacts)
+	assert.Equal(t, StatusInstalled, report.Results[2].Status)
+	assert.False(t, report.Results[2].Cached)
+	data, err := os.ReadFile(filepath.Join(dir, "bin", "vault"))
+	require.NoError(t, err)
+	assert.Equal(t, "vault 2.0", string(data))
+}
+
+func TestInstaller_Failures(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	archive := tarGzArchive(t, map[string]string{"app": "app"})
+	server := newReleaseServer(t, map[string][]byte{
+		"/app.tar.gz":     archive,
+		"/app.tar.gz.sig": []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte("other")))),
+		"/garbage.sig":    []byte("%%%"),
+	})
+	dir := t.TempDir()
+	key := base64.StdEncoding.EncodeToString(public)
+	declare := func(name string, change func(*providers.ArtifactConfig)) *providers.ArtifactConfig {
+		artifact := &providers.ArtifactConfig{
+			Name: name, Version: "1", URL: server.URL + "/app.tar.gz", Format: providers.ArtifactTarGz, Extract: "app",
+			Path: filepath.Join(dir, name), Mode: 0o755, SHA256: providers.PlatformDigests{providers.AnyPlatform: sum(archive)},
+		}
+		change(artifact)
+		return artifact
+	}
+	artifacts := providers.Artifacts{
+		"mismatch": declare("mismatch", func(a *providers.ArtifactConfig) {
+			a.SHA256 = providers.PlatformDigests{providers.AnyPlatform: strings.Repeat("0", 64)}
+			a.Services = []string{"api"}
+		}),
+		"missing": declare("missing", func(a *providers.ArtifactConfig) {
+			a.URL = server.URL + "/nope.tar.gz"
+			a.SHA256 = providers.PlatformDigests{providers.AnyPlatform: sum([]byte("nope"))}
+		}),
+		"entry":    declare("entry", func(a *providers.ArtifactConfig) { a.Extract = "bin/app" }),
+		"platform": declare("platform", func(a *providers.ArtifactConfig) { a.SHA256 = providers.PlatformDigests{"windows/amd64": sum(archive)} }),
+		"signature": declare("signature", func(a *providers.ArtifactConfig) {
+			a.SHA256 = nil
+			a.Signature = &providers.ArtifactSignature{URL: server.URL + "/app.tar.gz.sig", PublicKey: key}
+		}),
+		"garbage": declare("garbage", func(a *providers.ArtifactConfig) {
+			a.SHA256 = nil
+			a.Signature = &providers.ArtifactSignature{URL: server.URL + "/garbage.sig", PublicKey: key}
+		}),
+	}
+	installer := NewInstaller(filepath.Join(dir, "cache"), &InstallerOptions{HTTPClient: server.Client(), OS: "linux", Arch: "arm64"})
+
+	report := installer.Install(context.Background(), artifacts)
+	assert.Equal(t, []string{
+		"entry 1: failed to install " + filepath.Join(dir, "entry") + ": bin/app not found in the archive",
+		"garbage 1: invalid signature " + server.URL + "/garbage.sig: illegal base64 data at input byte 0",
+		"mismatch 1: checksum mismatch for " + server.URL + "/app.tar.gz: expected " + strings.Repeat("0", 64) + ", got " + sum(archive),
+		"missing 1: failed to download " + server.URL + "/nope.tar.gz: unexpected HTTP status 404 Not Found",
+		"platform 1: no sha256 for linux/arm64",
+		"signature 1: signature verification failed for " + server.URL + "/app.tar.gz",
+	}, formats(report))
+	assert.Len(t, report.Failed(), 6)
+	assert.Contains(t, report.Format(), "6 artifacts, 6 failed\n")
+	assert.NoFileExists(t, filepath.Join(dir, "mismatch"))
+
+	entries, err := os.ReadDir(filepath.Join(dir, "cache", "sha256"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "only the verified download of the entry artifact is cached")
+	assert.Equal(t, sum(archive), entries[0].Name())
+
+	assert.EqualError(t, report.Allow("api"), "artifact entry not installed: "+report.Results[0].Message)
+	ok := &Report{Results: []Result{
+		{Name: "vault", Status: StatusPresent},
+		{Name: "consul", Status: StatusFailed, Message: "boom", Services: []string{"consul"}},
+	}}
+	assert.NoError(t, ok.Allow("vault"), "only the listed services are refused")
+	assert.EqualError(t, ok.Allow("consul"), "artifact consul not installed: boom")
+
+	_, err = os.Stat(filepath.Join(dir, "cache", "installed"))
+	assert.ErrorIs(t, err, os.ErrNotExist, "failed installations leave no receipt")
+}
+
+func TestInstaller_CancelledDownload(t *testing.T) {
+	server := newReleaseServer(t, map[string][]byte{"/a": []byte("a")})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	installer := NewInstaller(t.TempDir(), &InstallerOptions{HTTPClient: server.Client()})
+	report := installer.Install(ctx, providers.Artifacts{"a": {
+		Name: "a", Version: "1", URL: server.URL + "/a", Format: providers.ArtifactRaw,
+		Path: filepath.Join(t.TempDir(), "a"), Mode: 0o755, SHA256: providers.PlatformDigests{providers.AnyPlatform: sum([]byte("a"))},
+	}})
+	require.Len(t, report.Failed(), 1)
+	assert.Contains(t, report.Results[0].Message, "context canceled")
+}
@@ -0,0 +1,67 @@
+// internal/services/artifact/gate.go - Start gate following the latest installation of each artifact
+package artifact
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Gate refuses the services depending on an artifact that is not installed,
+// following the latest installation of each artifact.
+type Gate struct {
+	// mu guards results
+	mu sync.Mutex
+	// results maps an artifact name to its latest installation
+	results map[string]Result
+}
+
+// NewGate creates a gate from the installation of the artifacts.
+//
+// Parameters:
+//   - report: *Report installation at startup
+//
+// Returns:
+//   - gate: *Gate allowing the services whose artifacts were installed
+func NewGate(report *Report) *Gate {
+	g := &Gate{results: make(map[string]Result, len(report.Results))}
+	for _, result := range report.Results {
+		g.results[result.Name] = result
+	}
+	return g
+}
+
+// Record replaces the latest installation of an artifact.
+//
+// Parameters:
+//   - result: Result installation of one artifact
+func (g *Gate) Record(result Result) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.results[result.Name] = result
+}
+
+// Allow tells whether a service may start, as the supervisor asks before
+// every start attempt.
+//
+// Parameters:
+//   - service: string service about to start
+//
+// Returns:
+//   - err: error naming the first failed artifact, in name order, the service depends on
+func (g *Gate) Allow(service string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	names := make([]string, 0, len(g.results))
+	for name := range g.results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result := g.results[name]
+		if !result.OK() && (len(result.Services) == 0 || containsString(result.Services, service)) {
+			return fmt.Errorf("artifact %s not installed: %s", result.Name, result.Message)
+		}
+	}
+	return nil
+}
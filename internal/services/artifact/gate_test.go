@@ -0,0 +1,22 @@
+package artifact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGate(t *testing.T) {
+	gate := NewGate(&Report{Results: []Result{
+		{Name: "vault", Status: StatusFailed, Message: "download failed", Services: []string{"vault"}},
+		{Name: "jq", Status: StatusPresent},
+	}})
+	assert.EqualError(t, gate.Allow("vault"), "artifact vault not installed: download failed")
+	assert.NoError(t, gate.Allow("web"))
+
+	gate.Record(Result{Name: "vault", Status: StatusInstalled, Services: []string{"vault"}})
+	assert.NoError(t, gate.Allow("vault"), "a later installation lifts the refusal")
+
+	gate.Record(Result{Name: "jq", Status: StatusFailed, Message: "checksum mismatch"})
+	assert.EqualError(t, gate.Allow("web"), "artifact jq not installed: checksum mismatch", "an artifact without services gates every service")
+}
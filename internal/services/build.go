@@ -0,0 +1,188 @@
+// internal/services/build.go - Pre-runtime provisioning of a superviz.yaml file
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services/artifact"
+	"github.com/kodflow/superviz.io/internal/services/integrity"
+	"github.com/kodflow/superviz.io/internal/utils"
+)
+
+// BuildResult is the outcome of a build.
+type BuildResult struct {
+	// Hash is the provisioning hash of the configuration
+	Hash string `json:"hash" yaml:"hash"`
+	// Lock is the lock file recording the build
+	Lock string `json:"lock" yaml:"lock"`
+	// UpToDate is true when a previous build was still satisfied and nothing was done
+	UpToDate bool `json:"up_to_date" yaml:"up_to_date"`
+	// Artifacts are the installation results
+	Artifacts []artifact.Result `json:"artifacts,omitempty" yaml:"artifacts,omitempty"`
+	// Integrity are the file checks
+	Integrity []integrity.Result `json:"integrity,omitempty" yaml:"integrity,omitempty"`
+}
+
+// Format describes the build, one line per artifact and file.
+//
+// Returns:
+//   - formatted: string multi-line report ending with a newline
+func (r *BuildResult) Format() string {
+	if r.UpToDate {
+		return fmt.Sprintf("build %s is up to date\n", shortHash(r.Hash))
+	}
+	var b strings.Builder
+	for _, result := range r.Artifacts {
+		fmt.Fprintf(&b, "artifact: %s\n", result.Format())
+	}
+	for _, result := range r.Integrity {
+		fmt.Fprintf(&b, "integrity: %s\n", result.Format())
+	}
+	fmt.Fprintf(&b, "build %s recorded in %s\n", shortHash(r.Hash), r.Lock)
+	return b.String()
+}
+
+// BuildService runs the provisioning phase of a superviz.yaml file on its own.
+type BuildService struct {
+	// httpClient downloads the artifacts
+	httpClient *http.Client
+}
+
+// BuildServiceOptions contains options for creating a BuildService.
+type BuildServiceOptions struct {
+	// HTTPClient downloads the artifacts (default client with artifact.DefaultTimeout)
+	HTTPClient *http.Client
+}
+
+// NewBuildService creates a new build service with the given options.
+//
+// Parameters:
+//   - opts: *BuildServiceOptions overrides, nil for defaults
+//
+// Returns:
+//   - service: *BuildService ready for use
+func NewBuildService(opts *BuildServiceOptions) *BuildService {
+	if opts == nil {
+		opts = &BuildServiceOptions{}
+	}
+	return &BuildService{httpClient: opts.HTTPClient}
+}
+
+// Build installs the artifacts and checks the files of a configuration, then
+// records the build in a lock file keyed by the provisioning hash.
+//
+// A build whose lock file matches the configuration, with every artifact
+// still installed, does nothing; svz run skips the artifact installation in
+// the same case. No service is started.
+//
+// Generated configuration files are not part of the build: superviz has no
+// config generation yet, so the provisioning hash and the lock file only cover
+// the artifacts and integrity sections.
+//
+// Parameters:
+//   - ctx: context.Context cancelling the downloads
+//   - w: io.Writer destination of the report
+//   - path: string superviz.yaml location
+//   - format: utils.OutputFormat text, json or yaml
+//
+// Returns:
+//   - err: error if the configuration is invalid, ErrBuildFailed when an artifact or a file fails
+func (s *BuildService) Build(ctx context.Context, w io.Writer, path string, format utils.OutputFormat) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+	config, err := providers.LoadSupervizConfig(path)
+	if err != nil {
+		return err
+	}
+
+	installer := newArtifactInstaller(config, s.httpClient)
+	result := &BuildResult{Lock: filepath.Join(config.Dir, providers.DefaultBuildLockPath)}
+	var satisfied bool
+	if result.Hash, satisfied, err = buildSatisfied(config, installer); err != nil {
+		return err
+	}
+
+	var problems []string
+	if satisfied {
+		result.UpToDate = true
+	} else {
+		report := installer.Install(ctx, config.Artifacts)
+		result.Artifacts = report.Results
+		if failed := len(report.Failed()); failed > 0 {
+			problems = append(problems, fmt.Sprintf("artifacts: %d of %d failed", failed, len(report.Results)))
+		}
+		if config.Integrity != nil {
+			checked := integrity.NewMonitor(config.Integrity, nil).Check()
+			result.Integrity = checked.Results
+			if failed := len(checked.Failed()); failed > 0 && config.Integrity.OnFailure != providers.IntegrityAlert {
+				problems = append(problems, fmt.Sprintf("integrity: %d of %d failed", failed, len(checked.Results)))
+			}
+		}
+		if len(problems) == 0 {
+			if err := buildLock(result.Hash, report).Write(result.Lock); err != nil {
+				return err
+			}
+		}
+	}
+
+	if format == utils.OutputText {
+		if _, err := io.WriteString(w, result.Format()); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	} else if err := utils.EncodeOutput(w, format, result); err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrBuildFailed, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// newArtifactInstaller returns the installer of the artifacts of config, caching next to it
+func newArtifactInstaller(config *providers.SupervizConfig, client *http.Client) *artifact.Installer {
+	return artifact.NewInstaller(filepath.Join(config.Dir, providers.DefaultArtifactCacheDir), &artifact.InstallerOptions{HTTPClient: client})
+}
+
+// buildSatisfied returns the provisioning hash of config and whether its lock
+// file records a build of it with every artifact still installed
+func buildSatisfied(config *providers.SupervizConfig, installer *artifact.Installer) (string, bool, error) {
+	hash, err := config.ProvisioningHash(runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", false, err
+	}
+	lock, err := providers.LoadBuildLock(filepath.Join(config.Dir, providers.DefaultBuildLockPath))
+	if err != nil || !lock.Satisfies(hash, runtime.GOOS, runtime.GOARCH) {
+		return hash, false, nil
+	}
+	return hash, installer.Installed(config.Artifacts), nil
+}
+
+// buildLock returns the lock recording a successful build
+func buildLock(hash string, report *artifact.Report) *providers.BuildLock {
+	lock := &providers.BuildLock{
+		Version: providers.BuildLockVersion, Hash: hash, OS: runtime.GOOS, Arch: runtime.GOARCH, BuiltAt: time.Now().UTC(),
+	}
+	for _, result := range report.Results {
+		lock.Artifacts = append(lock.Artifacts, providers.BuildLockArtifact{
+			Name: result.Name, Version: result.Version, Path: result.Path, SHA256: result.Digest,
+		})
+	}
+	return lock
+}
+
+// shortHash abbreviates a provisioning hash for display
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
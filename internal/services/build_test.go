@@ -0,0 +1,170 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildTestServer serves the tool artifact and counts the downloads
+func buildTestServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var downloads atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/tool-") {
+			http.NotFound(w, r)
+			return
+		}
+		downloads.Add(1)
+		_, _ = w.Write([]byte("echo tool\n")) //nolint:errcheck // test server
+	}))
+	t.Cleanup(server.Close)
+	return server, &downloads
+}
+
+// buildTestConfig writes a superviz.yaml installing the tool artifact and checking a file
+func buildTestConfig(t *testing.T, server *httptest.Server, version string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "superviz.yaml")
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(path), "app.conf"), []byte("conf"), 0o600))
+	writeBuildTestConfig(t, path, server, version)
+	return path
+}
+
+// writeBuildTestConfig writes the configuration of buildTestConfig at path
+func writeBuildTestConfig(t *testing.T, path string, server *httptest.Server, version string) {
+	t.Helper()
+	content := fmt.Sprintf(`artifacts:
+  tool:
+    version: %q
+    url: %s/{{name}}-{{version}}
+    path: bin/tool
+    sha256: %x
+integrity:
+  files: [{path: app.conf, sha256: %x}]
+services:
+  app: {command: sh, args: [bin/tool]}
+`, version, server.URL, sha256.Sum256([]byte("echo tool\n")), sha256.Sum256([]byte("conf")))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestBuildService_Build(t *testing.T) {
+	server, downloads := buildTestServer(t)
+	path := buildTestConfig(t, server, "1.0")
+	dir := filepath.Dir(path)
+	service := NewBuildService(&BuildServiceOptions{HTTPClient: server.Client()})
+
+	var out bytes.Buffer
+	require.NoError(t, service.Build(context.Background(), &out, path, utils.OutputText))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "artifact: tool 1.0: installed to "+filepath.Join(dir, "bin", "tool"), lines[0])
+	assert.Equal(t, "integrity: "+filepath.Join(dir, "app.conf")+": ok", lines[1])
+	assert.Regexp(t, `^build [0-9a-f]{12} recorded in `+regexp.QuoteMeta(filepath.Join(dir, ".superviz", "build.lock"))+`$`, lines[2])
+
+	lock, err := providers.LoadBuildLock(filepath.Join(dir, providers.DefaultBuildLockPath))
+	require.NoError(t, err)
+	assert.Equal(t, runtime.GOOS, lock.OS)
+	assert.Equal(t, []providers.BuildLockArtifact{{
+		Name: "tool", Version: "1.0", Path: filepath.Join(dir, "bin", "tool"), SHA256: fmt.Sprintf("%x", sha256.Sum256([]byte("echo tool\n"))),
+	}}, lock.Artifacts)
+
+	// An unchanged configuration is up to date
+	out.Reset()
+	require.NoError(t, service.Build(context.Background(), &out, path, utils.OutputText))
+	assert.Equal(t, "build "+lock.Hash[:12]+" is up to date\n", out.String())
+	assert.Equal(t, int32(1), downloads.Load())
+
+	// A removed artifact is installed again from the cache
+	require.NoError(t, os.Remove(filepath.Join(dir, "bin", "tool")))
+	out.Reset()
+	require.NoError(t, service.Build(context.Background(), &out, path, utils.OutputJSON))
+	var result BuildResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.False(t, result.UpToDate)
+	require.Len(t, result.Artifacts, 1)
+	assert.True(t, result.Artifacts[0].Cached)
+	assert.Equal(t, lock.Hash, result.Hash)
+
+	// A new version is a new build, its download taken from the cache when the content is the same
+	writeBuildTestConfig(t, path, server, "2.0")
+	out.Reset()
+	require.NoError(t, service.Build(context.Background(), &out, path, utils.OutputText))
+	assert.Contains(t, out.String(), "artifact: tool 2.0: installed to "+filepath.Join(dir, "bin", "tool")+" from cache\n")
+	assert.Equal(t, int32(1), downloads.Load())
+	updated, err := providers.LoadBuildLock(filepath.Join(dir, providers.DefaultBuildLockPath))
+	require.NoError(t, err)
+	assert.NotEqual(t, lock.Hash, updated.Hash)
+}
+
+func TestBuildService_Failure(t *testing.T) {
+	server, _ := buildTestServer(t)
+	path := buildTestConfig(t, server, "1.0")
+	dir := filepath.Dir(path)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.conf"), []byte("tampered"), 0o600))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(content), "/{{name}}", "/missing-{{name}}", 1)), 0o600))
+
+	var out bytes.Buffer
+	err = NewBuildService(&BuildServiceOptions{HTTPClient: server.Client()}).Build(context.Background(), &out, path, utils.OutputText)
+	require.ErrorIs(t, err, ErrBuildFailed)
+	assert.EqualError(t, err, "build failed: artifacts: 1 of 1 failed; integrity: 1 of 1 failed")
+	assert.Contains(t, out.String(), "integrity: "+filepath.Join(dir, "app.conf")+": sha256 mismatch")
+	assert.NoFileExists(t, filepath.Join(dir, providers.DefaultBuildLockPath), "a failed build is not recorded")
+
+	// Integrity failures with on_failure: alert do not fail the build
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(content), "integrity:\n", "integrity:\n  on_failure: alert\n", 1)), 0o600))
+	out.Reset()
+	require.NoError(t, NewBuildService(&BuildServiceOptions{HTTPClient: server.Client()}).Build(context.Background(), &out, path, utils.OutputText))
+	assert.Contains(t, out.String(), "sha256 mismatch")
+	assert.FileExists(t, filepath.Join(dir, providers.DefaultBuildLockPath))
+}
+
+func TestBuildService_Errors(t *testing.T) {
+	service := NewBuildService(nil)
+	require.ErrorIs(t, service.Build(context.Background(), nil, "superviz.yaml", utils.OutputText), ErrNilWriter)
+
+	err := service.Build(context.Background(), &bytes.Buffer{}, filepath.Join(t.TempDir(), "missing.yaml"), utils.OutputText)
+	assert.ErrorContains(t, err, "failed to read configuration")
+
+	path := filepath.Join(t.TempDir(), "superviz.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("services:\n  a: {command: x}\n"), 0o600))
+	assert.Error(t, service.Build(context.Background(), &bytes.Buffer{}, path, utils.OutputFormat("xml")))
+}
+
+func TestRunService_SkipsSatisfiedBuild(t *testing.T) {
+	server, downloads := buildTestServer(t)
+	path := writeSupervizConfig(t, "services: {}\n")
+	writeBuildTestConfig(t, path, server, "1.0")
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(path), "app.conf"), []byte("conf"), 0o600))
+	require.NoError(t, NewBuildService(&BuildServiceOptions{HTTPClient: server.Client()}).Build(context.Background(), &bytes.Buffer{}, path, utils.OutputText))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(content), "args: [bin/tool]", "args: [bin/tool], restart: never", 1)), 0o600))
+
+	var out lockedBuffer
+	var events bytes.Buffer
+	service := NewRunService(&RunServiceOptions{Stdout: &out, Stderr: &out, HTTPClient: server.Client()})
+	require.NoError(t, service.Run(context.Background(), &events, path, utils.OutputText))
+	assert.Regexp(t, `artifact: build [0-9a-f]{12} is up to date\n`, out.String(), "a change to the services keeps the build")
+	assert.Contains(t, out.String(), "tool\n")
+	assert.Equal(t, int32(1), downloads.Load())
+}
@@ -0,0 +1,91 @@
+// Package control exposes a running supervisor through a versioned HTTP API
+// served on a Unix socket and, optionally, on TCP with mutual TLS.
+//
+// Every path starts with the API version. Within a version, fields and
+// endpoints are only ever added; a breaking change gets a new version.
+//
+//	GET  /v1/info                              Info
+//	GET  /v1/services                          ServiceList
+//	GET  /v1/services/{name}                   supervisor.ServiceStatus
+//	POST /v1/services/{name}/start             supervisor.ServiceStatus
+//	POST /v1/services/{name}/stop              supervisor.ServiceStatus
+//	POST /v1/services/{name}/restart           supervisor.ServiceStatus
+//	POST /v1/services/{name}/signal            SignalRequest -> supervisor.ServiceStatus
+//	GET  /v1/services/{name}/logs?lines=&follow=  JSON lines of supervisor.LogLine
+//	POST /v1/reload?dry_run=                   ReloadResult
+//	GET  /v1/events?resource=&limit=           EventList
+//
+// Errors are returned as an ErrorResponse with a 4xx or 5xx status.
+package control
+
+import (
+	"fmt"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services/reconcile"
+	"github.com/kodflow/superviz.io/internal/services/supervisor"
+)
+
+// APIVersion is the version prefix of every path.
+const APIVersion = "v1"
+
+// Info describes the server.
+type Info struct {
+	// APIVersion is the API version served
+	APIVersion string `json:"api_version" yaml:"api_version"`
+	// Version is the svz version of the supervisor
+	Version string `json:"version" yaml:"version"`
+}
+
+// ServiceList is the response of GET /v1/services.
+type ServiceList struct {
+	// Services are the services in start order
+	Services []supervisor.ServiceStatus `json:"services" yaml:"services"`
+}
+
+// SignalRequest is the body of POST /v1/services/{name}/signal.
+type SignalRequest struct {
+	// Signal is a signal name such as HUP or SIGUSR1
+	Signal string `json:"signal" yaml:"signal"`
+}
+
+// ReloadResult is the response of POST /v1/reload.
+type ReloadResult struct {
+	// Message summarizes what the reload changed
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	// DryRun is set when the changes were computed but not applied
+	DryRun bool `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+	// Diff lists the changes, nil when the server does not report them
+	Diff *providers.ConfigDiff `json:"diff,omitempty" yaml:"diff,omitempty"`
+}
+
+// EventList is the response of GET /v1/events.
+type EventList struct {
+	// Events are the divergences and actions of the reconciler, oldest first
+	Events []reconcile.Event `json:"events" yaml:"events"`
+}
+
+// ErrorResponse is the body of a failed request.
+type ErrorResponse struct {
+	// Error describes the failure
+	Error string `json:"error" yaml:"error"`
+}
+
+// APIError is returned by the client for a failed request.
+type APIError struct {
+	// StatusCode is the HTTP status of the response
+	StatusCode int
+	// Message is the error returned by the server
+	Message string
+}
+
+// Error returns the server message.
+//
+// Returns:
+//   - message: string error returned by the server, or the HTTP status when it sent none
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("control API returned status %d", e.StatusCode)
+	}
+	return e.Message
+}
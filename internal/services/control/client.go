@@ -0,0 +1,330 @@
+// internal/services/control/client.go - Client of the control API used by svz ctl
+package control
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/kodflow/superviz.io/internal/services/reconcile"
+	"github.com/kodflow/superviz.io/internal/services/supervisor"
+)
+
+// ClientOptions selects how the client reaches the supervisor.
+//
+// The Unix socket is used unless Address is set, in which case the client
+// connects over TCP and authenticates with its certificate.
+type ClientOptions struct {
+	// Socket is the Unix socket path
+	Socket string
+	// Address is the host:port of the TCP listener
+	Address string
+	// Cert is the PEM client certificate for TCP
+	Cert string
+	// Key is the PEM client private key for TCP
+	Key string
+	// CA is the PEM bundle the server certificate must chain to, default the system roots
+	CA string
+	// ServerName overrides the name checked in the server certificate, default the address host
+	ServerName string
+}
+
+// Client calls the control API of a running supervisor.
+type Client struct {
+	// http sends the requests
+	http *http.Client
+	// base is the URL every path is appended to
+	base string
+}
+
+// NewClient creates a client for a socket or a TCP address.
+//
+// Parameters:
+//   - opts: *ClientOptions socket or TCP settings
+//
+// Returns:
+//   - client: *Client ready to call the API
+//   - err: error if neither a socket nor an address is set or the TLS files cannot be loaded
+func NewClient(opts *ClientOptions) (*Client, error) {
+	if opts == nil || (opts.Socket == "" && opts.Address == "") {
+		return nil, errors.New("a control socket or address is required")
+	}
+
+	if opts.Address == "" {
+		socket := opts.Socket
+		var dialer net.Dialer
+		transport := &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		}
+		return &Client{http: &http.Client{Transport: transport}, base: "http://superviz/" + APIVersion}, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS13, ServerName: opts.ServerName}
+	if opts.Cert != "" || opts.Key != "" {
+		cert, err := tls.LoadX509KeyPair(opts.Cert, opts.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if opts.CA != "" {
+		pool, err := loadCertPool(opts.CA)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport := &http.Transport{TLSClientConfig: tlsConfig, ForceAttemptHTTP2: true}
+	return &Client{http: &http.Client{Transport: transport}, base: "https://" + opts.Address + "/" + APIVersion}, nil
+}
+
+// Info returns the API and svz versions of the supervisor.
+//
+// Parameters:
+//   - ctx: context.Context for cancellation
+//
+// Returns:
+//   - info: Info server versions
+//   - err: error if the request fails
+func (c *Client) Info(ctx context.Context) (Info, error) {
+	var info Info
+	err := c.do(ctx, http.MethodGet, "/info", nil, &info)
+	return info, err
+}
+
+// List returns every service in start order.
+//
+// Parameters:
+//   - ctx: context.Context for cancellation
+//
+// Returns:
+//   - services: []supervisor.ServiceStatus current states
+//   - err: error if the request fails
+func (c *Client) List(ctx context.Context) ([]supervisor.ServiceStatus, error) {
+	var list ServiceList
+	err := c.do(ctx, http.MethodGet, "/services", nil, &list)
+	return list.Services, err
+}
+
+// Status returns one service.
+//
+// Parameters:
+//   - ctx: context.Context for cancellation
+//   - name: string service name
+//
+// Returns:
+//   - status: supervisor.ServiceStatus current state
+//   - err: error if the request fails or the service is unknown
+func (c *Client) Status(ctx context.Context, name string) (supervisor.ServiceStatus, error) {
+	return c.action(ctx, http.MethodGet, name, "", nil)
+}
+
+// Start starts a stopped service and returns its new status.
+//
+// Parameters:
+//   - ctx: context.Context for cancellation
+//   - name: string service name
+//
+// Returns:
+//   - status: supervisor.ServiceStatus state after the request
+//   - err: error if the service is unknown or already running
+func (c *Client) Start(ctx context.Context, name string) (supervisor.ServiceStatus, error) {
+	return c.action(ctx, http.MethodPost, name, "/start", nil)
+}
+
+// Stop stops a running service and returns its new status.
+//
+// Parameters:
+//   - ctx: context.Context for cancellation
+//   - name: string service name
+//
+// Returns:
+//   - status: supervisor.ServiceStatus state after the request
+//   - err: error if the service is unknown or not running
+func (c *Client) Stop(ctx context.Context, name string) (supervisor.ServiceStatus, error) {
+	return c.action(ctx, http.MethodPost, name, "/stop", nil)
+}
+
+// Restart stops a service if needed, starts it and returns its new status.
+//
+// Parameters:
+//   - ctx: context.Context for cancellation
+//   - name: string service name
+//
+// Returns:
+//   - status: supervisor.ServiceStatus state after the request
+//   - err: error if the service is unknown
+func (c *Client) Restart(ctx context.Context, name string) (supervisor.ServiceStatus, error) {
+	return c.action(ctx, http.MethodPost, name, "/restart", nil)
+}
+
+// Signal sends a signal to a running service and returns its status.
+//
+// Parameters:
+//   - ctx: context.Context for cancellation
+//   - name: string service name
+//   - signal: string signal name such as HUP
+//
+// Returns:
+//   - status: supervisor.ServiceStatus state after the request
+//   - err: error if the service is unknown or not running, or the signal is unsupported
+func (c *Client) Signal(ctx context.Context, name, signal string) (supervisor.ServiceStatus, error) {
+	return c.action(ctx, http.MethodPost, name, "/signal", SignalRequest{Signal: signal})
+}
+
+// Logs reads the last output lines of a service and, when following, every new line.
+//
+// Parameters:
+//   - ctx: context.Context whose cancellation ends a followed stream
+//   - name: string service name
+//   - lines: int number of past lines, every kept line when 0
+//   - follow: bool keeps reading new lines until ctx is done or the supervisor stops
+//   - fn: func(supervisor.LogLine) error called for each line, an error ends the stream
+//
+// Returns:
+//   - err: error if the request fails or fn returns one
+func (c *Client) Logs(ctx context.Context, name string, lines int, follow bool, fn func(supervisor.LogLine) error) error {
+	query := url.Values{}
+	if lines > 0 {
+		query.Set("lines", strconv.Itoa(lines))
+	}
+	if follow {
+		query.Set("follow", "true")
+	}
+	path := "/services/" + url.PathEscape(name) + "/logs"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	resp, err := c.send(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // the body is only read
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for scanner.Scan() {
+		var line supervisor.LogLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return fmt.Errorf("malformed log line: %w", err)
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read logs: %w", err)
+	}
+	return nil
+}
+
+// Reload asks the supervisor to apply its configuration again.
+//
+// Parameters:
+//   - ctx: context.Context for cancellation
+//   - dryRun: bool only computes the changes, nothing is applied
+//
+// Returns:
+//   - result: ReloadResult summary of the changes
+//   - err: error if the configuration is invalid or reload is unsupported
+func (c *Client) Reload(ctx context.Context, dryRun bool) (ReloadResult, error) {
+	path := "/reload"
+	if dryRun {
+		path += "?dry_run=true"
+	}
+	var result ReloadResult
+	err := c.do(ctx, http.MethodPost, path, nil, &result)
+	return result, err
+}
+
+// Events reads the latest reconciliation events.
+//
+// Parameters:
+//   - ctx: context.Context for cancellation
+//   - resource: string resource name such as service/web, every resource when empty
+//   - limit: int maximum number of events, every kept event when 0
+//
+// Returns:
+//   - events: []reconcile.Event divergences and actions, oldest first
+//   - err: error if the request fails or reconciliation is not enabled
+func (c *Client) Events(ctx context.Context, resource string, limit int) ([]reconcile.Event, error) {
+	query := url.Values{}
+	if resource != "" {
+		query.Set("resource", resource)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	path := "/events"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	var list EventList
+	if err := c.do(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return nil, err
+	}
+	return list.Events, nil
+}
+
+// action calls a service endpoint answering with the service status
+func (c *Client) action(ctx context.Context, method, name, suffix string, body any) (supervisor.ServiceStatus, error) {
+	var status supervisor.ServiceStatus
+	err := c.do(ctx, method, "/services/"+url.PathEscape(name)+suffix, body, &status)
+	return status, err
+}
+
+// do sends a request and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	resp, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // the body is only read
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("malformed control API response: %w", err)
+	}
+	return nil
+}
+
+// send sends a request and turns error statuses into an APIError
+func (c *Client) send(ctx context.Context, method, path string, body any) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the supervisor: %w", err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close() //nolint:errcheck // the body is only read
+		var apiErr ErrorResponse
+		_ = json.NewDecoder(io.LimitReader(resp.Body, maxRequestBody)).Decode(&apiErr) //nolint:errcheck // the status is enough
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: apiErr.Error}
+	}
+	return resp, nil
+}
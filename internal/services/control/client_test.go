@@ -0,0 +1,207 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services/reconcile"
+	"github.com/kodflow/superviz.io/internal/services/supervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// socketPath returns a socket path short enough for the sun_path limit
+func socketPath(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "svz")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return filepath.Join(dir, "ctl.sock")
+}
+
+// serveInBackground serves server on listeners until the test ends
+func serveInBackground(t *testing.T, server *Server, listeners ...net.Listener) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, listeners...) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+}
+
+// unixClient serves backend on a fresh socket and returns a client of it
+func unixClient(t *testing.T, backend Backend, opts *ServerOptions) *Client {
+	t.Helper()
+	config := &providers.ControlConfig{Socket: socketPath(t), SocketMode: providers.DefaultControlSocketMode}
+	listener, err := ListenUnix(config)
+	require.NoError(t, err)
+	serveInBackground(t, NewServer(backend, opts), listener)
+
+	client, err := NewClient(&ClientOptions{Socket: config.Socket})
+	require.NoError(t, err)
+	return client
+}
+
+func TestNewClient_RequiresTarget(t *testing.T) {
+	_, err := NewClient(nil)
+	require.Error(t, err)
+	_, err = NewClient(&ClientOptions{})
+	require.Error(t, err)
+
+	_, err = NewClient(&ClientOptions{Address: "127.0.0.1:1", Cert: "missing.pem", Key: "missing.pem"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load client certificate")
+}
+
+func TestClient_Operations(t *testing.T) {
+	backend := newFakeBackend()
+	reload := func(_ context.Context, dryRun bool) (ReloadResult, error) {
+		return ReloadResult{Message: fmt.Sprintf("nothing changed, dry run %t", dryRun)}, nil
+	}
+	client := unixClient(t, backend, &ServerOptions{Version: "1.2.3", Reload: reload})
+	ctx := context.Background()
+
+	info, err := client.Info(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Info{APIVersion: APIVersion, Version: "1.2.3"}, info)
+
+	services, err := client.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, services, 2)
+	assert.Equal(t, 42, services[0].PID)
+
+	status, err := client.Start(ctx, "worker")
+	require.NoError(t, err)
+	assert.Equal(t, supervisor.StateRunning, status.State)
+
+	status, err = client.Stop(ctx, "worker")
+	require.NoError(t, err)
+	assert.Equal(t, supervisor.StateStopped, status.State)
+
+	status, err = client.Restart(ctx, "web")
+	require.NoError(t, err)
+	assert.Equal(t, 8, status.PID)
+
+	_, err = client.Signal(ctx, "web", "USR1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web:USR1"}, backend.signals)
+
+	status, err = client.Status(ctx, "web")
+	require.NoError(t, err)
+	assert.Equal(t, "web", status.Name)
+
+	result, err := client.Reload(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, "nothing changed, dry run false", result.Message)
+	assert.False(t, result.DryRun)
+
+	result, err = client.Reload(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, "nothing changed, dry run true", result.Message)
+	assert.True(t, result.DryRun)
+}
+
+func TestClient_Events(t *testing.T) {
+	events := func(resource string, limit int) []reconcile.Event {
+		return []reconcile.Event{{Resource: resource, Kind: reconcile.EventAction, Message: fmt.Sprintf("limit %d", limit)}}
+	}
+	client := unixClient(t, newFakeBackend(), &ServerOptions{Events: events})
+
+	list, err := client.Events(context.Background(), "artifact/jq", 3)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "artifact/jq: action: limit 3", list[0].Format())
+
+	_, err = unixClient(t, newFakeBackend(), nil).Events(context.Background(), "", 0)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotImplemented, apiErr.StatusCode)
+}
+
+func TestClient_APIError(t *testing.T) {
+	client := unixClient(t, newFakeBackend(), nil)
+
+	_, err := client.Stop(context.Background(), "nope")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "unknown service: nope", err.Error())
+
+	_, err = client.Reload(context.Background(), false)
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotImplemented, apiErr.StatusCode)
+
+	assert.Equal(t, "control API returned status 502", (&APIError{StatusCode: http.StatusBadGateway}).Error())
+}
+
+func TestClient_Unreachable(t *testing.T) {
+	client, err := NewClient(&ClientOptions{Socket: filepath.Join(t.TempDir(), "missing.sock")})
+	require.NoError(t, err)
+
+	_, err = client.List(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to reach the supervisor")
+}
+
+func TestClient_Logs(t *testing.T) {
+	client := unixClient(t, newFakeBackend(), nil)
+
+	var texts []string
+	err := client.Logs(context.Background(), "web", 1, false, func(line supervisor.LogLine) error {
+		texts = append(texts, line.Text)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"two"}, texts)
+
+	stop := errors.New("stop")
+	err = client.Logs(context.Background(), "web", 0, false, func(supervisor.LogLine) error { return stop })
+	require.ErrorIs(t, err, stop)
+
+	err = client.Logs(context.Background(), "nope", 0, false, func(supervisor.LogLine) error { return nil })
+	require.Error(t, err)
+}
+
+func TestClient_FollowLogs(t *testing.T) {
+	backend := newFakeBackend()
+	client := unixClient(t, backend, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lines := make(chan string, 8)
+	done := make(chan error, 1)
+	go func() {
+		done <- client.Logs(ctx, "web", 0, true, func(line supervisor.LogLine) error {
+			lines <- line.Text
+			return nil
+		})
+	}()
+
+	assert.Equal(t, "one", <-lines)
+	assert.Equal(t, "two", <-lines)
+	backend.follow <- supervisor.LogLine{Service: "web", Stream: supervisor.StreamStdout, Text: "three"}
+	select {
+	case text := <-lines:
+		assert.Equal(t, "three", text)
+	case <-time.After(5 * time.Second):
+		t.Fatal("followed line not received")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err, "cancelling the context ends the stream cleanly")
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not end")
+	}
+}
@@ -0,0 +1,105 @@
+// internal/services/control/listen.go - Unix socket and mutual TLS listeners of the control API
+package control
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+// staleProbeTimeout bounds the connection attempt telling a stale socket from a live one
+const staleProbeTimeout = time.Second
+
+// ListenUnix listens on a Unix socket restricted by its permissions.
+//
+// A socket left behind by a supervisor that did not exit cleanly is
+// replaced; a socket another supervisor still answers on is an error. The
+// socket is removed when the listener is closed.
+//
+// Parameters:
+//   - config: *providers.ControlConfig socket path, mode and group
+//
+// Returns:
+//   - listener: net.Listener accepting local clients
+//   - err: error if the path is in use or the permissions cannot be applied
+func ListenUnix(config *providers.ControlConfig) (net.Listener, error) {
+	path := config.Socket
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("control socket %s exists and is not a socket", path)
+		}
+		if conn, err := net.DialTimeout("unix", path, staleProbeTimeout); err == nil {
+			_ = conn.Close() //nolint:errcheck // only probing
+			return nil, fmt.Errorf("control socket %s is in use by another supervisor", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale control socket: %w", err)
+		}
+	}
+
+	listener, err := listenOwnerOnly(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on control socket: %w", err)
+	}
+	if err := os.Chmod(path, os.FileMode(config.SocketMode)); err != nil {
+		_ = listener.Close() //nolint:errcheck // already failing
+		return nil, fmt.Errorf("failed to set control socket mode: %w", err)
+	}
+	if config.SocketGroup != "" {
+		if err := chownGroup(path, config.SocketGroup); err != nil {
+			_ = listener.Close() //nolint:errcheck // already failing
+			return nil, fmt.Errorf("failed to set control socket group: %w", err)
+		}
+	}
+	return listener, nil
+}
+
+// ListenTLS listens on TCP and requires client certificates signed by the client CA.
+//
+// Parameters:
+//   - config: *providers.ControlTCPConfig address and PEM files
+//
+// Returns:
+//   - listener: net.Listener accepting authenticated clients
+//   - err: error if the files cannot be loaded or the address is in use
+func ListenTLS(config *providers.ControlTCPConfig) (net.Listener, error) {
+	cert, err := tls.LoadX509KeyPair(config.Cert, config.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load control certificate: %w", err)
+	}
+	clientCAs, err := loadCertPool(config.ClientCA)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := tls.Listen("tcp", config.Address, &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS13,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", config.Address, err)
+	}
+	return listener, nil
+}
+
+// loadCertPool reads a PEM bundle of CA certificates
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // the path comes from the operator's configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New("CA bundle " + path + " contains no PEM certificate")
+	}
+	return pool, nil
+}
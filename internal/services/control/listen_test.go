@@ -0,0 +1,204 @@
+package control
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA signs certificates for the mutual TLS tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+}
+
+// newTestCA creates a CA and writes its certificate to ca.pem
+func newTestCA(t *testing.T, dir string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "superviz test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "ca.pem"), "CERTIFICATE", der)
+	return &testCA{cert: cert, key: key, dir: dir}
+}
+
+// issue writes a leaf certificate and key named name.pem and name-key.pem
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) (certPath, keyPath string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath = filepath.Join(ca.dir, name+".pem")
+	keyPath = filepath.Join(ca.dir, name+"-key.pem")
+	writePEM(t, certPath, "CERTIFICATE", der)
+	writePEM(t, keyPath, "EC PRIVATE KEY", keyDER)
+	return certPath, keyPath
+}
+
+// writePEM writes one PEM block to path
+func writePEM(t *testing.T, path, kind string, der []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0o600))
+}
+
+func TestListenUnix_SocketMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("socket permissions are a Unix feature")
+	}
+	config := &providers.ControlConfig{Socket: socketPath(t), SocketMode: 0o660}
+	listener, err := ListenUnix(config)
+	require.NoError(t, err)
+
+	info, err := os.Stat(config.Socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o660), info.Mode().Perm())
+
+	require.NoError(t, listener.Close())
+	_, err = os.Stat(config.Socket)
+	assert.True(t, os.IsNotExist(err), "closing the listener removes the socket")
+}
+
+func TestListenOwnerOnly(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("socket permissions are a Unix feature")
+	}
+	path := socketPath(t)
+	listener, err := listenOwnerOnly(path)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "the socket is private until its mode is applied")
+}
+
+func TestListenUnix_SocketGroup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("socket groups are a Unix feature")
+	}
+	config := &providers.ControlConfig{Socket: socketPath(t), SocketMode: 0o660, SocketGroup: "no-such-group-for-superviz"}
+	_, err := ListenUnix(config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to set control socket group")
+	_, err = os.Stat(config.Socket)
+	assert.True(t, os.IsNotExist(err), "a failed listener leaves no socket behind")
+}
+
+func TestListenUnix_InUse(t *testing.T) {
+	config := &providers.ControlConfig{Socket: socketPath(t), SocketMode: providers.DefaultControlSocketMode}
+	listener, err := ListenUnix(config)
+	require.NoError(t, err)
+	defer listener.Close() //nolint:errcheck // test cleanup
+
+	_, err = ListenUnix(config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is in use by another supervisor")
+}
+
+func TestListenUnix_StaleSocket(t *testing.T) {
+	config := &providers.ControlConfig{Socket: socketPath(t), SocketMode: providers.DefaultControlSocketMode}
+	stale, err := net.Listen("unix", config.Socket)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	listener, err := ListenUnix(config)
+	require.NoError(t, err, "a socket nobody answers on is replaced")
+	require.NoError(t, listener.Close())
+}
+
+func TestListenUnix_NotASocket(t *testing.T) {
+	config := &providers.ControlConfig{Socket: socketPath(t), SocketMode: providers.DefaultControlSocketMode}
+	require.NoError(t, os.WriteFile(config.Socket, []byte("data"), 0o600))
+
+	_, err := ListenUnix(config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exists and is not a socket")
+}
+
+func TestListenTLS_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	serverCert, serverKey := ca.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+
+	listener, err := ListenTLS(&providers.ControlTCPConfig{
+		Address:  "127.0.0.1:0",
+		Cert:     serverCert,
+		Key:      serverKey,
+		ClientCA: filepath.Join(dir, "ca.pem"),
+	})
+	require.NoError(t, err)
+	serveInBackground(t, NewServer(newFakeBackend(), &ServerOptions{Version: "1.2.3"}), listener)
+	address := listener.Addr().String()
+
+	client, err := NewClient(&ClientOptions{Address: address, Cert: clientCert, Key: clientKey, CA: filepath.Join(dir, "ca.pem")})
+	require.NoError(t, err)
+	info, err := client.Info(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3", info.Version)
+
+	anonymous, err := NewClient(&ClientOptions{Address: address, CA: filepath.Join(dir, "ca.pem")})
+	require.NoError(t, err)
+	_, err = anonymous.Info(context.Background())
+	require.Error(t, err, "a client without certificate is rejected")
+}
+
+func TestListenTLS_InvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	serverCert, serverKey := ca.issue(t, "server", x509.ExtKeyUsageServerAuth)
+
+	_, err := ListenTLS(&providers.ControlTCPConfig{Address: "127.0.0.1:0", Cert: "missing.pem", Key: "missing.pem"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load control certificate")
+
+	_, err = ListenTLS(&providers.ControlTCPConfig{Address: "127.0.0.1:0", Cert: serverCert, Key: serverKey, ClientCA: serverKey})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "contains no PEM certificate")
+
+	_, err = ListenTLS(&providers.ControlTCPConfig{Address: "127.0.0.1:0", Cert: serverCert, Key: serverKey, ClientCA: filepath.Join(dir, "missing.pem")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read CA bundle")
+}
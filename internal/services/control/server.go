@@ -0,0 +1,336 @@
+// internal/services/control/server.go - HTTP handlers of the control API
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/services/reconcile"
+	"github.com/kodflow/superviz.io/internal/services/supervisor"
+)
+
+const (
+	// readHeaderTimeout bounds how long a client may take to send its headers
+	readHeaderTimeout = 10 * time.Second
+	// shutdownTimeout bounds how long pending requests may take once the server stops
+	shutdownTimeout = 5 * time.Second
+	// maxRequestBody bounds the request bodies
+	maxRequestBody = 64 << 10
+)
+
+// ErrReloadUnsupported is returned by POST /v1/reload when the server has no reload function.
+var ErrReloadUnsupported = errors.New("reload is not supported by this supervisor")
+
+// ErrEventsUnsupported is returned by GET /v1/events when the server has no events function.
+var ErrEventsUnsupported = errors.New("reconciliation is not enabled on this supervisor")
+
+// Backend is the supervisor driven by the API.
+type Backend interface {
+	// Services returns every service in start order
+	Services() []supervisor.ServiceStatus
+	// Service returns one service
+	Service(name string) (supervisor.ServiceStatus, error)
+	// Start starts a stopped service
+	Start(name string) error
+	// Stop stops a running service
+	Stop(name string) error
+	// Restart stops a service if needed, then starts it
+	Restart(name string) error
+	// SignalService sends a signal to a running service
+	SignalService(name, signal string) error
+	// Logs returns the last output lines of a service
+	Logs(name string, lines int) ([]supervisor.LogLine, error)
+	// FollowLogs streams the last output lines of a service, then the new ones until ctx is done
+	FollowLogs(ctx context.Context, name string, lines int) (<-chan supervisor.LogLine, error)
+}
+
+// ServerOptions configures a Server.
+//
+// All fields are optional.
+type ServerOptions struct {
+	// Version is reported by GET /v1/info
+	Version string
+	// Reload applies the configuration again, or only computes the changes
+	// on a dry run; POST /v1/reload answers 501 when nil
+	Reload func(ctx context.Context, dryRun bool) (ReloadResult, error)
+	// Events returns the latest reconciliation events of a resource, every
+	// resource when empty; GET /v1/events answers 501 when nil
+	Events func(resource string, limit int) []reconcile.Event
+}
+
+// Server serves the control API of a backend.
+type Server struct {
+	// backend is the supervisor driven
+	backend Backend
+	// version is reported by GET /v1/info
+	version string
+	// reload applies the configuration again, nil when unsupported
+	reload func(ctx context.Context, dryRun bool) (ReloadResult, error)
+	// events returns the reconciliation events, nil when unsupported
+	events func(resource string, limit int) []reconcile.Event
+	// mux routes the requests
+	mux *http.ServeMux
+}
+
+// NewServer creates the API server of a backend.
+//
+// Parameters:
+//   - backend: Backend supervisor driven by the API
+//   - opts: *ServerOptions overrides, nil for defaults
+//
+// Returns:
+//   - server: *Server ready to Serve
+func NewServer(backend Backend, opts *ServerOptions) *Server {
+	if opts == nil {
+		opts = &ServerOptions{}
+	}
+	s := &Server{backend: backend, version: opts.Version, reload: opts.Reload, events: opts.Events, mux: http.NewServeMux()}
+
+	prefix := "/" + APIVersion
+	s.mux.HandleFunc("GET "+prefix+"/info", s.handleInfo)
+	s.mux.HandleFunc("GET "+prefix+"/services", s.handleList)
+	s.mux.HandleFunc("GET "+prefix+"/services/{name}", s.handleStatus)
+	s.mux.HandleFunc("POST "+prefix+"/services/{name}/start", s.handleAction(backend.Start))
+	s.mux.HandleFunc("POST "+prefix+"/services/{name}/stop", s.handleAction(backend.Stop))
+	s.mux.HandleFunc("POST "+prefix+"/services/{name}/restart", s.handleAction(backend.Restart))
+	s.mux.HandleFunc("POST "+prefix+"/services/{name}/signal", s.handleSignal)
+	s.mux.HandleFunc("GET "+prefix+"/services/{name}/logs", s.handleLogs)
+	s.mux.HandleFunc("POST "+prefix+"/reload", s.handleReload)
+	s.mux.HandleFunc("GET "+prefix+"/events", s.handleEvents)
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "unknown endpoint "+r.Method+" "+r.URL.Path)
+	})
+	return s
+}
+
+// ServeHTTP routes a request.
+//
+// Parameters:
+//   - w: http.ResponseWriter response
+//   - r: *http.Request request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Serve answers requests on every listener until ctx is done.
+//
+// Cancelling ctx ends the log streams, lets pending requests finish for a
+// few seconds and closes the listeners.
+//
+// Parameters:
+//   - ctx: context.Context stopping the server
+//   - listeners: ...net.Listener from ListenUnix or ListenTLS
+//
+// Returns:
+//   - err: error if a listener failed before ctx was done
+func (s *Server) Serve(ctx context.Context, listeners ...net.Listener) error {
+	srv := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: readHeaderTimeout,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	failed := make(chan error, len(listeners))
+	var wg sync.WaitGroup
+	for _, l := range listeners {
+		wg.Add(1)
+		go func(l net.Listener) {
+			defer wg.Done()
+			if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				failed <- err
+			}
+		}(l)
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+	case err = <-failed:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if srv.Shutdown(shutdownCtx) != nil {
+		_ = srv.Close() //nolint:errcheck // the server is going away
+	}
+	wg.Wait()
+	return err
+}
+
+// handleInfo answers GET /v1/info
+func (s *Server) handleInfo(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, Info{APIVersion: APIVersion, Version: s.version})
+}
+
+// handleList answers GET /v1/services
+func (s *Server) handleList(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, ServiceList{Services: s.backend.Services()})
+}
+
+// handleStatus answers GET /v1/services/{name}
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.writeStatus(w, r.PathValue("name"))
+}
+
+// handleAction answers the start, stop and restart endpoints with the resulting status
+func (s *Server) handleAction(action func(name string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		if err := action(name); err != nil {
+			writeBackendError(w, err)
+			return
+		}
+		s.writeStatus(w, name)
+	}
+}
+
+// handleSignal answers POST /v1/services/{name}/signal
+func (s *Server) handleSignal(w http.ResponseWriter, r *http.Request) {
+	var req SignalRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody)).Decode(&req); err != nil || req.Signal == "" {
+		writeError(w, http.StatusBadRequest, "request body must be a JSON object with a signal")
+		return
+	}
+	name := r.PathValue("name")
+	if err := s.backend.SignalService(name, req.Signal); err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	s.writeStatus(w, name)
+}
+
+// handleLogs answers GET /v1/services/{name}/logs with one JSON line per log line
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	query := r.URL.Query()
+	lines := 0
+	if value := query.Get("lines"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "lines must be a non-negative integer")
+			return
+		}
+		lines = n
+	}
+	follow, _ := strconv.ParseBool(query.Get("follow")) //nolint:errcheck // anything but a true value disables following
+
+	if !follow {
+		tail, err := s.backend.Logs(name, lines)
+		if err != nil {
+			writeBackendError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		encoder := json.NewEncoder(w)
+		for _, line := range tail {
+			if encoder.Encode(line) != nil {
+				return
+			}
+		}
+		return
+	}
+
+	stream, err := s.backend.FollowLogs(r.Context(), name, lines)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+	encoder := json.NewEncoder(w)
+	for line := range stream {
+		if encoder.Encode(line) != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// handleReload answers POST /v1/reload
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if s.reload == nil {
+		writeError(w, http.StatusNotImplemented, ErrReloadUnsupported.Error())
+		return
+	}
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run")) //nolint:errcheck // anything but a true value applies the changes
+	result, err := s.reload(r.Context(), dryRun)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	result.DryRun = dryRun
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleEvents answers GET /v1/events
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if s.events == nil {
+		writeError(w, http.StatusNotImplemented, ErrEventsUnsupported.Error())
+		return
+	}
+	query := r.URL.Query()
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "limit must be a non-negative integer")
+			return
+		}
+		limit = n
+	}
+	events := s.events(query.Get("resource"), limit)
+	if events == nil {
+		events = []reconcile.Event{}
+	}
+	writeJSON(w, http.StatusOK, EventList{Events: events})
+}
+
+// writeStatus writes the current status of a service
+func (s *Server) writeStatus(w http.ResponseWriter, name string) {
+	status, err := s.backend.Service(name)
+	if err != nil {
+		writeBackendError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// writeBackendError maps a supervisor error to its HTTP status
+func writeBackendError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, supervisor.ErrUnknownService):
+		code = http.StatusNotFound
+	case errors.Is(err, supervisor.ErrServiceActive), errors.Is(err, supervisor.ErrServiceNotActive):
+		code = http.StatusConflict
+	case errors.Is(err, supervisor.ErrUnsupportedSignal):
+		code = http.StatusBadRequest
+	case errors.Is(err, supervisor.ErrSupervisorStopped):
+		code = http.StatusServiceUnavailable
+	}
+	writeError(w, code, err.Error())
+}
+
+// writeError writes an ErrorResponse
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, ErrorResponse{Error: message})
+}
+
+// writeJSON writes v as the JSON response body
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v) //nolint:errcheck // the client may be gone
+}
@@ -0,0 +1,326 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services/reconcile"
+	"github.com/kodflow/superviz.io/internal/services/supervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackend is an in-memory supervisor whose services start and stop instantly
+type fakeBackend struct {
+	mu       sync.Mutex
+	services []supervisor.ServiceStatus
+	signals  []string
+	logs     []supervisor.LogLine
+	follow   chan supervisor.LogLine
+}
+
+// newFakeBackend returns a backend with a running web and a stopped worker
+func newFakeBackend() *fakeBackend {
+	since := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return &fakeBackend{
+		services: []supervisor.ServiceStatus{
+			{Name: "web", State: supervisor.StateRunning, PID: 42, Since: since},
+			{Name: "worker", State: supervisor.StateStopped, Since: since},
+		},
+		logs: []supervisor.LogLine{
+			{Time: since, Service: "web", Stream: supervisor.StreamStdout, Text: "one"},
+			{Time: since, Service: "web", Stream: supervisor.StreamStderr, Text: "two"},
+		},
+		follow: make(chan supervisor.LogLine, 1),
+	}
+}
+
+func (b *fakeBackend) Services() []supervisor.ServiceStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]supervisor.ServiceStatus(nil), b.services...)
+}
+
+func (b *fakeBackend) Service(name string) (supervisor.ServiceStatus, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, status := range b.services {
+		if status.Name == name {
+			return status, nil
+		}
+	}
+	return supervisor.ServiceStatus{}, fmt.Errorf("%w: %s", supervisor.ErrUnknownService, name)
+}
+
+// set changes the state of a service, failing when it already is in a state of from
+func (b *fakeBackend) set(name string, state supervisor.State, pid int, conflict error, from ...supervisor.State) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := range b.services {
+		if b.services[i].Name != name {
+			continue
+		}
+		for _, s := range from {
+			if b.services[i].State == s {
+				return fmt.Errorf("%w: %s", conflict, name)
+			}
+		}
+		b.services[i].State, b.services[i].PID = state, pid
+		return nil
+	}
+	return fmt.Errorf("%w: %s", supervisor.ErrUnknownService, name)
+}
+
+func (b *fakeBackend) Start(name string) error {
+	return b.set(name, supervisor.StateRunning, 7, supervisor.ErrServiceActive, supervisor.StateRunning)
+}
+
+func (b *fakeBackend) Stop(name string) error {
+	return b.set(name, supervisor.StateStopped, 0, supervisor.ErrServiceNotActive, supervisor.StateStopped)
+}
+
+func (b *fakeBackend) Restart(name string) error {
+	return b.set(name, supervisor.StateRunning, 8, nil)
+}
+
+func (b *fakeBackend) SignalService(name, signal string) error {
+	if _, err := b.Service(name); err != nil {
+		return err
+	}
+	if signal == "BOGUS" {
+		return fmt.Errorf("%w: %s", supervisor.ErrUnsupportedSignal, signal)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.signals = append(b.signals, name+":"+signal)
+	return nil
+}
+
+func (b *fakeBackend) Logs(name string, lines int) ([]supervisor.LogLine, error) {
+	if _, err := b.Service(name); err != nil {
+		return nil, err
+	}
+	if lines > 0 && lines < len(b.logs) {
+		return b.logs[len(b.logs)-lines:], nil
+	}
+	return b.logs, nil
+}
+
+func (b *fakeBackend) FollowLogs(ctx context.Context, name string, lines int) (<-chan supervisor.LogLine, error) {
+	tail, err := b.Logs(name, lines)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan supervisor.LogLine)
+	go func() {
+		defer close(out)
+		for _, line := range tail {
+			out <- line
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case line := <-b.follow:
+				select {
+				case out <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// serve sends a request to server and returns the recorded response
+func serve(t *testing.T, server *Server, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	return rec
+}
+
+// decodeError returns the error message of a failed response
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return resp.Error
+}
+
+func TestServer_Info(t *testing.T) {
+	server := NewServer(newFakeBackend(), &ServerOptions{Version: "1.2.3"})
+	rec := serve(t, server, http.MethodGet, "/v1/info", "")
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"api_version":"v1","version":"1.2.3"}`, rec.Body.String())
+}
+
+func TestServer_ListAndStatus(t *testing.T) {
+	server := NewServer(newFakeBackend(), nil)
+
+	rec := serve(t, server, http.MethodGet, "/v1/services", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list ServiceList
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Services, 2)
+	assert.Equal(t, "web", list.Services[0].Name)
+
+	rec = serve(t, server, http.MethodGet, "/v1/services/worker", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"state":"stopped"`)
+
+	rec = serve(t, server, http.MethodGet, "/v1/services/nope", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "unknown service: nope", decodeError(t, rec))
+}
+
+func TestServer_Actions(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		code int
+		want string
+	}{
+		{"start stopped", "/v1/services/worker/start", http.StatusOK, `"state":"running"`},
+		{"start running", "/v1/services/web/start", http.StatusConflict, "service is already running: web"},
+		{"stop running", "/v1/services/web/stop", http.StatusOK, `"state":"stopped"`},
+		{"stop stopped", "/v1/services/worker/stop", http.StatusConflict, "service is not running: worker"},
+		{"restart", "/v1/services/web/restart", http.StatusOK, `"pid":8`},
+		{"unknown", "/v1/services/nope/restart", http.StatusNotFound, "unknown service: nope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(newFakeBackend(), nil)
+			rec := serve(t, server, http.MethodPost, tt.path, "")
+			assert.Equal(t, tt.code, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.want)
+		})
+	}
+}
+
+func TestServer_Signal(t *testing.T) {
+	backend := newFakeBackend()
+	server := NewServer(backend, nil)
+
+	rec := serve(t, server, http.MethodPost, "/v1/services/web/signal", `{"signal":"HUP"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"web:HUP"}, backend.signals)
+
+	rec = serve(t, server, http.MethodPost, "/v1/services/web/signal", `{"signal":"BOGUS"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(t, server, http.MethodPost, "/v1/services/web/signal", `not json`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "request body must be a JSON object with a signal", decodeError(t, rec))
+
+	rec = serve(t, server, http.MethodPost, "/v1/services/web/signal", `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestServer_Logs(t *testing.T) {
+	server := NewServer(newFakeBackend(), nil)
+
+	rec := serve(t, server, http.MethodGet, "/v1/services/web/logs", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[1], `"text":"two"`)
+
+	rec = serve(t, server, http.MethodGet, "/v1/services/web/logs?lines=1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, strings.Count(rec.Body.String(), "\n"))
+
+	rec = serve(t, server, http.MethodGet, "/v1/services/web/logs?lines=-1", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(t, server, http.MethodGet, "/v1/services/nope/logs?follow=true", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_Reload(t *testing.T) {
+	rec := serve(t, NewServer(newFakeBackend(), nil), http.MethodPost, "/v1/reload", "")
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+	assert.Equal(t, ErrReloadUnsupported.Error(), decodeError(t, rec))
+
+	var dryRuns []bool
+	reload := func(_ context.Context, dryRun bool) (ReloadResult, error) {
+		dryRuns = append(dryRuns, dryRun)
+		return ReloadResult{Message: "1 restarted", Diff: &providers.ConfigDiff{
+			Restart: []providers.ServiceChange{{Service: "web", Fields: []string{"command"}}},
+		}}, nil
+	}
+	rec = serve(t, NewServer(newFakeBackend(), &ServerOptions{Reload: reload}), http.MethodPost, "/v1/reload", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"message":"1 restarted","diff":{"restart":[{"service":"web","fields":["command"]}]}}`, rec.Body.String())
+
+	rec = serve(t, NewServer(newFakeBackend(), &ServerOptions{Reload: reload}), http.MethodPost, "/v1/reload?dry_run=true", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"dry_run":true`)
+	assert.Equal(t, []bool{false, true}, dryRuns)
+
+	failing := func(context.Context, bool) (ReloadResult, error) {
+		return ReloadResult{}, fmt.Errorf("invalid configuration")
+	}
+	rec = serve(t, NewServer(newFakeBackend(), &ServerOptions{Reload: failing}), http.MethodPost, "/v1/reload", "")
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, "invalid configuration", decodeError(t, rec))
+}
+
+func TestServer_Events(t *testing.T) {
+	rec := serve(t, NewServer(newFakeBackend(), nil), http.MethodGet, "/v1/events", "")
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+	assert.Equal(t, ErrEventsUnsupported.Error(), decodeError(t, rec))
+
+	var queries []string
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := func(resource string, limit int) []reconcile.Event {
+		queries = append(queries, fmt.Sprintf("%s/%d", resource, limit))
+		if resource == "service/none" {
+			return nil
+		}
+		return []reconcile.Event{{Time: at, Resource: "service/web", Kind: reconcile.EventDrift, Message: "service is fatal"}}
+	}
+	server := NewServer(newFakeBackend(), &ServerOptions{Events: events})
+	rec = serve(t, server, http.MethodGet, "/v1/events?resource=service/web&limit=5", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"events":[{"time":"2026-01-01T00:00:00Z","resource":"service/web","kind":"drift","message":"service is fatal"}]}`, rec.Body.String())
+
+	rec = serve(t, server, http.MethodGet, "/v1/events?resource=service/none", "")
+	assert.JSONEq(t, `{"events":[]}`, rec.Body.String())
+	assert.Equal(t, []string{"service/web/5", "service/none/0"}, queries)
+
+	rec = serve(t, server, http.MethodGet, "/v1/events?limit=-1", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "limit must be a non-negative integer", decodeError(t, rec))
+}
+
+func TestServer_UnknownEndpoint(t *testing.T) {
+	server := NewServer(newFakeBackend(), nil)
+
+	rec := serve(t, server, http.MethodGet, "/v2/services", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "unknown endpoint GET /v2/services", decodeError(t, rec))
+
+	rec = serve(t, server, http.MethodDelete, "/v1/services/web", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "unknown endpoint DELETE /v1/services/web", decodeError(t, rec))
+}
+
+func TestServer_SupervisorStopped(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeBackendError(rec, fmt.Errorf("wrapped: %w", supervisor.ErrSupervisorStopped))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
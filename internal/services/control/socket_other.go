@@ -0,0 +1,19 @@
+//go:build !unix
+
+// internal/services/control/socket_other.go - Creation and group ownership of the control socket
+package control
+
+import (
+	"errors"
+	"net"
+)
+
+// listenOwnerOnly binds the socket, there is no umask outside Unix
+func listenOwnerOnly(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
+
+// chownGroup is not supported, sockets have no group outside Unix
+func chownGroup(_, _ string) error {
+	return errors.New("socket_group is only supported on Unix")
+}
@@ -0,0 +1,41 @@
+//go:build unix
+
+// internal/services/control/socket_unix.go - Creation and group ownership of the control socket
+package control
+
+import (
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+// umaskMu serializes the umask changes of the process
+var umaskMu sync.Mutex
+
+// listenOwnerOnly binds the socket under a 0177 umask, so that it is never
+// reachable by others before its mode is applied
+func listenOwnerOnly(path string) (net.Listener, error) {
+	umaskMu.Lock()
+	defer umaskMu.Unlock()
+	old := syscall.Umask(0o177)
+	defer syscall.Umask(old)
+	return net.Listen("unix", path)
+}
+
+// chownGroup gives the socket to a group name or gid
+func chownGroup(path, group string) error {
+	gid, err := strconv.Atoi(group)
+	if err != nil {
+		g, err := user.LookupGroup(group)
+		if err != nil {
+			return err
+		}
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return err
+		}
+	}
+	return os.Chown(path, -1, gid)
+}
@@ -0,0 +1,379 @@
+// internal/services/ctl.go - svz ctl client of a running supervisor
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/services/control"
+	"github.com/kodflow/superviz.io/internal/services/supervisor"
+	"github.com/kodflow/superviz.io/internal/utils"
+)
+
+// CtlAction is an operation svz ctl applies to services.
+type CtlAction string
+
+// Service operations of svz ctl.
+const (
+	// CtlStart starts stopped services
+	CtlStart CtlAction = "start"
+	// CtlStop stops running services
+	CtlStop CtlAction = "stop"
+	// CtlRestart restarts services
+	CtlRestart CtlAction = "restart"
+)
+
+// FormatServiceStatuses returns a table of service states.
+//
+//	NAME    STATE    PID   RESTARTS  EXIT  SINCE
+//	db      healthy  4242  0         -     3m12s
+//	worker  exited   -     2         1     4s
+//
+// Parameters:
+//   - statuses: []supervisor.ServiceStatus rows
+//   - now: time.Time reference for the SINCE column
+//
+// Returns:
+//   - formatted: string table ending with a newline
+func FormatServiceStatuses(statuses []supervisor.ServiceStatus, now time.Time) string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSTATE\tPID\tRESTARTS\tEXIT\tSINCE")
+	for _, status := range statuses {
+		pid, exit, since := "-", "-", "-"
+		if status.PID != 0 {
+			pid = strconv.Itoa(status.PID)
+		}
+		if status.ExitCode != nil {
+			exit = strconv.Itoa(*status.ExitCode)
+		}
+		if !status.Since.IsZero() {
+			since = now.Sub(status.Since).Round(time.Second).String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n", status.Name, status.State, pid, status.Restarts, exit, since)
+	}
+	tw.Flush() //nolint:errcheck // strings.Builder cannot fail
+	return b.String()
+}
+
+// CtlService drives a running supervisor through its control API.
+type CtlService struct {
+	// now returns the current time for the SINCE column
+	now func() time.Time
+}
+
+// NewCtlService creates a new ctl service.
+//
+// Returns:
+//   - service: *CtlService ready for use
+func NewCtlService() *CtlService {
+	return &CtlService{now: time.Now}
+}
+
+// List writes every service of the supervisor.
+//
+// Parameters:
+//   - ctx: context.Context for cancellation
+//   - w: io.Writer destination
+//   - opts: *control.ClientOptions socket or TCP settings
+//   - format: utils.OutputFormat text, json or yaml
+//
+// Returns:
+//   - err: error if the supervisor cannot be reached
+func (s *CtlService) List(ctx context.Context, w io.Writer, opts *control.ClientOptions, format utils.OutputFormat) error {
+	return s.Status(ctx, w, opts, nil, format)
+}
+
+// Status writes the named services, or every service when names is empty.
+//
+// Parameters:
+//   - ctx: context.Context for cancellation
+//   - w: io.Writer destination
+//   - opts: *control.ClientOptions socket or TCP settings
+//   - names: []string services to show
+//   - format: utils.OutputFormat text, json or yaml
+//
+// Returns:
+//   - err: error if the supervisor cannot be reached or a service is unknown
+func (s *CtlService) Status(ctx context.Context, w io.Writer, opts *control.ClientOptions, names []string, format utils.OutputFormat) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+	client, err := control.NewClient(opts)
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		statuses, err := client.List(ctx)
+		if err != nil {
+			return err
+		}
+		return s.writeStatuses(w, statuses, format)
+	}
+
+	statuses, err := eachService(names, func(name string) (supervisor.ServiceStatus, error) {
+		return client.Status(ctx, name)
+	})
+	return s.writeResults(w, statuses, err, format)
+}
+
+// Apply starts, stops or restarts services and writes their resulting status.
+//
+// Every service is attempted; the failures are returned together.
+//
+// Parameters:
+//   - ctx: context.Context for cancellation
+//   - w: io.Writer destination
+//   - opts: *control.ClientOptions socket or TCP settings
+//   - action: CtlAction operation to apply
+//   - names: []string services to act on
+//   - format: utils.OutputFormat text, json or yaml
+//
+// Returns:
+//   - err: error if the supervisor cannot be reached or an operation failed
+func (s *CtlService) Apply(ctx context.Context, w io.Writer, opts *control.ClientOptions, action CtlAction, names []string, format utils.OutputFormat) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+	client, err := control.NewClient(opts)
+	if err != nil {
+		return err
+	}
+
+	var call func(context.Context, string) (supervisor.ServiceStatus, error)
+	switch action {
+	case CtlStart:
+		call = client.Start
+	case CtlStop:
+		call = client.Stop
+	case CtlRestart:
+		call = client.Restart
+	default:
+		return fmt.Errorf("unknown action %q", action)
+	}
+
+	statuses, err := eachService(names, func(name string) (supervisor.ServiceStatus, error) {
+		return call(ctx, name)
+	})
+	return s.writeResults(w, statuses, err, format)
+}
+
+// Signal sends a signal to services and writes their status.
+//
+// Parameters:
+//   - ctx: context.Context for cancellation
+//   - w: io.Writer destination
+//   - opts: *control.ClientOptions socket or TCP settings
+//   - signal: string signal name such as HUP or SIGUSR1
+//   - names: []string services to signal
+//   - format: utils.OutputFormat text, json or yaml
+//
+// Returns:
+//   - err: error if the supervisor cannot be reached or a signal could not be sent
+func (s *CtlService) Signal(ctx context.Context, w io.Writer, opts *control.ClientOptions, signal string, names []string, format utils.OutputFormat) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+	client, err := control.NewClient(opts)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := eachService(names, func(name string) (supervisor.ServiceStatus, error) {
+		return client.Signal(ctx, name, signal)
+	})
+	return s.writeResults(w, statuses, err, format)
+}
+
+// Logs writes the last output lines of a service and, when following, every
+// new line until ctx is cancelled or the supervisor stops.
+//
+// Text lines are prefixed with the service name; structured formats write a
+// JSON lines or YAML document stream of supervisor.LogLine.
+//
+// Parameters:
+//   - ctx: context.Context whose cancellation ends a followed stream
+//   - w: io.Writer destination
+//   - opts: *control.ClientOptions socket or TCP settings
+//   - name: string service name
+//   - lines: int number of past lines, every kept line when 0
+//   - follow: bool keeps writing new lines
+//   - format: utils.OutputFormat text, json or yaml
+//
+// Returns:
+//   - err: error if the supervisor cannot be reached or the service is unknown
+func (s *CtlService) Logs(ctx context.Context, w io.Writer, opts *control.ClientOptions, name string, lines int, follow bool, format utils.OutputFormat) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+	if lines < 0 {
+		return fmt.Errorf("invalid line count %d: must not be negative", lines)
+	}
+	client, err := control.NewClient(opts)
+	if err != nil {
+		return err
+	}
+
+	write := func(line supervisor.LogLine) error {
+		_, err := fmt.Fprintln(w, line.Format())
+		return err
+	}
+	if format != utils.OutputText {
+		enc, err := utils.NewEncoder(w, format)
+		if err != nil {
+			return err
+		}
+		write = func(line supervisor.LogLine) error {
+			line.Time = line.Time.UTC()
+			return enc.Encode(line)
+		}
+	}
+	return client.Logs(ctx, name, lines, follow, write)
+}
+
+// Reload asks the supervisor to apply its configuration again and writes the
+// changes, one line per service, followed by their summary.
+//
+// Parameters:
+//   - ctx: context.Context for cancellation
+//   - w: io.Writer destination
+//   - opts: *control.ClientOptions socket or TCP settings
+//   - dryRun: bool only shows the changes, nothing is applied
+//   - format: utils.OutputFormat text, json or yaml
+//
+// Returns:
+//   - err: error if the supervisor cannot be reached or rejected the configuration
+func (s *CtlService) Reload(ctx context.Context, w io.Writer, opts *control.ClientOptions, dryRun bool, format utils.OutputFormat) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+	client, err := control.NewClient(opts)
+	if err != nil {
+		return err
+	}
+	result, err := client.Reload(ctx, dryRun)
+	if err != nil {
+		return err
+	}
+
+	if format != utils.OutputText {
+		return utils.EncodeOutput(w, format, result)
+	}
+	message := result.Message
+	if message == "" {
+		message = "configuration reloaded"
+	}
+	if result.DryRun {
+		message = "dry run: " + message
+	}
+	if result.Diff != nil {
+		message = result.Diff.Format() + message
+	}
+	if _, err := fmt.Fprintln(w, message); err != nil {
+		return fmt.Errorf("failed to write reload result: %w", err)
+	}
+	return nil
+}
+
+// Events writes the latest divergences and corrective actions recorded by
+// the reconciler, oldest first, one line per event in text.
+//
+// Parameters:
+//   - ctx: context.Context for cancellation
+//   - w: io.Writer destination
+//   - opts: *control.ClientOptions socket or TCP settings
+//   - resource: string resource name such as service/web, every resource when empty
+//   - limit: int maximum number of events, every kept event when 0
+//   - format: utils.OutputFormat text, json or yaml
+//
+// Returns:
+//   - err: error if the supervisor cannot be reached or does not reconcile
+func (s *CtlService) Events(ctx context.Context, w io.Writer, opts *control.ClientOptions, resource string, limit int, format utils.OutputFormat) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+	if limit < 0 {
+		return fmt.Errorf("invalid event count %d: must not be negative", limit)
+	}
+	client, err := control.NewClient(opts)
+	if err != nil {
+		return err
+	}
+	events, err := client.Events(ctx, resource, limit)
+	if err != nil {
+		return err
+	}
+
+	if format != utils.OutputText {
+		for i := range events {
+			events[i].Time = events[i].Time.UTC()
+		}
+		return utils.EncodeOutput(w, format, control.EventList{Events: events})
+	}
+	var b strings.Builder
+	for _, event := range events {
+		b.WriteString(event.Time.UTC().Format(time.RFC3339) + " " + event.Format() + "\n")
+	}
+	if len(events) == 0 {
+		b.WriteString("no events\n")
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write events: %w", err)
+	}
+	return nil
+}
+
+// writeResults writes the statuses of the successful calls, unless every call failed, and returns err
+func (s *CtlService) writeResults(w io.Writer, statuses []supervisor.ServiceStatus, err error, format utils.OutputFormat) error {
+	if len(statuses) == 0 && err != nil {
+		return err
+	}
+	if writeErr := s.writeStatuses(w, statuses, format); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+// writeStatuses writes a status table, or a ServiceList in structured formats
+func (s *CtlService) writeStatuses(w io.Writer, statuses []supervisor.ServiceStatus, format utils.OutputFormat) error {
+	if format != utils.OutputText {
+		if statuses == nil {
+			statuses = []supervisor.ServiceStatus{}
+		}
+		return utils.EncodeOutput(w, format, control.ServiceList{Services: statuses})
+	}
+	if len(statuses) == 0 {
+		return nil
+	}
+	if _, err := io.WriteString(w, FormatServiceStatuses(statuses, s.now())); err != nil {
+		return fmt.Errorf("failed to write services: %w", err)
+	}
+	return nil
+}
+
+// eachService calls fn for every name and returns the statuses of the successful calls
+// along with the failures prefixed by the service name
+func eachService(names []string, fn func(name string) (supervisor.ServiceStatus, error)) ([]supervisor.ServiceStatus, error) {
+	statuses := make([]supervisor.ServiceStatus, 0, len(names))
+	var errs []error
+	for _, name := range names {
+		status, err := fn(name)
+		if err != nil {
+			var apiErr *control.APIError
+			if !errors.As(err, &apiErr) {
+				return statuses, err
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, errors.Join(errs...)
+}
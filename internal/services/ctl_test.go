@@ -0,0 +1,229 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/services/control"
+	"github.com/kodflow/superviz.io/internal/services/supervisor"
+	"github.com/kodflow/superviz.io/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ctlTestConfig = `control: {}
+services:
+  db:
+    command: sh
+    args: ["-c", "echo db up; exec sleep 30"]
+    restart: never
+  web:
+    command: sh
+    args: ["-c", "trap 'echo got hup' HUP; echo web up; while :; do sleep 0.05; done"]
+    restart: never
+    depends_on: [db]
+`
+
+// startCtlSupervisor runs ctlTestConfig until the test ends and returns the options reaching it
+func startCtlSupervisor(t *testing.T) *control.ClientOptions {
+	t.Helper()
+	path := writeSupervizConfig(t, ctlTestConfig)
+	opts := &control.ClientOptions{Socket: filepath.Join(filepath.Dir(path), "superviz.sock")}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- newTestRunService(&lockedBuffer{}).Run(ctx, &lockedBuffer{}, path, utils.OutputText)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+
+	client, err := control.NewClient(opts)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		status, err := client.Status(ctx, "web")
+		return err == nil && status.State == supervisor.StateRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	return opts
+}
+
+func TestFormatServiceStatuses(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	code := 1
+	statuses := []supervisor.ServiceStatus{
+		{Name: "db", State: supervisor.StateHealthy, PID: 4242, Since: now.Add(-192 * time.Second)},
+		{Name: "worker", State: supervisor.StateExited, Restarts: 2, ExitCode: &code, Since: now.Add(-4 * time.Second)},
+		{Name: "idle", State: supervisor.StateStopped},
+	}
+
+	assert.Equal(t, "NAME    STATE    PID   RESTARTS  EXIT  SINCE\n"+
+		"db      healthy  4242  0         -     3m12s\n"+
+		"worker  exited   -     2         1     4s\n"+
+		"idle    stopped  -     0         -     -\n", FormatServiceStatuses(statuses, now))
+}
+
+func TestCtlService_ListAndStatus(t *testing.T) {
+	opts := startCtlSupervisor(t)
+	service := NewCtlService()
+
+	var out bytes.Buffer
+	require.NoError(t, service.List(context.Background(), &out, opts, utils.OutputText))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "NAME"))
+	assert.Contains(t, lines[1], "db")
+	assert.Contains(t, lines[2], "running")
+
+	out.Reset()
+	require.NoError(t, service.Status(context.Background(), &out, opts, []string{"web"}, utils.OutputJSON))
+	var list control.ServiceList
+	require.NoError(t, json.Unmarshal(out.Bytes(), &list))
+	require.Len(t, list.Services, 1)
+	assert.Equal(t, "web", list.Services[0].Name)
+
+	out.Reset()
+	err := service.Status(context.Background(), &out, opts, []string{"web", "nope"}, utils.OutputText)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nope: unknown service")
+	assert.Contains(t, out.String(), "web", "the known services are still shown")
+}
+
+func TestCtlService_Apply(t *testing.T) {
+	opts := startCtlSupervisor(t)
+	service := NewCtlService()
+	ctx := context.Background()
+
+	var out bytes.Buffer
+	require.NoError(t, service.Apply(ctx, &out, opts, CtlStop, []string{"web"}, utils.OutputText))
+	assert.Contains(t, out.String(), "stopped")
+
+	err := service.Apply(ctx, &bytes.Buffer{}, opts, CtlStop, []string{"web"}, utils.OutputText)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "web: service is not running")
+
+	require.NoError(t, service.Apply(ctx, &bytes.Buffer{}, opts, CtlStart, []string{"web"}, utils.OutputText))
+	require.NoError(t, service.Apply(ctx, &bytes.Buffer{}, opts, CtlRestart, []string{"db"}, utils.OutputYAML))
+
+	err = service.Apply(ctx, &bytes.Buffer{}, opts, CtlAction("pause"), []string{"web"}, utils.OutputText)
+	require.Error(t, err)
+}
+
+func TestCtlService_SignalAndLogs(t *testing.T) {
+	opts := startCtlSupervisor(t)
+	service := NewCtlService()
+	ctx := context.Background()
+
+	require.NoError(t, service.Signal(ctx, &bytes.Buffer{}, opts, "SIGHUP", []string{"web"}, utils.OutputText))
+	err := service.Signal(ctx, &bytes.Buffer{}, opts, "BOGUS", []string{"web"}, utils.OutputText)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported signal")
+
+	var out bytes.Buffer
+	require.Eventually(t, func() bool {
+		out.Reset()
+		require.NoError(t, service.Logs(ctx, &out, opts, "web", 0, false, utils.OutputText))
+		return strings.Contains(out.String(), "web | got hup")
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, strings.HasPrefix(out.String(), "web | web up\n"))
+
+	out.Reset()
+	require.NoError(t, service.Logs(ctx, &out, opts, "db", 1, false, utils.OutputJSON))
+	var line supervisor.LogLine
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, supervisor.LogLine{Time: line.Time, Service: "db", Stream: supervisor.StreamStdout, Text: "db up", PID: line.PID}, line)
+	assert.NotZero(t, line.PID)
+
+	followCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	out.Reset()
+	require.NoError(t, service.Logs(followCtx, &out, opts, "db", 0, true, utils.OutputText))
+	assert.Equal(t, "db | db up\n", out.String())
+
+	require.Error(t, service.Logs(ctx, &out, opts, "db", -1, false, utils.OutputText))
+}
+
+func TestCtlService_Reload(t *testing.T) {
+	opts := startCtlSupervisor(t)
+	path := filepath.Join(filepath.Dir(opts.Socket), "superviz.yaml")
+	service := NewCtlService()
+	ctx := context.Background()
+
+	var out bytes.Buffer
+	require.NoError(t, service.Reload(ctx, &out, opts, false, utils.OutputText))
+	assert.Equal(t, "nothing changed\n", out.String())
+
+	updated := strings.Replace(ctlTestConfig, "    depends_on: [db]\n", "    depends_on: [db]\n    stop_timeout: 5s\n", 1) +
+		"  api:\n    command: sh\n    args: [\"-c\", \"exec sleep 30\"]\n    restart: never\n"
+	require.NoError(t, os.WriteFile(path, []byte(updated), 0o600))
+
+	out.Reset()
+	require.NoError(t, service.Reload(ctx, &out, opts, true, utils.OutputText))
+	assert.Equal(t, "add api\nupdate web: stop_timeout\ndry run: 1 added, 1 updated\n", out.String())
+	out.Reset()
+	require.NoError(t, service.Reload(ctx, &out, opts, true, utils.OutputJSON))
+	var result control.ReloadResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.True(t, result.DryRun)
+	require.NotNil(t, result.Diff)
+	assert.Equal(t, []string{"api"}, result.Diff.Added)
+	err := service.Status(ctx, &bytes.Buffer{}, opts, []string{"api"}, utils.OutputText)
+	assert.ErrorContains(t, err, "unknown service", "a dry run applies nothing")
+
+	out.Reset()
+	require.NoError(t, service.Reload(ctx, &out, opts, false, utils.OutputText))
+	assert.Equal(t, "add api\nupdate web: stop_timeout\n1 added, 1 updated\n", out.String())
+	client, err := control.NewClient(opts)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		status, err := client.Status(ctx, "api")
+		return err == nil && status.State == supervisor.StateRunning
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(path, []byte("services:\n  web: {command: ''}\n"), 0o600))
+	err = service.Reload(ctx, &out, opts, true, utils.OutputText)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "keeping the current configuration")
+	err = service.Reload(ctx, &out, opts, false, utils.OutputText)
+	require.Error(t, err)
+	services, err := client.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, services, 3, "the running configuration is kept")
+}
+
+func TestCtlService_Events(t *testing.T) {
+	opts := startCtlSupervisor(t)
+	err := NewCtlService().Events(context.Background(), &bytes.Buffer{}, opts, "", 0, utils.OutputText)
+	assert.ErrorContains(t, err, "reconciliation is not enabled", "the events need a reconcile section")
+}
+
+func TestCtlService_Errors(t *testing.T) {
+	service := NewCtlService()
+	ctx := context.Background()
+	opts := &control.ClientOptions{Socket: filepath.Join(t.TempDir(), "missing.sock")}
+
+	require.ErrorIs(t, service.List(ctx, nil, opts, utils.OutputText), ErrNilWriter)
+	require.ErrorIs(t, service.Apply(ctx, nil, opts, CtlStart, nil, utils.OutputText), ErrNilWriter)
+	require.ErrorIs(t, service.Signal(ctx, nil, opts, "HUP", nil, utils.OutputText), ErrNilWriter)
+	require.ErrorIs(t, service.Logs(ctx, nil, opts, "web", 0, false, utils.OutputText), ErrNilWriter)
+	require.ErrorIs(t, service.Reload(ctx, nil, opts, false, utils.OutputText), ErrNilWriter)
+	require.ErrorIs(t, service.Events(ctx, nil, opts, "", 0, utils.OutputText), ErrNilWriter)
+	require.ErrorContains(t, service.Events(ctx, &bytes.Buffer{}, opts, "", -1, utils.OutputText), "must not be negative")
+
+	err := service.List(ctx, &bytes.Buffer{}, opts, utils.OutputText)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to reach the supervisor")
+
+	err = service.Apply(ctx, &bytes.Buffer{}, opts, CtlStop, []string{"a", "b"}, utils.OutputText)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "b:", "an unreachable supervisor fails once")
+
+	require.Error(t, service.List(ctx, &bytes.Buffer{}, &control.ClientOptions{}, utils.OutputText))
+}
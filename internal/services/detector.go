@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing"
 	"github.com/kodflow/superviz.io/internal/infrastructure/transports/ssh"
 )
 
@@ -65,7 +66,17 @@ func NewDetector(client ssh.Client) Detector {
 // Returns:
 //   - String identifier for the detected distribution (ubuntu, debian, alpine, centos, rhel, fedora, arch)
 //   - Error if no distribution can be identified
-func (d *detector) Detect(ctx context.Context) (string, error) {
+func (d *detector) Detect(ctx context.Context) (distro string, err error) {
+	ctx, span := tracing.Start(ctx, "distro.detect")
+	defer func() {
+		span.SetAttributes(tracing.Attr("os.distro", distro))
+		span.End(err)
+	}()
+	return d.detect(ctx)
+}
+
+// detect runs the detection commands and returns the first distribution matched
+func (d *detector) detect(ctx context.Context) (string, error) {
 	// Try to detect using /etc/os-release (most modern distributions)
 	if err := d.client.Execute(ctx, "test -f /etc/os-release"); err == nil {
 		// Check for specific distributions in order of preference
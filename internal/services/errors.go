@@ -14,4 +14,18 @@ var (
 	ErrNilConfig = errors.New("config cannot be nil")
 	// ErrNilWriter indicates that a required writer parameter is nil
 	ErrNilWriter = errors.New("writer cannot be nil")
+	// ErrPreflightFailed indicates that at least one preflight check failed
+	ErrPreflightFailed = errors.New("preflight checks failed")
+	// ErrStatusIncomplete indicates that at least one host could not be queried
+	ErrStatusIncomplete = errors.New("status could not be collected")
+	// ErrRepairFailed indicates that the repository of at least one host could not be repaired
+	ErrRepairFailed = errors.New("repository repair failed")
+	// ErrUpgradeFailed indicates that at least one host failed to upgrade
+	ErrUpgradeFailed = errors.New("upgrade failed")
+	// ErrIncompatibleVersion indicates an agent version outside the CLI's compatibility window
+	ErrIncompatibleVersion = errors.New("incompatible agent version")
+	// ErrIntegrityFailed indicates that at least one file failed its integrity check
+	ErrIntegrityFailed = errors.New("integrity check failed")
+	// ErrBuildFailed indicates that an artifact or a file failed during a build
+	ErrBuildFailed = errors.New("build failed")
 )
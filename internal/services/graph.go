@@ -0,0 +1,176 @@
+// internal/services/graph.go - Service dependency graph of a superviz.yaml file
+package services
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/utils"
+)
+
+// GraphNode is a service of the dependency graph.
+type GraphNode struct {
+	// Name is the service name
+	Name string `json:"name" yaml:"name"`
+	// Level is the length of the longest dependency chain below the service;
+	// services of one level start in parallel once the lower levels are up
+	Level int `json:"level" yaml:"level"`
+	// DependsOn lists the dependencies and their conditions
+	DependsOn providers.Dependencies `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+}
+
+// DependencyGraph is the start order of the services of a configuration.
+type DependencyGraph struct {
+	// Services are sorted by level, then by name
+	Services []GraphNode `json:"services" yaml:"services"`
+}
+
+// NewDependencyGraph builds the graph of a validated configuration.
+//
+// Parameters:
+//   - config: *providers.SupervizConfig services and their dependencies
+//
+// Returns:
+//   - graph: *DependencyGraph services with their levels
+//   - err: error if the dependencies contain a cycle
+func NewDependencyGraph(config *providers.SupervizConfig) (*DependencyGraph, error) {
+	if config == nil {
+		return nil, ErrNilConfig
+	}
+	order, err := config.StartOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	levels := make(map[string]int, len(order))
+	graph := &DependencyGraph{Services: make([]GraphNode, 0, len(order))}
+	for _, name := range order {
+		service := config.Services[name]
+		level := 0
+		for _, dep := range service.DependsOn {
+			if levels[dep.Service]+1 > level {
+				level = levels[dep.Service] + 1
+			}
+		}
+		levels[name] = level
+		graph.Services = append(graph.Services, GraphNode{Name: name, Level: level, DependsOn: service.DependsOn})
+	}
+
+	sort.SliceStable(graph.Services, func(i, j int) bool {
+		a, b := graph.Services[i], graph.Services[j]
+		if a.Level != b.Level {
+			return a.Level < b.Level
+		}
+		return a.Name < b.Name
+	})
+	return graph, nil
+}
+
+// Format returns the graph grouped by start level.
+//
+//	level 0
+//	  db
+//	  migrate
+//	level 1
+//	  app <- db (healthy), migrate (completed_successfully)
+//
+// Returns:
+//   - formatted: string multi-line graph ending with a newline
+func (g *DependencyGraph) Format() string {
+	var b strings.Builder
+	level := -1
+	for _, node := range g.Services {
+		if node.Level != level {
+			level = node.Level
+			fmt.Fprintf(&b, "level %d\n", level)
+		}
+		b.WriteString("  " + node.Name)
+		for i, dep := range node.DependsOn {
+			if i == 0 {
+				b.WriteString(" <- ")
+			} else {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "%s (%s)", dep.Service, dep.Condition)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// DOT returns the graph in Graphviz DOT format.
+//
+// Edges point from a dependency to its dependent, in start order, and are
+// labeled with the condition the dependent waits for.
+//
+// Returns:
+//   - dot: string digraph ending with a newline
+func (g *DependencyGraph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph superviz {\n  rankdir=LR;\n")
+	for _, node := range g.Services {
+		fmt.Fprintf(&b, "  %q;\n", node.Name)
+	}
+	for _, node := range g.Services {
+		for _, dep := range node.DependsOn {
+			fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", dep.Service, node.Name, string(dep.Condition))
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// GraphService renders the dependency graph of a superviz.yaml file.
+type GraphService struct{}
+
+// NewGraphService creates a new graph service.
+//
+// Returns:
+//   - service: *GraphService ready for use
+func NewGraphService() *GraphService {
+	return &GraphService{}
+}
+
+// Graph loads a configuration and writes its dependency graph.
+//
+// Parameters:
+//   - w: io.Writer destination
+//   - path: string superviz.yaml location
+//   - format: utils.OutputFormat text, json or yaml
+//   - dot: bool writes Graphviz DOT instead, only with the text format
+//
+// Returns:
+//   - err: error if the configuration is invalid or the output cannot be written
+func (s *GraphService) Graph(w io.Writer, path string, format utils.OutputFormat, dot bool) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+	if dot && format != utils.OutputText {
+		return fmt.Errorf("--dot cannot be combined with %s output", format)
+	}
+
+	config, err := providers.LoadSupervizConfig(path)
+	if err != nil {
+		return err
+	}
+	graph, err := NewDependencyGraph(config)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case dot:
+		_, err = io.WriteString(w, graph.DOT())
+	case format == utils.OutputText:
+		_, err = io.WriteString(w, graph.Format())
+	default:
+		return utils.EncodeOutput(w, format, graph)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write graph: %w", err)
+	}
+	return nil
+}
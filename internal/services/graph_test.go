@@ -0,0 +1,123 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const graphTestConfig = `services:
+  vault-agent: {command: vault}
+  migrate: {command: migrate, restart: never}
+  consul-template:
+    command: consul-template
+    depends_on:
+      vault-agent: {condition: healthy}
+  app:
+    command: app
+    depends_on:
+      consul-template:
+      migrate: {condition: completed_successfully}
+`
+
+// writeGraphConfig writes content as superviz.yaml and returns its path
+func writeGraphConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "superviz.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestNewDependencyGraph(t *testing.T) {
+	config, err := providers.ParseSupervizConfig([]byte(graphTestConfig), "/srv")
+	require.NoError(t, err)
+
+	graph, err := NewDependencyGraph(config)
+	require.NoError(t, err)
+
+	var names []string
+	var levels []int
+	for _, node := range graph.Services {
+		names = append(names, node.Name)
+		levels = append(levels, node.Level)
+	}
+	assert.Equal(t, []string{"migrate", "vault-agent", "consul-template", "app"}, names)
+	assert.Equal(t, []int{0, 0, 1, 2}, levels)
+
+	_, err = NewDependencyGraph(nil)
+	require.ErrorIs(t, err, ErrNilConfig)
+}
+
+func TestDependencyGraph_Format(t *testing.T) {
+	config, err := providers.ParseSupervizConfig([]byte(graphTestConfig), "/srv")
+	require.NoError(t, err)
+	graph, err := NewDependencyGraph(config)
+	require.NoError(t, err)
+
+	assert.Equal(t, `level 0
+  migrate
+  vault-agent
+level 1
+  consul-template <- vault-agent (healthy)
+level 2
+  app <- consul-template (started), migrate (completed_successfully)
+`, graph.Format())
+}
+
+func TestDependencyGraph_DOT(t *testing.T) {
+	config, err := providers.ParseSupervizConfig([]byte(graphTestConfig), "/srv")
+	require.NoError(t, err)
+	graph, err := NewDependencyGraph(config)
+	require.NoError(t, err)
+
+	assert.Equal(t, `digraph superviz {
+  rankdir=LR;
+  "migrate";
+  "vault-agent";
+  "consul-template";
+  "app";
+  "vault-agent" -> "consul-template" [label="healthy"];
+  "consul-template" -> "app" [label="started"];
+  "migrate" -> "app" [label="completed_successfully"];
+}
+`, graph.DOT())
+}
+
+func TestGraphService_Graph(t *testing.T) {
+	path := writeGraphConfig(t, graphTestConfig)
+	service := NewGraphService()
+
+	var text bytes.Buffer
+	require.NoError(t, service.Graph(&text, path, utils.OutputText, false))
+	assert.Contains(t, text.String(), "level 2\n  app <- ")
+
+	var dot bytes.Buffer
+	require.NoError(t, service.Graph(&dot, path, utils.OutputText, true))
+	assert.Contains(t, dot.String(), "digraph superviz {")
+
+	var structured bytes.Buffer
+	require.NoError(t, service.Graph(&structured, path, utils.OutputJSON, false))
+	var graph DependencyGraph
+	require.NoError(t, json.Unmarshal(structured.Bytes(), &graph))
+	require.Len(t, graph.Services, 4)
+	assert.Equal(t, "app", graph.Services[3].Name)
+	assert.Equal(t, providers.ConditionCompletedSuccessfully, graph.Services[3].DependsOn[1].Condition)
+}
+
+func TestGraphService_Errors(t *testing.T) {
+	service := NewGraphService()
+	path := writeGraphConfig(t, graphTestConfig)
+
+	require.ErrorIs(t, service.Graph(nil, path, utils.OutputText, false), ErrNilWriter)
+	assert.ErrorContains(t, service.Graph(&bytes.Buffer{}, path, utils.OutputJSON, true), "--dot cannot be combined")
+
+	cyclic := writeGraphConfig(t, "services:\n  a: {command: x, depends_on: [b]}\n  b: {command: x, depends_on: [a]}\n")
+	assert.ErrorContains(t, service.Graph(&bytes.Buffer{}, cyclic, utils.OutputText, false), "dependency cycle involving services a, b")
+}
@@ -7,26 +7,30 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
+	"github.com/kodflow/superviz.io/internal/infrastructure/pkgmanager"
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing"
 	"github.com/kodflow/superviz.io/internal/infrastructure/transports/ssh"
 	"github.com/kodflow/superviz.io/internal/providers"
 	"github.com/kodflow/superviz.io/internal/services/repository"
+	"github.com/kodflow/superviz.io/internal/services/repository/common"
 )
 
 // Pre-compiled install commands for performance optimization.
 //
-// installCommands contains distribution-specific installation commands
-// for superviz.io, reducing string construction overhead during installation.
+// installCommands contains distribution-specific installation command
+// templates, formatted with the package to install.
 var installCommands = map[string]string{
-	"ubuntu": "  sudo apt update && sudo apt install superviz\n",
-	"debian": "  sudo apt update && sudo apt install superviz\n",
-	"alpine": "  sudo apk update && sudo apk add superviz\n",
-	"centos": "  sudo yum install superviz  # or dnf install superviz\n",
-	"rhel":   "  sudo yum install superviz  # or dnf install superviz\n",
-	"fedora": "  sudo dnf install superviz\n",
-	"arch":   "  sudo pacman -S superviz\n",
-	"suse":   "  sudo zypper install superviz\n",
-	"gentoo": "  sudo emerge superviz\n",
+	"ubuntu": "  sudo apt update && sudo apt install %[1]s\n",
+	"debian": "  sudo apt update && sudo apt install %[1]s\n",
+	"alpine": "  sudo apk update && sudo apk add %[1]s\n",
+	"centos": "  sudo yum install %[1]s  # or dnf install %[1]s\n",
+	"rhel":   "  sudo yum install %[1]s  # or dnf install %[1]s\n",
+	"fedora": "  sudo dnf install %[1]s\n",
+	"arch":   "  sudo pacman -S %[1]s\n",
+	"suse":   "  sudo zypper install %[1]s\n",
+	"gentoo": "  sudo emerge %[1]s\n",
 }
 
 // bufferedWriter wraps a writer with buffering and error tracking.
@@ -110,6 +114,8 @@ type InstallService struct {
 	client    ssh.Client
 	detector  DistroDetector
 	repoSetup repository.Setup
+	preflight PreflightChecker
+	versions  *VersionService
 }
 
 // InstallServiceOptions contains options for creating an InstallService
@@ -118,6 +124,9 @@ type InstallServiceOptions struct {
 	SSHClient      ssh.Client
 	DistroDetector DistroDetector
 	RepoSetup      repository.Setup
+	Preflight      PreflightChecker
+	// Versions enforces the agent compatibility window, nil for the running binary's
+	Versions *VersionService
 }
 
 // NewInstallService creates a new install service with the given options
@@ -130,6 +139,8 @@ func NewInstallService(opts *InstallServiceOptions) *InstallService {
 		s.client = ssh.NewClient(nil)
 		s.detector = NewDetector(s.client)
 		s.repoSetup = repository.NewSetup(s.client, s.provider)
+		s.preflight = NewPreflightChecker(s.client, s.provider)
+		s.versions = NewVersionService(nil)
 		return s
 	}
 
@@ -154,11 +165,62 @@ func NewInstallService(opts *InstallServiceOptions) *InstallService {
 		s.repoSetup = repository.NewSetup(s.client, s.provider)
 	}
 
+	s.preflight = opts.Preflight
+	if s.preflight == nil {
+		s.preflight = NewPreflightChecker(s.client, s.provider)
+	}
+
+	s.versions = opts.Versions
+	if s.versions == nil {
+		s.versions = NewVersionService(nil)
+	}
+
 	return s
 }
 
 // ValidateAndPrepareConfig validates and prepares the installation configuration
 func (s *InstallService) ValidateAndPrepareConfig(config *providers.InstallConfig, args []string) error {
+	if err := parseTarget(config, args); err != nil {
+		return err
+	}
+	if config.Version != "" {
+		if _, err := providers.ParsePackageVersion(config.Version); err != nil {
+			return fmt.Errorf("invalid agent version: %w", err)
+		}
+	}
+	return validateRepoMirror(config)
+}
+
+// validateRepoMirror rejects mirror URLs that are unsafe to embed in remote commands
+func validateRepoMirror(config *providers.InstallConfig) error {
+	if config.RepoMirror == "" {
+		return nil
+	}
+	if err := common.ValidateURL(config.RepoMirror); err != nil {
+		return fmt.Errorf("invalid repository mirror: %w", err)
+	}
+	return nil
+}
+
+// forMirror returns the repository setup and preflight checker for config.
+//
+// When a mirror is configured the repository setup must support it and the
+// preflight checker probes the mirror; a checker without mirror support is
+// returned unchanged and keeps probing the default repository.
+func (s *InstallService) forMirror(config *providers.InstallConfig) (repository.Setup, PreflightChecker, error) {
+	if config.RepoMirror == "" {
+		return s.repoSetup, s.preflight, nil
+	}
+
+	ms, ok := s.repoSetup.(repository.MirrorSetup)
+	if !ok {
+		return nil, nil, fmt.Errorf("repository setup does not support mirrors")
+	}
+	return ms.WithMirror(config.RepoMirror), checkerForMirror(s.preflight, config.RepoMirror), nil
+}
+
+// parseTarget fills the connection fields of config from a user@host argument
+func parseTarget(config *providers.InstallConfig, args []string) error {
 	if config == nil {
 		return ErrNilConfig
 	}
@@ -187,15 +249,81 @@ func (s *InstallService) Install(ctx context.Context, w io.Writer, config *provi
 	if w == nil {
 		return ErrNilWriter
 	}
+	return s.InstallWithReporter(ctx, NewTextInstallReporter(w), config)
+}
+
+// InstallWithReporter performs the installation process reporting structured events.
+//
+// InstallWithReporter emits one event per workflow stage and always finishes
+// with an EventSummary, including when the installation fails.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - reporter: InstallReporter receiving progress events
+//   - config: *providers.InstallConfig validated installation configuration
+//
+// Returns:
+//   - err: error if installation fails at any stage
+func (s *InstallService) InstallWithReporter(ctx context.Context, reporter InstallReporter, config *providers.InstallConfig) error {
+	if reporter == nil {
+		return ErrNilWriter
+	}
 	if config == nil {
 		return ErrNilConfig
 	}
 
-	// Create buffered writer for efficient output
-	bw := &bufferedWriter{Writer: bufio.NewWriter(w)}
+	started := time.Now()
+	summary := &InstallSummary{Target: config.Target}
+
+	ctx, span := tracing.Start(ctx, "install",
+		tracing.Attr("server.address", config.Host),
+		tracing.Attr("ssh.user", config.User))
+	err := s.install(ctx, reporter, config, summary)
+	span.SetAttributes(tracing.Attr("os.distro", summary.Distro), tracing.Attr("agent.version", summary.AgentVersion))
+	span.End(err)
+
+	summary.DurationMS = time.Since(started).Milliseconds()
+	summary.Success = err == nil
+	if err != nil {
+		summary.Error = err.Error()
+		// Best effort: the installation error takes precedence over reporting errors
+		_ = reporter.Report(InstallEvent{Type: EventFailed, Target: config.Target, Error: err.Error()}) //nolint:errcheck
+		_ = reporter.Report(InstallEvent{Type: EventSummary, Target: config.Target, Summary: summary})  //nolint:errcheck
+		return err
+	}
+
+	if err := reporter.Report(InstallEvent{Type: EventSummary, Target: config.Target, Summary: summary}); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// install runs the workflow stages, filling summary as it progresses
+func (s *InstallService) install(ctx context.Context, reporter InstallReporter, config *providers.InstallConfig, summary *InstallSummary) error {
+	report := func(event InstallEvent) error {
+		event.Target = config.Target
+		if err := reporter.Report(event); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		return nil
+	}
+
+	repoSetup, preflight, err := s.forMirror(config)
+	if err != nil {
+		return err
+	}
+
+	// A pinned agent version is refused before touching the host
+	if config.Version != "" {
+		if err := s.versions.CheckAgentVersion(config.Version); err != nil {
+			return err
+		}
+	}
 
 	// Start installation
-	bw.Printf("Starting repository setup on %s\n", config.Target)
+	if err := report(InstallEvent{Type: EventStarted}); err != nil {
+		return err
+	}
 
 	// Create SSH config and connect
 	sshConfig := s.createSSHConfig(config)
@@ -206,39 +334,105 @@ func (s *InstallService) Install(ctx context.Context, w io.Writer, config *provi
 	// Ensure connection is closed
 	defer func() {
 		if err := s.client.Close(); err != nil {
-			// Best effort - write warning but don't fail
-			bw.Printf("Warning: failed to close connection: %v\n", err)
+			// Best effort - report warning but don't fail
+			_ = report(InstallEvent{Type: EventWarning, Message: "failed to close connection: " + err.Error()}) //nolint:errcheck
 		}
 	}()
 
-	bw.Printf("Connected to %s\n", config.Target)
+	if err := report(InstallEvent{Type: EventConnected}); err != nil {
+		return err
+	}
+
+	// Run preflight checks unless explicitly skipped
+	if !config.SkipPreflight {
+		preflightCtx, span := tracing.Start(ctx, "preflight.check")
+		result := preflight.Check(preflightCtx, config.Target)
+		span.SetAttributes(tracing.Attr("preflight.status", string(result.Status())))
+		span.End(nil)
+		if err := report(InstallEvent{Type: EventPreflight, Preflight: result}); err != nil {
+			return err
+		}
+		if result.Status() == PreflightFail {
+			return fmt.Errorf("%w on %s (use --skip-preflight to bypass)", ErrPreflightFailed, config.Target)
+		}
+	}
 
 	// Detect distribution
 	distro, err := s.detector.Detect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to detect distribution: %w", err)
 	}
-	bw.Printf("Detected distribution: %s\n", distro)
+	summary.Distro = distro
+	if err := report(InstallEvent{Type: EventDetected, Distro: distro}); err != nil {
+		return err
+	}
 
 	// Setup repository
-	if err := s.repoSetup.Setup(ctx, distro, w); err != nil {
+	if err := repoSetup.Setup(ctx, distro, reporter.Output()); err != nil {
 		return fmt.Errorf("failed to setup repository: %w", err)
 	}
 
+	// Refuse an agent the CLI cannot manage
+	agentVersion, err := s.resolveAgentVersion(ctx, config, distro, report)
+	if err != nil {
+		return err
+	}
+	summary.AgentVersion = agentVersion
+
 	// Display completion
-	bw.Printf("Repository setup completed successfully on %s\n", config.Target)
-	bw.Printf("You can now install superviz.io with:\n%s", s.getInstallCommand(distro))
+	command := s.getInstallCommand(distro, installPackageSpec(distro, s.provider.GetPackageName(), config.Version))
+	summary.InstallCommand = strings.TrimSpace(command)
+	return report(InstallEvent{Type: EventCompleted, Distro: distro, Message: command})
+}
 
-	// Check for any write errors
-	if err := bw.Error(); err != nil {
-		return fmt.Errorf("failed to write output: %w", err)
+// resolveAgentVersion returns the agent version the host will install and checks its compatibility.
+//
+// A pinned version was checked before connecting; otherwise the version
+// offered by the freshly configured repository is queried. Development builds
+// of the CLI support any agent and skip the query.
+func (s *InstallService) resolveAgentVersion(ctx context.Context, config *providers.InstallConfig, distro string, report func(InstallEvent) error) (string, error) {
+	if config.Version != "" {
+		return config.Version, nil
+	}
+	if _, err := s.versions.CompatibilityWindow(); err != nil {
+		return "", nil
 	}
 
-	return nil
+	candidate := queryPackageStatus(ctx, s.client, s.provider.GetPackageName(), distro).Candidate
+	if candidate == "" {
+		return "", report(InstallEvent{Type: EventWarning, Message: "could not determine the agent version offered by the repository, compatibility not verified"})
+	}
+	if err := s.versions.CheckAgentVersion(candidate); err != nil {
+		return candidate, fmt.Errorf("%w (pin a supported release with --version)", err)
+	}
+	return candidate, nil
+}
+
+// installPackageSpec returns the install argument of pkg, pinned to version when set.
+//
+// The plain package name is returned when the package manager cannot pin versions.
+func installPackageSpec(distro, pkg, version string) string {
+	if version == "" {
+		return pkg
+	}
+	manager, err := pkgmanager.ForDistro(distro)
+	if err != nil {
+		return pkg
+	}
+	spec, err := pkgmanager.PackageSpec(manager, pkg, version)
+	if err != nil {
+		return pkg
+	}
+	return spec
 }
 
 // createSSHConfig creates SSH configuration from install config
 func (s *InstallService) createSSHConfig(config *providers.InstallConfig) *ssh.Config {
+	return newSSHConfig(config)
+}
+
+// newSSHConfig creates SSH configuration from install config
+func newSSHConfig(config *providers.InstallConfig) *ssh.Config {
 	return &ssh.Config{
 		Host:             config.Host,
 		User:             config.User,
@@ -247,11 +441,17 @@ func (s *InstallService) createSSHConfig(config *providers.InstallConfig) *ssh.C
 		Timeout:          config.Timeout,
 		SkipHostKeyCheck: config.SkipHostKeyCheck,
 		AcceptNewHostKey: config.SkipHostKeyCheck, // Backward compatibility
+		JumpHost:         config.JumpHost,
 	}
 }
 
 // wrapConnectionError wraps connection errors with context
 func (s *InstallService) wrapConnectionError(err error, target string) error {
+	return wrapConnectionError(err, target)
+}
+
+// wrapConnectionError wraps connection errors with context
+func wrapConnectionError(err error, target string) error {
 	switch {
 	case ssh.IsAuthError(err):
 		return fmt.Errorf("authentication failed for %s: %w", target, err)
@@ -262,10 +462,10 @@ func (s *InstallService) wrapConnectionError(err error, target string) error {
 	}
 }
 
-// getInstallCommand returns the appropriate install command
-func (s *InstallService) getInstallCommand(distro string) string {
+// getInstallCommand returns the appropriate install command for the package spec
+func (s *InstallService) getInstallCommand(distro, spec string) string {
 	if cmd, ok := installCommands[strings.ToLower(distro)]; ok {
-		return cmd
+		return fmt.Sprintf(cmd, spec)
 	}
 	return "  Please check your package manager documentation\n"
 }
@@ -0,0 +1,286 @@
+// internal/services/install_events.go - Structured progress reporting for installations
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/utils"
+)
+
+// InstallEventType identifies a stage of the installation workflow.
+type InstallEventType string
+
+// Installation event types in emission order.
+const (
+	// EventStarted is emitted before connecting to the target
+	EventStarted InstallEventType = "started"
+	// EventConnected is emitted once the SSH connection is established
+	EventConnected InstallEventType = "connected"
+	// EventPreflight carries the preflight report
+	EventPreflight InstallEventType = "preflight"
+	// EventDetected is emitted once the distribution is known
+	EventDetected InstallEventType = "detected"
+	// EventStepStarted is emitted before each remote setup command
+	EventStepStarted InstallEventType = "step_started"
+	// EventStepFinished is emitted after each remote setup command
+	EventStepFinished InstallEventType = "step_finished"
+	// EventMessage carries free-form progress text from setup handlers
+	EventMessage InstallEventType = "message"
+	// EventWarning carries a non-fatal problem
+	EventWarning InstallEventType = "warning"
+	// EventCompleted is emitted when the repository setup succeeded
+	EventCompleted InstallEventType = "completed"
+	// EventFailed is emitted when the installation aborted
+	EventFailed InstallEventType = "failed"
+	// EventSummary is always the last event of an installation
+	EventSummary InstallEventType = "summary"
+)
+
+// InstallEvent is a structured record of installation progress.
+type InstallEvent struct {
+	// Type identifies the stage
+	Type InstallEventType `json:"type" yaml:"type"`
+	// Time is when the event was emitted
+	Time time.Time `json:"time" yaml:"time"`
+	// Target is the user@host being installed
+	Target string `json:"target,omitempty" yaml:"target,omitempty"`
+	// Distro is the detected distribution
+	Distro string `json:"distro,omitempty" yaml:"distro,omitempty"`
+	// Step is the 1-based index of a setup command
+	Step int `json:"step,omitempty" yaml:"step,omitempty"`
+	// Total is the number of setup commands
+	Total int `json:"total,omitempty" yaml:"total,omitempty"`
+	// Command is the setup command being run
+	Command string `json:"command,omitempty" yaml:"command,omitempty"`
+	// Message carries human-readable detail
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	// Error is the failure cause, if any
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+	// Preflight is set on EventPreflight
+	Preflight *PreflightReport `json:"preflight,omitempty" yaml:"preflight,omitempty"`
+	// Summary is set on EventSummary
+	Summary *InstallSummary `json:"summary,omitempty" yaml:"summary,omitempty"`
+}
+
+// InstallSummary describes the outcome of an installation.
+type InstallSummary struct {
+	// Target is the user@host that was installed
+	Target string `json:"target" yaml:"target"`
+	// Distro is the detected distribution, empty if detection did not run
+	Distro string `json:"distro,omitempty" yaml:"distro,omitempty"`
+	// Success reports whether the repository setup completed
+	Success bool `json:"success" yaml:"success"`
+	// Error is the failure cause when Success is false
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+	// DurationMS is the wall-clock duration in milliseconds
+	DurationMS int64 `json:"duration_ms" yaml:"duration_ms"`
+	// InstallCommand is the suggested package installation command
+	InstallCommand string `json:"install_command,omitempty" yaml:"install_command,omitempty"`
+	// AgentVersion is the checked agent version, empty when it could not be determined
+	AgentVersion string `json:"agent_version,omitempty" yaml:"agent_version,omitempty"`
+}
+
+// InstallReporter receives installation progress.
+type InstallReporter interface {
+	// Report records a structured event.
+	//
+	// Parameters:
+	//   - event: InstallEvent event to record, Time is filled if zero
+	//
+	// Returns:
+	//   - err: error if the event cannot be written
+	Report(event InstallEvent) error
+
+	// Output returns the writer handed to repository setup handlers.
+	//
+	// Returns:
+	//   - w: io.Writer receiving free-form progress
+	Output() io.Writer
+}
+
+// textInstallReporter renders events as human-readable lines.
+type textInstallReporter struct {
+	// bw buffers output shared by events and handler progress
+	bw *bufferedWriter
+}
+
+// NewTextInstallReporter creates a reporter printing human-readable progress.
+//
+// Parameters:
+//   - w: io.Writer destination
+//
+// Returns:
+//   - reporter: InstallReporter writing text
+func NewTextInstallReporter(w io.Writer) InstallReporter {
+	return &textInstallReporter{bw: &bufferedWriter{Writer: bufio.NewWriter(w)}}
+}
+
+// Report renders the event as text and flushes it.
+//
+// Parameters:
+//   - event: InstallEvent event to render
+//
+// Returns:
+//   - err: error if writing fails
+func (r *textInstallReporter) Report(event InstallEvent) error {
+	switch event.Type {
+	case EventStarted:
+		r.bw.Printf("Starting repository setup on %s\n", event.Target)
+	case EventConnected:
+		r.bw.Printf("Connected to %s\n", event.Target)
+	case EventPreflight:
+		r.bw.Printf("%s", event.Preflight.Format())
+	case EventDetected:
+		r.bw.Printf("Detected distribution: %s\n", event.Distro)
+	case EventWarning:
+		r.bw.Printf("Warning: %s\n", event.Message)
+	case EventCompleted:
+		r.bw.Printf("Repository setup completed successfully on %s\n", event.Target)
+		r.bw.Printf("You can now install superviz.io with:\n%s", event.Message)
+	}
+	return r.bw.Error()
+}
+
+// Output returns the shared buffered writer.
+//
+// Returns:
+//   - w: io.Writer receiving handler progress
+func (r *textInstallReporter) Output() io.Writer {
+	return r.bw
+}
+
+// structuredInstallReporter encodes events as a JSON or YAML stream.
+type structuredInstallReporter struct {
+	// mu serializes encoder access
+	mu sync.Mutex
+	// enc encodes one event per record
+	enc utils.Encoder
+	// now returns the event timestamp
+	now func() time.Time
+	// pending holds handler text not yet terminated by a newline
+	pending bytes.Buffer
+	// err is the first encoding error, returned by subsequent calls
+	err error
+}
+
+// NewStructuredInstallReporter creates a reporter encoding one record per event.
+//
+// JSON output is newline-delimited (NDJSON); YAML output is a multi-document stream.
+//
+// Parameters:
+//   - w: io.Writer destination
+//   - format: utils.OutputFormat utils.OutputJSON or utils.OutputYAML
+//
+// Returns:
+//   - reporter: InstallReporter encoding events
+//   - err: error if the format is not structured
+func NewStructuredInstallReporter(w io.Writer, format utils.OutputFormat) (InstallReporter, error) {
+	enc, err := utils.NewEncoder(w, format)
+	if err != nil {
+		return nil, err
+	}
+	return &structuredInstallReporter{enc: enc, now: time.Now}, nil
+}
+
+// Report encodes the event.
+//
+// Parameters:
+//   - event: InstallEvent event to encode
+//
+// Returns:
+//   - err: error if encoding fails
+func (r *structuredInstallReporter) Report(event InstallEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.encode(event)
+}
+
+// encode stamps and encodes the event; the caller holds mu.
+//
+// The first encoding error is kept and returned by every later call so
+// that failures in StepObserver callbacks are not lost.
+func (r *structuredInstallReporter) encode(event InstallEvent) error {
+	if r.err != nil {
+		return r.err
+	}
+	if event.Time.IsZero() {
+		event.Time = r.now().UTC()
+	}
+	if err := r.enc.Encode(event); err != nil {
+		r.err = fmt.Errorf("failed to encode event: %w", err)
+	}
+	return r.err
+}
+
+// Output returns the reporter itself so handler text becomes message events.
+//
+// Returns:
+//   - w: io.Writer converting lines into EventMessage records
+func (r *structuredInstallReporter) Output() io.Writer {
+	return r
+}
+
+// Write converts complete lines of handler output into message events.
+//
+// Parameters:
+//   - p: []byte text written by a setup handler
+//
+// Returns:
+//   - n: int number of bytes consumed
+//   - err: error if encoding fails
+func (r *structuredInstallReporter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pending.Write(p)
+	for {
+		line, err := r.pending.ReadString('\n')
+		if err != nil {
+			// Keep the incomplete line for the next write
+			r.pending.Reset()
+			r.pending.WriteString(line)
+			return len(p), nil
+		}
+		msg := line[:len(line)-1]
+		if msg == "" {
+			continue
+		}
+		if err := r.encode(InstallEvent{Type: EventMessage, Message: msg}); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// StepStarted implements common.StepObserver.
+//
+// Encoding errors are kept and surfaced by the next Report call.
+//
+// Parameters:
+//   - step: int 1-based command index
+//   - total: int number of commands
+//   - command: string command about to run
+func (r *structuredInstallReporter) StepStarted(step, total int, command string) {
+	_ = r.Report(InstallEvent{Type: EventStepStarted, Step: step, Total: total, Command: command}) //nolint:errcheck // sticky error
+}
+
+// StepFinished implements common.StepObserver.
+//
+// Encoding errors are kept and surfaced by the next Report call.
+//
+// Parameters:
+//   - step: int 1-based command index
+//   - total: int number of commands
+//   - command: string command that ran
+//   - err: error returned by the command, nil on success
+func (r *structuredInstallReporter) StepFinished(step, total int, command string, err error) {
+	event := InstallEvent{Type: EventStepFinished, Step: step, Total: total, Command: command}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	_ = r.Report(event) //nolint:errcheck // sticky error
+}
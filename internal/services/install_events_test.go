@@ -0,0 +1,217 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services/repository/common"
+	"github.com/kodflow/superviz.io/internal/utils"
+)
+
+// decodeEvents parses an NDJSON event stream.
+func decodeEvents(t *testing.T, data []byte) []InstallEvent {
+	t.Helper()
+	var events []InstallEvent
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var event InstallEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event), "line: %s", scanner.Text())
+		events = append(events, event)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+// eventTypes lists the types of events in order.
+func eventTypes(events []InstallEvent) []InstallEventType {
+	types := make([]InstallEventType, 0, len(events))
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func TestNewStructuredInstallReporter_TextFormat(t *testing.T) {
+	_, err := NewStructuredInstallReporter(io.Discard, utils.OutputText)
+	assert.Error(t, err)
+}
+
+func TestStructuredInstallReporter_WriteConvertsLines(t *testing.T) {
+	var buf bytes.Buffer
+	reporter, err := NewStructuredInstallReporter(&buf, utils.OutputJSON)
+	require.NoError(t, err)
+
+	w := reporter.Output()
+	_, err = fmt.Fprint(w, "first line\nsecond ")
+	require.NoError(t, err)
+	_, err = fmt.Fprint(w, "line\n\n")
+	require.NoError(t, err)
+
+	events := decodeEvents(t, buf.Bytes())
+	require.Len(t, events, 2)
+	assert.Equal(t, EventMessage, events[0].Type)
+	assert.Equal(t, "first line", events[0].Message)
+	assert.Equal(t, "second line", events[1].Message)
+	assert.False(t, events[0].Time.IsZero())
+}
+
+func TestStructuredInstallReporter_StepObserver(t *testing.T) {
+	var buf bytes.Buffer
+	reporter, err := NewStructuredInstallReporter(&buf, utils.OutputJSON)
+	require.NoError(t, err)
+
+	observer, ok := reporter.Output().(common.StepObserver)
+	require.True(t, ok)
+
+	observer.StepStarted(1, 2, "apt update")
+	observer.StepFinished(1, 2, "apt update", errors.New("exit status 100"))
+
+	events := decodeEvents(t, buf.Bytes())
+	require.Len(t, events, 2)
+	assert.Equal(t, InstallEvent{Type: EventStepStarted, Time: events[0].Time, Step: 1, Total: 2, Command: "apt update"}, events[0])
+	assert.Equal(t, EventStepFinished, events[1].Type)
+	assert.Equal(t, "exit status 100", events[1].Error)
+}
+
+func TestStructuredInstallReporter_StickyError(t *testing.T) {
+	reporter, err := NewStructuredInstallReporter(&failingWriter{shouldFail: true}, utils.OutputJSON)
+	require.NoError(t, err)
+
+	observer := reporter.Output().(common.StepObserver)
+	observer.StepStarted(1, 1, "true")
+
+	err = reporter.Report(InstallEvent{Type: EventStarted})
+	assert.ErrorContains(t, err, "failed to encode event")
+}
+
+func TestStructuredInstallReporter_YAML(t *testing.T) {
+	var buf bytes.Buffer
+	reporter, err := NewStructuredInstallReporter(&buf, utils.OutputYAML)
+	require.NoError(t, err)
+
+	require.NoError(t, reporter.Report(InstallEvent{Type: EventStarted, Target: "u@h"}))
+	require.NoError(t, reporter.Report(InstallEvent{Type: EventConnected, Target: "u@h"}))
+
+	dec := yaml.NewDecoder(&buf)
+	var docs []map[string]any
+	for {
+		var doc map[string]any
+		if err := dec.Decode(&doc); err != nil {
+			require.ErrorIs(t, err, io.EOF)
+			break
+		}
+		docs = append(docs, doc)
+	}
+	require.Len(t, docs, 2)
+	assert.Equal(t, "started", docs[0]["type"])
+	assert.Equal(t, "connected", docs[1]["type"])
+}
+
+func TestTextInstallReporter_Report(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := NewTextInstallReporter(&buf)
+
+	require.NoError(t, reporter.Report(InstallEvent{Type: EventStarted, Target: "u@h"}))
+	_, err := fmt.Fprint(reporter.Output(), "handler output\n")
+	require.NoError(t, err)
+	require.NoError(t, reporter.Report(InstallEvent{Type: EventWarning, Message: "careful"}))
+	require.NoError(t, reporter.Report(InstallEvent{Type: EventSummary, Summary: &InstallSummary{}}))
+
+	assert.Equal(t, "Starting repository setup on u@h\nhandler output\nWarning: careful\n", buf.String())
+}
+
+func TestInstallService_InstallWithReporter_JSON(t *testing.T) {
+	client := &mockSSHClient{}
+	detector := &mockDistroDetector{}
+	repoSetup := &mockRepoSetup{}
+
+	client.On("Connect", mock.Anything, mock.Anything).Return(nil)
+	client.On("Close").Return(nil)
+	detector.On("Detect", mock.Anything).Return("ubuntu", nil)
+	repoSetup.On("Setup", mock.Anything, "ubuntu", mock.Anything).Run(func(args mock.Arguments) {
+		w := args.Get(2).(io.Writer)
+		if observer, ok := w.(common.StepObserver); ok {
+			observer.StepStarted(1, 1, "apt update")
+			observer.StepFinished(1, 1, "apt update", nil)
+		}
+		_, _ = fmt.Fprintln(w, "Setting up APT repository") //nolint:errcheck
+	}).Return(nil)
+
+	service := NewInstallService(&InstallServiceOptions{
+		SSHClient:      client,
+		DistroDetector: detector,
+		RepoSetup:      repoSetup,
+		Preflight:      &mockPreflightChecker{},
+	})
+	config := &providers.InstallConfig{Host: "h", User: "u", Target: "u@h"}
+
+	var buf bytes.Buffer
+	reporter, err := NewStructuredInstallReporter(&buf, utils.OutputJSON)
+	require.NoError(t, err)
+
+	err = service.InstallWithReporter(context.Background(), reporter, config)
+	require.NoError(t, err)
+
+	events := decodeEvents(t, buf.Bytes())
+	assert.Equal(t, []InstallEventType{
+		EventStarted, EventConnected, EventPreflight, EventDetected,
+		EventStepStarted, EventStepFinished, EventMessage,
+		EventCompleted, EventSummary,
+	}, eventTypes(events))
+
+	summary := events[len(events)-1].Summary
+	require.NotNil(t, summary)
+	assert.True(t, summary.Success)
+	assert.Equal(t, "u@h", summary.Target)
+	assert.Equal(t, "ubuntu", summary.Distro)
+	assert.Equal(t, "sudo apt update && sudo apt install superviz", summary.InstallCommand)
+	assert.NotNil(t, events[2].Preflight)
+}
+
+func TestInstallService_InstallWithReporter_Failure(t *testing.T) {
+	client := &mockSSHClient{}
+	client.On("Connect", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+
+	service := NewInstallService(&InstallServiceOptions{
+		SSHClient:      client,
+		DistroDetector: &mockDistroDetector{},
+		RepoSetup:      &mockRepoSetup{},
+		Preflight:      &mockPreflightChecker{},
+	})
+	config := &providers.InstallConfig{Host: "h", User: "u", Target: "u@h"}
+
+	var buf bytes.Buffer
+	reporter, err := NewStructuredInstallReporter(&buf, utils.OutputJSON)
+	require.NoError(t, err)
+
+	err = service.InstallWithReporter(context.Background(), reporter, config)
+	require.Error(t, err)
+
+	events := decodeEvents(t, buf.Bytes())
+	assert.Equal(t, []InstallEventType{EventStarted, EventFailed, EventSummary}, eventTypes(events))
+	summary := events[2].Summary
+	require.NotNil(t, summary)
+	assert.False(t, summary.Success)
+	assert.True(t, strings.Contains(summary.Error, "connection refused"))
+	assert.GreaterOrEqual(t, summary.DurationMS, int64(0))
+}
+
+func TestInstallService_InstallWithReporter_NilArguments(t *testing.T) {
+	service := NewInstallService(nil)
+
+	assert.ErrorIs(t, service.InstallWithReporter(context.Background(), nil, &providers.InstallConfig{}), ErrNilWriter)
+	assert.ErrorIs(t, service.InstallWithReporter(context.Background(), NewTextInstallReporter(io.Discard), nil), ErrNilConfig)
+}
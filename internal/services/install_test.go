@@ -5,7 +5,9 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,8 +15,12 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing/tracingtest"
 	"github.com/kodflow/superviz.io/internal/infrastructure/transports/ssh"
 	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services/repository"
+	"github.com/kodflow/superviz.io/internal/utils"
 )
 
 // Mock implementations
@@ -80,6 +86,21 @@ func (m *mockRepoSetup) Setup(ctx context.Context, distro string, w io.Writer) e
 	return args.Error(0)
 }
 
+type mockPreflightChecker struct {
+	status PreflightStatus
+}
+
+func (m *mockPreflightChecker) Check(_ context.Context, target string) *PreflightReport {
+	status := m.status
+	if status == "" {
+		status = PreflightPass
+	}
+	return &PreflightReport{
+		Target:  target,
+		Results: []PreflightResult{{Check: "mock", Status: status, Message: "mock check"}},
+	}
+}
+
 // Tests for bufferedWriter
 
 func TestBufferedWriter_Write(t *testing.T) {
@@ -236,6 +257,7 @@ func TestNewInstallService_WithOptions(t *testing.T) {
 		SSHClient:      client,
 		DistroDetector: detector,
 		RepoSetup:      repoSetup,
+		Preflight:      &mockPreflightChecker{},
 	}
 
 	service := NewInstallService(opts)
@@ -331,12 +353,14 @@ func TestInstallService_Install_Success(t *testing.T) {
 	client.On("Close").Return(nil)
 	detector.On("Detect", mock.Anything).Return("ubuntu", nil)
 	repoSetup.On("Setup", mock.Anything, "ubuntu", mock.Anything).Return(nil)
+	provider.On("GetPackageName").Return("superviz")
 
 	opts := &InstallServiceOptions{
 		Provider:       provider,
 		SSHClient:      client,
 		DistroDetector: detector,
 		RepoSetup:      repoSetup,
+		Preflight:      &mockPreflightChecker{},
 	}
 
 	service := NewInstallService(opts)
@@ -364,6 +388,41 @@ func TestInstallService_Install_Success(t *testing.T) {
 	repoSetup.AssertExpectations(t)
 }
 
+func TestInstallService_Install_Traces(t *testing.T) {
+	recorder := tracingtest.Install(t)
+	client := &mockSSHClient{}
+	client.On("Connect", mock.Anything, mock.Anything).Return(nil)
+	client.On("Close").Return(nil)
+	client.On("Execute", mock.Anything, mock.MatchedBy(func(command string) bool {
+		return command == "test -f /etc/os-release" || strings.Contains(command, "ID=ubuntu")
+	})).Return(nil)
+	client.On("Execute", mock.Anything, mock.Anything).Return(errors.New("exit status 1"))
+	repoSetup := &mockRepoSetup{}
+	repoSetup.On("Setup", mock.Anything, "ubuntu", mock.Anything).Return(errors.New("no network"))
+
+	service := NewInstallService(&InstallServiceOptions{
+		Provider:       &mockInstallProvider{},
+		SSHClient:      client,
+		DistroDetector: NewDetector(client),
+		RepoSetup:      repoSetup,
+		Preflight:      &mockPreflightChecker{},
+	})
+	config := &providers.InstallConfig{Host: "test.example.com", User: "testuser", Target: "testuser@test.example.com"}
+	require.Error(t, service.Install(context.Background(), &bytes.Buffer{}, config))
+
+	spans := recorder.Spans()
+	require.Equal(t, []string{"preflight.check", "distro.detect", "install"}, tracingtest.Names(spans))
+	preflight, detect, install := spans[0], spans[1], spans[2]
+	assert.Equal(t, install.SpanID, preflight.ParentSpanID)
+	assert.Equal(t, install.SpanID, detect.ParentSpanID)
+	assert.Contains(t, preflight.Attributes, otlp.Attribute("preflight.status", "pass"))
+	assert.Contains(t, detect.Attributes, otlp.Attribute("os.distro", "ubuntu"))
+	assert.Contains(t, install.Attributes, otlp.Attribute("server.address", "test.example.com"))
+	assert.Contains(t, install.Attributes, otlp.Attribute("os.distro", "ubuntu"))
+	assert.Equal(t, otlp.StatusError, install.Status.Code)
+	assert.Contains(t, install.Status.Message, "failed to setup repository: no network")
+}
+
 func TestInstallService_Install_NilWriter(t *testing.T) {
 	service := NewInstallService(nil)
 	config := &providers.InstallConfig{}
@@ -419,6 +478,7 @@ func TestInstallService_Install_DetectionError(t *testing.T) {
 	opts := &InstallServiceOptions{
 		SSHClient:      client,
 		DistroDetector: detector,
+		Preflight:      &mockPreflightChecker{},
 	}
 
 	service := NewInstallService(opts)
@@ -452,6 +512,7 @@ func TestInstallService_Install_RepoSetupError(t *testing.T) {
 		SSHClient:      client,
 		DistroDetector: detector,
 		RepoSetup:      repoSetup,
+		Preflight:      &mockPreflightChecker{},
 	}
 
 	service := NewInstallService(opts)
@@ -486,6 +547,7 @@ func TestInstallService_Install_CloseError(t *testing.T) {
 		SSHClient:      client,
 		DistroDetector: detector,
 		RepoSetup:      repoSetup,
+		Preflight:      &mockPreflightChecker{},
 	}
 
 	service := NewInstallService(opts)
@@ -589,7 +651,7 @@ func TestInstallService_GetInstallCommand(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.distro, func(t *testing.T) {
-			result := service.getInstallCommand(tt.distro)
+			result := service.getInstallCommand(tt.distro, "superviz")
 			assert.Equal(t, tt.expected, result)
 		})
 	}
@@ -631,7 +693,7 @@ func TestInstallCommands(t *testing.T) {
 			cmd, ok := installCommands[distro]
 			assert.True(t, ok, "Missing command for distribution: %s", distro)
 			assert.NotEmpty(t, cmd, "Empty command for distribution: %s", distro)
-			assert.Contains(t, cmd, "superviz", "Command should contain 'superviz': %s", cmd)
+			assert.Contains(t, fmt.Sprintf(cmd, "superviz"), " superviz", "Command should install 'superviz': %s", cmd)
 		})
 	}
 }
@@ -648,6 +710,7 @@ func TestInstallService_Install_CloseErrorLogging(t *testing.T) {
 		SSHClient:      sshClient,
 		DistroDetector: detector,
 		RepoSetup:      repoSetup,
+		Preflight:      &mockPreflightChecker{},
 	}
 
 	service := NewInstallService(opts)
@@ -665,6 +728,7 @@ func TestInstallService_Install_CloseErrorLogging(t *testing.T) {
 	sshClient.On("Close").Return(errors.New("close failed"))
 	detector.On("Detect", mock.Anything).Return("ubuntu", nil)
 	repoSetup.On("Setup", mock.Anything, "ubuntu", mock.Anything).Return(nil)
+	provider.On("GetPackageName").Return("superviz")
 
 	err := service.Install(context.Background(), &output, config)
 
@@ -679,3 +743,250 @@ func TestInstallService_Install_CloseErrorLogging(t *testing.T) {
 	detector.AssertExpectations(t)
 	repoSetup.AssertExpectations(t)
 }
+
+func TestInstallService_Install_PreflightFailure(t *testing.T) {
+	client := &mockSSHClient{}
+	detector := &mockDistroDetector{}
+
+	client.On("Connect", mock.Anything, mock.Anything).Return(nil)
+	client.On("Close").Return(nil)
+
+	service := NewInstallService(&InstallServiceOptions{
+		SSHClient:      client,
+		DistroDetector: detector,
+		RepoSetup:      &mockRepoSetup{},
+		Preflight:      &mockPreflightChecker{status: PreflightFail},
+	})
+	config := &providers.InstallConfig{Host: "h", User: "u", Target: "u@h"}
+
+	var output bytes.Buffer
+	err := service.Install(context.Background(), &output, config)
+
+	assert.ErrorIs(t, err, ErrPreflightFailed)
+	assert.Contains(t, err.Error(), "--skip-preflight")
+	assert.Contains(t, output.String(), "Preflight checks on u@h")
+	assert.Contains(t, output.String(), "Result: fail")
+	detector.AssertNotCalled(t, "Detect", mock.Anything)
+}
+
+func TestInstallService_Install_SkipPreflight(t *testing.T) {
+	client := &mockSSHClient{}
+	detector := &mockDistroDetector{}
+	repoSetup := &mockRepoSetup{}
+
+	client.On("Connect", mock.Anything, mock.Anything).Return(nil)
+	client.On("Close").Return(nil)
+	detector.On("Detect", mock.Anything).Return("alpine", nil)
+	repoSetup.On("Setup", mock.Anything, "alpine", mock.Anything).Return(nil)
+
+	service := NewInstallService(&InstallServiceOptions{
+		SSHClient:      client,
+		DistroDetector: detector,
+		RepoSetup:      repoSetup,
+		Preflight:      &mockPreflightChecker{status: PreflightFail},
+	})
+	config := &providers.InstallConfig{Host: "h", User: "u", Target: "u@h", SkipPreflight: true}
+
+	var output bytes.Buffer
+	err := service.Install(context.Background(), &output, config)
+
+	assert.NoError(t, err)
+	assert.NotContains(t, output.String(), "Preflight checks")
+	repoSetup.AssertExpectations(t)
+}
+
+// mirrorRepoSetup is a repository setup recording the mirror it was given.
+type mirrorRepoSetup struct {
+	mockRepoSetup
+	mirror string
+}
+
+func (m *mirrorRepoSetup) WithMirror(baseURL string) repository.Setup {
+	m.mirror = baseURL
+	return m
+}
+
+func TestInstallService_Install_RepoMirror(t *testing.T) {
+	client := &mockSSHClient{}
+	detector := &mockDistroDetector{}
+	repoSetup := &mirrorRepoSetup{}
+
+	client.On("Connect", mock.Anything, mock.Anything).Return(nil)
+	client.On("Close").Return(nil)
+	detector.On("Detect", mock.Anything).Return("debian", nil)
+	repoSetup.On("Setup", mock.Anything, "debian", mock.Anything).Return(nil)
+
+	service := NewInstallService(&InstallServiceOptions{
+		SSHClient:      client,
+		DistroDetector: detector,
+		RepoSetup:      repoSetup,
+		Preflight:      &mockPreflightChecker{},
+	})
+	config := &providers.InstallConfig{Host: "h", User: "u", Target: "u@h", RepoMirror: "https://mirror.example.com"}
+
+	err := service.Install(context.Background(), io.Discard, config)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://mirror.example.com", repoSetup.mirror)
+	repoSetup.AssertExpectations(t)
+}
+
+func TestInstallService_Install_RepoMirrorUnsupported(t *testing.T) {
+	client := &mockSSHClient{}
+
+	service := NewInstallService(&InstallServiceOptions{
+		SSHClient:      client,
+		DistroDetector: &mockDistroDetector{},
+		RepoSetup:      &mockRepoSetup{},
+		Preflight:      &mockPreflightChecker{},
+	})
+	config := &providers.InstallConfig{Host: "h", User: "u", Target: "u@h", RepoMirror: "https://mirror.example.com"}
+
+	err := service.Install(context.Background(), io.Discard, config)
+
+	assert.ErrorContains(t, err, "does not support mirrors")
+	client.AssertNotCalled(t, "Connect", mock.Anything, mock.Anything)
+}
+
+func TestInstallService_ValidateAndPrepareConfig_RepoMirror(t *testing.T) {
+	service := NewInstallService(nil)
+
+	config := &providers.InstallConfig{RepoMirror: "https://mirror.example.com/superviz"}
+	assert.NoError(t, service.ValidateAndPrepareConfig(config, []string{"u@h"}))
+
+	config = &providers.InstallConfig{RepoMirror: "http://mirror.example.com"}
+	assert.ErrorContains(t, service.ValidateAndPrepareConfig(config, []string{"u@h"}), "invalid repository mirror")
+}
+
+func TestNewSSHConfig_JumpHost(t *testing.T) {
+	config := &providers.InstallConfig{Host: "h", User: "u", Port: 22, JumpHost: "ops@bastion"}
+
+	assert.Equal(t, "ops@bastion", newSSHConfig(config).JumpHost)
+}
+
+// releaseVersionProvider reports a released CLI version.
+type releaseVersionProvider string
+
+func (p releaseVersionProvider) GetVersionInfo() providers.VersionInfo {
+	return providers.VersionInfo{Version: string(p)}
+}
+
+// outputSSHClient answers every output query with the same package listing.
+type outputSSHClient struct {
+	mockSSHClient
+	output string
+}
+
+func (c *outputSSHClient) ExecuteOutput(_ context.Context, _ string) (string, error) {
+	return c.output, nil
+}
+
+func newVersionedInstallService(client ssh.Client, cli string) *InstallService {
+	detector := &mockDistroDetector{}
+	detector.On("Detect", mock.Anything).Return("debian", nil)
+	repoSetup := &mockRepoSetup{}
+	repoSetup.On("Setup", mock.Anything, "debian", mock.Anything).Return(nil)
+
+	return NewInstallService(&InstallServiceOptions{
+		SSHClient:      client,
+		DistroDetector: detector,
+		RepoSetup:      repoSetup,
+		Preflight:      &mockPreflightChecker{},
+		Versions:       NewVersionService(releaseVersionProvider(cli)),
+	})
+}
+
+func TestInstallService_ValidateAndPrepareConfig_Version(t *testing.T) {
+	service := NewInstallService(nil)
+
+	config := &providers.InstallConfig{Version: "1.3.0-1"}
+	assert.NoError(t, service.ValidateAndPrepareConfig(config, []string{"user@host"}))
+
+	config = &providers.InstallConfig{Version: "1.3; reboot"}
+	assert.ErrorContains(t, service.ValidateAndPrepareConfig(config, []string{"user@host"}), "invalid agent version")
+}
+
+func TestInstallService_Install_PinnedVersion(t *testing.T) {
+	client := &mockSSHClient{}
+	client.On("Connect", mock.Anything, mock.Anything).Return(nil)
+	client.On("Close").Return(nil)
+	service := newVersionedInstallService(client, "1.3.2")
+
+	var buf bytes.Buffer
+	reporter, err := NewStructuredInstallReporter(&buf, utils.OutputJSON)
+	require.NoError(t, err)
+	config := &providers.InstallConfig{Host: "h", User: "u", Target: "u@h", Version: "1.4.0-1"}
+
+	require.NoError(t, service.InstallWithReporter(context.Background(), reporter, config))
+
+	events := decodeEvents(t, buf.Bytes())
+	summary := events[len(events)-1].Summary
+	require.NotNil(t, summary)
+	assert.Equal(t, "1.4.0-1", summary.AgentVersion)
+	assert.Equal(t, "sudo apt update && sudo apt install superviz=1.4.0-1", summary.InstallCommand)
+}
+
+func TestInstallService_Install_PinnedVersionOutsideWindow(t *testing.T) {
+	client := &mockSSHClient{}
+	service := newVersionedInstallService(client, "1.3.2")
+	config := &providers.InstallConfig{Host: "h", User: "u", Target: "u@h", Version: "1.5.0"}
+
+	err := service.Install(context.Background(), io.Discard, config)
+
+	assert.ErrorIs(t, err, ErrIncompatibleVersion)
+	client.AssertNotCalled(t, "Connect", mock.Anything, mock.Anything)
+}
+
+func TestInstallService_Install_RepositoryCandidate(t *testing.T) {
+	tests := map[string]struct {
+		cli     string
+		listing string
+		wantErr bool
+	}{
+		"compatible candidate":   {cli: "1.3.2", listing: "superviz:\n  Installed: (none)\n  Candidate: 1.4.1-1\n"},
+		"candidate too new":      {cli: "1.3.2", listing: "superviz:\n  Installed: (none)\n  Candidate: 1.5.0-1\n", wantErr: true},
+		"candidate too old":      {cli: "2.0.0", listing: "superviz:\n  Installed: (none)\n  Candidate: 1.4.1-1\n", wantErr: true},
+		"development build skip": {cli: "dev", listing: "superviz:\n  Installed: (none)\n  Candidate: 0.1.0-1\n"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := &outputSSHClient{output: tt.listing}
+			client.On("Connect", mock.Anything, mock.Anything).Return(nil)
+			client.On("Close").Return(nil)
+			service := newVersionedInstallService(client, tt.cli)
+			service.provider = providers.DefaultInstallProvider()
+
+			err := service.Install(context.Background(), io.Discard, &providers.InstallConfig{Host: "h", User: "u", Target: "u@h"})
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrIncompatibleVersion)
+				assert.ErrorContains(t, err, "pin a supported release with --version")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestInstallService_Install_CandidateUnknown(t *testing.T) {
+	client := &mockSSHClient{}
+	client.On("Connect", mock.Anything, mock.Anything).Return(nil)
+	client.On("Close").Return(nil)
+	service := newVersionedInstallService(client, "1.3.2")
+
+	var output bytes.Buffer
+	err := service.Install(context.Background(), &output, &providers.InstallConfig{Host: "h", User: "u", Target: "u@h"})
+
+	assert.NoError(t, err)
+	assert.Contains(t, output.String(), "Warning: could not determine the agent version offered by the repository")
+}
+
+func TestInstallPackageSpec(t *testing.T) {
+	service := NewInstallService(nil)
+	assert.Equal(t, "  sudo apt update && sudo apt install superviz=1.3.0-1\n", service.getInstallCommand("debian", installPackageSpec("debian", "superviz", "1.3.0-1")))
+	assert.Equal(t, "  sudo yum install superviz-1.3.0  # or dnf install superviz-1.3.0\n", service.getInstallCommand("rhel", installPackageSpec("rhel", "superviz", "1.3.0")))
+	assert.Equal(t, "superviz-agent=2.0.0", installPackageSpec("alpine", "superviz-agent", "2.0.0"), "the provider package name is used")
+	assert.Equal(t, "superviz", installPackageSpec("gentoo", "superviz", "1.3.0"))
+	assert.Equal(t, "superviz", installPackageSpec("debian", "superviz", ""))
+}
@@ -0,0 +1,357 @@
+// Package integrity checks critical files against their expected digests,
+// declared in superviz.yaml or in a signed manifest.
+package integrity
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+// Status is the outcome of the check of one file.
+type Status string
+
+// Check outcomes.
+const (
+	// StatusOK means the file has its expected digest or is listed in the manifest
+	StatusOK Status = "ok"
+	// StatusMismatch means the file content changed
+	StatusMismatch Status = "mismatch"
+	// StatusMissing means the file, or every file matching a glob, is absent
+	StatusMissing Status = "missing"
+	// StatusUnlisted means the file has no digest and is absent from the manifest
+	StatusUnlisted Status = "unlisted"
+	// StatusError means the file or the manifest cannot be read or verified
+	StatusError Status = "error"
+)
+
+// Result is the check of one file.
+type Result struct {
+	// Path is the file checked, or the manifest when it cannot be loaded
+	Path string `json:"path" yaml:"path"`
+	// Status is the outcome
+	Status Status `json:"status" yaml:"status"`
+	// Algorithm is sha256 or sha512, empty for files checked against the manifest listing
+	Algorithm string `json:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+	// Expected is the declared hex digest
+	Expected string `json:"expected,omitempty" yaml:"expected,omitempty"`
+	// Actual is the hex digest of the file, set for ok and mismatch results
+	Actual string `json:"actual,omitempty" yaml:"actual,omitempty"`
+	// Message explains a missing or error result
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+	// Restored names the source the file was copied back from
+	Restored string `json:"restored,omitempty" yaml:"restored,omitempty"`
+	// Services are refused while the file fails, every service when empty
+	Services []string `json:"services,omitempty" yaml:"services,omitempty"`
+	// source is the known-good copy of the file, empty when it cannot be restored
+	source string
+}
+
+// OK reports whether the file passed.
+//
+// Returns:
+//   - ok: true for StatusOK
+func (r Result) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Format describes the result on one line.
+//
+// Returns:
+//   - line: string such as "/usr/bin/vault: sha256 mismatch, expected 5f1c..., got 09ab..."
+func (r Result) Format() string {
+	var line string
+	switch r.Status {
+	case StatusMismatch:
+		line = fmt.Sprintf("%s: %s mismatch, expected %s, got %s", r.Path, r.Algorithm, r.Expected, r.Actual)
+	case StatusUnlisted:
+		line = r.Path + ": not listed in the manifest"
+	case StatusOK:
+		line = r.Path + ": ok"
+	default:
+		line = r.Path + ": " + string(r.Status)
+		if r.Message != "" {
+			line = r.Path + ": " + r.Message
+		}
+	}
+	if r.Restored != "" {
+		line += " (restored from " + r.Restored + ")"
+	}
+	return line
+}
+
+// Report is the check of every declared file.
+type Report struct {
+	// Results are sorted by path
+	Results []Result `json:"results" yaml:"results"`
+}
+
+// Failed returns the results that did not pass.
+//
+// Returns:
+//   - failed: []Result results in path order
+func (r *Report) Failed() []Result {
+	var failed []Result
+	for _, result := range r.Results {
+		if !result.OK() {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Format lists the results followed by a summary line.
+//
+// Returns:
+//   - formatted: string multi-line report ending with a newline
+func (r *Report) Format() string {
+	var b strings.Builder
+	for _, result := range r.Results {
+		b.WriteString(result.Format())
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(&b, "%d checked, %d failed\n", len(r.Results), len(r.Failed()))
+	return b.String()
+}
+
+// Digest computes the hex digest of a file.
+//
+// Parameters:
+//   - path: string file to read
+//   - algorithm: string providers.DigestSHA256 or providers.DigestSHA512
+//
+// Returns:
+//   - digest: string lower-case hex digest
+//   - err: error if the file cannot be read
+func Digest(path, algorithm string) (string, error) {
+	f, err := os.Open(path) //nolint:gosec // the path comes from the operator's configuration
+	if err != nil {
+		return "", err
+	}
+	defer f.Close() //nolint:errcheck // read-only file
+
+	h := newHash(algorithm)
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// newHash returns the hash of a digest algorithm, SHA-256 by default
+func newHash(algorithm string) hash.Hash {
+	if algorithm == providers.DigestSHA512 {
+		return sha512.New()
+	}
+	return sha256.New()
+}
+
+// LoadManifest reads a manifest and verifies its signature.
+//
+// Parameters:
+//   - path: string manifest location
+//   - signature: string file holding the base64 signature, empty for an unsigned manifest
+//   - key: ed25519.PublicKey verifying the signature, required with signature
+//
+// Returns:
+//   - manifest: *providers.IntegrityManifest validated manifest
+//   - err: error if the manifest cannot be read, is invalid or its signature does not verify
+func LoadManifest(path, signature string, key ed25519.PublicKey) (*providers.IntegrityManifest, error) {
+	manifest, data, err := providers.LoadIntegrityManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	if signature == "" {
+		return manifest, nil
+	}
+	if key == nil {
+		return nil, errors.New("a public key is required to verify the manifest signature")
+	}
+
+	encoded, err := os.ReadFile(signature) //nolint:gosec // the path comes from the operator's configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest signature: %w", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest signature: %w", err)
+	}
+	if !ed25519.Verify(key, data, sig) {
+		return nil, fmt.Errorf("manifest signature verification failed for %s", path)
+	}
+	return manifest, nil
+}
+
+// Check checks the files of an integrity section and of its manifest.
+//
+// A manifest that cannot be loaded or verified is reported as an error
+// result, and so are the files meant to be checked against its listing.
+//
+// Parameters:
+//   - config: *providers.IntegrityConfig validated section
+//
+// Returns:
+//   - report: *Report results sorted by path
+func Check(config *providers.IntegrityConfig) *Report {
+	report := &Report{}
+	var listed map[string]bool
+	var manifestErr error
+
+	if m := config.Manifest; m != nil {
+		key, err := m.Key()
+		var manifest *providers.IntegrityManifest
+		if err == nil {
+			manifest, err = LoadManifest(m.Path, m.Signature, key)
+		}
+		if err != nil {
+			manifestErr = err
+			report.Results = append(report.Results, Result{Path: m.Path, Status: StatusError, Message: err.Error(), Services: m.Services})
+		} else {
+			listed = make(map[string]bool, len(manifest.Files))
+			for i := range manifest.Files {
+				file := manifest.Files[i]
+				file.Services = m.Services
+				for _, result := range checkFile(&file, nil) {
+					listed[result.Path] = true
+					report.Results = append(report.Results, result)
+				}
+			}
+		}
+	}
+
+	for i := range config.Files {
+		file := &config.Files[i]
+		if algorithm, _ := file.Digest(); algorithm == "" && manifestErr != nil {
+			report.Results = append(report.Results, Result{Path: file.Path, Status: StatusError, Message: "manifest unavailable", Services: file.Services})
+			continue
+		}
+		report.Results = append(report.Results, checkFile(file, listed)...)
+	}
+
+	sort.SliceStable(report.Results, func(i, j int) bool { return report.Results[i].Path < report.Results[j].Path })
+	return report
+}
+
+// checkFile checks one entry, each file matching a glob separately; entries
+// without digest pass when the file is listed
+func checkFile(file *providers.IntegrityFile, listed map[string]bool) []Result {
+	paths := []string{file.Path}
+	if file.IsGlob() {
+		matches, err := filepath.Glob(file.Path)
+		if err != nil {
+			return []Result{{Path: file.Path, Status: StatusError, Message: err.Error(), Services: file.Services}}
+		}
+		paths = paths[:0]
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && !info.IsDir() {
+				paths = append(paths, match)
+			}
+		}
+		if len(paths) == 0 {
+			return []Result{{Path: file.Path, Status: StatusMissing, Message: "no file matches", Services: file.Services}}
+		}
+	}
+
+	algorithm, expected := file.Digest()
+	results := make([]Result, 0, len(paths))
+	for _, path := range paths {
+		result := Result{Path: path, Algorithm: algorithm, Expected: expected, Services: file.Services, source: file.Source}
+		if algorithm == "" {
+			if _, err := os.Stat(path); err != nil {
+				result.Status, result.Message = statusOf(err)
+			} else if listed[path] {
+				result.Status = StatusOK
+			} else {
+				result.Status = StatusUnlisted
+			}
+			results = append(results, result)
+			continue
+		}
+
+		actual, err := Digest(path, algorithm)
+		switch {
+		case err != nil:
+			result.Status, result.Message = statusOf(err)
+		case actual != expected:
+			result.Status, result.Actual = StatusMismatch, actual
+		default:
+			result.Status, result.Actual = StatusOK, actual
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// statusOf classifies a file error
+func statusOf(err error) (Status, string) {
+	if errors.Is(err, os.ErrNotExist) {
+		return StatusMissing, "missing"
+	}
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return StatusError, err.Error()
+}
+
+// restore copies source over path atomically, once its content has the
+// expected digest.
+//
+// Parameters:
+//   - source: string known-good copy
+//   - path: string file to replace
+//   - algorithm: string digest algorithm
+//   - expected: string expected hex digest
+//
+// Returns:
+//   - err: error if the source cannot be read, does not match or the file cannot be replaced
+func restore(source, path, algorithm, expected string) error {
+	in, err := os.Open(source) //nolint:gosec // the path comes from the operator's configuration
+	if err != nil {
+		return err
+	}
+	defer in.Close() //nolint:errcheck // read-only file
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".restore-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // gone once renamed
+
+	h := newHash(algorithm)
+	if _, err := io.Copy(io.MultiWriter(tmp, h), in); err != nil {
+		_ = tmp.Close() //nolint:errcheck // already failing
+		return err
+	}
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != expected {
+		_ = tmp.Close() //nolint:errcheck // already failing
+		return fmt.Errorf("source %s does not match either, got %s", source, actual)
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		_ = tmp.Close() //nolint:errcheck // already failing
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close() //nolint:errcheck // already failing
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,164 @@
+package integrity
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile creates a file under dir and returns its path and SHA-256 digest
+func writeFile(t *testing.T, dir, name, content string) (string, string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	sum := sha256.Sum256([]byte(content))
+	return path, hex.EncodeToString(sum[:])
+}
+
+// signManifest writes a manifest and its signature and returns their paths and the public key
+func signManifest(t *testing.T, dir, content string) (string, string, ed25519.PublicKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	path, _ := writeFile(t, dir, "manifest.yaml", content)
+	signature, _ := writeFile(t, dir, "manifest.yaml.sig", base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte(content)))+"\n")
+	return path, signature, public
+}
+
+func TestDigest(t *testing.T) {
+	path, want := writeFile(t, t.TempDir(), "a", "hello")
+	got, err := Digest(path, providers.DigestSHA256)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	sum := sha512.Sum512([]byte("hello"))
+	got, err = Digest(path, providers.DigestSHA512)
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(sum[:]), got)
+
+	_, err = Digest(path+".missing", providers.DigestSHA256)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestCheck_Files(t *testing.T) {
+	dir := t.TempDir()
+	vault, vaultSum := writeFile(t, dir, "bin/vault", "vault")
+	consul, _ := writeFile(t, dir, "bin/consul", "tampered")
+	_, confSum := writeFile(t, dir, "etc/a.conf", "same")
+	writeFile(t, dir, "etc/b.conf", "same")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "etc", "dir.conf"), 0o755))
+
+	report := Check(&providers.IntegrityConfig{Files: []providers.IntegrityFile{
+		{Path: vault, SHA256: vaultSum},
+		{Path: consul, SHA256: vaultSum, Services: []string{"consul"}},
+		{Path: filepath.Join(dir, "bin/nomad"), SHA256: vaultSum},
+		{Path: filepath.Join(dir, "etc/*.conf"), SHA256: confSum},
+		{Path: filepath.Join(dir, "lib/*.so"), SHA256: confSum},
+	}})
+
+	var lines []string
+	for _, result := range report.Results {
+		lines = append(lines, result.Format())
+	}
+	assert.Equal(t, []string{
+		consul + ": sha256 mismatch, expected " + vaultSum + ", got " + report.Results[0].Actual,
+		filepath.Join(dir, "bin/nomad") + ": missing",
+		vault + ": ok",
+		filepath.Join(dir, "etc/a.conf") + ": ok",
+		filepath.Join(dir, "etc/b.conf") + ": ok",
+		filepath.Join(dir, "lib/*.so") + ": no file matches",
+	}, lines, "results are sorted by path and directories are skipped")
+	assert.Equal(t, []string{"consul"}, report.Results[0].Services)
+	assert.Len(t, report.Failed(), 3)
+	assert.Contains(t, report.Format(), "6 checked, 3 failed\n")
+}
+
+func TestCheck_Manifest(t *testing.T) {
+	dir := t.TempDir()
+	_, vaultSum := writeFile(t, dir, "bin/vault", "vault")
+	writeFile(t, dir, "bin/backdoor", "nc -l")
+	manifest, signature, key := signManifest(t, dir, "files:\n  - path: bin/vault\n    sha256: "+vaultSum+"\n")
+
+	config := &providers.IntegrityConfig{
+		Files: []providers.IntegrityFile{{Path: filepath.Join(dir, "bin/*")}},
+		Manifest: &providers.IntegrityManifestSource{
+			Path: manifest, Signature: signature, PublicKey: base64.StdEncoding.EncodeToString(key), Services: []string{"vault"},
+		},
+	}
+	report := Check(config)
+	require.Len(t, report.Results, 3)
+	assert.Equal(t, Result{Path: filepath.Join(dir, "bin/backdoor"), Status: StatusUnlisted}, report.Results[0])
+	assert.Equal(t, filepath.Join(dir, "bin/backdoor")+": not listed in the manifest", report.Results[0].Format())
+	assert.Equal(t, Result{
+		Path: filepath.Join(dir, "bin/vault"), Status: StatusOK, Algorithm: providers.DigestSHA256, Expected: vaultSum, Actual: vaultSum, Services: []string{"vault"},
+	}, report.Results[1], "the vault binary has its digest")
+	assert.Equal(t, Result{Path: filepath.Join(dir, "bin/vault"), Status: StatusOK}, report.Results[2], "the vault binary is listed")
+
+	// A manifest changed after signing fails, with the files checked against it
+	require.NoError(t, os.WriteFile(manifest, []byte("files:\n  - path: bin/backdoor\n    sha256: "+vaultSum+"\n"), 0o600))
+	report = Check(config)
+	require.Len(t, report.Results, 2)
+	assert.Equal(t, filepath.Join(dir, "bin/*")+": manifest unavailable", report.Results[0].Format())
+	assert.Equal(t, Result{Path: manifest, Status: StatusError, Message: "manifest signature verification failed for " + manifest, Services: []string{"vault"}}, report.Results[1])
+}
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+	_, sum := writeFile(t, dir, "a", "a")
+	content := "files: [{path: a, sha256: " + sum + "}]\n"
+	manifest, signature, key := signManifest(t, dir, content)
+
+	loaded, err := LoadManifest(manifest, signature, key)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "a"), loaded.Files[0].Path)
+
+	_, err = LoadManifest(manifest, "", nil)
+	require.NoError(t, err, "unsigned manifests load without a key")
+
+	_, err = LoadManifest(manifest, signature, nil)
+	assert.EqualError(t, err, "a public key is required to verify the manifest signature")
+
+	other, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, err = LoadManifest(manifest, signature, other)
+	assert.EqualError(t, err, "manifest signature verification failed for "+manifest)
+
+	_, err = LoadManifest(manifest, filepath.Join(dir, "missing.sig"), key)
+	assert.ErrorContains(t, err, "failed to read manifest signature")
+
+	garbage, _ := writeFile(t, dir, "garbage.sig", "%%%")
+	_, err = LoadManifest(manifest, garbage, key)
+	assert.ErrorContains(t, err, "invalid manifest signature")
+}
+
+func TestRestore(t *testing.T) {
+	dir := t.TempDir()
+	source, sum := writeFile(t, dir, "golden/app", "good")
+	require.NoError(t, os.Chmod(source, 0o750))
+	target, _ := writeFile(t, dir, "bin/app", "bad")
+
+	require.NoError(t, restore(source, target, providers.DigestSHA256, sum))
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "good", string(data))
+	info, err := os.Stat(target)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o750), info.Mode().Perm(), "the source mode is kept")
+
+	other, _ := writeFile(t, dir, "golden/other", "also bad")
+	err = restore(other, target, providers.DigestSHA256, sum)
+	assert.ErrorContains(t, err, "source "+other+" does not match either")
+	entries, err := os.ReadDir(filepath.Join(dir, "bin"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary file is left behind")
+}
@@ -0,0 +1,160 @@
+// internal/services/integrity/monitor.go - Periodic integrity checks gating the service starts
+package integrity
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+// MonitorOptions configures a Monitor.
+//
+// All fields are optional.
+type MonitorOptions struct {
+	// Report receives each failure once per streak, and each restoration
+	Report func(Result)
+}
+
+// Monitor checks an integrity section and refuses the start of the services
+// depending on a failing file.
+type Monitor struct {
+	// config is the validated integrity section
+	config *providers.IntegrityConfig
+	// report receives the failures and restorations
+	report func(Result)
+	// mu guards last and alerted
+	mu sync.Mutex
+	// last is the latest report, nil before the first check
+	last *Report
+	// alerted records the failing paths already reported
+	alerted map[string]bool
+}
+
+// NewMonitor creates a monitor for a validated integrity section.
+//
+// Parameters:
+//   - config: *providers.IntegrityConfig files, manifest, interval and action
+//   - opts: *MonitorOptions overrides, nil for defaults
+//
+// Returns:
+//   - monitor: *Monitor ready to Check
+func NewMonitor(config *providers.IntegrityConfig, opts *MonitorOptions) *Monitor {
+	if opts == nil {
+		opts = &MonitorOptions{}
+	}
+	m := &Monitor{config: config, report: opts.Report, alerted: make(map[string]bool)}
+	if m.report == nil {
+		m.report = func(Result) {}
+	}
+	return m
+}
+
+// Check runs the checks once and applies the on_failure action.
+//
+// With on_failure: restore, a failing file with a source is copied back
+// from it, provided the source has the expected digest.
+//
+// Returns:
+//   - report: *Report latest results, restored files included
+func (m *Monitor) Check() *Report {
+	report := Check(m.config)
+	for i := range report.Results {
+		result := &report.Results[i]
+		if result.OK() || m.config.OnFailure != providers.IntegrityRestore || result.source == "" || result.Algorithm == "" {
+			continue
+		}
+		if err := restore(result.source, result.Path, result.Algorithm, result.Expected); err != nil {
+			result.Message = fmt.Sprintf("%s, restore failed: %v", strings.TrimPrefix(result.Format(), result.Path+": "), err)
+			result.Status = StatusError
+			continue
+		}
+		*result = Result{
+			Path: result.Path, Status: StatusOK, Algorithm: result.Algorithm, Expected: result.Expected,
+			Actual: result.Expected, Restored: result.source, Services: result.Services, source: result.source,
+		}
+		m.report(*result)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	failing := make(map[string]bool)
+	for _, result := range report.Failed() {
+		failing[result.Path] = true
+		if !m.alerted[result.Path] {
+			m.report(result)
+		}
+	}
+	m.alerted = failing
+	m.last = report
+	return report
+}
+
+// Last returns the latest report.
+//
+// Returns:
+//   - report: *Report latest results, nil before the first check
+func (m *Monitor) Last() *Report {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last
+}
+
+// Allow tells whether a service may start, as the supervisor asks before
+// every start attempt.
+//
+// Parameters:
+//   - service: string service about to start
+//
+// Returns:
+//   - err: error naming the first failing file the service depends on, nil with on_failure: alert
+func (m *Monitor) Allow(service string) error {
+	if m.config.OnFailure == providers.IntegrityAlert {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.last == nil {
+		return nil
+	}
+	for _, result := range m.last.Failed() {
+		if len(result.Services) == 0 || containsString(result.Services, service) {
+			return fmt.Errorf("integrity check failed: %s", result.Format())
+		}
+	}
+	return nil
+}
+
+// Run checks the files every interval, default 5m, until ctx is done.
+//
+// Parameters:
+//   - ctx: context.Context stopping the checks
+func (m *Monitor) Run(ctx context.Context) {
+	interval := m.config.Interval
+	if interval <= 0 {
+		interval = providers.DefaultIntegrityInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check()
+		}
+	}
+}
+
+// containsString reports whether list holds s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
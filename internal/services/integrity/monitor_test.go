@@ -0,0 +1,131 @@
+package integrity
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reports records the results handed to MonitorOptions.Report
+type reports struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+// add is the Report callback
+func (r *reports) add(result Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = append(r.lines, result.Format())
+}
+
+// get returns the recorded lines
+func (r *reports) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.lines...)
+}
+
+func TestMonitor_Refuse(t *testing.T) {
+	dir := t.TempDir()
+	vault, sum := writeFile(t, dir, "vault", "vault")
+	config := &providers.IntegrityConfig{
+		Files:     []providers.IntegrityFile{{Path: vault, SHA256: sum, Services: []string{"vault"}}},
+		OnFailure: providers.IntegrityRefuse,
+	}
+	log := &reports{}
+	m := NewMonitor(config, &MonitorOptions{Report: log.add})
+	assert.NoError(t, m.Allow("vault"), "nothing is refused before the first check")
+	assert.Nil(t, m.Last())
+
+	m.Check()
+	require.NoError(t, m.Allow("vault"))
+	assert.Empty(t, log.get())
+
+	require.NoError(t, os.WriteFile(vault, []byte("tampered"), 0o600))
+	m.Check()
+	m.Check()
+	assert.ErrorContains(t, m.Allow("vault"), "integrity check failed: "+vault+": sha256 mismatch")
+	assert.NoError(t, m.Allow("web"), "only the listed services are refused")
+	assert.Len(t, log.get(), 1, "a failure is reported once per streak")
+	assert.Len(t, m.Last().Failed(), 1)
+
+	require.NoError(t, os.WriteFile(vault, []byte("vault"), 0o600))
+	m.Check()
+	require.NoError(t, m.Allow("vault"))
+	require.NoError(t, os.Remove(vault))
+	m.Check()
+	assert.Equal(t, []string{log.get()[0], vault + ": missing"}, log.get(), "a new streak is reported again")
+}
+
+func TestMonitor_Alert(t *testing.T) {
+	dir := t.TempDir()
+	config := &providers.IntegrityConfig{
+		Files:     []providers.IntegrityFile{{Path: filepath.Join(dir, "missing"), SHA256: "00"}},
+		OnFailure: providers.IntegrityAlert,
+	}
+	log := &reports{}
+	m := NewMonitor(config, &MonitorOptions{Report: log.add})
+	m.Check()
+	assert.NoError(t, m.Allow("any"), "alert never refuses")
+	assert.Equal(t, []string{filepath.Join(dir, "missing") + ": missing"}, log.get())
+}
+
+func TestMonitor_Restore(t *testing.T) {
+	dir := t.TempDir()
+	golden, sum := writeFile(t, dir, "golden/app", "good")
+	app, _ := writeFile(t, dir, "bin/app", "bad")
+	broken, _ := writeFile(t, dir, "bin/broken", "bad")
+	badSource, badSum := writeFile(t, dir, "golden/broken", "also bad")
+	config := &providers.IntegrityConfig{
+		Files: []providers.IntegrityFile{
+			{Path: app, SHA256: sum, Source: golden},
+			{Path: broken, SHA256: sum, Source: badSource},
+		},
+		OnFailure: providers.IntegrityRestore,
+	}
+	log := &reports{}
+	m := NewMonitor(config, &MonitorOptions{Report: log.add})
+
+	report := m.Check()
+	data, err := os.ReadFile(app)
+	require.NoError(t, err)
+	assert.Equal(t, "good", string(data))
+	assert.Equal(t, StatusOK, report.Results[0].Status)
+	assert.Equal(t, []string{
+		app + ": ok (restored from " + golden + ")",
+		broken + ": sha256 mismatch, expected " + sum + ", got " + report.Results[1].Actual + ", restore failed: source " + badSource + " does not match either, got " + badSum,
+	}, log.get())
+	assert.Error(t, m.Allow("web"), "a file that cannot be restored refuses every service")
+}
+
+func TestMonitor_Run(t *testing.T) {
+	dir := t.TempDir()
+	app, sum := writeFile(t, dir, "app", "app")
+	config := &providers.IntegrityConfig{
+		Files:     []providers.IntegrityFile{{Path: app, SHA256: sum}},
+		Interval:  10 * time.Millisecond,
+		OnFailure: providers.IntegrityRefuse,
+	}
+	m := NewMonitor(config, nil)
+	m.Check()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.Run(ctx)
+	}()
+
+	require.NoError(t, os.WriteFile(app, []byte("changed"), 0o600))
+	assert.Eventually(t, func() bool { return m.Allow("app") != nil }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+}
@@ -91,3 +91,20 @@ type RepositorySetup interface {
 	//   - Error if repository setup fails
 	Setup(ctx context.Context, distro string, writer io.Writer) error
 }
+
+// ReleaseSource reports the published svz releases.
+//
+// ReleaseSource lets version checks query the release index without
+// depending on how it is fetched.
+type ReleaseSource interface {
+	// LatestRelease returns the current version of a release channel.
+	//
+	// Parameters:
+	//   - ctx: context.Context for timeout and cancellation
+	//   - channel: string release channel such as stable or beta
+	//
+	// Returns:
+	//   - String latest version of the channel
+	//   - Error if the index cannot be fetched or the channel is unknown
+	LatestRelease(ctx context.Context, channel string) (string, error)
+}
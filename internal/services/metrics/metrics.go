@@ -0,0 +1,160 @@
+// internal/services/metrics/metrics.go - Metric families of the supervised services
+package metrics
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/services/supervisor"
+)
+
+// Type is the kind of a metric family.
+type Type string
+
+// Metric types.
+const (
+	// TypeGauge is a sampled value that goes up and down
+	TypeGauge Type = "gauge"
+	// TypeCounter is a total that only increases while the supervisor runs
+	TypeCounter Type = "counter"
+)
+
+// states are the values of the state label, in lifecycle order
+var states = []supervisor.State{
+	supervisor.StateStarting,
+	supervisor.StateRunning,
+	supervisor.StateHealthy,
+	supervisor.StateUnhealthy,
+	supervisor.StateStopping,
+	supervisor.StateStopped,
+	supervisor.StateExited,
+	supervisor.StateFatal,
+}
+
+// Source provides the measurements, implemented by *supervisor.Supervisor.
+type Source interface {
+	// Metrics returns the measurements of every service in start order
+	Metrics() []supervisor.ServiceMetrics
+}
+
+// Label is a name and value telling the samples of a family apart.
+type Label struct {
+	// Name is the label name
+	Name string
+	// Value is the label value
+	Value string
+}
+
+// Sample is one value of a family.
+type Sample struct {
+	// Labels identify the series, service first
+	Labels []Label
+	// Value is the measurement
+	Value float64
+}
+
+// Family is a named metric with its samples.
+type Family struct {
+	// Name is the metric name
+	Name string
+	// Help describes the metric
+	Help string
+	// Type is gauge or counter
+	Type Type
+	// Unit is the UCUM unit used over OTLP
+	Unit string
+	// Samples are the values, one per series
+	Samples []Sample
+}
+
+// Collect turns the measurements of the services into metric families.
+//
+// Families are always returned, in a fixed order, even when they have no
+// sample, so every metric is described to the scrapers.
+//
+// Parameters:
+//   - services: []supervisor.ServiceMetrics measurements from Source
+//   - now: time.Time reference of the uptimes
+//
+// Returns:
+//   - families: []Family metrics of every service
+func Collect(services []supervisor.ServiceMetrics, now time.Time) []Family {
+	up := Family{Name: "superviz_service_up", Help: "Whether the service process is running.", Type: TypeGauge, Unit: "1"}
+	healthy := Family{Name: "superviz_service_healthy", Help: "Whether the service passes its probes.", Type: TypeGauge, Unit: "1"}
+	state := Family{Name: "superviz_service_state", Help: "Current lifecycle state of the service.", Type: TypeGauge, Unit: "1"}
+	restarts := Family{Name: "superviz_service_restarts_total", Help: "Starts of the service after the first one.", Type: TypeCounter, Unit: "1"}
+	exits := Family{Name: "superviz_service_exits_total", Help: "Exits of the service by exit code, -1 for a signal.", Type: TypeCounter, Unit: "1"}
+	lastExit := Family{Name: "superviz_service_last_exit_code", Help: "Exit code of the last exit of the service.", Type: TypeGauge, Unit: "1"}
+	uptime := Family{Name: "superviz_service_uptime_seconds", Help: "Time since the current process started.", Type: TypeGauge, Unit: "s"}
+	probeDuration := Family{Name: "superviz_probe_duration_seconds", Help: "Duration of the last check of the probe.", Type: TypeGauge, Unit: "s"}
+	probeChecks := Family{Name: "superviz_probe_checks_total", Help: "Checks run by the probe.", Type: TypeCounter, Unit: "1"}
+	probeFailures := Family{Name: "superviz_probe_failures_total", Help: "Failed checks of the probe.", Type: TypeCounter, Unit: "1"}
+	cpu := Family{Name: "superviz_process_cpu_seconds_total", Help: "User and system CPU time of the service process.", Type: TypeCounter, Unit: "s"}
+	rss := Family{Name: "superviz_process_resident_memory_bytes", Help: "Resident memory of the service process.", Type: TypeGauge, Unit: "By"}
+	fds := Family{Name: "superviz_process_open_fds", Help: "Open file descriptors of the service process.", Type: TypeGauge, Unit: "1"}
+
+	for _, service := range services {
+		name := []Label{{Name: "service", Value: service.Name}}
+		with := func(label, value string) []Label {
+			return []Label{name[0], {Name: label, Value: value}}
+		}
+
+		running := service.State == supervisor.StateRunning || service.State == supervisor.StateHealthy || service.State == supervisor.StateUnhealthy
+		up.Samples = append(up.Samples, Sample{Labels: name, Value: boolValue(running)})
+		healthy.Samples = append(healthy.Samples, Sample{Labels: name, Value: boolValue(service.State == supervisor.StateHealthy)})
+		for _, s := range states {
+			state.Samples = append(state.Samples, Sample{Labels: with("state", string(s)), Value: boolValue(service.State == s)})
+		}
+		restarts.Samples = append(restarts.Samples, Sample{Labels: name, Value: float64(service.Restarts)})
+
+		codes := make([]int, 0, len(service.Exits))
+		for code := range service.Exits {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			exits.Samples = append(exits.Samples, Sample{Labels: with("code", strconv.Itoa(code)), Value: float64(service.Exits[code])})
+		}
+		if service.ExitCode != nil {
+			lastExit.Samples = append(lastExit.Samples, Sample{Labels: name, Value: float64(*service.ExitCode)})
+		}
+
+		var seconds float64
+		if !service.StartedAt.IsZero() {
+			seconds = now.Sub(service.StartedAt).Seconds()
+		}
+		uptime.Samples = append(uptime.Samples, Sample{Labels: name, Value: seconds})
+
+		kinds := make([]string, 0, len(service.Probes))
+		for kind := range service.Probes {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		for _, kind := range kinds {
+			probe := service.Probes[kind]
+			labels := with("probe", kind)
+			probeDuration.Samples = append(probeDuration.Samples, Sample{Labels: labels, Value: probe.Duration.Seconds()})
+			probeChecks.Samples = append(probeChecks.Samples, Sample{Labels: labels, Value: float64(probe.Checks)})
+			probeFailures.Samples = append(probeFailures.Samples, Sample{Labels: labels, Value: float64(probe.Failures)})
+		}
+
+		if process := service.Process; process != nil {
+			cpu.Samples = append(cpu.Samples, Sample{Labels: name, Value: process.CPU.Seconds()})
+			rss.Samples = append(rss.Samples, Sample{Labels: name, Value: float64(process.RSS)})
+			if process.OpenFDs >= 0 {
+				fds.Samples = append(fds.Samples, Sample{Labels: name, Value: float64(process.OpenFDs)})
+			}
+		}
+	}
+
+	return []Family{up, healthy, state, restarts, exits, lastExit, uptime, probeDuration, probeChecks, probeFailures, cpu, rss, fds}
+}
+
+// boolValue returns 1 for true and 0 for false
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
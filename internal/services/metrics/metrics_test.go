@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/services/supervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource returns fixed measurements
+type fakeSource []supervisor.ServiceMetrics
+
+func (f fakeSource) Metrics() []supervisor.ServiceMetrics {
+	return f
+}
+
+// testNow is the reference time of the fixtures
+var testNow = time.Date(2026, 1, 1, 0, 1, 0, 0, time.UTC)
+
+// testServices returns a healthy web with probes and process stats and an exited job
+func testServices() fakeSource {
+	code := 2
+	return fakeSource{
+		{
+			ServiceStatus: supervisor.ServiceStatus{Name: "web", State: supervisor.StateHealthy, PID: 42, Restarts: 1},
+			StartedAt:     testNow.Add(-30 * time.Second),
+			Exits:         map[int]uint64{1: 1},
+			Probes: map[string]supervisor.ProbeMetrics{
+				supervisor.ProbeReadiness: {Duration: 250 * time.Millisecond, Checks: 10, Failures: 2},
+			},
+			Process: &supervisor.ProcessStats{CPU: 1500 * time.Millisecond, RSS: 4096, OpenFDs: 7},
+		},
+		{
+			ServiceStatus: supervisor.ServiceStatus{Name: "job", State: supervisor.StateExited, ExitCode: &code},
+			Exits:         map[int]uint64{2: 1, -1: 3},
+		},
+	}
+}
+
+// family returns the family named name
+func family(t *testing.T, families []Family, name string) Family {
+	t.Helper()
+	for _, f := range families {
+		if f.Name == name {
+			return f
+		}
+	}
+	require.Failf(t, "missing family", "%s", name)
+	return Family{}
+}
+
+func TestCollect(t *testing.T) {
+	families := Collect(testServices(), testNow)
+	web := []Label{{Name: "service", Value: "web"}}
+	job := []Label{{Name: "service", Value: "job"}}
+
+	assert.Equal(t, []Sample{{Labels: web, Value: 1}, {Labels: job, Value: 0}}, family(t, families, "superviz_service_up").Samples)
+	assert.Equal(t, []Sample{{Labels: web, Value: 1}, {Labels: job, Value: 0}}, family(t, families, "superviz_service_healthy").Samples)
+	assert.Equal(t, []Sample{{Labels: web, Value: 1}, {Labels: job, Value: 0}}, family(t, families, "superviz_service_restarts_total").Samples)
+	assert.Equal(t, []Sample{{Labels: job, Value: 2}}, family(t, families, "superviz_service_last_exit_code").Samples)
+	assert.Equal(t, []Sample{{Labels: web, Value: 30}, {Labels: job, Value: 0}}, family(t, families, "superviz_service_uptime_seconds").Samples)
+
+	assert.Equal(t, []Sample{
+		{Labels: []Label{{"service", "web"}, {"code", "1"}}, Value: 1},
+		{Labels: []Label{{"service", "job"}, {"code", "-1"}}, Value: 3},
+		{Labels: []Label{{"service", "job"}, {"code", "2"}}, Value: 1},
+	}, family(t, families, "superviz_service_exits_total").Samples)
+
+	state := family(t, families, "superviz_service_state")
+	assert.Len(t, state.Samples, 2*len(states))
+	assert.Contains(t, state.Samples, Sample{Labels: []Label{{"service", "web"}, {"state", "healthy"}}, Value: 1})
+	assert.Contains(t, state.Samples, Sample{Labels: []Label{{"service", "web"}, {"state", "running"}}, Value: 0})
+
+	readiness := []Label{{"service", "web"}, {"probe", "readiness"}}
+	assert.Equal(t, []Sample{{Labels: readiness, Value: 0.25}}, family(t, families, "superviz_probe_duration_seconds").Samples)
+	assert.Equal(t, []Sample{{Labels: readiness, Value: 10}}, family(t, families, "superviz_probe_checks_total").Samples)
+	assert.Equal(t, []Sample{{Labels: readiness, Value: 2}}, family(t, families, "superviz_probe_failures_total").Samples)
+
+	assert.Equal(t, []Sample{{Labels: web, Value: 1.5}}, family(t, families, "superviz_process_cpu_seconds_total").Samples)
+	assert.Equal(t, []Sample{{Labels: web, Value: 4096}}, family(t, families, "superviz_process_resident_memory_bytes").Samples)
+	assert.Equal(t, []Sample{{Labels: web, Value: 7}}, family(t, families, "superviz_process_open_fds").Samples)
+}
+
+func TestCollect_NoServices(t *testing.T) {
+	families := Collect(nil, testNow)
+	assert.Len(t, families, 13, "every family is described")
+	for _, f := range families {
+		assert.Empty(t, f.Samples, f.Name)
+		assert.NotEmpty(t, f.Help, f.Name)
+	}
+}
@@ -0,0 +1,175 @@
+// internal/services/metrics/otlp.go - Periodic OTLP export of the metrics
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+// otlpScope names the instrumentation of the exported metrics
+const otlpScope = "superviz"
+
+// ExporterOptions configures an Exporter.
+//
+// All fields are optional.
+type ExporterOptions struct {
+	// Host is the host.name resource attribute, omitted when empty
+	Host string
+	// Start is when the counters started counting, default the creation of the exporter
+	Start time.Time
+	// Report receives the export failures, once per streak of failures
+	Report func(error)
+}
+
+// Exporter pushes the metrics of a source to an OpenTelemetry collector.
+type Exporter struct {
+	// client posts the exports
+	client *otlp.Client
+	// source provides the measurements
+	source Source
+	// host is the host.name resource attribute
+	host string
+	// start is the start time of the cumulative sums
+	start time.Time
+	// timeout bounds one export
+	timeout time.Duration
+	// report receives the failures
+	report func(error)
+}
+
+// NewExporter creates an exporter for a collector.
+//
+// Parameters:
+//   - config: *providers.OTLPConfig collector endpoint, headers and timeout
+//   - source: Source measurements, usually the supervisor
+//   - opts: *ExporterOptions overrides, nil for defaults
+//
+// Returns:
+//   - exporter: *Exporter ready to Run
+//   - err: error if the endpoint is invalid
+func NewExporter(config *providers.OTLPConfig, source Source, opts *ExporterOptions) (*Exporter, error) {
+	if opts == nil {
+		opts = &ExporterOptions{}
+	}
+	client, err := otlp.NewClient(&otlp.ClientOptions{Endpoint: config.Endpoint, Headers: config.Headers, Timeout: config.Timeout})
+	if err != nil {
+		return nil, err
+	}
+	e := &Exporter{
+		client:  client,
+		source:  source,
+		host:    opts.Host,
+		start:   opts.Start,
+		timeout: config.Timeout,
+		report:  opts.Report,
+	}
+	if e.start.IsZero() {
+		e.start = time.Now()
+	}
+	if e.timeout <= 0 {
+		e.timeout = providers.DefaultOTLPTimeout
+	}
+	if e.report == nil {
+		e.report = func(error) {}
+	}
+	return e, nil
+}
+
+// Export pushes the current metrics once.
+//
+// Parameters:
+//   - ctx: context.Context bounding the request
+//
+// Returns:
+//   - err: error if the collector could not be reached or refused the metrics
+func (e *Exporter) Export(ctx context.Context) error {
+	now := time.Now()
+	return e.client.Export(ctx, otlp.SignalMetrics, MetricsData(Collect(e.source.Metrics(), now), e.host, e.start, now))
+}
+
+// Run pushes the metrics every interval until ctx is done, then one last
+// time so the final state of the services reaches the collector.
+//
+// Parameters:
+//   - ctx: context.Context stopping the exports
+//   - interval: time.Duration between two exports
+func (e *Exporter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	failing := false
+	export := func(parent context.Context) {
+		exportCtx, cancel := context.WithTimeout(parent, e.timeout)
+		defer cancel()
+		err := e.Export(exportCtx)
+		if err != nil && !failing {
+			e.report(err)
+		}
+		failing = err != nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			export(context.Background())
+			return
+		case <-ticker.C:
+			export(ctx)
+		}
+	}
+}
+
+// MetricsData converts families to an export request.
+//
+// Gauges keep their last value; counters become monotonic cumulative sums
+// counted from start. The service and the other labels become attributes of
+// each data point.
+//
+// Parameters:
+//   - families: []Family metrics from Collect
+//   - host: string host.name resource attribute, omitted when empty
+//   - start: time.Time start of the cumulative sums
+//   - now: time.Time observation time of the data points
+//
+// Returns:
+//   - data: otlp.MetricsData export request with one resource
+func MetricsData(families []Family, host string, start, now time.Time) otlp.MetricsData {
+	attributes := []otlp.KeyValue{otlp.Attribute("service.name", "superviz")}
+	if host != "" {
+		attributes = append(attributes, otlp.Attribute("host.name", host))
+	}
+
+	metrics := make([]otlp.Metric, 0, len(families))
+	for _, family := range families {
+		if len(family.Samples) == 0 {
+			continue
+		}
+		points := make([]otlp.NumberDataPoint, 0, len(family.Samples))
+		for _, sample := range family.Samples {
+			point := otlp.NumberDataPoint{TimeUnixNano: otlp.UnixNano(now), AsDouble: sample.Value}
+			for _, label := range sample.Labels {
+				point.Attributes = append(point.Attributes, otlp.Attribute(label.Name, label.Value))
+			}
+			if family.Type == TypeCounter {
+				point.StartTimeUnixNano = otlp.UnixNano(start)
+			}
+			points = append(points, point)
+		}
+
+		metric := otlp.Metric{Name: family.Name, Description: family.Help, Unit: family.Unit}
+		if family.Type == TypeCounter {
+			metric.Sum = &otlp.Sum{DataPoints: points, AggregationTemporality: otlp.TemporalityCumulative, IsMonotonic: true}
+		} else {
+			metric.Gauge = &otlp.Gauge{DataPoints: points}
+		}
+		metrics = append(metrics, metric)
+	}
+
+	return otlp.MetricsData{ResourceMetrics: []otlp.ResourceMetrics{{
+		Resource:     otlp.Resource{Attributes: attributes},
+		ScopeMetrics: []otlp.ScopeMetrics{{Scope: otlp.Scope{Name: otlpScope}, Metrics: metrics}},
+	}}}
+}
@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// otlpReceiver is an in-process stand-in for an OTLP/HTTP collector
+type otlpReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []otlp.MetricsData
+	status   int
+}
+
+// newOTLPReceiver starts a receiver decoding the metrics export requests
+func newOTLPReceiver(t *testing.T) *otlpReceiver {
+	t.Helper()
+	r := &otlpReceiver{status: http.StatusOK}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v1/metrics" || req.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(req.Body)
+		var data otlp.MetricsData
+		if err := json.Unmarshal(body, &data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, data)
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+// received returns the decoded requests
+func (r *otlpReceiver) received() []otlp.MetricsData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]otlp.MetricsData(nil), r.requests...)
+}
+
+// fail makes the receiver answer with status
+func (r *otlpReceiver) fail(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+// metric returns the metric named name of a request
+func metric(t *testing.T, data otlp.MetricsData, name string) otlp.Metric {
+	t.Helper()
+	require.Len(t, data.ResourceMetrics, 1)
+	require.Len(t, data.ResourceMetrics[0].ScopeMetrics, 1)
+	for _, m := range data.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		if m.Name == name {
+			return m
+		}
+	}
+	require.Failf(t, "missing metric", "%s", name)
+	return otlp.Metric{}
+}
+
+func TestMetricsData(t *testing.T) {
+	start := testNow.Add(-time.Hour)
+	data := MetricsData(Collect(testServices(), testNow), "box", start, testNow)
+
+	assert.Equal(t, []otlp.KeyValue{otlp.Attribute("service.name", "superviz"), otlp.Attribute("host.name", "box")},
+		data.ResourceMetrics[0].Resource.Attributes)
+	assert.Equal(t, otlpScope, data.ResourceMetrics[0].ScopeMetrics[0].Scope.Name)
+
+	up := metric(t, data, "superviz_service_up")
+	require.NotNil(t, up.Gauge)
+	assert.Nil(t, up.Sum)
+	assert.Equal(t, "1", up.Unit)
+	assert.Equal(t, otlp.NumberDataPoint{
+		Attributes:   []otlp.KeyValue{otlp.Attribute("service", "web")},
+		TimeUnixNano: otlp.UnixNano(testNow),
+		AsDouble:     1,
+	}, up.Gauge.DataPoints[0])
+
+	checks := metric(t, data, "superviz_probe_checks_total")
+	require.NotNil(t, checks.Sum)
+	assert.True(t, checks.Sum.IsMonotonic)
+	assert.Equal(t, otlp.TemporalityCumulative, checks.Sum.AggregationTemporality)
+	assert.Equal(t, otlp.NumberDataPoint{
+		Attributes:        []otlp.KeyValue{otlp.Attribute("service", "web"), otlp.Attribute("probe", "readiness")},
+		StartTimeUnixNano: otlp.UnixNano(start),
+		TimeUnixNano:      otlp.UnixNano(testNow),
+		AsDouble:          10,
+	}, checks.Sum.DataPoints[0])
+
+	empty := MetricsData(Collect(nil, testNow), "", start, testNow)
+	assert.Empty(t, empty.ResourceMetrics[0].ScopeMetrics[0].Metrics, "families without samples are left out")
+	assert.Len(t, empty.ResourceMetrics[0].Resource.Attributes, 1)
+}
+
+func TestExporter_Run(t *testing.T) {
+	receiver := newOTLPReceiver(t)
+	exporter, err := NewExporter(&providers.OTLPConfig{Endpoint: receiver.URL}, testServices(), &ExporterOptions{Host: "box"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		exporter.Run(ctx, 20*time.Millisecond)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return len(receiver.received()) >= 2 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+	before := len(receiver.received())
+	assert.GreaterOrEqual(t, before, 3, "a last export is pushed on stop")
+
+	data := receiver.received()[0]
+	assert.Equal(t, float64(1), metric(t, data, "superviz_service_restarts_total").Sum.DataPoints[0].AsDouble)
+	assert.Equal(t, float64(4096), metric(t, data, "superviz_process_resident_memory_bytes").Gauge.DataPoints[0].AsDouble)
+}
+
+func TestExporter_ReportsFailuresOncePerStreak(t *testing.T) {
+	receiver := newOTLPReceiver(t)
+	receiver.fail(http.StatusServiceUnavailable)
+
+	var mu sync.Mutex
+	var reports []error
+	exporter, err := NewExporter(&providers.OTLPConfig{Endpoint: receiver.URL}, testServices(), &ExporterOptions{
+		Report: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reports = append(reports, err)
+		},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		exporter.Run(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	require.Eventually(t, func() bool { return len(receiver.received()) >= 3 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, reports, 1)
+	assert.Contains(t, reports[0].Error(), "503")
+}
+
+func TestNewExporter_InvalidEndpoint(t *testing.T) {
+	_, err := NewExporter(&providers.OTLPConfig{Endpoint: "collector"}, testServices(), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,138 @@
+// internal/services/metrics/prometheus.go - Prometheus text exposition of the metrics
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ContentType is the media type of the Prometheus text format
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+	// readHeaderTimeout bounds how long a scraper may take to send its headers
+	readHeaderTimeout = 10 * time.Second
+	// shutdownTimeout bounds how long a pending scrape may take once the server stops
+	shutdownTimeout = 5 * time.Second
+)
+
+// helpEscaper escapes the HELP text
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// labelEscaper escapes the label values
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// WritePrometheus writes families in the Prometheus text format.
+//
+// Parameters:
+//   - w: io.Writer destination
+//   - families: []Family metrics from Collect
+//
+// Returns:
+//   - err: error if w failed
+func WritePrometheus(w io.Writer, families []Family) error {
+	var b strings.Builder
+	for _, family := range families {
+		b.WriteString("# HELP " + family.Name + " " + helpEscaper.Replace(family.Help) + "\n")
+		b.WriteString("# TYPE " + family.Name + " " + string(family.Type) + "\n")
+		for _, sample := range family.Samples {
+			b.WriteString(family.Name)
+			if len(sample.Labels) > 0 {
+				b.WriteString("{")
+				for i, label := range sample.Labels {
+					if i > 0 {
+						b.WriteString(",")
+					}
+					b.WriteString(label.Name + `="` + labelEscaper.Replace(label.Value) + `"`)
+				}
+				b.WriteString("}")
+			}
+			b.WriteString(" " + formatValue(sample.Value) + "\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatValue writes a value the way Prometheus parses it
+func formatValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// Handler returns the HTTP handler answering scrapes with the metrics of source.
+//
+// Parameters:
+//   - source: Source measurements, usually the supervisor
+//
+// Returns:
+//   - handler: http.Handler answering GET and HEAD
+func Handler(source Source) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		if r.Method == http.MethodHead {
+			return
+		}
+		_ = WritePrometheus(w, Collect(source.Metrics(), time.Now())) //nolint:errcheck // the scraper went away
+	})
+}
+
+// Serve answers scrapes on path until ctx is done.
+//
+// Parameters:
+//   - ctx: context.Context stopping the server
+//   - listener: net.Listener accepting the scrapers
+//   - path: string HTTP path of the metrics, other paths answer 404
+//   - source: Source measurements
+//
+// Returns:
+//   - err: error if the listener failed before ctx was done
+func Serve(ctx context.Context, listener net.Listener, path string, source Source) error {
+	mux := http.NewServeMux()
+	mux.Handle(path, Handler(source))
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	failed := make(chan error, 1)
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			failed <- err
+		}
+		close(failed)
+	}()
+
+	var err error
+	select {
+	case <-ctx.Done():
+	case err = <-failed:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if srv.Shutdown(shutdownCtx) != nil {
+		_ = srv.Close() //nolint:errcheck // the server is going away
+	}
+	<-failed
+	return err
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	families := []Family{
+		{Name: "a_total", Help: "Counts\nthings.", Type: TypeCounter, Samples: []Sample{
+			{Labels: []Label{{"service", `we"b\`}}, Value: 3},
+		}},
+		{Name: "b", Help: "Empty.", Type: TypeGauge},
+		{Name: "c", Help: "Values.", Type: TypeGauge, Samples: []Sample{
+			{Value: 0.5}, {Value: math.Inf(1)}, {Value: math.NaN()},
+		}},
+	}
+
+	var b strings.Builder
+	require.NoError(t, WritePrometheus(&b, families))
+	assert.Equal(t, `# HELP a_total Counts\nthings.
+# TYPE a_total counter
+a_total{service="we\"b\\"} 3
+# HELP b Empty.
+# TYPE b gauge
+# HELP c Values.
+# TYPE c gauge
+c 0.5
+c +Inf
+c NaN
+`, b.String())
+}
+
+func TestHandler(t *testing.T) {
+	handler := Handler(testServices())
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `superviz_service_up{service="web"} 1`+"\n")
+	assert.Contains(t, rec.Body.String(), `superviz_service_exits_total{service="job",code="-1"} 3`+"\n")
+	assert.Contains(t, rec.Body.String(), `superviz_process_resident_memory_bytes{service="web"} 4096`+"\n")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestServe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Serve(ctx, listener, "/prom", testServices()) }()
+
+	base := "http://" + listener.Addr().String()
+	resp, err := http.Get(base + "/prom")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), "# TYPE superviz_service_up gauge")
+
+	resp, err = http.Get(base + "/other")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return")
+	}
+}
@@ -0,0 +1,499 @@
+// internal/services/preflight.go - Remote host preflight checks before installation
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/transports/ssh"
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/utils"
+)
+
+// PreflightStatus is the outcome of a single preflight check.
+type PreflightStatus string
+
+// Preflight statuses ordered by severity.
+const (
+	// PreflightPass indicates the check succeeded
+	PreflightPass PreflightStatus = "pass"
+	// PreflightWarn indicates a non-blocking problem
+	PreflightWarn PreflightStatus = "warn"
+	// PreflightFail indicates a problem that would break the installation
+	PreflightFail PreflightStatus = "fail"
+)
+
+// severity returns the ordering weight of the status.
+//
+// Returns:
+//   - weight: int higher values are more severe
+func (s PreflightStatus) severity() int {
+	switch s {
+	case PreflightFail:
+		return 2
+	case PreflightWarn:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Thresholds applied by the default preflight checks.
+const (
+	// minFreeDiskKB is the minimum free space required under /var
+	minFreeDiskKB = 100 * 1024
+	// clockSkewWarn is the skew above which GPG signatures may be rejected
+	clockSkewWarn = 30 * time.Second
+	// clockSkewFail is the skew above which TLS handshakes start failing
+	clockSkewFail = 5 * time.Minute
+	// reachabilityTimeout bounds the HTTPS reachability probe in seconds
+	reachabilityTimeout = 10
+)
+
+// PreflightResult is the outcome of one preflight check.
+type PreflightResult struct {
+	// Check is the stable identifier of the check
+	Check string `json:"check" yaml:"check"`
+	// Status is the check outcome
+	Status PreflightStatus `json:"status" yaml:"status"`
+	// Message describes the outcome for humans
+	Message string `json:"message" yaml:"message"`
+}
+
+// PreflightReport aggregates the results of all preflight checks on a host.
+type PreflightReport struct {
+	// Target is the user@host the checks ran against
+	Target string `json:"target" yaml:"target"`
+	// Results holds one entry per check, in execution order
+	Results []PreflightResult `json:"results" yaml:"results"`
+}
+
+// Status returns the most severe status across all results.
+//
+// Returns:
+//   - status: PreflightStatus worst outcome, PreflightPass when empty
+func (r *PreflightReport) Status() PreflightStatus {
+	status := PreflightPass
+	for _, res := range r.Results {
+		if res.Status.severity() > status.severity() {
+			status = res.Status
+		}
+	}
+	return status
+}
+
+// Format returns a human-readable table of the report.
+//
+//	Preflight checks on admin@web-1
+//	  [pass] disk-space        at least 100 MiB free under /var
+//	  [warn] clock-skew        clock differs from local time by more than 30s
+//	Result: warn
+//
+// Returns:
+//   - formatted: string multi-line report ending with a newline
+func (r *PreflightReport) Format() string {
+	var b strings.Builder
+	b.WriteString("Preflight checks on ")
+	b.WriteString(r.Target)
+	b.WriteByte('\n')
+	for _, res := range r.Results {
+		fmt.Fprintf(&b, "  [%s] %-17s %s\n", res.Status, res.Check, res.Message)
+	}
+	b.WriteString("Result: ")
+	b.WriteString(string(r.Status()))
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// preflightProbe is a shell predicate evaluated on the remote host.
+type preflightProbe struct {
+	// command exits zero when the condition holds
+	command string
+	// status is reported when command fails
+	status PreflightStatus
+	// message is reported when command fails
+	message string
+}
+
+// preflightCheck groups ordered probes under one identifier.
+//
+// Probes are evaluated in order and the first failing one determines the
+// result; when all succeed the check passes with the pass message.
+type preflightCheck struct {
+	// id is the stable identifier of the check
+	id string
+	// probes are evaluated in order
+	probes []preflightProbe
+	// pass is reported when all probes succeed
+	pass string
+}
+
+// initSystems lists init system detection predicates in preference order.
+var initSystems = []struct {
+	name    string
+	command string
+}{
+	{"systemd", "test -d /run/systemd/system"},
+	{"openrc", "command -v openrc >/dev/null 2>&1"},
+	{"runit", "test -d /etc/runit || command -v runsvdir >/dev/null 2>&1"},
+	{"sysvinit", "test -x /sbin/init -a -d /etc/init.d"},
+}
+
+// pkgLockProbe fails when a package manager process is currently running.
+const pkgLockProbe = "! pgrep -x 'apt|apt-get|aptitude|dpkg|unattended-upgr|yum|dnf|zypper|pacman|apk|emerge' >/dev/null 2>&1"
+
+// PreflightChecker runs preflight checks over an established connection.
+type PreflightChecker interface {
+	// Check runs all preflight checks on the connected host.
+	//
+	// Parameters:
+	//   - ctx: context.Context for timeout and cancellation
+	//   - target: string user@host label recorded in the report
+	//
+	// Returns:
+	//   - report: *PreflightReport results of all checks
+	Check(ctx context.Context, target string) *PreflightReport
+}
+
+// preflightChecker implements PreflightChecker with shell predicates.
+type preflightChecker struct {
+	// client executes predicates on the remote host
+	client ssh.Client
+	// provider supplies the repository URL to probe
+	provider providers.InstallProvider
+	// now returns the local reference time for clock skew detection
+	now func() time.Time
+}
+
+// NewPreflightChecker creates a checker running predicates through client.
+//
+// Parameters:
+//   - client: ssh.Client connected SSH client
+//   - provider: providers.InstallProvider repository information source
+//
+// Returns:
+//   - checker: PreflightChecker ready for use
+func NewPreflightChecker(client ssh.Client, provider providers.InstallProvider) PreflightChecker {
+	return &preflightChecker{
+		client:   client,
+		provider: provider,
+		now:      time.Now,
+	}
+}
+
+// WithMirror returns a copy of the checker probing a repository mirror.
+//
+// Parameters:
+//   - baseURL: string mirror repository root
+//
+// Returns:
+//   - checker: PreflightChecker probing the mirror host
+func (c *preflightChecker) WithMirror(baseURL string) PreflightChecker {
+	mirrored := *c
+	mirrored.provider = providers.NewMirrorInstallProvider(c.provider, baseURL)
+	return &mirrored
+}
+
+// checkerForMirror returns checker targeting baseURL when it supports mirrors,
+// or checker unchanged otherwise.
+//
+// Parameters:
+//   - checker: PreflightChecker checker to adapt
+//   - baseURL: string mirror repository root
+//
+// Returns:
+//   - checker: PreflightChecker probing the mirror when supported
+func checkerForMirror(checker PreflightChecker, baseURL string) PreflightChecker {
+	if m, ok := checker.(interface {
+		WithMirror(baseURL string) PreflightChecker
+	}); ok {
+		return m.WithMirror(baseURL)
+	}
+	return checker
+}
+
+// checks builds the list of checks for the current time and repository.
+//
+// Returns:
+//   - checks: []preflightCheck ordered checks
+func (c *preflightChecker) checks() []preflightCheck {
+	repoURL := c.provider.GetRepositoryURL()
+	host := repoURL
+	if parsed, err := url.Parse(repoURL); err == nil && parsed.Hostname() != "" {
+		host = parsed.Hostname()
+	}
+
+	epoch := strconv.FormatInt(c.now().Unix(), 10)
+	skew := func(limit time.Duration) string {
+		return fmt.Sprintf(`d=$(( $(date -u +%%s) - %s )); [ "${d#-}" -le %d ]`, epoch, int64(limit.Seconds()))
+	}
+
+	return []preflightCheck{
+		{
+			id: "disk-space",
+			probes: []preflightProbe{{
+				command: fmt.Sprintf(`[ "$(df -Pk /var | awk 'NR==2 {print $4}')" -ge %d ]`, minFreeDiskKB),
+				status:  PreflightFail,
+				message: fmt.Sprintf("less than %d MiB free under /var", minFreeDiskKB/1024),
+			}},
+			pass: fmt.Sprintf("at least %d MiB free under /var", minFreeDiskKB/1024),
+		},
+		{
+			id: "downloader",
+			probes: []preflightProbe{{
+				command: "command -v curl >/dev/null 2>&1 || command -v wget >/dev/null 2>&1",
+				status:  PreflightFail,
+				message: "neither curl nor wget is installed",
+			}},
+			pass: "curl or wget available",
+		},
+		{
+			id: "gpg",
+			probes: []preflightProbe{{
+				command: "command -v gpg >/dev/null 2>&1 || command -v gpgv >/dev/null 2>&1",
+				status:  PreflightWarn,
+				message: "gpg/gpgv not found, signature checks depend on the package manager",
+			}},
+			pass: "gpg or gpgv available",
+		},
+		{
+			id: "tar",
+			probes: []preflightProbe{{
+				command: "command -v tar >/dev/null 2>&1",
+				status:  PreflightWarn,
+				message: "tar is not installed",
+			}},
+			pass: "tar available",
+		},
+		{
+			id: "clock-skew",
+			probes: []preflightProbe{
+				{
+					command: skew(clockSkewFail),
+					status:  PreflightFail,
+					message: "clock differs from local time by more than " + clockSkewFail.String() + ", TLS will fail",
+				},
+				{
+					command: skew(clockSkewWarn),
+					status:  PreflightWarn,
+					message: "clock differs from local time by more than " + clockSkewWarn.String() + ", GPG may reject signatures",
+				},
+			},
+			pass: "clock within " + clockSkewWarn.String() + " of local time",
+		},
+		{
+			id: "dns",
+			probes: []preflightProbe{{
+				command: "getent hosts " + host + " >/dev/null 2>&1 || nslookup " + host + " >/dev/null 2>&1",
+				status:  PreflightFail,
+				message: "cannot resolve " + host,
+			}},
+			pass: host + " resolves",
+		},
+		{
+			id: "https",
+			probes: []preflightProbe{{
+				command: fmt.Sprintf("curl -fsS -o /dev/null --max-time %[2]d %[1]s || wget -q --spider -T %[2]d %[1]s",
+					repoURL, reachabilityTimeout),
+				status:  PreflightFail,
+				message: repoURL + " is not reachable over HTTPS",
+			}},
+			pass: repoURL + " reachable over HTTPS",
+		},
+		{
+			id: "package-lock",
+			probes: []preflightProbe{{
+				command: pkgLockProbe,
+				status:  PreflightFail,
+				message: "another package manager process is running and holds the lock",
+			}},
+			pass: "no package manager process running",
+		},
+	}
+}
+
+// Check runs all preflight checks on the connected host.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - target: string user@host label recorded in the report
+//
+// Returns:
+//   - report: *PreflightReport results of all checks
+func (c *preflightChecker) Check(ctx context.Context, target string) *PreflightReport {
+	checks := c.checks()
+	report := &PreflightReport{
+		Target:  target,
+		Results: make([]PreflightResult, 0, len(checks)+1),
+	}
+
+	for _, check := range checks {
+		result := PreflightResult{Check: check.id, Status: PreflightPass, Message: check.pass}
+		for _, probe := range check.probes {
+			if err := c.client.Execute(ctx, probe.command); err != nil {
+				result.Status = probe.status
+				result.Message = probe.message
+				break
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	report.Results = append(report.Results, c.checkInitSystem(ctx))
+	return report
+}
+
+// checkInitSystem identifies the init system of the remote host.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//
+// Returns:
+//   - result: PreflightResult naming the init system or warning when unknown
+func (c *preflightChecker) checkInitSystem(ctx context.Context) PreflightResult {
+	for _, candidate := range initSystems {
+		if err := c.client.Execute(ctx, candidate.command); err == nil {
+			return PreflightResult{Check: "init-system", Status: PreflightPass, Message: candidate.name + " detected"}
+		}
+	}
+	return PreflightResult{Check: "init-system", Status: PreflightWarn, Message: "no supported init system detected"}
+}
+
+// PreflightService runs preflight checks against remote hosts.
+type PreflightService struct {
+	// client provides SSH connectivity to the target
+	client ssh.Client
+	// checker evaluates checks over the established connection
+	checker PreflightChecker
+}
+
+// PreflightServiceOptions contains options for creating a PreflightService.
+type PreflightServiceOptions struct {
+	// Provider supplies repository information
+	Provider providers.InstallProvider
+	// SSHClient connects to the target host
+	SSHClient ssh.Client
+	// Checker overrides the default checker
+	Checker PreflightChecker
+}
+
+// NewPreflightService creates a new preflight service with the given options.
+//
+// Parameters:
+//   - opts: *PreflightServiceOptions dependencies, nil for defaults
+//
+// Returns:
+//   - service: *PreflightService ready for use
+func NewPreflightService(opts *PreflightServiceOptions) *PreflightService {
+	if opts == nil {
+		opts = &PreflightServiceOptions{}
+	}
+
+	s := &PreflightService{client: opts.SSHClient, checker: opts.Checker}
+	if s.client == nil {
+		s.client = ssh.NewClient(nil)
+	}
+
+	if s.checker == nil {
+		provider := opts.Provider
+		if provider == nil {
+			provider = providers.DefaultInstallProvider()
+		}
+		s.checker = NewPreflightChecker(s.client, provider)
+	}
+
+	return s
+}
+
+// ValidateAndPrepareConfig validates and prepares the connection configuration.
+//
+// Parameters:
+//   - config: *providers.InstallConfig connection configuration to fill
+//   - args: []string command-line arguments holding user@host
+//
+// Returns:
+//   - err: error if the target is missing or malformed
+func (s *PreflightService) ValidateAndPrepareConfig(config *providers.InstallConfig, args []string) error {
+	if err := parseTarget(config, args); err != nil {
+		return err
+	}
+	return validateRepoMirror(config)
+}
+
+// Run connects to the target, runs all checks and returns the report.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - config: *providers.InstallConfig validated connection configuration
+//
+// Returns:
+//   - report: *PreflightReport results of all checks
+//   - err: error if the connection fails
+func (s *PreflightService) Run(ctx context.Context, config *providers.InstallConfig) (*PreflightReport, error) {
+	if config == nil {
+		return nil, ErrNilConfig
+	}
+
+	if err := s.client.Connect(ctx, newSSHConfig(config)); err != nil {
+		return nil, wrapConnectionError(err, config.Target)
+	}
+	defer s.client.Close() //nolint:errcheck // best effort, the report is already complete
+
+	checker := s.checker
+	if config.RepoMirror != "" {
+		checker = checkerForMirror(checker, config.RepoMirror)
+	}
+	return checker.Check(ctx, config.Target), nil
+}
+
+// Preflight runs all checks and writes the formatted report to w.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - w: io.Writer receiving the report
+//   - config: *providers.InstallConfig validated connection configuration
+//
+// Returns:
+//   - err: error if the connection fails, writing fails or a check failed
+func (s *PreflightService) Preflight(ctx context.Context, w io.Writer, config *providers.InstallConfig) error {
+	return s.PreflightAs(ctx, w, config, utils.OutputText)
+}
+
+// PreflightAs runs all checks and writes the report in the requested format.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - w: io.Writer receiving the report
+//   - config: *providers.InstallConfig validated connection configuration
+//   - format: utils.OutputFormat text table, or the report encoded as JSON or YAML
+//
+// Returns:
+//   - err: error if the connection fails, writing fails or a check failed
+func (s *PreflightService) PreflightAs(ctx context.Context, w io.Writer, config *providers.InstallConfig, format utils.OutputFormat) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+
+	report, err := s.Run(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	if format == utils.OutputText {
+		if _, err := io.WriteString(w, report.Format()); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	} else if err := utils.EncodeOutput(w, format, report); err != nil {
+		return err
+	}
+
+	if report.Status() == PreflightFail {
+		return fmt.Errorf("%w on %s", ErrPreflightFailed, config.Target)
+	}
+	return nil
+}
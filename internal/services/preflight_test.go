@@ -0,0 +1,231 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/utils"
+)
+
+// newTestChecker returns a checker with a fixed clock and repository URL.
+func newTestChecker(client *mockSSHClient) *preflightChecker {
+	provider := &mockInstallProvider{}
+	provider.On("GetRepositoryURL").Return("https://repo.example.com")
+	return &preflightChecker{
+		client:   client,
+		provider: provider,
+		now:      func() time.Time { return time.Unix(1700000000, 0) },
+	}
+}
+
+func TestPreflightReport_Status(t *testing.T) {
+	report := &PreflightReport{}
+	assert.Equal(t, PreflightPass, report.Status())
+
+	report.Results = []PreflightResult{
+		{Check: "a", Status: PreflightPass},
+		{Check: "b", Status: PreflightWarn},
+	}
+	assert.Equal(t, PreflightWarn, report.Status())
+
+	report.Results = append(report.Results, PreflightResult{Check: "c", Status: PreflightFail})
+	assert.Equal(t, PreflightFail, report.Status())
+}
+
+func TestPreflightReport_Format(t *testing.T) {
+	report := &PreflightReport{
+		Target: "admin@web-1",
+		Results: []PreflightResult{
+			{Check: "disk-space", Status: PreflightPass, Message: "ok"},
+			{Check: "clock-skew", Status: PreflightWarn, Message: "drift"},
+		},
+	}
+
+	out := report.Format()
+
+	assert.Contains(t, out, "Preflight checks on admin@web-1\n")
+	assert.Contains(t, out, "[pass] disk-space")
+	assert.Contains(t, out, "[warn] clock-skew")
+	assert.True(t, strings.HasSuffix(out, "Result: warn\n"))
+}
+
+func TestPreflightChecker_Check_AllPass(t *testing.T) {
+	client := &mockSSHClient{}
+	client.On("Execute", mock.Anything, mock.AnythingOfType("string")).Return(nil)
+
+	report := newTestChecker(client).Check(context.Background(), "u@h")
+
+	assert.Equal(t, PreflightPass, report.Status())
+	ids := make([]string, 0, len(report.Results))
+	for _, r := range report.Results {
+		ids = append(ids, r.Check)
+	}
+	assert.Equal(t, []string{
+		"disk-space", "downloader", "gpg", "tar", "clock-skew", "dns", "https", "package-lock", "init-system",
+	}, ids)
+	assert.Equal(t, "systemd detected", report.Results[len(report.Results)-1].Message)
+}
+
+func TestPreflightChecker_Check_Commands(t *testing.T) {
+	client := &mockSSHClient{}
+	client.On("Execute", mock.Anything, mock.AnythingOfType("string")).Return(nil)
+
+	newTestChecker(client).Check(context.Background(), "u@h")
+
+	var commands []string
+	for _, call := range client.Calls {
+		commands = append(commands, call.Arguments.String(1))
+	}
+	joined := strings.Join(commands, "\n")
+
+	assert.Contains(t, joined, `d=$(( $(date -u +%s) - 1700000000 )); [ "${d#-}" -le 300 ]`)
+	assert.Contains(t, joined, `[ "${d#-}" -le 30 ]`)
+	assert.Contains(t, joined, "getent hosts repo.example.com")
+	assert.Contains(t, joined, "curl -fsS -o /dev/null --max-time 10 https://repo.example.com")
+	assert.Contains(t, joined, pkgLockProbe)
+}
+
+func TestPreflightChecker_Check_Failures(t *testing.T) {
+	client := &mockSSHClient{}
+	// Clock is off by more than the warning threshold but below the failure threshold
+	client.On("Execute", mock.Anything, mock.MatchedBy(func(cmd string) bool {
+		return strings.HasSuffix(cmd, "-le 30 ]")
+	})).Return(errors.New("exit 1"))
+	client.On("Execute", mock.Anything, pkgLockProbe).Return(errors.New("exit 1"))
+	client.On("Execute", mock.Anything, mock.MatchedBy(func(cmd string) bool {
+		return cmd == initSystems[0].command || cmd == initSystems[1].command
+	})).Return(errors.New("exit 1"))
+	client.On("Execute", mock.Anything, mock.AnythingOfType("string")).Return(nil)
+
+	report := newTestChecker(client).Check(context.Background(), "u@h")
+
+	byID := make(map[string]PreflightResult, len(report.Results))
+	for _, r := range report.Results {
+		byID[r.Check] = r
+	}
+
+	assert.Equal(t, PreflightWarn, byID["clock-skew"].Status)
+	assert.Contains(t, byID["clock-skew"].Message, "GPG")
+	assert.Equal(t, PreflightFail, byID["package-lock"].Status)
+	assert.Equal(t, PreflightPass, byID["init-system"].Status)
+	assert.Equal(t, "runit detected", byID["init-system"].Message)
+	assert.Equal(t, PreflightFail, report.Status())
+}
+
+func TestPreflightChecker_CheckInitSystem_Unknown(t *testing.T) {
+	client := &mockSSHClient{}
+	client.On("Execute", mock.Anything, mock.AnythingOfType("string")).Return(errors.New("exit 1"))
+
+	result := newTestChecker(client).checkInitSystem(context.Background())
+
+	assert.Equal(t, PreflightWarn, result.Status)
+}
+
+func TestNewPreflightService_Defaults(t *testing.T) {
+	service := NewPreflightService(nil)
+
+	require.NotNil(t, service)
+	assert.NotNil(t, service.client)
+	assert.NotNil(t, service.checker)
+}
+
+func TestPreflightService_Preflight(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  PreflightStatus
+		wantErr error
+	}{
+		{name: "pass", status: PreflightPass},
+		{name: "warn", status: PreflightWarn},
+		{name: "fail", status: PreflightFail, wantErr: ErrPreflightFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockSSHClient{}
+			client.On("Connect", mock.Anything, mock.Anything).Return(nil)
+			client.On("Close").Return(nil)
+
+			service := NewPreflightService(&PreflightServiceOptions{
+				SSHClient: client,
+				Checker:   &mockPreflightChecker{status: tt.status},
+			})
+			config := &providers.InstallConfig{}
+			require.NoError(t, service.ValidateAndPrepareConfig(config, []string{"u@h"}))
+
+			var out bytes.Buffer
+			err := service.Preflight(context.Background(), &out, config)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Contains(t, out.String(), "Result: "+string(tt.status))
+			client.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPreflightService_PreflightAs_JSON(t *testing.T) {
+	client := &mockSSHClient{}
+	client.On("Connect", mock.Anything, mock.Anything).Return(nil)
+	client.On("Close").Return(nil)
+
+	service := NewPreflightService(&PreflightServiceOptions{
+		SSHClient: client,
+		Checker:   &mockPreflightChecker{status: PreflightFail},
+	})
+	config := &providers.InstallConfig{}
+	require.NoError(t, service.ValidateAndPrepareConfig(config, []string{"u@h"}))
+
+	var out bytes.Buffer
+	err := service.PreflightAs(context.Background(), &out, config, utils.OutputJSON)
+
+	assert.ErrorIs(t, err, ErrPreflightFailed)
+	var report PreflightReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, "u@h", report.Target)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, PreflightFail, report.Results[0].Status)
+}
+
+func TestPreflightService_Preflight_ConnectionError(t *testing.T) {
+	client := &mockSSHClient{}
+	client.On("Connect", mock.Anything, mock.Anything).Return(errors.New("refused"))
+
+	service := NewPreflightService(&PreflightServiceOptions{SSHClient: client, Checker: &mockPreflightChecker{}})
+
+	err := service.Preflight(context.Background(), &bytes.Buffer{}, &providers.InstallConfig{Target: "u@h"})
+
+	assert.ErrorContains(t, err, "failed to connect to u@h")
+}
+
+func TestPreflightService_Preflight_NilArguments(t *testing.T) {
+	service := NewPreflightService(&PreflightServiceOptions{SSHClient: &mockSSHClient{}, Checker: &mockPreflightChecker{}})
+
+	assert.ErrorIs(t, service.Preflight(context.Background(), nil, &providers.InstallConfig{}), ErrNilWriter)
+	assert.ErrorIs(t, service.Preflight(context.Background(), &bytes.Buffer{}, nil), ErrNilConfig)
+}
+
+func TestCheckerForMirror(t *testing.T) {
+	checker := NewPreflightChecker(&mockSSHClient{}, providers.NewInstallProvider())
+
+	mirrored, ok := checkerForMirror(checker, "https://mirror.example.com/superviz").(*preflightChecker)
+	require.True(t, ok)
+	assert.Equal(t, "https://mirror.example.com/superviz", mirrored.provider.GetRepositoryURL())
+	assert.Equal(t, "https://repo.superviz.io", checker.(*preflightChecker).provider.GetRepositoryURL())
+
+	fixed := &mockPreflightChecker{}
+	assert.Same(t, fixed, checkerForMirror(fixed, "https://mirror.example.com"))
+}
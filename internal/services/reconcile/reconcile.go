@@ -0,0 +1,355 @@
+// Package reconcile converges the observed state of a host to the declared
+// one: each pass checks every resource, records the divergences and queues
+// the corrective actions, taken within a global rate limit and with a
+// per-resource backoff.
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+// DefaultHistory is the number of events kept by default.
+const DefaultHistory = 1000
+
+// ErrNoAction is returned by Resource.Apply when a divergence has no
+// automatic correction and must be fixed by hand.
+var ErrNoAction = errors.New("no automatic action")
+
+// Resource is one piece of declared state.
+type Resource interface {
+	// Name identifies the resource, such as service/web or artifact/vault
+	Name() string
+	// Check observes the resource and describes how it diverges, empty when it converged
+	Check(ctx context.Context) (reason string, err error)
+	// Apply corrects a divergence, ErrNoAction when it cannot
+	Apply(ctx context.Context) error
+}
+
+// EventKind classifies an event.
+type EventKind string
+
+// Event kinds.
+const (
+	// EventDrift means a resource diverged, or diverges for a new reason
+	EventDrift EventKind = "drift"
+	// EventAction means a corrective action was taken
+	EventAction EventKind = "action"
+	// EventFailed means a check or an action failed, or no action exists
+	EventFailed EventKind = "failed"
+	// EventThrottled means an action was postponed by the rate limit
+	EventThrottled EventKind = "throttled"
+	// EventConverged means a diverging resource matches its declaration again
+	EventConverged EventKind = "converged"
+)
+
+// Event records a divergence or an action on a resource.
+type Event struct {
+	// Time is when the event happened
+	Time time.Time `json:"time" yaml:"time"`
+	// Resource is the resource name
+	Resource string `json:"resource" yaml:"resource"`
+	// Kind classifies the event
+	Kind EventKind `json:"kind" yaml:"kind"`
+	// Message describes the divergence or the outcome of the action
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// Format returns a one-line description of the event.
+//
+// Returns:
+//   - line: string such as "service/web: drift: fatal" without a trailing newline
+func (e Event) Format() string {
+	line := e.Resource + ": " + string(e.Kind)
+	if e.Message != "" {
+		line += ": " + e.Message
+	}
+	return line
+}
+
+// Options configures a Reconciler.
+//
+// All fields are optional.
+type Options struct {
+	// History is the number of events kept, default DefaultHistory
+	History int
+	// OnEvent is called for every event, serialized
+	OnEvent func(Event)
+}
+
+// status tracks one diverging resource.
+type status struct {
+	// reason is the divergence last observed
+	reason string
+	// attempts counts the actions since the resource diverged
+	attempts int
+	// next is the earliest time of the next action
+	next time.Time
+	// queued is set while the resource waits in the queue
+	queued bool
+	// throttled is set once the postponed action was reported
+	throttled bool
+	// manual is set once the resource reported it has no automatic action
+	manual bool
+}
+
+// Reconciler checks resources and corrects their divergences.
+type Reconciler struct {
+	// config holds the interval, the rate limit and the backoff
+	config providers.ReconcileConfig
+	// resources lists the resources of a pass, called at each pass
+	resources func() []Resource
+	// onEvent receives the events
+	onEvent func(Event)
+	// now returns the current time, replaced in tests
+	now func() time.Time
+	// passing serializes the passes
+	passing sync.Mutex
+	// diverged tracks the diverging resources by name, guarded by passing
+	diverged map[string]*status
+	// unobserved maps the resources whose check fails to the failure last reported, guarded by passing
+	unobserved map[string]string
+	// queue lists the resources waiting for an action in arrival order, guarded by passing
+	queue []string
+	// actions holds the times of the actions within the window, guarded by passing
+	actions []time.Time
+	// mu guards events, head and size
+	mu sync.Mutex
+	// events is the ring buffer of events
+	events []Event
+	// head is the index of the oldest event
+	head int
+	// size is the number of events held
+	size int
+}
+
+// New creates a reconciler.
+//
+// Parameters:
+//   - config: *providers.ReconcileConfig validated section with defaults applied
+//   - resources: func() []Resource listing the resources, called at each pass so the list can change
+//   - opts: *Options overrides, nil for defaults
+//
+// Returns:
+//   - reconciler: *Reconciler ready to Run
+func New(config *providers.ReconcileConfig, resources func() []Resource, opts *Options) *Reconciler {
+	if opts == nil {
+		opts = &Options{}
+	}
+	history := opts.History
+	if history <= 0 {
+		history = DefaultHistory
+	}
+	r := &Reconciler{
+		config:     *config,
+		resources:  resources,
+		onEvent:    opts.OnEvent,
+		now:        time.Now,
+		diverged:   make(map[string]*status),
+		unobserved: make(map[string]string),
+		events:     make([]Event, history),
+	}
+	if r.onEvent == nil {
+		r.onEvent = func(Event) {}
+	}
+	return r
+}
+
+// Reconcile runs one pass.
+//
+// Every resource is checked; a failed check is recorded when its error
+// changes and leaves the resource as it was. A divergence is recorded when
+// it appears or its reason changes, and the resource is queued once. The
+// queued resources are then corrected in arrival order, unless their
+// backoff, doubled at each action while the divergence lasts, has not
+// elapsed, or max_actions were already taken within the window; they stay
+// queued for the next pass. A resource reporting ErrNoAction is not retried
+// until its divergence changes.
+//
+// Parameters:
+//   - ctx: context.Context cancelling the checks and the actions
+func (r *Reconciler) Reconcile(ctx context.Context) {
+	r.passing.Lock()
+	defer r.passing.Unlock()
+
+	resources := r.resources()
+	byName := make(map[string]Resource, len(resources))
+	for _, resource := range resources {
+		name := resource.Name()
+		byName[name] = resource
+		reason, err := resource.Check(ctx)
+		if err != nil {
+			if message := "check: " + err.Error(); r.unobserved[name] != message {
+				r.unobserved[name] = message
+				r.record(name, EventFailed, message)
+			}
+			continue
+		}
+		delete(r.unobserved, name)
+		current := r.diverged[name]
+		if reason == "" {
+			if current != nil {
+				delete(r.diverged, name)
+				r.record(name, EventConverged, "")
+			}
+			continue
+		}
+		if current == nil {
+			current = &status{}
+			r.diverged[name] = current
+		}
+		if current.reason != reason {
+			current.reason, current.manual = reason, false
+			r.record(name, EventDrift, reason)
+		}
+		// A resource still diverging after an action is queued again, its backoff applies
+		if !current.queued && !current.manual {
+			current.queued = true
+			r.queue = append(r.queue, name)
+		}
+	}
+
+	// Resources gone from the list are forgotten
+	for name := range r.diverged {
+		if _, ok := byName[name]; !ok {
+			delete(r.diverged, name)
+		}
+	}
+	for name := range r.unobserved {
+		if _, ok := byName[name]; !ok {
+			delete(r.unobserved, name)
+		}
+	}
+	queue := r.queue[:0]
+	for _, name := range r.queue {
+		if r.diverged[name] != nil {
+			queue = append(queue, name)
+		}
+	}
+	r.queue = queue
+
+	r.act(ctx, byName)
+}
+
+// act takes the actions due, within the rate limit; r.passing must be held
+func (r *Reconciler) act(ctx context.Context, resources map[string]Resource) {
+	now := r.now()
+	recent := r.actions[:0]
+	for _, at := range r.actions {
+		if now.Sub(at) < r.config.Window {
+			recent = append(recent, at)
+		}
+	}
+	r.actions = recent
+
+	var waiting []string
+	for _, name := range r.queue {
+		current := r.diverged[name]
+		if ctx.Err() != nil || now.Before(current.next) {
+			waiting = append(waiting, name)
+			continue
+		}
+		if len(r.actions) >= r.config.MaxActions {
+			if !current.throttled {
+				current.throttled = true
+				r.record(name, EventThrottled, fmt.Sprintf("%d actions within %s", len(r.actions), r.config.Window))
+			}
+			waiting = append(waiting, name)
+			continue
+		}
+
+		err := resources[name].Apply(ctx)
+		if errors.Is(err, ErrNoAction) {
+			current.manual, current.queued = true, false
+			r.record(name, EventFailed, current.reason+": "+err.Error())
+			continue
+		}
+		r.actions = append(r.actions, now)
+		current.attempts++
+		current.throttled = false
+		current.next = now.Add(r.backoff(current.attempts))
+		if err != nil {
+			r.record(name, EventFailed, err.Error())
+			waiting = append(waiting, name)
+			continue
+		}
+		// The next pass checks the outcome and queues the resource again if it still diverges
+		current.queued = false
+		r.record(name, EventAction, "corrected "+current.reason)
+	}
+	r.queue = waiting
+}
+
+// backoff returns the delay after the given number of actions, doubled from Backoff up to MaxBackoff
+func (r *Reconciler) backoff(attempts int) time.Duration {
+	delay := r.config.Backoff
+	for i := 1; i < attempts && delay < r.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, r.config.MaxBackoff)
+}
+
+// Run runs a pass right away, then every interval, until ctx is done.
+//
+// Parameters:
+//   - ctx: context.Context stopping the passes
+func (r *Reconciler) Run(ctx context.Context) {
+	interval := r.config.Interval
+	if interval <= 0 {
+		interval = providers.DefaultReconcileInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		r.Reconcile(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Events returns the latest events, oldest first.
+//
+// Parameters:
+//   - resource: string resource name, empty for every resource
+//   - limit: int maximum number of events, 0 for all those kept
+//
+// Returns:
+//   - events: []Event matching events in time order
+func (r *Reconciler) Events(resource string, limit int) []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := make([]Event, 0, r.size)
+	for i := range r.size {
+		event := r.events[(r.head+i)%len(r.events)]
+		if resource == "" || event.Resource == resource {
+			events = append(events, event)
+		}
+	}
+	if limit > 0 && len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+	return events
+}
+
+// record appends an event to the history and reports it
+func (r *Reconciler) record(resource string, kind EventKind, message string) {
+	event := Event{Time: r.now(), Resource: resource, Kind: kind, Message: message}
+	r.mu.Lock()
+	if r.size < len(r.events) {
+		r.events[(r.head+r.size)%len(r.events)] = event
+		r.size++
+	} else {
+		r.events[r.head] = event
+		r.head = (r.head + 1) % len(r.events)
+	}
+	r.mu.Unlock()
+	r.onEvent(event)
+}
@@ -0,0 +1,203 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResource diverges with reason until an action clears it
+type fakeResource struct {
+	name     string
+	reason   string
+	checkErr error
+	applyErr error
+	applied  int
+	// fixes clears the reason when Apply succeeds
+	fixes bool
+}
+
+func (f *fakeResource) Name() string { return f.name }
+
+func (f *fakeResource) Check(context.Context) (string, error) { return f.reason, f.checkErr }
+
+func (f *fakeResource) Apply(context.Context) error {
+	f.applied++
+	if f.applyErr == nil && f.fixes {
+		f.reason = ""
+	}
+	return f.applyErr
+}
+
+// clock is a manual time source
+type clock struct{ now time.Time }
+
+func (c *clock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// newTestReconciler creates a reconciler over resources with a manual clock
+func newTestReconciler(config providers.ReconcileConfig, opts *Options, resources ...Resource) (*Reconciler, *clock) {
+	c := &clock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	r := New(&config, func() []Resource { return resources }, opts)
+	r.now = func() time.Time { return c.now }
+	return r, c
+}
+
+// kinds returns the kinds of the events of a resource
+func kinds(r *Reconciler, resource string) []EventKind {
+	var list []EventKind
+	for _, event := range r.Events(resource, 0) {
+		list = append(list, event.Kind)
+	}
+	return list
+}
+
+var testConfig = providers.ReconcileConfig{MaxActions: 10, Window: time.Minute, Backoff: time.Second, MaxBackoff: 4 * time.Second}
+
+func TestReconciler_Reconcile(t *testing.T) {
+	web := &fakeResource{name: "service/web", reason: "service is fatal", fixes: true}
+	ok := &fakeResource{name: "artifact/jq"}
+	var reported []string
+	r, _ := newTestReconciler(testConfig, &Options{OnEvent: func(e Event) { reported = append(reported, e.Format()) }}, web, ok)
+
+	r.Reconcile(context.Background())
+	assert.Equal(t, 1, web.applied)
+	assert.Equal(t, 0, ok.applied)
+	r.Reconcile(context.Background())
+	r.Reconcile(context.Background())
+	assert.Equal(t, 1, web.applied, "a converged resource is left alone")
+
+	assert.Equal(t, []string{
+		"service/web: drift: service is fatal",
+		"service/web: action: corrected service is fatal",
+		"service/web: converged",
+	}, reported)
+	assert.Equal(t, []EventKind{EventDrift, EventAction, EventConverged}, kinds(r, "service/web"))
+	assert.Empty(t, r.Events("artifact/jq", 0))
+}
+
+func TestReconciler_Backoff(t *testing.T) {
+	web := &fakeResource{name: "service/web", reason: "service is fatal"}
+	r, c := newTestReconciler(testConfig, nil, web)
+
+	// Each action leaves the service fatal, the delays double up to max_backoff
+	var applied []int
+	for range 16 {
+		r.Reconcile(context.Background())
+		applied = append(applied, web.applied)
+		c.advance(time.Second)
+	}
+	assert.Equal(t, []int{1, 2, 2, 3, 3, 3, 3, 4, 4, 4, 4, 5, 5, 5, 5, 6}, applied)
+	assert.Equal(t, []EventKind{EventDrift, EventAction, EventAction}, kinds(r, "service/web")[:3], "an unchanged divergence is recorded once")
+
+	web.reason = ""
+	r.Reconcile(context.Background())
+	web.reason = "service is fatal"
+	r.Reconcile(context.Background())
+	assert.Equal(t, 7, web.applied, "the backoff starts over once the resource converged")
+}
+
+func TestReconciler_RateLimit(t *testing.T) {
+	a := &fakeResource{name: "a", reason: "missing", fixes: true}
+	b := &fakeResource{name: "b", reason: "missing", fixes: true}
+	config := testConfig
+	config.MaxActions = 1
+	r, c := newTestReconciler(config, nil, a, b)
+
+	r.Reconcile(context.Background())
+	r.Reconcile(context.Background())
+	assert.Equal(t, 1, a.applied)
+	assert.Equal(t, 0, b.applied)
+	assert.Equal(t, []EventKind{EventDrift, EventThrottled}, kinds(r, "b"), "the postponement is recorded once")
+	assert.Equal(t, "1 actions within 1m0s", r.Events("b", 1)[0].Message)
+
+	c.advance(time.Minute)
+	r.Reconcile(context.Background())
+	assert.Equal(t, 1, b.applied, "the queued action runs once the window passed")
+	r.Reconcile(context.Background())
+	assert.Equal(t, []EventKind{EventDrift, EventThrottled, EventAction, EventConverged}, kinds(r, "b"))
+}
+
+func TestReconciler_Failures(t *testing.T) {
+	env := &fakeResource{name: "env/web", reason: "invalid environment: PORT is required", applyErr: ErrNoAction}
+	repo := &fakeResource{name: "repository/debian", checkErr: errors.New("connection refused")}
+	artifact := &fakeResource{name: "artifact/vault", reason: "vault 1.0 not installed", applyErr: errors.New("download failed")}
+	r, c := newTestReconciler(testConfig, nil, env, repo, artifact)
+
+	r.Reconcile(context.Background())
+	c.advance(time.Minute)
+	r.Reconcile(context.Background())
+	assert.Equal(t, 1, env.applied, "no action is retried until the divergence changes")
+	assert.Equal(t, 2, artifact.applied, "a failed action is retried after its backoff")
+	assert.Equal(t, 0, repo.applied)
+
+	events := r.Events("env/web", 0)
+	require.Len(t, events, 2)
+	assert.Equal(t, "env/web: failed: invalid environment: PORT is required: no automatic action", events[1].Format())
+	assert.Equal(t, []EventKind{EventFailed}, kinds(r, "repository/debian"), "the same check failure is recorded once")
+	assert.Equal(t, "check: connection refused", r.Events("repository/debian", 0)[0].Message)
+	assert.Equal(t, []EventKind{EventDrift, EventFailed, EventFailed}, kinds(r, "artifact/vault"))
+
+	env.reason = "invalid environment: PORT is required; HOST is required"
+	r.Reconcile(context.Background())
+	assert.Equal(t, 2, env.applied, "a new divergence is acted on")
+}
+
+func TestReconciler_Events(t *testing.T) {
+	a := &fakeResource{name: "a"}
+	b := &fakeResource{name: "b"}
+	r, _ := newTestReconciler(testConfig, &Options{History: 3}, a, b)
+	for _, reason := range []string{"one", "two", "three"} {
+		a.reason, b.reason = reason, reason
+		a.applyErr, b.applyErr = ErrNoAction, ErrNoAction
+		r.Reconcile(context.Background())
+	}
+
+	events := r.Events("", 0)
+	require.Len(t, events, 3, "the oldest events are dropped")
+	assert.Equal(t, "b: failed: three: no automatic action", events[2].Format())
+	assert.Len(t, r.Events("", 2), 2)
+	assert.Equal(t, "a: failed: three: no automatic action", r.Events("", 2)[0].Format())
+	assert.Len(t, r.Events("a", 0), 1)
+	assert.Empty(t, r.Events("c", 0))
+}
+
+func TestReconciler_ForgetsRemovedResources(t *testing.T) {
+	a := &fakeResource{name: "a", reason: "missing"}
+	resources := []Resource{a}
+	r := New(&testConfig, func() []Resource { return resources }, nil)
+	r.Reconcile(context.Background())
+	resources = nil
+	r.Reconcile(context.Background())
+	assert.Empty(t, r.diverged)
+	assert.Empty(t, r.queue)
+}
+
+func TestReconciler_Run(t *testing.T) {
+	a := &fakeResource{name: "a", reason: "missing", fixes: true}
+	config := testConfig
+	config.Interval = 5 * time.Millisecond
+	events := make(chan Event, 10)
+	r := New(&config, func() []Resource { return []Resource{a} }, &Options{OnEvent: func(e Event) { events <- e }})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(ctx)
+	}()
+
+	for _, kind := range []EventKind{EventDrift, EventAction, EventConverged} {
+		select {
+		case event := <-events:
+			assert.Equal(t, kind, event.Kind)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s event", kind)
+		}
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,218 @@
+// internal/services/reconcile/resources.go - Declared state of superviz.yaml as reconciled resources
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services/artifact"
+	"github.com/kodflow/superviz.io/internal/services/integrity"
+	"github.com/kodflow/superviz.io/internal/services/supervisor"
+)
+
+// Supervisor is the supervisor whose services are reconciled.
+type Supervisor interface {
+	// Services returns every service in start order
+	Services() []supervisor.ServiceStatus
+	// Service returns one service
+	Service(name string) (supervisor.ServiceStatus, error)
+	// Start starts a stopped, exited or fatal service
+	Start(name string) error
+	// CheckEnv resolves the environment of a service without starting it
+	CheckEnv(name string) error
+}
+
+// Services returns the resources of every supervised service: the service
+// itself, expected not to be fatal, and its environment, expected to resolve.
+//
+// Parameters:
+//   - sup: Supervisor running the services
+//
+// Returns:
+//   - resources: []Resource two resources per service in start order
+func Services(sup Supervisor) []Resource {
+	statuses := sup.Services()
+	resources := make([]Resource, 0, 2*len(statuses))
+	for _, status := range statuses {
+		resources = append(resources, &service{sup: sup, name: status.Name}, &env{sup: sup, name: status.Name})
+	}
+	return resources
+}
+
+// service is a supervised service, diverging once fatal; the operator
+// stopping it or its restart mode letting it exit are not divergences
+type service struct {
+	// sup runs the service
+	sup Supervisor
+	// name is the service name
+	name string
+}
+
+// Name returns service/<name>
+func (s *service) Name() string {
+	return "service/" + s.name
+}
+
+// Check reports a fatal service
+func (s *service) Check(context.Context) (string, error) {
+	status, err := s.sup.Service(s.name)
+	if err != nil {
+		return "", err
+	}
+	if status.State == supervisor.StateFatal {
+		return "service is fatal", nil
+	}
+	return "", nil
+}
+
+// Apply starts the service again, which waits for its dependencies
+func (s *service) Apply(context.Context) error {
+	if err := s.sup.Start(s.name); err != nil && !errors.Is(err, supervisor.ErrServiceActive) {
+		return err
+	}
+	return nil
+}
+
+// env is the environment of a service, diverging while it does not resolve
+type env struct {
+	// sup runs the service
+	sup Supervisor
+	// name is the service name
+	name string
+}
+
+// Name returns env/<name>
+func (e *env) Name() string {
+	return "env/" + e.name
+}
+
+// Check reports the variables failing to resolve, without their secret values
+func (e *env) Check(context.Context) (string, error) {
+	var envErr *supervisor.EnvError
+	if err := e.sup.CheckEnv(e.name); errors.As(err, &envErr) {
+		return envErr.Error(), nil
+	} else if err != nil {
+		return "", err
+	}
+	return "", nil
+}
+
+// Apply cannot fix the environment of the host
+func (e *env) Apply(context.Context) error {
+	return ErrNoAction
+}
+
+// files are the files of the integrity section, diverging while one fails
+type files struct {
+	// config is the validated integrity section
+	config *providers.IntegrityConfig
+	// monitor gates the service starts on the latest check
+	monitor *integrity.Monitor
+}
+
+// Files returns the resource named files checking the integrity section.
+//
+// The action checks the files again through the monitor, so the services
+// are gated on the latest state, and restores them with on_failure:
+// restore; otherwise the files must be fixed by hand.
+//
+// Parameters:
+//   - config: *providers.IntegrityConfig validated section
+//   - monitor: *integrity.Monitor gating the service starts
+//
+// Returns:
+//   - resource: Resource named files
+func Files(config *providers.IntegrityConfig, monitor *integrity.Monitor) Resource {
+	return &files{config: config, monitor: monitor}
+}
+
+// Name returns files
+func (f *files) Name() string {
+	return "files"
+}
+
+// Check lists the failing files
+func (f *files) Check(context.Context) (string, error) {
+	return failures(integrity.Check(f.config).Failed()), nil
+}
+
+// Apply checks the files through the monitor, restoring them when configured
+func (f *files) Apply(context.Context) error {
+	failed := f.monitor.Check().Failed()
+	switch {
+	case len(failed) == 0:
+		return nil
+	case f.config.OnFailure != providers.IntegrityRestore:
+		return ErrNoAction
+	default:
+		return fmt.Errorf("restore failed: %s", failures(failed))
+	}
+}
+
+// failures joins the descriptions of failed integrity results
+func failures(results []integrity.Result) string {
+	lines := make([]string, 0, len(results))
+	for _, result := range results {
+		lines = append(lines, result.Format())
+	}
+	return strings.Join(lines, "; ")
+}
+
+// installed is one declared artifact, diverging while it is not installed
+// at its declared version
+type installed struct {
+	// installer installs the artifact
+	installer *artifact.Installer
+	// config is the artifact declaration
+	config *providers.ArtifactConfig
+	// gate records the installations, nil when no service is gated
+	gate *artifact.Gate
+}
+
+// Artifacts returns one resource per artifact, named artifact/<name>, in name order.
+//
+// The action installs the artifact again and records the outcome in gate,
+// so the services depending on it may start once it is installed.
+//
+// Parameters:
+//   - installer: *artifact.Installer installing the artifacts
+//   - artifacts: providers.Artifacts validated declarations
+//   - gate: *artifact.Gate refusing the services, nil for none
+//
+// Returns:
+//   - resources: []Resource one resource per artifact
+func Artifacts(installer *artifact.Installer, artifacts providers.Artifacts, gate *artifact.Gate) []Resource {
+	resources := make([]Resource, 0, len(artifacts))
+	for _, name := range artifacts.Names() {
+		resources = append(resources, &installed{installer: installer, config: artifacts[name], gate: gate})
+	}
+	return resources
+}
+
+// Name returns artifact/<name>
+func (i *installed) Name() string {
+	return "artifact/" + i.config.Name
+}
+
+// Check reports an artifact missing, modified or at another version
+func (i *installed) Check(context.Context) (string, error) {
+	if i.installer.Installed(providers.Artifacts{i.config.Name: i.config}) {
+		return "", nil
+	}
+	return fmt.Sprintf("%s %s not installed at %s", i.config.Name, i.config.Version, i.config.Path), nil
+}
+
+// Apply installs the artifact
+func (i *installed) Apply(ctx context.Context) error {
+	result := i.installer.Install(ctx, providers.Artifacts{i.config.Name: i.config}).Results[0]
+	if i.gate != nil {
+		i.gate.Record(result)
+	}
+	if !result.OK() {
+		return errors.New(result.Message)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package reconcile
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services/artifact"
+	"github.com/kodflow/superviz.io/internal/services/integrity"
+	"github.com/kodflow/superviz.io/internal/services/supervisor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSupervisor holds service states and environment errors
+type fakeSupervisor struct {
+	states map[string]supervisor.State
+	envs   map[string]error
+	order  []string
+}
+
+func (f *fakeSupervisor) Services() []supervisor.ServiceStatus {
+	statuses := make([]supervisor.ServiceStatus, 0, len(f.order))
+	for _, name := range f.order {
+		statuses = append(statuses, supervisor.ServiceStatus{Name: name, State: f.states[name]})
+	}
+	return statuses
+}
+
+func (f *fakeSupervisor) Service(name string) (supervisor.ServiceStatus, error) {
+	state, ok := f.states[name]
+	if !ok {
+		return supervisor.ServiceStatus{}, fmt.Errorf("%w %q", supervisor.ErrUnknownService, name)
+	}
+	return supervisor.ServiceStatus{Name: name, State: state}, nil
+}
+
+func (f *fakeSupervisor) Start(name string) error {
+	if f.states[name] == supervisor.StateRunning {
+		return supervisor.ErrServiceActive
+	}
+	f.states[name] = supervisor.StateStarting
+	return nil
+}
+
+func (f *fakeSupervisor) CheckEnv(name string) error {
+	return f.envs[name]
+}
+
+// check returns the divergence of a resource, failing the test on a check error
+func check(t *testing.T, resource Resource) string {
+	t.Helper()
+	reason, err := resource.Check(context.Background())
+	require.NoError(t, err)
+	return reason
+}
+
+func TestServices(t *testing.T) {
+	sup := &fakeSupervisor{
+		states: map[string]supervisor.State{"db": supervisor.StateRunning, "web": supervisor.StateFatal, "job": supervisor.StateStopped},
+		envs:   map[string]error{"web": &supervisor.EnvError{Problems: []string{"PORT is required"}}},
+		order:  []string{"db", "web", "job"},
+	}
+	resources := Services(sup)
+	names := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		names = append(names, resource.Name())
+	}
+	assert.Equal(t, []string{"service/db", "env/db", "service/web", "env/web", "service/job", "env/job"}, names)
+
+	assert.Empty(t, check(t, resources[0]))
+	assert.Empty(t, check(t, resources[4]), "a service stopped by the operator does not diverge")
+	assert.Equal(t, "service is fatal", check(t, resources[2]))
+	require.NoError(t, resources[2].Apply(context.Background()))
+	assert.Equal(t, supervisor.StateStarting, sup.states["web"])
+	assert.Empty(t, check(t, resources[2]))
+	require.NoError(t, resources[0].Apply(context.Background()), "a service started meanwhile is converged")
+
+	assert.Empty(t, check(t, resources[1]))
+	assert.Equal(t, "invalid environment: PORT is required", check(t, resources[3]))
+	assert.ErrorIs(t, resources[3].Apply(context.Background()), ErrNoAction)
+
+	delete(sup.states, "db")
+	sup.envs["db"] = fmt.Errorf("%w %q", supervisor.ErrUnknownService, "db")
+	_, err := resources[0].Check(context.Background())
+	assert.ErrorIs(t, err, supervisor.ErrUnknownService)
+	_, err = resources[1].Check(context.Background())
+	assert.ErrorIs(t, err, supervisor.ErrUnknownService)
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	path, golden := filepath.Join(dir, "app"), filepath.Join(dir, "golden")
+	digest := sha256.Sum256([]byte("good"))
+	sum := hex.EncodeToString(digest[:])
+	require.NoError(t, os.WriteFile(path, []byte("good"), 0o600))
+	require.NoError(t, os.WriteFile(golden, []byte("good"), 0o600))
+
+	config := &providers.IntegrityConfig{Files: []providers.IntegrityFile{{Path: path, SHA256: sum}}, OnFailure: providers.IntegrityRefuse}
+	monitor := integrity.NewMonitor(config, nil)
+	files := Files(config, monitor)
+	assert.Equal(t, "files", files.Name())
+	assert.Empty(t, check(t, files))
+
+	require.NoError(t, os.WriteFile(path, []byte("bad"), 0o600))
+	assert.Contains(t, check(t, files), path+": sha256 mismatch, expected "+sum)
+	assert.Nil(t, monitor.Last(), "a check leaves the gate alone")
+	assert.ErrorIs(t, files.Apply(context.Background()), ErrNoAction)
+	assert.Error(t, monitor.Allow("web"), "the action refreshes the gate")
+
+	config.OnFailure = providers.IntegrityRestore
+	config.Files[0].Source = golden
+	require.NoError(t, files.Apply(context.Background()))
+	assert.Empty(t, check(t, files))
+	assert.NoError(t, monitor.Allow("web"))
+
+	require.NoError(t, os.WriteFile(path, []byte("bad"), 0o600))
+	require.NoError(t, os.WriteFile(golden, []byte("bad too"), 0o600))
+	assert.ErrorContains(t, files.Apply(context.Background()), "restore failed: "+path)
+}
+
+func TestArtifacts(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jq" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("jq")) //nolint:errcheck // test server
+	}))
+	t.Cleanup(server.Close)
+	digest := sha256.Sum256([]byte("jq"))
+
+	dir := t.TempDir()
+	artifacts := providers.Artifacts{
+		"jq": {
+			Name: "jq", Version: "1.7", URL: server.URL + "/jq", Format: providers.ArtifactRaw,
+			Path: filepath.Join(dir, "bin", "jq"), Mode: 0o755, Services: []string{"web"},
+			SHA256: providers.PlatformDigests{providers.AnyPlatform: hex.EncodeToString(digest[:])},
+		},
+		"yq": {
+			Name: "yq", Version: "4", URL: server.URL + "/yq", Format: providers.ArtifactRaw,
+			Path: filepath.Join(dir, "bin", "yq"), Mode: 0o755, Services: []string{"api"},
+			SHA256: providers.PlatformDigests{providers.AnyPlatform: strings.Repeat("0", 64)},
+		},
+	}
+	installer := artifact.NewInstaller(filepath.Join(dir, "cache"), &artifact.InstallerOptions{HTTPClient: server.Client()})
+	gate := artifact.NewGate(&artifact.Report{Results: []artifact.Result{
+		{Name: "jq", Status: artifact.StatusFailed, Message: "connection refused", Services: []string{"web"}},
+	}})
+	resources := Artifacts(installer, artifacts, gate)
+	require.Len(t, resources, 2)
+	jq, yq := resources[0], resources[1]
+	assert.Equal(t, "artifact/jq", jq.Name())
+	assert.Equal(t, "jq 1.7 not installed at "+filepath.Join(dir, "bin", "jq"), check(t, jq))
+	require.Error(t, gate.Allow("web"))
+
+	require.NoError(t, jq.Apply(context.Background()))
+	assert.Empty(t, check(t, jq))
+	assert.NoError(t, gate.Allow("web"), "the installation lifts the refusal")
+
+	assert.ErrorContains(t, yq.Apply(context.Background()), "404")
+	assert.ErrorContains(t, gate.Allow("api"), "artifact yq not installed")
+}
@@ -3,12 +3,17 @@ package alpine
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"strings"
 
 	"github.com/kodflow/superviz.io/internal/infrastructure/transports/ssh"
 	"github.com/kodflow/superviz.io/internal/services/repository/common"
 )
 
+// defaultBaseURL is the production superviz.io repository location.
+const defaultBaseURL = "https://repo.superviz.io"
+
 // Handler handles Alpine repository setup.
 //
 //	handler := NewHandler(client)
@@ -19,6 +24,8 @@ import (
 type Handler struct {
 	// Base provides common repository setup functionality
 	Base *common.BaseHandler
+	// baseURL is the repository root serving the alpine/ tree
+	baseURL string
 }
 
 // NewHandler creates a new Alpine repository handler.
@@ -32,8 +39,23 @@ type Handler struct {
 // Returns:
 //   - handler: *Handler configured Alpine repository handler
 func NewHandler(client ssh.Client) *Handler {
+	return NewHandlerWithBaseURL(client, defaultBaseURL)
+}
+
+// NewHandlerWithBaseURL creates an Alpine repository handler for a mirror.
+//
+//	handler := NewHandlerWithBaseURL(client, "https://mirror.example.com/superviz")
+//
+// Parameters:
+//   - client: ssh.Client SSH client for executing commands
+//   - baseURL: string repository root serving the alpine/ tree
+//
+// Returns:
+//   - handler: *Handler configured Alpine repository handler
+func NewHandlerWithBaseURL(client ssh.Client, baseURL string) *Handler {
 	return &Handler{
-		Base: common.NewBaseHandler(client),
+		Base:    common.NewBaseHandler(client),
+		baseURL: strings.TrimRight(baseURL, "/"),
 	}
 }
 
@@ -52,12 +74,16 @@ func NewHandler(client ssh.Client) *Handler {
 // Returns:
 //   - err: error if repository setup fails
 func (h *Handler) Setup(ctx context.Context, writer io.Writer) error {
+	if err := common.ValidateURL(h.baseURL); err != nil {
+		return fmt.Errorf("invalid repository URL: %w", err)
+	}
+
 	commands := []string{
 		// Add repository
-		"echo 'https://repo.superviz.io/alpine/v$(cat /etc/alpine-release | cut -d'.' -f1-2)/main' >> /etc/apk/repositories",
+		"echo '" + h.baseURL + "/alpine/v$(cat /etc/alpine-release | cut -d'.' -f1-2)/main' >> /etc/apk/repositories",
 
 		// Add public key
-		"wget -O /tmp/superviz.rsa.pub https://repo.superviz.io/alpine/superviz.rsa.pub",
+		"wget -O /tmp/superviz.rsa.pub " + h.baseURL + "/alpine/superviz.rsa.pub",
 		"cp /tmp/superviz.rsa.pub /etc/apk/keys/superviz.rsa.pub",
 		"rm /tmp/superviz.rsa.pub",
 
@@ -67,3 +93,17 @@ func (h *Handler) Setup(ctx context.Context, writer io.Writer) error {
 
 	return h.Base.ExecuteSetup(ctx, writer, "Setting up APK repository...", commands)
 }
+
+// State reports whether the APK repository and its public key are configured.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//
+// Returns:
+//   - state: common.RepoState probe results
+func (h *Handler) State(ctx context.Context) common.RepoState {
+	return h.Base.ProbeState(ctx,
+		"grep -qF '"+h.baseURL+"/alpine/' /etc/apk/repositories",
+		"test -s /etc/apk/keys/superviz.rsa.pub",
+	)
+}
@@ -224,3 +224,46 @@ func (w *conditionalFailingWriter) Write(p []byte) (n int, err error) {
 	}
 	return len(p), nil
 }
+
+func TestHandler_Setup_Mirror(t *testing.T) {
+	client := &MockSSHClient{}
+	client.On("Execute", mock.Anything, "test -w /etc/apt/sources.list.d/").Return(nil)
+
+	expectedCommands := []string{
+		"echo 'https://mirror.example.com/superviz/alpine/v$(cat /etc/alpine-release | cut -d'.' -f1-2)/main' >> /etc/apk/repositories",
+		"wget -O /tmp/superviz.rsa.pub https://mirror.example.com/superviz/alpine/superviz.rsa.pub",
+		"cp /tmp/superviz.rsa.pub /etc/apk/keys/superviz.rsa.pub",
+		"rm /tmp/superviz.rsa.pub",
+		"apk update",
+	}
+	for _, cmd := range expectedCommands {
+		client.On("Execute", mock.Anything, cmd).Return(nil)
+	}
+
+	handler := NewHandlerWithBaseURL(client, "https://mirror.example.com/superviz/")
+	var output bytes.Buffer
+
+	assert.NoError(t, handler.Setup(context.Background(), &output))
+	client.AssertExpectations(t)
+}
+
+func TestHandler_Setup_InvalidMirror(t *testing.T) {
+	client := &MockSSHClient{}
+	handler := NewHandlerWithBaseURL(client, "http://mirror.example.com")
+
+	err := handler.Setup(context.Background(), &bytes.Buffer{})
+
+	assert.ErrorContains(t, err, "invalid repository URL")
+	client.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything)
+}
+
+func TestHandler_State(t *testing.T) {
+	client := &MockSSHClient{}
+	client.On("Execute", mock.Anything, "grep -qF 'https://mirror.example.com/alpine/' /etc/apk/repositories").Return(nil)
+	client.On("Execute", mock.Anything, "test -s /etc/apk/keys/superviz.rsa.pub").Return(nil)
+
+	state := NewHandlerWithBaseURL(client, "https://mirror.example.com").State(context.Background())
+
+	assert.True(t, state.Configured())
+	client.AssertExpectations(t)
+}
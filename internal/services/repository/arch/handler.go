@@ -5,12 +5,16 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/kodflow/superviz.io/internal/infrastructure/transports/ssh"
 	"github.com/kodflow/superviz.io/internal/providers"
 	"github.com/kodflow/superviz.io/internal/services/repository/common"
 )
 
+// defaultBaseURL is the production superviz.io repository location.
+const defaultBaseURL = "https://repo.superviz.io"
+
 // Handler handles Arch repository setup.
 //
 //	handler := NewHandler(client, provider)
@@ -23,6 +27,8 @@ type Handler struct {
 	Base *common.BaseHandler
 	// provider supplies GPG key and repository information
 	provider providers.InstallProvider
+	// baseURL is the repository root serving the arch/ tree
+	baseURL string
 }
 
 // NewHandler creates a new Arch repository handler.
@@ -38,9 +44,25 @@ type Handler struct {
 // Returns:
 //   - handler: *Handler configured Arch repository handler
 func NewHandler(client ssh.Client, provider providers.InstallProvider) *Handler {
+	return NewHandlerWithBaseURL(client, provider, defaultBaseURL)
+}
+
+// NewHandlerWithBaseURL creates an Arch repository handler for a mirror.
+//
+//	handler := NewHandlerWithBaseURL(client, provider, "https://mirror.example.com/superviz")
+//
+// Parameters:
+//   - client: ssh.Client SSH client for executing commands
+//   - provider: providers.InstallProvider install configuration provider
+//   - baseURL: string repository root serving the arch/ tree
+//
+// Returns:
+//   - handler: *Handler configured Arch repository handler
+func NewHandlerWithBaseURL(client ssh.Client, provider providers.InstallProvider, baseURL string) *Handler {
 	return &Handler{
 		Base:     common.NewBaseHandler(client),
 		provider: provider,
+		baseURL:  strings.TrimRight(baseURL, "/"),
 	}
 }
 
@@ -59,12 +81,16 @@ func NewHandler(client ssh.Client, provider providers.InstallProvider) *Handler
 // Returns:
 //   - err: error if repository setup fails
 func (h *Handler) Setup(ctx context.Context, writer io.Writer) error {
+	if err := common.ValidateURL(h.baseURL); err != nil {
+		return fmt.Errorf("invalid repository URL: %w", err)
+	}
+
 	// Get GPG key ID from provider
 	gpgKeyID := h.provider.GetGPGKeyID()
 
 	commands := []string{
 		// Create temporary config addition
-		"cat >> /tmp/superviz-pacman.conf << 'EOF'\n\n[superviz]\nServer = https://repo.superviz.io/arch/$arch\nEOF",
+		"cat >> /tmp/superviz-pacman.conf << 'EOF'\n\n[superviz]\nServer = " + h.baseURL + "/arch/$arch\nEOF",
 
 		// Add to pacman.conf
 		"cat /tmp/superviz-pacman.conf >> /etc/pacman.conf",
@@ -80,3 +106,21 @@ func (h *Handler) Setup(ctx context.Context, writer io.Writer) error {
 
 	return h.Base.ExecuteSetup(ctx, writer, "Setting up Pacman repository...", commands)
 }
+
+// State reports whether the Pacman repository and its signing key are configured.
+//
+// Listing the keyring may require root, so the key probe falls back to
+// non-interactive sudo.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//
+// Returns:
+//   - state: common.RepoState probe results
+func (h *Handler) State(ctx context.Context) common.RepoState {
+	listKey := fmt.Sprintf("pacman-key --list-keys %s >/dev/null 2>&1", h.provider.GetGPGKeyID())
+	return h.Base.ProbeState(ctx,
+		"grep -q '^\\[superviz\\]' /etc/pacman.conf",
+		listKey+" || sudo -n "+listKey,
+	)
+}
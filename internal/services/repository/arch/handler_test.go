@@ -287,3 +287,54 @@ func (w *conditionalFailingWriter) Write(p []byte) (n int, err error) {
 	}
 	return len(p), nil
 }
+
+func TestHandler_Setup_Mirror(t *testing.T) {
+	client := &MockSSHClient{}
+	provider := &MockInstallProvider{}
+
+	client.On("Execute", mock.Anything, "test -w /etc/apt/sources.list.d/").Return(nil)
+	provider.On("GetGPGKeyID").Return("ABC123")
+
+	expectedCommands := []string{
+		"cat >> /tmp/superviz-pacman.conf << 'EOF'\n\n[superviz]\nServer = https://mirror.example.com/superviz/arch/$arch\nEOF",
+		"cat /tmp/superviz-pacman.conf >> /etc/pacman.conf",
+		"rm /tmp/superviz-pacman.conf",
+		"pacman-key --recv-keys ABC123",
+		"pacman-key --lsign-key ABC123",
+		"pacman -Sy",
+	}
+	for _, cmd := range expectedCommands {
+		client.On("Execute", mock.Anything, cmd).Return(nil)
+	}
+
+	handler := NewHandlerWithBaseURL(client, provider, "https://mirror.example.com/superviz")
+	var output bytes.Buffer
+
+	assert.NoError(t, handler.Setup(context.Background(), &output))
+	client.AssertExpectations(t)
+}
+
+func TestHandler_Setup_InvalidMirror(t *testing.T) {
+	client := &MockSSHClient{}
+	handler := NewHandlerWithBaseURL(client, &MockInstallProvider{}, "https://mirror.example.com/$(id)")
+
+	err := handler.Setup(context.Background(), &bytes.Buffer{})
+
+	assert.ErrorContains(t, err, "invalid repository URL")
+	client.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything)
+}
+
+func TestHandler_State(t *testing.T) {
+	client := &MockSSHClient{}
+	provider := &MockInstallProvider{}
+	provider.On("GetGPGKeyID").Return("ABCD1234")
+	client.On("Execute", mock.Anything, `grep -q '^\[superviz\]' /etc/pacman.conf`).Return(errors.New("exit status 1"))
+	client.On("Execute", mock.Anything, "pacman-key --list-keys ABCD1234 >/dev/null 2>&1 || sudo -n pacman-key --list-keys ABCD1234 >/dev/null 2>&1").Return(nil)
+
+	state := NewHandler(client, provider).State(context.Background())
+
+	assert.False(t, state.Repository)
+	assert.True(t, state.Key)
+	assert.False(t, state.Configured())
+	client.AssertExpectations(t)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing"
 	"github.com/kodflow/superviz.io/internal/infrastructure/transports/ssh"
 )
 
@@ -63,7 +64,10 @@ func (h *BaseHandler) ExecuteSetup(ctx context.Context, writer io.Writer, setupM
 	}
 
 	// Detect sudo requirement
-	needSudo, err := h.sudo.IsNeeded(ctx)
+	sudoCtx, span := tracing.Start(ctx, "sudo.probe")
+	needSudo, err := h.sudo.IsNeeded(sudoCtx)
+	span.SetAttributes(tracing.Attr("sudo.needed", needSudo))
+	span.End(err)
 	if err != nil {
 		return fmt.Errorf("failed to detect sudo requirement: %w", err)
 	}
@@ -82,3 +86,22 @@ func (h *BaseHandler) ExecuteSetup(ctx context.Context, writer io.Writer, setupM
 	executor := NewCommandExecutor(h.client)
 	return executor.Execute(ctx, commands, writer)
 }
+
+// Probe runs a read-only check command on the target system.
+//
+//	if handler.Probe(ctx, "command -v apt-get >/dev/null 2>&1") {
+//		// apt-get is available
+//	}
+//
+// Probe is intended for capability detection before building the setup
+// command list; it never applies sudo and treats any failure as false.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - command: string shell command whose exit status is evaluated
+//
+// Returns:
+//   - ok: bool true if the command exited successfully
+func (h *BaseHandler) Probe(ctx context.Context, command string) bool {
+	return h.client.Execute(ctx, command) == nil
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"strings"
 
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing"
 	"github.com/kodflow/superviz.io/internal/infrastructure/transports/ssh"
 )
 
@@ -129,6 +130,17 @@ func (s *SudoHelper) commandNeedsSudo(cmd string) bool {
 	return false
 }
 
+// StepObserver receives structured progress from CommandExecutor.
+//
+// Writers handed to repository handlers may implement StepObserver to
+// receive one notification per command instead of free-form progress lines.
+type StepObserver interface {
+	// StepStarted is called before a command runs.
+	StepStarted(step, total int, command string)
+	// StepFinished is called after a command ran, with its error if any.
+	StepFinished(step, total int, command string, err error)
+}
+
 // CommandExecutor executes commands with proper error handling.
 type CommandExecutor struct {
 	client ssh.Client
@@ -143,11 +155,40 @@ func NewCommandExecutor(client ssh.Client) *CommandExecutor {
 
 // Execute executes a list of commands in sequence.
 func (c *CommandExecutor) Execute(ctx context.Context, commands []string, writer io.Writer) error {
+	if observer, ok := writer.(StepObserver); ok {
+		return c.executeObserved(ctx, commands, observer)
+	}
+
 	for i, cmd := range commands {
 		if _, err := fmt.Fprintf(writer, "  [%d/%d] %s\n", i+1, len(commands), cmd); err != nil {
 			return fmt.Errorf("failed to write to output: %w", err)
 		}
-		if err := c.client.Execute(ctx, cmd); err != nil {
+		if err := c.step(ctx, i+1, len(commands), cmd); err != nil {
+			return fmt.Errorf("command failed: %s: %w", cmd, err)
+		}
+	}
+	return nil
+}
+
+// step runs one command within its trace span
+func (c *CommandExecutor) step(ctx context.Context, step, total int, cmd string) error {
+	ctx, span := tracing.Start(ctx, "repository.step",
+		tracing.Attr("step.index", step),
+		tracing.Attr("step.total", total),
+		tracing.Attr("step.command", cmd))
+	err := c.client.Execute(ctx, cmd)
+	span.End(err)
+	return err
+}
+
+// executeObserved executes commands reporting each step to observer.
+func (c *CommandExecutor) executeObserved(ctx context.Context, commands []string, observer StepObserver) error {
+	total := len(commands)
+	for i, cmd := range commands {
+		observer.StepStarted(i+1, total, cmd)
+		err := c.step(ctx, i+1, total, cmd)
+		observer.StepFinished(i+1, total, cmd, err)
+		if err != nil {
 			return fmt.Errorf("command failed: %s: %w", cmd, err)
 		}
 	}
@@ -3,12 +3,16 @@ package common
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing/tracingtest"
 	"github.com/kodflow/superviz.io/internal/infrastructure/transports/ssh"
 )
 
@@ -276,3 +280,58 @@ type FailingWriter struct{}
 func (f *FailingWriter) Write(p []byte) (n int, err error) {
 	return 0, errors.New("write failed")
 }
+
+// recordingObserver implements io.Writer and StepObserver for testing
+type recordingObserver struct {
+	MockWriter
+	events []string
+}
+
+func (r *recordingObserver) StepStarted(step, total int, command string) {
+	r.events = append(r.events, fmt.Sprintf("start %d/%d %s", step, total, command))
+}
+
+func (r *recordingObserver) StepFinished(step, total int, command string, err error) {
+	r.events = append(r.events, fmt.Sprintf("finish %d/%d %s %v", step, total, command, err))
+}
+
+func TestCommandExecutor_Execute_StepObserver(t *testing.T) {
+	client := &mockSSHClient{}
+	client.On("Execute", mock.Anything, "echo ok").Return(nil).Once()
+	client.On("Execute", mock.Anything, "false").Return(errors.New("exit 1")).Once()
+
+	observer := &recordingObserver{}
+	err := NewCommandExecutor(client).Execute(context.Background(), []string{"echo ok", "false", "pwd"}, observer)
+
+	assert.ErrorContains(t, err, "command failed: false")
+	assert.Equal(t, []string{
+		"start 1/3 echo ok",
+		"finish 1/3 echo ok <nil>",
+		"start 2/3 false",
+		"finish 2/3 false exit 1",
+	}, observer.events)
+	assert.Empty(t, observer.String(), "observed execution must not write progress lines")
+	client.AssertExpectations(t)
+}
+
+func TestBaseHandler_ExecuteSetup_Traces(t *testing.T) {
+	recorder := tracingtest.Install(t)
+	client := &mockSSHClient{}
+	client.On("Execute", mock.Anything, "test -w /etc/apt/sources.list.d/").Return(nil)
+	client.On("Execute", mock.Anything, "apt-get update").Return(nil)
+	client.On("Execute", mock.Anything, "apt-get install -y curl").Return(errors.New("exit status 100"))
+
+	err := NewBaseHandler(client).ExecuteSetup(context.Background(), io.Discard, "Setting up...", []string{"apt-get update", "apt-get install -y curl"})
+	require.Error(t, err)
+
+	spans := recorder.Spans()
+	require.Equal(t, []string{"sudo.probe", "repository.step", "repository.step"}, tracingtest.Names(spans))
+	assert.Contains(t, spans[0].Attributes, otlp.Attribute("sudo.needed", false))
+	assert.Equal(t, []otlp.KeyValue{
+		otlp.Attribute("step.index", 2),
+		otlp.Attribute("step.total", 2),
+		otlp.Attribute("step.command", "apt-get install -y curl"),
+	}, spans[2].Attributes)
+	assert.Equal(t, otlp.StatusUnset, spans[1].Status.Code)
+	assert.Equal(t, otlp.StatusError, spans[2].Status.Code)
+}
@@ -0,0 +1,39 @@
+// internal/services/repository/common/state.go
+package common
+
+import "context"
+
+// RepoState reports whether the superviz.io repository is configured on a host.
+type RepoState struct {
+	// Repository is true when the package source is configured
+	Repository bool `json:"repository" yaml:"repository"`
+	// Key is true when the repository signing key is installed
+	Key bool `json:"key" yaml:"key"`
+}
+
+// Configured reports whether both the source and its key are present.
+//
+// Returns:
+//   - configured: bool true when the repository is usable
+func (s RepoState) Configured() bool {
+	return s.Repository && s.Key
+}
+
+// ProbeState runs the repository and key probes through the handler.
+//
+// Probes are read-only commands that succeed when the checked item is present;
+// they run without sudo.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - repoProbe: string command succeeding when the source is configured
+//   - keyProbe: string command succeeding when the key is installed
+//
+// Returns:
+//   - state: RepoState probe results
+func (h *BaseHandler) ProbeState(ctx context.Context, repoProbe, keyProbe string) RepoState {
+	return RepoState{
+		Repository: h.Probe(ctx, repoProbe),
+		Key:        h.Probe(ctx, keyProbe),
+	}
+}
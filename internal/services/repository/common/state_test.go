@@ -0,0 +1,23 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBaseHandler_ProbeState(t *testing.T) {
+	client := &mockSSHClient{}
+	client.On("Execute", mock.Anything, "test -f repo").Return(nil)
+	client.On("Execute", mock.Anything, "test -f key").Return(errors.New("exit status 1"))
+
+	state := NewBaseHandler(client).ProbeState(context.Background(), "test -f repo", "test -f key")
+
+	assert.Equal(t, RepoState{Repository: true, Key: false}, state)
+	assert.False(t, state.Configured())
+	assert.True(t, RepoState{Repository: true, Key: true}.Configured())
+	client.AssertExpectations(t)
+}
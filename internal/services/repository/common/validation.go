@@ -0,0 +1,72 @@
+// internal/services/repository/common/validation.go
+package common
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// unsafeShellChars lists characters that must never appear in values
+// interpolated into remote shell commands.
+const unsafeShellChars = "'\"`$\\;|&<>(){} \t\n\r"
+
+// ValidateURL validates that a URL is well-formed, uses HTTPS and is safe
+// to interpolate into remote shell commands.
+//
+//	err := ValidateURL("https://repo.superviz.io/apt")
+//	// Returns nil (valid HTTPS URL)
+//
+//	err = ValidateURL("http://unsafe.com/")
+//	// Returns error (not HTTPS)
+//
+// Parameters:
+//   - rawURL: string URL to validate
+//
+// Returns:
+//   - err: error if URL is invalid, insecure or contains shell metacharacters
+func ValidateURL(rawURL string) error {
+	if strings.TrimSpace(rawURL) == "" {
+		return fmt.Errorf("URL cannot be empty")
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL format: %w", err)
+	}
+
+	if parsedURL.Scheme != "https" {
+		return fmt.Errorf("URL must use HTTPS scheme, got: %s", parsedURL.Scheme)
+	}
+
+	if parsedURL.Host == "" {
+		return fmt.Errorf("URL must have a valid host")
+	}
+
+	if strings.ContainsAny(rawURL, unsafeShellChars) {
+		return fmt.Errorf("URL contains invalid characters: %s", rawURL)
+	}
+
+	return nil
+}
+
+// ValidateToken validates that a value is a non-empty shell-safe token.
+//
+//	err := ValidateToken("components", "main")
+//	// Returns nil
+//
+// Parameters:
+//   - field: string name of the validated field used in error messages
+//   - value: string value to validate
+//
+// Returns:
+//   - err: error if value is empty or contains shell metacharacters
+func ValidateToken(field, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("%s cannot be empty", field)
+	}
+	if strings.ContainsAny(value, unsafeShellChars) {
+		return fmt.Errorf("%s contains invalid characters: %s", field, value)
+	}
+	return nil
+}
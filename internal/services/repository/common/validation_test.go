@@ -0,0 +1,55 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		errMsg string
+	}{
+		{name: "valid HTTPS URL", url: "https://repo.superviz.io/apt"},
+		{name: "empty URL", url: " ", errMsg: "URL cannot be empty"},
+		{name: "HTTP URL", url: "http://repo.superviz.io/apt", errMsg: "URL must use HTTPS scheme"},
+		{name: "missing host", url: "https://", errMsg: "URL must have a valid host"},
+		{name: "single quote", url: "https://repo.superviz.io/a'b", errMsg: "invalid characters"},
+		{name: "command substitution", url: "https://repo.superviz.io/$(id)", errMsg: "invalid characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateURL(tt.url)
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestValidateToken(t *testing.T) {
+	assert.NoError(t, ValidateToken("component", "main"))
+	assert.ErrorContains(t, ValidateToken("component", ""), "component cannot be empty")
+	assert.ErrorContains(t, ValidateToken("component", "main contrib"), "invalid characters")
+	assert.ErrorContains(t, ValidateToken("component", "a;b"), "invalid characters")
+}
+
+func TestBaseHandler_Probe(t *testing.T) {
+	client := &mockSSHClient{}
+	client.On("Execute", mock.Anything, "true").Return(nil)
+	client.On("Execute", mock.Anything, "false").Return(errors.New("exit status 1"))
+
+	handler := NewBaseHandler(client)
+
+	assert.True(t, handler.Probe(context.Background(), "true"))
+	assert.False(t, handler.Probe(context.Background(), "false"))
+	client.AssertExpectations(t)
+}
@@ -3,12 +3,153 @@ package debian
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/kodflow/superviz.io/internal/infrastructure/transports/ssh"
 	"github.com/kodflow/superviz.io/internal/services/repository/common"
 )
 
+// Remote paths used by the APT repository configuration.
+const (
+	// sourcesPath is the deb822 source file written on modern releases
+	sourcesPath = "/etc/apt/sources.list.d/superviz.sources"
+	// legacyListPath is the one-line source file written on old releases
+	legacyListPath = "/etc/apt/sources.list.d/superviz.list"
+	// keyringDir holds ASCII-armored keys referenced by legacy sources
+	keyringDir = "/etc/apt/keyrings"
+	// legacyKeyPath is the armored key referenced by the legacy source file
+	legacyKeyPath = keyringDir + "/superviz.asc"
+	// preferencesPath is the APT pinning file for the repository
+	preferencesPath = "/etc/apt/preferences.d/superviz"
+	// tmpKeyPath is the temporary download location of the repository key
+	tmpKeyPath = "/tmp/superviz.asc"
+)
+
+// deb822Probe succeeds when the installed APT understands deb822 sources
+// with an inline ASCII-armored Signed-By field (APT >= 2.3.10, shipped with
+// Debian 12 and Ubuntu 22.04 onwards).
+const deb822Probe = `dpkg --compare-versions "$(dpkg-query -W -f='${Version}' apt)" ge 2.3.10`
+
+// codenameExpr expands to the release codename on the target system
+// without requiring lsb-release.
+const codenameExpr = `$(. /etc/os-release && echo "${VERSION_CODENAME:-$UBUNTU_CODENAME}")`
+
+// RepoConfig holds configuration for APT repository setup.
+//
+// RepoConfig contains the repository location, signing key and pinning
+// settings used to generate the APT source and preferences files.
+//
+// Example:
+//
+//	config := &RepoConfig{
+//		URI:         "https://repo.superviz.io/apt",
+//		GPGKeyURL:   "https://repo.superviz.io/gpg",
+//		Component:   "main",
+//		PackageName: "superviz",
+//		PinPriority: 600,
+//	}
+type RepoConfig struct {
+	// URI is the base URL of the APT repository
+	URI string
+	// GPGKeyURL is the URL of the ASCII-armored repository signing key
+	GPGKeyURL string
+	// Component is the repository component (e.g. main)
+	Component string
+	// PackageName is the package preferred from this repository
+	PackageName string
+	// PinPriority is the APT pin priority applied to PackageName
+	PinPriority int
+}
+
+// RepoProvider defines the interface for providing APT repository configuration.
+//
+// RepoProvider abstracts the source of repository configuration, enabling
+// mirrors and testability of repository setup operations.
+type RepoProvider interface {
+	// GetRepoConfig returns the repository configuration.
+	//
+	// Returns:
+	//   - config: *RepoConfig containing repository settings
+	GetRepoConfig() *RepoConfig
+}
+
+// defaultRepoProvider implements RepoProvider with production values.
+type defaultRepoProvider struct{}
+
+// GetRepoConfig returns the default repository configuration.
+//
+// Returns:
+//   - config: *RepoConfig with default production settings
+func (p *defaultRepoProvider) GetRepoConfig() *RepoConfig {
+	return &RepoConfig{
+		URI:         "https://repo.superviz.io/apt",
+		GPGKeyURL:   "https://repo.superviz.io/gpg",
+		Component:   "main",
+		PackageName: "superviz",
+		PinPriority: 600,
+	}
+}
+
+// customRepoProvider implements RepoProvider with an injected configuration.
+type customRepoProvider struct {
+	config *RepoConfig
+}
+
+// GetRepoConfig returns the injected repository configuration.
+//
+// Returns:
+//   - config: *RepoConfig custom repository configuration
+func (p *customRepoProvider) GetRepoConfig() *RepoConfig {
+	return p.config
+}
+
+// NewDefaultRepoProvider creates a provider with the production configuration.
+//
+// Returns:
+//   - provider: RepoProvider with default configuration
+func NewDefaultRepoProvider() RepoProvider {
+	return &defaultRepoProvider{}
+}
+
+// NewCustomRepoProvider creates a provider returning the given configuration.
+//
+//	provider := NewCustomRepoProvider(&RepoConfig{URI: "https://mirror.example.com/apt", ...})
+//	handler := NewHandlerWithProvider(client, provider)
+//
+// Parameters:
+//   - config: *RepoConfig repository configuration to return
+//
+// Returns:
+//   - provider: RepoProvider with custom configuration
+func NewCustomRepoProvider(config *RepoConfig) RepoProvider {
+	return &customRepoProvider{config: config}
+}
+
+// NewMirrorRepoProvider creates a provider for a mirror of the superviz.io repository.
+//
+// The default repository layout (apt/ tree and gpg key) is kept and only the
+// repository root is replaced; pinning then targets the mirror host.
+//
+//	provider := NewMirrorRepoProvider("https://mirror.example.com/superviz")
+//	handler := NewHandlerWithProvider(client, provider)
+//
+// Parameters:
+//   - baseURL: string repository root serving the apt/ tree
+//
+// Returns:
+//   - provider: RepoProvider with mirrored configuration
+func NewMirrorRepoProvider(baseURL string) RepoProvider {
+	config := (&defaultRepoProvider{}).GetRepoConfig()
+	baseURL = strings.TrimRight(baseURL, "/")
+	config.URI = baseURL + "/apt"
+	config.GPGKeyURL = baseURL + "/gpg"
+	return &customRepoProvider{config: config}
+}
+
 // Handler handles Debian/Ubuntu repository setup.
 //
 //	handler := NewHandler(client)
@@ -19,6 +160,8 @@ import (
 type Handler struct {
 	// Base provides common repository setup functionality
 	Base *common.BaseHandler
+	// provider supplies repository configuration
+	provider RepoProvider
 }
 
 // NewHandler creates a new Debian repository handler.
@@ -32,8 +175,163 @@ type Handler struct {
 // Returns:
 //   - handler: *Handler configured Debian repository handler
 func NewHandler(client ssh.Client) *Handler {
+	return NewHandlerWithProvider(client, &defaultRepoProvider{})
+}
+
+// NewHandlerWithProvider creates a new Debian repository handler with custom provider.
+//
+//	provider := NewCustomRepoProvider(mirrorConfig)
+//	handler := NewHandlerWithProvider(client, provider)
+//
+// Parameters:
+//   - client: ssh.Client SSH client for executing commands
+//   - provider: RepoProvider repository configuration provider
+//
+// Returns:
+//   - handler: *Handler configured Debian repository handler
+func NewHandlerWithProvider(client ssh.Client, provider RepoProvider) *Handler {
 	return &Handler{
-		Base: common.NewBaseHandler(client),
+		Base:     common.NewBaseHandler(client),
+		provider: provider,
+	}
+}
+
+// validateRepoConfig validates repository configuration for security and correctness.
+//
+// Parameters:
+//   - config: *RepoConfig repository configuration to validate
+//
+// Returns:
+//   - err: error if configuration is invalid
+func validateRepoConfig(config *RepoConfig) error {
+	if config == nil {
+		return fmt.Errorf("repository configuration cannot be nil")
+	}
+	if err := common.ValidateURL(config.URI); err != nil {
+		return fmt.Errorf("invalid repository URI: %w", err)
+	}
+	if err := common.ValidateURL(config.GPGKeyURL); err != nil {
+		return fmt.Errorf("invalid GPG key URL: %w", err)
+	}
+	if err := common.ValidateToken("component", config.Component); err != nil {
+		return err
+	}
+	if err := common.ValidateToken("package name", config.PackageName); err != nil {
+		return err
+	}
+	if config.PinPriority < 1 || config.PinPriority > 1000 {
+		return fmt.Errorf("pin priority must be between 1 and 1000, got: %d", config.PinPriority)
+	}
+	return nil
+}
+
+// writeFileCommand builds a command writing the given lines to a file.
+//
+// The command is wrapped in sh -c so that the redirection itself runs with
+// elevated privileges when the sudo prefix is applied.
+//
+// Parameters:
+//   - path: string destination file on the target system
+//   - lines: ...string file lines, validated beforehand to be quote-free
+//
+// Returns:
+//   - cmd: string shell command writing the file
+func writeFileCommand(path string, lines ...string) string {
+	var b strings.Builder
+	b.WriteString(`sh -c '{ printf "%s\n"`)
+	for _, line := range lines {
+		b.WriteString(` "`)
+		b.WriteString(line)
+		b.WriteByte('"')
+	}
+	b.WriteString("; } > ")
+	b.WriteString(path)
+	b.WriteByte('\'')
+	return b.String()
+}
+
+// fetchKeyCommand downloads the repository key without extra packages.
+//
+// Parameters:
+//   - keyURL: string validated key URL
+//
+// Returns:
+//   - cmd: string shell command downloading the key to tmpKeyPath
+func fetchKeyCommand(keyURL string) string {
+	return fmt.Sprintf("curl -fsSL %[1]s -o %[2]s || wget -qO %[2]s %[1]s", keyURL, tmpKeyPath)
+}
+
+// preferencesCommand pins the repository so it never overrides
+// distribution packages other than the superviz package itself.
+//
+// Parameters:
+//   - config: *RepoConfig validated repository configuration
+//
+// Returns:
+//   - cmd: string shell command writing the preferences file
+func preferencesCommand(config *RepoConfig) (string, error) {
+	parsed, err := url.Parse(config.URI)
+	if err != nil {
+		return "", fmt.Errorf("invalid repository URI: %w", err)
+	}
+	origin := parsed.Hostname()
+
+	return writeFileCommand(preferencesPath,
+		"Package: *",
+		"Pin: origin "+origin,
+		"Pin-Priority: 100",
+		"",
+		"Package: "+config.PackageName,
+		"Pin: origin "+origin,
+		"Pin-Priority: "+strconv.Itoa(config.PinPriority),
+	), nil
+}
+
+// deb822Commands returns the commands configuring a deb822 source with an
+// inline Signed-By key, removing any legacy one-line source.
+//
+// Parameters:
+//   - config: *RepoConfig validated repository configuration
+//   - prefs: string command writing the preferences file
+//
+// Returns:
+//   - commands: []string ordered setup commands
+func deb822Commands(config *RepoConfig, prefs string) []string {
+	// Blank lines of the armored key become " ." as required by deb822
+	sources := fmt.Sprintf(`sh -c '{ printf "%%s\n" "Types: deb" "URIs: %s" "Suites: %s" "Components: %s" "Signed-By:"; `+
+		`sed -e "s/^$/./" -e "s/^/ /" %s; } > %s'`,
+		config.URI, codenameExpr, config.Component, tmpKeyPath, sourcesPath)
+
+	return []string{
+		fetchKeyCommand(config.GPGKeyURL),
+		sources,
+		"rm -f " + legacyListPath,
+		prefs,
+		"rm -f " + tmpKeyPath,
+		"apt update",
+	}
+}
+
+// legacyCommands returns the commands configuring a one-line source that
+// references an ASCII-armored key file, for releases without deb822 support.
+//
+// Parameters:
+//   - config: *RepoConfig validated repository configuration
+//   - prefs: string command writing the preferences file
+//
+// Returns:
+//   - commands: []string ordered setup commands
+func legacyCommands(config *RepoConfig, prefs string) []string {
+	line := fmt.Sprintf("deb [signed-by=%s] %s %s %s", legacyKeyPath, config.URI, codenameExpr, config.Component)
+
+	return []string{
+		fetchKeyCommand(config.GPGKeyURL),
+		"install -d -m 0755 " + keyringDir,
+		"cp " + tmpKeyPath + " " + legacyKeyPath,
+		writeFileCommand(legacyListPath, line),
+		prefs,
+		"rm -f " + tmpKeyPath,
+		"apt update",
 	}
 }
 
@@ -42,8 +340,11 @@ func NewHandler(client ssh.Client) *Handler {
 //	handler := NewHandler(client)
 //	err := handler.Setup(ctx, os.Stdout)
 //
-// Setup configures the superviz.io APT repository on Debian/Ubuntu systems
-// by installing dependencies, adding GPG keys, and configuring the repository.
+// Setup configures the superviz.io APT repository on Debian/Ubuntu systems.
+// Releases whose APT supports it get a deb822 .sources file with the key
+// inlined in Signed-By; older releases fall back to a legacy .list file.
+// Both variants pin the repository through /etc/apt/preferences.d and
+// install no additional packages on the target.
 //
 // Parameters:
 //   - ctx: context.Context for timeout and cancellation
@@ -52,23 +353,36 @@ func NewHandler(client ssh.Client) *Handler {
 // Returns:
 //   - err: error if repository setup fails
 func (h *Handler) Setup(ctx context.Context, writer io.Writer) error {
-	commands := []string{
-		// Install required packages
-		"apt update",
-		"apt install -y curl gnupg lsb-release",
-
-		// Add GPG key (download to temp, then move with appropriate permissions)
-		"curl -fsSL https://repo.superviz.io/gpg -o /tmp/superviz.gpg",
-		"gpg --dearmor < /tmp/superviz.gpg > /tmp/superviz.gpg.dearmored",
-		"cp /tmp/superviz.gpg.dearmored /usr/share/keyrings/superviz.gpg",
-		"rm /tmp/superviz.gpg /tmp/superviz.gpg.dearmored",
+	config := h.provider.GetRepoConfig()
+	if err := validateRepoConfig(config); err != nil {
+		return fmt.Errorf("invalid repository configuration: %w", err)
+	}
 
-		// Add repository
-		`echo "deb [signed-by=/usr/share/keyrings/superviz.gpg] https://repo.superviz.io/apt $(lsb_release -cs) main" > /etc/apt/sources.list.d/superviz.list`,
+	prefs, err := preferencesCommand(config)
+	if err != nil {
+		return err
+	}
 
-		// Update package list
-		"apt update",
+	if h.Base.Probe(ctx, deb822Probe) {
+		return h.Base.ExecuteSetup(ctx, writer, "Setting up APT repository (deb822)...", deb822Commands(config, prefs))
 	}
 
-	return h.Base.ExecuteSetup(ctx, writer, "Setting up APT repository...", commands)
+	return h.Base.ExecuteSetup(ctx, writer, "Setting up APT repository (legacy)...", legacyCommands(config, prefs))
+}
+
+// State reports whether the APT source and its signing key are configured.
+//
+// Both the deb822 source with its inline key and the legacy one-line source
+// with a keyring file are recognized.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//
+// Returns:
+//   - state: common.RepoState probe results
+func (h *Handler) State(ctx context.Context) common.RepoState {
+	return h.Base.ProbeState(ctx,
+		fmt.Sprintf("test -f %s || test -f %s", sourcesPath, legacyListPath),
+		fmt.Sprintf("grep -q 'BEGIN PGP PUBLIC KEY BLOCK' %s || test -s %s", sourcesPath, legacyKeyPath),
+	)
 }
@@ -41,25 +41,24 @@ func TestNewHandler(t *testing.T) {
 	assert.NotNil(t, handler.Base)
 }
 
+// deb822Expected lists the commands issued on a release supporting deb822 sources.
+var deb822Expected = []string{
+	"curl -fsSL https://repo.superviz.io/gpg -o /tmp/superviz.asc || wget -qO /tmp/superviz.asc https://repo.superviz.io/gpg",
+	`sh -c '{ printf "%s\n" "Types: deb" "URIs: https://repo.superviz.io/apt" "Suites: $(. /etc/os-release && echo "${VERSION_CODENAME:-$UBUNTU_CODENAME}")" "Components: main" "Signed-By:"; sed -e "s/^$/./" -e "s/^/ /" /tmp/superviz.asc; } > /etc/apt/sources.list.d/superviz.sources'`,
+	"rm -f /etc/apt/sources.list.d/superviz.list",
+	`sh -c '{ printf "%s\n" "Package: *" "Pin: origin repo.superviz.io" "Pin-Priority: 100" "" "Package: superviz" "Pin: origin repo.superviz.io" "Pin-Priority: 600"; } > /etc/apt/preferences.d/superviz'`,
+	"rm -f /tmp/superviz.asc",
+	"apt update",
+}
+
 func TestHandler_Setup_Success_NoSudoNeeded(t *testing.T) {
 	client := &MockSSHClient{}
 
+	client.On("Execute", mock.Anything, deb822Probe).Return(nil)
 	// Mock system directory write test - first one succeeds (no sudo needed)
 	client.On("Execute", mock.Anything, "test -w /etc/apt/sources.list.d/").Return(nil)
 
-	// Mock repository setup commands without sudo
-	expectedCommands := []string{
-		"apt update",
-		"apt install -y curl gnupg lsb-release",
-		"curl -fsSL https://repo.superviz.io/gpg -o /tmp/superviz.gpg",
-		"gpg --dearmor < /tmp/superviz.gpg > /tmp/superviz.gpg.dearmored",
-		"cp /tmp/superviz.gpg.dearmored /usr/share/keyrings/superviz.gpg",
-		"rm /tmp/superviz.gpg /tmp/superviz.gpg.dearmored",
-		`echo "deb [signed-by=/usr/share/keyrings/superviz.gpg] https://repo.superviz.io/apt $(lsb_release -cs) main" > /etc/apt/sources.list.d/superviz.list`,
-		"apt update",
-	}
-
-	for _, cmd := range expectedCommands {
+	for _, cmd := range deb822Expected {
 		client.On("Execute", mock.Anything, cmd).Return(nil)
 	}
 
@@ -69,14 +68,17 @@ func TestHandler_Setup_Success_NoSudoNeeded(t *testing.T) {
 	err := handler.Setup(context.Background(), &output)
 
 	assert.NoError(t, err)
-	assert.Contains(t, output.String(), "Setting up APT repository...")
+	assert.Contains(t, output.String(), "Setting up APT repository (deb822)...")
 	assert.NotContains(t, output.String(), "Using sudo for system operations...")
+	assert.NotContains(t, output.String(), "gnupg")
 	client.AssertExpectations(t)
 }
 
 func TestHandler_Setup_Success_WithSudo(t *testing.T) {
 	client := &MockSSHClient{}
 
+	client.On("Execute", mock.Anything, deb822Probe).Return(nil)
+
 	// Mock system directory write tests - all fail (need sudo)
 	client.On("Execute", mock.Anything, "test -w /etc/apt/sources.list.d/").Return(errors.New("not writable"))
 	client.On("Execute", mock.Anything, "test -w /etc/apk/repositories").Return(errors.New("not writable"))
@@ -86,15 +88,13 @@ func TestHandler_Setup_Success_WithSudo(t *testing.T) {
 	// Mock sudo check - sudo available
 	client.On("Execute", mock.Anything, "command -v sudo >/dev/null 2>&1").Return(nil)
 
-	// Mock repository setup commands with sudo prefix
+	// Writes to /etc run under sudo, downloads and temp cleanup do not
 	expectedCommands := []string{
-		"sudo apt update",
-		"sudo apt install -y curl gnupg lsb-release",
-		"curl -fsSL https://repo.superviz.io/gpg -o /tmp/superviz.gpg",
-		"gpg --dearmor < /tmp/superviz.gpg > /tmp/superviz.gpg.dearmored",
-		"sudo cp /tmp/superviz.gpg.dearmored /usr/share/keyrings/superviz.gpg",
-		"rm /tmp/superviz.gpg /tmp/superviz.gpg.dearmored",
-		`sudo echo "deb [signed-by=/usr/share/keyrings/superviz.gpg] https://repo.superviz.io/apt $(lsb_release -cs) main" > /etc/apt/sources.list.d/superviz.list`,
+		deb822Expected[0],
+		"sudo " + deb822Expected[1],
+		"sudo " + deb822Expected[2],
+		"sudo " + deb822Expected[3],
+		deb822Expected[4],
 		"sudo apt update",
 	}
 
@@ -108,13 +108,118 @@ func TestHandler_Setup_Success_WithSudo(t *testing.T) {
 	err := handler.Setup(context.Background(), &output)
 
 	assert.NoError(t, err)
-	assert.Contains(t, output.String(), "Setting up APT repository...")
+	assert.Contains(t, output.String(), "Setting up APT repository (deb822)...")
 	assert.Contains(t, output.String(), "Using sudo for system operations...")
 	client.AssertExpectations(t)
 }
 
+func TestHandler_Setup_Success_Legacy(t *testing.T) {
+	client := &MockSSHClient{}
+
+	client.On("Execute", mock.Anything, deb822Probe).Return(errors.New("apt too old"))
+	client.On("Execute", mock.Anything, "test -w /etc/apt/sources.list.d/").Return(nil)
+
+	expectedCommands := []string{
+		deb822Expected[0],
+		"install -d -m 0755 /etc/apt/keyrings",
+		"cp /tmp/superviz.asc /etc/apt/keyrings/superviz.asc",
+		`sh -c '{ printf "%s\n" "deb [signed-by=/etc/apt/keyrings/superviz.asc] https://repo.superviz.io/apt $(. /etc/os-release && echo "${VERSION_CODENAME:-$UBUNTU_CODENAME}") main"; } > /etc/apt/sources.list.d/superviz.list'`,
+		deb822Expected[3],
+		"rm -f /tmp/superviz.asc",
+		"apt update",
+	}
+
+	for _, cmd := range expectedCommands {
+		client.On("Execute", mock.Anything, cmd).Return(nil)
+	}
+
+	handler := NewHandler(client)
+	var output bytes.Buffer
+
+	err := handler.Setup(context.Background(), &output)
+
+	assert.NoError(t, err)
+	assert.Contains(t, output.String(), "Setting up APT repository (legacy)...")
+	assert.Contains(t, output.String(), "[3/7] cp /tmp/superviz.asc /etc/apt/keyrings/superviz.asc")
+	client.AssertExpectations(t)
+}
+
+func TestHandler_Setup_WithCustomProvider(t *testing.T) {
+	client := &MockSSHClient{}
+	client.On("Execute", mock.Anything, deb822Probe).Return(nil)
+	client.On("Execute", mock.Anything, "test -w /etc/apt/sources.list.d/").Return(nil)
+	client.On("Execute", mock.Anything, mock.AnythingOfType("string")).Return(nil)
+
+	provider := NewCustomRepoProvider(&RepoConfig{
+		URI:         "https://mirror.example.com/superviz/apt",
+		GPGKeyURL:   "https://mirror.example.com/superviz/gpg",
+		Component:   "main",
+		PackageName: "superviz",
+		PinPriority: 990,
+	})
+	handler := NewHandlerWithProvider(client, provider)
+	var output bytes.Buffer
+
+	err := handler.Setup(context.Background(), &output)
+
+	assert.NoError(t, err)
+	assert.Contains(t, output.String(), "URIs: https://mirror.example.com/superviz/apt")
+	assert.Contains(t, output.String(), "Pin: origin mirror.example.com")
+	assert.Contains(t, output.String(), "Pin-Priority: 990")
+}
+
+func TestHandler_Setup_WithInvalidProvider(t *testing.T) {
+	client := &MockSSHClient{}
+
+	provider := NewCustomRepoProvider(&RepoConfig{
+		URI:         "http://insecure.example.com/apt",
+		GPGKeyURL:   "https://repo.superviz.io/gpg",
+		Component:   "main",
+		PackageName: "superviz",
+		PinPriority: 600,
+	})
+	handler := NewHandlerWithProvider(client, provider)
+
+	err := handler.Setup(context.Background(), &bytes.Buffer{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid repository configuration")
+	client.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything)
+}
+
+func TestValidateRepoConfig(t *testing.T) {
+	valid := func() *RepoConfig { return NewDefaultRepoProvider().GetRepoConfig() }
+
+	testCases := []struct {
+		name   string
+		mutate func(*RepoConfig) *RepoConfig
+		errMsg string
+	}{
+		{name: "valid config", mutate: func(c *RepoConfig) *RepoConfig { return c }},
+		{name: "nil config", mutate: func(*RepoConfig) *RepoConfig { return nil }, errMsg: "cannot be nil"},
+		{name: "insecure URI", mutate: func(c *RepoConfig) *RepoConfig { c.URI = "http://x.io/apt"; return c }, errMsg: "invalid repository URI"},
+		{name: "quoted key URL", mutate: func(c *RepoConfig) *RepoConfig { c.GPGKeyURL = "https://x.io/'k"; return c }, errMsg: "invalid GPG key URL"},
+		{name: "unsafe component", mutate: func(c *RepoConfig) *RepoConfig { c.Component = "main;rm"; return c }, errMsg: "component"},
+		{name: "empty package", mutate: func(c *RepoConfig) *RepoConfig { c.PackageName = ""; return c }, errMsg: "package name"},
+		{name: "priority out of range", mutate: func(c *RepoConfig) *RepoConfig { c.PinPriority = 0; return c }, errMsg: "pin priority"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateRepoConfig(tc.mutate(valid()))
+			if tc.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errMsg)
+		})
+	}
+}
+
 func TestHandler_Setup_Success_SudoNotAvailable(t *testing.T) {
 	client := &MockSSHClient{}
+	client.On("Execute", mock.Anything, deb822Probe).Return(nil)
 
 	// Mock system directory write tests - all fail (need sudo)
 	client.On("Execute", mock.Anything, "test -w /etc/apt/sources.list.d/").Return(errors.New("not writable"))
@@ -133,7 +238,7 @@ func TestHandler_Setup_Success_SudoNotAvailable(t *testing.T) {
 	// This should fail because we need sudo but it's not available
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "root privileges required but sudo is not available")
-	assert.Contains(t, output.String(), "Setting up APT repository...")
+	assert.Contains(t, output.String(), "Setting up APT repository")
 }
 
 func TestHandler_Setup_SudoDetectionError(t *testing.T) {
@@ -149,11 +254,12 @@ func TestHandler_Setup_SudoDetectionError(t *testing.T) {
 
 	// Should get connection error during the write test or sudo check
 	assert.Error(t, err)
-	assert.Contains(t, output.String(), "Setting up APT repository...")
+	assert.Contains(t, output.String(), "Setting up APT repository")
 }
 
 func TestHandler_Setup_WriteError(t *testing.T) {
 	client := &MockSSHClient{}
+	client.On("Execute", mock.Anything, deb822Probe).Return(nil)
 	handler := NewHandler(client)
 
 	// Use a writer that will fail
@@ -167,12 +273,13 @@ func TestHandler_Setup_WriteError(t *testing.T) {
 
 func TestHandler_Setup_CommandExecutionError(t *testing.T) {
 	client := &MockSSHClient{}
+	client.On("Execute", mock.Anything, deb822Probe).Return(nil)
 
 	// Mock system directory write test - first one succeeds (no sudo needed)
 	client.On("Execute", mock.Anything, "test -w /etc/apt/sources.list.d/").Return(nil)
 
 	// Mock first command to fail
-	client.On("Execute", mock.Anything, "apt update").Return(errors.New("command failed"))
+	client.On("Execute", mock.Anything, deb822Expected[0]).Return(errors.New("command failed"))
 
 	handler := NewHandler(client)
 	var output bytes.Buffer
@@ -186,6 +293,7 @@ func TestHandler_Setup_CommandExecutionError(t *testing.T) {
 
 func TestHandler_Setup_SudoWriteError(t *testing.T) {
 	client := &MockSSHClient{}
+	client.On("Execute", mock.Anything, deb822Probe).Return(nil)
 
 	// Mock system directory write tests - all fail (need sudo)
 	client.On("Execute", mock.Anything, "test -w /etc/apt/sources.list.d/").Return(errors.New("not writable"))
@@ -227,3 +335,31 @@ func (w *conditionalFailingWriter) Write(p []byte) (n int, err error) {
 	}
 	return len(p), nil
 }
+
+func TestNewMirrorRepoProvider(t *testing.T) {
+	config := NewMirrorRepoProvider("https://mirror.example.com/superviz/").GetRepoConfig()
+	defaults := NewDefaultRepoProvider().GetRepoConfig()
+
+	assert.Equal(t, "https://mirror.example.com/superviz/apt", config.URI)
+	assert.Equal(t, "https://mirror.example.com/superviz/gpg", config.GPGKeyURL)
+	assert.Equal(t, defaults.Component, config.Component)
+	assert.Equal(t, defaults.PackageName, config.PackageName)
+	assert.Equal(t, defaults.PinPriority, config.PinPriority)
+	assert.NoError(t, validateRepoConfig(config))
+
+	prefs, err := preferencesCommand(config)
+	assert.NoError(t, err)
+	assert.Contains(t, prefs, `"Pin: origin mirror.example.com"`)
+}
+
+func TestHandler_State(t *testing.T) {
+	client := &MockSSHClient{}
+	client.On("Execute", mock.Anything, "test -f /etc/apt/sources.list.d/superviz.sources || test -f /etc/apt/sources.list.d/superviz.list").Return(nil)
+	client.On("Execute", mock.Anything, "grep -q 'BEGIN PGP PUBLIC KEY BLOCK' /etc/apt/sources.list.d/superviz.sources || test -s /etc/apt/keyrings/superviz.asc").Return(errors.New("exit status 1"))
+
+	state := NewHandler(client).State(context.Background())
+
+	assert.True(t, state.Repository)
+	assert.False(t, state.Key)
+	client.AssertExpectations(t)
+}
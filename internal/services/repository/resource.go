@@ -0,0 +1,85 @@
+// internal/services/repository/resource.go - Repository setup as a reconciled resource
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Resource is the repository configuration of a host, checked and applied
+// like the other reconciled resources.
+type Resource struct {
+	// setup configures the repository
+	setup Setup
+	// distro is the distribution of the host
+	distro string
+	// writer receives the setup output
+	writer io.Writer
+}
+
+// NewResource creates the repository resource of a host.
+//
+// Parameters:
+//   - setup: Setup configuring the repository, also an Inspector to be checked
+//   - distro: string detected distribution
+//   - writer: io.Writer receiving the setup output, io.Discard when nil
+//
+// Returns:
+//   - resource: *Resource named repository/<distro>
+func NewResource(setup Setup, distro string, writer io.Writer) *Resource {
+	if writer == nil {
+		writer = io.Discard
+	}
+	return &Resource{setup: setup, distro: distro, writer: writer}
+}
+
+// Name identifies the resource.
+//
+// Returns:
+//   - name: string repository/<distro>
+func (r *Resource) Name() string {
+	return "repository/" + r.distro
+}
+
+// Check probes the package source and its signing key.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//
+// Returns:
+//   - reason: string naming what is missing, empty when the repository is configured
+//   - err: error if the setup cannot report its state or the distribution is not supported
+func (r *Resource) Check(ctx context.Context) (string, error) {
+	inspector, ok := r.setup.(Inspector)
+	if !ok {
+		return "", errors.New("repository setup cannot report its state")
+	}
+	state, err := inspector.State(ctx, r.distro)
+	if err != nil {
+		return "", err
+	}
+	var missing []string
+	if !state.Repository {
+		missing = append(missing, "package source")
+	}
+	if !state.Key {
+		missing = append(missing, "signing key")
+	}
+	if len(missing) == 0 {
+		return "", nil
+	}
+	return "missing " + strings.Join(missing, " and "), nil
+}
+
+// Apply configures the repository.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//
+// Returns:
+//   - err: error if the setup failed
+func (r *Resource) Apply(ctx context.Context) error {
+	return r.setup.Setup(ctx, r.distro, r.writer)
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services/reconcile"
+	"github.com/kodflow/superviz.io/internal/services/repository/common"
+)
+
+// Compile-time check that the repository plugs into the reconciler
+var _ reconcile.Resource = (*Resource)(nil)
+
+// setupFunc is a Setup without state
+type setupFunc func(ctx context.Context, distro string, writer io.Writer) error
+
+func (f setupFunc) Setup(ctx context.Context, distro string, writer io.Writer) error {
+	return f(ctx, distro, writer)
+}
+
+func TestResource_Check(t *testing.T) {
+	client := &mockSSHClient{}
+	provider := &mockInstallProvider{}
+	provider.On("GetGPGKeyID").Return("test-gpg-key-id").Maybe()
+	client.On("Execute", mock.Anything, mock.MatchedBy(func(command string) bool {
+		return strings.HasPrefix(command, "test -f")
+	})).Return(nil)
+	client.On("Execute", mock.Anything, mock.AnythingOfType("string")).Return(errors.New("exit status 1"))
+
+	resource := NewResource(NewSetup(client, provider), "debian", nil)
+	assert.Equal(t, "repository/debian", resource.Name())
+	reason, err := resource.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "missing signing key", reason)
+
+	_, err = NewResource(NewSetup(client, provider), "unknown", nil).Check(context.Background())
+	assert.ErrorContains(t, err, "unsupported distribution: unknown")
+
+	_, err = NewResource(setupFunc(func(context.Context, string, io.Writer) error { return nil }), "debian", nil).Check(context.Background())
+	assert.ErrorContains(t, err, "cannot report its state")
+}
+
+func TestResource_Reconcile(t *testing.T) {
+	client := &mockSSHClient{}
+	provider := &mockInstallProvider{}
+	provider.On("GetGPGKeyID").Return("test-gpg-key-id").Maybe()
+	client.On("Execute", mock.Anything, mock.AnythingOfType("string")).Return(nil)
+
+	var out bytes.Buffer
+	configured := false
+	setup := &stateSetup{setup: NewSetup(client, provider), configured: &configured}
+	reconciler := reconcile.New(&providers.ReconcileConfig{MaxActions: 1, Window: providers.DefaultReconcileWindow},
+		func() []reconcile.Resource { return []reconcile.Resource{NewResource(setup, "alpine", &out)} }, nil)
+
+	reconciler.Reconcile(context.Background())
+	assert.True(t, configured, "the setup runs once the repository is found missing")
+	reconciler.Reconcile(context.Background())
+
+	var kinds []reconcile.EventKind
+	for _, event := range reconciler.Events("repository/alpine", 0) {
+		kinds = append(kinds, event.Kind)
+	}
+	assert.Equal(t, []reconcile.EventKind{reconcile.EventDrift, reconcile.EventAction, reconcile.EventConverged}, kinds)
+}
+
+// stateSetup reports the repository missing until Setup ran
+type stateSetup struct {
+	setup      Setup
+	configured *bool
+}
+
+func (s *stateSetup) Setup(ctx context.Context, distro string, writer io.Writer) error {
+	*s.configured = true
+	return s.setup.Setup(ctx, distro, writer)
+}
+
+func (s *stateSetup) State(context.Context, string) (common.RepoState, error) {
+	return common.RepoState{Repository: *s.configured, Key: *s.configured}, nil
+}
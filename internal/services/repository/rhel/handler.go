@@ -205,6 +205,29 @@ func NewCustomRepoProvider(name, baseURL, gpgKeyURL string, enabled, gpgCheck bo
 	}
 }
 
+// NewMirrorRepoProvider creates a provider for a mirror of the superviz.io repository.
+//
+// NewMirrorRepoProvider keeps the default repository layout (rpm/ tree and
+// its signing key) and only replaces the repository root.
+//
+// Example:
+//
+//	provider := NewMirrorRepoProvider("https://mirror.example.com/superviz")
+//	handler := NewHandlerWithProvider(client, provider)
+//
+// Parameters:
+//   - baseURL: string repository root serving the rpm/ tree
+//
+// Returns:
+//   - provider: RepoProvider with mirrored configuration
+func NewMirrorRepoProvider(baseURL string) RepoProvider {
+	config := (&defaultRepoProvider{}).GetRepoConfig()
+	baseURL = strings.TrimRight(baseURL, "/")
+	config.BaseURL = baseURL + "/rpm/"
+	config.GPGKeyURL = baseURL + "/rpm/RPM-GPG-KEY-superviz"
+	return &customRepoProvider{config: config}
+}
+
 // customRepoProvider implements RepoProvider with custom configuration.
 //
 // customRepoProvider allows injection of non-standard repository
@@ -392,3 +415,17 @@ func (h *Handler) Setup(ctx context.Context, writer io.Writer) error {
 
 	return h.Base.ExecuteSetup(ctx, writer, "Setting up YUM/DNF repository...", commands)
 }
+
+// State reports whether the YUM/DNF repository and its signing key are configured.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//
+// Returns:
+//   - state: common.RepoState probe results
+func (h *Handler) State(ctx context.Context) common.RepoState {
+	return h.Base.ProbeState(ctx,
+		"test -f /etc/yum.repos.d/superviz.repo",
+		"rpm -q gpg-pubkey --qf '%{SUMMARY}\\n' | grep -qi superviz",
+	)
+}
@@ -255,6 +255,11 @@ func TestValidateURL(t *testing.T) {
 			url:       "https://repo.superviz.io/rpm/",
 			expectErr: false,
 		},
+		{
+			name:      "yum variables",
+			url:       "https://mirror.example.com/superviz/$releasever/$basearch/",
+			expectErr: false,
+		},
 		{
 			name:      "empty URL",
 			url:       "",
@@ -548,3 +553,27 @@ func TestHandler_Setup_WithInvalidProvider(t *testing.T) {
 	// No SSH commands should be executed due to validation failure
 	client.AssertNotCalled(t, "Execute")
 }
+
+func TestNewMirrorRepoProvider(t *testing.T) {
+	config := NewMirrorRepoProvider("https://mirror.example.com/superviz/").GetRepoConfig()
+	defaults := NewDefaultRepoProvider().GetRepoConfig()
+
+	assert.Equal(t, "https://mirror.example.com/superviz/rpm/", config.BaseURL)
+	assert.Equal(t, "https://mirror.example.com/superviz/rpm/RPM-GPG-KEY-superviz", config.GPGKeyURL)
+	assert.Equal(t, defaults.Name, config.Name)
+	assert.True(t, config.Enabled)
+	assert.True(t, config.GPGCheck)
+	assert.NoError(t, validateRepoConfig(config))
+}
+
+func TestHandler_State(t *testing.T) {
+	client := &MockSSHClient{}
+	client.On("Execute", mock.Anything, "test -f /etc/yum.repos.d/superviz.repo").Return(nil)
+	client.On("Execute", mock.Anything, `rpm -q gpg-pubkey --qf '%{SUMMARY}\n' | grep -qi superviz`).Return(nil)
+
+	state := NewHandler(client).State(context.Background())
+
+	assert.True(t, state.Repository)
+	assert.True(t, state.Key)
+	client.AssertExpectations(t)
+}
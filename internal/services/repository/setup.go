@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing"
 	"github.com/kodflow/superviz.io/internal/infrastructure/transports/ssh"
 	"github.com/kodflow/superviz.io/internal/providers"
 	"github.com/kodflow/superviz.io/internal/services/repository/alpine"
 	"github.com/kodflow/superviz.io/internal/services/repository/arch"
+	"github.com/kodflow/superviz.io/internal/services/repository/common"
 	"github.com/kodflow/superviz.io/internal/services/repository/debian"
 	"github.com/kodflow/superviz.io/internal/services/repository/rhel"
 )
@@ -19,10 +21,24 @@ type Setup interface {
 	Setup(ctx context.Context, distro string, writer io.Writer) error
 }
 
+// MirrorSetup is implemented by setups able to target a repository mirror.
+type MirrorSetup interface {
+	// WithMirror returns a setup configuring baseURL instead of the default repository.
+	//
+	// Parameters:
+	//   - baseURL: string mirror root with the same layout as repo.superviz.io
+	//
+	// Returns:
+	//   - setup: Setup targeting the mirror
+	WithMirror(baseURL string) Setup
+}
+
 // setup implements repository setup for different distributions.
 type setup struct {
 	client   ssh.Client
 	provider providers.InstallProvider
+	// mirror replaces the default repository root when set
+	mirror string
 }
 
 // NewSetup creates a new repository setup instance.
@@ -33,22 +49,83 @@ func NewSetup(client ssh.Client, provider providers.InstallProvider) Setup {
 	}
 }
 
+// WithMirror returns a copy of the setup targeting a repository mirror.
+func (s *setup) WithMirror(baseURL string) Setup {
+	return &setup{
+		client:   s.client,
+		provider: providers.NewMirrorInstallProvider(s.provider, baseURL),
+		mirror:   baseURL,
+	}
+}
+
+// Inspector is implemented by setups able to report the repository state.
+type Inspector interface {
+	// State reports whether the repository and key are configured.
+	//
+	// Parameters:
+	//   - ctx: context.Context for timeout and cancellation
+	//   - distro: string detected distribution
+	//
+	// Returns:
+	//   - state: common.RepoState probe results
+	//   - err: error if the distribution is not supported
+	State(ctx context.Context, distro string) (common.RepoState, error)
+}
+
+// distroHandler is the behavior shared by the per-distribution handlers.
+type distroHandler interface {
+	Setup(ctx context.Context, writer io.Writer) error
+	State(ctx context.Context) common.RepoState
+}
+
 // Setup sets up the repository for the specified distribution.
-func (s *setup) Setup(ctx context.Context, distro string, writer io.Writer) error {
+func (s *setup) Setup(ctx context.Context, distro string, writer io.Writer) (err error) {
+	ctx, span := tracing.Start(ctx, "repository.setup", tracing.Attr("os.distro", distro))
+	if s.mirror != "" {
+		span.SetAttributes(tracing.Attr("repository.mirror", s.mirror))
+	}
+	defer func() { span.End(err) }()
+
+	handler, err := s.handler(distro)
+	if err != nil {
+		return err
+	}
+	return handler.Setup(ctx, writer)
+}
+
+// State reports whether the repository is configured for the specified distribution.
+func (s *setup) State(ctx context.Context, distro string) (common.RepoState, error) {
+	handler, err := s.handler(distro)
+	if err != nil {
+		return common.RepoState{}, err
+	}
+	return handler.State(ctx), nil
+}
+
+// handler returns the repository handler for a distribution.
+func (s *setup) handler(distro string) (distroHandler, error) {
 	switch distro {
 	case "ubuntu", "debian":
-		handler := debian.NewHandler(s.client)
-		return handler.Setup(ctx, writer)
+		if s.mirror != "" {
+			return debian.NewHandlerWithProvider(s.client, debian.NewMirrorRepoProvider(s.mirror)), nil
+		}
+		return debian.NewHandler(s.client), nil
 	case "alpine":
-		handler := alpine.NewHandler(s.client)
-		return handler.Setup(ctx, writer)
+		if s.mirror != "" {
+			return alpine.NewHandlerWithBaseURL(s.client, s.mirror), nil
+		}
+		return alpine.NewHandler(s.client), nil
 	case "centos", "rhel", "fedora":
-		handler := rhel.NewHandler(s.client)
-		return handler.Setup(ctx, writer)
+		if s.mirror != "" {
+			return rhel.NewHandlerWithProvider(s.client, rhel.NewMirrorRepoProvider(s.mirror)), nil
+		}
+		return rhel.NewHandler(s.client), nil
 	case "arch":
-		handler := arch.NewHandler(s.client, s.provider)
-		return handler.Setup(ctx, writer)
+		if s.mirror != "" {
+			return arch.NewHandlerWithBaseURL(s.client, s.provider, s.mirror), nil
+		}
+		return arch.NewHandler(s.client, s.provider), nil
 	default:
-		return fmt.Errorf("unsupported distribution: %s", distro)
+		return nil, fmt.Errorf("unsupported distribution: %s", distro)
 	}
 }
@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing/tracingtest"
 	"github.com/kodflow/superviz.io/internal/infrastructure/transports/ssh"
 	"github.com/kodflow/superviz.io/internal/providers"
 )
@@ -222,6 +225,25 @@ func TestSetup_Setup_CommandError(t *testing.T) {
 	client.AssertExpectations(t)
 }
 
+func TestSetup_Setup_Traces(t *testing.T) {
+	recorder := tracingtest.Install(t)
+	client := &mockSSHClient{}
+	client.On("Execute", mock.Anything, mock.AnythingOfType("string")).Return(errors.New("command failed"))
+
+	err := NewSetup(client, &mockInstallProvider{}).(MirrorSetup).WithMirror("https://mirror.example.com").
+		Setup(context.Background(), "alpine", &bytes.Buffer{})
+	require.Error(t, err)
+
+	spans := recorder.Spans()
+	require.Equal(t, []string{"sudo.probe", "repository.setup"}, tracingtest.Names(spans))
+	assert.Equal(t, spans[1].SpanID, spans[0].ParentSpanID)
+	assert.Equal(t, []otlp.KeyValue{
+		otlp.Attribute("os.distro", "alpine"),
+		otlp.Attribute("repository.mirror", "https://mirror.example.com"),
+	}, spans[1].Attributes)
+	assert.Equal(t, otlp.StatusError, spans[1].Status.Code)
+}
+
 // Test the interface implementation
 func TestSetup_ImplementsInterface(t *testing.T) {
 	client := &mockSSHClient{}
@@ -232,3 +254,69 @@ func TestSetup_ImplementsInterface(t *testing.T) {
 	// Verify it implements the Setup interface
 	var _ = Setup(setup)
 }
+
+func TestSetup_WithMirror(t *testing.T) {
+	tests := []struct {
+		distro  string
+		command string
+	}{
+		{distro: "ubuntu", command: "https://mirror.example.com/superviz/apt"},
+		{distro: "alpine", command: "https://mirror.example.com/superviz/alpine/"},
+		{distro: "fedora", command: "https://mirror.example.com/superviz/rpm/"},
+		{distro: "arch", command: "https://mirror.example.com/superviz/arch/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.distro, func(t *testing.T) {
+			client := &mockSSHClient{}
+			provider := &mockInstallProvider{}
+			provider.On("GetGPGKeyID").Return("test-gpg-key-id").Maybe()
+
+			var commands []string
+			client.On("Execute", mock.Anything, mock.AnythingOfType("string")).
+				Run(func(args mock.Arguments) { commands = append(commands, args.String(1)) }).
+				Return(nil)
+
+			ms, ok := NewSetup(client, provider).(MirrorSetup)
+			require.True(t, ok)
+
+			err := ms.WithMirror("https://mirror.example.com/superviz").Setup(context.Background(), tt.distro, &bytes.Buffer{})
+			require.NoError(t, err)
+
+			found := false
+			for _, cmd := range commands {
+				assert.NotContains(t, cmd, "repo.superviz.io")
+				if strings.Contains(cmd, tt.command) {
+					found = true
+				}
+			}
+			assert.True(t, found, "no command references %s", tt.command)
+		})
+	}
+}
+
+func TestSetup_State(t *testing.T) {
+	for _, distro := range []string{"ubuntu", "debian", "alpine", "centos", "rhel", "fedora", "arch"} {
+		t.Run(distro, func(t *testing.T) {
+			client := &mockSSHClient{}
+			provider := &mockInstallProvider{}
+			provider.On("GetGPGKeyID").Return("test-gpg-key-id").Maybe()
+			client.On("Execute", mock.Anything, mock.AnythingOfType("string")).Return(nil)
+
+			inspector, ok := NewSetup(client, provider).(Inspector)
+			require.True(t, ok)
+
+			state, err := inspector.State(context.Background(), distro)
+			require.NoError(t, err)
+			assert.True(t, state.Configured())
+			client.AssertNumberOfCalls(t, "Execute", 2)
+		})
+	}
+}
+
+func TestSetup_State_UnsupportedDistribution(t *testing.T) {
+	inspector := NewSetup(&mockSSHClient{}, &mockInstallProvider{}).(Inspector)
+
+	_, err := inspector.State(context.Background(), "unknown")
+	assert.ErrorContains(t, err, "unsupported distribution: unknown")
+}
@@ -0,0 +1,410 @@
+// internal/services/run.go - Local process supervision from superviz.yaml
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services/artifact"
+	"github.com/kodflow/superviz.io/internal/services/control"
+	"github.com/kodflow/superviz.io/internal/services/integrity"
+	"github.com/kodflow/superviz.io/internal/services/metrics"
+	"github.com/kodflow/superviz.io/internal/services/reconcile"
+	"github.com/kodflow/superviz.io/internal/services/supervisor"
+	"github.com/kodflow/superviz.io/internal/utils"
+)
+
+// RunService supervises the services declared in a superviz.yaml file.
+type RunService struct {
+	// stdout receives the standard output of the services
+	stdout io.Writer
+	// stderr receives the standard error of the services
+	stderr io.Writer
+	// environ is the environment inherited by the services
+	environ []string
+	// reap makes the supervisor wait for every child, orphans included
+	reap bool
+	// httpClient downloads the artifacts
+	httpClient *http.Client
+}
+
+// RunServiceOptions contains options for creating a RunService.
+type RunServiceOptions struct {
+	// Stdout receives the standard output of the services (default os.Stdout)
+	Stdout io.Writer
+	// Stderr receives the standard error of the services (default os.Stderr)
+	Stderr io.Writer
+	// Environ is the environment inherited by the services (default os.Environ())
+	Environ []string
+	// Reap collects orphaned children, always enabled when svz runs as PID 1
+	Reap bool
+	// HTTPClient downloads the artifacts (default client with artifact.DefaultTimeout)
+	HTTPClient *http.Client
+}
+
+// NewRunService creates a new run service with the given options.
+//
+// Parameters:
+//   - opts: *RunServiceOptions overrides, nil for defaults
+//
+// Returns:
+//   - service: *RunService ready for use
+func NewRunService(opts *RunServiceOptions) *RunService {
+	if opts == nil {
+		opts = &RunServiceOptions{}
+	}
+	return &RunService{
+		stdout:     opts.Stdout,
+		stderr:     opts.Stderr,
+		environ:    opts.Environ,
+		reap:       opts.Reap || os.Getpid() == 1,
+		httpClient: opts.HTTPClient,
+	}
+}
+
+// Run loads a configuration and supervises its services until ctx is cancelled
+// or every service has ended.
+//
+// SIGTERM and SIGINT stop the services in reverse start order; SIGUSR1 and
+// SIGUSR2 are forwarded to the process group of every running service.
+// SIGHUP, like svz ctl reload, reads the configuration again and applies the
+// changes with supervisor.Reload; an invalid configuration is reported on the
+// standard error and the running one is kept. State transitions are written
+// to w as text lines, or as a JSON lines or YAML document stream in
+// structured formats.
+//
+// When the configuration has a control section, the control API used by
+// svz ctl is served for as long as the supervisor runs, and the supervisor
+// keeps running after its services have ended so they can be started again.
+// A metrics section serves the Prometheus endpoint and pushes the metrics
+// to an OTLP collector for as long as the supervisor runs. An integrity
+// section checks its files before the services start and every interval;
+// failures are written to the standard error and, unless on_failure is
+// alert, the services depending on a failing file are refused. Artifacts
+// are installed first, before any service starts; the outcome is written to
+// the standard error and the services depending on an artifact that could
+// not be installed are refused. The installation is skipped when the lock
+// file of svz build matches the configuration. A reconcile section checks
+// the services, their environment, the integrity files and the artifacts
+// every interval: divergences and corrective actions are written to the
+// standard error and served by GET /v1/events.
+//
+// Parameters:
+//   - ctx: context.Context whose cancellation stops the services
+//   - w: io.Writer destination for state transitions
+//   - path: string superviz.yaml location
+//   - format: utils.OutputFormat text, json or yaml
+//
+// Returns:
+//   - err: error if the configuration is invalid or a service failed
+func (s *RunService) Run(ctx context.Context, w io.Writer, path string, format utils.OutputFormat) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+
+	config, err := providers.LoadSupervizConfig(path)
+	if err != nil {
+		return err
+	}
+
+	report, err := runEventWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	load := func() (*providers.SupervizConfig, error) {
+		return providers.LoadSupervizConfig(path)
+	}
+	opts := &supervisor.Options{
+		Stdout:        s.stdout,
+		Stderr:        s.stderr,
+		Environ:       s.environ,
+		OnEvent:       report,
+		HandleSignals: true,
+		Reap:          s.reap,
+		StayUp:        config.Control != nil,
+		LoadConfig:    load,
+	}
+	var gates []func(service string) error
+	var installer *artifact.Installer
+	var installed *artifact.Gate
+	if len(config.Artifacts) > 0 {
+		installer = newArtifactInstaller(config, s.httpClient)
+		installed = artifact.NewGate(s.installArtifacts(ctx, config, installer))
+		gates = append(gates, installed.Allow)
+	}
+	var monitor *integrity.Monitor
+	if config.Integrity != nil {
+		monitor = integrity.NewMonitor(config.Integrity, &integrity.MonitorOptions{Report: integrityReporter(s.stderr)})
+		monitor.Check()
+		gates = append(gates, monitor.Allow)
+	}
+	opts.BeforeStart = allowAll(gates)
+	sup, err := supervisor.New(config, opts)
+	if err != nil {
+		return err
+	}
+	var reconciler *reconcile.Reconciler
+	if config.Reconcile != nil {
+		reconciler = reconcile.New(config.Reconcile, func() []reconcile.Resource {
+			resources := reconcile.Services(sup)
+			if monitor != nil {
+				resources = append(resources, reconcile.Files(config.Integrity, monitor))
+			}
+			return append(resources, reconcile.Artifacts(installer, config.Artifacts, installed)...)
+		}, &reconcile.Options{OnEvent: reconcileReporter(s.stderr)})
+	}
+	var stops []func() error
+	started := false
+	defer func() {
+		if started {
+			return
+		}
+		for _, stop := range stops {
+			_ = stop() //nolint:errcheck // already failing
+		}
+	}()
+	if monitor != nil {
+		stops = append(stops, runIntegrity(monitor))
+	}
+	if config.Control != nil {
+		controlOpts := &control.ServerOptions{Reload: reloader(sup, load)}
+		if reconciler != nil {
+			controlOpts.Events = reconciler.Events
+		}
+		stopControl, err := serveControl(config.Control, sup, controlOpts)
+		if err != nil {
+			return err
+		}
+		stops = append(stops, stopControl)
+	}
+	if config.Metrics != nil {
+		stopMetrics, err := serveMetrics(config.Metrics, sup, s.stderr)
+		if err != nil {
+			return err
+		}
+		stops = append(stops, stopMetrics)
+	}
+	if reconciler != nil {
+		stops = append(stops, runReconciler(reconciler))
+	}
+
+	started = true
+	err = sup.Run(ctx)
+	for _, stop := range stops {
+		if stopErr := stop(); err == nil {
+			err = stopErr
+		}
+	}
+	return err
+}
+
+// serveControl starts the control API of sup, with the reload and events
+// functions of opts, and returns the function stopping it
+func serveControl(config *providers.ControlConfig, sup *supervisor.Supervisor, opts *control.ServerOptions) (stop func() error, err error) {
+	listener, err := control.ListenUnix(config)
+	if err != nil {
+		return nil, err
+	}
+	listeners := []net.Listener{listener}
+	if config.TCP != nil {
+		tcp, err := control.ListenTLS(config.TCP)
+		if err != nil {
+			_ = listener.Close() //nolint:errcheck // already failing
+			return nil, err
+		}
+		listeners = append(listeners, tcp)
+	}
+
+	opts.Version = providers.DefaultVersionProvider().GetVersionInfo().Version
+	server := control.NewServer(sup, opts)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, listeners...) }()
+
+	return func() error {
+		cancel()
+		if err := <-done; err != nil {
+			return fmt.Errorf("control API failed: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// reloader returns the reload function of the control API, reading the configuration with load
+func reloader(sup *supervisor.Supervisor, load func() (*providers.SupervizConfig, error)) func(context.Context, bool) (control.ReloadResult, error) {
+	return func(_ context.Context, dryRun bool) (control.ReloadResult, error) {
+		config, err := load()
+		if err != nil {
+			return control.ReloadResult{}, fmt.Errorf("%w, keeping the current configuration", err)
+		}
+		apply := sup.Reload
+		if dryRun {
+			apply = sup.Plan
+		}
+		diff, err := apply(config)
+		if err != nil {
+			return control.ReloadResult{}, fmt.Errorf("%w, keeping the current configuration", err)
+		}
+		return control.ReloadResult{Message: diff.Summary(), Diff: diff}, nil
+	}
+}
+
+// serveMetrics starts the Prometheus endpoint and the OTLP export of the
+// metrics of sup and returns the function stopping them
+func serveMetrics(config *providers.MetricsConfig, sup *supervisor.Supervisor, stderr io.Writer) (stop func() error, err error) {
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	var exporter *metrics.Exporter
+	if config.OTLP != nil {
+		host, _ := os.Hostname() //nolint:errcheck // the nil value is used without a host name
+		exporter, err = metrics.NewExporter(config.OTLP, sup, &metrics.ExporterOptions{
+			Host: host,
+			Report: func(err error) {
+				_, _ = fmt.Fprintf(stderr, "metrics export to OTLP %s: %v\n", config.OTLP.Endpoint, err) //nolint:errcheck // output is best effort
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("metrics: otlp: %w", err)
+		}
+	}
+	var listener net.Listener
+	if config.Listen != "" {
+		if listener, err = net.Listen("tcp", config.Listen); err != nil {
+			return nil, fmt.Errorf("failed to listen for metrics: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 2)
+	running := 0
+	if listener != nil {
+		running++
+		go func() { done <- metrics.Serve(ctx, listener, config.Path, sup) }()
+	}
+	if exporter != nil {
+		running++
+		go func() {
+			exporter.Run(ctx, config.Interval)
+			done <- nil
+		}()
+	}
+
+	return func() error {
+		cancel()
+		var err error
+		for range running {
+			if serveErr := <-done; serveErr != nil && err == nil {
+				err = fmt.Errorf("metrics endpoint failed: %w", serveErr)
+			}
+		}
+		return err
+	}, nil
+}
+
+// installArtifacts installs the artifacts of config with installer, unless
+// svz build already did, and writes each outcome to stderr
+func (s *RunService) installArtifacts(ctx context.Context, config *providers.SupervizConfig, installer *artifact.Installer) *artifact.Report {
+	stderr := s.stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	if hash, satisfied, err := buildSatisfied(config, installer); err == nil && satisfied {
+		_, _ = fmt.Fprintf(stderr, "artifact: build %s is up to date\n", shortHash(hash)) //nolint:errcheck // output is best effort
+		return &artifact.Report{}
+	}
+	report := installer.Install(ctx, config.Artifacts)
+	for _, result := range report.Results {
+		_, _ = fmt.Fprintf(stderr, "artifact: %s\n", result.Format()) //nolint:errcheck // output is best effort
+	}
+	return report
+}
+
+// allowAll returns the BeforeStart hook refusing a service as soon as one gate does, nil without gates
+func allowAll(gates []func(service string) error) func(service string) error {
+	if len(gates) == 0 {
+		return nil
+	}
+	return func(service string) error {
+		for _, allow := range gates {
+			if err := allow(service); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// integrityReporter returns the callback writing the integrity failures and restorations to stderr
+func integrityReporter(stderr io.Writer) func(integrity.Result) {
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	return func(result integrity.Result) {
+		_, _ = fmt.Fprintf(stderr, "integrity: %s\n", result.Format()) //nolint:errcheck // output is best effort
+	}
+}
+
+// runIntegrity checks the files periodically and returns the function stopping the checks
+func runIntegrity(monitor *integrity.Monitor) (stop func() error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		monitor.Run(ctx)
+	}()
+	return func() error {
+		cancel()
+		<-done
+		return nil
+	}
+}
+
+// reconcileReporter returns the callback writing the reconciliation events to stderr
+func reconcileReporter(stderr io.Writer) func(reconcile.Event) {
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	return func(event reconcile.Event) {
+		_, _ = fmt.Fprintf(stderr, "reconcile: %s\n", event.Format()) //nolint:errcheck // output is best effort
+	}
+}
+
+// runReconciler reconciles periodically and returns the function stopping the passes
+func runReconciler(reconciler *reconcile.Reconciler) (stop func() error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reconciler.Run(ctx)
+	}()
+	return func() error {
+		cancel()
+		<-done
+		return nil
+	}
+}
+
+// runEventWriter returns the callback writing supervisor events in format
+func runEventWriter(w io.Writer, format utils.OutputFormat) (func(supervisor.Event), error) {
+	if format == utils.OutputText {
+		return func(event supervisor.Event) {
+			_, _ = fmt.Fprintln(w, event.Format()) //nolint:errcheck // events are best effort
+		}, nil
+	}
+
+	enc, err := utils.NewEncoder(w, format)
+	if err != nil {
+		return nil, err
+	}
+	return func(event supervisor.Event) {
+		event.Time = event.Time.UTC()
+		_ = enc.Encode(event) //nolint:errcheck // events are best effort
+	}, nil
+}
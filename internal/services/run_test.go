@@ -0,0 +1,439 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/services/control"
+	"github.com/kodflow/superviz.io/internal/services/supervisor"
+	"github.com/kodflow/superviz.io/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// writeSupervizConfig writes a superviz.yaml running sh scripts and returns its path
+func writeSupervizConfig(t *testing.T, content string) string {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is required to run the test services")
+	}
+	path := filepath.Join(t.TempDir(), "superviz.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// lockedBuffer collects the output of concurrently running services
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write appends p to the buffer
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// String returns the collected output
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// newTestRunService creates a run service capturing the service output in out
+func newTestRunService(out *lockedBuffer) *RunService {
+	return NewRunService(&RunServiceOptions{Stdout: out, Stderr: out})
+}
+
+const runTestConfig = `version: 1
+services:
+  db:
+    command: sh
+    args: ["-c", "echo db $GREETING"]
+    env:
+      GREETING: ready
+    restart: never
+  web:
+    command: sh
+    args: ["-c", "pwd"]
+    working_dir: www
+    restart: never
+    depends_on: [db]
+`
+
+func TestRunService_Text(t *testing.T) {
+	path := writeSupervizConfig(t, runTestConfig)
+	require.NoError(t, os.Mkdir(filepath.Join(filepath.Dir(path), "www"), 0o755))
+
+	var out lockedBuffer
+	var events bytes.Buffer
+	require.NoError(t, newTestRunService(&out).Run(context.Background(), &events, path, utils.OutputText))
+
+	assert.Contains(t, out.String(), "db ready\n")
+	assert.Contains(t, out.String(), string(filepath.Separator)+"www\n")
+
+	lines := strings.Split(strings.TrimSpace(events.String()), "\n")
+	require.NotEmpty(t, lines)
+	assert.Equal(t, "db: starting", lines[0])
+	assert.Contains(t, events.String(), "web: exited: exit status 0")
+}
+
+func TestRunService_JSON(t *testing.T) {
+	path := writeSupervizConfig(t, runTestConfig)
+	require.NoError(t, os.Mkdir(filepath.Join(filepath.Dir(path), "www"), 0o755))
+
+	var out lockedBuffer
+	var events bytes.Buffer
+	require.NoError(t, newTestRunService(&out).Run(context.Background(), &events, path, utils.OutputJSON))
+
+	var states []supervisor.State
+	scanner := bufio.NewScanner(&events)
+	for scanner.Scan() {
+		var event supervisor.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		assert.Equal(t, time.UTC, event.Time.Location())
+		if event.Service == "db" {
+			states = append(states, event.State)
+		}
+	}
+	assert.Equal(t, []supervisor.State{supervisor.StateStarting, supervisor.StateRunning, supervisor.StateExited}, states)
+}
+
+func TestRunService_YAML(t *testing.T) {
+	path := writeSupervizConfig(t, "services:\n  one:\n    command: sh\n    args: [\"-c\", \"exit 0\"]\n    restart: never\n")
+
+	var out lockedBuffer
+	var events bytes.Buffer
+	require.NoError(t, newTestRunService(&out).Run(context.Background(), &events, path, utils.OutputYAML))
+
+	decoder := yaml.NewDecoder(&events)
+	count := 0
+	for {
+		var event supervisor.Event
+		if err := decoder.Decode(&event); err != nil {
+			break
+		}
+		assert.Equal(t, "one", event.Service)
+		count++
+	}
+	assert.Equal(t, 3, count)
+}
+
+func TestRunService_ServiceFailure(t *testing.T) {
+	path := writeSupervizConfig(t, "services:\n  bad:\n    command: sh\n    args: [\"-c\", \"exit 4\"]\n    restart: never\n")
+
+	var out lockedBuffer
+	var events bytes.Buffer
+	err := newTestRunService(&out).Run(context.Background(), &events, path, utils.OutputText)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service bad: exited: exit status 4")
+}
+
+func TestRunService_Integrity(t *testing.T) {
+	path := writeSupervizConfig(t, `integrity:
+  files:
+    - path: vault
+      sha256: `+strings.Repeat("0", 64)+`
+      services: [db]
+services:
+  db:
+    command: sh
+    args: ["-c", "echo db"]
+    restart: never
+  job:
+    command: sh
+    args: ["-c", "echo job"]
+    restart: never
+`)
+	vault := filepath.Join(filepath.Dir(path), "vault")
+	require.NoError(t, os.WriteFile(vault, []byte("tampered"), 0o600))
+
+	var out lockedBuffer
+	var events bytes.Buffer
+	err := newTestRunService(&out).Run(context.Background(), &events, path, utils.OutputText)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service db: failed to start: integrity check failed: "+vault+": sha256 mismatch")
+	assert.Contains(t, out.String(), "integrity: "+vault+": sha256 mismatch, expected "+strings.Repeat("0", 64))
+	assert.Contains(t, out.String(), "job\n", "services not depending on the file start")
+	assert.NotContains(t, out.String(), "db\n")
+}
+
+func TestRunService_Artifacts(t *testing.T) {
+	script := []byte("echo from artifact\n")
+	digest := sha256.Sum256(script)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tool-1.0" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(script) //nolint:errcheck // test server
+	}))
+	defer server.Close()
+
+	path := writeSupervizConfig(t, fmt.Sprintf(`artifacts:
+  tool:
+    version: "1.0"
+    url: %[1]s/{{name}}-{{version}}
+    path: bin/tool
+    sha256: %[2]x
+    services: [app]
+  broken:
+    version: "1.0"
+    url: %[1]s/broken
+    path: bin/broken
+    sha256: %[3]s
+    services: [other]
+services:
+  app:
+    command: sh
+    args: [bin/tool]
+    restart: never
+  other:
+    command: sh
+    args: [bin/broken]
+    restart: never
+`, server.URL, digest, strings.Repeat("1", 64)))
+
+	var out lockedBuffer
+	var events bytes.Buffer
+	service := NewRunService(&RunServiceOptions{Stdout: &out, Stderr: &out, HTTPClient: server.Client()})
+	err := service.Run(context.Background(), &events, path, utils.OutputText)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service other: failed to start: artifact broken not installed: failed to download "+server.URL+"/broken")
+	assert.Contains(t, out.String(), "artifact: tool 1.0: installed to "+filepath.Join(filepath.Dir(path), "bin", "tool")+"\n")
+	assert.Contains(t, out.String(), "from artifact\n")
+}
+
+func TestRunService_Reconcile(t *testing.T) {
+	script := []byte("echo from artifact; exec sleep 30\n")
+	digest := sha256.Sum256(script)
+	var published atomic.Bool
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !published.Load() {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(script) //nolint:errcheck // test server
+	}))
+	defer server.Close()
+
+	path := writeSupervizConfig(t, fmt.Sprintf(`control: {}
+reconcile: {interval: 20ms, backoff: 10ms, max_backoff: 20ms}
+artifacts:
+  tool:
+    version: "1.0"
+    url: %s/tool
+    path: bin/tool
+    sha256: %x
+    services: [app]
+services:
+  app:
+    command: sh
+    args: [bin/tool]
+    restart: never
+`, server.URL, digest))
+	socket := filepath.Join(filepath.Dir(path), "superviz.sock")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var out, events lockedBuffer
+	done := make(chan error, 1)
+	service := NewRunService(&RunServiceOptions{Stdout: &out, Stderr: &out, HTTPClient: server.Client()})
+	go func() { done <- service.Run(ctx, &events, path, utils.OutputText) }()
+
+	client, err := control.NewClient(&control.ClientOptions{Socket: socket})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		events, err := client.Events(ctx, "artifact/tool", 0)
+		return err == nil && len(events) >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	published.Store(true)
+	require.Eventually(t, func() bool {
+		status, err := client.Status(ctx, "app")
+		return err == nil && status.State == supervisor.StateRunning
+	}, 5*time.Second, 10*time.Millisecond, "the artifact is installed and the fatal service started again")
+	assert.Eventually(t, func() bool { return strings.Contains(out.String(), "from artifact\n") }, 5*time.Second, 10*time.Millisecond)
+	assert.Contains(t, out.String(), "reconcile: artifact/tool: drift: tool 1.0 not installed at "+filepath.Join(filepath.Dir(path), "bin", "tool")+"\n")
+	assert.Contains(t, out.String(), "reconcile: service/app: drift: service is fatal\n")
+
+	var text bytes.Buffer
+	require.NoError(t, NewCtlService().Events(ctx, &text, &control.ClientOptions{Socket: socket}, "artifact/tool", 0, utils.OutputText))
+	assert.Contains(t, text.String(), " artifact/tool: drift: tool 1.0 not installed")
+	assert.Contains(t, text.String(), " artifact/tool: action: corrected tool 1.0 not installed")
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestRunService_StopsOnCancel(t *testing.T) {
+	path := writeSupervizConfig(t, "services:\n  sleeper:\n    command: sh\n    args: [\"-c\", \"exec sleep 30\"]\n    restart: always\n    stop_timeout: 5s\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	var out lockedBuffer
+	var events bytes.Buffer
+	start := time.Now()
+	require.NoError(t, newTestRunService(&out).Run(ctx, &events, path, utils.OutputText))
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Contains(t, events.String(), "sleeper: stopping")
+	assert.Contains(t, events.String(), "sleeper: stopped")
+}
+
+func TestRunService_Control(t *testing.T) {
+	path := writeSupervizConfig(t, "control: {}\nservices:\n  sleeper:\n    command: sh\n    args: [\"-c\", \"echo up; exec sleep 30\"]\n    restart: never\n")
+	socket := filepath.Join(filepath.Dir(path), "superviz.sock")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var out lockedBuffer
+	var events lockedBuffer
+	done := make(chan error, 1)
+	go func() { done <- newTestRunService(&out).Run(ctx, &events, path, utils.OutputText) }()
+
+	client, err := control.NewClient(&control.ClientOptions{Socket: socket})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		services, err := client.List(ctx)
+		return err == nil && len(services) == 1 && services[0].State == supervisor.StateRunning
+	}, 5*time.Second, 10*time.Millisecond)
+
+	status, err := client.Stop(ctx, "sleeper")
+	require.NoError(t, err)
+	assert.Equal(t, supervisor.StateStopped, status.State)
+
+	var texts []string
+	require.NoError(t, client.Logs(ctx, "sleeper", 0, false, func(line supervisor.LogLine) error {
+		texts = append(texts, line.Text)
+		return nil
+	}))
+	assert.Equal(t, []string{"up"}, texts)
+
+	_, err = client.Start(ctx, "sleeper")
+	require.NoError(t, err, "the supervisor stays up once its services have stopped")
+	require.Eventually(t, func() bool {
+		status, err := client.Status(ctx, "sleeper")
+		return err == nil && status.State == supervisor.StateRunning
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+	_, err = os.Stat(socket)
+	assert.True(t, os.IsNotExist(err), "the socket is removed on exit")
+}
+
+func TestRunService_Metrics(t *testing.T) {
+	var pushes atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/metrics" {
+			pushes.Add(1)
+		}
+	}))
+	defer collector.Close()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	path := writeSupervizConfig(t, fmt.Sprintf("metrics:\n  listen: %q\n  otlp: {endpoint: %q}\n  interval: 1h\n"+
+		"services:\n  sleeper:\n    command: sh\n    args: [\"-c\", \"exec sleep 30\"]\n    restart: never\n", addr, collector.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var out lockedBuffer
+	done := make(chan error, 1)
+	go func() { done <- newTestRunService(&out).Run(ctx, &lockedBuffer{}, path, utils.OutputText) }()
+
+	var body string
+	require.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + addr + "/metrics")
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		body = string(data)
+		return strings.Contains(body, `superviz_service_up{service="sleeper"} 1`)
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Contains(t, body, `superviz_service_state{service="sleeper",state="running"} 1`)
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, int32(1), pushes.Load(), "the metrics are pushed once more on exit")
+	_, err = http.Get("http://" + addr + "/metrics")
+	assert.Error(t, err, "the endpoint is closed on exit")
+}
+
+func TestRunService_StopsHelpersOnStartFailure(t *testing.T) {
+	path := writeSupervizConfig(t, `control: {}
+integrity:
+  interval: 10ms
+  files:
+    - path: vault
+      sha256: `+fmt.Sprintf("%x", sha256.Sum256([]byte("vault")))+`
+services:
+  one:
+    command: sh
+`)
+	dir := filepath.Dir(path)
+	vault := filepath.Join(dir, "vault")
+	require.NoError(t, os.WriteFile(vault, []byte("vault"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "superviz.sock"), nil, 0o600))
+
+	var out lockedBuffer
+	err := newTestRunService(&out).Run(context.Background(), &bytes.Buffer{}, path, utils.OutputText)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not a socket")
+
+	require.NoError(t, os.WriteFile(vault, []byte("tampered"), 0o600))
+	time.Sleep(100 * time.Millisecond)
+	assert.NotContains(t, out.String(), "sha256 mismatch", "the integrity checks stop with the failed run")
+}
+
+func TestRunService_Errors(t *testing.T) {
+	service := NewRunService(nil)
+
+	err := service.Run(context.Background(), nil, "superviz.yaml", utils.OutputText)
+	require.ErrorIs(t, err, ErrNilWriter)
+
+	err = service.Run(context.Background(), &bytes.Buffer{}, filepath.Join(t.TempDir(), "missing.yaml"), utils.OutputText)
+	require.Error(t, err)
+
+	path := writeSupervizConfig(t, "services:\n  one:\n    command: sh\n")
+	err = service.Run(context.Background(), &bytes.Buffer{}, path, utils.OutputFormat("xml"))
+	require.Error(t, err)
+
+	path = writeSupervizConfig(t, "control: {}\nservices:\n  one:\n    command: sh\n")
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(path), "superviz.sock"), nil, 0o600))
+	err = service.Run(context.Background(), &bytes.Buffer{}, path, utils.OutputText)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not a socket")
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	path = writeSupervizConfig(t, fmt.Sprintf("metrics: {listen: %q}\nservices:\n  one:\n    command: sh\n", listener.Addr().String()))
+	err = service.Run(context.Background(), &bytes.Buffer{}, path, utils.OutputText)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to listen for metrics")
+}
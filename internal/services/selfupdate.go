@@ -0,0 +1,510 @@
+// internal/services/selfupdate.go - Verified in-place update of the svz binary
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/utils"
+)
+
+// Self-update limits.
+const (
+	// maxReleaseIndexSize bounds the release index download
+	maxReleaseIndexSize = 4 << 20
+	// maxArtifactSize bounds the binary download
+	maxArtifactSize = 256 << 20
+	// selfUpdateTimeout bounds each HTTP request
+	selfUpdateTimeout = 5 * time.Minute
+	// backupSuffix is appended to the executable path to keep the previous binary
+	backupSuffix = ".old"
+)
+
+// SelfUpdateOptions selects the release to install.
+type SelfUpdateOptions struct {
+	// Channel is the release channel followed when Version is empty
+	Channel string
+	// Version pins an exact release, allowing downgrades
+	Version string
+	// IndexURL overrides the release index location
+	IndexURL string
+	// CheckOnly reports the available release without installing it
+	CheckOnly bool
+}
+
+// Validate checks that the options select a known channel.
+//
+// Returns:
+//   - err: error if the channel is neither stable nor beta
+func (o SelfUpdateOptions) Validate() error {
+	switch o.Channel {
+	case "", providers.ChannelStable, providers.ChannelBeta:
+		return nil
+	default:
+		return fmt.Errorf("invalid channel %q: must be %s or %s", o.Channel, providers.ChannelStable, providers.ChannelBeta)
+	}
+}
+
+// SelfUpdateResult describes the outcome of a self-update.
+type SelfUpdateResult struct {
+	// Current is the version of the running binary
+	Current string `json:"current" yaml:"current"`
+	// Target is the selected release version
+	Target string `json:"target" yaml:"target"`
+	// Channel is the followed channel, empty when a version was pinned
+	Channel string `json:"channel,omitempty" yaml:"channel,omitempty"`
+	// UpToDate is true when the running binary already is the target
+	UpToDate bool `json:"up_to_date" yaml:"up_to_date"`
+	// Updated is true when the binary was replaced by the target
+	Updated bool `json:"updated" yaml:"updated"`
+	// RolledBack is true when the previous binary was restored
+	RolledBack bool `json:"rolled_back,omitempty" yaml:"rolled_back,omitempty"`
+	// Path is the replaced executable
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Backup is where the previous binary was kept
+	Backup string `json:"backup,omitempty" yaml:"backup,omitempty"`
+}
+
+// Format returns a human-readable summary of the result.
+//
+// Returns:
+//   - formatted: string one or two lines ending with a newline
+func (r *SelfUpdateResult) Format() string {
+	switch {
+	case r.RolledBack:
+		return fmt.Sprintf("Restored the previous binary at %s\n", r.Path)
+	case r.UpToDate:
+		return fmt.Sprintf("svz %s is up to date\n", r.Current)
+	case r.Updated:
+		return fmt.Sprintf("Updated %s from %s to %s\nPrevious binary kept at %s (svz self-update --rollback)\n", r.Path, r.Current, r.Target, r.Backup)
+	default:
+		return fmt.Sprintf("svz %s is available (running %s)\n", r.Target, r.Current)
+	}
+}
+
+// SelfUpdateService replaces the running svz binary with a verified release.
+type SelfUpdateService struct {
+	// httpClient downloads the index and artifacts
+	httpClient *http.Client
+	// indexURL is the default release index location
+	indexURL string
+	// publicKey verifies artifact signatures, nil to use the embedded key
+	publicKey ed25519.PublicKey
+	// executable returns the path of the binary to replace
+	executable func() (string, error)
+	// current is the version of the running binary
+	current string
+	// goos and goarch select the artifact
+	goos, goarch string
+}
+
+// SelfUpdateServiceOptions contains options for creating a SelfUpdateService.
+//
+// All fields are optional and default to the running binary and the public
+// release server.
+type SelfUpdateServiceOptions struct {
+	// HTTPClient overrides the HTTP client
+	HTTPClient *http.Client
+	// IndexURL overrides providers.DefaultReleaseIndexURL
+	IndexURL string
+	// PublicKey overrides the signing key embedded at build time
+	PublicKey ed25519.PublicKey
+	// Executable overrides the path of the binary to replace
+	Executable string
+	// CurrentVersion overrides the version of the running binary
+	CurrentVersion string
+	// GOOS overrides runtime.GOOS
+	GOOS string
+	// GOARCH overrides runtime.GOARCH
+	GOARCH string
+}
+
+// NewSelfUpdateService creates a new self-update service with the given options.
+//
+// Parameters:
+//   - opts: *SelfUpdateServiceOptions overrides, nil for defaults
+//
+// Returns:
+//   - service: *SelfUpdateService ready for use
+func NewSelfUpdateService(opts *SelfUpdateServiceOptions) *SelfUpdateService {
+	if opts == nil {
+		opts = &SelfUpdateServiceOptions{}
+	}
+
+	s := &SelfUpdateService{
+		httpClient: opts.HTTPClient,
+		indexURL:   opts.IndexURL,
+		publicKey:  opts.PublicKey,
+		executable: currentExecutable,
+		current:    opts.CurrentVersion,
+		goos:       opts.GOOS,
+		goarch:     opts.GOARCH,
+	}
+	if s.httpClient == nil {
+		s.httpClient = &http.Client{Timeout: selfUpdateTimeout}
+	}
+	if s.indexURL == "" {
+		s.indexURL = providers.DefaultReleaseIndexURL
+	}
+	if opts.Executable != "" {
+		path := opts.Executable
+		s.executable = func() (string, error) { return path, nil }
+	}
+	if s.current == "" {
+		s.current = providers.DefaultVersionProvider().GetVersionInfo().Version
+	}
+	if s.goos == "" {
+		s.goos = runtime.GOOS
+	}
+	if s.goarch == "" {
+		s.goarch = runtime.GOARCH
+	}
+
+	return s
+}
+
+// currentExecutable returns the resolved path of the running binary
+func currentExecutable() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the running executable: %w", err)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	return resolved, nil
+}
+
+// SelfUpdate checks the release index and installs the selected release.
+//
+// The artifact is verified against its SHA-256 digest and Ed25519 signature
+// before the running executable is atomically replaced; the previous binary
+// is kept next to it with a ".old" suffix.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - w: io.Writer receiving the result
+//   - opts: SelfUpdateOptions release selection
+//   - format: utils.OutputFormat text summary, or the result encoded as JSON or YAML
+//
+// Returns:
+//   - err: error if the release cannot be resolved, downloaded, verified or installed
+func (s *SelfUpdateService) SelfUpdate(ctx context.Context, w io.Writer, opts SelfUpdateOptions, format utils.OutputFormat) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+
+	result, err := s.Run(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return writeSelfUpdateResult(w, format, result.Format(), result)
+}
+
+// Run performs the self-update and returns its outcome.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - opts: SelfUpdateOptions release selection
+//
+// Returns:
+//   - result: *SelfUpdateResult outcome
+//   - err: error if the release cannot be resolved, downloaded, verified or installed
+func (s *SelfUpdateService) Run(ctx context.Context, opts SelfUpdateOptions) (*SelfUpdateResult, error) {
+	if opts.Channel == "" {
+		opts.Channel = providers.ChannelStable
+	}
+	indexURL := opts.IndexURL
+	if indexURL == "" {
+		indexURL = s.indexURL
+	}
+
+	index, err := s.fetchIndex(ctx, indexURL)
+	if err != nil {
+		return nil, err
+	}
+	release, err := index.Resolve(opts.Channel, opts.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SelfUpdateResult{Current: s.current, Target: release.Version}
+	if opts.Version == "" {
+		result.Channel = opts.Channel
+	}
+	if isUpToDate(s.current, release.Version, opts.Version != "") {
+		result.UpToDate = true
+		return result, nil
+	}
+	if opts.CheckOnly {
+		return result, nil
+	}
+
+	artifact, err := release.Artifact(s.goos, s.goarch)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.download(ctx, artifact)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verify(artifact, data); err != nil {
+		return nil, err
+	}
+
+	path, err := s.executable()
+	if err != nil {
+		return nil, err
+	}
+	backup, err := replaceExecutable(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Updated = true
+	result.Path = path
+	result.Backup = backup
+	return result, nil
+}
+
+// LatestRelease returns the current version of a release channel.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - channel: string release channel
+//
+// Returns:
+//   - version: string latest release of the channel
+//   - err: error if the index cannot be fetched or the channel is unknown
+func (s *SelfUpdateService) LatestRelease(ctx context.Context, channel string) (string, error) {
+	index, err := s.fetchIndex(ctx, s.indexURL)
+	if err != nil {
+		return "", err
+	}
+	release, err := index.Resolve(channel, "")
+	if err != nil {
+		return "", err
+	}
+	return release.Version, nil
+}
+
+// isUpToDate reports whether the running version needs no update to reach target.
+//
+// A pinned target must match exactly; a channel target only updates to a
+// newer version so a beta build is not downgraded by the stable channel.
+// Development builds without a semantic version are always updated.
+func isUpToDate(current, target string, pinned bool) bool {
+	cur, errCur := providers.ParseSemVer(current)
+	tgt, errTgt := providers.ParseSemVer(target)
+	if errCur != nil || errTgt != nil {
+		return strings.TrimPrefix(current, "v") == target
+	}
+	if pinned {
+		return cur.Compare(tgt) == 0
+	}
+	return cur.Compare(tgt) >= 0
+}
+
+// Rollback restores the binary kept by the last self-update.
+//
+// The current binary takes the place of the backup, so a second rollback
+// re-applies the update.
+//
+// Parameters:
+//   - w: io.Writer receiving the confirmation
+//   - format: utils.OutputFormat text message, or the result encoded as JSON or YAML
+//
+// Returns:
+//   - err: error if there is no backup or the swap fails
+func (s *SelfUpdateService) Rollback(w io.Writer, format utils.OutputFormat) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+
+	path, err := s.executable()
+	if err != nil {
+		return err
+	}
+	backup := path + backupSuffix
+	if _, err := os.Stat(backup); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("no previous binary to roll back to at %s", backup)
+		}
+		return fmt.Errorf("failed to inspect %s: %w", backup, err)
+	}
+
+	swap := path + ".swap"
+	if err := os.Rename(path, swap); err != nil {
+		return fmt.Errorf("failed to move %s aside: %w", path, err)
+	}
+	if err := os.Rename(backup, path); err != nil {
+		os.Rename(swap, path) //nolint:errcheck // best effort restore
+		return fmt.Errorf("failed to restore %s: %w", backup, err)
+	}
+	if err := os.Rename(swap, backup); err != nil {
+		return fmt.Errorf("failed to keep the replaced binary at %s: %w", backup, err)
+	}
+
+	result := &SelfUpdateResult{Current: s.current, Path: path, Backup: backup, RolledBack: true}
+	return writeSelfUpdateResult(w, format, result.Format(), result)
+}
+
+// writeSelfUpdateResult writes text in text mode, otherwise the encoded result
+func writeSelfUpdateResult(w io.Writer, format utils.OutputFormat, text string, result *SelfUpdateResult) error {
+	if format != utils.OutputText {
+		return utils.EncodeOutput(w, format, result)
+	}
+	if _, err := io.WriteString(w, text); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// fetchIndex downloads and decodes the release index
+func (s *SelfUpdateService) fetchIndex(ctx context.Context, indexURL string) (*providers.ReleaseIndex, error) {
+	data, err := s.get(ctx, indexURL, maxReleaseIndexSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release index: %w", err)
+	}
+
+	var index providers.ReleaseIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse release index: %w", err)
+	}
+	return &index, nil
+}
+
+// download fetches the artifact binary
+func (s *SelfUpdateService) download(ctx context.Context, artifact *providers.ReleaseArtifact) ([]byte, error) {
+	data, err := s.get(ctx, artifact.URL, maxArtifactSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", artifact.URL, err)
+	}
+	return data, nil
+}
+
+// get performs an HTTPS GET and returns at most limit bytes of body
+func (s *SelfUpdateService) get(ctx context.Context, rawURL string, limit int64) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("refusing non-HTTPS URL %s", rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // read-only body
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("response exceeds %d bytes", limit)
+	}
+	return data, nil
+}
+
+// verify checks the artifact digest and signature
+func (s *SelfUpdateService) verify(artifact *providers.ReleaseArtifact, data []byte) error {
+	sum := sha256.Sum256(data)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), artifact.SHA256) {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %x", artifact.URL, artifact.SHA256, sum)
+	}
+
+	key := s.publicKey
+	if key == nil {
+		var err error
+		if key, err = providers.ReleasePublicKey(); err != nil {
+			return err
+		}
+	}
+	signature, err := base64.StdEncoding.DecodeString(artifact.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature for %s: %w", artifact.URL, err)
+	}
+	if !ed25519.Verify(key, data, signature) {
+		return fmt.Errorf("signature verification failed for %s", artifact.URL)
+	}
+	return nil
+}
+
+// replaceExecutable atomically installs data at path and keeps the previous binary.
+//
+// The new binary is written next to path so the final rename stays on one
+// filesystem. Renaming a running executable is allowed on every supported
+// platform, including Windows.
+//
+// Parameters:
+//   - path: string executable to replace
+//   - data: []byte verified binary
+//
+// Returns:
+//   - backup: string location of the previous binary
+//   - err: error if the binary cannot be written or swapped
+func replaceExecutable(path string, data []byte) (string, error) {
+	mode := fs.FileMode(0o755)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".svz-update-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to stage update: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) //nolint:errcheck // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck // already failing
+		return "", fmt.Errorf("failed to stage update: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to stage update: %w", err)
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return "", fmt.Errorf("failed to stage update: %w", err)
+	}
+
+	backup := path + backupSuffix
+	if err := os.Remove(backup); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("failed to remove stale backup %s: %w", backup, err)
+	}
+	if err := os.Rename(path, backup); err != nil {
+		return "", fmt.Errorf("failed to keep the previous binary: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Rename(backup, path) //nolint:errcheck // best effort restore
+		return "", fmt.Errorf("failed to install the new binary: %w", err)
+	}
+	return backup, nil
+}
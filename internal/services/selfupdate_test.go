@@ -0,0 +1,296 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/utils"
+)
+
+// releaseServer is a local stand-in for the release server.
+type releaseServer struct {
+	*httptest.Server
+	key      ed25519.PrivateKey
+	binaries map[string][]byte
+	index    providers.ReleaseIndex
+}
+
+// newReleaseServer serves an index with stable 1.4.0 and beta 1.5.0-rc.1 for linux/amd64.
+func newReleaseServer(t *testing.T) *releaseServer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	rs := &releaseServer{key: key, binaries: map[string][]byte{
+		"/svz_1.4.0_linux_amd64":      []byte("svz 1.4.0 binary"),
+		"/svz_1.5.0-rc.1_linux_amd64": []byte("svz 1.5.0-rc.1 binary"),
+	}}
+	rs.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/index.json" {
+			_ = json.NewEncoder(w).Encode(rs.index) //nolint:errcheck
+			return
+		}
+		data, ok := rs.binaries[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data) //nolint:errcheck
+	}))
+	t.Cleanup(rs.Close)
+
+	rs.index = providers.ReleaseIndex{
+		Channels: map[string]string{providers.ChannelStable: "1.4.0", providers.ChannelBeta: "1.5.0-rc.1"},
+		Releases: []providers.Release{rs.release("1.4.0", providers.ChannelStable), rs.release("1.5.0-rc.1", providers.ChannelBeta)},
+	}
+	return rs
+}
+
+// release builds a signed index entry for a served binary.
+func (rs *releaseServer) release(version, channel string) providers.Release {
+	path := "/svz_" + version + "_linux_amd64"
+	data := rs.binaries[path]
+	sum := sha256.Sum256(data)
+	return providers.Release{Version: version, Channel: channel, Artifacts: []providers.ReleaseArtifact{{
+		OS:        "linux",
+		Arch:      "amd64",
+		URL:       rs.URL + path,
+		SHA256:    hex.EncodeToString(sum[:]),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(rs.key, data)),
+	}}}
+}
+
+// service returns a self-update service replacing a fake executable in a temp dir.
+func (rs *releaseServer) service(t *testing.T, current string) (*SelfUpdateService, string) {
+	t.Helper()
+	exe := filepath.Join(t.TempDir(), "svz")
+	require.NoError(t, os.WriteFile(exe, []byte("svz "+current+" binary"), 0o755))
+
+	return NewSelfUpdateService(&SelfUpdateServiceOptions{
+		HTTPClient:     rs.Client(),
+		IndexURL:       rs.URL + "/index.json",
+		PublicKey:      rs.key.Public().(ed25519.PublicKey),
+		Executable:     exe,
+		CurrentVersion: current,
+		GOOS:           "linux",
+		GOARCH:         "amd64",
+	}), exe
+}
+
+func TestSelfUpdateService_Run_Stable(t *testing.T) {
+	rs := newReleaseServer(t)
+	service, exe := rs.service(t, "1.3.0")
+
+	result, err := service.Run(context.Background(), SelfUpdateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, &SelfUpdateResult{
+		Current: "1.3.0",
+		Target:  "1.4.0",
+		Channel: providers.ChannelStable,
+		Updated: true,
+		Path:    exe,
+		Backup:  exe + ".old",
+	}, result)
+
+	data, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	assert.Equal(t, "svz 1.4.0 binary", string(data))
+	previous, err := os.ReadFile(exe + ".old")
+	require.NoError(t, err)
+	assert.Equal(t, "svz 1.3.0 binary", string(previous))
+
+	info, err := os.Stat(exe)
+	require.NoError(t, err)
+	if os.PathSeparator == '/' {
+		assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	}
+}
+
+func TestSelfUpdateService_Run_BetaAndPinnedVersion(t *testing.T) {
+	rs := newReleaseServer(t)
+
+	service, exe := rs.service(t, "1.4.0")
+	result, err := service.Run(context.Background(), SelfUpdateOptions{Channel: providers.ChannelBeta})
+	require.NoError(t, err)
+	assert.Equal(t, "1.5.0-rc.1", result.Target)
+	assert.Equal(t, providers.ChannelBeta, result.Channel)
+	data, _ := os.ReadFile(exe) //nolint:errcheck
+	assert.Equal(t, "svz 1.5.0-rc.1 binary", string(data))
+
+	service, _ = rs.service(t, "1.5.0-rc.1")
+	result, err = service.Run(context.Background(), SelfUpdateOptions{Version: "v1.4.0"})
+	require.NoError(t, err)
+	assert.True(t, result.Updated, "pinning allows downgrades")
+	assert.Empty(t, result.Channel)
+}
+
+func TestSelfUpdateService_Run_UpToDateAndCheckOnly(t *testing.T) {
+	rs := newReleaseServer(t)
+
+	service, exe := rs.service(t, "v1.4.0")
+	result, err := service.Run(context.Background(), SelfUpdateOptions{})
+	require.NoError(t, err)
+	assert.True(t, result.UpToDate)
+	assert.NoFileExists(t, exe+".old")
+
+	service, exe = rs.service(t, "1.3.0")
+	result, err = service.Run(context.Background(), SelfUpdateOptions{CheckOnly: true})
+	require.NoError(t, err)
+	assert.False(t, result.Updated)
+	assert.Equal(t, "1.4.0", result.Target)
+	assert.NoFileExists(t, exe+".old")
+}
+
+func TestSelfUpdateService_Run_VerificationFailures(t *testing.T) {
+	tests := map[string]func(rs *releaseServer){
+		"checksum mismatch": func(rs *releaseServer) {
+			rs.binaries["/svz_1.4.0_linux_amd64"] = []byte("tampered binary")
+		},
+		"signature verification failed": func(rs *releaseServer) {
+			_, other, _ := ed25519.GenerateKey(nil) //nolint:errcheck
+			data := rs.binaries["/svz_1.4.0_linux_amd64"]
+			rs.index.Releases[0].Artifacts[0].Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(other, data))
+		},
+		"invalid signature": func(rs *releaseServer) {
+			rs.index.Releases[0].Artifacts[0].Signature = "%%%"
+		},
+		"has no binary for linux/amd64": func(rs *releaseServer) {
+			rs.index.Releases[0].Artifacts[0].Arch = "arm64"
+		},
+		"unexpected HTTP status 404": func(rs *releaseServer) {
+			delete(rs.binaries, "/svz_1.4.0_linux_amd64")
+		},
+		"refusing non-HTTPS URL": func(rs *releaseServer) {
+			rs.index.Releases[0].Artifacts[0].URL = "http://example.com/svz"
+		},
+	}
+
+	for want, tamper := range tests {
+		t.Run(want, func(t *testing.T) {
+			rs := newReleaseServer(t)
+			tamper(rs)
+			service, exe := rs.service(t, "1.3.0")
+
+			_, err := service.Run(context.Background(), SelfUpdateOptions{})
+			require.ErrorContains(t, err, want)
+
+			data, readErr := os.ReadFile(exe)
+			require.NoError(t, readErr)
+			assert.Equal(t, "svz 1.3.0 binary", string(data), "executable must be untouched")
+			assert.NoFileExists(t, exe+".old")
+		})
+	}
+}
+
+func TestSelfUpdateService_Run_IndexErrors(t *testing.T) {
+	rs := newReleaseServer(t)
+	service, _ := rs.service(t, "1.3.0")
+
+	_, err := service.Run(context.Background(), SelfUpdateOptions{Channel: "nightly"})
+	assert.ErrorContains(t, err, `unknown release channel "nightly"`)
+
+	_, err = service.Run(context.Background(), SelfUpdateOptions{IndexURL: rs.URL + "/missing.json"})
+	assert.ErrorContains(t, err, "failed to fetch release index")
+
+	rs.binaries["/garbage.json"] = []byte("{")
+	_, err = service.Run(context.Background(), SelfUpdateOptions{IndexURL: rs.URL + "/garbage.json"})
+	assert.ErrorContains(t, err, "failed to parse release index")
+}
+
+func TestSelfUpdateService_SelfUpdate_JSON(t *testing.T) {
+	rs := newReleaseServer(t)
+	service, _ := rs.service(t, "1.3.0")
+
+	var buf bytes.Buffer
+	require.NoError(t, service.SelfUpdate(context.Background(), &buf, SelfUpdateOptions{CheckOnly: true}, utils.OutputJSON))
+
+	var result SelfUpdateResult
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Equal(t, "1.4.0", result.Target)
+	assert.Equal(t, "1.3.0", result.Current)
+
+	assert.ErrorIs(t, service.SelfUpdate(context.Background(), nil, SelfUpdateOptions{}, utils.OutputText), ErrNilWriter)
+}
+
+func TestSelfUpdateService_Rollback(t *testing.T) {
+	rs := newReleaseServer(t)
+	service, exe := rs.service(t, "1.3.0")
+
+	var buf bytes.Buffer
+	err := service.Rollback(&buf, utils.OutputText)
+	assert.ErrorContains(t, err, "no previous binary to roll back to")
+
+	_, err = service.Run(context.Background(), SelfUpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, service.Rollback(&buf, utils.OutputText))
+	assert.Equal(t, "Restored the previous binary at "+exe+"\n", buf.String())
+
+	data, _ := os.ReadFile(exe)            //nolint:errcheck
+	backup, _ := os.ReadFile(exe + ".old") //nolint:errcheck
+	assert.Equal(t, "svz 1.3.0 binary", string(data))
+	assert.Equal(t, "svz 1.4.0 binary", string(backup), "rolling back again re-applies the update")
+
+	buf.Reset()
+	require.NoError(t, service.Rollback(&buf, utils.OutputJSON))
+	var result SelfUpdateResult
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.True(t, result.RolledBack)
+	assert.False(t, result.Updated, "a rollback is not reported as an update")
+	assert.Equal(t, exe+".old", result.Backup)
+}
+
+func TestSelfUpdateResult_Format(t *testing.T) {
+	assert.Equal(t, "svz 1.4.0 is up to date\n", (&SelfUpdateResult{Current: "1.4.0", UpToDate: true}).Format())
+	assert.Equal(t, "svz 1.4.0 is available (running 1.3.0)\n", (&SelfUpdateResult{Current: "1.3.0", Target: "1.4.0"}).Format())
+	assert.Contains(t, (&SelfUpdateResult{Current: "1.3.0", Target: "1.4.0", Updated: true, Path: "/bin/svz", Backup: "/bin/svz.old"}).Format(),
+		"Updated /bin/svz from 1.3.0 to 1.4.0\n")
+}
+
+func TestSelfUpdateOptions_Validate(t *testing.T) {
+	assert.NoError(t, SelfUpdateOptions{}.Validate())
+	assert.NoError(t, SelfUpdateOptions{Channel: providers.ChannelStable}.Validate())
+	assert.NoError(t, SelfUpdateOptions{Channel: providers.ChannelBeta}.Validate())
+	assert.ErrorContains(t, SelfUpdateOptions{Channel: "nightly"}.Validate(), `invalid channel "nightly"`)
+}
+
+func TestSelfUpdateService_Run_ChannelNeverDowngrades(t *testing.T) {
+	rs := newReleaseServer(t)
+	service, exe := rs.service(t, "1.5.0-rc.1")
+
+	result, err := service.Run(context.Background(), SelfUpdateOptions{Channel: providers.ChannelStable})
+	require.NoError(t, err)
+	assert.True(t, result.UpToDate, "a beta build must not be replaced by an older stable release")
+	assert.NoFileExists(t, exe+".old")
+
+	service, _ = rs.service(t, "dev")
+	result, err = service.Run(context.Background(), SelfUpdateOptions{CheckOnly: true})
+	require.NoError(t, err)
+	assert.False(t, result.UpToDate, "development builds always update")
+}
+
+func TestSelfUpdateService_LatestRelease(t *testing.T) {
+	rs := newReleaseServer(t)
+	service, _ := rs.service(t, "1.3.0")
+
+	latest, err := service.LatestRelease(context.Background(), providers.ChannelBeta)
+	require.NoError(t, err)
+	assert.Equal(t, "1.5.0-rc.1", latest)
+
+	_, err = service.LatestRelease(context.Background(), "nightly")
+	assert.ErrorContains(t, err, "unknown release channel")
+}
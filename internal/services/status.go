@@ -0,0 +1,621 @@
+// internal/services/status.go - Remote superviz state queries
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/pkgmanager"
+	"github.com/kodflow/superviz.io/internal/infrastructure/transports/ssh"
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services/reconcile"
+	"github.com/kodflow/superviz.io/internal/services/repository"
+	"github.com/kodflow/superviz.io/internal/services/repository/common"
+	"github.com/kodflow/superviz.io/internal/utils"
+)
+
+// defaultStatusConcurrency bounds the number of hosts queried at once
+const defaultStatusConcurrency = 8
+
+// ServiceState is the runtime state of the superviz service on a host.
+type ServiceState string
+
+// Service states reported by status queries.
+const (
+	// ServiceRunning indicates the service is active
+	ServiceRunning ServiceState = "running"
+	// ServiceStopped indicates the service is not active
+	ServiceStopped ServiceState = "stopped"
+)
+
+// serviceProbes detect a running superviz service, tried in order.
+var serviceProbes = []string{
+	"systemctl is-active --quiet superviz",
+	"rc-service superviz status >/dev/null 2>&1",
+	"pgrep -x superviz >/dev/null",
+}
+
+// PackageStatus describes the superviz package on a host.
+type PackageStatus struct {
+	// Manager is the package manager used by the distribution
+	Manager string `json:"manager,omitempty" yaml:"manager,omitempty"`
+	// Installed is true when the package is installed
+	Installed bool `json:"installed" yaml:"installed"`
+	// Version is the installed version
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Candidate is the newest version offered by the configured repositories
+	Candidate string `json:"candidate,omitempty" yaml:"candidate,omitempty"`
+	// UpdateAvailable is true when the candidate differs from the installed version
+	UpdateAvailable bool `json:"update_available" yaml:"update_available"`
+	// Error explains why the package state could not be determined
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// HostStatus is the superviz state of one host.
+type HostStatus struct {
+	// Target is the user@host that was queried
+	Target string `json:"target" yaml:"target"`
+	// Distro is the detected distribution
+	Distro string `json:"distro,omitempty" yaml:"distro,omitempty"`
+	// Repository reports the repository source and key
+	Repository common.RepoState `json:"repository" yaml:"repository"`
+	// Package reports the installed and candidate versions
+	Package PackageStatus `json:"package" yaml:"package"`
+	// Service is the runtime state of the superviz service
+	Service ServiceState `json:"service,omitempty" yaml:"service,omitempty"`
+	// Repairs are the reconciliation events of the repository, with --repair
+	Repairs []reconcile.Event `json:"repairs,omitempty" yaml:"repairs,omitempty"`
+	// Error is set when the host could not be queried
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// StatusReport aggregates the status of all queried hosts.
+type StatusReport struct {
+	// Hosts holds one entry per host, in request order
+	Hosts []HostStatus `json:"hosts" yaml:"hosts"`
+}
+
+// Failed returns the number of hosts that could not be queried.
+//
+// Returns:
+//   - count: int hosts with an error
+func (r *StatusReport) Failed() int {
+	count := 0
+	for _, host := range r.Hosts {
+		if host.Error != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// RepairsFailed returns the number of hosts whose repository repair failed.
+//
+// Returns:
+//   - count: int hosts with a failed repair event
+func (r *StatusReport) RepairsFailed() int {
+	count := 0
+	for _, host := range r.Hosts {
+		for _, event := range host.Repairs {
+			if event.Kind == reconcile.EventFailed {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// Format returns a human-readable summary of the report.
+//
+//	admin@web-1
+//	  distro:     ubuntu
+//	  repository: configured
+//	  package:    1.2.0 installed, 1.3.0 available
+//	  service:    running
+//
+// Returns:
+//   - formatted: string multi-line report ending with a newline
+func (r *StatusReport) Format() string {
+	var b strings.Builder
+	for i, host := range r.Hosts {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(host.Target)
+		b.WriteByte('\n')
+		if host.Error != "" {
+			fmt.Fprintf(&b, "  error:      %s\n", host.Error)
+			continue
+		}
+		fmt.Fprintf(&b, "  distro:     %s\n", host.Distro)
+		fmt.Fprintf(&b, "  repository: %s\n", formatRepoState(host.Repository))
+		fmt.Fprintf(&b, "  package:    %s\n", formatPackageStatus(host.Package))
+		fmt.Fprintf(&b, "  service:    %s\n", host.Service)
+		for _, event := range host.Repairs {
+			fmt.Fprintf(&b, "  repair:     %s\n", event.Format())
+		}
+	}
+	return b.String()
+}
+
+// formatRepoState describes a repository state for the text report
+func formatRepoState(state common.RepoState) string {
+	switch {
+	case state.Configured():
+		return "configured"
+	case state.Repository:
+		return "configured, signing key missing"
+	case state.Key:
+		return "not configured (signing key present)"
+	default:
+		return "not configured"
+	}
+}
+
+// formatPackageStatus describes a package state for the text report
+func formatPackageStatus(pkg PackageStatus) string {
+	switch {
+	case pkg.Error != "":
+		return "unknown (" + pkg.Error + ")"
+	case !pkg.Installed && pkg.Candidate != "":
+		return "not installed, " + pkg.Candidate + " available"
+	case !pkg.Installed:
+		return "not installed"
+	case pkg.UpdateAvailable:
+		return pkg.Version + " installed, " + pkg.Candidate + " available"
+	default:
+		return pkg.Version + " installed, up to date"
+	}
+}
+
+// StatusChecker collects the superviz state over an established connection.
+type StatusChecker interface {
+	// Check queries the connected host.
+	//
+	// Parameters:
+	//   - ctx: context.Context for timeout and cancellation
+	//   - target: string user@host recorded in the status
+	//
+	// Returns:
+	//   - status: *HostStatus collected state, with Error set on failure
+	Check(ctx context.Context, target string) *HostStatus
+}
+
+// statusChecker implements StatusChecker with read-only remote commands.
+type statusChecker struct {
+	// client executes probes on the connected host
+	client ssh.Client
+	// detector identifies the distribution
+	detector DistroDetector
+	// inspector reports the repository state
+	inspector repository.Inspector
+	// pkg is the package name to query
+	pkg string
+}
+
+// NewStatusChecker creates a checker using the given connected client.
+//
+// Package versions are read with ssh.OutputExecutor; clients without output
+// capture report the package state as unknown.
+//
+// Parameters:
+//   - client: ssh.Client connected to the host
+//   - inspector: repository.Inspector reporting the repository state
+//   - pkg: string package name
+//
+// Returns:
+//   - checker: StatusChecker ready for use
+func NewStatusChecker(client ssh.Client, inspector repository.Inspector, pkg string) StatusChecker {
+	return &statusChecker{
+		client:    client,
+		detector:  NewDetector(client),
+		inspector: inspector,
+		pkg:       pkg,
+	}
+}
+
+// Check queries the distribution, repository, package and service state.
+func (c *statusChecker) Check(ctx context.Context, target string) *HostStatus {
+	status := &HostStatus{Target: target}
+
+	distro, err := c.detector.Detect(ctx)
+	if err != nil {
+		status.Error = fmt.Sprintf("failed to detect distribution: %v", err)
+		return status
+	}
+	status.Distro = distro
+
+	repo, err := c.inspector.State(ctx, distro)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Repository = repo
+	status.Package = c.packageStatus(ctx, distro)
+	status.Service = c.serviceState(ctx)
+
+	return status
+}
+
+// packageStatus reads the installed and candidate versions of the package.
+func (c *statusChecker) packageStatus(ctx context.Context, distro string) PackageStatus {
+	return queryPackageStatus(ctx, c.client, c.pkg, distro)
+}
+
+// rpmInstalledQuery prints the version and release of an installed package
+// and exits non-zero when the package is not installed
+const rpmInstalledQuery = "rpm -q --qf '%%{VERSION}-%%{RELEASE}' %s"
+
+// queryPackageStatus reads the installed and candidate versions of pkg on a connected host.
+func queryPackageStatus(ctx context.Context, client ssh.Client, pkg, distro string) PackageStatus {
+	manager, err := pkgmanager.ForDistro(distro)
+	if err != nil {
+		return PackageStatus{Error: err.Error()}
+	}
+	status := PackageStatus{Manager: manager.Name()}
+
+	executor, ok := client.(ssh.OutputExecutor)
+	if !ok {
+		status.Error = "ssh client cannot capture command output"
+		return status
+	}
+
+	installedCmd, candidateCmd, err := manager.VersionCheck(ctx, pkg)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	if manager.Name() == "dnf" || manager.Name() == "yum" {
+		// dnf and yum info also print the version of a package only available
+		installedCmd = fmt.Sprintf(rpmInstalledQuery, pkg)
+	}
+
+	// Query commands exit non-zero or print nothing when the package is absent
+	if out, err := executor.ExecuteOutput(ctx, installedCmd); err == nil {
+		status.Version = firstVersion(out)
+		status.Installed = status.Version != ""
+	}
+	if out, err := executor.ExecuteOutput(ctx, candidateCmd); err == nil {
+		status.Candidate = lastVersion(out)
+	}
+	status.UpdateAvailable = status.Installed && status.Candidate != "" && status.Candidate != status.Version
+
+	return status
+}
+
+// serviceState reports whether the superviz service is running.
+func (c *statusChecker) serviceState(ctx context.Context) ServiceState {
+	for _, probe := range serviceProbes {
+		if c.client.Execute(ctx, probe) == nil {
+			return ServiceRunning
+		}
+	}
+	return ServiceStopped
+}
+
+// versionTokens returns the whitespace-separated tokens of out that look like versions.
+//
+// Package manager output mixes labels ("Version : 1.2.3", "Candidate: 1.2.3")
+// with values; a version token starts with a digit.
+func versionTokens(out string) []string {
+	var tokens []string
+	for _, field := range strings.Fields(out) {
+		if field[0] >= '0' && field[0] <= '9' {
+			tokens = append(tokens, field)
+		}
+	}
+	return tokens
+}
+
+// firstVersion returns the first version in out, or "" when none
+func firstVersion(out string) string {
+	tokens := versionTokens(out)
+	if len(tokens) == 0 {
+		return ""
+	}
+	return tokens[0]
+}
+
+// lastVersion returns the last version in out, or "" when none.
+//
+// Listings that show several versions put the newest last.
+func lastVersion(out string) string {
+	tokens := versionTokens(out)
+	if len(tokens) == 0 {
+		return ""
+	}
+	return tokens[len(tokens)-1]
+}
+
+// StatusService queries the superviz state of one or more remote hosts.
+type StatusService struct {
+	// newClient creates one SSH client per host
+	newClient func() ssh.Client
+	// newChecker creates the checker for a connected host
+	newChecker func(client ssh.Client, config *providers.InstallConfig) StatusChecker
+	// newSetup creates the repository setup repairing a connected host
+	newSetup func(client ssh.Client, config *providers.InstallConfig) repository.Setup
+	// concurrency bounds the number of hosts queried at once
+	concurrency int
+}
+
+// StatusServiceOptions contains options for creating a StatusService.
+type StatusServiceOptions struct {
+	// Provider supplies repository and package information
+	Provider providers.InstallProvider
+	// NewClient overrides the SSH client factory
+	NewClient func() ssh.Client
+	// NewChecker overrides the checker factory
+	NewChecker func(client ssh.Client, config *providers.InstallConfig) StatusChecker
+	// NewSetup overrides the repository setup factory used by Repair
+	NewSetup func(client ssh.Client, config *providers.InstallConfig) repository.Setup
+	// Concurrency bounds the number of hosts queried at once (default 8)
+	Concurrency int
+}
+
+// NewStatusService creates a new status service with the given options.
+//
+// Parameters:
+//   - opts: *StatusServiceOptions dependencies, nil for defaults
+//
+// Returns:
+//   - service: *StatusService ready for use
+func NewStatusService(opts *StatusServiceOptions) *StatusService {
+	if opts == nil {
+		opts = &StatusServiceOptions{}
+	}
+
+	s := &StatusService{
+		newClient:   opts.NewClient,
+		newChecker:  opts.NewChecker,
+		newSetup:    opts.NewSetup,
+		concurrency: opts.Concurrency,
+	}
+	if s.newClient == nil {
+		s.newClient = func() ssh.Client { return ssh.NewClient(nil) }
+	}
+	if s.concurrency <= 0 {
+		s.concurrency = defaultStatusConcurrency
+	}
+
+	if s.newChecker == nil {
+		s.newChecker = statusCheckerFactory(opts.Provider)
+	}
+	if s.newSetup == nil {
+		s.newSetup = repositorySetupFactory(opts.Provider)
+	}
+
+	return s
+}
+
+// statusCheckerFactory returns a factory building the default checker for a host.
+//
+// Parameters:
+//   - provider: providers.InstallProvider repository information, nil for the default
+//
+// Returns:
+//   - factory: func creating a StatusChecker honoring the host's repository mirror
+func statusCheckerFactory(provider providers.InstallProvider) func(ssh.Client, *providers.InstallConfig) StatusChecker {
+	if provider == nil {
+		provider = providers.DefaultInstallProvider()
+	}
+	newSetup := repositorySetupFactory(provider)
+	return func(client ssh.Client, config *providers.InstallConfig) StatusChecker {
+		return NewStatusChecker(client, newSetup(client, config).(repository.Inspector), provider.GetPackageName())
+	}
+}
+
+// repositorySetupFactory returns a factory building the repository setup of a host.
+//
+// Parameters:
+//   - provider: providers.InstallProvider repository information, nil for the default
+//
+// Returns:
+//   - factory: func creating a repository.Setup honoring the host's repository mirror
+func repositorySetupFactory(provider providers.InstallProvider) func(ssh.Client, *providers.InstallConfig) repository.Setup {
+	if provider == nil {
+		provider = providers.DefaultInstallProvider()
+	}
+	return func(client ssh.Client, config *providers.InstallConfig) repository.Setup {
+		setup := repository.NewSetup(client, provider)
+		if config.RepoMirror != "" {
+			setup = setup.(repository.MirrorSetup).WithMirror(config.RepoMirror)
+		}
+		return setup
+	}
+}
+
+// ValidateAndPrepareConfig validates and prepares the connection configuration.
+//
+// Parameters:
+//   - config: *providers.InstallConfig connection configuration to fill
+//   - args: []string command-line arguments holding user@host
+//
+// Returns:
+//   - err: error if the target is missing or malformed
+func (s *StatusService) ValidateAndPrepareConfig(config *providers.InstallConfig, args []string) error {
+	if err := parseTarget(config, args); err != nil {
+		return err
+	}
+	return validateRepoMirror(config)
+}
+
+// Run queries every host concurrently and returns the report.
+//
+// Hosts that cannot be reached are reported with Error set rather than
+// aborting the whole run.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - configs: []*providers.InstallConfig validated per-host configurations
+//
+// Returns:
+//   - report: *StatusReport one entry per config, in order
+func (s *StatusService) Run(ctx context.Context, configs []*providers.InstallConfig) *StatusReport {
+	return s.run(ctx, configs, false)
+}
+
+// run queries every host concurrently, repairing their repository when repair is set
+func (s *StatusService) run(ctx context.Context, configs []*providers.InstallConfig, repair bool) *StatusReport {
+	report := &StatusReport{Hosts: make([]HostStatus, len(configs))}
+
+	sem := make(chan struct{}, s.concurrency)
+	var wg sync.WaitGroup
+	for i, config := range configs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			report.Hosts[i] = *s.checkHost(ctx, config, repair)
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
+
+// checkHost connects to one host and collects its status, after repairing its repository when repair is set.
+func (s *StatusService) checkHost(ctx context.Context, config *providers.InstallConfig, repair bool) *HostStatus {
+	client := s.newClient()
+	if err := client.Connect(ctx, newSSHConfig(config)); err != nil {
+		return &HostStatus{Target: config.Target, Error: wrapConnectionError(err, config.Target).Error()}
+	}
+	defer client.Close() //nolint:errcheck // best effort, the status is already collected
+
+	checker := s.newChecker(client, config)
+	status := checker.Check(ctx, config.Target)
+	if !repair || status.Error != "" {
+		return status
+	}
+
+	events := repairRepository(ctx, repository.NewResource(s.newSetup(client, config), status.Distro, nil))
+	if len(events) > 0 {
+		status = checker.Check(ctx, config.Target)
+	}
+	status.Repairs = events
+	return status
+}
+
+// repairRepository runs one reconciliation pass over the repository resource and returns its events
+func repairRepository(ctx context.Context, resource *repository.Resource) []reconcile.Event {
+	reconciler := reconcile.New(&providers.ReconcileConfig{
+		MaxActions: 1,
+		Window:     providers.DefaultReconcileWindow,
+		Backoff:    providers.DefaultReconcileBackoff,
+		MaxBackoff: providers.DefaultReconcileMaxBackoff,
+	}, func() []reconcile.Resource { return []reconcile.Resource{resource} }, nil)
+	reconciler.Reconcile(ctx)
+	return reconciler.Events("", 0)
+}
+
+// Status queries every host and writes the report in the requested format.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - w: io.Writer receiving the report
+//   - configs: []*providers.InstallConfig validated per-host configurations
+//   - format: utils.OutputFormat text summary, or the report encoded as JSON or YAML
+//
+// Returns:
+//   - err: error if writing fails or a host could not be queried
+func (s *StatusService) Status(ctx context.Context, w io.Writer, configs []*providers.InstallConfig, format utils.OutputFormat) error {
+	return s.write(ctx, w, configs, format, false)
+}
+
+// Repair configures the repository of the hosts where it diverges, then
+// writes their status in the requested format.
+//
+// The repository of each host is reconciled once: a missing package source
+// or signing key is recorded as a drift and corrected by the repository
+// setup; the events are reported with the host.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - w: io.Writer receiving the report
+//   - configs: []*providers.InstallConfig validated per-host configurations
+//   - format: utils.OutputFormat text summary, or the report encoded as JSON or YAML
+//
+// Returns:
+//   - err: error if writing fails, a host could not be queried or its repair failed
+func (s *StatusService) Repair(ctx context.Context, w io.Writer, configs []*providers.InstallConfig, format utils.OutputFormat) error {
+	return s.write(ctx, w, configs, format, true)
+}
+
+// write queries every host, repairing them when repair is set, and writes the report
+func (s *StatusService) write(ctx context.Context, w io.Writer, configs []*providers.InstallConfig, format utils.OutputFormat, repair bool) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+	if len(configs) == 0 {
+		return errors.New("no hosts to query")
+	}
+
+	report := s.run(ctx, configs, repair)
+
+	if format == utils.OutputText {
+		if _, err := io.WriteString(w, report.Format()); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	} else if err := utils.EncodeOutput(w, format, report); err != nil {
+		return err
+	}
+
+	if failed := report.Failed(); failed > 0 {
+		return fmt.Errorf("%w: %d of %d hosts", ErrStatusIncomplete, failed, len(configs))
+	}
+	if failed := report.RepairsFailed(); failed > 0 {
+		return fmt.Errorf("%w: %d of %d hosts", ErrRepairFailed, failed, len(configs))
+	}
+	return nil
+}
+
+// ResolveHosts returns the validated per-host configurations of a command.
+//
+// Exactly one of a user@host argument or an inventory file must be given;
+// inventory entries inherit the flag settings of base.
+//
+// Parameters:
+//   - base: *providers.InstallConfig settings from the command-line flags
+//   - args: []string command-line arguments, holding at most one user@host
+//   - inventoryPath: string inventory file, empty when targeting a single host
+//
+// Returns:
+//   - configs: []*providers.InstallConfig one configuration per host
+//   - err: error if targets are missing, ambiguous or malformed
+func ResolveHosts(base *providers.InstallConfig, args []string, inventoryPath string) ([]*providers.InstallConfig, error) {
+	if base == nil {
+		return nil, ErrNilConfig
+	}
+	if err := validateRepoMirror(base); err != nil {
+		return nil, err
+	}
+
+	if inventoryPath == "" {
+		if err := parseTarget(base, args); err != nil {
+			return nil, err
+		}
+		return []*providers.InstallConfig{base}, nil
+	}
+
+	if len(args) > 0 {
+		return nil, errors.New("specify either user@host or --inventory, not both")
+	}
+	inventory, err := providers.LoadInventory(inventoryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := inventory.Configs(base)
+	for _, config := range configs {
+		if err := parseTarget(config, []string{config.Target}); err != nil {
+			return nil, err
+		}
+	}
+	return configs, nil
+}
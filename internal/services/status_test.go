@@ -0,0 +1,432 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/pkgmanager"
+	"github.com/kodflow/superviz.io/internal/infrastructure/transports/ssh"
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services/repository"
+	"github.com/kodflow/superviz.io/internal/services/repository/common"
+	"github.com/kodflow/superviz.io/internal/utils"
+)
+
+// mockOutputClient is an SSH client able to capture command output.
+type mockOutputClient struct {
+	mockSSHClient
+}
+
+func (m *mockOutputClient) ExecuteOutput(ctx context.Context, command string) (string, error) {
+	args := m.Called(ctx, command)
+	return args.String(0), args.Error(1)
+}
+
+type mockInspector struct {
+	mock.Mock
+}
+
+func (m *mockInspector) State(ctx context.Context, distro string) (common.RepoState, error) {
+	args := m.Called(ctx, distro)
+	return args.Get(0).(common.RepoState), args.Error(1)
+}
+
+// fixedStatusChecker returns a canned status for every host.
+type fixedStatusChecker struct {
+	status HostStatus
+}
+
+func (c *fixedStatusChecker) Check(_ context.Context, target string) *HostStatus {
+	status := c.status
+	status.Target = target
+	return &status
+}
+
+// aptVersionCommands returns the APT version queries for the superviz package.
+func aptVersionCommands(t *testing.T) (string, string) {
+	t.Helper()
+	installed, candidate, err := pkgmanager.NewAPT().VersionCheck(context.Background(), "superviz")
+	require.NoError(t, err)
+	return installed, candidate
+}
+
+func TestStatusChecker_Check(t *testing.T) {
+	installedCmd, candidateCmd := aptVersionCommands(t)
+
+	client := &mockOutputClient{}
+	client.On("ExecuteOutput", mock.Anything, installedCmd).Return("1.2.0", nil)
+	client.On("ExecuteOutput", mock.Anything, candidateCmd).Return("1.3.0", nil)
+	client.On("Execute", mock.Anything, serviceProbes[0]).Return(nil)
+
+	detector := &mockDistroDetector{}
+	detector.On("Detect", mock.Anything).Return("ubuntu", nil)
+	inspector := &mockInspector{}
+	inspector.On("State", mock.Anything, "ubuntu").Return(common.RepoState{Repository: true, Key: true}, nil)
+
+	checker := &statusChecker{client: client, detector: detector, inspector: inspector, pkg: "superviz"}
+	status := checker.Check(context.Background(), "u@h")
+
+	assert.Equal(t, &HostStatus{
+		Target:     "u@h",
+		Distro:     "ubuntu",
+		Repository: common.RepoState{Repository: true, Key: true},
+		Package: PackageStatus{
+			Manager:         "apt",
+			Installed:       true,
+			Version:         "1.2.0",
+			Candidate:       "1.3.0",
+			UpdateAvailable: true,
+		},
+		Service: ServiceRunning,
+	}, status)
+}
+
+func TestStatusChecker_Check_NotInstalled(t *testing.T) {
+	installedCmd, candidateCmd := aptVersionCommands(t)
+
+	client := &mockOutputClient{}
+	client.On("ExecuteOutput", mock.Anything, installedCmd).Return("", errors.New("exit status 1"))
+	client.On("ExecuteOutput", mock.Anything, candidateCmd).Return("(none)", nil)
+	client.On("Execute", mock.Anything, mock.Anything).Return(errors.New("exit status 1"))
+
+	detector := &mockDistroDetector{}
+	detector.On("Detect", mock.Anything).Return("debian", nil)
+	inspector := &mockInspector{}
+	inspector.On("State", mock.Anything, "debian").Return(common.RepoState{}, nil)
+
+	checker := &statusChecker{client: client, detector: detector, inspector: inspector, pkg: "superviz"}
+	status := checker.Check(context.Background(), "u@h")
+
+	assert.Equal(t, PackageStatus{Manager: "apt"}, status.Package)
+	assert.Equal(t, ServiceStopped, status.Service)
+	assert.Empty(t, status.Error)
+}
+
+func TestQueryPackageStatus_RPM(t *testing.T) {
+	for _, manager := range []pkgmanager.Manager{pkgmanager.NewDNF(), pkgmanager.NewYUM()} {
+		distro := map[string]string{"dnf": "fedora", "yum": "centos"}[manager.Name()]
+		_, candidateCmd, err := manager.VersionCheck(context.Background(), "superviz")
+		require.NoError(t, err)
+		installedCmd := "rpm -q --qf '%{VERSION}-%{RELEASE}' superviz"
+
+		t.Run(manager.Name()+" available only", func(t *testing.T) {
+			client := &mockOutputClient{}
+			client.On("ExecuteOutput", mock.Anything, installedCmd).Return("package superviz is not installed", errors.New("exit status 1"))
+			client.On("ExecuteOutput", mock.Anything, candidateCmd).Return("Packages\n1.3.0-1.el9", nil)
+
+			status := queryPackageStatus(context.Background(), client, "superviz", distro)
+			assert.Equal(t, PackageStatus{Manager: manager.Name(), Candidate: "1.3.0-1.el9"}, status)
+		})
+
+		t.Run(manager.Name()+" installed", func(t *testing.T) {
+			client := &mockOutputClient{}
+			client.On("ExecuteOutput", mock.Anything, installedCmd).Return("1.2.0-1.el9", nil)
+			client.On("ExecuteOutput", mock.Anything, candidateCmd).Return("Packages\n1.2.0-1.el9\n1.3.0-1.el9", nil)
+
+			status := queryPackageStatus(context.Background(), client, "superviz", distro)
+			assert.Equal(t, PackageStatus{
+				Manager: manager.Name(), Installed: true, Version: "1.2.0-1.el9", Candidate: "1.3.0-1.el9", UpdateAvailable: true,
+			}, status)
+		})
+	}
+}
+
+func TestStatusChecker_Check_Errors(t *testing.T) {
+	t.Run("detection", func(t *testing.T) {
+		detector := &mockDistroDetector{}
+		detector.On("Detect", mock.Anything).Return("unknown", errors.New("no os-release"))
+
+		checker := &statusChecker{client: &mockSSHClient{}, detector: detector, inspector: &mockInspector{}}
+		status := checker.Check(context.Background(), "u@h")
+		assert.Equal(t, "failed to detect distribution: no os-release", status.Error)
+	})
+
+	t.Run("unsupported distribution", func(t *testing.T) {
+		detector := &mockDistroDetector{}
+		detector.On("Detect", mock.Anything).Return("gentoo", nil)
+		inspector := &mockInspector{}
+		inspector.On("State", mock.Anything, "gentoo").Return(common.RepoState{}, errors.New("unsupported distribution: gentoo"))
+
+		checker := &statusChecker{client: &mockSSHClient{}, detector: detector, inspector: inspector}
+		status := checker.Check(context.Background(), "u@h")
+		assert.Equal(t, "unsupported distribution: gentoo", status.Error)
+	})
+
+	t.Run("no output capture", func(t *testing.T) {
+		client := &mockSSHClient{}
+		client.On("Execute", mock.Anything, mock.Anything).Return(nil)
+		detector := &mockDistroDetector{}
+		detector.On("Detect", mock.Anything).Return("alpine", nil)
+		inspector := &mockInspector{}
+		inspector.On("State", mock.Anything, "alpine").Return(common.RepoState{Repository: true}, nil)
+
+		checker := &statusChecker{client: client, detector: detector, inspector: inspector, pkg: "superviz"}
+		status := checker.Check(context.Background(), "u@h")
+		assert.Equal(t, "apk", status.Package.Manager)
+		assert.Equal(t, "ssh client cannot capture command output", status.Package.Error)
+		assert.Equal(t, ServiceRunning, status.Service)
+	})
+}
+
+func TestVersionTokens(t *testing.T) {
+	assert.Equal(t, "1.2.0", firstVersion("Version     : 1.2.0\nVersion     : 1.3.0"))
+	assert.Equal(t, "1.3.0", lastVersion("Version     : 1.2.0\nVersion     : 1.3.0"))
+	assert.Equal(t, "1:2.3-1", firstVersion("1:2.3-1"))
+	assert.Empty(t, firstVersion("(none)"))
+	assert.Empty(t, lastVersion(""))
+}
+
+func TestStatusReport_Format(t *testing.T) {
+	report := &StatusReport{Hosts: []HostStatus{
+		{
+			Target:     "admin@web-1",
+			Distro:     "ubuntu",
+			Repository: common.RepoState{Repository: true, Key: true},
+			Package:    PackageStatus{Installed: true, Version: "1.2.0", Candidate: "1.3.0", UpdateAvailable: true},
+			Service:    ServiceRunning,
+		},
+		{
+			Target:     "admin@web-2",
+			Distro:     "alpine",
+			Repository: common.RepoState{Repository: true},
+			Package:    PackageStatus{Candidate: "1.3.0"},
+			Service:    ServiceStopped,
+		},
+		{Target: "admin@web-3", Error: "failed to connect to admin@web-3: timeout"},
+	}}
+
+	assert.Equal(t, `admin@web-1
+  distro:     ubuntu
+  repository: configured
+  package:    1.2.0 installed, 1.3.0 available
+  service:    running
+
+admin@web-2
+  distro:     alpine
+  repository: configured, signing key missing
+  package:    not installed, 1.3.0 available
+  service:    stopped
+
+admin@web-3
+  error:      failed to connect to admin@web-3: timeout
+`, report.Format())
+	assert.Equal(t, 1, report.Failed())
+}
+
+func TestFormatPackageStatus(t *testing.T) {
+	assert.Equal(t, "1.3.0 installed, up to date", formatPackageStatus(PackageStatus{Installed: true, Version: "1.3.0", Candidate: "1.3.0"}))
+	assert.Equal(t, "not installed", formatPackageStatus(PackageStatus{}))
+	assert.Equal(t, "unknown (boom)", formatPackageStatus(PackageStatus{Error: "boom"}))
+	assert.Equal(t, "not configured (signing key present)", formatRepoState(common.RepoState{Key: true}))
+	assert.Equal(t, "not configured", formatRepoState(common.RepoState{}))
+}
+
+func TestStatusService_Status(t *testing.T) {
+	var connected []string
+	service := NewStatusService(&StatusServiceOptions{
+		NewClient: func() ssh.Client {
+			client := &mockSSHClient{}
+			isDown := func(config *ssh.Config) bool { return config.Host == "down" }
+			client.On("Connect", mock.Anything, mock.MatchedBy(isDown)).Return(errors.New("connection refused"))
+			client.On("Connect", mock.Anything, mock.Anything).Return(nil)
+			client.On("Close").Return(nil)
+			return client
+		},
+		NewChecker: func(_ ssh.Client, config *providers.InstallConfig) StatusChecker {
+			connected = append(connected, config.Target)
+			return &fixedStatusChecker{status: HostStatus{Distro: "ubuntu", Service: ServiceRunning}}
+		},
+		Concurrency: 1,
+	})
+
+	configs := []*providers.InstallConfig{
+		{User: "u", Host: "up", Target: "u@up"},
+		{User: "u", Host: "down", Target: "u@down"},
+	}
+
+	var buf bytes.Buffer
+	err := service.Status(context.Background(), &buf, configs, utils.OutputJSON)
+	require.ErrorIs(t, err, ErrStatusIncomplete)
+	assert.ErrorContains(t, err, "1 of 2 hosts")
+
+	var report StatusReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+	require.Len(t, report.Hosts, 2)
+	assert.Equal(t, "u@up", report.Hosts[0].Target)
+	assert.Equal(t, "ubuntu", report.Hosts[0].Distro)
+	assert.Equal(t, "u@down", report.Hosts[1].Target)
+	assert.Contains(t, report.Hosts[1].Error, "connection refused")
+	assert.Equal(t, []string{"u@up"}, connected)
+}
+
+func TestStatusService_Status_Text(t *testing.T) {
+	service := NewStatusService(&StatusServiceOptions{
+		NewClient: func() ssh.Client {
+			client := &mockSSHClient{}
+			client.On("Connect", mock.Anything, mock.Anything).Return(nil)
+			client.On("Close").Return(nil)
+			return client
+		},
+		NewChecker: func(ssh.Client, *providers.InstallConfig) StatusChecker {
+			return &fixedStatusChecker{status: HostStatus{Distro: "arch", Service: ServiceStopped}}
+		},
+	})
+
+	var buf bytes.Buffer
+	err := service.Status(context.Background(), &buf, []*providers.InstallConfig{{Target: "u@h"}}, utils.OutputText)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "u@h\n  distro:     arch\n")
+}
+
+// repairHostFake is a host repository configured by Setup and reported by Check.
+type repairHostFake struct {
+	mu         sync.Mutex
+	configured bool
+	setupErr   error
+	setups     int
+}
+
+// Setup configures the repository unless setupErr is set.
+func (h *repairHostFake) Setup(context.Context, string, io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.setups++
+	if h.setupErr != nil {
+		return h.setupErr
+	}
+	h.configured = true
+	return nil
+}
+
+// State reports the repository as configured once Setup succeeded.
+func (h *repairHostFake) State(context.Context, string) (common.RepoState, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return common.RepoState{Repository: h.configured, Key: h.configured}, nil
+}
+
+// Check reports the repository state of the host.
+func (h *repairHostFake) Check(ctx context.Context, target string) *HostStatus {
+	state, _ := h.State(ctx, "ubuntu") //nolint:errcheck // never fails
+	return &HostStatus{Target: target, Distro: "ubuntu", Repository: state, Service: ServiceRunning}
+}
+
+// repairService builds a status service whose hosts are the fakes, by target.
+func repairService(hosts map[string]*repairHostFake) *StatusService {
+	return NewStatusService(&StatusServiceOptions{
+		NewClient: func() ssh.Client {
+			client := &mockSSHClient{}
+			client.On("Connect", mock.Anything, mock.Anything).Return(nil)
+			client.On("Close").Return(nil)
+			return client
+		},
+		NewChecker: func(_ ssh.Client, config *providers.InstallConfig) StatusChecker {
+			return hosts[config.Target]
+		},
+		NewSetup: func(_ ssh.Client, config *providers.InstallConfig) repository.Setup {
+			return hosts[config.Target]
+		},
+	})
+}
+
+func TestStatusService_Repair(t *testing.T) {
+	hosts := map[string]*repairHostFake{"u@missing": {}, "u@configured": {configured: true}}
+	configs := []*providers.InstallConfig{{Target: "u@missing"}, {Target: "u@configured"}}
+
+	var buf bytes.Buffer
+	require.NoError(t, repairService(hosts).Repair(context.Background(), &buf, configs, utils.OutputJSON))
+
+	var report StatusReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+	missing, configured := report.Hosts[0], report.Hosts[1]
+	assert.True(t, missing.Repository.Configured(), "the status is collected again after the repair")
+	require.Len(t, missing.Repairs, 2)
+	assert.Equal(t, "repository/ubuntu: drift: missing package source and signing key", missing.Repairs[0].Format())
+	assert.Equal(t, "repository/ubuntu: action: corrected missing package source and signing key", missing.Repairs[1].Format())
+	assert.Equal(t, 1, hosts["u@missing"].setups)
+
+	assert.Empty(t, configured.Repairs)
+	assert.Zero(t, hosts["u@configured"].setups, "a configured repository is left alone")
+}
+
+func TestStatusService_Repair_Failure(t *testing.T) {
+	hosts := map[string]*repairHostFake{"u@h": {setupErr: errors.New("no network")}}
+
+	var buf bytes.Buffer
+	err := repairService(hosts).Repair(context.Background(), &buf, []*providers.InstallConfig{{Target: "u@h"}}, utils.OutputText)
+	require.ErrorIs(t, err, ErrRepairFailed)
+	assert.ErrorContains(t, err, "1 of 1 hosts")
+	assert.Contains(t, buf.String(), "  repository: not configured\n")
+	assert.Contains(t, buf.String(), "  repair:     repository/ubuntu: failed: no network\n")
+}
+
+func TestStatusService_Status_InvalidArguments(t *testing.T) {
+	service := NewStatusService(nil)
+
+	assert.ErrorIs(t, service.Status(context.Background(), nil, nil, utils.OutputText), ErrNilWriter)
+	assert.ErrorContains(t, service.Status(context.Background(), &bytes.Buffer{}, nil, utils.OutputText), "no hosts to query")
+}
+
+func TestStatusService_ValidateAndPrepareConfig(t *testing.T) {
+	service := NewStatusService(nil)
+
+	config := &providers.InstallConfig{}
+	require.NoError(t, service.ValidateAndPrepareConfig(config, []string{"admin@web-1"}))
+	assert.Equal(t, "admin", config.User)
+	assert.Equal(t, "web-1", config.Host)
+
+	assert.ErrorIs(t, service.ValidateAndPrepareConfig(&providers.InstallConfig{}, []string{"web-1"}), ErrInvalidTarget)
+	assert.ErrorContains(t, service.ValidateAndPrepareConfig(&providers.InstallConfig{RepoMirror: "http://mirror"}, []string{"a@b"}), "invalid repository mirror")
+}
+
+func TestResolveHosts(t *testing.T) {
+	t.Run("single target", func(t *testing.T) {
+		base := &providers.InstallConfig{Port: 22}
+		configs, err := ResolveHosts(base, []string{"admin@web-1"}, "")
+		require.NoError(t, err)
+		require.Equal(t, []*providers.InstallConfig{base}, configs)
+		assert.Equal(t, "web-1", base.Host)
+	})
+
+	t.Run("inventory", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "hosts.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("hosts:\n  - admin@web-1\n  - target: root@web-2\n    ssh-port: 2222\n"), 0o600))
+
+		configs, err := ResolveHosts(&providers.InstallConfig{Port: 22}, nil, path)
+		require.NoError(t, err)
+		require.Len(t, configs, 2)
+		assert.Equal(t, "admin", configs[0].User)
+		assert.Equal(t, "web-1", configs[0].Host)
+		assert.Equal(t, 22, configs[0].Port)
+		assert.Equal(t, "root", configs[1].User)
+		assert.Equal(t, 2222, configs[1].Port)
+
+		_, err = ResolveHosts(&providers.InstallConfig{}, []string{"a@b"}, path)
+		assert.ErrorContains(t, err, "not both")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "hosts.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("hosts:\n  - web-1\n"), 0o600))
+
+		_, err := ResolveHosts(&providers.InstallConfig{}, nil, path)
+		assert.ErrorIs(t, err, ErrInvalidTarget)
+		_, err = ResolveHosts(&providers.InstallConfig{}, nil, "")
+		assert.ErrorIs(t, err, ErrInvalidTarget)
+		_, err = ResolveHosts(nil, nil, "")
+		assert.ErrorIs(t, err, ErrNilConfig)
+		_, err = ResolveHosts(&providers.InstallConfig{RepoMirror: "ftp://x"}, []string{"a@b"}, "")
+		assert.ErrorContains(t, err, "invalid repository mirror")
+	})
+}
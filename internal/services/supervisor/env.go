@@ -0,0 +1,210 @@
+// internal/services/supervisor/env.go - Resolution of the typed service environment
+package supervisor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+// EnvError reports every variable of a service that failed to resolve.
+type EnvError struct {
+	// Problems describe the variables in name order, without the secret values
+	Problems []string
+}
+
+// Error lists the problems on one line
+func (e *EnvError) Error() string {
+	return "invalid environment: " + strings.Join(e.Problems, "; ")
+}
+
+// envResolver resolves the declared variables of a service once, in
+// reference order.
+type envResolver struct {
+	// vars are the declared variables
+	vars providers.EnvVars
+	// inherited is the base environment of the service
+	inherited map[string]string
+	// dir resolves the relative file references
+	dir string
+	// values holds the resolved variables, empty for those left out
+	values map[string]string
+	// injected records the variables whose condition holds
+	injected map[string]bool
+	// resolving detects the reference cycles left by hand-built configurations
+	resolving map[string]bool
+	// problems maps each failed variable to its description
+	problems map[string]string
+}
+
+// resolveEnv computes the environment of a service start.
+//
+// Each declared variable takes its interpolated value, else the inherited
+// one, else its interpolated default, and is injected when its condition
+// holds. Every variable is checked before reporting, so one start attempt
+// lists all the problems.
+//
+// Parameters:
+//   - base: []string inherited KEY=value environment
+//   - config: *providers.ServiceConfig service whose env is resolved
+//
+// Returns:
+//   - env: []string sorted KEY=value environment of the process
+//   - secrets: []string non-empty values of the secret variables, to mask
+//   - err: *EnvError listing the invalid, missing or unreadable variables
+func resolveEnv(base []string, config *providers.ServiceConfig) ([]string, []string, error) {
+	r := &envResolver{
+		vars:      config.Env,
+		inherited: make(map[string]string, len(base)),
+		dir:       config.WorkingDir,
+		values:    make(map[string]string, len(config.Env)),
+		injected:  make(map[string]bool, len(config.Env)),
+		resolving: make(map[string]bool),
+		problems:  make(map[string]string),
+	}
+	for _, entry := range base {
+		if key, value, ok := strings.Cut(entry, "="); ok {
+			r.inherited[key] = value
+		}
+	}
+
+	names := make([]string, 0, len(config.Env))
+	for name := range config.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	env := make(map[string]string, len(names))
+	var secrets []string
+	for _, name := range names {
+		r.resolve(name)
+		if !r.injected[name] {
+			continue
+		}
+		value := r.values[name]
+		env[name] = value
+		if v := r.vars[name]; v != nil && v.Secret && value != "" {
+			secrets = append(secrets, value)
+		}
+	}
+
+	if len(r.problems) > 0 {
+		problems := make([]string, 0, len(r.problems))
+		for _, name := range names {
+			if problem, ok := r.problems[name]; ok {
+				problems = append(problems, problem)
+			}
+		}
+		return nil, nil, &EnvError{Problems: problems}
+	}
+	return mergeEnv(base, env), secrets, nil
+}
+
+// resolve computes, checks and records a declared variable, once
+func (r *envResolver) resolve(name string) {
+	if _, done := r.values[name]; done || r.resolving[name] {
+		return
+	}
+	r.resolving[name] = true
+	defer delete(r.resolving, name)
+
+	v := r.vars[name]
+	if v == nil {
+		v = &providers.EnvVar{}
+	}
+
+	// A variable left out by its condition is neither read nor checked
+	if !v.When.Met(r.lookup) {
+		r.values[name] = ""
+		return
+	}
+	r.injected[name] = true
+
+	value, err := providers.Interpolate(v.Value, r.reference)
+	if err == nil && value == "" {
+		value = r.inherited[name]
+	}
+	if err == nil && value == "" {
+		value, err = providers.Interpolate(v.Default, r.reference)
+	}
+	r.values[name] = value
+	if err != nil {
+		r.problems[name] = fmt.Sprintf("%s: %s", name, err)
+		return
+	}
+
+	switch {
+	case value == "" && v.Required:
+		r.problems[name] = name + " is required"
+	case value == "":
+	default:
+		if err := v.Check(value); err != nil {
+			if v.Secret {
+				r.problems[name] = fmt.Sprintf("%s %s", name, err)
+			} else {
+				r.problems[name] = fmt.Sprintf("%s=%q %s", name, value, err)
+			}
+		}
+	}
+}
+
+// lookup returns the value a variable will have in the process, empty when unset
+func (r *envResolver) lookup(name string) string {
+	if _, declared := r.vars[name]; declared {
+		if r.resolving[name] {
+			return ""
+		}
+		r.resolve(name)
+		if r.injected[name] {
+			return r.values[name]
+		}
+	}
+	return r.inherited[name]
+}
+
+// reference replaces one ${NAME} or ${FILE:path} reference
+func (r *envResolver) reference(ref string) (string, error) {
+	path, ok := strings.CutPrefix(ref, "FILE:")
+	if !ok {
+		if r.resolving[ref] {
+			return "", fmt.Errorf("reference cycle through %s", ref)
+		}
+		return r.lookup(ref), nil
+	}
+
+	if !filepath.IsAbs(path) && r.dir != "" {
+		path = filepath.Join(r.dir, path)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // the path comes from the operator's configuration
+	if err != nil {
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// CheckEnv resolves the environment of a service as its next start would,
+// without starting it.
+//
+// Parameters:
+//   - name: string service name
+//
+// Returns:
+//   - err: *EnvError listing the invalid, missing or unreadable variables, or wrapping ErrUnknownService
+func (s *Supervisor) CheckEnv(name string) error {
+	config, ok := s.currentConfig().Services[name]
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownService, name)
+	}
+	_, _, err := resolveEnv(s.environ, config)
+	return err
+}
@@ -0,0 +1,126 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveEnv(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "password"), []byte("s3cret-pw\n"), 0o600))
+
+	config := &providers.ServiceConfig{
+		WorkingDir: dir,
+		Env: providers.EnvVars{
+			"PLAIN":    {Value: "x"},
+			"EMPTY":    {},
+			"PORT":     {Type: providers.EnvInt, Default: "8080"},
+			"HOST":     {Default: "localhost"},
+			"URL":      {Type: providers.EnvURL, Value: "http://${HOST}:${PORT}/"},
+			"PASSWORD": {Value: "${FILE:password}", Secret: true},
+			"DSN":      {Value: "app:${PASSWORD}@${HOST}", Secret: true},
+			"TLS_KEY":  {Value: "/etc/key.pem", When: &providers.EnvCondition{Set: "TLS_CERT"}},
+			"DEV_ONLY": {Value: "1", When: &providers.EnvCondition{Equals: map[string]string{"MODE": "dev"}}},
+			"FALLBACK": {Value: "on", When: &providers.EnvCondition{Unset: "TLS_KEY"}},
+		},
+	}
+
+	env, secrets, err := resolveEnv([]string{"HOST=db", "MODE=prod", "KEEP=1"}, config)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"DSN=app:s3cret-pw@db",
+		"EMPTY=",
+		"FALLBACK=on",
+		"HOST=db",
+		"KEEP=1",
+		"MODE=prod",
+		"PASSWORD=s3cret-pw",
+		"PLAIN=x",
+		"PORT=8080",
+		"URL=http://db:8080/",
+	}, env, "inherited values win over defaults, conditions drop TLS_KEY and DEV_ONLY")
+	assert.Equal(t, []string{"app:s3cret-pw@db", "s3cret-pw"}, secrets)
+
+	env, _, err = resolveEnv([]string{"TLS_CERT=/etc/cert.pem", "MODE=dev"}, config)
+	require.NoError(t, err)
+	assert.Contains(t, env, "TLS_KEY=/etc/key.pem")
+	assert.Contains(t, env, "DEV_ONLY=1")
+	assert.NotContains(t, env, "FALLBACK=on", "TLS_KEY is injected")
+	assert.Contains(t, env, "HOST=localhost")
+}
+
+func TestResolveEnv_Problems(t *testing.T) {
+	config := &providers.ServiceConfig{
+		WorkingDir: t.TempDir(),
+		Env: providers.EnvVars{
+			"DATABASE_URL": {Type: providers.EnvURL, Required: true},
+			"PORT":         {Type: providers.EnvInt, Value: "${PORT_NUMBER}"},
+			"TOKEN":        {Type: providers.EnvRegex, Pattern: "[a-f0-9]{8}", Secret: true},
+			"CERT":         {Value: "${FILE:/nonexistent/cert.pem}"},
+			"OPTIONAL":     {Required: true, Value: "${FILE:/nonexistent/feature}", When: &providers.EnvCondition{Set: "FEATURE"}},
+		},
+	}
+
+	_, _, err := resolveEnv([]string{"PORT_NUMBER=http", "TOKEN=not-hex-token"}, config)
+	var envErr *EnvError
+	require.True(t, errors.As(err, &envErr))
+	assert.Equal(t, []string{
+		"CERT: failed to read /nonexistent/cert.pem: no such file or directory",
+		"DATABASE_URL is required",
+		`PORT="http" must be an int`,
+		"TOKEN must match [a-f0-9]{8}",
+	}, envErr.Problems)
+	assert.NotContains(t, err.Error(), "not-hex-token", "secret values are not reported")
+	assert.Contains(t, err.Error(), "invalid environment: CERT: failed to read")
+}
+
+func TestRun_InvalidEnvIsFatal(t *testing.T) {
+	service := helperService("app", "env", "PORT")
+	service.Env["PORT"] = &providers.EnvVar{Type: providers.EnvInt, Value: "${PORT_NUMBER}"}
+	service.Env["API_KEY"] = &providers.EnvVar{Required: true}
+	out, log := &syncBuffer{}, &eventLog{}
+	s, err := New(helperConfig(t, service), &Options{Stdout: out, Stderr: out, OnEvent: log.add, Environ: []string{"PORT_NUMBER=eighty"}})
+	require.NoError(t, err)
+
+	err = s.Run(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `service app: failed to start: invalid environment: API_KEY is required; PORT="eighty" must be an int`)
+	assert.Equal(t, []State{StateStarting, StateFatal}, log.states("app"))
+	assert.Empty(t, out.String(), "the process never started")
+}
+
+func TestRun_MasksSecrets(t *testing.T) {
+	service := helperService("app", "env", "API_KEY")
+	service.Env["API_KEY"] = &providers.EnvVar{Value: "k3y-${PIN}-value", Secret: true}
+	service.Env["PIN"] = &providers.EnvVar{Value: "42", Secret: true}
+	out := &syncBuffer{}
+	s, err := New(helperConfig(t, service), &Options{Stdout: out, Stderr: out})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Run(context.Background()))
+	assert.Equal(t, "app | ****\n", out.String())
+}
+
+func TestSupervisor_CheckEnv(t *testing.T) {
+	service := helperService("app", "env")
+	service.Env["API_KEY"] = &providers.EnvVar{Required: true}
+	s, err := New(helperConfig(t, service), &Options{Environ: []string{}})
+	require.NoError(t, err)
+
+	err = s.CheckEnv("app")
+	var envErr *EnvError
+	require.True(t, errors.As(err, &envErr))
+	assert.Equal(t, []string{"API_KEY is required"}, envErr.Problems)
+
+	s, err = New(helperConfig(t, service), &Options{Environ: []string{"API_KEY=k"}})
+	require.NoError(t, err)
+	require.NoError(t, s.CheckEnv("app"))
+	require.ErrorIs(t, s.CheckEnv("missing"), ErrUnknownService)
+}
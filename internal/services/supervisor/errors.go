@@ -0,0 +1,18 @@
+// internal/services/supervisor/errors.go - Errors returned by the service operations
+package supervisor
+
+import "errors"
+
+// Errors returned by Start, Stop, Restart, SignalService and the log accessors.
+var (
+	// ErrUnknownService indicates that no service has the requested name
+	ErrUnknownService = errors.New("unknown service")
+	// ErrServiceActive indicates that the service is already running or waiting to start
+	ErrServiceActive = errors.New("service is already running")
+	// ErrServiceNotActive indicates that the service is neither running nor waiting to start
+	ErrServiceNotActive = errors.New("service is not running")
+	// ErrUnsupportedSignal indicates a signal name unknown or not deliverable on this platform
+	ErrUnsupportedSignal = errors.New("unsupported signal")
+	// ErrSupervisorStopped indicates that the supervisor is not running or is shutting down
+	ErrSupervisorStopped = errors.New("supervisor is not running")
+)
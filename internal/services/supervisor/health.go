@@ -0,0 +1,106 @@
+// internal/services/supervisor/health.go - Startup, liveness and readiness probes of a running service
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+// healthMonitor runs the probes of one process until it is stopped.
+type healthMonitor struct {
+	// failed receives the error of a failed startup or liveness probe, at most once
+	failed chan error
+	// cancel stops the probes
+	cancel context.CancelFunc
+	// done is closed once every probe returned
+	done chan struct{}
+}
+
+// stop cancels the probes and waits for them, so no transition follows
+func (m *healthMonitor) stop() {
+	m.cancel()
+	<-m.done
+}
+
+// monitor starts the probes of a running process.
+//
+// The startup probe runs first and holds the other probes back. Once it
+// passes, the liveness and readiness probes run side by side: readiness moves
+// the service between healthy and unhealthy, liveness reports a failure on
+// the failed channel for the runner to restart the process. A service is
+// healthy, for depends_on, once its readiness probe passes, or its startup
+// probe without readiness probe, or as soon as it runs without either.
+//
+// Parameters:
+//   - pid: int process id reported in the transitions
+//   - env: []string environment of the process, given to the exec probes
+//
+// Returns:
+//   - monitor: *healthMonitor to stop once the process ends
+func (r *runner) monitor(pid int, env []string) *healthMonitor {
+	ctx, cancel := context.WithCancel(r.ctx)
+	m := &healthMonitor{failed: make(chan error, 1), cancel: cancel, done: make(chan struct{})}
+	config := r.config
+	probes := config.Probes
+
+	if probes.Startup == nil && probes.Readiness == nil {
+		r.reach(providers.ConditionHealthy)
+	}
+
+	go func() {
+		defer close(m.done)
+
+		if probes.Startup != nil {
+			var startupErr error
+			watchProbe(ctx, probes.Startup, r.measure(ProbeStartup, r.sup.checker(config, env, probes.Startup)), func(err error) bool {
+				startupErr = err
+				return true
+			})
+			switch {
+			case ctx.Err() != nil:
+				return
+			case startupErr != nil:
+				m.failed <- fmt.Errorf("startup probe failed: %w", startupErr)
+				return
+			case probes.Readiness == nil:
+				r.transition(StateHealthy, pid, nil, "")
+				r.reach(providers.ConditionHealthy)
+			}
+		}
+
+		var wg sync.WaitGroup
+		if probes.Liveness != nil {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				watchProbe(ctx, probes.Liveness, r.measure(ProbeLiveness, r.sup.checker(config, env, probes.Liveness)), func(err error) bool {
+					if err == nil {
+						return false
+					}
+					m.failed <- fmt.Errorf("liveness probe failed: %w", err)
+					return true
+				})
+			}()
+		}
+		if probes.Readiness != nil {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				watchProbe(ctx, probes.Readiness, r.measure(ProbeReadiness, r.sup.checker(config, env, probes.Readiness)), func(err error) bool {
+					if err != nil {
+						r.transition(StateUnhealthy, pid, nil, "readiness probe failed: "+err.Error())
+						return false
+					}
+					r.transition(StateHealthy, pid, nil, "")
+					r.reach(providers.ConditionHealthy)
+					return false
+				})
+			}()
+		}
+		wg.Wait()
+	}()
+	return m
+}
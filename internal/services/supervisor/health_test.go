@@ -0,0 +1,134 @@
+package supervisor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// healthServer is a stand-in HTTP endpoint answering 200 while ready is set, 503 otherwise
+func healthServer(t *testing.T, ready *atomic.Bool) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if !ready.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// fastProbe returns a probe checking url every few milliseconds
+func fastProbe(url string, failureThreshold int) *providers.Probe {
+	return &providers.Probe{
+		HTTP:             &providers.HTTPProbe{URL: url},
+		Interval:         5 * time.Millisecond,
+		Timeout:          time.Second,
+		SuccessThreshold: 1,
+		FailureThreshold: failureThreshold,
+	}
+}
+
+// runInBackground starts s and returns a function stopping it and returning the error of Run
+func runInBackground(s *Supervisor) (stop func() error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	return func() error {
+		cancel()
+		return <-done
+	}
+}
+
+func TestHealth_ReadinessDrivesState(t *testing.T) {
+	var ready atomic.Bool
+	service := helperService("web", "trap")
+	service.Probes.Readiness = fastProbe(healthServer(t, &ready), 1)
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, service), out, log)
+	stop := runInBackground(s)
+
+	require.Eventually(t, func() bool { return log.index("web", StateUnhealthy) >= 0 }, 5*time.Second, 5*time.Millisecond)
+	ready.Store(true)
+	require.Eventually(t, func() bool { return s.Services()[0].State == StateHealthy }, 5*time.Second, 5*time.Millisecond)
+	ready.Store(false)
+	require.Eventually(t, func() bool { return s.Services()[0].State == StateUnhealthy }, 5*time.Second, 5*time.Millisecond)
+
+	require.NoError(t, stop())
+	assert.Equal(t, 0, s.Services()[0].Restarts, "readiness never restarts the service")
+	assert.Equal(t, []State{StateStarting, StateRunning, StateUnhealthy, StateHealthy, StateUnhealthy, StateStopping, StateStopped}, log.states("web"))
+}
+
+func TestHealth_HealthyConditionWaitsForReadiness(t *testing.T) {
+	var ready atomic.Bool
+	db := helperService("db", "trap")
+	db.Probes.Readiness = fastProbe(healthServer(t, &ready), 1)
+	app := helperService("app", "trap")
+	app.DependsOn = providers.Dependencies{{Service: "db", Condition: providers.ConditionHealthy}}
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, db, app), out, log)
+	stop := runInBackground(s)
+
+	require.Eventually(t, func() bool { return log.index("db", StateUnhealthy) >= 0 }, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, -1, log.index("app", StateStarting), "app waits for db to become healthy")
+
+	ready.Store(true)
+	require.Eventually(t, func() bool { return log.index("app", StateRunning) >= 0 }, 5*time.Second, 5*time.Millisecond)
+	assert.Less(t, log.index("db", StateHealthy), log.index("app", StateStarting))
+	require.NoError(t, stop())
+}
+
+func TestHealth_LivenessFailureRestarts(t *testing.T) {
+	var alive atomic.Bool
+	service := helperService("web", "trap")
+	service.Restart = providers.RestartOnFailure
+	service.Probes.Liveness = fastProbe(healthServer(t, &alive), 2)
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, service), out, log)
+	stop := runInBackground(s)
+
+	require.Eventually(t, func() bool { return s.Services()[0].Restarts >= 1 }, 5*time.Second, 5*time.Millisecond)
+	alive.Store(true)
+	require.NoError(t, stop())
+
+	states := log.states("web")
+	require.GreaterOrEqual(t, len(states), 6)
+	assert.Equal(t, []State{StateStarting, StateRunning, StateUnhealthy, StateStopping, StateExited, StateStarting}, states[:6],
+		"a trapped TERM exits 0, the liveness failure still restarts an on-failure service")
+	assert.Contains(t, log.events[2].Message, "liveness probe failed: status 503")
+}
+
+func TestHealth_StartupFailureBecomesFatal(t *testing.T) {
+	var started atomic.Bool
+	service := helperService("web", "trap")
+	service.Restart = providers.RestartNever
+	service.Probes.Startup = fastProbe(healthServer(t, &started), 3)
+	service.Probes.Liveness = fastProbe("http://127.0.0.1:1/unreachable", 1)
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, service), out, log)
+
+	err := s.Run(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service web: startup probe failed: status 503")
+	assert.Equal(t, []State{StateStarting, StateRunning, StateUnhealthy, StateStopping, StateExited}, log.states("web"))
+}
+
+func TestHealth_StartupProbeGatesHealthy(t *testing.T) {
+	var started atomic.Bool
+	started.Store(true)
+	service := helperService("web", "trap")
+	service.Probes.Startup = fastProbe(healthServer(t, &started), 3)
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, service), out, log)
+	stop := runInBackground(s)
+
+	require.Eventually(t, func() bool { return s.Services()[0].State == StateHealthy }, 5*time.Second, 5*time.Millisecond)
+	require.NoError(t, stop())
+}
@@ -0,0 +1,156 @@
+package supervisor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+// helperEnv switches the test binary into a small helper program
+const helperEnv = "SVZ_SUPERVISOR_HELPER"
+
+// TestMain runs the helper program when the test binary is started by a supervisor under test
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(helperEnv); mode != "" {
+		os.Exit(runHelper(mode, os.Args[1:]))
+	}
+	os.Exit(m.Run())
+}
+
+// runHelper implements the helper modes:
+//
+//	exit CODE        exit immediately with CODE
+//	sleep DURATION   sleep, then exit 0
+//	echo TEXT        print TEXT and exit 0
+//	fail TEXT        print TEXT to stderr and exit 1
+//	env NAME         print the value of NAME
+//	pwd              print the working directory
+//	trap             print "ready", exit 0 on TERM
+//	ignore           print "ready", ignore TERM and sleep
+//	signals          print "ready", then the forwarded signals received, exit 0 on TERM
+//	orphan           start a helper sleeping 200ms, print its pid and exit without waiting
+func runHelper(mode string, args []string) int {
+	arg := ""
+	if len(args) > 0 {
+		arg = args[0]
+	}
+
+	switch mode {
+	case "exit":
+		code, _ := strconv.Atoi(arg)
+		return code
+	case "sleep":
+		d, _ := time.ParseDuration(arg)
+		time.Sleep(d)
+		return 0
+	case "echo":
+		fmt.Println(arg)
+		return 0
+	case "fail":
+		fmt.Fprintln(os.Stderr, arg)
+		return 1
+	case "env":
+		fmt.Println(os.Getenv(arg))
+		return 0
+	case "pwd":
+		dir, _ := os.Getwd()
+		fmt.Println(dir)
+		return 0
+	case "trap":
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGTERM)
+		fmt.Println("ready")
+		<-ch
+		return 0
+	case "signals":
+		ch := make(chan os.Signal, 4)
+		signal.Notify(ch, append([]os.Signal{syscall.SIGTERM}, forwardedSignals...)...)
+		fmt.Println("ready")
+		for sig := range ch {
+			if sig == syscall.SIGTERM {
+				return 0
+			}
+			fmt.Println("got", sig)
+		}
+		return 0
+	case "orphan":
+		cmd := exec.Command(os.Args[0], "200ms")
+		cmd.Env = append(os.Environ(), helperEnv+"=sleep")
+		if err := cmd.Start(); err != nil {
+			return 1
+		}
+		fmt.Println(cmd.Process.Pid)
+		return 0
+	case "ignore":
+		signal.Ignore(syscall.SIGTERM)
+		fmt.Println("ready")
+		time.Sleep(time.Minute)
+		return 0
+	}
+	return 2
+}
+
+// helperService declares a service running the test binary in a helper mode
+func helperService(name, mode string, args ...string) *providers.ServiceConfig {
+	return &providers.ServiceConfig{
+		Name:        name,
+		Command:     os.Args[0],
+		Args:        args,
+		Env:         providers.EnvVars{helperEnv: {Value: mode}},
+		Restart:     providers.RestartNever,
+		StopSignal:  providers.DefaultStopSignal,
+		StopTimeout: providers.DefaultStopTimeout,
+		// Restart fast so tests do not wait for the default backoff
+		RestartPolicy: providers.RestartPolicy{Backoff: 10 * time.Millisecond, MaxBackoff: 10 * time.Millisecond, Multiplier: 1},
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent writers
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write appends p to the buffer
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// String returns the buffered output
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// helperConfig assembles and validates a configuration from services
+func helperConfig(t *testing.T, services ...*providers.ServiceConfig) *providers.SupervizConfig {
+	t.Helper()
+	config := &providers.SupervizConfig{
+		Version:  providers.SupervizConfigVersion,
+		OnFatal:  providers.OnFatalConfig{Action: providers.FatalContinue, Timeout: providers.DefaultHookTimeout},
+		Services: make(map[string]*providers.ServiceConfig, len(services)),
+		Dir:      t.TempDir(),
+	}
+	for _, service := range services {
+		if service.WorkingDir == "" {
+			service.WorkingDir = config.Dir
+		}
+		config.Services[service.Name] = service
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("invalid helper configuration: %v", err)
+	}
+	return config
+}
@@ -0,0 +1,310 @@
+// internal/services/supervisor/logfile.go - Rotated log files of the supervised services
+package supervisor
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+const (
+	// logTimeLayout prefixes every line of a log file
+	logTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+	// rotatedLayout names rotated files after their rotation time, so their names sort by age
+	rotatedLayout = "20060102T150405.000000000Z"
+	// logExt ends the current and the rotated files
+	logExt = ".log"
+	// gzipExt is appended to the compressed rotated files
+	gzipExt = ".gz"
+)
+
+// logFile writes the lines of a service to <dir>/<service>.log, as
+// timestamped text or as JSON records.
+//
+// Before a line would make the file larger than max_size, or once a new
+// rotate_every period has started, the file is renamed to
+// <service>-<time>.log and a new one is opened. The rotated file is then
+// compressed and the rotated files beyond max_files or older than max_age
+// are removed, in the background.
+type logFile struct {
+	// config holds the limits, resolved for the service
+	config providers.LogConfig
+	// service prefixes the file names
+	service string
+	// report receives the write failures, once per failing streak, and the maintenance failures
+	report func(error)
+	// mu guards every field below
+	mu sync.Mutex
+	// file is the current file, nil until the first line or after a failure
+	file *os.File
+	// size is the size of the current file
+	size int64
+	// period is the start of the rotate_every period the current file belongs to
+	period time.Time
+	// failing is set while writes fail, so a broken disk is reported once
+	failing bool
+	// closed is set once the service output ended for good
+	closed bool
+	// maintenance serializes compression and pruning
+	maintenance sync.Mutex
+	// pending counts the running maintenance goroutines
+	pending sync.WaitGroup
+}
+
+// newLogFile creates the log file sink of a service, opened on its first line
+func newLogFile(service string, config *providers.LogConfig, report func(error)) *logFile {
+	return &logFile{config: *config, service: service, report: report}
+}
+
+// path returns the location of the current file
+func (f *logFile) path() string {
+	return filepath.Join(f.config.Dir, f.service+logExt)
+}
+
+// writeLine appends a timestamped line, rotating the file first when needed
+func (f *logFile) writeLine(line LogLine) {
+	data := line.Time.UTC().Format(logTimeLayout) + " " + line.Stream + " " + line.Text + "\n"
+	if f.config.JSONEnabled() {
+		data = line.JSON() + "\n"
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return
+	}
+	if err := f.write(data, line.Time); err != nil {
+		if !f.failing {
+			f.report(err)
+		}
+		f.failing = true
+		return
+	}
+	f.failing = false
+}
+
+// write appends data, opening or rotating the file first, f.mu must be held
+func (f *logFile) write(data string, now time.Time) error {
+	if f.file == nil {
+		if err := f.open(now); err != nil {
+			return err
+		}
+	}
+	full := f.config.MaxSize > 0 && f.size+int64(len(data)) > int64(f.config.MaxSize)
+	expired := f.config.RotateEvery > 0 && !f.periodOf(now).Equal(f.period)
+	if f.size > 0 && (full || expired) {
+		if err := f.rotate(now); err != nil {
+			return err
+		}
+	}
+
+	n, err := f.file.WriteString(data)
+	f.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", f.path(), err)
+	}
+	return nil
+}
+
+// periodOf returns the start of the rotate_every period containing t
+func (f *logFile) periodOf(t time.Time) time.Time {
+	if f.config.RotateEvery <= 0 {
+		return time.Time{}
+	}
+	return t.UTC().Truncate(f.config.RotateEvery)
+}
+
+// open opens the current file for appending; an existing file belongs to the
+// period of its last write, so it is rotated on the first line of a later period
+func (f *logFile) open(now time.Time) error {
+	if err := os.MkdirAll(f.config.Dir, 0o755); err != nil { //nolint:gosec // log directories are world-readable like /var/log
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(f.path(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644) //nolint:gosec // the path comes from the operator's configuration
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close() //nolint:errcheck // already failing
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	f.file, f.size, f.period = file, info.Size(), f.periodOf(now)
+	if f.size > 0 {
+		f.period = f.periodOf(info.ModTime())
+	}
+	return nil
+}
+
+// rotate renames the current file after now, opens a new one and starts the maintenance
+func (f *logFile) rotate(now time.Time) error {
+	if err := f.file.Close(); err != nil {
+		f.report(fmt.Errorf("failed to close %s: %w", f.path(), err))
+	}
+	f.file = nil
+
+	rotated := filepath.Join(f.config.Dir, f.service+"-"+now.UTC().Format(rotatedLayout)+logExt)
+	if err := os.Rename(f.path(), rotated); err != nil {
+		return fmt.Errorf("failed to rotate %s: %w", f.path(), err)
+	}
+	if err := f.open(now); err != nil {
+		return err
+	}
+
+	f.pending.Add(1)
+	go func() {
+		defer f.pending.Done()
+		f.maintain(now)
+	}()
+	return nil
+}
+
+// maintain compresses the rotated files and removes those beyond the limits.
+//
+// Every run sweeps the whole directory, so runs started by successive
+// rotations may complete in any order.
+func (f *logFile) maintain(now time.Time) {
+	f.maintenance.Lock()
+	defer f.maintenance.Unlock()
+
+	files, err := f.rotated()
+	if err != nil {
+		f.report(err)
+		return
+	}
+	if f.config.CompressEnabled() {
+		for i, file := range files {
+			if strings.HasSuffix(file.path, gzipExt) {
+				continue
+			}
+			if err := compressFile(file.path); err != nil {
+				f.report(err)
+				continue
+			}
+			files[i].path += gzipExt
+		}
+	}
+	if err := f.prune(files, now); err != nil {
+		f.report(err)
+	}
+}
+
+// rotatedFile is a rotated log file and its rotation time
+type rotatedFile struct {
+	path string
+	at   time.Time
+}
+
+// rotated lists the rotated files of the service, newest first
+func (f *logFile) rotated() ([]rotatedFile, error) {
+	entries, err := os.ReadDir(f.config.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rotated log files: %w", err)
+	}
+	var files []rotatedFile
+	for _, entry := range entries {
+		if at, ok := f.rotatedAt(entry.Name()); ok && entry.Type().IsRegular() {
+			files = append(files, rotatedFile{path: filepath.Join(f.config.Dir, entry.Name()), at: at})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].at.After(files[j].at) })
+	return files, nil
+}
+
+// prune removes the rotated files beyond max_files, oldest first, and those older than max_age
+func (f *logFile) prune(files []rotatedFile, now time.Time) error {
+	var errs []error
+	for i, file := range files {
+		tooMany := f.config.MaxFiles > 0 && i >= f.config.MaxFiles
+		tooOld := f.config.MaxAge > 0 && now.Sub(file.at) > f.config.MaxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.Remove(file.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("failed to remove rotated log file: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// rotatedAt parses the rotation time of a rotated file of this service
+func (f *logFile) rotatedAt(name string) (time.Time, bool) {
+	stamp, ok := strings.CutPrefix(name, f.service+"-")
+	if !ok {
+		return time.Time{}, false
+	}
+	stamp = strings.TrimSuffix(stamp, gzipExt)
+	stamp, ok = strings.CutSuffix(stamp, logExt)
+	if !ok {
+		return time.Time{}, false
+	}
+	at, err := time.Parse(rotatedLayout, stamp)
+	return at, err == nil
+}
+
+// close closes the current file and waits for the maintenance to finish
+func (f *logFile) close() error {
+	f.mu.Lock()
+	f.closed = true
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+
+	f.pending.Wait()
+	return err
+}
+
+// compressFile replaces path with path.gz, written through a temporary file
+func compressFile(path string) (err error) {
+	src, err := os.Open(path) //nolint:gosec // rotated by the supervisor
+	if err != nil {
+		return fmt.Errorf("failed to compress rotated log file: %w", err)
+	}
+	defer src.Close() //nolint:errcheck // only read
+
+	tmp := path + gzipExt + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644) //nolint:gosec // same permissions as the log file
+	if err != nil {
+		return fmt.Errorf("failed to compress rotated log file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = dst.Close()    //nolint:errcheck // already failing
+			_ = os.Remove(tmp) //nolint:errcheck // already failing
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = dst.Sync()
+	}
+	if err == nil {
+		err = dst.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmp, path+gzipExt)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to compress rotated log file: %w", err)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove compressed log file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package supervisor
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errorLog collects the errors reported by a log file
+type errorLog struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+// add records err
+func (l *errorLog) add(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errs = append(l.errs, err)
+}
+
+// count returns the number of reported errors
+func (l *errorLog) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.errs)
+}
+
+// testLogFile returns a log file of service web in a temporary directory
+func testLogFile(t *testing.T, config providers.LogConfig) (*logFile, *errorLog) {
+	t.Helper()
+	if config.Dir == "" {
+		config.Dir = filepath.Join(t.TempDir(), "logs")
+	}
+	errs := &errorLog{}
+	return newLogFile("web", &config, errs.add), errs
+}
+
+// at returns a stdout line of web written at the given time
+func at(when time.Time, text string) LogLine {
+	return LogLine{Time: when, Service: "web", Stream: StreamStdout, Text: text}
+}
+
+// dirNames returns the sorted file names of dir
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readLog returns the content of a plain or gzipped log file
+func readLog(t *testing.T, path string) string {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close() //nolint:errcheck // test cleanup
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, gzipExt) {
+		zr, err := gzip.NewReader(file)
+		require.NoError(t, err)
+		r = zr
+	}
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(data)
+}
+
+var logEpoch = time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+func TestLogFile_WritesTimestampedLines(t *testing.T) {
+	f, errs := testLogFile(t, providers.LogConfig{MaxSize: providers.DefaultLogMaxSize})
+	f.writeLine(at(logEpoch, "hello"))
+	f.writeLine(LogLine{Time: logEpoch.Add(1500 * time.Millisecond), Service: "web", Stream: StreamStderr, Text: "oops"})
+	require.NoError(t, f.close())
+
+	assert.Equal(t, "2026-03-01T10:00:00.000Z stdout hello\n2026-03-01T10:00:01.500Z stderr oops\n", readLog(t, f.path()))
+	assert.Zero(t, errs.count())
+
+	f.writeLine(at(logEpoch, "late"))
+	assert.NotContains(t, readLog(t, f.path()), "late", "lines after close are dropped")
+}
+
+func TestLogFile_RotatesBySize(t *testing.T) {
+	compress := true
+	// Each line is 39 bytes, so two lines fit in 80 bytes
+	f, errs := testLogFile(t, providers.LogConfig{MaxSize: 80, MaxFiles: 2, Compress: &compress})
+	for i := range 7 {
+		f.writeLine(at(logEpoch.Add(time.Duration(i)*time.Second), "line "+string(rune('a'+i))))
+	}
+	require.NoError(t, f.close())
+	require.Zero(t, errs.count())
+
+	names := dirNames(t, f.config.Dir)
+	assert.Equal(t, []string{
+		"web-20260301T100004.000000000Z.log.gz",
+		"web-20260301T100006.000000000Z.log.gz",
+		"web.log",
+	}, names, "the oldest rotated file is removed beyond max_files")
+	assert.Equal(t, "2026-03-01T10:00:06.000Z stdout line g\n", readLog(t, f.path()))
+	assert.Equal(t, "2026-03-01T10:00:04.000Z stdout line e\n2026-03-01T10:00:05.000Z stdout line f\n",
+		readLog(t, filepath.Join(f.config.Dir, names[1])))
+}
+
+func TestLogFile_RotatesByPeriod(t *testing.T) {
+	compress := false
+	f, _ := testLogFile(t, providers.LogConfig{MaxSize: providers.DefaultLogMaxSize, RotateEvery: time.Hour, MaxFiles: 5, Compress: &compress})
+	f.writeLine(at(logEpoch.Add(30*time.Minute), "first"))
+	f.writeLine(at(logEpoch.Add(59*time.Minute), "second"))
+	f.writeLine(at(logEpoch.Add(time.Hour), "third"))
+	require.NoError(t, f.close())
+
+	names := dirNames(t, f.config.Dir)
+	assert.Equal(t, []string{"web-20260301T110000.000000000Z.log", "web.log"}, names, "a new hour starts a new file, uncompressed")
+	assert.Contains(t, readLog(t, filepath.Join(f.config.Dir, names[0])), "second")
+	assert.Contains(t, readLog(t, f.path()), "third")
+}
+
+func TestLogFile_RotatesStaleFileOnOpen(t *testing.T) {
+	compress := false
+	f, _ := testLogFile(t, providers.LogConfig{MaxSize: providers.DefaultLogMaxSize, RotateEvery: 24 * time.Hour, MaxFiles: 5, Compress: &compress})
+	require.NoError(t, os.MkdirAll(f.config.Dir, 0o755))
+	require.NoError(t, os.WriteFile(f.path(), []byte("yesterday\n"), 0o600))
+	yesterday := logEpoch.Add(-24 * time.Hour)
+	require.NoError(t, os.Chtimes(f.path(), yesterday, yesterday))
+
+	f.writeLine(at(logEpoch, "today"))
+	require.NoError(t, f.close())
+
+	names := dirNames(t, f.config.Dir)
+	require.Len(t, names, 2, "a file left by a previous run in an earlier period is rotated")
+	assert.Equal(t, "yesterday\n", readLog(t, filepath.Join(f.config.Dir, names[0])))
+}
+
+func TestLogFile_PrunesByAge(t *testing.T) {
+	compress := false
+	f, _ := testLogFile(t, providers.LogConfig{MaxSize: 50, MaxFiles: 10, MaxAge: 48 * time.Hour, Compress: &compress})
+	require.NoError(t, os.MkdirAll(f.config.Dir, 0o755))
+	old := filepath.Join(f.config.Dir, "web-"+logEpoch.Add(-72*time.Hour).Format(rotatedLayout)+".log.gz")
+	recent := filepath.Join(f.config.Dir, "web-"+logEpoch.Add(-24*time.Hour).Format(rotatedLayout)+".log")
+	other := filepath.Join(f.config.Dir, "web-api-"+logEpoch.Add(-72*time.Hour).Format(rotatedLayout)+".log")
+	for _, path := range []string{old, recent, other} {
+		require.NoError(t, os.WriteFile(path, []byte("x\n"), 0o600))
+	}
+
+	f.writeLine(at(logEpoch, "first line fills the file"))
+	f.writeLine(at(logEpoch.Add(time.Second), "second line rotates it"))
+	require.NoError(t, f.close())
+
+	assert.NoFileExists(t, old)
+	assert.FileExists(t, recent)
+	assert.FileExists(t, other, "files of other services are left alone")
+}
+
+func TestLogFile_ReportsFailuresOnce(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(blocker, nil, 0o600))
+	f, errs := testLogFile(t, providers.LogConfig{Dir: filepath.Join(blocker, "logs"), MaxSize: providers.DefaultLogMaxSize})
+
+	f.writeLine(at(logEpoch, "one"))
+	f.writeLine(at(logEpoch, "two"))
+	require.NoError(t, f.close())
+	require.Equal(t, 1, errs.count())
+	assert.Contains(t, errs.errs[0].Error(), "failed to create log directory")
+}
+
+func TestLogFile_RotatedAt(t *testing.T) {
+	f := newLogFile("web", &providers.LogConfig{}, func(error) {})
+	stamp := logEpoch.Format(rotatedLayout)
+
+	for name, ok := range map[string]bool{
+		"web-" + stamp + ".log":        true,
+		"web-" + stamp + ".log.gz":     true,
+		"web-" + stamp + ".log.gz.tmp": false,
+		"web.log":                      false,
+		"web-api.log":                  false,
+		"web-api-" + stamp + ".log":    false,
+		"db-" + stamp + ".log":         false,
+	} {
+		when, matched := f.rotatedAt(name)
+		assert.Equal(t, ok, matched, name)
+		if ok {
+			assert.True(t, when.Equal(logEpoch), name)
+		}
+	}
+}
+
+func TestSupervisor_WritesLogFiles(t *testing.T) {
+	mirror := false
+	service := helperService("web", "echo", "hello world")
+	service.Logs = providers.LogConfig{Dir: filepath.Join(t.TempDir(), "logs"), MaxSize: providers.DefaultLogMaxSize, Mirror: &mirror}
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, service), out, log)
+
+	require.NoError(t, s.Run(context.Background()))
+	data, err := os.ReadFile(filepath.Join(service.Logs.Dir, "web.log"))
+	require.NoError(t, err)
+	assert.Regexp(t, `^\S+Z stdout hello world\n$`, string(data))
+	assert.NotContains(t, out.String(), "hello world", "mirror: false keeps the lines off the svz output")
+}
@@ -0,0 +1,215 @@
+// internal/services/supervisor/logforward.go - Forwarding of the service lines to remote destinations
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+const (
+	// forwardQueue is the number of lines waiting for a destination before lines are dropped
+	forwardQueue = 4096
+	// forwardDrainTimeout bounds the export of the queued lines once the supervisor stops
+	forwardDrainTimeout = 5 * time.Second
+)
+
+// logExporter sends batches of lines to a remote destination.
+type logExporter interface {
+	// export sends the lines, which must not be retained after it returns
+	export(ctx context.Context, lines []LogLine) error
+	// close releases the connection
+	close() error
+}
+
+// logForwarder queues the lines of every service and exports them in
+// batches from one goroutine, so a slow destination never blocks a service.
+//
+// Lines are dropped while the queue is full; drops and export failures are
+// reported once per streak.
+type logForwarder struct {
+	// name identifies the destination in the reports
+	name string
+	// exporter sends the batches
+	exporter logExporter
+	// batch is the largest number of lines exported at once
+	batch int
+	// interval is the longest a line waits for its batch
+	interval time.Duration
+	// timeout bounds one export
+	timeout time.Duration
+	// report receives the failures
+	report func(error)
+	// lines is the queue
+	lines chan LogLine
+	// mu guards started and closed, and closed against sends on the closed queue
+	mu sync.RWMutex
+	// started is set once the export goroutine runs
+	started bool
+	// closed is set once the queue is closed
+	closed bool
+	// dropping is set while lines are dropped
+	dropping atomic.Bool
+	// ctx is cancelled when the drain timeout expires
+	ctx context.Context
+	// cancel aborts the export in progress
+	cancel context.CancelFunc
+	// done is closed once the export goroutine returned
+	done chan struct{}
+}
+
+// newLogForwarder creates a stopped forwarder
+func newLogForwarder(name string, exporter logExporter, batch int, interval, timeout time.Duration, report func(error)) *logForwarder {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &logForwarder{
+		name:     name,
+		exporter: exporter,
+		batch:    batch,
+		interval: interval,
+		timeout:  timeout,
+		report:   report,
+		lines:    make(chan LogLine, forwardQueue),
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// writeLine queues the line, or drops it when the queue is full
+func (f *logForwarder) writeLine(line LogLine) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.closed {
+		return
+	}
+	select {
+	case f.lines <- line:
+		f.dropping.Store(false)
+	default:
+		if f.dropping.CompareAndSwap(false, true) {
+			f.report(fmt.Errorf("%s is too slow, dropping lines", f.name))
+		}
+	}
+}
+
+// close does nothing, the forwarder is shared by the services and stopped by the supervisor
+func (f *logForwarder) close() error {
+	return nil
+}
+
+// start exports the queued lines until stop is called
+func (f *logForwarder) start() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.started || f.closed {
+		return
+	}
+	f.started = true
+	go f.run()
+}
+
+// run exports a batch once it is full or its oldest line waited for interval
+func (f *logForwarder) run() {
+	defer close(f.done)
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	batch := make([]LogLine, 0, f.batch)
+	failing := false
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(f.ctx, f.timeout)
+		err := f.exporter.export(ctx, batch)
+		cancel()
+		batch = batch[:0]
+		if err != nil && !failing {
+			f.report(err)
+		}
+		failing = err != nil
+	}
+
+	for {
+		select {
+		case line, ok := <-f.lines:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, line)
+			if len(batch) >= f.batch {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// stop exports the queued lines, waiting up to forwardDrainTimeout, and closes the exporter
+func (f *logForwarder) stop() {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return
+	}
+	f.closed = true
+	close(f.lines)
+	started := f.started
+	f.mu.Unlock()
+	if !started {
+		close(f.done)
+	}
+
+	timer := time.NewTimer(forwardDrainTimeout)
+	defer timer.Stop()
+	select {
+	case <-f.done:
+	case <-timer.C:
+		f.cancel()
+		<-f.done
+	}
+	f.cancel()
+	if err := f.exporter.close(); err != nil {
+		f.report(err)
+	}
+}
+
+// buildForwarders creates the forwarders of the log_forward section, stopped until Run
+func (s *Supervisor) buildForwarders() error {
+	forward := s.config.LogForward
+	if forward == nil {
+		return nil
+	}
+	host, _ := os.Hostname() //nolint:errcheck // the nil value is used without a host name
+	report := func(name string) func(error) {
+		return func(err error) {
+			_, _ = fmt.Fprintf(s.stderr, "log forwarding to %s: %v\n", name, err) //nolint:errcheck // output is best effort
+		}
+	}
+
+	if forward.Syslog != nil {
+		name := "syslog " + forward.Syslog.Address
+		exporter := newSyslogExporter(forward.Syslog, host)
+		s.forwarders = append(s.forwarders, newLogForwarder(name, exporter, syslogBatch, syslogInterval, syslogTimeout, report(name)))
+	}
+	if forward.OTLP != nil {
+		exporter, err := newOTLPLogExporter(forward.OTLP, host)
+		if err != nil {
+			return fmt.Errorf("log_forward: otlp: %w", err)
+		}
+		timeout := forward.OTLP.Timeout
+		if timeout <= 0 {
+			timeout = providers.DefaultOTLPTimeout
+		}
+		name := "OTLP " + forward.OTLP.Endpoint
+		s.forwarders = append(s.forwarders, newLogForwarder(name, exporter, otlpLogBatch, otlpLogInterval, timeout, report(name)))
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeExporter records the exported batches and fails while err is set
+type fakeExporter struct {
+	mu      sync.Mutex
+	batches [][]string
+	err     error
+	block   chan struct{}
+	closed  bool
+}
+
+// export records the texts of the batch
+func (e *fakeExporter) export(ctx context.Context, lines []LogLine) error {
+	if e.block != nil {
+		select {
+		case <-e.block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.err != nil {
+		return e.err
+	}
+	e.batches = append(e.batches, texts(lines))
+	return nil
+}
+
+// close records the call
+func (e *fakeExporter) close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closed = true
+	return nil
+}
+
+// exported returns the recorded batches
+func (e *fakeExporter) exported() [][]string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([][]string(nil), e.batches...)
+}
+
+func TestLogForwarder_Batches(t *testing.T) {
+	exporter := &fakeExporter{}
+	errs := &errorLog{}
+	f := newLogForwarder("fake", exporter, 2, time.Hour, time.Second, errs.add)
+	f.start()
+
+	for _, text := range []string{"a", "b", "c"} {
+		f.writeLine(at(logEpoch, text))
+	}
+	require.Eventually(t, func() bool { return len(exporter.exported()) == 1 }, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, [][]string{{"a", "b"}}, exporter.exported(), "a full batch is exported right away")
+
+	f.stop()
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, exporter.exported(), "stop exports the rest")
+	assert.True(t, exporter.closed)
+	assert.Zero(t, errs.count())
+
+	f.writeLine(at(logEpoch, "late"))
+	f.stop()
+	assert.Len(t, exporter.exported(), 2, "lines after stop are dropped")
+}
+
+func TestLogForwarder_Interval(t *testing.T) {
+	exporter := &fakeExporter{}
+	f := newLogForwarder("fake", exporter, 100, 10*time.Millisecond, time.Second, func(error) {})
+	f.start()
+	defer f.stop()
+
+	f.writeLine(at(logEpoch, "a"))
+	assert.Eventually(t, func() bool { return len(exporter.exported()) == 1 }, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestLogForwarder_ReportsOncePerStreak(t *testing.T) {
+	exporter := &fakeExporter{err: errors.New("collector down")}
+	errs := &errorLog{}
+	f := newLogForwarder("fake", exporter, 1, time.Hour, time.Second, errs.add)
+	f.start()
+
+	f.writeLine(at(logEpoch, "a"))
+	f.writeLine(at(logEpoch, "b"))
+	require.Eventually(t, func() bool { return len(f.lines) == 0 }, 5*time.Second, 5*time.Millisecond)
+	f.stop()
+	assert.Equal(t, 1, errs.count())
+	assert.EqualError(t, errs.errs[0], "collector down")
+}
+
+func TestLogForwarder_DropsWhenFull(t *testing.T) {
+	exporter := &fakeExporter{block: make(chan struct{})}
+	errs := &errorLog{}
+	f := newLogForwarder("fake", exporter, 1, time.Hour, time.Minute, errs.add)
+	f.start()
+
+	for range forwardQueue + 10 {
+		f.writeLine(at(logEpoch, "x"))
+	}
+	require.Equal(t, 1, errs.count(), "drops are reported once")
+	assert.Contains(t, errs.errs[0].Error(), "fake is too slow, dropping lines")
+
+	close(exporter.block)
+	f.stop()
+	assert.LessOrEqual(t, len(exporter.exported()), forwardQueue+1)
+}
+
+func TestLogForwarder_StopWithoutStart(t *testing.T) {
+	exporter := &fakeExporter{}
+	f := newLogForwarder("fake", exporter, 1, time.Hour, time.Second, func(error) {})
+	f.writeLine(at(logEpoch, "a"))
+	f.stop()
+	assert.True(t, exporter.closed)
+}
+
+func TestSupervisor_ForwardsToSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close() //nolint:errcheck // test cleanup
+
+	quiet := helperService("quiet", "echo", "secret")
+	quiet.Logs.Forward = new(bool)
+	config := helperConfig(t, helperService("web", "echo", `level=warn msg="disk full"`), quiet)
+	config.LogForward = &providers.LogForwardConfig{Syslog: &providers.SyslogConfig{
+		Network: "udp", Address: conn.LocalAddr().String(), Facility: "local0", Hostname: "box",
+	}}
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, config, out, log)
+	require.NoError(t, s.Run(context.Background()))
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 2048)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Regexp(t, `^<132>1 \S+Z box web \d+ stdout - disk full$`, string(buf[:n]))
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(100*time.Millisecond)))
+	_, _, err = conn.ReadFrom(buf)
+	assert.Error(t, err, "services with forward: false are not forwarded")
+}
+
+func TestNew_InvalidForwarder(t *testing.T) {
+	config := helperConfig(t, helperService("web", "echo", "x"))
+	config.LogForward = &providers.LogForwardConfig{OTLP: &providers.OTLPConfig{Endpoint: "collector"}}
+	_, err := New(config, nil)
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "log_forward: otlp: invalid OTLP endpoint"))
+}
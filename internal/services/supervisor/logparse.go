@@ -0,0 +1,185 @@
+// internal/services/supervisor/logparse.go - Structured parsing of the service output
+package supervisor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+)
+
+// messageKeys hold the message of a structured line, by priority
+var messageKeys = []string{"msg", "message"}
+
+// levelKeys hold the level of a structured line, by priority
+var levelKeys = []string{"level", "lvl", "severity"}
+
+// LogRecord is the unified form of a line, written by the json log format
+// and forwarded to syslog and OTLP.
+type LogRecord struct {
+	// Time is when the line was read
+	Time time.Time `json:"time"`
+	// Service is the service name
+	Service string `json:"service"`
+	// PID is the process that wrote the line
+	PID int `json:"pid,omitempty"`
+	// Stream is stdout or stderr
+	Stream string `json:"stream"`
+	// Level is the level written by the service, when the line is structured
+	Level string `json:"level,omitempty"`
+	// Message is the msg or message field of a structured line, or the line itself
+	Message string `json:"message"`
+	// Fields are the other fields of a structured line
+	Fields map[string]any `json:"fields,omitempty"`
+}
+
+// Record returns the unified form of the line.
+//
+// Returns:
+//   - record: LogRecord with the message and level taken out of the parsed fields
+func (l LogLine) Record() LogRecord {
+	record := LogRecord{Time: l.Time, Service: l.Service, PID: l.PID, Stream: l.Stream, Message: l.Text}
+	if len(l.Fields) == 0 {
+		return record
+	}
+
+	record.Fields = make(map[string]any, len(l.Fields))
+	for key, value := range l.Fields {
+		record.Fields[key] = value
+	}
+	if key, value, ok := takeField(record.Fields, messageKeys); ok {
+		if message, isString := value.(string); isString {
+			record.Message = message
+		} else {
+			record.Fields[key] = value
+		}
+	}
+	if _, value, ok := takeField(record.Fields, levelKeys); ok {
+		record.Level = fmt.Sprint(value)
+	}
+	if len(record.Fields) == 0 {
+		record.Fields = nil
+	}
+	return record
+}
+
+// takeField removes and returns the first of keys present in fields
+func takeField(fields map[string]any, keys []string) (string, any, bool) {
+	for _, key := range keys {
+		if value, ok := fields[key]; ok {
+			delete(fields, key)
+			return key, value, true
+		}
+	}
+	return "", nil, false
+}
+
+// Severity returns the normalized level of the record.
+//
+// Known level names and the numeric levels of pino and bunyan are mapped;
+// lines without a level are info on stdout and error on stderr.
+//
+// Returns:
+//   - severity: otlp.Severity base number of the level
+func (r LogRecord) Severity() otlp.Severity {
+	switch strings.ToLower(r.Level) {
+	case "trace", "10":
+		return otlp.SeverityTrace
+	case "debug", "dbg", "20":
+		return otlp.SeverityDebug
+	case "info", "information", "notice", "30":
+		return otlp.SeverityInfo
+	case "warn", "warning", "40":
+		return otlp.SeverityWarn
+	case "error", "err", "50":
+		return otlp.SeverityError
+	case "fatal", "critical", "crit", "panic", "alert", "emerg", "emergency", "60":
+		return otlp.SeverityFatal
+	}
+	if r.Stream == StreamStderr {
+		return otlp.SeverityError
+	}
+	return otlp.SeverityInfo
+}
+
+// parseFields returns the fields of a JSON object or logfmt line, nil for other lines
+func parseFields(text string) map[string]any {
+	trimmed := strings.TrimSpace(text)
+	if strings.HasPrefix(trimmed, "{") && strings.HasSuffix(trimmed, "}") {
+		decoder := json.NewDecoder(bytes.NewReader([]byte(trimmed)))
+		decoder.UseNumber()
+		var fields map[string]any
+		if err := decoder.Decode(&fields); err == nil && len(fields) > 0 && !decoder.More() {
+			return fields
+		}
+		return nil
+	}
+	if strings.Contains(trimmed, "=") {
+		return parseLogfmt(trimmed)
+	}
+	return nil
+}
+
+// parseLogfmt parses key=value pairs separated by spaces, values optionally
+// quoted; nil unless every word is a pair, so plain sentences are left alone
+func parseLogfmt(text string) map[string]any {
+	fields := make(map[string]any)
+	for i := 0; i < len(text); {
+		if text[i] == ' ' || text[i] == '\t' {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(text) && isLogfmtKey(text[i]) {
+			i++
+		}
+		if i == start || i == len(text) || text[i] != '=' {
+			return nil
+		}
+		key := text[start:i]
+		i++
+
+		if i < len(text) && text[i] == '"' {
+			end := i + 1
+			for end < len(text) && text[end] != '"' {
+				if text[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(text) {
+				return nil
+			}
+			value, err := strconv.Unquote(text[i : end+1])
+			if err != nil {
+				return nil
+			}
+			fields[key] = value
+			i = end + 1
+			if i < len(text) && text[i] != ' ' && text[i] != '\t' {
+				return nil
+			}
+			continue
+		}
+
+		start = i
+		for i < len(text) && text[i] != ' ' && text[i] != '\t' {
+			i++
+		}
+		fields[key] = text[start:i]
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// isLogfmtKey reports whether c may appear in a logfmt key
+func isLogfmtKey(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-' || c == '/'
+}
@@ -0,0 +1,65 @@
+package supervisor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFields(t *testing.T) {
+	tests := map[string]map[string]any{
+		`{"msg":"ready","port":8080}`:                    {"msg": "ready", "port": json.Number("8080")},
+		`  {"nested":{"a":true}}  `:                      {"nested": map[string]any{"a": true}},
+		`level=info msg="listening on :80" addr=:80`:     {"level": "info", "msg": "listening on :80", "addr": ":80"},
+		`ts=2026-01-01T00:00:00Z  user.id=7 q="a \"b\""`: {"ts": "2026-01-01T00:00:00Z", "user.id": "7", "q": `a "b"`},
+		`url=http://x/?a=b`:                              {"url": "http://x/?a=b"},
+		"plain text":                                     nil,
+		"listening on port=80":                           nil,
+		`{"broken":`:                                     nil,
+		`{}`:                                             nil,
+		`{"a":1} {"b":2}`:                                nil,
+		`msg="unterminated`:                              nil,
+		`msg="a"b`:                                       nil,
+		`=value`:                                         nil,
+	}
+	for text, want := range tests {
+		assert.Equal(t, want, parseFields(text), text)
+	}
+}
+
+func TestLogLine_Record(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	line := LogLine{Time: at, Service: "api", Stream: StreamStdout, PID: 7, Text: "raw",
+		Fields: map[string]any{"message": "hello", "lvl": "debug", "user": "ann"}}
+
+	record := line.Record()
+	assert.Equal(t, LogRecord{Time: at, Service: "api", PID: 7, Stream: StreamStdout, Level: "debug", Message: "hello",
+		Fields: map[string]any{"user": "ann"}}, record)
+	assert.Len(t, line.Fields, 3, "the line fields are left untouched")
+
+	record = LogLine{Text: "x", Fields: map[string]any{"msg": json.Number("1"), "level": json.Number("50")}}.Record()
+	assert.Equal(t, "x", record.Message, "a non-string message stays a field")
+	assert.Equal(t, map[string]any{"msg": json.Number("1")}, record.Fields)
+	assert.Equal(t, otlp.SeverityError, record.Severity())
+
+	record = LogLine{Text: "only", Fields: map[string]any{"msg": "only"}}.Record()
+	assert.Nil(t, record.Fields)
+
+	assert.JSONEq(t, `{"time":"2026-01-01T00:00:00Z","service":"api","pid":7,"stream":"stdout","level":"debug","message":"hello","fields":{"user":"ann"}}`,
+		line.JSON())
+}
+
+func TestLogRecord_Severity(t *testing.T) {
+	tests := map[string]otlp.Severity{
+		"TRACE": otlp.SeverityTrace, "debug": otlp.SeverityDebug, "info": otlp.SeverityInfo, "30": otlp.SeverityInfo,
+		"Warning": otlp.SeverityWarn, "err": otlp.SeverityError, "panic": otlp.SeverityFatal, "60": otlp.SeverityFatal,
+	}
+	for level, want := range tests {
+		assert.Equal(t, want, LogRecord{Level: level}.Severity(), level)
+	}
+	assert.Equal(t, otlp.SeverityInfo, LogRecord{Stream: StreamStdout}.Severity())
+	assert.Equal(t, otlp.SeverityError, LogRecord{Stream: StreamStderr, Level: "unknown"}.Severity())
+}
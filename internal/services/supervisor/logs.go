@@ -0,0 +1,437 @@
+// internal/services/supervisor/logs.go - Recent output of the supervised services
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+const (
+	// defaultLogLines is the number of output lines kept per service
+	defaultLogLines = 1000
+	// maxLogLine splits longer lines so a process without newlines cannot grow the buffer
+	maxLogLine = 16 << 10
+	// followBuffer is the number of lines queued for a slow follower before lines are dropped
+	followBuffer = 1024
+	// minMaskedSecret is the length under which a secret is not masked, it would hide common words
+	minMaskedSecret = 4
+	// secretMask replaces the secrets in the output
+	secretMask = "****"
+)
+
+// Output streams of a service.
+const (
+	// StreamStdout is the standard output
+	StreamStdout = "stdout"
+	// StreamStderr is the standard error
+	StreamStderr = "stderr"
+)
+
+// LogLine is one line of output of a service.
+type LogLine struct {
+	// Time is when the line was read
+	Time time.Time `json:"time" yaml:"time"`
+	// Service is the service name
+	Service string `json:"service" yaml:"service"`
+	// Stream is stdout or stderr
+	Stream string `json:"stream" yaml:"stream"`
+	// Text is the line without its newline, or the joined lines of a multi-line entry
+	Text string `json:"text" yaml:"text"`
+	// PID is the process that wrote the line
+	PID int `json:"pid,omitempty" yaml:"pid,omitempty"`
+	// Fields are the key-values of a JSON or logfmt line
+	Fields map[string]any `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// Format returns the line prefixed with the service name.
+//
+// Returns:
+//   - line: string such as "web | listening on :8080" without a trailing newline
+func (l LogLine) Format() string {
+	return l.Service + " | " + l.Text
+}
+
+// JSON returns the unified record of the line as a JSON object.
+//
+// Returns:
+//   - line: string JSON object without a trailing newline
+func (l LogLine) JSON() string {
+	record := l.Record()
+	record.Time = record.Time.UTC()
+	data, err := json.Marshal(record)
+	if err != nil {
+		// A field decoded from JSON always encodes back, keep the line anyway
+		record.Fields = nil
+		data, _ = json.Marshal(record) //nolint:errcheck // only strings and numbers left
+	}
+	return string(data)
+}
+
+// logSink receives every line of a service, serialized
+type logSink interface {
+	// writeLine records a complete line
+	writeLine(line LogLine)
+	// close releases the sink once the service output has ended for good
+	close() error
+}
+
+// mirrorSink copies lines to the svz output, prefixed with the service name,
+// or as JSON records on stdout
+type mirrorSink struct {
+	stdout io.Writer
+	stderr io.Writer
+	json   bool
+}
+
+// writeLine writes the line to the writer of its stream; output is best effort
+func (m *mirrorSink) writeLine(line LogLine) {
+	if m.json {
+		_, _ = io.WriteString(m.stdout, line.JSON()+"\n") //nolint:errcheck // output is best effort
+		return
+	}
+	dst := m.stdout
+	if line.Stream == StreamStderr {
+		dst = m.stderr
+	}
+	_, _ = io.WriteString(dst, line.Format()+"\n") //nolint:errcheck // output is best effort
+}
+
+// close does nothing, the svz output outlives the services
+func (m *mirrorSink) close() error {
+	return nil
+}
+
+// logSinks returns the sinks configured for a service: the svz output, the log file and the forwarders
+func (s *Supervisor) logSinks(config *providers.ServiceConfig) []logSink {
+	var sinks []logSink
+	if config.Logs.MirrorEnabled() {
+		sinks = append(sinks, &mirrorSink{stdout: s.stdout, stderr: s.stderr, json: config.Logs.JSONEnabled()})
+	}
+	if config.Logs.Dir != "" {
+		name := config.Name
+		sinks = append(sinks, newLogFile(name, &config.Logs, func(err error) {
+			_, _ = fmt.Fprintf(s.stderr, "log file for service %s: %v\n", name, err) //nolint:errcheck // output is best effort
+		}))
+	}
+	if config.Logs.ForwardEnabled() {
+		for _, forwarder := range s.forwarders {
+			sinks = append(sinks, forwarder)
+		}
+	}
+	return sinks
+}
+
+// pendingEntry is a multi-line entry waiting for its continuation lines
+type pendingEntry struct {
+	// line holds the joined lines
+	line LogLine
+	// lines counts the joined lines
+	lines int
+	// deadline ends the entry when no line arrived before it
+	deadline time.Time
+}
+
+// logBuffer keeps the last lines written by a service, feeds followers and
+// hands every line to its sinks, after joining multi-line entries and
+// parsing structured lines.
+type logBuffer struct {
+	// service names the lines
+	service string
+	// sinks receive every line
+	sinks []logSink
+	// entryStart matches the first line of a multi-line entry, nil when lines are not joined
+	entryStart *regexp.Regexp
+	// entryMaxLines ends an entry once it holds this many lines
+	entryMaxLines int
+	// entryTimeout ends an entry when no line follows for this long
+	entryTimeout time.Duration
+	// mu guards every field below
+	mu sync.Mutex
+	// pid is the process writing the lines
+	pid int
+	// secrets masks the secret values of the process environment, nil when there are none
+	secrets *strings.Replacer
+	// entries holds the multi-line entry in progress of each stream
+	entries map[string]*pendingEntry
+	// lines is a ring of at most size lines, oldest at start
+	lines []LogLine
+	// start is the position of the oldest line once the ring is full
+	start int
+	// size is the capacity of the ring
+	size int
+	// partial holds the unterminated line of each stream
+	partial map[string][]byte
+	// followers receive every new line
+	followers map[chan LogLine]struct{}
+}
+
+// newLogBuffer creates a buffer keeping size lines and forwarding them to sinks;
+// multiline joins the lines of an entry when not nil
+func newLogBuffer(service string, size int, multiline *providers.MultilineConfig, sinks ...logSink) *logBuffer {
+	b := &logBuffer{
+		service:   service,
+		sinks:     sinks,
+		size:      size,
+		entries:   make(map[string]*pendingEntry, 2),
+		partial:   make(map[string][]byte, 2),
+		followers: make(map[chan LogLine]struct{}),
+	}
+	if multiline != nil {
+		start, err := multiline.StartPattern()
+		if err != nil {
+			// The configuration was validated, fall back to the default for hand-built ones
+			start = regexp.MustCompile(providers.DefaultMultilineStart)
+		}
+		b.entryStart, b.entryMaxLines, b.entryTimeout = start, multiline.MaxLines, multiline.Timeout
+		if b.entryMaxLines <= 0 {
+			b.entryMaxLines = providers.DefaultMultilineMaxLines
+		}
+		if b.entryTimeout <= 0 {
+			b.entryTimeout = providers.DefaultMultilineTimeout
+		}
+	}
+	return b
+}
+
+// setPID records the process writing the next lines
+func (b *logBuffer) setPID(pid int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pid = pid
+}
+
+// mask replaces the given secret values with a mask in the next lines
+func (b *logBuffer) mask(secrets []string) {
+	// Longer secrets first, so a secret containing another is masked whole
+	sorted := append([]string(nil), secrets...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	pairs := make([]string, 0, 2*len(sorted))
+	for _, secret := range sorted {
+		if len(secret) >= minMaskedSecret {
+			pairs = append(pairs, secret, secretMask)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.secrets = nil
+	if len(pairs) > 0 {
+		b.secrets = strings.NewReplacer(pairs...)
+	}
+}
+
+// redact masks the secrets in text
+func (b *logBuffer) redact(text string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.secrets == nil {
+		return text
+	}
+	return b.secrets.Replace(text)
+}
+
+// logWriter records one stream into a logBuffer
+type logWriter struct {
+	buffer *logBuffer
+	stream string
+}
+
+// Write records the lines of p; it never fails, so a broken sink cannot
+// block the process on a full pipe
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.buffer.append(w.stream, p)
+	return len(p), nil
+}
+
+// writer returns a writer recording stream
+func (b *logBuffer) writer(stream string) io.Writer {
+	return &logWriter{buffer: b, stream: stream}
+}
+
+// close ends the pending entries and closes the sinks
+func (b *logBuffer) close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.endEntry(StreamStdout)
+	b.endEntry(StreamStderr)
+	errs := make([]error, 0, len(b.sinks))
+	for _, sink := range b.sinks {
+		errs = append(errs, sink.close())
+	}
+	return errors.Join(errs...)
+}
+
+// append splits p into lines, completing the partial line of stream
+func (b *logBuffer) append(stream string, p []byte) {
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	data := append(b.partial[stream], p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		b.collect(LogLine{Time: now, Service: b.service, Stream: stream, Text: string(bytes.TrimSuffix(data[:i], []byte("\r"))), PID: b.pid})
+		data = data[i+1:]
+	}
+	for len(data) >= maxLogLine {
+		b.collect(LogLine{Time: now, Service: b.service, Stream: stream, Text: string(data[:maxLogLine]), PID: b.pid})
+		data = data[maxLogLine:]
+	}
+	b.partial[stream] = append([]byte(nil), data...)
+}
+
+// flush records the unterminated lines and the pending entries, once the process ended
+func (b *logBuffer) flush() {
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, stream := range []string{StreamStdout, StreamStderr} {
+		if len(b.partial[stream]) > 0 {
+			b.collect(LogLine{Time: now, Service: b.service, Stream: stream, Text: string(b.partial[stream]), PID: b.pid})
+			b.partial[stream] = nil
+		}
+		b.endEntry(stream)
+	}
+}
+
+// collect adds a complete line, or joins it to the entry in progress of its
+// stream when it does not start a new entry; b.mu must be held
+func (b *logBuffer) collect(line LogLine) {
+	if b.entryStart == nil {
+		b.add(line)
+		return
+	}
+
+	entry := b.entries[line.Stream]
+	if entry != nil && entry.lines < b.entryMaxLines && !b.entryStart.MatchString(line.Text) {
+		entry.line.Text += "\n" + line.Text
+		entry.lines++
+		entry.deadline = line.Time.Add(b.entryTimeout)
+		return
+	}
+
+	b.endEntry(line.Stream)
+	entry = &pendingEntry{line: line, lines: 1, deadline: line.Time.Add(b.entryTimeout)}
+	b.entries[line.Stream] = entry
+	b.expireEntry(line.Stream, entry, b.entryTimeout)
+}
+
+// expireEntry ends entry once its deadline passed without a new line
+func (b *logBuffer) expireEntry(stream string, entry *pendingEntry, after time.Duration) {
+	time.AfterFunc(after, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if b.entries[stream] != entry {
+			return
+		}
+		if remaining := time.Until(entry.deadline); remaining > 0 {
+			b.expireEntry(stream, entry, remaining)
+			return
+		}
+		b.endEntry(stream)
+	})
+}
+
+// endEntry adds the entry in progress of stream, if any; b.mu must be held
+func (b *logBuffer) endEntry(stream string) {
+	if entry := b.entries[stream]; entry != nil {
+		delete(b.entries, stream)
+		b.add(entry.line)
+	}
+}
+
+// add masks the secrets of a line, parses it, stores it, hands it to the sinks and to the followers,
+// dropping it for those lagging behind; b.mu must be held
+func (b *logBuffer) add(line LogLine) {
+	if b.secrets != nil {
+		line.Text = b.secrets.Replace(line.Text)
+	}
+	line.Fields = parseFields(line.Text)
+	for _, sink := range b.sinks {
+		sink.writeLine(line)
+	}
+	if len(b.lines) < b.size {
+		b.lines = append(b.lines, line)
+	} else {
+		b.lines[b.start] = line
+		b.start = (b.start + 1) % b.size
+	}
+	for follower := range b.followers {
+		select {
+		case follower <- line:
+		default:
+		}
+	}
+}
+
+// tailLocked returns the last n lines, all of them when n <= 0
+func (b *logBuffer) tailLocked(n int) []LogLine {
+	ordered := append(append(make([]LogLine, 0, len(b.lines)), b.lines[b.start:]...), b.lines[:b.start]...)
+	if n > 0 && n < len(ordered) {
+		ordered = ordered[len(ordered)-n:]
+	}
+	return ordered
+}
+
+// tail returns the last n lines, all of them when n <= 0
+func (b *logBuffer) tail(n int) []LogLine {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tailLocked(n)
+}
+
+// follow returns the last n lines followed by every new line until ctx is done.
+//
+// The channel is closed once ctx is done.
+func (b *logBuffer) follow(ctx context.Context, n int) <-chan LogLine {
+	live := make(chan LogLine, followBuffer)
+	b.mu.Lock()
+	backlog := b.tailLocked(n)
+	b.followers[live] = struct{}{}
+	b.mu.Unlock()
+
+	out := make(chan LogLine)
+	go func() {
+		defer close(out)
+		defer func() {
+			b.mu.Lock()
+			delete(b.followers, live)
+			b.mu.Unlock()
+		}()
+
+		for _, line := range backlog {
+			select {
+			case out <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		for {
+			select {
+			case line := <-live:
+				select {
+				case out <- line:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,150 @@
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// texts returns the text of each line
+func texts(lines []LogLine) []string {
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		out = append(out, line.Text)
+	}
+	return out
+}
+
+func TestLogBuffer_SplitsLines(t *testing.T) {
+	var mirrorOut, mirrorErr strings.Builder
+	buffer := newLogBuffer("web", 10, nil, &mirrorSink{stdout: &mirrorOut, stderr: &mirrorErr})
+	stdout := buffer.writer(StreamStdout)
+	stderr := buffer.writer(StreamStderr)
+
+	_, _ = stdout.Write([]byte("one\r\ntw"))
+	_, _ = stderr.Write([]byte("oops\n"))
+	_, _ = stdout.Write([]byte("o\nthree"))
+	assert.Equal(t, []string{"one", "oops", "two"}, texts(buffer.tail(0)))
+
+	buffer.flush()
+	lines := buffer.tail(0)
+	assert.Equal(t, []string{"one", "oops", "two", "three"}, texts(lines))
+	assert.Equal(t, StreamStderr, lines[1].Stream)
+	assert.Equal(t, "web", lines[3].Service)
+	assert.Equal(t, "web | one\nweb | two\nweb | three\n", mirrorOut.String(), "lines are mirrored with the service name")
+	assert.Equal(t, "web | oops\n", mirrorErr.String(), "to the writer of their stream")
+	require.NoError(t, buffer.close())
+}
+
+func TestLogBuffer_Ring(t *testing.T) {
+	buffer := newLogBuffer("web", 3, nil)
+	for _, text := range []string{"a", "b", "c", "d", "e"} {
+		buffer.append(StreamStdout, []byte(text+"\n"))
+	}
+	assert.Equal(t, []string{"c", "d", "e"}, texts(buffer.tail(0)))
+	assert.Equal(t, []string{"d", "e"}, texts(buffer.tail(2)))
+	assert.Equal(t, []string{"c", "d", "e"}, texts(buffer.tail(10)))
+}
+
+func TestLogBuffer_LongLine(t *testing.T) {
+	buffer := newLogBuffer("web", 10, nil)
+	buffer.append(StreamStdout, []byte(strings.Repeat("x", maxLogLine+5)))
+	lines := buffer.tail(0)
+	require.Len(t, lines, 1)
+	assert.Len(t, lines[0].Text, maxLogLine)
+	buffer.flush()
+	assert.Equal(t, "xxxxx", buffer.tail(1)[0].Text)
+}
+
+func TestLogBuffer_Follow(t *testing.T) {
+	buffer := newLogBuffer("web", 10, nil)
+	buffer.append(StreamStdout, []byte("old-1\nold-2\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := buffer.follow(ctx, 1)
+	buffer.append(StreamStdout, []byte("new\n"))
+
+	var got []string
+	for len(got) < 2 {
+		select {
+		case line := <-stream:
+			got = append(got, line.Text)
+		case <-time.After(5 * time.Second):
+			t.Fatal("no line followed")
+		}
+	}
+	assert.Equal(t, []string{"old-2", "new"}, got)
+
+	cancel()
+	for range stream {
+	}
+	buffer.mu.Lock()
+	defer buffer.mu.Unlock()
+	assert.Empty(t, buffer.followers, "the follower is removed once ctx is done")
+}
+
+func TestLogLine_Format(t *testing.T) {
+	assert.Equal(t, "web | ready", LogLine{Service: "web", Text: "ready"}.Format())
+}
+
+func TestLogBuffer_JSONMirror(t *testing.T) {
+	var mirrorOut, mirrorErr strings.Builder
+	buffer := newLogBuffer("web", 10, nil, &mirrorSink{stdout: &mirrorOut, stderr: &mirrorErr, json: true})
+	buffer.setPID(42)
+	buffer.append(StreamStderr, []byte(`{"level":"warn","msg":"slow","ms":12}`+"\n"))
+	buffer.append(StreamStdout, []byte("plain\n"))
+
+	assert.Empty(t, mirrorErr.String(), "JSON records all go to stdout")
+	records := strings.Split(strings.TrimSpace(mirrorOut.String()), "\n")
+	require.Len(t, records, 2)
+	assert.Regexp(t, `^\{"time":"[^"]+Z","service":"web","pid":42,"stream":"stderr","level":"warn","message":"slow","fields":\{"ms":12\}\}$`, records[0])
+	assert.Regexp(t, `^\{"time":"[^"]+Z","service":"web","pid":42,"stream":"stdout","message":"plain"\}$`, records[1])
+
+	lines := buffer.tail(0)
+	assert.Equal(t, 42, lines[1].PID)
+	assert.Equal(t, map[string]any{"level": "warn", "msg": "slow", "ms": json.Number("12")}, lines[0].Fields)
+}
+
+func TestLogBuffer_JoinsMultilineEntries(t *testing.T) {
+	buffer := newLogBuffer("web", 10, &providers.MultilineConfig{MaxLines: 3, Timeout: time.Hour})
+	buffer.append(StreamStdout, []byte("Exception in thread main\n\tat A.run(A.java:1)\n\tat A.main(A.java:9)\n"))
+	buffer.append(StreamStderr, []byte("err line\n  detail\n"))
+	buffer.append(StreamStdout, []byte("next entry\n"))
+	assert.Equal(t, []string{"Exception in thread main\n\tat A.run(A.java:1)\n\tat A.main(A.java:9)"}, texts(buffer.tail(0)),
+		"an entry ends at the next start line, entries of the other stream wait")
+
+	buffer.append(StreamStdout, []byte("  one\n  two\n  three\n"))
+	assert.Equal(t, "next entry\n  one\n  two", buffer.tail(1)[0].Text, "an entry ends at max_lines")
+
+	buffer.flush()
+	assert.ElementsMatch(t, []string{"err line\n  detail", "  three"}, texts(buffer.tail(2)), "flush ends the pending entries")
+}
+
+func TestLogBuffer_MultilineTimeout(t *testing.T) {
+	buffer := newLogBuffer("web", 10, &providers.MultilineConfig{Start: `^\[`, Timeout: 20 * time.Millisecond})
+	buffer.append(StreamStdout, []byte("[1] panic\ngoroutine 1\n"))
+	assert.Empty(t, buffer.tail(0))
+	assert.Eventually(t, func() bool { return len(buffer.tail(0)) == 1 }, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, "[1] panic\ngoroutine 1", buffer.tail(0)[0].Text)
+	require.NoError(t, buffer.close())
+}
+
+func TestLogBuffer_MasksSecrets(t *testing.T) {
+	var mirror strings.Builder
+	buffer := newLogBuffer("web", 10, nil, &mirrorSink{stdout: &mirror, stderr: &mirror})
+	buffer.mask([]string{"tok", "s3cret", "s3cret-pw"})
+
+	_, _ = buffer.writer(StreamStdout).Write([]byte("login with s3cret-pw, then s3cret, tok\n"))
+	assert.Equal(t, []string{"login with ****, then ****, tok"}, texts(buffer.tail(0)), "secrets shorter than 4 bytes are kept")
+	assert.Equal(t, "web | login with ****, then ****, tok\n", mirror.String())
+	assert.Equal(t, "probe said ****", buffer.redact("probe said s3cret"))
+
+	buffer.mask(nil)
+	assert.Equal(t, "probe said s3cret", buffer.redact("probe said s3cret"))
+}
@@ -0,0 +1,134 @@
+// internal/services/supervisor/metrics.go - Runtime measurements of the supervised services
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Probe kinds reported in ServiceMetrics.Probes.
+const (
+	// ProbeStartup is the startup probe
+	ProbeStartup = "startup"
+	// ProbeLiveness is the liveness probe
+	ProbeLiveness = "liveness"
+	// ProbeReadiness is the readiness probe
+	ProbeReadiness = "readiness"
+)
+
+// ProbeMetrics measures the checks of one probe.
+type ProbeMetrics struct {
+	// Duration is how long the last check took
+	Duration time.Duration
+	// Checks counts the checks run
+	Checks uint64
+	// Failures counts the failed checks
+	Failures uint64
+}
+
+// ProcessStats is the resource usage of the main process of a service.
+type ProcessStats struct {
+	// CPU is the user and system CPU time consumed
+	CPU time.Duration
+	// RSS is the resident memory in bytes
+	RSS uint64
+	// OpenFDs is the number of open file descriptors, -1 when they cannot be listed
+	OpenFDs int
+}
+
+// ServiceMetrics is a snapshot of the measurements of a service.
+type ServiceMetrics struct {
+	ServiceStatus
+	// StartedAt is when the current process started, zero when no process runs
+	StartedAt time.Time
+	// Exits counts the process exits by exit code, -1 for a signal
+	Exits map[int]uint64
+	// Probes holds the measurements of each probe kind that ran
+	Probes map[string]ProbeMetrics
+	// Process is the resource usage of the current process, nil when none runs
+	// or the platform does not expose it
+	Process *ProcessStats
+}
+
+// Metrics returns the measurements of every service in start order.
+//
+// Resource usage is read from /proc/<pid> on Linux and left out elsewhere.
+//
+// Returns:
+//   - metrics: []ServiceMetrics one entry per service
+func (s *Supervisor) Metrics() []ServiceMetrics {
+	runners := s.services()
+	metrics := make([]ServiceMetrics, 0, len(runners))
+	for _, r := range runners {
+		m := r.metrics()
+		if m.PID != 0 {
+			if stats, err := readProcessStats(m.PID); err == nil {
+				m.Process = &stats
+			}
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+// metrics returns a copy of the measurements of the runner
+func (r *runner) metrics() ServiceMetrics {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := ServiceMetrics{
+		ServiceStatus: r.status,
+		StartedAt:     r.startedAt,
+		Exits:         make(map[int]uint64, len(r.exits)),
+		Probes:        make(map[string]ProbeMetrics, len(r.probes)),
+	}
+	for code, count := range r.exits {
+		m.Exits[code] = count
+	}
+	for kind, probe := range r.probes {
+		m.Probes[kind] = *probe
+	}
+	return m
+}
+
+// processStarted records the start time of a new process
+func (r *runner) processStarted(at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.startedAt = at
+}
+
+// processExited counts the exit of the current process
+func (r *runner) processExited(code int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.startedAt = time.Time{}
+	r.exits[code]++
+}
+
+// measure wraps check to record its duration and outcome under kind
+func (r *runner) measure(kind string, check checkFunc) checkFunc {
+	return func(ctx context.Context) error {
+		start := time.Now()
+		err := check(ctx)
+		elapsed := time.Since(start)
+		if errors.Is(ctx.Err(), context.Canceled) {
+			// Cancelled with the process, not a probe outcome
+			return err
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		probe := r.probes[kind]
+		if probe == nil {
+			probe = &ProbeMetrics{}
+			r.probes[kind] = probe
+		}
+		probe.Duration = elapsed
+		probe.Checks++
+		if err != nil {
+			probe.Failures++
+		}
+		return err
+	}
+}
@@ -0,0 +1,80 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupervisor_Metrics(t *testing.T) {
+	var ready atomic.Bool
+	ready.Store(true)
+	service := helperService("web", "trap")
+	service.Probes.Readiness = fastProbe(healthServer(t, &ready), 1)
+	s := newTestSupervisor(t, helperConfig(t, service), &syncBuffer{}, &eventLog{})
+
+	metrics := s.Metrics()
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "web", metrics[0].Name)
+	assert.True(t, metrics[0].StartedAt.IsZero())
+	assert.Nil(t, metrics[0].Process)
+
+	stop := runInBackground(s)
+	require.Eventually(t, func() bool { return s.Metrics()[0].Probes[ProbeReadiness].Checks >= 2 }, 5*time.Second, 5*time.Millisecond)
+
+	m := s.Metrics()[0]
+	assert.Equal(t, StateHealthy, m.State)
+	assert.False(t, m.StartedAt.IsZero())
+	assert.Empty(t, m.Exits)
+	assert.Zero(t, m.Probes[ProbeReadiness].Failures)
+	assert.Positive(t, m.Probes[ProbeReadiness].Duration)
+	if runtime.GOOS == "linux" {
+		require.NotNil(t, m.Process)
+		assert.Positive(t, m.Process.RSS)
+		assert.Positive(t, m.Process.OpenFDs)
+	}
+
+	ready.Store(false)
+	require.Eventually(t, func() bool { return s.Metrics()[0].Probes[ProbeReadiness].Failures > 0 }, 5*time.Second, 5*time.Millisecond)
+
+	require.NoError(t, stop())
+	m = s.Metrics()[0]
+	assert.True(t, m.StartedAt.IsZero())
+	assert.Nil(t, m.Process)
+	assert.Equal(t, map[int]uint64{0: 1}, m.Exits)
+}
+
+func TestSupervisor_MetricsCountExits(t *testing.T) {
+	service := helperService("job", "exit", "3")
+	service.Restart = providers.RestartOnFailure
+	service.RestartPolicy.MaxRetries = 2
+	s := newTestSupervisor(t, helperConfig(t, service), &syncBuffer{}, &eventLog{})
+
+	err := s.Run(context.Background())
+	require.Error(t, err)
+	m := s.Metrics()[0]
+	assert.Equal(t, map[int]uint64{3: 3}, m.Exits)
+	assert.Equal(t, 2, m.Restarts)
+	require.NotNil(t, m.ExitCode)
+	assert.Equal(t, 3, *m.ExitCode)
+}
+
+func TestRunner_MeasureIgnoresCancellation(t *testing.T) {
+	r := &runner{probes: make(map[string]*ProbeMetrics)}
+	failing := r.measure(ProbeLiveness, func(context.Context) error { return errors.New("down") })
+
+	require.Error(t, failing(context.Background()))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Error(t, failing(ctx))
+
+	assert.Equal(t, uint64(1), r.probes[ProbeLiveness].Checks)
+	assert.Equal(t, uint64(1), r.probes[ProbeLiveness].Failures)
+}
@@ -0,0 +1,87 @@
+// internal/services/supervisor/otlplogs.go - OTLP logs forwarding of the service lines
+package supervisor
+
+import (
+	"context"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+const (
+	// otlpLogBatch is the largest number of records exported at once
+	otlpLogBatch = 512
+	// otlpLogInterval is the longest a line waits for its batch
+	otlpLogInterval = time.Second
+	// otlpScope names the instrumentation of the exported telemetry
+	otlpScope = "superviz"
+)
+
+// otlpLogExporter exports the lines as OTLP log records, one resource per service.
+type otlpLogExporter struct {
+	// client posts the batches
+	client *otlp.Client
+	// host is the host.name resource attribute, omitted when empty
+	host string
+}
+
+// newOTLPLogExporter creates an exporter for a collector
+func newOTLPLogExporter(config *providers.OTLPConfig, host string) (*otlpLogExporter, error) {
+	client, err := otlp.NewClient(&otlp.ClientOptions{Endpoint: config.Endpoint, Headers: config.Headers, Timeout: config.Timeout})
+	if err != nil {
+		return nil, err
+	}
+	return &otlpLogExporter{client: client, host: host}, nil
+}
+
+// export posts the lines grouped by service
+func (e *otlpLogExporter) export(ctx context.Context, lines []LogLine) error {
+	return e.client.Export(ctx, otlp.SignalLogs, e.logsData(lines))
+}
+
+// close does nothing, requests do not keep state
+func (e *otlpLogExporter) close() error {
+	return nil
+}
+
+// logsData converts the lines to an export request, services in order of first appearance
+func (e *otlpLogExporter) logsData(lines []LogLine) otlp.LogsData {
+	var data otlp.LogsData
+	index := make(map[string]int)
+	for _, line := range lines {
+		i, ok := index[line.Service]
+		if !ok {
+			attributes := []otlp.KeyValue{otlp.Attribute("service.name", line.Service)}
+			if e.host != "" {
+				attributes = append(attributes, otlp.Attribute("host.name", e.host))
+			}
+			i = len(data.ResourceLogs)
+			index[line.Service] = i
+			data.ResourceLogs = append(data.ResourceLogs, otlp.ResourceLogs{
+				Resource:  otlp.Resource{Attributes: attributes},
+				ScopeLogs: []otlp.ScopeLogs{{Scope: otlp.Scope{Name: otlpScope}}},
+			})
+		}
+		scope := &data.ResourceLogs[i].ScopeLogs[0]
+		scope.LogRecords = append(scope.LogRecords, otlpLogRecord(line.Record()))
+	}
+	return data
+}
+
+// otlpLogRecord converts a record, its fields becoming attributes next to the pid and the stream
+func otlpLogRecord(record LogRecord) otlp.LogRecord {
+	attributes := otlp.Attributes(record.Fields)
+	attributes = append(attributes, otlp.Attribute("log.iostream", record.Stream))
+	if record.PID != 0 {
+		attributes = append(attributes, otlp.Attribute("process.pid", record.PID))
+	}
+	return otlp.LogRecord{
+		TimeUnixNano:         otlp.UnixNano(record.Time),
+		ObservedTimeUnixNano: otlp.UnixNano(record.Time),
+		SeverityNumber:       record.Severity(),
+		SeverityText:         record.Level,
+		Body:                 otlp.StringValue(record.Message),
+		Attributes:           attributes,
+	}
+}
@@ -0,0 +1,122 @@
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// otlpReceiver is an in-process stand-in for an OTLP/HTTP collector
+type otlpReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []otlp.LogsData
+}
+
+// newOTLPReceiver starts a receiver decoding the logs export requests
+func newOTLPReceiver(t *testing.T) *otlpReceiver {
+	t.Helper()
+	r := &otlpReceiver{}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v1/logs" || req.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(req.Body)
+		var data otlp.LogsData
+		if err := json.Unmarshal(body, &data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.mu.Lock()
+		r.requests = append(r.requests, data)
+		r.mu.Unlock()
+		_, _ = w.Write([]byte("{}"))
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+// records returns the received records of a service
+func (r *otlpReceiver) records(service string) []otlp.LogRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var records []otlp.LogRecord
+	for _, data := range r.requests {
+		for _, resource := range data.ResourceLogs {
+			if *resource.Resource.Attributes[0].Value.StringValue != service {
+				continue
+			}
+			for _, scope := range resource.ScopeLogs {
+				records = append(records, scope.LogRecords...)
+			}
+		}
+	}
+	return records
+}
+
+func TestOTLPLogExporter_LogsData(t *testing.T) {
+	e, err := newOTLPLogExporter(&providers.OTLPConfig{Endpoint: "http://collector:4318"}, "box")
+	require.NoError(t, err)
+
+	db := LogLine{Time: logEpoch, Service: "db", Stream: StreamStderr, Text: "boom"}
+	web := LogLine{Time: logEpoch, Service: "web", Stream: StreamStdout, PID: 9, Text: `{"msg":"up","level":"debug","port":80}`}
+	web.Fields = parseFields(web.Text)
+	data := e.logsData([]LogLine{web, db, web})
+
+	encoded, err := json.Marshal(data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"resourceLogs":[
+		{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"web"}},{"key":"host.name","value":{"stringValue":"box"}}]},
+		 "scopeLogs":[{"scope":{"name":"superviz"},"logRecords":[
+			{"timeUnixNano":"1772359200000000000","observedTimeUnixNano":"1772359200000000000","severityNumber":5,"severityText":"debug",
+			 "body":{"stringValue":"up"},"attributes":[{"key":"port","value":{"intValue":"80"}},{"key":"log.iostream","value":{"stringValue":"stdout"}},{"key":"process.pid","value":{"intValue":"9"}}]},
+			{"timeUnixNano":"1772359200000000000","observedTimeUnixNano":"1772359200000000000","severityNumber":5,"severityText":"debug",
+			 "body":{"stringValue":"up"},"attributes":[{"key":"port","value":{"intValue":"80"}},{"key":"log.iostream","value":{"stringValue":"stdout"}},{"key":"process.pid","value":{"intValue":"9"}}]}
+		 ]}]},
+		{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"db"}},{"key":"host.name","value":{"stringValue":"box"}}]},
+		 "scopeLogs":[{"scope":{"name":"superviz"},"logRecords":[
+			{"timeUnixNano":"1772359200000000000","observedTimeUnixNano":"1772359200000000000","severityNumber":17,
+			 "body":{"stringValue":"boom"},"attributes":[{"key":"log.iostream","value":{"stringValue":"stderr"}}]}
+		 ]}]}
+	]}`, string(encoded))
+}
+
+func TestOTLPLogExporter_Export(t *testing.T) {
+	receiver := newOTLPReceiver(t)
+	e, err := newOTLPLogExporter(&providers.OTLPConfig{Endpoint: receiver.URL}, "")
+	require.NoError(t, err)
+
+	require.NoError(t, e.export(context.Background(), []LogLine{at(logEpoch, "hello")}))
+	records := receiver.records("web")
+	require.Len(t, records, 1)
+	assert.Equal(t, "hello", *records[0].Body.StringValue)
+	assert.NoError(t, e.close())
+
+	receiver.Close()
+	assert.Error(t, e.export(context.Background(), []LogLine{at(logEpoch, "lost")}))
+}
+
+func TestSupervisor_ForwardsToOTLP(t *testing.T) {
+	receiver := newOTLPReceiver(t)
+	config := helperConfig(t, helperService("web", "fail", "went wrong"))
+	config.LogForward = &providers.LogForwardConfig{OTLP: &providers.OTLPConfig{Endpoint: receiver.URL, Timeout: time.Second}}
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, config, out, log)
+	require.Error(t, s.Run(context.Background()))
+
+	records := receiver.records("web")
+	require.Len(t, records, 1, "the queued lines are exported when Run returns")
+	assert.Equal(t, "went wrong", *records[0].Body.StringValue)
+	assert.Equal(t, otlp.SeverityError, records[0].SeverityNumber)
+}
@@ -0,0 +1,309 @@
+// internal/services/supervisor/probe.go - Exec, HTTP, TCP and gRPC health checks
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+// maxProbeOutput bounds the command output or response body kept by a check
+const maxProbeOutput = 64 << 10
+
+// checkFunc runs one check and returns nil when it passes
+type checkFunc func(ctx context.Context) error
+
+// probeHTTPClient sends the HTTP checks; connections are not reused between checks
+var probeHTTPClient = &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+// probeGRPCClient speaks plaintext HTTP/2 with prior knowledge, as gRPC servers expect
+var probeGRPCClient = func() *http.Client {
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	return &http.Client{Transport: &http.Transport{Protocols: protocols, DisableKeepAlives: true}}
+}()
+
+// checker returns the check of a probe.
+//
+// Parameters:
+//   - service: *providers.ServiceConfig service probed, exec checks run in its directory and as its user
+//   - env: []string resolved environment of the service process, given to the exec checks
+//   - probe: *providers.Probe validated probe
+//
+// Returns:
+//   - check: checkFunc running one check
+func (s *Supervisor) checker(service *providers.ServiceConfig, env []string, probe *providers.Probe) checkFunc {
+	switch {
+	case probe.Exec != nil:
+		return func(ctx context.Context) error { return s.execCheck(ctx, service, env, probe.Exec) }
+	case probe.HTTP != nil:
+		return func(ctx context.Context) error { return httpCheck(ctx, probe.HTTP) }
+	case probe.TCP != nil:
+		return func(ctx context.Context) error { return tcpCheck(ctx, probe.TCP) }
+	default:
+		return func(ctx context.Context) error { return grpcCheck(ctx, probe.GRPC) }
+	}
+}
+
+// watchProbe runs probe until ctx is done or report returns true.
+//
+// report is called with nil when the probe starts passing, and with the last
+// check error when it starts failing.
+//
+// Parameters:
+//   - ctx: context.Context stopping the probe
+//   - probe: *providers.Probe delay, interval, timeout and thresholds
+//   - check: checkFunc one check
+//   - report: func(error) bool called on every change, true stops watching
+func watchProbe(ctx context.Context, probe *providers.Probe, check checkFunc, report func(err error) (stop bool)) {
+	timer := time.NewTimer(probe.InitialDelay)
+	defer timer.Stop()
+
+	var successes, failures int
+	var passing, failing bool
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, probe.Timeout)
+		err := check(checkCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err == nil {
+			successes, failures = successes+1, 0
+			if !passing && successes >= probe.SuccessThreshold {
+				passing, failing = true, false
+				if report(nil) {
+					return
+				}
+			}
+		} else {
+			successes, failures = 0, failures+1
+			if !failing && failures >= probe.FailureThreshold {
+				passing, failing = false, true
+				if report(err) {
+					return
+				}
+			}
+		}
+		timer.Reset(probe.Interval)
+	}
+}
+
+// execCheck runs the probe command as the service, in its environment, and passes on exit status 0
+func (s *Supervisor) execCheck(ctx context.Context, service *providers.ServiceConfig, env []string, probe *providers.ExecProbe) error {
+	output := &probeOutput{}
+	cmd := exec.Command(probe.Command, probe.Args...) //nolint:gosec // the command comes from the operator's configuration
+	cmd.Dir = service.WorkingDir
+	cmd.Env = env
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := configureProcess(cmd, service.User, service.Group); err != nil {
+		return err
+	}
+
+	err := s.execute(ctx, cmd)
+	if text := strings.TrimSpace(output.String()); err != nil && text != "" {
+		return fmt.Errorf("%w: %s", err, text)
+	}
+	return err
+}
+
+// httpCheck sends a GET request and checks the status and body
+func httpCheck(ctx context.Context, probe *providers.HTTPProbe) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probe.URL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "superviz-probe")
+
+	resp, err := probeHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // the body is only read
+
+	switch {
+	case probe.Status != 0 && resp.StatusCode != probe.Status:
+		return fmt.Errorf("status %d, expected %d", resp.StatusCode, probe.Status)
+	case probe.Status == 0 && (resp.StatusCode < 200 || resp.StatusCode >= 400):
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if probe.Body == "" {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeOutput))
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if !bytes.Contains(body, []byte(probe.Body)) {
+		return fmt.Errorf("response body does not contain %q", probe.Body)
+	}
+	return nil
+}
+
+// tcpCheck opens and closes a connection
+func tcpCheck(ctx context.Context, probe *providers.TCPProbe) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", probe.Address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// gRPC health protocol constants, see grpc/health/v1/health.proto
+const (
+	// grpcHealthPath is the Check method of the grpc.health.v1.Health service
+	grpcHealthPath = "/grpc.health.v1.Health/Check"
+	// grpcServing is the SERVING value of HealthCheckResponse.ServingStatus
+	grpcServing = 1
+)
+
+// grpcStatusNames maps HealthCheckResponse.ServingStatus values to their names
+var grpcStatusNames = map[uint64]string{0: "UNKNOWN", 1: "SERVING", 2: "NOT_SERVING", 3: "SERVICE_UNKNOWN"}
+
+// grpcCheck calls grpc.health.v1.Health/Check and passes when the status is SERVING.
+//
+// The request and response messages are tiny enough to be encoded by hand:
+// HealthCheckRequest has a single string field, HealthCheckResponse a single enum.
+func grpcCheck(ctx context.Context, probe *providers.GRPCProbe) error {
+	message := appendProtoString(nil, 1, probe.Service)
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message))) //nolint:gosec // the service name is short
+	frame = append(frame, message...)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+probe.Address+grpcHealthPath, bytes.NewReader(frame))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	req.Header.Set("User-Agent", "superviz-probe")
+
+	resp, err := probeGRPCClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // the body is only read
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProbeOutput))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	// grpc-status is a trailer, or a header in trailers-only responses
+	code := resp.Trailer.Get("Grpc-Status")
+	if code == "" {
+		code = resp.Header.Get("Grpc-Status")
+	}
+	if code != "0" {
+		message := resp.Trailer.Get("Grpc-Message")
+		if message == "" {
+			message = resp.Header.Get("Grpc-Message")
+		}
+		return fmt.Errorf("grpc status %s %s", code, message)
+	}
+
+	if len(body) < 5 || int(binary.BigEndian.Uint32(body[1:5])) != len(body)-5 {
+		return errors.New("malformed grpc response")
+	}
+	status, err := protoVarintField(body[5:], 1)
+	if err != nil {
+		return err
+	}
+	if status != grpcServing {
+		name, ok := grpcStatusNames[status]
+		if !ok {
+			name = fmt.Sprint(status)
+		}
+		return fmt.Errorf("serving status %s", name)
+	}
+	return nil
+}
+
+// appendProtoString appends a length-delimited protobuf field, omitted when empty as in proto3
+func appendProtoString(b []byte, field int, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = binary.AppendUvarint(b, uint64(field)<<3|2) //nolint:gosec // field numbers are small
+	b = binary.AppendUvarint(b, uint64(len(value)))
+	return append(b, value...)
+}
+
+// protoVarintField returns a varint field of a protobuf message, 0 when absent as in proto3
+func protoVarintField(message []byte, field int) (uint64, error) {
+	var value uint64
+	for len(message) > 0 {
+		key, n := binary.Uvarint(message)
+		if n <= 0 {
+			return 0, errors.New("malformed protobuf message")
+		}
+		message = message[n:]
+
+		switch key & 7 {
+		case 0:
+			v, n := binary.Uvarint(message)
+			if n <= 0 {
+				return 0, errors.New("malformed protobuf varint")
+			}
+			message = message[n:]
+			if key>>3 == uint64(field) { //nolint:gosec // field numbers are small
+				value = v
+			}
+		case 2:
+			length, n := binary.Uvarint(message)
+			if n <= 0 || length > uint64(len(message)-n) {
+				return 0, errors.New("malformed protobuf field")
+			}
+			message = message[n+int(length):] //nolint:gosec // bounded by the message length
+		default:
+			return 0, fmt.Errorf("unsupported protobuf wire type %d", key&7)
+		}
+	}
+	return value, nil
+}
+
+// probeOutput keeps the first bytes written by an exec check
+type probeOutput struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write keeps p up to maxProbeOutput bytes and never fails
+func (o *probeOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if room := maxProbeOutput - o.buf.Len(); room > 0 {
+		o.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+// String returns the kept output
+func (o *probeOutput) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.String()
+}
@@ -0,0 +1,181 @@
+package supervisor
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// grpcHealthServer is a stand-in gRPC server answering grpc.health.v1.Health/Check
+// over plaintext HTTP/2, with the serving status of each service name
+func grpcHealthServer(t *testing.T, statuses map[string]uint64) string {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != grpcHealthPath || r.ProtoMajor != 2 || r.Header.Get("Content-Type") != "application/grpc" {
+			http.Error(w, "not a grpc health check", http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		service := ""
+		if len(body) > 7 {
+			// field 1, length-delimited, short name: tag, length, bytes
+			service = string(body[7:])
+		}
+
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+		status, ok := statuses[service]
+		if !ok {
+			w.Header().Set("Grpc-Status", "5")
+			w.Header().Set("Grpc-Message", "unknown service")
+			return
+		}
+		message := binary.AppendUvarint([]byte{0x08}, status)
+		frame := binary.BigEndian.AppendUint32([]byte{0}, uint32(len(message)))
+		_, _ = w.Write(append(frame, message...))
+		w.Header().Set("Grpc-Status", "0")
+	}))
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	t.Cleanup(server.Close)
+	return server.Listener.Addr().String()
+}
+
+func TestHTTPCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			_, _ = io.WriteString(w, `{"status":"ok"}`)
+		case "/accepted":
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.Error(w, "down", http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	require.NoError(t, httpCheck(ctx, &providers.HTTPProbe{URL: server.URL + "/ok"}))
+	require.NoError(t, httpCheck(ctx, &providers.HTTPProbe{URL: server.URL + "/ok", Status: 200, Body: `"ok"`}))
+	require.NoError(t, httpCheck(ctx, &providers.HTTPProbe{URL: server.URL + "/accepted"}))
+	assert.EqualError(t, httpCheck(ctx, &providers.HTTPProbe{URL: server.URL + "/accepted", Status: 200}), "status 202, expected 200")
+	assert.EqualError(t, httpCheck(ctx, &providers.HTTPProbe{URL: server.URL + "/down"}), "status 503")
+	assert.EqualError(t, httpCheck(ctx, &providers.HTTPProbe{URL: server.URL + "/ok", Body: "ready"}), `response body does not contain "ready"`)
+}
+
+func TestTCPCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+
+	require.NoError(t, tcpCheck(context.Background(), &providers.TCPProbe{Address: address}))
+	require.NoError(t, listener.Close())
+	assert.Error(t, tcpCheck(context.Background(), &providers.TCPProbe{Address: address}))
+}
+
+func TestGRPCCheck(t *testing.T) {
+	address := grpcHealthServer(t, map[string]uint64{"": 1, "db": 2})
+	ctx := context.Background()
+
+	require.NoError(t, grpcCheck(ctx, &providers.GRPCProbe{Address: address}))
+	assert.EqualError(t, grpcCheck(ctx, &providers.GRPCProbe{Address: address, Service: "db"}), "serving status NOT_SERVING")
+	assert.EqualError(t, grpcCheck(ctx, &providers.GRPCProbe{Address: address, Service: "cache"}), "grpc status 5 unknown service")
+}
+
+func TestExecCheck(t *testing.T) {
+	s, err := New(helperConfig(t, helperService("a", "exit", "0")), &Options{Environ: os.Environ()})
+	require.NoError(t, err)
+	service := s.config.Services["a"]
+	ctx := context.Background()
+
+	env := func(mode string) []string { return mergeEnv(s.environ, map[string]string{helperEnv: mode}) }
+
+	require.NoError(t, s.execCheck(ctx, service, env("exit"), &providers.ExecProbe{Command: os.Args[0], Args: []string{"0"}}))
+	assert.EqualError(t, s.execCheck(ctx, service, env("exit"), &providers.ExecProbe{Command: os.Args[0], Args: []string{"3"}}), "exit status 3")
+
+	assert.EqualError(t, s.execCheck(ctx, service, env("fail"), &providers.ExecProbe{Command: os.Args[0], Args: []string{"database down"}}), "exit status 1: database down")
+
+	timeout, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	assert.EqualError(t, s.execCheck(timeout, service, env("sleep"), &providers.ExecProbe{Command: os.Args[0], Args: []string{"1m"}}), "timed out")
+}
+
+func TestWatchProbe_Thresholds(t *testing.T) {
+	probe := &providers.Probe{Interval: time.Millisecond, Timeout: time.Second, SuccessThreshold: 2, FailureThreshold: 3}
+	// fail, fail, fail, pass, pass, fail, pass, pass
+	outcomes := []bool{false, false, false, true, true, false, true, true}
+	var calls atomic.Int32
+	check := func(context.Context) error {
+		n := int(calls.Add(1)) - 1
+		if n >= len(outcomes) || outcomes[n] {
+			return nil
+		}
+		return errors.New("down")
+	}
+
+	var reports []string
+	watchProbe(context.Background(), probe, check, func(err error) bool {
+		if err != nil {
+			reports = append(reports, "failing")
+		} else {
+			reports = append(reports, "passing")
+		}
+		return len(reports) == 2
+	})
+	assert.Equal(t, []string{"failing", "passing"}, reports)
+	assert.EqualValues(t, 5, calls.Load(), "reports once per change, after the thresholds")
+}
+
+func TestWatchProbe_InitialDelayAndCancel(t *testing.T) {
+	probe := &providers.Probe{InitialDelay: time.Minute, Interval: time.Millisecond, Timeout: time.Second, SuccessThreshold: 1, FailureThreshold: 1}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	watchProbe(ctx, probe, func(context.Context) error {
+		t.Error("checked before the initial delay")
+		return nil
+	}, func(error) bool { return true })
+}
+
+func TestProtoVarintField(t *testing.T) {
+	message := appendProtoString(nil, 2, "skipped")
+	message = append(message, 0x08, 0x02)
+
+	value, err := protoVarintField(message, 1)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, value)
+
+	value, err = protoVarintField(nil, 1)
+	require.NoError(t, err)
+	assert.Zero(t, value, "absent fields are zero")
+
+	_, err = protoVarintField([]byte{0x0a, 0x10}, 1)
+	assert.Error(t, err)
+	_, err = protoVarintField([]byte{0x0d, 0, 0, 0, 0}, 1)
+	assert.EqualError(t, err, "unsupported protobuf wire type 5")
+
+	assert.Empty(t, appendProtoString(nil, 1, ""))
+	assert.Equal(t, []byte{0x0a, 0x02, 'd', 'b'}, appendProtoString(nil, 1, "db"))
+}
+
+func TestProbeOutput_Bounded(t *testing.T) {
+	output := &probeOutput{}
+	n, err := output.Write(make([]byte, maxProbeOutput+10))
+	require.NoError(t, err)
+	assert.Equal(t, maxProbeOutput+10, n)
+	_, _ = output.Write([]byte("more"))
+	assert.Len(t, output.String(), maxProbeOutput)
+}
@@ -0,0 +1,254 @@
+// internal/services/supervisor/process.go - Process construction and waiting for supervised services
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+// outputWaitDelay bounds how long output is drained after a process exits,
+// in case a leftover grandchild keeps the pipes open
+const outputWaitDelay = time.Second
+
+// exitStatus describes how a process ended.
+type exitStatus struct {
+	// code is the exit code, -1 when the process was killed by a signal
+	code int
+	// desc reads like os.ProcessState.String(), e.g. "exit status 1" or "signal: killed"
+	desc string
+}
+
+// process is a started service process.
+type process struct {
+	// cmd is the started command
+	cmd *exec.Cmd
+	// pid is the process id, also the process group id on Unix
+	pid int
+	// reaped is closed as soon as the process has been waited for
+	reaped chan struct{}
+	// done is closed once the process has been reaped and its output drained
+	done chan struct{}
+	// status is set before reaped is closed
+	status exitStatus
+}
+
+// newProcess wraps a started command
+func newProcess(cmd *exec.Cmd) *process {
+	return &process{cmd: cmd, pid: cmd.Process.Pid, reaped: make(chan struct{}), done: make(chan struct{})}
+}
+
+// reap records the exit status; the pid may be reused from now on
+func (p *process) reap(status exitStatus) {
+	p.status = status
+	close(p.reaped)
+}
+
+// exited reports whether the process has been reaped
+func (p *process) exited() bool {
+	select {
+	case <-p.reaped:
+		return true
+	default:
+		return false
+	}
+}
+
+// wait blocks until the process has ended and its output is drained, and returns its status
+func (p *process) wait() exitStatus {
+	<-p.done
+	return p.status
+}
+
+// command builds the process of a service with its resolved environment, ready to start
+func (s *Supervisor) command(config *providers.ServiceConfig, env []string) (*exec.Cmd, error) {
+	cmd := exec.Command(config.Command, config.Args...) //nolint:gosec // the command comes from the operator's configuration
+	cmd.Dir = config.WorkingDir
+	cmd.Env = env
+	cmd.Stdout = s.stdout
+	cmd.Stderr = s.stderr
+
+	if err := configureProcess(cmd, config.User, config.Group); err != nil {
+		return nil, fmt.Errorf("failed to resolve user and group: %w", err)
+	}
+	return cmd, nil
+}
+
+// start starts cmd and waits for it in the background.
+//
+// Output written to anything but a file goes through pipes owned by the
+// supervisor, so the process is marked reaped before its output is drained.
+// When the supervisor reaps every child, the reaper owns the wait so that
+// exec.Cmd.Wait never races with wait4(-1).
+func (s *Supervisor) start(cmd *exec.Cmd) (*process, error) {
+	pipes, err := pipeOutput(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var p *process
+	if s.reaper != nil {
+		p, err = s.reaper.start(cmd)
+	} else if err = cmd.Start(); err == nil {
+		p = newProcess(cmd)
+		go func() {
+			_ = cmd.Wait() //nolint:errcheck // the exit status is read from ProcessState
+			p.reap(exitStatus{code: cmd.ProcessState.ExitCode(), desc: cmd.ProcessState.String()})
+		}()
+	}
+	if err != nil {
+		pipes.close()
+		return nil, err
+	}
+
+	pipes.started()
+	go func() {
+		<-p.reaped
+		pipes.wait()
+		close(p.done)
+	}()
+	return p, nil
+}
+
+// execute runs a short-lived command to completion.
+//
+// The command is started like a service, so the reaper sees it; when ctx is
+// done first, its process group is killed.
+//
+// Parameters:
+//   - ctx: context.Context bounding the command
+//   - cmd: *exec.Cmd configured command, not started
+//
+// Returns:
+//   - err: error if the command cannot start, exits with a non-zero status or is killed
+func (s *Supervisor) execute(ctx context.Context, cmd *exec.Cmd) error {
+	p, err := s.start(cmd)
+	if err != nil {
+		return err
+	}
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		_ = signalGroup(p, os.Kill) //nolint:errcheck // the process may exit concurrently
+		p.wait()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return errors.New("timed out")
+		}
+		return ctx.Err()
+	}
+	if status := p.status; status.code != 0 {
+		return errors.New(status.desc)
+	}
+	return nil
+}
+
+// mergeEnv returns base with the service variables added or overriding, sorted by name
+func mergeEnv(base []string, env map[string]string) []string {
+	values := make(map[string]string, len(base)+len(env))
+	for _, entry := range base {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		values[key] = value
+	}
+	for key, value := range env {
+		values[key] = value
+	}
+
+	merged := make([]string, 0, len(values))
+	for key, value := range values {
+		merged = append(merged, key+"="+value)
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// outputPipes copies the output of a process to writers that are not files.
+//
+// exec.Cmd copies such writers itself but only releases the pipes in Wait,
+// which the reaper never calls.
+type outputPipes struct {
+	// parent are the read ends, copied to dst
+	parent []*os.File
+	// child are the write ends, closed once the child started
+	child []*os.File
+	// dst are the original writers
+	dst []io.Writer
+	// copies tracks the copy goroutines
+	copies sync.WaitGroup
+}
+
+// pipeOutput replaces the non-file Stdout and Stderr writers of cmd with pipes
+func pipeOutput(cmd *exec.Cmd) (*outputPipes, error) {
+	pipes := &outputPipes{}
+	for _, target := range []*io.Writer{&cmd.Stdout, &cmd.Stderr} {
+		w := *target
+		if w == nil {
+			continue
+		}
+		if _, ok := w.(*os.File); ok {
+			continue
+		}
+		r, pw, err := os.Pipe()
+		if err != nil {
+			pipes.close()
+			return nil, fmt.Errorf("failed to create output pipe: %w", err)
+		}
+		pipes.parent = append(pipes.parent, r)
+		pipes.child = append(pipes.child, pw)
+		pipes.dst = append(pipes.dst, w)
+		*target = pw
+	}
+	return pipes, nil
+}
+
+// started closes the child ends and starts copying
+func (p *outputPipes) started() {
+	for _, f := range p.child {
+		_ = f.Close() //nolint:errcheck // the child holds its own copy
+	}
+	for i, r := range p.parent {
+		p.copies.Add(1)
+		go func(r *os.File, w io.Writer) {
+			defer p.copies.Done()
+			_, _ = io.Copy(w, r) //nolint:errcheck // output is best effort
+		}(r, p.dst[i])
+	}
+}
+
+// wait drains the output for at most outputWaitDelay, then closes the pipes
+func (p *outputPipes) wait() {
+	drained := make(chan struct{})
+	go func() {
+		p.copies.Wait()
+		close(drained)
+	}()
+
+	timer := time.NewTimer(outputWaitDelay)
+	defer timer.Stop()
+	select {
+	case <-drained:
+	case <-timer.C:
+	}
+	for _, r := range p.parent {
+		_ = r.Close() //nolint:errcheck // unblocks a pending copy
+	}
+	<-drained
+}
+
+// close releases every pipe, used when the process failed to start
+func (p *outputPipes) close() {
+	for _, f := range append(p.parent, p.child...) {
+		_ = f.Close() //nolint:errcheck // nothing was started
+	}
+}
@@ -0,0 +1,47 @@
+//go:build !unix
+
+// internal/services/supervisor/process_other.go - Credentials and signals on non-Unix platforms
+package supervisor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// shutdownSignals start an ordered shutdown of the services
+var shutdownSignals = []os.Signal{os.Interrupt}
+
+// forwardedSignals are relayed to every running service, none on this platform
+var forwardedSignals []os.Signal
+
+// reloadSignal reloads the configuration when Options.LoadConfig is set, none on this platform
+var reloadSignal os.Signal
+
+// signalByName returns the signal for a stop_signal name, only KILL is deliverable
+func signalByName(name string) (os.Signal, error) {
+	if name == "KILL" {
+		return os.Kill, nil
+	}
+	return nil, fmt.Errorf("signal %s is not supported on this platform", name)
+}
+
+// signalGroup sends sig to the process, process groups being a Unix feature
+func signalGroup(p *process, sig os.Signal) error {
+	if p.exited() {
+		return nil
+	}
+	if err := p.cmd.Process.Signal(sig); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+	return nil
+}
+
+// configureProcess rejects user and group switching, which requires Unix
+func configureProcess(_ *exec.Cmd, userName, groupName string) error {
+	if userName != "" || groupName != "" {
+		return errors.New("user and group are only supported on Unix")
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package supervisor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeEnv(t *testing.T) {
+	merged := mergeEnv([]string{"PATH=/bin", "HOME=/root", "broken"}, map[string]string{"HOME": "/srv", "PORT": "8080"})
+	assert.Equal(t, []string{"HOME=/srv", "PATH=/bin", "PORT=8080"}, merged)
+}
+
+func TestCommand_EnvAndWorkingDir(t *testing.T) {
+	env := helperService("env", "env", "GREETING")
+	env.Env["GREETING"] = &providers.EnvVar{Value: "bonjour"}
+	pwd := helperService("pwd", "pwd")
+	pwd.WorkingDir = t.TempDir()
+
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, env, pwd), out, log)
+	require.NoError(t, s.Run(context.Background()))
+
+	assert.Contains(t, out.String(), "bonjour\n")
+
+	want, err := filepath.EvalSymlinks(pwd.WorkingDir)
+	require.NoError(t, err)
+	found := false
+	for _, line := range strings.Split(out.String(), "\n") {
+		if got, err := filepath.EvalSymlinks(strings.TrimPrefix(line, "pwd | ")); err == nil && got == want {
+			found = true
+		}
+	}
+	assert.True(t, found, "pwd output %q should contain %s", out.String(), want)
+}
+
+func TestCommand_InheritsBaseEnvironment(t *testing.T) {
+	service := helperService("env", "env", "SVZ_BASE")
+	config := helperConfig(t, service)
+
+	out := &syncBuffer{}
+	s, err := New(config, &Options{Stdout: out, Stderr: out, Environ: []string{"SVZ_BASE=inherited"}})
+	require.NoError(t, err)
+	require.NoError(t, s.Run(context.Background()))
+	assert.Equal(t, "env | inherited\n", out.String())
+}
+
+func TestCommand_UnknownUser(t *testing.T) {
+	service := helperService("a", "exit", "0")
+	service.User = "svz-no-such-user"
+	s, err := New(helperConfig(t, service), &Options{Stdout: &syncBuffer{}})
+	require.NoError(t, err)
+
+	_, err = s.command(service, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "svz-no-such-user")
+}
+
+func TestCommand_CurrentUser(t *testing.T) {
+	service := helperService("a", "exit", "0")
+	service.User = "0"
+	if os.Getuid() != 0 {
+		t.Skip("switching to uid 0 requires root")
+	}
+	s, err := New(helperConfig(t, service), &Options{Stdout: &syncBuffer{}})
+	require.NoError(t, err)
+
+	cmd, err := s.command(service, nil)
+	require.NoError(t, err)
+	require.NotNil(t, cmd.SysProcAttr)
+	require.NoError(t, s.Run(context.Background()))
+}
+
+func TestSignalByName(t *testing.T) {
+	sig, err := signalByName("KILL")
+	require.NoError(t, err)
+	assert.Equal(t, os.Kill, sig)
+
+	_, err = signalByName("BOGUS")
+	require.Error(t, err)
+}
@@ -0,0 +1,151 @@
+//go:build unix
+
+// internal/services/supervisor/process_unix.go - Process groups, credentials and signals on Unix
+package supervisor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// signals maps the stop_signal names to Unix signals
+var signals = map[string]syscall.Signal{
+	"TERM": syscall.SIGTERM,
+	"INT":  syscall.SIGINT,
+	"QUIT": syscall.SIGQUIT,
+	"HUP":  syscall.SIGHUP,
+	"KILL": syscall.SIGKILL,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+}
+
+// shutdownSignals start an ordered shutdown of the services
+var shutdownSignals = []os.Signal{syscall.SIGTERM, syscall.SIGINT}
+
+// forwardedSignals are relayed to the process group of every running service
+var forwardedSignals = []os.Signal{syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2}
+
+// reloadSignal reloads the configuration instead of being forwarded when Options.LoadConfig is set
+var reloadSignal os.Signal = syscall.SIGHUP
+
+// signalByName returns the signal for a stop_signal name
+func signalByName(name string) (os.Signal, error) {
+	sig, ok := signals[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported signal %s", name)
+	}
+	return sig, nil
+}
+
+// signalGroup sends sig to the process group led by p, unless p was already waited for
+func signalGroup(p *process, sig os.Signal) error {
+	if p.exited() {
+		return nil
+	}
+	unixSig, ok := sig.(syscall.Signal)
+	if !ok {
+		return fmt.Errorf("unsupported signal %s", sig)
+	}
+	if err := syscall.Kill(-p.pid, unixSig); err != nil && err != syscall.ESRCH {
+		return fmt.Errorf("failed to signal process group %d: %w", p.pid, err)
+	}
+	return nil
+}
+
+// configureProcess puts cmd in its own process group and makes it run as
+// the given user and group.
+//
+// The own process group keeps terminal signals away from the services and
+// lets the supervisor signal a service together with its children. The
+// group defaults to the user's primary group; the user's supplementary
+// groups are applied when svz runs as root.
+func configureProcess(cmd *exec.Cmd, userName, groupName string) error {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if userName == "" && groupName == "" {
+		return nil
+	}
+
+	cred := &syscall.Credential{
+		Uid:         uint32(os.Getuid()), //nolint:gosec // uids fit in 32 bits
+		Gid:         uint32(os.Getgid()), //nolint:gosec // gids fit in 32 bits
+		NoSetGroups: true,
+	}
+
+	if userName != "" {
+		u, err := lookupUser(userName)
+		if err != nil {
+			return err
+		}
+		uid, err := parseID(u.Uid)
+		if err != nil {
+			return fmt.Errorf("user %s: %w", userName, err)
+		}
+		gid, err := parseID(u.Gid)
+		if err != nil {
+			return fmt.Errorf("user %s: %w", userName, err)
+		}
+		cred.Uid, cred.Gid = uid, gid
+
+		if os.Geteuid() == 0 {
+			cred.NoSetGroups = false
+			if ids, err := u.GroupIds(); err == nil {
+				for _, id := range ids {
+					if gid, err := parseID(id); err == nil {
+						cred.Groups = append(cred.Groups, gid)
+					}
+				}
+			}
+		}
+	}
+
+	if groupName != "" {
+		gid, err := lookupGroup(groupName)
+		if err != nil {
+			return err
+		}
+		cred.Gid = gid
+	}
+
+	cmd.SysProcAttr.Credential = cred
+	return nil
+}
+
+// lookupUser resolves a user name or numeric uid
+func lookupUser(name string) (*user.User, error) {
+	if u, err := user.Lookup(name); err == nil {
+		return u, nil
+	}
+	if _, err := parseID(name); err == nil {
+		if u, err := user.LookupId(name); err == nil {
+			return u, nil
+		}
+		// A numeric uid without a passwd entry runs with the same gid
+		return &user.User{Uid: name, Gid: name}, nil
+	}
+	return nil, fmt.Errorf("unknown user %s", name)
+}
+
+// lookupGroup resolves a group name or numeric gid
+func lookupGroup(name string) (uint32, error) {
+	if gid, err := parseID(name); err == nil {
+		return gid, nil
+	}
+	g, err := user.LookupGroup(name)
+	if err != nil {
+		return 0, fmt.Errorf("unknown group %s", name)
+	}
+	return parseID(g.Gid)
+}
+
+// parseID parses a numeric uid or gid
+func parseID(id string) (uint32, error) {
+	n, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid id %q", id)
+	}
+	return uint32(n), nil
+}
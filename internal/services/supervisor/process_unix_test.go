@@ -0,0 +1,165 @@
+//go:build unix
+
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTestSupervisor runs s in the background until the returned function is called
+func startTestSupervisor(t *testing.T, s *Supervisor) (stop func() error) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	t.Cleanup(cancel)
+	return func() error {
+		cancel()
+		return <-done
+	}
+}
+
+func TestConfigureProcess_OwnProcessGroup(t *testing.T) {
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, helperService("trap", "trap")), out, log)
+	stop := startTestSupervisor(t, s)
+
+	require.Eventually(t, func() bool { return strings.Contains(out.String(), "ready") }, 5*time.Second, 10*time.Millisecond)
+	pid := s.Services()[0].PID
+	pgid, err := syscall.Getpgid(pid)
+	require.NoError(t, err)
+	assert.Equal(t, pid, pgid, "each service leads its own process group")
+	require.NoError(t, stop())
+}
+
+func TestSupervisor_SignalForwardsToServices(t *testing.T) {
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, helperService("a", "signals"), helperService("b", "signals")), out, log)
+	stop := startTestSupervisor(t, s)
+
+	require.Eventually(t, func() bool { return strings.Count(out.String(), "ready") == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, s.Signal(syscall.SIGUSR1))
+	require.Eventually(t, func() bool {
+		return strings.Count(out.String(), "got user defined signal 1") == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, stop())
+}
+
+func TestRun_HandleSignals(t *testing.T) {
+	out, log := &syncBuffer{}, &eventLog{}
+	s, err := New(helperConfig(t, helperService("a", "signals")), &Options{
+		Stdout: out, Stderr: out, OnEvent: log.add, HandleSignals: true,
+	})
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- s.Run(context.Background()) }()
+
+	require.Eventually(t, func() bool { return strings.Contains(out.String(), "ready") }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	require.Eventually(t, func() bool { return strings.Contains(out.String(), "got hangup") }, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("SIGTERM did not stop the supervisor")
+	}
+	assert.Equal(t, StateStopped, s.Services()[0].State)
+}
+
+func TestRun_HUPReloads(t *testing.T) {
+	dir := t.TempDir()
+	next := reloadConfig(t, reloadServices(dir, "a", "b"))
+	out, log := &syncBuffer{}, &eventLog{}
+	s, err := New(reloadConfig(t, reloadServices(dir, "a")), &Options{
+		Stdout: out, Stderr: out, OnEvent: log.add, HandleSignals: true,
+		LoadConfig: func() (*providers.SupervizConfig, error) { return next, nil },
+	})
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- s.Run(context.Background()) }()
+
+	require.Eventually(t, func() bool { return pidOf(s, "a") != 0 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	require.Eventually(t, func() bool { return pidOf(s, "b") != 0 }, 5*time.Second, 10*time.Millisecond)
+	assert.Contains(t, out.String(), "reload: add b\n")
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("SIGTERM did not stop the supervisor")
+	}
+}
+
+func TestRun_HUPDuringShutdown(t *testing.T) {
+	service := helperService("stubborn", "ignore")
+	service.StopTimeout = time.Minute
+	var loads atomic.Int32
+	out, log := &syncBuffer{}, &eventLog{}
+	s, err := New(helperConfig(t, service), &Options{
+		Stdout: out, Stderr: out, OnEvent: log.add, HandleSignals: true,
+		LoadConfig: func() (*providers.SupervizConfig, error) {
+			loads.Add(1)
+			return nil, errors.New("unexpected reload")
+		},
+	})
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- s.Run(context.Background()) }()
+
+	require.Eventually(t, func() bool { return strings.Contains(out.String(), "ready") }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+	require.Eventually(t, func() bool { return log.index("stubborn", StateStopping) >= 0 }, 5*time.Second, 10*time.Millisecond)
+	for range 5 {
+		require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	}
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the supervisor did not stop")
+	}
+	assert.Zero(t, loads.Load(), "no reload starts once the shutdown began")
+	assert.NotContains(t, out.String(), "reload:")
+}
+
+func TestRun_SecondShutdownSignalKills(t *testing.T) {
+	service := helperService("stubborn", "ignore")
+	service.StopTimeout = time.Minute
+	out, log := &syncBuffer{}, &eventLog{}
+	s, err := New(helperConfig(t, service), &Options{Stdout: out, Stderr: out, OnEvent: log.add, HandleSignals: true})
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- s.Run(context.Background()) }()
+
+	require.Eventually(t, func() bool { return strings.Contains(out.String(), "ready") }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGINT))
+	require.Eventually(t, func() bool { return log.index("stubborn", StateStopping) >= 0 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGINT))
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("a second SIGINT did not kill the service")
+	}
+	status := s.Services()[0]
+	require.NotNil(t, status.ExitCode)
+	assert.Equal(t, -1, *status.ExitCode)
+}
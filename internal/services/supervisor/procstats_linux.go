@@ -0,0 +1,64 @@
+// internal/services/supervisor/procstats_linux.go - Resource usage of a process read from /proc
+package supervisor
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// clockTicks is USER_HZ, the unit of the CPU times in /proc/<pid>/stat, 100 on every Linux architecture
+	clockTicks = 100
+	// statUtime is the field index of utime in /proc/<pid>/stat, counted after the command name
+	statUtime = 11
+	// statStime is the field index of stime
+	statStime = 12
+	// statRSS is the field index of rss, in pages
+	statRSS = 21
+)
+
+// readProcessStats reads the CPU time, resident memory and open descriptors of pid
+func readProcessStats(pid int) (ProcessStats, error) {
+	dir := "/proc/" + strconv.Itoa(pid)
+	data, err := os.ReadFile(dir + "/stat")
+	if err != nil {
+		return ProcessStats{}, fmt.Errorf("failed to read process stats: %w", err)
+	}
+	stats, err := parseProcStat(string(data))
+	if err != nil {
+		return ProcessStats{}, err
+	}
+
+	stats.OpenFDs = -1
+	if entries, err := os.ReadDir(dir + "/fd"); err == nil {
+		stats.OpenFDs = len(entries)
+	}
+	return stats, nil
+}
+
+// parseProcStat extracts the CPU time and resident memory of a /proc/<pid>/stat line;
+// the command name is skipped up to its last parenthesis, as it may hold spaces
+func parseProcStat(line string) (ProcessStats, error) {
+	end := strings.LastIndexByte(line, ')')
+	if end < 0 {
+		return ProcessStats{}, fmt.Errorf("invalid process stats %q", line)
+	}
+	fields := strings.Fields(line[end+1:])
+	if len(fields) <= statRSS {
+		return ProcessStats{}, fmt.Errorf("invalid process stats %q", line)
+	}
+
+	utime, err1 := strconv.ParseUint(fields[statUtime], 10, 64)
+	stime, err2 := strconv.ParseUint(fields[statStime], 10, 64)
+	rss, err3 := strconv.ParseInt(fields[statRSS], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil || rss < 0 {
+		return ProcessStats{}, fmt.Errorf("invalid process stats %q", line)
+	}
+	return ProcessStats{
+		CPU: time.Duration(utime+stime) * time.Second / clockTicks,
+		RSS: uint64(rss) * uint64(os.Getpagesize()),
+	}, nil
+}
@@ -0,0 +1,33 @@
+package supervisor
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProcStat(t *testing.T) {
+	line := "1234 (my (odd) cmd) S 1 1234 1234 0 -1 4194560 500 0 0 0 150 50 0 0 20 0 1 0 100 12345678 300 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 0 0 0 0 0 0"
+	stats, err := parseProcStat(line)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, stats.CPU)
+	assert.Equal(t, uint64(300*os.Getpagesize()), stats.RSS)
+
+	for _, invalid := range []string{"", "1234 no parenthesis", "1234 (cmd) S 1 2", "1234 (cmd) S 1 1234 1234 0 -1 4194560 500 0 0 0 x 50 0 0 20 0 1 0 100 12345678 300"} {
+		_, err := parseProcStat(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestReadProcessStats(t *testing.T) {
+	stats, err := readProcessStats(os.Getpid())
+	require.NoError(t, err)
+	assert.Positive(t, stats.RSS)
+	assert.Positive(t, stats.OpenFDs)
+
+	_, err = readProcessStats(-1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+// internal/services/supervisor/procstats_other.go - Resource usage stub for platforms without /proc
+package supervisor
+
+import "errors"
+
+// readProcessStats is not supported without /proc
+func readProcessStats(int) (ProcessStats, error) {
+	return ProcessStats{}, errors.New("process stats are only available on Linux")
+}
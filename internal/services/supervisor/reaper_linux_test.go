@@ -0,0 +1,49 @@
+package supervisor
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// prSetChildSubreaper makes orphaned descendants reparent to the calling process
+const prSetChildSubreaper = 36
+
+func TestReaper_ReapsOrphans(t *testing.T) {
+	// Stand in for PID 1: orphans of our descendants are reparented to the test process
+	_, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, prSetChildSubreaper, 1, 0)
+	require.Zero(t, errno)
+	t.Cleanup(func() { _, _, _ = syscall.RawSyscall(syscall.SYS_PRCTL, prSetChildSubreaper, 0, 0) })
+
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newReapingSupervisor(t, helperConfig(t, helperService("parent", "orphan"), helperService("keepalive", "trap")), out, log)
+	stop := startTestSupervisor(t, s)
+
+	var orphan int
+	require.Eventually(t, func() bool {
+		for _, line := range strings.Fields(out.String()) {
+			if pid, err := strconv.Atoi(line); err == nil {
+				orphan = pid
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A zombie keeps its /proc entry until it is reaped
+	require.Eventually(t, func() bool {
+		_, err := os.Stat("/proc/" + strconv.Itoa(orphan))
+		return os.IsNotExist(err)
+	}, 5*time.Second, 20*time.Millisecond, "orphan %d was not reaped", orphan)
+
+	require.NoError(t, stop())
+	s.reaper.mu.Lock()
+	defer s.reaper.mu.Unlock()
+	assert.GreaterOrEqual(t, s.reaper.orphans, 1)
+}
@@ -0,0 +1,27 @@
+//go:build !unix
+
+// internal/services/supervisor/reaper_other.go - Child reaping stub for non-Unix platforms
+package supervisor
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// reaper is unavailable without wait4.
+type reaper struct{}
+
+// newReaper reports that reaping requires Unix
+func newReaper() (*reaper, error) {
+	return nil, errors.New("reaping child processes is only supported on Unix")
+}
+
+// run is never called since newReaper fails
+func (r *reaper) run() (stop func()) {
+	return func() {}
+}
+
+// start is never called since newReaper fails
+func (r *reaper) start(cmd *exec.Cmd) (*process, error) {
+	return nil, errors.New("reaping child processes is only supported on Unix")
+}
@@ -0,0 +1,115 @@
+//go:build unix
+
+// internal/services/supervisor/reaper_unix.go - Child reaping for PID 1
+package supervisor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// reaper waits for every child of the process with wait4(-1).
+//
+// As PID 1 the supervisor inherits the orphans of the whole container and
+// must collect them, or they stay zombies. Supervised processes are started
+// through the reaper so that their exit statuses are routed back to them.
+type reaper struct {
+	// mu orders process starts with reaping, so a pid is registered before it can be reaped
+	mu sync.Mutex
+	// waiting maps the pid of a supervised process to its handle
+	waiting map[int]*process
+	// orphans counts the reaped processes that were not started by the supervisor
+	orphans int
+}
+
+// newReaper creates a reaper.
+//
+// Returns:
+//   - reaper: *reaper ready to run
+//   - err: error on platforms without wait4
+func newReaper() (*reaper, error) {
+	return &reaper{waiting: make(map[int]*process)}, nil
+}
+
+// run reaps children on every SIGCHLD until the returned function is called
+func (r *reaper) run() (stop func()) {
+	sigchld := make(chan os.Signal, 1)
+	signal.Notify(sigchld, syscall.SIGCHLD)
+	quit := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		// Children may have exited before SIGCHLD was trapped
+		r.reap()
+		for {
+			select {
+			case <-sigchld:
+				r.reap()
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigchld)
+		close(quit)
+		<-done
+	}
+}
+
+// start starts cmd and registers it before any SIGCHLD can be processed
+func (r *reaper) start(cmd *exec.Cmd) (*process, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	p := newProcess(cmd)
+	r.waiting[p.pid] = p
+	return p, nil
+}
+
+// reap collects every exited child without blocking.
+//
+// SIGCHLD signals coalesce, so a single delivery may stand for several
+// exited children: wait4 is called until no zombie is left.
+func (r *reaper) reap() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for {
+		var ws syscall.WaitStatus
+		pid, err := syscall.Wait4(-1, &ws, syscall.WNOHANG, nil)
+		if errors.Is(err, syscall.EINTR) {
+			continue
+		}
+		if err != nil || pid <= 0 {
+			return
+		}
+
+		p, ok := r.waiting[pid]
+		if !ok {
+			r.orphans++
+			continue
+		}
+		delete(r.waiting, pid)
+		_ = p.cmd.Process.Release() //nolint:errcheck // the process was waited for by wait4
+		p.reap(waitExitStatus(ws))
+	}
+}
+
+// waitExitStatus converts a wait status to the form reported by os.ProcessState
+func waitExitStatus(ws syscall.WaitStatus) exitStatus {
+	if ws.Signaled() {
+		return exitStatus{code: -1, desc: "signal: " + ws.Signal().String()}
+	}
+	return exitStatus{code: ws.ExitStatus(), desc: fmt.Sprintf("exit status %d", ws.ExitStatus())}
+}
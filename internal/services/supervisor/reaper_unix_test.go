@@ -0,0 +1,71 @@
+//go:build unix
+
+package supervisor
+
+import (
+	"context"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReapingSupervisor creates a supervisor reaping every child
+func newReapingSupervisor(t *testing.T, config *providers.SupervizConfig, out *syncBuffer, log *eventLog) *Supervisor {
+	t.Helper()
+	s, err := New(config, &Options{Stdout: out, Stderr: out, OnEvent: log.add, Reap: true})
+	require.NoError(t, err)
+	require.NotNil(t, s.reaper)
+	return s
+}
+
+func TestReaper_RoutesExitStatuses(t *testing.T) {
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newReapingSupervisor(t, helperConfig(t, helperService("bad", "exit", "3"), helperService("hello", "echo", "hi")), out, log)
+
+	err := s.Run(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service bad: exited: exit status 3")
+	assert.Contains(t, out.String(), "hi\n")
+	assert.Equal(t, []State{StateStarting, StateRunning, StateExited}, log.states("hello"))
+}
+
+func TestReaper_ReportsKillSignal(t *testing.T) {
+	service := helperService("stubborn", "ignore")
+	service.StopTimeout = 100 * time.Millisecond
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newReapingSupervisor(t, helperConfig(t, service), out, log)
+	stop := startTestSupervisor(t, s)
+
+	require.Eventually(t, func() bool { return strings.Contains(out.String(), "ready") }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, stop())
+
+	status := s.Services()[0]
+	require.NotNil(t, status.ExitCode)
+	assert.Equal(t, -1, *status.ExitCode)
+	assert.Empty(t, s.reaper.waiting, "every started process is released")
+}
+
+func TestReaper_Restarts(t *testing.T) {
+	service := helperService("flaky", "exit", "1")
+	service.Restart = providers.RestartOnFailure
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newReapingSupervisor(t, helperConfig(t, service), out, log)
+	stop := startTestSupervisor(t, s)
+
+	require.Eventually(t, func() bool { return s.Services()[0].Restarts >= 3 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, stop())
+}
+
+func TestWaitExitStatus(t *testing.T) {
+	// Linux and BSD encode a normal exit as code<<8 and a signal death as the signal number
+	exited := waitExitStatus(syscall.WaitStatus(2 << 8))
+	assert.Equal(t, exitStatus{code: 2, desc: "exit status 2"}, exited)
+
+	killed := waitExitStatus(syscall.WaitStatus(syscall.SIGKILL))
+	assert.Equal(t, exitStatus{code: -1, desc: "signal: killed"}, killed)
+}
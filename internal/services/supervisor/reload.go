@@ -0,0 +1,171 @@
+// internal/services/supervisor/reload.go - Hot reload of the configuration of a running supervisor
+package supervisor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+// Plan computes what Reload would change, without applying anything.
+//
+// Parameters:
+//   - config: *providers.SupervizConfig validated configuration replacing the current one
+//
+// Returns:
+//   - diff: *providers.ConfigDiff changes Reload would apply
+//   - err: error if the configuration is nil or its dependencies cannot be ordered
+func (s *Supervisor) Plan(config *providers.SupervizConfig) (*providers.ConfigDiff, error) {
+	s.reloading.Lock()
+	defer s.reloading.Unlock()
+	diff, _, err := s.plan(config)
+	return diff, err
+}
+
+// plan diffs config against the current configuration and returns its start order; s.reloading must be held
+func (s *Supervisor) plan(config *providers.SupervizConfig) (*providers.ConfigDiff, []string, error) {
+	if config == nil {
+		return nil, nil, errors.New("supervisor configuration cannot be nil")
+	}
+	order, err := config.StartOrder()
+	if err != nil {
+		return nil, nil, err
+	}
+	diff, err := s.currentConfig().Diff(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return diff, order, nil
+}
+
+// Reload replaces the configuration while Run is running, with the fewest
+// actions.
+//
+// Removed services, and services changed in a field that needs a new
+// process, are stopped after their dependents among them. Services changed
+// only in reloadable fields keep their process: new stop settings and restart
+// policies apply right away and new probes restart the probes. Added services
+// start, and restarted services start again if they were active or fatal;
+// each waits for its dependencies like at startup. The other services are
+// left alone. The sections listed as unapplied keep their current settings.
+//
+// Parameters:
+//   - config: *providers.SupervizConfig validated configuration replacing the current one
+//
+// Returns:
+//   - diff: *providers.ConfigDiff changes applied
+//   - err: error if the configuration cannot be ordered, the current one is then kept,
+//     or wrapping ErrSupervisorStopped
+func (s *Supervisor) Reload(config *providers.SupervizConfig) (*providers.ConfigDiff, error) {
+	s.reloading.Lock()
+	defer s.reloading.Unlock()
+	diff, order, err := s.plan(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// The reload counts as active so that Run keeps going while services are replaced
+	s.mu.Lock()
+	if !s.open {
+		s.mu.Unlock()
+		return nil, ErrSupervisorStopped
+	}
+	s.active++
+	s.mu.Unlock()
+	defer s.finished()
+
+	s.graph.RLock()
+	dependents := s.dependents
+	stopping := make(map[string]*runner, len(diff.Removed)+len(diff.Restart))
+	for _, name := range diff.Removed {
+		stopping[name] = s.runners[name]
+	}
+	restart := make(map[string]bool, len(diff.Restart))
+	for _, change := range diff.Restart {
+		r := s.runners[change.Service]
+		stopping[change.Service] = r
+		state := r.snapshot().State
+		restart[change.Service] = r.isActive() || state == StateFatal
+	}
+	s.graph.RUnlock()
+	stopServices(stopping, dependents)
+
+	var replaced []*logBuffer
+	for _, change := range diff.Restart {
+		r := stopping[change.Service]
+		for {
+			logs, ok := r.reconfigure(config.Services[change.Service])
+			if ok {
+				if logs != nil {
+					replaced = append(replaced, logs)
+				}
+				break
+			}
+			// Started again through Start meanwhile
+			r.shutdown()
+		}
+	}
+
+	s.graph.Lock()
+	for _, name := range diff.Removed {
+		replaced = append(replaced, s.runners[name].logBuffer())
+		delete(s.runners, name)
+	}
+	for _, change := range diff.Update {
+		s.runners[change.Service].update(config.Services[change.Service])
+	}
+	for _, name := range diff.Added {
+		s.runners[name] = newRunner(s, config.Services[name])
+		restart[name] = true
+	}
+	s.config = config
+	s.order = order
+	s.dependents = make(map[string][]string, len(order))
+	for _, name := range order {
+		s.dependents[name] = config.Dependents(name)
+	}
+	s.graph.Unlock()
+	for _, logs := range replaced {
+		s.closeLogs(logs.service, logs)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.services() {
+		if restart[r.service] {
+			if err := s.launchLocked(r); errors.Is(err, ErrSupervisorStopped) {
+				return diff, err
+			}
+		}
+	}
+	return diff, nil
+}
+
+// reloadConfig reads the configuration with Options.LoadConfig and reloads
+// it, reporting the changes or why the current configuration is kept on
+// stderr; nothing is reported once the supervisor stopped
+func (s *Supervisor) reloadConfig() {
+	config, err := s.loadConfig()
+	var diff *providers.ConfigDiff
+	if err == nil {
+		diff, err = s.Reload(config)
+	}
+	if errors.Is(err, ErrSupervisorStopped) {
+		// The shutdown started meanwhile, there is no configuration to keep
+		return
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(s.stderr, "reload: %v, keeping the current configuration\n", err) //nolint:errcheck // output is best effort
+		return
+	}
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(diff.Format(), "\n") {
+		if line != "" {
+			b.WriteString("reload: " + line)
+		}
+	}
+	b.WriteString("reload: " + diff.Summary() + "\n")
+	_, _ = fmt.Fprint(s.stderr, b.String()) //nolint:errcheck // output is best effort
+}
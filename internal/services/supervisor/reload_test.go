@@ -0,0 +1,217 @@
+package supervisor
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reloadServices returns the services of a reload test, all running in dir
+func reloadServices(dir string, names ...string) map[string]*providers.ServiceConfig {
+	services := make(map[string]*providers.ServiceConfig, len(names))
+	for _, name := range names {
+		service := helperService(name, "trap")
+		service.WorkingDir = dir
+		services[name] = service
+	}
+	return services
+}
+
+// reloadConfig assembles a configuration of services, validated like helperConfig
+func reloadConfig(t *testing.T, services map[string]*providers.ServiceConfig) *providers.SupervizConfig {
+	t.Helper()
+	list := make([]*providers.ServiceConfig, 0, len(services))
+	for _, service := range services {
+		list = append(list, service)
+	}
+	return helperConfig(t, list...)
+}
+
+// pidOf returns the pid of a running service, 0 when it does not run
+func pidOf(s *Supervisor, name string) int {
+	status, err := s.Service(name)
+	if err != nil || status.State != StateRunning {
+		return 0
+	}
+	return status.PID
+}
+
+func TestSupervisor_Reload(t *testing.T) {
+	dir := t.TempDir()
+	current := reloadServices(dir, "db", "web", "old")
+	current["web"].DependsOn = providers.Dependencies{{Service: "db", Condition: providers.ConditionStarted}}
+	out, log := &syncBuffer{}, &eventLog{}
+	s, err := New(reloadConfig(t, current), &Options{Stdout: out, Stderr: out, OnEvent: log.add, StayUp: true})
+	require.NoError(t, err)
+	stop := runInBackground(s)
+	require.Eventually(t, func() bool {
+		return pidOf(s, "db") != 0 && pidOf(s, "web") != 0 && pidOf(s, "old") != 0
+	}, 5*time.Second, 5*time.Millisecond)
+	db, web := pidOf(s, "db"), pidOf(s, "web")
+
+	next := reloadServices(dir, "db", "web", "api")
+	next["web"].DependsOn = current["web"].DependsOn
+	next["web"].Args = []string{"v2"}
+	next["db"].StopTimeout = 2 * time.Second
+	config := reloadConfig(t, next)
+
+	expected := &providers.ConfigDiff{
+		Added:   []string{"api"},
+		Removed: []string{"old"},
+		Restart: []providers.ServiceChange{{Service: "web", Fields: []string{"args"}}},
+		Update:  []providers.ServiceChange{{Service: "db", Fields: []string{"stop_timeout"}}},
+	}
+	diff, err := s.Plan(config)
+	require.NoError(t, err)
+	assert.Equal(t, expected, diff)
+	assert.Equal(t, web, pidOf(s, "web"), "a plan changes nothing")
+	_, err = s.Service("api")
+	require.ErrorIs(t, err, ErrUnknownService)
+
+	diff, err = s.Reload(config)
+	require.NoError(t, err)
+	assert.Equal(t, expected, diff)
+	require.Eventually(t, func() bool {
+		return pidOf(s, "web") != 0 && pidOf(s, "api") != 0
+	}, 5*time.Second, 5*time.Millisecond)
+	assert.NotEqual(t, web, pidOf(s, "web"), "web is restarted")
+	assert.Equal(t, db, pidOf(s, "db"), "db keeps its process")
+	assert.NotContains(t, log.states("db"), StateStopping)
+	_, err = s.Service("old")
+	require.ErrorIs(t, err, ErrUnknownService)
+	assert.Equal(t, StateStopped, log.states("old")[len(log.states("old"))-1])
+
+	names := make([]string, 0, 3)
+	for _, status := range s.Services() {
+		names = append(names, status.Name)
+	}
+	assert.Equal(t, []string{"api", "db", "web"}, names)
+
+	diff, err = s.Reload(config)
+	require.NoError(t, err)
+	assert.True(t, diff.Empty())
+	require.NoError(t, stop())
+}
+
+func TestSupervisor_ReloadRestartsLastService(t *testing.T) {
+	dir := t.TempDir()
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, reloadConfig(t, reloadServices(dir, "web")), out, log)
+	stop := runInBackground(s)
+	require.Eventually(t, func() bool { return pidOf(s, "web") != 0 }, 5*time.Second, 5*time.Millisecond)
+	pid := pidOf(s, "web")
+
+	next := reloadServices(dir, "web")
+	next["web"].Args = []string{"v2"}
+	_, err := s.Reload(reloadConfig(t, next))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return pidOf(s, "web") != 0 }, 5*time.Second, 5*time.Millisecond)
+	assert.NotEqual(t, pid, pidOf(s, "web"), "Run keeps going while its only service is replaced")
+	require.NoError(t, stop())
+}
+
+func TestSupervisor_ReloadKeepsStoppedServices(t *testing.T) {
+	dir := t.TempDir()
+	current := reloadServices(dir, "web", "job")
+	out, log := &syncBuffer{}, &eventLog{}
+	s, err := New(reloadConfig(t, current), &Options{Stdout: out, Stderr: out, OnEvent: log.add, StayUp: true})
+	require.NoError(t, err)
+	stop := runInBackground(s)
+	require.Eventually(t, func() bool { return pidOf(s, "web") != 0 && pidOf(s, "job") != 0 }, 5*time.Second, 5*time.Millisecond)
+	require.NoError(t, s.Stop("job"))
+
+	next := reloadServices(dir, "web", "job")
+	next["job"].Args = []string{"v2"}
+	next["web"].Restart = providers.RestartAlways
+	_, err = s.Reload(reloadConfig(t, next))
+	require.NoError(t, err)
+
+	job, err := s.Service("job")
+	require.NoError(t, err)
+	assert.Equal(t, StateStopped, job.State, "a stopped service stays stopped")
+	require.NoError(t, s.Start("job"))
+	require.Eventually(t, func() bool { return pidOf(s, "job") != 0 }, 5*time.Second, 5*time.Millisecond)
+	require.NoError(t, stop())
+}
+
+func TestSupervisor_ReloadProbes(t *testing.T) {
+	var ready atomic.Bool
+	dir := t.TempDir()
+	current := reloadServices(dir, "web")
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, reloadConfig(t, current), out, log)
+	stop := runInBackground(s)
+	require.Eventually(t, func() bool { return pidOf(s, "web") != 0 }, 5*time.Second, 5*time.Millisecond)
+	pid := pidOf(s, "web")
+
+	next := reloadServices(dir, "web")
+	next["web"].Probes.Readiness = fastProbe(healthServer(t, &ready), 1)
+	diff, err := s.Reload(reloadConfig(t, next))
+	require.NoError(t, err)
+	assert.Equal(t, []providers.ServiceChange{{Service: "web", Fields: []string{"probes"}}}, diff.Update)
+
+	require.Eventually(t, func() bool { return log.index("web", StateUnhealthy) >= 0 }, 5*time.Second, 5*time.Millisecond)
+	ready.Store(true)
+	require.Eventually(t, func() bool { return log.index("web", StateHealthy) >= 0 }, 5*time.Second, 5*time.Millisecond)
+	status, err := s.Service("web")
+	require.NoError(t, err)
+	assert.Equal(t, pid, status.PID, "new probes keep the process")
+	require.NoError(t, stop())
+}
+
+func TestSupervisor_ReloadErrors(t *testing.T) {
+	dir := t.TempDir()
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, reloadConfig(t, reloadServices(dir, "web")), out, log)
+
+	_, err := s.Reload(reloadConfig(t, reloadServices(dir, "web")))
+	require.ErrorIs(t, err, ErrSupervisorStopped, "Reload requires Run")
+
+	stop := runInBackground(s)
+	require.Eventually(t, func() bool { return pidOf(s, "web") != 0 }, 5*time.Second, 5*time.Millisecond)
+	pid := pidOf(s, "web")
+
+	_, err = s.Reload(nil)
+	require.Error(t, err)
+
+	cycle := reloadServices(dir, "web", "a")
+	cycle["web"].DependsOn = providers.Dependencies{{Service: "a", Condition: providers.ConditionStarted}}
+	cycle["a"].DependsOn = providers.Dependencies{{Service: "web", Condition: providers.ConditionStarted}}
+	_, err = s.Plan(&providers.SupervizConfig{Services: cycle})
+	require.Error(t, err)
+	_, err = s.Reload(&providers.SupervizConfig{Services: cycle})
+	require.Error(t, err)
+	assert.Equal(t, pid, pidOf(s, "web"), "the running configuration is kept")
+	assert.Len(t, s.Services(), 1)
+	require.NoError(t, stop())
+}
+
+func TestSupervisor_ReloadConfig(t *testing.T) {
+	dir := t.TempDir()
+	out, log := &syncBuffer{}, &eventLog{}
+	var load func() (*providers.SupervizConfig, error)
+	s, err := New(reloadConfig(t, reloadServices(dir, "web")), &Options{
+		Stdout: out, Stderr: out, OnEvent: log.add, StayUp: true,
+		LoadConfig: func() (*providers.SupervizConfig, error) { return load() },
+	})
+	require.NoError(t, err)
+	stop := runInBackground(s)
+	require.Eventually(t, func() bool { return pidOf(s, "web") != 0 }, 5*time.Second, 5*time.Millisecond)
+
+	load = func() (*providers.SupervizConfig, error) { return nil, errors.New("superviz.yaml: invalid") }
+	s.reloadConfig()
+	assert.Contains(t, out.String(), "reload: superviz.yaml: invalid, keeping the current configuration\n")
+
+	next := reloadConfig(t, reloadServices(dir, "web", "api"))
+	load = func() (*providers.SupervizConfig, error) { return next, nil }
+	s.reloadConfig()
+	assert.True(t, strings.Contains(out.String(), "reload: add api\nreload: 1 added\n"), out.String())
+	require.Eventually(t, func() bool { return pidOf(s, "api") != 0 }, 5*time.Second, 5*time.Millisecond)
+	require.NoError(t, stop())
+}
@@ -0,0 +1,93 @@
+// internal/services/supervisor/restart.go - Restart decisions, backoff and crash-loop detection
+package supervisor
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+// jitterSource returns a number in [0, 1) used to randomize backoff delays
+var jitterSource = rand.Float64
+
+// restartTracker applies a restart policy across the runs of a service.
+type restartTracker struct {
+	// mode is the restart mode of the service
+	mode providers.RestartMode
+	// policy tunes retries, backoff and crash-loop detection
+	policy providers.RestartPolicy
+	// consecutive counts the restarts since the last reset
+	consecutive int
+	// failures holds the times of the failed exits within the crash-loop window
+	failures []time.Time
+}
+
+// newRestartTracker creates a tracker for a service definition
+func newRestartTracker(config *providers.ServiceConfig) *restartTracker {
+	return &restartTracker{mode: config.Restart, policy: config.RestartPolicy}
+}
+
+// exited records an exit and decides what happens next.
+//
+// Parameters:
+//   - failed: whether the run exited with a non-zero status or failed its liveness or startup probe
+//   - ran: how long the run lasted
+//   - now: time of the exit
+//
+// Returns:
+//   - restart: whether the service is started again
+//   - delay: pause before the restart
+//   - fatal: error when the service must be marked fatal instead
+func (t *restartTracker) exited(failed bool, ran time.Duration, now time.Time) (restart bool, delay time.Duration, fatal error) {
+	if t.policy.ResetAfter > 0 && ran >= t.policy.ResetAfter {
+		t.consecutive = 0
+	}
+
+	if !shouldRestart(t.mode, failed) {
+		return false, 0, nil
+	}
+	if failed && t.policy.CrashLoop.Failures > 0 {
+		t.failures = append(t.failures, now)
+		cutoff := now.Add(-t.policy.CrashLoop.Window)
+		for len(t.failures) > 0 && !t.failures[0].After(cutoff) {
+			t.failures = t.failures[1:]
+		}
+		if len(t.failures) >= t.policy.CrashLoop.Failures {
+			return false, 0, fmt.Errorf("crash loop: %d failures within %s", len(t.failures), t.policy.CrashLoop.Window)
+		}
+	}
+
+	if t.policy.MaxRetries > 0 && t.consecutive >= t.policy.MaxRetries {
+		return false, 0, fmt.Errorf("gave up after %d consecutive restarts", t.consecutive)
+	}
+
+	t.consecutive++
+	return true, backoffDelay(t.policy, t.consecutive), nil
+}
+
+// shouldRestart applies the restart mode to the outcome of a run
+func shouldRestart(mode providers.RestartMode, failed bool) bool {
+	switch mode {
+	case providers.RestartAlways, providers.RestartUnlessStopped:
+		return true
+	case providers.RestartOnFailure:
+		return failed
+	default:
+		return false
+	}
+}
+
+// backoffDelay returns the delay before the n-th consecutive restart
+func backoffDelay(policy providers.RestartPolicy, n int) time.Duration {
+	delay := float64(policy.Backoff) * math.Pow(policy.Multiplier, float64(n-1))
+	if limit := float64(policy.MaxBackoff); delay > limit {
+		delay = limit
+	}
+	if policy.Jitter != nil && *policy.Jitter > 0 {
+		delay *= 1 + *policy.Jitter*(2*jitterSource()-1)
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,119 @@
+package supervisor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPolicy returns a policy without jitter, reset or crash-loop detection
+func testPolicy() providers.RestartPolicy {
+	jitter := 0.0
+	return providers.RestartPolicy{Backoff: time.Second, MaxBackoff: 10 * time.Second, Multiplier: 2, Jitter: &jitter}
+}
+
+func TestShouldRestart(t *testing.T) {
+	assert.True(t, shouldRestart(providers.RestartAlways, false))
+	assert.True(t, shouldRestart(providers.RestartAlways, true))
+	assert.True(t, shouldRestart(providers.RestartUnlessStopped, false))
+	assert.False(t, shouldRestart(providers.RestartOnFailure, false))
+	assert.True(t, shouldRestart(providers.RestartOnFailure, true))
+	assert.False(t, shouldRestart(providers.RestartNever, true))
+}
+
+func TestBackoffDelay_GrowsAndCaps(t *testing.T) {
+	policy := testPolicy()
+
+	var delays []time.Duration
+	for n := 1; n <= 6; n++ {
+		delays = append(delays, backoffDelay(policy, n))
+	}
+	assert.Equal(t, []time.Duration{
+		time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second,
+	}, delays)
+}
+
+func TestBackoffDelay_Jitter(t *testing.T) {
+	policy := testPolicy()
+	jitter := 0.5
+	policy.Jitter = &jitter
+
+	original := jitterSource
+	t.Cleanup(func() { jitterSource = original })
+
+	jitterSource = func() float64 { return 0 }
+	assert.Equal(t, 500*time.Millisecond, backoffDelay(policy, 1))
+	jitterSource = func() float64 { return 0.5 }
+	assert.Equal(t, time.Second, backoffDelay(policy, 1))
+	jitterSource = func() float64 { return 0.75 }
+	assert.Equal(t, 1250*time.Millisecond, backoffDelay(policy, 1))
+}
+
+func TestRestartTracker_Modes(t *testing.T) {
+	now := time.Now()
+
+	never := newRestartTracker(&providers.ServiceConfig{Restart: providers.RestartNever, RestartPolicy: testPolicy()})
+	restart, _, fatal := never.exited(true, time.Second, now)
+	assert.False(t, restart)
+	assert.NoError(t, fatal)
+
+	onFailure := newRestartTracker(&providers.ServiceConfig{Restart: providers.RestartOnFailure, RestartPolicy: testPolicy()})
+	restart, _, _ = onFailure.exited(false, time.Second, now)
+	assert.False(t, restart)
+	restart, delay, fatal := onFailure.exited(true, time.Second, now)
+	assert.True(t, restart)
+	assert.Equal(t, time.Second, delay)
+	assert.NoError(t, fatal)
+}
+
+func TestRestartTracker_ResetAfter(t *testing.T) {
+	policy := testPolicy()
+	policy.ResetAfter = time.Minute
+	tracker := newRestartTracker(&providers.ServiceConfig{Restart: providers.RestartAlways, RestartPolicy: policy})
+	now := time.Now()
+
+	_, first, _ := tracker.exited(true, time.Second, now)
+	_, second, _ := tracker.exited(true, time.Second, now)
+	_, third, _ := tracker.exited(true, 2*time.Minute, now)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, time.Second}, []time.Duration{first, second, third})
+}
+
+func TestRestartTracker_MaxRetries(t *testing.T) {
+	policy := testPolicy()
+	policy.MaxRetries = 2
+	tracker := newRestartTracker(&providers.ServiceConfig{Restart: providers.RestartAlways, RestartPolicy: policy})
+	now := time.Now()
+
+	for range 2 {
+		restart, _, fatal := tracker.exited(true, time.Second, now)
+		require.True(t, restart)
+		require.NoError(t, fatal)
+	}
+	restart, _, fatal := tracker.exited(false, time.Second, now)
+	assert.False(t, restart)
+	assert.EqualError(t, fatal, "gave up after 2 consecutive restarts")
+}
+
+func TestRestartTracker_CrashLoop(t *testing.T) {
+	policy := testPolicy()
+	policy.CrashLoop = providers.CrashLoopPolicy{Failures: 3, Window: 10 * time.Second}
+	tracker := newRestartTracker(&providers.ServiceConfig{Restart: providers.RestartOnFailure, RestartPolicy: policy})
+	start := time.Now()
+
+	// Failures spread beyond the window never accumulate
+	for i := range 5 {
+		restart, _, fatal := tracker.exited(true, time.Second, start.Add(time.Duration(i)*6*time.Second))
+		require.True(t, restart)
+		require.NoError(t, fatal)
+	}
+
+	later := start.Add(time.Hour)
+	tracker.exited(true, time.Second, later)
+	tracker.exited(true, time.Second, later.Add(time.Second))
+	restart, _, fatal := tracker.exited(true, time.Second, later.Add(2*time.Second))
+	assert.False(t, restart)
+	assert.EqualError(t, fatal, "crash loop: 3 failures within 10s")
+}
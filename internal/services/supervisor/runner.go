@@ -0,0 +1,550 @@
+// internal/services/supervisor/runner.go - Lifecycle loop of one supervised service
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing"
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+// runner starts one service and restarts it according to its restart mode.
+type runner struct {
+	// sup is the owning supervisor
+	sup *Supervisor
+	// service is the service name
+	service string
+	// config is the service definition, replaced while inactive or by run itself
+	config *providers.ServiceConfig
+	// mu guards status, proc, reached, ended, changed, pending, logs, the activation fields and the measurements
+	mu sync.Mutex
+	// pending is the definition given to update while active, applied by run at the next safe point
+	pending *providers.ServiceConfig
+	// updated wakes run once pending is set
+	updated chan struct{}
+	// status is the current snapshot
+	status ServiceStatus
+	// proc is the current process, nil before the first start
+	proc *process
+	// reached records the dependency conditions the service has met
+	reached map[providers.DependencyCondition]bool
+	// ended is set once run returned, no condition can be met afterwards
+	ended bool
+	// changed is closed and replaced whenever reached or ended changes
+	changed chan struct{}
+	// active is set from activate until run returns
+	active bool
+	// err is what the last activation returned
+	err error
+	// ctx is cancelled to stop the current activation
+	ctx context.Context
+	// cancel requests the current activation to stop
+	cancel context.CancelFunc
+	// done is closed when the current activation returns, and is closed before the first one
+	done chan struct{}
+	// logs keeps the recent output of the service, replaced while inactive when its settings change
+	logs *logBuffer
+	// startedAt is when the current process started, zero when none runs
+	startedAt time.Time
+	// exits counts the process exits by exit code
+	exits map[int]uint64
+	// probes holds the measurements of each probe kind
+	probes map[string]*ProbeMetrics
+	// span traces the current start attempt, nil between attempts or when tracing is disabled
+	span *tracing.Span
+}
+
+// newRunner creates a stopped runner for a service
+func newRunner(sup *Supervisor, config *providers.ServiceConfig) *runner {
+	done := make(chan struct{})
+	close(done)
+	return &runner{
+		sup:     sup,
+		service: config.Name,
+		config:  config,
+		updated: make(chan struct{}, 1),
+		status:  ServiceStatus{Name: config.Name, State: StateStopped, Since: time.Now()},
+		reached: make(map[providers.DependencyCondition]bool),
+		changed: make(chan struct{}),
+		cancel:  func() {},
+		done:    done,
+		logs:    newLogBuffer(config.Name, defaultLogLines, config.Logs.Multiline, sup.logSinks(config)...),
+		exits:   make(map[int]uint64),
+		probes:  make(map[string]*ProbeMetrics),
+	}
+}
+
+// activate starts run in a goroutine unless the service is already active.
+//
+// The dependency conditions met by a previous activation are forgotten, so
+// dependents started afterwards wait for them again.
+//
+// Parameters:
+//   - finished: func() called once run returned and its error was recorded
+//
+// Returns:
+//   - ok: false when the service was already active
+func (r *runner) activate(finished func()) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.active {
+		return false
+	}
+	r.active = true
+	r.err = nil
+	if r.pending != nil {
+		r.config, r.pending = r.pending, nil
+	}
+	r.ended = false
+	clear(r.reached)
+	r.broadcast()
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	done := make(chan struct{})
+	r.done = done
+
+	go func() {
+		err := r.run()
+		r.mu.Lock()
+		r.err = err
+		r.active = false
+		r.mu.Unlock()
+		close(done)
+		finished()
+	}()
+	return true
+}
+
+// run waits for the dependencies, then supervises the service until
+// shutdown is called or it exits for good.
+//
+// The returned error reports an unmet dependency, a start failure, a failed
+// probe or a non-zero final exit; a service stopped through shutdown returns nil.
+//
+// Each start attempt is traced as a span holding the state transitions.
+func (r *runner) run() (err error) {
+	defer func() {
+		r.endSpan(err)
+		r.mu.Lock()
+		r.ended = true
+		r.broadcast()
+		r.mu.Unlock()
+	}()
+
+	if err := r.waitDependencies(); err != nil {
+		if r.ctx.Err() != nil {
+			return nil
+		}
+		return r.fatal(err)
+	}
+
+	tracker := newRestartTracker(r.config)
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			r.mu.Lock()
+			r.status.Restarts++
+			r.mu.Unlock()
+		}
+		r.adopt(tracker)
+		r.startSpan(attempt)
+		r.transition(StateStarting, 0, nil, "")
+
+		cmd, env, err := r.prepare()
+		var p *process
+		if err == nil {
+			p, err = r.sup.start(cmd)
+		}
+		if err != nil {
+			return r.fatal(fmt.Errorf("failed to start: %w", err))
+		}
+		startedAt := time.Now()
+		r.processStarted(startedAt)
+		r.logs.setPID(p.pid)
+		r.mu.Lock()
+		r.proc = p
+		r.mu.Unlock()
+		r.transition(StateRunning, p.pid, nil, "")
+		r.reach(providers.ConditionStarted)
+		health := r.monitor(p.pid, env)
+
+		var probeErr error
+	supervise:
+		for {
+			select {
+			case <-r.ctx.Done():
+				health.stop()
+				r.adopt(tracker)
+				r.transition(StateStopping, p.pid, nil, "")
+				status := r.stop(p)
+				r.processExited(status.code)
+				r.logs.flush()
+				r.transition(StateStopped, 0, &status.code, status.desc)
+				return nil
+			case <-p.done:
+				health.stop()
+				break supervise
+			case probeErr = <-health.failed:
+				health.stop()
+				r.adopt(tracker)
+				r.transition(StateUnhealthy, p.pid, nil, probeErr.Error())
+				r.transition(StateStopping, p.pid, nil, "")
+				r.stop(p)
+				break supervise
+			case <-r.updated:
+				// The process is kept, only new probes restart the monitor
+				if previous := r.adopt(tracker); previous != nil && !reflect.DeepEqual(previous.Probes, r.config.Probes) {
+					health.stop()
+					health = r.monitor(p.pid, env)
+				}
+			}
+		}
+
+		r.logs.flush()
+		status := p.status
+		r.processExited(status.code)
+		failed := status.code != 0 || probeErr != nil
+		now := time.Now()
+		restart, delay, fatal := tracker.exited(failed, now.Sub(startedAt), now)
+		message := status.desc
+		if restart {
+			message += fmt.Sprintf(", restarting in %s", delay.Round(time.Millisecond))
+		}
+		r.transition(StateExited, 0, &status.code, message)
+		if !failed {
+			r.reach(providers.ConditionCompletedSuccessfully)
+		}
+		if fatal != nil {
+			return r.fatal(fatal)
+		}
+		var exitErr error
+		switch {
+		case probeErr != nil:
+			exitErr = probeErr
+		case status.code != 0:
+			exitErr = fmt.Errorf("exited: %s", status.desc)
+		}
+		if !restart {
+			return exitErr
+		}
+		r.endSpan(exitErr)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-r.ctx.Done():
+			timer.Stop()
+			r.transition(StateStopped, 0, &status.code, "")
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// prepare builds the process of a start attempt, once the BeforeStart hook
+// allowed it and the environment resolved.
+//
+// Returns:
+//   - cmd: *exec.Cmd process writing to the logs of the service, not started
+//   - env: []string resolved environment of the process
+//   - err: error from the hook, the environment or the process construction
+func (r *runner) prepare() (*exec.Cmd, []string, error) {
+	if r.sup.beforeStart != nil {
+		if err := r.sup.beforeStart(r.service); err != nil {
+			return nil, nil, err
+		}
+	}
+	env, secrets, err := resolveEnv(r.sup.environ, r.config)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.logs.mask(secrets)
+	cmd, err := r.sup.command(r.config, env)
+	if err != nil {
+		return nil, nil, err
+	}
+	cmd.Stdout = r.logs.writer(StreamStdout)
+	cmd.Stderr = r.logs.writer(StreamStderr)
+	return cmd, env, nil
+}
+
+// fatal marks the service fatal and applies the on_fatal action.
+//
+// Parameters:
+//   - err: error explaining why the service cannot run
+//
+// Returns:
+//   - err: the same error, for run to return
+func (r *runner) fatal(err error) error {
+	r.transition(StateFatal, 0, nil, err.Error())
+	r.sup.fatal(r.service, err)
+	return err
+}
+
+// shutdown stops the service and waits for run to return
+func (r *runner) shutdown() {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.mu.Unlock()
+	cancel()
+	<-done
+}
+
+// isActive reports whether the service is running or waiting to start or restart
+func (r *runner) isActive() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.active
+}
+
+// wait blocks until the current activation returned
+func (r *runner) wait() {
+	r.mu.Lock()
+	done := r.done
+	r.mu.Unlock()
+	<-done
+}
+
+// result returns the error of the last activation
+func (r *runner) result() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// logBuffer returns the buffer keeping the output of the service
+func (r *runner) logBuffer() *logBuffer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.logs
+}
+
+// update replaces the definition of the service, right away when inactive,
+// otherwise at the next safe point of run; the process is kept.
+//
+// Parameters:
+//   - config: *providers.ServiceConfig new definition, changed only in reloadable fields
+func (r *runner) update(config *providers.ServiceConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.active {
+		r.config, r.pending = config, nil
+		return
+	}
+	r.pending = config
+	select {
+	case r.updated <- struct{}{}:
+	default:
+	}
+}
+
+// adopt applies the pending definition, called by run when no probe reads it.
+//
+// Parameters:
+//   - tracker: *restartTracker taking the new restart mode and policy, its counters kept
+//
+// Returns:
+//   - previous: *providers.ServiceConfig replaced definition, nil when none was pending
+func (r *runner) adopt(tracker *restartTracker) *providers.ServiceConfig {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending == nil {
+		return nil
+	}
+	previous := r.config
+	r.config, r.pending = r.pending, nil
+	tracker.mode, tracker.policy = r.config.Restart, r.config.RestartPolicy
+	return previous
+}
+
+// reconfigure replaces the definition of an inactive service, with a new
+// log buffer when its logs settings change.
+//
+// Parameters:
+//   - config: *providers.ServiceConfig new definition
+//
+// Returns:
+//   - replaced: *logBuffer previous buffer for the caller to close, nil when kept
+//   - ok: bool false when the service is active and nothing was changed
+func (r *runner) reconfigure(config *providers.ServiceConfig) (*logBuffer, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.active {
+		return nil, false
+	}
+	previous := r.config
+	r.config, r.pending = config, nil
+	if reflect.DeepEqual(previous.Logs, config.Logs) {
+		return nil, true
+	}
+	replaced := r.logs
+	r.logs = newLogBuffer(config.Name, defaultLogLines, config.Logs.Multiline, r.sup.logSinks(config)...)
+	return replaced, true
+}
+
+// stop sends the stop signal to the process group, then kills it once the stop timeout elapsed
+func (r *runner) stop(p *process) exitStatus {
+	sig, err := signalByName(r.config.StopSignal)
+	if err == nil {
+		err = signalGroup(p, sig)
+	}
+	if err != nil {
+		// The stop signal cannot be delivered: kill right away
+		_ = signalGroup(p, os.Kill) //nolint:errcheck // the process may exit concurrently
+		return p.wait()
+	}
+
+	timer := time.NewTimer(r.config.StopTimeout)
+	defer timer.Stop()
+	select {
+	case <-p.done:
+	case <-timer.C:
+		_ = signalGroup(p, os.Kill) //nolint:errcheck // the process may exit concurrently
+	}
+	return p.wait()
+}
+
+// signal delivers sig to the process group of the current process, if any
+func (r *runner) signal(sig os.Signal) error {
+	r.mu.Lock()
+	p := r.proc
+	r.mu.Unlock()
+	if p == nil {
+		return nil
+	}
+	return signalGroup(p, sig)
+}
+
+// kill sends SIGKILL to the process group of the current process, if any
+func (r *runner) kill() {
+	_ = r.signal(os.Kill) //nolint:errcheck // the process may exit concurrently
+}
+
+// waitDependencies blocks until every dependency meets its condition.
+//
+// Returns:
+//   - err: error naming the first dependency that cannot meet its condition, or ctx's error
+func (r *runner) waitDependencies() error {
+	for _, dep := range r.config.DependsOn {
+		d, err := r.sup.lookup(dep.Service)
+		if err != nil {
+			return fmt.Errorf("dependency %s was removed", dep.Service)
+		}
+		if err := d.waitCondition(r.ctx, dep.Condition); err != nil {
+			if r.ctx.Err() != nil {
+				return r.ctx.Err()
+			}
+			return fmt.Errorf("dependency %s %s", dep.Service, err)
+		}
+	}
+	return nil
+}
+
+// waitCondition blocks until the service meets cond, or returns an error once it no longer can
+func (r *runner) waitCondition(ctx context.Context, cond providers.DependencyCondition) error {
+	for {
+		r.mu.Lock()
+		reached, ended, changed := r.reached[cond], r.ended, r.changed
+		r.mu.Unlock()
+
+		switch {
+		case reached:
+			return nil
+		case ended:
+			return unmetCondition(cond)
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// unmetCondition describes a dependency that ended without meeting cond
+func unmetCondition(cond providers.DependencyCondition) error {
+	switch cond {
+	case providers.ConditionHealthy:
+		return errors.New("did not become healthy")
+	case providers.ConditionCompletedSuccessfully:
+		return errors.New("did not complete successfully")
+	default:
+		return errors.New("did not start")
+	}
+}
+
+// reach records met dependency conditions and wakes the waiting dependents
+func (r *runner) reach(conds ...providers.DependencyCondition) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, cond := range conds {
+		r.reached[cond] = true
+	}
+	r.broadcast()
+}
+
+// broadcast wakes every waitCondition call; the caller holds mu
+func (r *runner) broadcast() {
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// transition updates the status and emits the matching event, with the secrets masked
+func (r *runner) transition(state State, pid int, exitCode *int, message string) {
+	now := time.Now()
+	message = r.logs.redact(message)
+
+	r.mu.Lock()
+	r.status.State = state
+	r.status.PID = pid
+	r.status.Since = now
+	if exitCode != nil {
+		r.status.ExitCode = exitCode
+	}
+	span := r.span
+	r.mu.Unlock()
+
+	attrs := []otlp.KeyValue{tracing.Attr("process.pid", pid)}
+	if exitCode != nil {
+		attrs = append(attrs, tracing.Attr("process.exit.code", *exitCode))
+	}
+	if message != "" {
+		attrs = append(attrs, tracing.Attr("message", message))
+	}
+	span.AddEvent(string(state), attrs...)
+
+	r.sup.emit(Event{Time: now, Service: r.service, State: state, PID: pid, ExitCode: exitCode, Message: message})
+}
+
+// startSpan starts the span of a start attempt
+func (r *runner) startSpan(attempt int) {
+	_, span := tracing.Start(context.Background(), "service.run",
+		tracing.Attr("superviz.service", r.service),
+		tracing.Attr("process.command", r.config.Command),
+		tracing.Attr("superviz.attempt", attempt))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.span = span
+}
+
+// endSpan ends the span of the current start attempt, if any
+func (r *runner) endSpan(err error) {
+	r.mu.Lock()
+	span := r.span
+	r.span = nil
+	r.mu.Unlock()
+	span.End(err)
+}
+
+// snapshot returns a copy of the status
+func (r *runner) snapshot() ServiceStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
@@ -0,0 +1,700 @@
+// Package supervisor starts, supervises and stops the processes declared in superviz.yaml
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+// State is the lifecycle state of a supervised service.
+type State string
+
+// Service lifecycle states.
+const (
+	// StateStarting means the process is being launched
+	StateStarting State = "starting"
+	// StateRunning means the process is alive
+	StateRunning State = "running"
+	// StateHealthy means the startup or readiness probe passes
+	StateHealthy State = "healthy"
+	// StateUnhealthy means the readiness probe fails, or the liveness or startup probe failed and the process is restarted
+	StateUnhealthy State = "unhealthy"
+	// StateStopping means the stop signal was sent and the process has not exited yet
+	StateStopping State = "stopping"
+	// StateStopped means the process was stopped by the supervisor or never started
+	StateStopped State = "stopped"
+	// StateExited means the process exited on its own
+	StateExited State = "exited"
+	// StateFatal means the process cannot be started or restarted anymore
+	StateFatal State = "fatal"
+)
+
+// Event records a state transition of a service.
+type Event struct {
+	// Time is when the transition happened
+	Time time.Time `json:"time" yaml:"time"`
+	// Service is the service name
+	Service string `json:"service" yaml:"service"`
+	// State is the new state
+	State State `json:"state" yaml:"state"`
+	// PID is the process id while the service is running or stopping
+	PID int `json:"pid,omitempty" yaml:"pid,omitempty"`
+	// ExitCode is the exit status once the process ended, -1 when killed by a signal
+	ExitCode *int `json:"exit_code,omitempty" yaml:"exit_code,omitempty"`
+	// Message explains the transition
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// Format returns a one-line description of the event.
+//
+// Returns:
+//   - line: string such as "web: running (pid 42)" without a trailing newline
+func (e Event) Format() string {
+	line := e.Service + ": " + string(e.State)
+	if e.PID != 0 {
+		line += fmt.Sprintf(" (pid %d)", e.PID)
+	}
+	if e.Message != "" {
+		line += ": " + e.Message
+	}
+	return line
+}
+
+// ServiceStatus is a snapshot of a supervised service.
+type ServiceStatus struct {
+	// Name is the service name
+	Name string `json:"name" yaml:"name"`
+	// State is the current lifecycle state
+	State State `json:"state" yaml:"state"`
+	// PID is the process id while the service is running or stopping
+	PID int `json:"pid,omitempty" yaml:"pid,omitempty"`
+	// Restarts counts the starts after the first one
+	Restarts int `json:"restarts" yaml:"restarts"`
+	// ExitCode is the status of the last exit
+	ExitCode *int `json:"exit_code,omitempty" yaml:"exit_code,omitempty"`
+	// Since is when the service entered its current state
+	Since time.Time `json:"since" yaml:"since"`
+}
+
+// Options configures a Supervisor.
+//
+// All fields are optional.
+type Options struct {
+	// Stdout receives the standard output of the services, default os.Stdout
+	Stdout io.Writer
+	// Stderr receives the standard error of the services, default os.Stderr
+	Stderr io.Writer
+	// Environ is the environment inherited by the services, default os.Environ()
+	Environ []string
+	// OnEvent is called for every state transition, serialized
+	OnEvent func(Event)
+	// HandleSignals traps TERM and INT to stop the services in reverse start
+	// order, and forwards HUP, USR1 and USR2 to their process groups; HUP
+	// reloads the configuration instead when LoadConfig is set
+	HandleSignals bool
+	// Reap waits for every child with wait4(-1), orphans included, as
+	// required from PID 1 (Unix only)
+	Reap bool
+	// StayUp keeps Run going once every service ended, until ctx is
+	// cancelled, so services can still be started through Start
+	StayUp bool
+	// BeforeStart is called before every start attempt of a service; an
+	// error marks the service fatal instead of starting it
+	BeforeStart func(service string) error
+	// LoadConfig reads the configuration again for a reload on HUP, nil to
+	// forward HUP to the services
+	LoadConfig func() (*providers.SupervizConfig, error)
+}
+
+// Supervisor runs the services of a configuration.
+type Supervisor struct {
+	// graph guards config, order, runners and dependents, replaced by Reload
+	graph sync.RWMutex
+	// config is the validated configuration
+	config *providers.SupervizConfig
+	// order lists the services with dependencies first
+	order []string
+	// runners holds one runner per service
+	runners map[string]*runner
+	// dependents maps a service to the services depending on it
+	dependents map[string][]string
+	// reloading serializes the reloads
+	reloading sync.Mutex
+	// loadConfig reads the configuration again on HUP, nil when HUP is forwarded
+	loadConfig func() (*providers.SupervizConfig, error)
+	// stdout and stderr receive the service output
+	stdout, stderr io.Writer
+	// environ is the base environment of the services
+	environ []string
+	// onEvent receives the transitions
+	onEvent func(Event)
+	// eventMu serializes onEvent calls
+	eventMu sync.Mutex
+	// handleSignals traps shutdown and forwarded signals during Run
+	handleSignals bool
+	// reaper waits for every child, nil unless Options.Reap is set
+	reaper *reaper
+	// stayUp keeps Run going once every service ended
+	stayUp bool
+	// beforeStart vetoes start attempts, nil when every attempt proceeds
+	beforeStart func(service string) error
+	// mu guards requestStop, open, active and idle
+	mu sync.Mutex
+	// requestStop stops every service, set by Run before the services start
+	requestStop context.CancelFunc
+	// open is set while Run accepts services to start
+	open bool
+	// active counts the services running or waiting to start or restart
+	active int
+	// idle is closed once Run stopped accepting services and none is active
+	idle chan struct{}
+	// hooks tracks the running on_fatal hooks, Run waits for them
+	hooks sync.WaitGroup
+	// reloads tracks the reloads started by HUP, added to under mu while open
+	reloads sync.WaitGroup
+	// forwarders send the service lines to the log_forward destinations
+	forwarders []*logForwarder
+}
+
+// New creates a supervisor for a validated configuration.
+//
+// Parameters:
+//   - config: *providers.SupervizConfig services to run
+//   - opts: *Options overrides, nil for defaults
+//
+// Returns:
+//   - supervisor: *Supervisor ready to Run
+//   - err: error if the configuration is nil, its dependencies cannot be ordered, reaping is unsupported
+//     or a log_forward destination is invalid
+func New(config *providers.SupervizConfig, opts *Options) (*Supervisor, error) {
+	if config == nil {
+		return nil, errors.New("supervisor configuration cannot be nil")
+	}
+	order, err := config.StartOrder()
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	s := &Supervisor{
+		config:        config,
+		order:         order,
+		runners:       make(map[string]*runner, len(order)),
+		stdout:        opts.Stdout,
+		stderr:        opts.Stderr,
+		environ:       opts.Environ,
+		onEvent:       opts.OnEvent,
+		handleSignals: opts.HandleSignals,
+		stayUp:        opts.StayUp,
+		beforeStart:   opts.BeforeStart,
+		loadConfig:    opts.LoadConfig,
+		idle:          make(chan struct{}),
+	}
+	if opts.Reap {
+		if s.reaper, err = newReaper(); err != nil {
+			return nil, err
+		}
+	}
+	if s.stdout == nil {
+		s.stdout = os.Stdout
+	}
+	if s.stderr == nil {
+		s.stderr = os.Stderr
+	}
+	if s.environ == nil {
+		s.environ = os.Environ()
+	}
+	if err := s.buildForwarders(); err != nil {
+		return nil, err
+	}
+
+	s.dependents = make(map[string][]string, len(order))
+	for _, name := range order {
+		s.runners[name] = newRunner(s, config.Services[name])
+		s.dependents[name] = config.Dependents(name)
+	}
+	return s, nil
+}
+
+// Run starts the services and supervises them.
+//
+// Every service starts as soon as its dependencies meet their conditions,
+// so independent services start in parallel; when a dependency can no
+// longer meet its condition, the dependent is marked fatal. A service that
+// fails to start, crash-loops or exhausts its retries is marked fatal too,
+// and the on_fatal action applies. Run returns when no service is left
+// running or waiting to restart, unless Options.StayUp is set, or once every
+// service was stopped after ctx is cancelled or a shutdown signal is
+// trapped. A service is stopped after all its dependents, with its stop
+// signal and, past its stop_timeout, SIGKILL. Run must be called once.
+//
+// Parameters:
+//   - ctx: context.Context whose cancellation stops the services
+//
+// Returns:
+//   - err: error joining the failures of services that could not start or exited with an error
+func (s *Supervisor) Run(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	if s.reaper != nil {
+		stopReaper := s.reaper.run()
+		defer stopReaper()
+	}
+	for _, forwarder := range s.forwarders {
+		forwarder.start()
+	}
+
+	stopCtx, requestStop := context.WithCancel(ctx)
+	defer requestStop()
+	if s.handleSignals {
+		stopTrap := s.trapSignals(requestStop)
+		defer stopTrap()
+	}
+
+	s.mu.Lock()
+	s.requestStop = requestStop
+	s.open = true
+	for _, r := range s.services() {
+		_ = s.launchLocked(r) //nolint:errcheck // Run is open and no runner is active yet
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.idle:
+	case <-stopCtx.Done():
+		s.shutdown()
+		<-s.idle
+	}
+	// Run is closed, no reload starts anymore
+	s.reloads.Wait()
+	s.hooks.Wait()
+
+	runners := s.services()
+	errs := make([]error, 0, len(runners))
+	for _, r := range runners {
+		name := r.service
+		if err := r.result(); err != nil {
+			errs = append(errs, fmt.Errorf("service %s: %w", name, err))
+		}
+		s.closeLogs(name, r.logBuffer())
+	}
+	for _, forwarder := range s.forwarders {
+		forwarder.stop()
+	}
+	return errors.Join(errs...)
+}
+
+// launchLocked activates a runner and counts it, s.mu must be held
+func (s *Supervisor) launchLocked(r *runner) error {
+	if !s.open {
+		return ErrSupervisorStopped
+	}
+	if !r.activate(s.finished) {
+		return ErrServiceActive
+	}
+	s.active++
+	return nil
+}
+
+// finished is called by a runner whose activation returned
+func (s *Supervisor) finished() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active--
+	s.settleLocked()
+}
+
+// settleLocked closes idle once no service is active and Run stopped
+// accepting services, which happens right away without StayUp; s.mu must be held
+func (s *Supervisor) settleLocked() {
+	if s.active > 0 || (s.open && s.stayUp) {
+		return
+	}
+	s.open = false
+	select {
+	case <-s.idle:
+	default:
+		close(s.idle)
+	}
+}
+
+// shutdown stops every service once its dependents are stopped, independent services in parallel
+func (s *Supervisor) shutdown() {
+	s.mu.Lock()
+	s.open = false
+	s.mu.Unlock()
+
+	s.graph.RLock()
+	runners := make(map[string]*runner, len(s.runners))
+	for name, r := range s.runners {
+		runners[name] = r
+	}
+	dependents := s.dependents
+	s.graph.RUnlock()
+	stopServices(runners, dependents)
+
+	s.mu.Lock()
+	s.settleLocked()
+	s.mu.Unlock()
+}
+
+// stopServices stops the runners, each once its dependents among them are
+// stopped, independent services in parallel
+func stopServices(runners map[string]*runner, dependents map[string][]string) {
+	var wg sync.WaitGroup
+	for name, r := range runners {
+		wg.Add(1)
+		go func(name string, r *runner) {
+			defer wg.Done()
+			for _, dependent := range dependents[name] {
+				if d, ok := runners[dependent]; ok {
+					d.wait()
+				}
+			}
+			r.shutdown()
+		}(name, r)
+	}
+	wg.Wait()
+}
+
+// services returns the runners in start order
+func (s *Supervisor) services() []*runner {
+	s.graph.RLock()
+	defer s.graph.RUnlock()
+	runners := make([]*runner, 0, len(s.order))
+	for _, name := range s.order {
+		runners = append(runners, s.runners[name])
+	}
+	return runners
+}
+
+// currentConfig returns the configuration in use
+func (s *Supervisor) currentConfig() *providers.SupervizConfig {
+	s.graph.RLock()
+	defer s.graph.RUnlock()
+	return s.config
+}
+
+// closeLogs closes the log buffer of a service, reporting a failure on stderr
+func (s *Supervisor) closeLogs(name string, logs *logBuffer) {
+	if err := logs.close(); err != nil {
+		_, _ = fmt.Fprintf(s.stderr, "log file for service %s: %v\n", name, err) //nolint:errcheck // output is best effort
+	}
+}
+
+// trapSignals handles the shutdown and forwarded signals until the returned function is called.
+//
+// A second shutdown signal kills every service right away.
+func (s *Supervisor) trapSignals(requestStop context.CancelFunc) (stop func()) {
+	trapped := make(chan os.Signal, 8)
+	signal.Notify(trapped, append(append([]os.Signal{}, shutdownSignals...), forwardedSignals...)...)
+	quit := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		stopping := false
+		for {
+			select {
+			case sig := <-trapped:
+				if sig == reloadSignal && s.loadConfig != nil {
+					s.startReload()
+					continue
+				}
+				if !isShutdownSignal(sig) {
+					_ = s.Signal(sig) //nolint:errcheck // services may exit concurrently
+					continue
+				}
+				if stopping {
+					s.kill()
+				}
+				stopping = true
+				requestStop()
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(trapped)
+		close(quit)
+		<-done
+	}
+}
+
+// startReload reloads the configuration in the background while Run
+// accepts services, a HUP received during the shutdown is ignored
+func (s *Supervisor) startReload() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.open {
+		return
+	}
+	s.reloads.Add(1)
+	go func() {
+		defer s.reloads.Done()
+		s.reloadConfig()
+	}()
+}
+
+// isShutdownSignal reports whether sig starts an ordered shutdown
+func isShutdownSignal(sig os.Signal) bool {
+	for _, shutdown := range shutdownSignals {
+		if sig == shutdown {
+			return true
+		}
+	}
+	return false
+}
+
+// Signal forwards a signal to the process group of every running service.
+//
+// Parameters:
+//   - sig: os.Signal signal to deliver
+//
+// Returns:
+//   - err: error joining the delivery failures
+func (s *Supervisor) Signal(sig os.Signal) error {
+	var errs []error
+	for _, r := range s.services() {
+		if err := r.signal(sig); err != nil {
+			errs = append(errs, fmt.Errorf("service %s: %w", r.service, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// kill sends SIGKILL to every running service
+func (s *Supervisor) kill() {
+	for _, r := range s.services() {
+		r.kill()
+	}
+}
+
+// lookup returns the runner of a service
+func (s *Supervisor) lookup(name string) (*runner, error) {
+	s.graph.RLock()
+	r, ok := s.runners[name]
+	s.graph.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownService, name)
+	}
+	return r, nil
+}
+
+// Service returns a snapshot of one service.
+//
+// Parameters:
+//   - name: string service name
+//
+// Returns:
+//   - status: ServiceStatus current state
+//   - err: error wrapping ErrUnknownService
+func (s *Supervisor) Service(name string) (ServiceStatus, error) {
+	r, err := s.lookup(name)
+	if err != nil {
+		return ServiceStatus{}, err
+	}
+	return r.snapshot(), nil
+}
+
+// Start starts a stopped, exited or fatal service while Run is running.
+//
+// The service waits for its dependencies like at startup.
+//
+// Parameters:
+//   - name: string service name
+//
+// Returns:
+//   - err: error wrapping ErrUnknownService, ErrServiceActive or ErrSupervisorStopped
+func (s *Supervisor) Start(name string) error {
+	r, err := s.lookup(name)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.launchLocked(r)
+}
+
+// Stop stops a service with its stop signal and, past its stop_timeout, SIGKILL.
+//
+// The service is not restarted, whatever its restart mode, until Start is
+// called; its dependents keep running.
+//
+// Parameters:
+//   - name: string service name
+//
+// Returns:
+//   - err: error wrapping ErrUnknownService or ErrServiceNotActive
+func (s *Supervisor) Stop(name string) error {
+	r, err := s.lookup(name)
+	if err != nil {
+		return err
+	}
+	if !r.isActive() {
+		return ErrServiceNotActive
+	}
+	r.shutdown()
+	return nil
+}
+
+// Restart stops a service if it is active, then starts it.
+//
+// Parameters:
+//   - name: string service name
+//
+// Returns:
+//   - err: error wrapping ErrUnknownService or ErrSupervisorStopped
+func (s *Supervisor) Restart(name string) error {
+	r, err := s.lookup(name)
+	if err != nil {
+		return err
+	}
+	r.shutdown()
+	return s.Start(name)
+}
+
+// SignalService sends a signal to the process group of a running service.
+//
+// Parameters:
+//   - name: string service name
+//   - signal: string signal name such as HUP or SIGUSR1
+//
+// Returns:
+//   - err: error wrapping ErrUnknownService, ErrUnsupportedSignal or ErrServiceNotActive
+func (s *Supervisor) SignalService(name, signal string) error {
+	r, err := s.lookup(name)
+	if err != nil {
+		return err
+	}
+	sig, err := signalByName(strings.TrimPrefix(strings.ToUpper(signal), "SIG"))
+	if err != nil {
+		return fmt.Errorf("%w %s", ErrUnsupportedSignal, signal)
+	}
+	if !r.isActive() {
+		return ErrServiceNotActive
+	}
+	return r.signal(sig)
+}
+
+// Logs returns the last output lines of a service.
+//
+// Parameters:
+//   - name: string service name
+//   - lines: int number of lines, every kept line when <= 0
+//
+// Returns:
+//   - lines: []LogLine oldest first
+//   - err: error wrapping ErrUnknownService
+func (s *Supervisor) Logs(name string, lines int) ([]LogLine, error) {
+	r, err := s.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return r.logBuffer().tail(lines), nil
+}
+
+// FollowLogs returns the last output lines of a service, then every new line until ctx is done.
+//
+// Lines are dropped for a reader lagging too far behind.
+//
+// Parameters:
+//   - ctx: context.Context ending the stream
+//   - name: string service name
+//   - lines: int number of past lines, every kept line when <= 0
+//
+// Returns:
+//   - stream: <-chan LogLine closed once ctx is done
+//   - err: error wrapping ErrUnknownService
+func (s *Supervisor) FollowLogs(ctx context.Context, name string, lines int) (<-chan LogLine, error) {
+	r, err := s.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return r.logBuffer().follow(ctx, lines), nil
+}
+
+// Services returns a snapshot of every service in start order.
+//
+// Returns:
+//   - statuses: []ServiceStatus one entry per service
+func (s *Supervisor) Services() []ServiceStatus {
+	runners := s.services()
+	statuses := make([]ServiceStatus, 0, len(runners))
+	for _, r := range runners {
+		statuses = append(statuses, r.snapshot())
+	}
+	return statuses
+}
+
+// emit delivers an event to the OnEvent callback
+func (s *Supervisor) emit(event Event) {
+	if s.onEvent == nil {
+		return
+	}
+	s.eventMu.Lock()
+	defer s.eventMu.Unlock()
+	s.onEvent(event)
+}
+
+// fatal applies the on_fatal action to a service that became fatal
+func (s *Supervisor) fatal(name string, reason error) {
+	switch s.currentConfig().OnFatal.Action {
+	case providers.FatalExit:
+		s.requestStop()
+	case providers.FatalHook:
+		s.hooks.Add(1)
+		go func() {
+			defer s.hooks.Done()
+			if err := s.runHook(name, reason); err != nil {
+				fmt.Fprintf(s.stderr, "on_fatal hook for service %s: %v\n", name, err)
+			}
+		}()
+	}
+}
+
+// runHook runs the on_fatal hook with SVZ_SERVICE and SVZ_REASON set.
+//
+// Parameters:
+//   - name: string fatal service
+//   - reason: error why the service became fatal
+//
+// Returns:
+//   - err: error if the hook cannot start, fails or times out
+func (s *Supervisor) runHook(name string, reason error) error {
+	config := s.currentConfig()
+	hook := config.OnFatal
+	cmd := exec.Command(hook.Command, hook.Args...) //nolint:gosec // the command comes from the operator's configuration
+	cmd.Dir = config.Dir
+	cmd.Env = mergeEnv(s.environ, map[string]string{"SVZ_SERVICE": name, "SVZ_REASON": reason.Error()})
+	cmd.Stdout = s.stdout
+	cmd.Stderr = s.stderr
+	if err := configureProcess(cmd, "", ""); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hook.Timeout)
+	defer cancel()
+	if err := s.execute(ctx, cmd); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w after %s", err, hook.Timeout)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,532 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+	"github.com/kodflow/superviz.io/internal/infrastructure/tracing/tracingtest"
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventLog records the events of a supervisor
+type eventLog struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// add is the OnEvent callback
+func (l *eventLog) add(event Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+// states returns the successive states of a service
+func (l *eventLog) states(service string) []State {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var states []State
+	for _, event := range l.events {
+		if event.Service == service {
+			states = append(states, event.State)
+		}
+	}
+	return states
+}
+
+// index returns the position of the first event matching service and state, or -1
+func (l *eventLog) index(service string, state State) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, event := range l.events {
+		if event.Service == service && event.State == state {
+			return i
+		}
+	}
+	return -1
+}
+
+// newTestSupervisor creates a supervisor writing to out and recording to log
+func newTestSupervisor(t *testing.T, config *providers.SupervizConfig, out *syncBuffer, log *eventLog) *Supervisor {
+	t.Helper()
+	s, err := New(config, &Options{Stdout: out, Stderr: out, OnEvent: log.add})
+	require.NoError(t, err)
+	return s
+}
+
+func TestNew_NilConfig(t *testing.T) {
+	_, err := New(nil, nil)
+	require.Error(t, err)
+}
+
+func TestNew_Defaults(t *testing.T) {
+	s, err := New(helperConfig(t, helperService("a", "exit", "0")), nil)
+	require.NoError(t, err)
+	assert.NotNil(t, s.stdout)
+	assert.NotNil(t, s.stderr)
+	assert.NotEmpty(t, s.environ)
+
+	statuses := s.Services()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "a", statuses[0].Name)
+	assert.Equal(t, StateStopped, statuses[0].State)
+}
+
+func TestRun_ServiceExitsCleanly(t *testing.T) {
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, helperService("hello", "echo", "hello world")), out, log)
+
+	require.NoError(t, s.Run(context.Background()))
+	assert.Contains(t, out.String(), "hello world")
+	assert.Equal(t, []State{StateStarting, StateRunning, StateExited}, log.states("hello"))
+
+	status := s.Services()[0]
+	assert.Equal(t, StateExited, status.State)
+	require.NotNil(t, status.ExitCode)
+	assert.Equal(t, 0, *status.ExitCode)
+}
+
+func TestRun_FailedExitReturnsError(t *testing.T) {
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, helperService("bad", "exit", "3")), out, log)
+
+	err := s.Run(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service bad")
+	assert.Contains(t, err.Error(), "exit status 3")
+}
+
+func TestRun_RestartOnFailure(t *testing.T) {
+	service := helperService("flaky", "exit", "1")
+	service.Restart = providers.RestartOnFailure
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, service), out, log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	require.Eventually(t, func() bool { return s.Services()[0].Restarts >= 2 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, StateStopped, s.Services()[0].State)
+}
+
+func TestRun_RestartAlwaysAfterCleanExit(t *testing.T) {
+	service := helperService("loop", "exit", "0")
+	service.Restart = providers.RestartAlways
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, service), out, log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	require.Eventually(t, func() bool { return s.Services()[0].Restarts >= 1 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestRun_CrashLoopIsFatal(t *testing.T) {
+	service := helperService("crashy", "exit", "1")
+	service.Restart = providers.RestartAlways
+	service.RestartPolicy.CrashLoop = providers.CrashLoopPolicy{Failures: 3, Window: time.Minute}
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, service), out, log)
+
+	err := s.Run(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service crashy: crash loop: 3 failures within 1m0s")
+	assert.Equal(t, StateFatal, s.Services()[0].State)
+	assert.Equal(t, 2, s.Services()[0].Restarts)
+}
+
+func TestRun_MaxRetriesIsFatal(t *testing.T) {
+	service := helperService("flaky", "exit", "1")
+	service.Restart = providers.RestartOnFailure
+	service.RestartPolicy.MaxRetries = 1
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, service), out, log)
+
+	err := s.Run(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "gave up after 1 consecutive restarts")
+	assert.Equal(t, []State{StateStarting, StateRunning, StateExited, StateStarting, StateRunning, StateExited, StateFatal}, log.states("flaky"))
+}
+
+func TestRun_OnFatalExitStopsEveryService(t *testing.T) {
+	config := helperConfig(t, helperService("bad", "exit", "0"), helperService("web", "trap"))
+	config.Services["bad"].Command = "/nonexistent/svz-helper"
+	config.OnFatal.Action = providers.FatalExit
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, config, out, log)
+
+	done := make(chan error, 1)
+	go func() { done <- s.Run(context.Background()) }()
+
+	select {
+	case err := <-done:
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "service bad: failed to start")
+	case <-time.After(10 * time.Second):
+		t.Fatal("on_fatal exit did not stop the supervisor")
+	}
+	assert.Equal(t, StateStopped, s.Services()[1].State)
+}
+
+func TestRun_OnFatalHook(t *testing.T) {
+	config := helperConfig(t, helperService("bad", "exit", "1"))
+	config.Services["bad"].Restart = providers.RestartOnFailure
+	config.Services["bad"].RestartPolicy.MaxRetries = 1
+	config.OnFatal = providers.OnFatalConfig{Action: providers.FatalHook, Command: os.Args[0], Args: []string{"SVZ_REASON"}, Timeout: 5 * time.Second}
+	out, log := &syncBuffer{}, &eventLog{}
+	s, err := New(config, &Options{Stdout: out, Stderr: out, OnEvent: log.add, Environ: append(os.Environ(), helperEnv+"=env")})
+	require.NoError(t, err)
+
+	require.Error(t, s.Run(context.Background()))
+	assert.Contains(t, out.String(), "gave up after 1 consecutive restarts\n")
+}
+
+func TestRun_OnFatalHookTimeout(t *testing.T) {
+	config := helperConfig(t, helperService("bad", "exit", "1"))
+	config.OnFatal = providers.OnFatalConfig{Action: providers.FatalHook, Command: os.Args[0], Args: []string{"1m"}, Timeout: 100 * time.Millisecond}
+	config.Services["bad"].Command = "/nonexistent/svz-helper"
+	out, log := &syncBuffer{}, &eventLog{}
+	s, err := New(config, &Options{Stdout: out, Stderr: out, OnEvent: log.add, Environ: append(os.Environ(), helperEnv+"=sleep")})
+	require.NoError(t, err)
+
+	require.Error(t, s.Run(context.Background()))
+	assert.Contains(t, out.String(), "on_fatal hook for service bad: timed out after 100ms")
+}
+
+func TestRun_StopsServicesOnCancel(t *testing.T) {
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, helperService("trap", "trap")), out, log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	require.Eventually(t, func() bool { return strings.Contains(out.String(), "ready") }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Equal(t, []State{StateStarting, StateRunning, StateStopping, StateStopped}, log.states("trap"))
+	status := s.Services()[0]
+	require.NotNil(t, status.ExitCode)
+	assert.Equal(t, 0, *status.ExitCode, "the helper exits cleanly on TERM")
+}
+
+func TestRun_KillsAfterStopTimeout(t *testing.T) {
+	service := helperService("stubborn", "ignore")
+	service.StopTimeout = 100 * time.Millisecond
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, service), out, log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	require.Eventually(t, func() bool { return strings.Contains(out.String(), "ready") }, 5*time.Second, 10*time.Millisecond)
+	start := time.Now()
+	cancel()
+	require.NoError(t, <-done)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	status := s.Services()[0]
+	assert.Equal(t, StateStopped, status.State)
+	require.NotNil(t, status.ExitCode)
+	assert.Equal(t, -1, *status.ExitCode, "killed processes report -1")
+}
+
+func TestRun_StartsDependenciesFirst(t *testing.T) {
+	db := helperService("db", "trap")
+	web := helperService("web", "trap")
+	web.DependsOn = providers.Dependencies{{Service: "db", Condition: providers.ConditionStarted}}
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, web, db), out, log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	require.Eventually(t, func() bool { return strings.Count(out.String(), "ready") == 2 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Less(t, log.index("db", StateRunning), log.index("web", StateStarting))
+	assert.Equal(t, []string{"db", "web"}, []string{s.Services()[0].Name, s.Services()[1].Name})
+}
+
+func TestRun_DependentOfFailedServiceIsFatal(t *testing.T) {
+	db := helperService("db", "trap")
+	db.Command = "/nonexistent/svz-helper"
+	web := helperService("web", "trap")
+	web.DependsOn = providers.Dependencies{{Service: "db", Condition: providers.ConditionStarted}}
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, db, web), out, log)
+
+	err := s.Run(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service db: failed to start")
+	assert.Contains(t, err.Error(), "service web: dependency db did not start")
+	assert.Equal(t, []State{StateStarting, StateFatal}, log.states("db"))
+	assert.Equal(t, []State{StateFatal}, log.states("web"))
+}
+
+func TestRun_BeforeStartRefusesService(t *testing.T) {
+	config := helperConfig(t, helperService("vault", "trap"), helperService("job", "exit", "0"))
+	config.Services["job"].Restart = providers.RestartNever
+	var asked []string
+	var mu sync.Mutex
+	out, log := &syncBuffer{}, &eventLog{}
+	s, err := New(config, &Options{Stdout: out, Stderr: out, OnEvent: log.add, BeforeStart: func(service string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		asked = append(asked, service)
+		if service == "vault" {
+			return errors.New("integrity check failed: /usr/bin/vault: missing")
+		}
+		return nil
+	}})
+	require.NoError(t, err)
+
+	err = s.Run(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service vault: failed to start: integrity check failed: /usr/bin/vault: missing")
+	assert.Equal(t, []State{StateStarting, StateFatal}, log.states("vault"), "a refused start is not retried")
+	assert.Equal(t, []State{StateStarting, StateRunning, StateExited}, log.states("job"))
+	assert.ElementsMatch(t, []string{"vault", "job"}, asked)
+}
+
+func TestRun_StopsInReverseStartOrder(t *testing.T) {
+	db := helperService("db", "trap")
+	cache := helperService("cache", "trap")
+	cache.DependsOn = providers.Dependencies{{Service: "db", Condition: providers.ConditionStarted}}
+	web := helperService("web", "trap")
+	web.DependsOn = providers.Dependencies{{Service: "cache", Condition: providers.ConditionStarted}}
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, db, cache, web), out, log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	require.Eventually(t, func() bool { return strings.Count(out.String(), "ready") == 3 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Less(t, log.index("web", StateStopped), log.index("cache", StateStopping))
+	assert.Less(t, log.index("cache", StateStopped), log.index("db", StateStopping))
+}
+
+func TestRun_CompletedSuccessfullyCondition(t *testing.T) {
+	migrate := helperService("migrate", "sleep", "100ms")
+	app := helperService("app", "echo", "serving")
+	app.DependsOn = providers.Dependencies{{Service: "migrate", Condition: providers.ConditionCompletedSuccessfully}}
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, migrate, app), out, log)
+
+	require.NoError(t, s.Run(context.Background()))
+	assert.Less(t, log.index("migrate", StateExited), log.index("app", StateStarting))
+	assert.Contains(t, out.String(), "serving")
+}
+
+func TestRun_UnmetConditionIsFatal(t *testing.T) {
+	migrate := helperService("migrate", "exit", "1")
+	app := helperService("app", "echo", "serving")
+	app.DependsOn = providers.Dependencies{{Service: "migrate", Condition: providers.ConditionCompletedSuccessfully}}
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, migrate, app), out, log)
+
+	err := s.Run(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "service app: dependency migrate did not complete successfully")
+	assert.Equal(t, []State{StateFatal}, log.states("app"))
+	assert.NotContains(t, out.String(), "serving")
+}
+
+func TestRun_StartsIndependentServicesInParallel(t *testing.T) {
+	a := helperService("a", "sleep", "300ms")
+	b := helperService("b", "sleep", "300ms")
+	c := helperService("c", "echo", "done")
+	c.DependsOn = providers.Dependencies{
+		{Service: "a", Condition: providers.ConditionCompletedSuccessfully},
+		{Service: "b", Condition: providers.ConditionCompletedSuccessfully},
+	}
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, a, b, c), out, log)
+
+	require.NoError(t, s.Run(context.Background()))
+	assert.Less(t, log.index("a", StateRunning), log.index("b", StateExited), "a and b run at the same time")
+	assert.Less(t, log.index("b", StateRunning), log.index("a", StateExited), "a and b run at the same time")
+	assert.Greater(t, log.index("c", StateStarting), log.index("a", StateExited))
+	assert.Greater(t, log.index("c", StateStarting), log.index("b", StateExited))
+}
+
+func TestRun_StopWhileWaitingForDependency(t *testing.T) {
+	migrate := helperService("migrate", "trap")
+	app := helperService("app", "echo", "serving")
+	app.DependsOn = providers.Dependencies{{Service: "migrate", Condition: providers.ConditionCompletedSuccessfully}}
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, migrate, app), out, log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	require.Eventually(t, func() bool { return strings.Contains(out.String(), "ready") }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+	assert.Empty(t, log.states("app"), "a service stopped while waiting never starts")
+	assert.Equal(t, StateStopped, s.Services()[1].State)
+}
+
+func TestRun_CancelledBeforeStart(t *testing.T) {
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, helperService("a", "trap")), out, log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, s.Run(ctx))
+	assert.Empty(t, log.states("a"))
+}
+
+func TestEvent_Format(t *testing.T) {
+	code := 1
+	assert.Equal(t, "web: running (pid 42)", Event{Service: "web", State: StateRunning, PID: 42}.Format())
+	assert.Equal(t, "web: exited: exit status 1", Event{Service: "web", State: StateExited, ExitCode: &code, Message: "exit status 1"}.Format())
+}
+
+func TestSupervisor_StartStopRestart(t *testing.T) {
+	out, log := &syncBuffer{}, &eventLog{}
+	s, err := New(helperConfig(t, helperService("web", "trap"), helperService("job", "echo", "done")),
+		&Options{Stdout: out, Stderr: out, OnEvent: log.add, StayUp: true})
+	require.NoError(t, err)
+	require.ErrorIs(t, s.Start("web"), ErrSupervisorStopped, "Start requires Run")
+
+	stop := runInBackground(s)
+	require.Eventually(t, func() bool {
+		web, _ := s.Service("web")
+		job, _ := s.Service("job")
+		return web.State == StateRunning && job.State == StateExited
+	}, 5*time.Second, 5*time.Millisecond)
+
+	assert.ErrorIs(t, s.Start("web"), ErrServiceActive)
+	require.NoError(t, s.Start("job"), "an exited service can be started again")
+	require.NoError(t, s.Stop("web"))
+	web, err := s.Service("web")
+	require.NoError(t, err)
+	assert.Equal(t, StateStopped, web.State)
+	assert.ErrorIs(t, s.Stop("web"), ErrServiceNotActive)
+	assert.ErrorIs(t, s.SignalService("web", "HUP"), ErrServiceNotActive)
+
+	require.NoError(t, s.Restart("web"))
+	require.Eventually(t, func() bool { web, _ := s.Service("web"); return web.State == StateRunning }, 5*time.Second, 5*time.Millisecond)
+	require.NoError(t, s.Restart("web"))
+	require.Eventually(t, func() bool { lines, _ := s.Logs("job", 0); return len(lines) == 2 }, 5*time.Second, 5*time.Millisecond)
+
+	_, err = s.Service("db")
+	assert.ErrorIs(t, err, ErrUnknownService)
+	assert.ErrorIs(t, s.Start("db"), ErrUnknownService)
+	assert.ErrorIs(t, s.Stop("db"), ErrUnknownService)
+	assert.ErrorIs(t, s.Restart("db"), ErrUnknownService)
+	assert.ErrorIs(t, s.SignalService("web", "BOGUS"), ErrUnsupportedSignal)
+
+	require.NoError(t, stop())
+	stops := 0
+	for _, state := range log.states("web") {
+		if state == StateStopped {
+			stops++
+		}
+	}
+	assert.Equal(t, 3, stops, "stopped by Stop, Restart and the shutdown")
+	assert.ErrorIs(t, s.Start("web"), ErrSupervisorStopped, "Start is refused once Run returned")
+
+	lines, err := s.Logs("job", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"done", "done"}, texts(lines))
+	_, err = s.Logs("db", 0)
+	assert.ErrorIs(t, err, ErrUnknownService)
+}
+
+func TestSupervisor_StopLastServiceEndsRun(t *testing.T) {
+	out, log := &syncBuffer{}, &eventLog{}
+	s := newTestSupervisor(t, helperConfig(t, helperService("web", "trap")), out, log)
+
+	done := make(chan error, 1)
+	go func() { done <- s.Run(context.Background()) }()
+	require.Eventually(t, func() bool { return s.Services()[0].State == StateRunning }, 5*time.Second, 5*time.Millisecond)
+
+	require.NoError(t, s.Stop("web"))
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run kept going without StayUp")
+	}
+}
+
+func TestSupervisor_FollowLogs(t *testing.T) {
+	out, log := &syncBuffer{}, &eventLog{}
+	s, err := New(helperConfig(t, helperService("web", "trap")), &Options{Stdout: out, Stderr: out, OnEvent: log.add, StayUp: true})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := s.FollowLogs(ctx, "web", 10)
+	require.NoError(t, err)
+	_, err = s.FollowLogs(ctx, "db", 10)
+	assert.ErrorIs(t, err, ErrUnknownService)
+
+	stop := runInBackground(s)
+	select {
+	case line := <-stream:
+		assert.Equal(t, LogLine{Time: line.Time, Service: "web", Stream: StreamStdout, Text: "ready", PID: line.PID}, line)
+		assert.NotZero(t, line.PID, "lines carry the pid of the process")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no line followed")
+	}
+	require.NoError(t, stop())
+	assert.Contains(t, out.String(), "ready\n", "output still reaches the supervisor writers")
+}
+
+func TestSupervisor_TracesStartAttempts(t *testing.T) {
+	recorder := tracingtest.Install(t)
+	service := helperService("job", "exit", "3")
+	service.Restart = providers.RestartOnFailure
+	service.RestartPolicy.MaxRetries = 1
+	s := newTestSupervisor(t, helperConfig(t, service), &syncBuffer{}, &eventLog{})
+	require.Error(t, s.Run(context.Background()))
+
+	spans := recorder.Spans()
+	require.Equal(t, []string{"service.run", "service.run"}, tracingtest.Names(spans))
+	for attempt, span := range spans {
+		assert.Contains(t, span.Attributes, otlp.Attribute("superviz.service", "job"))
+		assert.Contains(t, span.Attributes, otlp.Attribute("superviz.attempt", attempt))
+		assert.Equal(t, otlp.StatusError, span.Status.Code)
+	}
+	assert.NotEqual(t, spans[0].TraceID, spans[1].TraceID, "each attempt is its own trace")
+
+	var events []string
+	for _, event := range spans[1].Events {
+		events = append(events, event.Name)
+	}
+	assert.Equal(t, []string{"starting", "running", "exited", "fatal", "exception"}, events)
+	assert.Contains(t, spans[1].Events[2].Attributes, otlp.Attribute("process.exit.code", 3))
+}
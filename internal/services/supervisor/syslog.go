@@ -0,0 +1,200 @@
+// internal/services/supervisor/syslog.go - RFC 5424 syslog forwarding of the service lines
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/otlp"
+	"github.com/kodflow/superviz.io/internal/providers"
+)
+
+const (
+	// syslogTimeLayout is the RFC 5424 timestamp with microseconds
+	syslogTimeLayout = "2006-01-02T15:04:05.000000Z07:00"
+	// syslogSDID names the structured data element holding the parsed fields;
+	// 32473 is the enterprise number reserved for documentation by RFC 5612
+	syslogSDID = "superviz@32473"
+	// syslogBatch is the largest number of messages written at once
+	syslogBatch = 128
+	// syslogInterval is the longest a line waits before it is written
+	syslogInterval = 100 * time.Millisecond
+	// syslogTimeout bounds the connection and the writes of one batch
+	syslogTimeout = 5 * time.Second
+)
+
+// syslogExporter writes RFC 5424 messages to a syslog server.
+//
+// UDP and unix datagram sockets carry one message per datagram; TCP and
+// unix stream sockets use the octet-counting framing of RFC 6587.
+type syslogExporter struct {
+	// config is the destination
+	config providers.SyslogConfig
+	// hostname identifies the machine in the messages
+	hostname string
+	// conn is the open connection, nil until the first batch or after a failure
+	conn net.Conn
+	// framed prefixes each message with its length, on stream connections
+	framed bool
+}
+
+// newSyslogExporter creates an exporter connecting on its first batch
+func newSyslogExporter(config *providers.SyslogConfig, hostname string) *syslogExporter {
+	if config.Hostname != "" {
+		hostname = config.Hostname
+	}
+	return &syslogExporter{config: *config, hostname: hostname}
+}
+
+// export writes every line, reconnecting on the next batch after a failure
+func (e *syslogExporter) export(ctx context.Context, lines []LogLine) error {
+	if e.conn == nil {
+		if err := e.dial(ctx); err != nil {
+			return err
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = e.conn.SetWriteDeadline(deadline) //nolint:errcheck // a failed write reports the problem
+	}
+
+	for _, line := range lines {
+		message := e.format(line)
+		if e.framed {
+			message = strconv.Itoa(len(message)) + " " + message
+		}
+		if _, err := e.conn.Write([]byte(message)); err != nil {
+			_ = e.conn.Close() //nolint:errcheck // already failing
+			e.conn = nil
+			return fmt.Errorf("failed to write to syslog %s: %w", e.config.Address, err)
+		}
+	}
+	return nil
+}
+
+// dial connects to the server; a unix socket is tried as a datagram socket first
+func (e *syslogExporter) dial(ctx context.Context) error {
+	var dialer net.Dialer
+	var err error
+	switch e.config.Network {
+	case "unix":
+		if e.conn, err = dialer.DialContext(ctx, "unixgram", e.config.Address); err == nil {
+			e.framed = false
+			return nil
+		}
+		e.conn, err = dialer.DialContext(ctx, "unix", e.config.Address)
+		e.framed = true
+	default:
+		e.conn, err = dialer.DialContext(ctx, e.config.Network, e.config.Address)
+		e.framed = e.config.Network == "tcp"
+	}
+	if err != nil {
+		e.conn = nil
+		return fmt.Errorf("failed to connect to syslog %s: %w", e.config.Address, err)
+	}
+	return nil
+}
+
+// close closes the connection
+func (e *syslogExporter) close() error {
+	if e.conn == nil {
+		return nil
+	}
+	err := e.conn.Close()
+	e.conn = nil
+	return err
+}
+
+// format returns the RFC 5424 message of a line:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG
+//
+// with the service as APP-NAME, the pid as PROCID, the stream as MSGID and
+// the parsed fields as structured data.
+func (e *syslogExporter) format(line LogLine) string {
+	record := line.Record()
+	procID := "-"
+	if record.PID != 0 {
+		procID = strconv.Itoa(record.PID)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %s %s ",
+		e.config.FacilityCode()*8+syslogSeverity(record.Severity()),
+		record.Time.UTC().Format(syslogTimeLayout),
+		syslogHeader(e.hostname, 255),
+		syslogHeader(record.Service, 48),
+		procID,
+		syslogHeader(record.Stream, 32))
+	b.WriteString(syslogStructuredData(record.Fields))
+	b.WriteString(" ")
+	b.WriteString(record.Message)
+	return b.String()
+}
+
+// syslogSeverity maps a severity to the RFC 5424 severity code
+func syslogSeverity(severity otlp.Severity) int {
+	switch {
+	case severity >= otlp.SeverityFatal:
+		return 2 // critical
+	case severity >= otlp.SeverityError:
+		return 3 // error
+	case severity >= otlp.SeverityWarn:
+		return 4 // warning
+	case severity >= otlp.SeverityInfo:
+		return 6 // informational
+	default:
+		return 7 // debug
+	}
+}
+
+// syslogHeader returns value restricted to the printable characters allowed in a header field, or the nil value
+func syslogHeader(value string, size int) string {
+	clean := strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return -1
+		}
+		return r
+	}, value)
+	if len(clean) > size {
+		clean = clean[:size]
+	}
+	if clean == "" {
+		return "-"
+	}
+	return clean
+}
+
+// syslogStructuredData returns the fields as one structured data element, or the nil value;
+// fields whose name is not a valid parameter name are left out
+func syslogStructuredData(fields map[string]any) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if key != "" && len(key) <= 32 && syslogHeader(key, 32) == key && !strings.ContainsAny(key, `="]`) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return "-"
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("[" + syslogSDID)
+	for _, key := range keys {
+		value, ok := fields[key].(string)
+		if !ok {
+			data, _ := json.Marshal(fields[key]) //nolint:errcheck // decoded from JSON
+			value = string(data)
+		}
+		value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+		b.WriteString(" " + key + `="` + value + `"`)
+	}
+	b.WriteString("]")
+	return b.String()
+}
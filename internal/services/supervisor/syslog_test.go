@@ -0,0 +1,122 @@
+package supervisor
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogExporter_Format(t *testing.T) {
+	e := newSyslogExporter(&providers.SyslogConfig{Facility: "daemon"}, "host one")
+	line := LogLine{
+		Time: time.Date(2026, 1, 2, 3, 4, 5, 6000, time.UTC), Service: "api", Stream: StreamStderr, PID: 42,
+		Text: `{"level":"info","msg":"hi","quote":"a\"b]c\\d","n":3,"bad key":1}`,
+	}
+	line.Fields = parseFields(line.Text)
+
+	assert.Equal(t, `<30>1 2026-01-02T03:04:05.000006Z hostone api 42 stderr [superviz@32473 n="3" quote="a\"b\]c\\d"] hi`, e.format(line))
+
+	e = newSyslogExporter(&providers.SyslogConfig{Facility: "local7", Hostname: "box"}, "ignored")
+	assert.Equal(t, `<187>1 2026-01-02T03:04:05.000006Z box - - stderr - multi`+"\n"+`line`,
+		e.format(LogLine{Time: line.Time, Stream: StreamStderr, Text: "multi\nline"}))
+}
+
+func TestSyslogSeverity(t *testing.T) {
+	for level, want := range map[string]int{"fatal": 2, "error": 3, "warn": 4, "info": 6, "debug": 7, "trace": 7} {
+		assert.Equal(t, want, syslogSeverity(LogRecord{Level: level}.Severity()), level)
+	}
+}
+
+func TestSyslogExporter_TCPFraming(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close() //nolint:errcheck // test cleanup
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close() //nolint:errcheck // test cleanup
+		r := bufio.NewReader(conn)
+		var messages []string
+		for len(messages) < 2 {
+			size, err := r.ReadString(' ')
+			if err != nil {
+				break
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(size))
+			message := make([]byte, n)
+			if _, err := io.ReadFull(r, message); err != nil {
+				break
+			}
+			messages = append(messages, string(message))
+		}
+		received <- messages
+	}()
+
+	e := newSyslogExporter(&providers.SyslogConfig{Network: "tcp", Address: listener.Addr().String(), Facility: "user"}, "h")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, e.export(ctx, []LogLine{at(logEpoch, "one"), at(logEpoch, "two\nlines")}))
+	require.NoError(t, e.close())
+
+	select {
+	case messages := <-received:
+		require.Len(t, messages, 2)
+		assert.True(t, strings.HasPrefix(messages[0], "<14>1 "))
+		assert.True(t, strings.HasSuffix(messages[1], " - two\nlines"), "octet counting keeps newlines inside a message")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+}
+
+// socketDir returns a short temporary directory, as socket paths are limited to about 100 bytes
+func socketDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "svz")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return dir
+}
+
+func TestSyslogExporter_UnixDatagram(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix datagram sockets are not supported on Windows")
+	}
+	path := filepath.Join(socketDir(t), "log")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close() //nolint:errcheck // test cleanup
+
+	e := newSyslogExporter(&providers.SyslogConfig{Network: "unix", Address: path, Facility: "daemon"}, "h")
+	require.NoError(t, e.export(context.Background(), []LogLine{at(logEpoch, "hello")}))
+	defer e.close() //nolint:errcheck // test cleanup
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "<30>1 2026-03-01T10:00:00.000000Z h web - stdout - hello", string(buf[:n]))
+}
+
+func TestSyslogExporter_DialFailure(t *testing.T) {
+	e := newSyslogExporter(&providers.SyslogConfig{Network: "unix", Address: filepath.Join(t.TempDir(), "missing")}, "h")
+	err := e.export(context.Background(), []LogLine{at(logEpoch, "x")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to connect to syslog")
+	assert.NoError(t, e.close())
+}
@@ -0,0 +1,573 @@
+// internal/services/upgrade.go - Fleet-wide agent upgrades with canary rollout
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/pkgmanager"
+	"github.com/kodflow/superviz.io/internal/infrastructure/transports/ssh"
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/utils"
+)
+
+// Rollout defaults applied when UpgradeOptions fields are zero.
+const (
+	// defaultCanaryHosts is the size of the first batch
+	defaultCanaryHosts = 1
+	// defaultWavePercent is the share of the fleet upgraded per wave after the canary
+	defaultWavePercent = 25
+	// defaultHealthTimeout bounds the wait for an upgraded host to become healthy
+	defaultHealthTimeout = 2 * time.Minute
+	// defaultHealthInterval is the delay between two health checks
+	defaultHealthInterval = 5 * time.Second
+)
+
+// rootProbe succeeds when the remote user is root and sudo is not needed
+const rootProbe = `test "$(id -u)" -eq 0`
+
+// UpgradeResult is the outcome of an upgrade on one host.
+type UpgradeResult string
+
+// Upgrade results reported per host.
+const (
+	// UpgradeUpgraded indicates the host now runs the target version
+	UpgradeUpgraded UpgradeResult = "upgraded"
+	// UpgradeUnchanged indicates the host already ran the target version
+	UpgradeUnchanged UpgradeResult = "unchanged"
+	// UpgradeFailed indicates the upgrade or its health check failed
+	UpgradeFailed UpgradeResult = "failed"
+	// UpgradeSkipped indicates the rollout halted before reaching the host
+	UpgradeSkipped UpgradeResult = "skipped"
+)
+
+// UpgradeOptions controls the target version and the rollout.
+type UpgradeOptions struct {
+	// Version is the exact package version to install
+	Version string
+	// Canary is the number of hosts upgraded first; any canary failure halts the rollout
+	Canary int
+	// WavePercent is the share of all hosts upgraded per wave after the canary
+	WavePercent int
+	// MaxFailurePercent halts the rollout when the failure rate exceeds it
+	MaxFailurePercent float64
+	// HealthTimeout bounds the wait for an upgraded host to report healthy
+	HealthTimeout time.Duration
+	// WavePause is waited between two waves
+	WavePause time.Duration
+}
+
+// withDefaults returns a copy of the options with zero values replaced.
+func (o UpgradeOptions) withDefaults() UpgradeOptions {
+	if o.Canary <= 0 {
+		o.Canary = defaultCanaryHosts
+	}
+	if o.WavePercent <= 0 {
+		o.WavePercent = defaultWavePercent
+	}
+	if o.HealthTimeout <= 0 {
+		o.HealthTimeout = defaultHealthTimeout
+	}
+	return o
+}
+
+// Validate checks the options.
+//
+// Returns:
+//   - err: error if the version is missing or a rollout setting is out of range
+func (o UpgradeOptions) Validate() error {
+	if o.Version == "" {
+		return errors.New("a target version is required")
+	}
+	if o.WavePercent < 0 || o.WavePercent > 100 {
+		return fmt.Errorf("wave percentage must be between 1 and 100, got %d", o.WavePercent)
+	}
+	if o.MaxFailurePercent < 0 || o.MaxFailurePercent > 100 {
+		return fmt.Errorf("maximum failure percentage must be between 0 and 100, got %g", o.MaxFailurePercent)
+	}
+	if o.Canary < 0 {
+		return fmt.Errorf("canary size cannot be negative, got %d", o.Canary)
+	}
+	return nil
+}
+
+// HostUpgrade is the upgrade outcome of one host.
+type HostUpgrade struct {
+	// Target is the user@host that was upgraded
+	Target string `json:"target" yaml:"target"`
+	// Wave is the rollout wave of the host, 0 for the canary
+	Wave int `json:"wave" yaml:"wave"`
+	// Before is the version installed before the upgrade
+	Before string `json:"before,omitempty" yaml:"before,omitempty"`
+	// After is the version installed after the upgrade
+	After string `json:"after,omitempty" yaml:"after,omitempty"`
+	// Result is the outcome for this host
+	Result UpgradeResult `json:"result" yaml:"result"`
+	// Error explains a failure
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// UpgradeReport aggregates the outcome of a rollout.
+type UpgradeReport struct {
+	// Version is the target version
+	Version string `json:"version" yaml:"version"`
+	// Hosts holds one entry per host, in rollout order
+	Hosts []HostUpgrade `json:"hosts" yaml:"hosts"`
+	// Halted is true when the rollout stopped before every host was processed
+	Halted bool `json:"halted" yaml:"halted"`
+	// HaltReason explains why the rollout stopped
+	HaltReason string `json:"halt_reason,omitempty" yaml:"halt_reason,omitempty"`
+}
+
+// Count returns the number of hosts with the given result.
+//
+// Parameters:
+//   - result: UpgradeResult outcome to count
+//
+// Returns:
+//   - count: int matching hosts
+func (r *UpgradeReport) Count(result UpgradeResult) int {
+	count := 0
+	for _, host := range r.Hosts {
+		if host.Result == result {
+			count++
+		}
+	}
+	return count
+}
+
+// Format returns a human-readable table of the report.
+//
+//	Upgrade to 1.3.0-1
+//	WAVE  HOST         BEFORE   AFTER    RESULT
+//	0     admin@web-1  1.2.0-1  1.3.0-1  upgraded
+//	Upgraded: 1, unchanged: 0, failed: 0, skipped: 0
+//
+// Returns:
+//   - formatted: string multi-line report ending with a newline
+func (r *UpgradeReport) Format() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Upgrade to %s\n", r.Version)
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "WAVE\tHOST\tBEFORE\tAFTER\tRESULT")
+	for _, host := range r.Hosts {
+		result := string(host.Result)
+		if host.Error != "" {
+			result += ": " + host.Error
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", host.Wave, host.Target, dash(host.Before), dash(host.After), result)
+	}
+	tw.Flush() //nolint:errcheck // strings.Builder cannot fail
+
+	if r.Halted {
+		fmt.Fprintf(&b, "Halted: %s\n", r.HaltReason)
+	}
+	fmt.Fprintf(&b, "Upgraded: %d, unchanged: %d, failed: %d, skipped: %d\n",
+		r.Count(UpgradeUpgraded), r.Count(UpgradeUnchanged), r.Count(UpgradeFailed), r.Count(UpgradeSkipped))
+	return b.String()
+}
+
+// dash returns s, or "-" when s is empty
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// planWaves splits n hosts into a canary batch followed by percentage waves.
+//
+// Parameters:
+//   - n: int number of hosts
+//   - canary: int size of the first batch
+//   - percent: int share of n per following wave, rounded up
+//
+// Returns:
+//   - waves: [][]int host indexes per wave, in order
+func planWaves(n, canary, percent int) [][]int {
+	size := (n*percent + 99) / 100
+	if size < 1 {
+		size = 1
+	}
+
+	var waves [][]int
+	next := 0
+	for next < n {
+		end := next + size
+		if next == 0 {
+			end = canary
+		}
+		if end > n {
+			end = n
+		}
+		wave := make([]int, 0, end-next)
+		for i := next; i < end; i++ {
+			wave = append(wave, i)
+		}
+		waves = append(waves, wave)
+		next = end
+	}
+	return waves
+}
+
+// UpgradeService upgrades the superviz package across hosts.
+type UpgradeService struct {
+	// newClient creates one SSH client per host
+	newClient func() ssh.Client
+	// newChecker creates the checker reading versions and service state
+	newChecker func(client ssh.Client, config *providers.InstallConfig) StatusChecker
+	// pkg is the package name to upgrade
+	pkg string
+	// concurrency bounds the number of hosts upgraded at once within a wave
+	concurrency int
+	// healthInterval is the delay between two health checks
+	healthInterval time.Duration
+	// versions enforces the agent compatibility window
+	versions *VersionService
+}
+
+// UpgradeServiceOptions contains options for creating an UpgradeService.
+type UpgradeServiceOptions struct {
+	// Provider supplies repository and package information
+	Provider providers.InstallProvider
+	// NewClient overrides the SSH client factory
+	NewClient func() ssh.Client
+	// NewChecker overrides the status checker factory
+	NewChecker func(client ssh.Client, config *providers.InstallConfig) StatusChecker
+	// Concurrency bounds the number of hosts upgraded at once within a wave (default 8)
+	Concurrency int
+	// HealthInterval overrides the delay between two health checks (default 5s)
+	HealthInterval time.Duration
+	// Versions enforces the agent compatibility window, nil for the running binary's
+	Versions *VersionService
+}
+
+// NewUpgradeService creates a new upgrade service with the given options.
+//
+// Parameters:
+//   - opts: *UpgradeServiceOptions dependencies, nil for defaults
+//
+// Returns:
+//   - service: *UpgradeService ready for use
+func NewUpgradeService(opts *UpgradeServiceOptions) *UpgradeService {
+	if opts == nil {
+		opts = &UpgradeServiceOptions{}
+	}
+
+	provider := opts.Provider
+	if provider == nil {
+		provider = providers.DefaultInstallProvider()
+	}
+
+	s := &UpgradeService{
+		newClient:      opts.NewClient,
+		newChecker:     opts.NewChecker,
+		pkg:            provider.GetPackageName(),
+		concurrency:    opts.Concurrency,
+		healthInterval: opts.HealthInterval,
+		versions:       opts.Versions,
+	}
+	if s.newClient == nil {
+		s.newClient = func() ssh.Client { return ssh.NewClient(nil) }
+	}
+	if s.newChecker == nil {
+		s.newChecker = statusCheckerFactory(provider)
+	}
+	if s.concurrency <= 0 {
+		s.concurrency = defaultStatusConcurrency
+	}
+	if s.healthInterval <= 0 {
+		s.healthInterval = defaultHealthInterval
+	}
+	if s.versions == nil {
+		s.versions = NewVersionService(nil)
+	}
+
+	return s
+}
+
+// Run upgrades the hosts wave by wave and returns the report.
+//
+// The canary batch runs first and any failure in it halts the rollout. After
+// each following wave the cumulative failure rate is compared with
+// MaxFailurePercent; hosts not reached are reported as skipped.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - progress: io.Writer receiving one line per wave, io.Discard to disable
+//   - configs: []*providers.InstallConfig validated per-host configurations
+//   - opts: UpgradeOptions target version and rollout settings
+//
+// Returns:
+//   - report: *UpgradeReport outcome per host, in rollout order
+func (s *UpgradeService) Run(ctx context.Context, progress io.Writer, configs []*providers.InstallConfig, opts UpgradeOptions) *UpgradeReport {
+	opts = opts.withDefaults()
+	report := &UpgradeReport{Version: opts.Version, Hosts: make([]HostUpgrade, len(configs))}
+
+	waves := planWaves(len(configs), opts.Canary, opts.WavePercent)
+	for wave, indexes := range waves {
+		for _, i := range indexes {
+			report.Hosts[i] = HostUpgrade{Target: configs[i].Target, Wave: wave, Result: UpgradeSkipped}
+		}
+	}
+
+	processed, failed := 0, 0
+	for wave, indexes := range waves {
+		if reason := s.haltReason(ctx, wave, processed, failed, opts); reason != "" {
+			report.Halted = true
+			report.HaltReason = reason
+			break
+		}
+		if wave > 0 && opts.WavePause > 0 {
+			select {
+			case <-ctx.Done():
+				report.Halted = true
+				report.HaltReason = fmt.Sprintf("cancelled: %v", ctx.Err())
+			case <-time.After(opts.WavePause):
+			}
+			if report.Halted {
+				break
+			}
+		}
+
+		label := fmt.Sprintf("Wave %d/%d", wave, len(waves)-1)
+		if wave == 0 {
+			label = "Canary"
+		}
+		fmt.Fprintf(progress, "%s: upgrading %d host(s)\n", label, len(indexes)) //nolint:errcheck // progress is informational
+
+		s.runWave(ctx, configs, indexes, wave, opts, report)
+		for _, i := range indexes {
+			processed++
+			if report.Hosts[i].Result == UpgradeFailed {
+				failed++
+			}
+		}
+	}
+
+	return report
+}
+
+// haltReason returns why the rollout must stop before the given wave, or "".
+func (s *UpgradeService) haltReason(ctx context.Context, wave, processed, failed int, opts UpgradeOptions) string {
+	switch {
+	case ctx.Err() != nil:
+		return fmt.Sprintf("cancelled: %v", ctx.Err())
+	case wave == 1 && failed > 0:
+		return fmt.Sprintf("%d canary host(s) failed", failed)
+	case processed > 0 && float64(failed)*100 > opts.MaxFailurePercent*float64(processed):
+		return fmt.Sprintf("failure rate %.0f%% exceeds %g%% after wave %d", float64(failed)*100/float64(processed), opts.MaxFailurePercent, wave-1)
+	default:
+		return ""
+	}
+}
+
+// runWave upgrades the hosts of one wave concurrently.
+func (s *UpgradeService) runWave(ctx context.Context, configs []*providers.InstallConfig, indexes []int, wave int, opts UpgradeOptions, report *UpgradeReport) {
+	sem := make(chan struct{}, s.concurrency)
+	var wg sync.WaitGroup
+	for _, i := range indexes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			result := s.upgradeHost(ctx, configs[i], opts)
+			result.Wave = wave
+			report.Hosts[i] = result
+		}()
+	}
+	wg.Wait()
+}
+
+// upgradeHost upgrades one host and waits for it to report healthy.
+func (s *UpgradeService) upgradeHost(ctx context.Context, config *providers.InstallConfig, opts UpgradeOptions) HostUpgrade {
+	result := HostUpgrade{Target: config.Target, Result: UpgradeFailed}
+
+	client := s.newClient()
+	if err := client.Connect(ctx, newSSHConfig(config)); err != nil {
+		result.Error = wrapConnectionError(err, config.Target).Error()
+		return result
+	}
+	defer client.Close() //nolint:errcheck // best effort, the outcome is already known
+
+	checker := s.newChecker(client, config)
+	before := checker.Check(ctx, config.Target)
+	if before.Error != "" {
+		result.Error = before.Error
+		return result
+	}
+	if !before.Package.Installed {
+		result.Error = "superviz is not installed, run svz install first"
+		return result
+	}
+	result.Before = before.Package.Version
+
+	if versionMatches(before.Package.Version, opts.Version) {
+		result.After = before.Package.Version
+		result.Result = UpgradeUnchanged
+		return result
+	}
+
+	commands, err := s.upgradeCommands(ctx, before.Distro, opts.Version)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	asRoot := client.Execute(ctx, rootProbe) == nil
+	for _, command := range commands {
+		if asRoot {
+			command = strings.TrimPrefix(command, "sudo ")
+		}
+		if err := client.Execute(ctx, command); err != nil {
+			result.Error = fmt.Sprintf("%s: %v", command, err)
+			return result
+		}
+	}
+
+	after, err := s.awaitHealthy(ctx, checker, config.Target, opts)
+	if after != nil {
+		result.After = after.Package.Version
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Result = UpgradeUpgraded
+	return result
+}
+
+// upgradeCommands returns the package manager commands installing version.
+func (s *UpgradeService) upgradeCommands(ctx context.Context, distro, version string) ([]string, error) {
+	manager, err := pkgmanager.ForDistro(distro)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := pkgmanager.PackageSpec(manager, s.pkg, version)
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := manager.Update(ctx)
+	if err != nil {
+		return nil, err
+	}
+	install, err := manager.Install(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	return []string{update, install}, nil
+}
+
+// awaitHealthy polls the host until it runs version with the service up.
+//
+// Returns:
+//   - status: *HostStatus last observed state, nil if none was collected
+//   - err: error if the host is not healthy within the health timeout
+func (s *UpgradeService) awaitHealthy(ctx context.Context, checker StatusChecker, target string, opts UpgradeOptions) (*HostStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, opts.HealthTimeout)
+	defer cancel()
+
+	var last *HostStatus
+	for {
+		status := checker.Check(ctx, target)
+		if status.Error == "" {
+			last = status
+			if versionMatches(status.Package.Version, opts.Version) && status.Service == ServiceRunning {
+				return status, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return last, unhealthyError(last, opts)
+		case <-time.After(s.healthInterval):
+		}
+	}
+}
+
+// versionMatches reports whether the installed version is the target.
+//
+// RPM hosts report the release and the distribution tag ("1.3.0-1.el9"):
+// a target without release matches every release and a target with a
+// release matches every distribution tag.
+func versionMatches(installed, target string) bool {
+	switch {
+	case installed == target:
+		return true
+	case strings.HasPrefix(installed, target+"-"):
+		return true
+	default:
+		return strings.Contains(target, "-") && strings.HasPrefix(installed, target+".")
+	}
+}
+
+// unhealthyError describes why a host did not pass its health check
+func unhealthyError(last *HostStatus, opts UpgradeOptions) error {
+	switch {
+	case last == nil:
+		return fmt.Errorf("health check timed out after %s: host did not respond", opts.HealthTimeout)
+	case !versionMatches(last.Package.Version, opts.Version):
+		return fmt.Errorf("health check timed out after %s: version %s installed instead of %s", opts.HealthTimeout, dash(last.Package.Version), opts.Version)
+	default:
+		return fmt.Errorf("health check timed out after %s: service %s", opts.HealthTimeout, last.Service)
+	}
+}
+
+// Upgrade runs the rollout and writes the report in the requested format.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - w: io.Writer receiving progress and the report
+//   - configs: []*providers.InstallConfig validated per-host configurations
+//   - opts: UpgradeOptions target version and rollout settings
+//   - format: utils.OutputFormat text table, or the report encoded as JSON or YAML
+//
+// Returns:
+//   - err: error if the options are invalid, writing fails, a host failed or the rollout halted
+func (s *UpgradeService) Upgrade(ctx context.Context, w io.Writer, configs []*providers.InstallConfig, opts UpgradeOptions, format utils.OutputFormat) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+	if len(configs) == 0 {
+		return errors.New("no hosts to upgrade")
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	if err := s.versions.CheckAgentVersion(opts.Version); err != nil {
+		return err
+	}
+
+	progress := io.Discard
+	if format == utils.OutputText {
+		progress = w
+	}
+	report := s.Run(ctx, progress, configs, opts)
+
+	if format == utils.OutputText {
+		if _, err := io.WriteString(w, report.Format()); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	} else if err := utils.EncodeOutput(w, format, report); err != nil {
+		return err
+	}
+
+	switch {
+	case report.Halted:
+		return fmt.Errorf("%w: rollout halted, %s", ErrUpgradeFailed, report.HaltReason)
+	case report.Count(UpgradeFailed) > 0:
+		return fmt.Errorf("%w: %d of %d hosts", ErrUpgradeFailed, report.Count(UpgradeFailed), len(configs))
+	default:
+		return nil
+	}
+}
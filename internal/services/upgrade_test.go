@@ -0,0 +1,390 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kodflow/superviz.io/internal/infrastructure/transports/ssh"
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/utils"
+)
+
+// upgradeHostFake simulates a host whose version changes once the install command ran.
+type upgradeHostFake struct {
+	mu        sync.Mutex
+	distro    string
+	version   string
+	installed string
+	running   bool
+	failCmd   string
+	commands  []string
+	upgraded  bool
+}
+
+// Connect accepts every connection.
+func (h *upgradeHostFake) Connect(context.Context, *ssh.Config) error { return nil }
+
+// Execute records the command and applies the upgrade once install ran.
+func (h *upgradeHostFake) Execute(_ context.Context, command string) error {
+	if command == rootProbe {
+		return errors.New("exit status 1")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.commands = append(h.commands, command)
+	if command == h.failCmd {
+		return errors.New("exit status 100")
+	}
+	if h.installed != "" && strings.Contains(command, " install ") {
+		h.upgraded = true
+	}
+	return nil
+}
+
+// Close does nothing.
+func (h *upgradeHostFake) Close() error { return nil }
+
+// Check reports the fake host state.
+func (h *upgradeHostFake) Check(_ context.Context, target string) *HostStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	version := h.version
+	if h.upgraded {
+		version = h.installed
+	}
+	service := ServiceStopped
+	if h.running {
+		service = ServiceRunning
+	}
+	distro, manager := "ubuntu", "apt"
+	if h.distro != "" {
+		distro, manager = h.distro, "dnf"
+	}
+	return &HostStatus{
+		Target:  target,
+		Distro:  distro,
+		Package: PackageStatus{Manager: manager, Installed: version != "", Version: version},
+		Service: service,
+	}
+}
+
+// fakeConfigs returns configurations for n hosts named host-0..host-n-1.
+func fakeConfigs(n int) []*providers.InstallConfig {
+	configs := make([]*providers.InstallConfig, n)
+	for i := range configs {
+		configs[i] = &providers.InstallConfig{User: "u", Host: fmt.Sprintf("host-%d", i), Target: fmt.Sprintf("u@host-%d", i)}
+	}
+	return configs
+}
+
+// fakeUpgradeService builds a service whose clients and checkers share the fake hosts.
+func fakeUpgradeService(hosts []*upgradeHostFake) *UpgradeService {
+	byTarget := make(map[string]*upgradeHostFake, len(hosts))
+	for i, h := range hosts {
+		byTarget[fmt.Sprintf("u@host-%d", i)] = h
+	}
+
+	return NewUpgradeService(&UpgradeServiceOptions{
+		NewClient: func() ssh.Client {
+			return &lazyClient{resolve: func(config *ssh.Config) ssh.Client {
+				return byTarget[config.User+"@"+config.Host]
+			}}
+		},
+		NewChecker: func(_ ssh.Client, config *providers.InstallConfig) StatusChecker {
+			return byTarget[config.Target]
+		},
+		HealthInterval: time.Millisecond,
+	})
+}
+
+// lazyClient binds to a fake host's client on Connect, once the host is known.
+type lazyClient struct {
+	resolve func(config *ssh.Config) ssh.Client
+	client  ssh.Client
+}
+
+func (c *lazyClient) Connect(ctx context.Context, config *ssh.Config) error {
+	c.client = c.resolve(config)
+	return c.client.Connect(ctx, config)
+}
+
+func (c *lazyClient) Execute(ctx context.Context, command string) error {
+	return c.client.Execute(ctx, command)
+}
+
+func (c *lazyClient) Close() error {
+	return c.client.Close()
+}
+
+func TestPlanWaves(t *testing.T) {
+	assert.Equal(t, [][]int{{0}}, planWaves(1, 1, 25))
+	assert.Equal(t, [][]int{{0}, {1, 2, 3}, {4, 5, 6}, {7, 8, 9}}, planWaves(10, 1, 25))
+	assert.Equal(t, [][]int{{0, 1}, {2, 3, 4, 5, 6, 7, 8, 9}}, planWaves(10, 2, 100))
+	assert.Equal(t, [][]int{{0, 1, 2}}, planWaves(3, 5, 50))
+	assert.Equal(t, [][]int{{0}, {1}, {2}}, planWaves(3, 1, 1))
+	assert.Empty(t, planWaves(0, 1, 25))
+}
+
+func TestUpgradeOptions_Validate(t *testing.T) {
+	assert.NoError(t, UpgradeOptions{Version: "1.3.0"}.Validate())
+	assert.ErrorContains(t, UpgradeOptions{}.Validate(), "target version is required")
+	assert.ErrorContains(t, UpgradeOptions{Version: "1", WavePercent: 101}.Validate(), "wave percentage")
+	assert.ErrorContains(t, UpgradeOptions{Version: "1", MaxFailurePercent: -1}.Validate(), "maximum failure percentage")
+	assert.ErrorContains(t, UpgradeOptions{Version: "1", Canary: -1}.Validate(), "canary size")
+}
+
+func TestUpgradeService_Run_Success(t *testing.T) {
+	hosts := []*upgradeHostFake{
+		{version: "1.2.0", installed: "1.3.0", running: true},
+		{version: "1.3.0", installed: "1.3.0", running: true},
+		{version: "1.2.0", installed: "1.3.0", running: true},
+	}
+	service := fakeUpgradeService(hosts)
+
+	var progress bytes.Buffer
+	report := service.Run(context.Background(), &progress, fakeConfigs(3), UpgradeOptions{Version: "1.3.0", WavePercent: 50})
+
+	assert.False(t, report.Halted)
+	assert.Equal(t, []HostUpgrade{
+		{Target: "u@host-0", Wave: 0, Before: "1.2.0", After: "1.3.0", Result: UpgradeUpgraded},
+		{Target: "u@host-1", Wave: 1, Before: "1.3.0", After: "1.3.0", Result: UpgradeUnchanged},
+		{Target: "u@host-2", Wave: 1, Before: "1.2.0", After: "1.3.0", Result: UpgradeUpgraded},
+	}, report.Hosts)
+	assert.Equal(t, "Canary: upgrading 1 host(s)\nWave 1/1: upgrading 2 host(s)\n", progress.String())
+
+	assert.Equal(t, []string{"sudo apt update", "sudo apt install -y superviz=1.3.0"}, hosts[0].commands)
+	assert.Empty(t, hosts[1].commands)
+}
+
+func TestUpgradeService_Run_RPMRelease(t *testing.T) {
+	hosts := []*upgradeHostFake{
+		{distro: "fedora", version: "1.2.0-1.fc40", installed: "1.3.0-1.fc40", running: true},
+		{distro: "fedora", version: "1.3.0-1.fc40", installed: "1.3.0-1.fc40", running: true},
+	}
+	service := fakeUpgradeService(hosts)
+
+	report := service.Run(context.Background(), io.Discard, fakeConfigs(2), UpgradeOptions{Version: "1.3.0-1", HealthTimeout: time.Second})
+
+	assert.False(t, report.Halted)
+	assert.Equal(t, []HostUpgrade{
+		{Target: "u@host-0", Wave: 0, Before: "1.2.0-1.fc40", After: "1.3.0-1.fc40", Result: UpgradeUpgraded},
+		{Target: "u@host-1", Wave: 1, Before: "1.3.0-1.fc40", After: "1.3.0-1.fc40", Result: UpgradeUnchanged},
+	}, report.Hosts)
+	assert.Contains(t, hosts[0].commands, "sudo dnf install -y superviz-1.3.0-1")
+}
+
+func TestVersionMatches(t *testing.T) {
+	assert.True(t, versionMatches("1.3.0", "1.3.0"))
+	assert.True(t, versionMatches("1.3.0-1.el9", "1.3.0"))
+	assert.True(t, versionMatches("1.3.0-1.el9", "1.3.0-1"))
+	assert.True(t, versionMatches("1.3.0-r0", "1.3.0"))
+	assert.False(t, versionMatches("1.3.0-10.el9", "1.3.0-1"))
+	assert.False(t, versionMatches("1.3.0.1-1", "1.3.0"))
+	assert.False(t, versionMatches("1.3.10", "1.3.1"))
+	assert.False(t, versionMatches("", "1.3.0"))
+}
+
+func TestUpgradeService_Run_CanaryFailureHalts(t *testing.T) {
+	hosts := []*upgradeHostFake{
+		{version: "1.2.0", installed: "1.3.0", running: true, failCmd: "sudo apt install -y superviz=1.3.0"},
+		{version: "1.2.0", installed: "1.3.0", running: true},
+	}
+	service := fakeUpgradeService(hosts)
+
+	report := service.Run(context.Background(), io.Discard, fakeConfigs(2), UpgradeOptions{Version: "1.3.0", MaxFailurePercent: 100})
+
+	assert.True(t, report.Halted)
+	assert.Equal(t, "1 canary host(s) failed", report.HaltReason)
+	assert.Equal(t, UpgradeFailed, report.Hosts[0].Result)
+	assert.Contains(t, report.Hosts[0].Error, "exit status 100")
+	assert.Equal(t, "1.2.0", report.Hosts[0].Before)
+	assert.Equal(t, HostUpgrade{Target: "u@host-1", Wave: 1, Result: UpgradeSkipped}, report.Hosts[1])
+	assert.Empty(t, hosts[1].commands)
+}
+
+func TestUpgradeService_Run_FailureRateHalts(t *testing.T) {
+	hosts := []*upgradeHostFake{
+		{version: "1.2.0", installed: "1.3.0", running: true},
+		{version: "1.2.0", installed: "1.3.0", running: false},
+		{version: "1.2.0", installed: "1.3.0", running: true},
+		{version: "1.2.0", installed: "1.3.0", running: true},
+	}
+	service := fakeUpgradeService(hosts)
+	service.healthInterval = time.Millisecond
+
+	report := service.Run(context.Background(), io.Discard, fakeConfigs(4), UpgradeOptions{
+		Version:           "1.3.0",
+		WavePercent:       25,
+		MaxFailurePercent: 10,
+		HealthTimeout:     20 * time.Millisecond,
+	})
+
+	assert.True(t, report.Halted)
+	assert.Equal(t, "failure rate 50% exceeds 10% after wave 1", report.HaltReason)
+	assert.Equal(t, UpgradeFailed, report.Hosts[1].Result)
+	assert.Equal(t, "1.3.0", report.Hosts[1].After)
+	assert.Contains(t, report.Hosts[1].Error, "service stopped")
+	assert.Equal(t, UpgradeSkipped, report.Hosts[2].Result)
+	assert.Equal(t, UpgradeSkipped, report.Hosts[3].Result)
+}
+
+func TestUpgradeService_Run_CancelDuringPause(t *testing.T) {
+	hosts := []*upgradeHostFake{
+		{version: "1.2.0", installed: "1.3.0", running: true},
+		{version: "1.2.0", installed: "1.3.0", running: true},
+	}
+	service := fakeUpgradeService(hosts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var progress bytes.Buffer
+	time.AfterFunc(50*time.Millisecond, cancel)
+	report := service.Run(ctx, &progress, fakeConfigs(2), UpgradeOptions{Version: "1.3.0", WavePause: time.Minute, HealthTimeout: time.Second})
+
+	assert.True(t, report.Halted)
+	assert.Equal(t, "cancelled: context canceled", report.HaltReason)
+	assert.Equal(t, UpgradeUpgraded, report.Hosts[0].Result)
+	assert.Equal(t, HostUpgrade{Target: "u@host-1", Wave: 1, Result: UpgradeSkipped}, report.Hosts[1])
+	assert.Empty(t, hosts[1].commands)
+	assert.Equal(t, "Canary: upgrading 1 host(s)\n", progress.String())
+}
+
+func TestUpgradeService_UpgradeHost_Errors(t *testing.T) {
+	t.Run("connection", func(t *testing.T) {
+		client := &mockSSHClient{}
+		client.On("Connect", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+		service := NewUpgradeService(&UpgradeServiceOptions{NewClient: func() ssh.Client { return client }})
+
+		result := service.upgradeHost(context.Background(), &providers.InstallConfig{Target: "u@h"}, UpgradeOptions{Version: "1.3.0"})
+		assert.Equal(t, UpgradeFailed, result.Result)
+		assert.Contains(t, result.Error, "connection refused")
+	})
+
+	t.Run("not installed", func(t *testing.T) {
+		hosts := []*upgradeHostFake{{}}
+		report := fakeUpgradeService(hosts).Run(context.Background(), io.Discard, fakeConfigs(1), UpgradeOptions{Version: "1.3.0"})
+		assert.Equal(t, "superviz is not installed, run svz install first", report.Hosts[0].Error)
+	})
+
+	t.Run("wrong version after upgrade", func(t *testing.T) {
+		hosts := []*upgradeHostFake{{version: "1.2.0", installed: "1.2.5", running: true}}
+		report := fakeUpgradeService(hosts).Run(context.Background(), io.Discard, fakeConfigs(1), UpgradeOptions{Version: "1.3.0", HealthTimeout: 10 * time.Millisecond})
+		assert.Equal(t, "1.2.5", report.Hosts[0].After)
+		assert.Contains(t, report.Hosts[0].Error, "version 1.2.5 installed instead of 1.3.0")
+	})
+
+	t.Run("as root", func(t *testing.T) {
+		var commands []string
+		client := &mockSSHClient{}
+		client.On("Connect", mock.Anything, mock.Anything).Return(nil)
+		client.On("Close").Return(nil)
+		client.On("Execute", mock.Anything, mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+			commands = append(commands, args.String(1))
+		}).Return(nil)
+		host := &upgradeHostFake{version: "1.2.0", installed: "1.3.0", running: true, upgraded: true}
+		service := NewUpgradeService(&UpgradeServiceOptions{
+			NewClient: func() ssh.Client { return client },
+			NewChecker: func(ssh.Client, *providers.InstallConfig) StatusChecker {
+				return &beforeAfterChecker{before: "1.2.0", host: host}
+			},
+		})
+
+		result := service.upgradeHost(context.Background(), &providers.InstallConfig{Target: "u@h"}, UpgradeOptions{Version: "1.3.0", HealthTimeout: time.Second})
+		assert.Equal(t, UpgradeUpgraded, result.Result)
+		assert.Equal(t, []string{rootProbe, "apt update", "apt install -y superviz=1.3.0"}, commands)
+	})
+}
+
+// beforeAfterChecker reports the before version once, then delegates to host.
+type beforeAfterChecker struct {
+	before string
+	host   *upgradeHostFake
+	calls  int
+}
+
+func (c *beforeAfterChecker) Check(ctx context.Context, target string) *HostStatus {
+	c.calls++
+	status := c.host.Check(ctx, target)
+	if c.calls == 1 {
+		status.Package.Version = c.before
+	}
+	return status
+}
+
+func TestUpgradeService_Upgrade(t *testing.T) {
+	hosts := []*upgradeHostFake{
+		{version: "1.2.0", installed: "1.3.0", running: true},
+		{version: "1.2.0", installed: "1.3.0", running: true, failCmd: "sudo apt update"},
+	}
+	service := fakeUpgradeService(hosts)
+
+	var buf bytes.Buffer
+	err := service.Upgrade(context.Background(), &buf, fakeConfigs(2), UpgradeOptions{Version: "1.3.0", MaxFailurePercent: 50}, utils.OutputJSON)
+	require.ErrorIs(t, err, ErrUpgradeFailed)
+	assert.ErrorContains(t, err, "1 of 2 hosts")
+
+	var report UpgradeReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+	assert.Equal(t, "1.3.0", report.Version)
+	assert.Equal(t, UpgradeUpgraded, report.Hosts[0].Result)
+	assert.Equal(t, UpgradeFailed, report.Hosts[1].Result)
+}
+
+func TestUpgradeService_Upgrade_Text(t *testing.T) {
+	hosts := []*upgradeHostFake{{version: "1.2.0", installed: "1.3.0", running: true}}
+	service := fakeUpgradeService(hosts)
+
+	var buf bytes.Buffer
+	err := service.Upgrade(context.Background(), &buf, fakeConfigs(1), UpgradeOptions{Version: "1.3.0"}, utils.OutputText)
+	require.NoError(t, err)
+	assert.Equal(t, `Canary: upgrading 1 host(s)
+Upgrade to 1.3.0
+WAVE  HOST      BEFORE  AFTER  RESULT
+0     u@host-0  1.2.0   1.3.0  upgraded
+Upgraded: 1, unchanged: 0, failed: 0, skipped: 0
+`, buf.String())
+}
+
+func TestUpgradeService_Upgrade_InvalidArguments(t *testing.T) {
+	service := NewUpgradeService(nil)
+	configs := fakeConfigs(1)
+
+	assert.ErrorIs(t, service.Upgrade(context.Background(), nil, configs, UpgradeOptions{Version: "1"}, utils.OutputText), ErrNilWriter)
+	assert.ErrorContains(t, service.Upgrade(context.Background(), io.Discard, nil, UpgradeOptions{Version: "1"}, utils.OutputText), "no hosts to upgrade")
+	assert.ErrorContains(t, service.Upgrade(context.Background(), io.Discard, configs, UpgradeOptions{}, utils.OutputText), "target version is required")
+}
+
+func TestUpgradeService_Upgrade_IncompatibleVersion(t *testing.T) {
+	hosts := []*upgradeHostFake{{version: "1.2.0", installed: "1.5.0", running: true}}
+	service := fakeUpgradeService(hosts)
+	service.versions = NewVersionService(releaseVersionProvider("1.3.2"))
+
+	err := service.Upgrade(context.Background(), io.Discard, fakeConfigs(1), UpgradeOptions{Version: "1.5.0-1"}, utils.OutputText)
+	require.ErrorIs(t, err, ErrIncompatibleVersion)
+	assert.Empty(t, hosts[0].commands, "no host is touched")
+}
+
+func TestUpgradeReport_FormatHalted(t *testing.T) {
+	report := &UpgradeReport{
+		Version:    "1.3.0",
+		Hosts:      []HostUpgrade{{Target: "u@h", Result: UpgradeFailed, Error: "boom"}},
+		Halted:     true,
+		HaltReason: "1 canary host(s) failed",
+	}
+
+	formatted := report.Format()
+	assert.Contains(t, formatted, "0     u@h   -       -      failed: boom\n")
+	assert.Contains(t, formatted, "Halted: 1 canary host(s) failed\n")
+}
@@ -0,0 +1,78 @@
+// internal/services/verify.go - Standalone verification of an integrity manifest
+package services
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/services/integrity"
+	"github.com/kodflow/superviz.io/internal/utils"
+)
+
+// VerifyOptions selects how the manifest itself is authenticated.
+type VerifyOptions struct {
+	// Signature is the file holding the base64 Ed25519 signature of the manifest, empty for no signature check
+	Signature string
+	// PublicKey is the base64 Ed25519 key verifying the signature
+	PublicKey string
+}
+
+// VerifyService checks the files listed in an integrity manifest.
+type VerifyService struct{}
+
+// NewVerifyService creates a new verify service.
+//
+// Returns:
+//   - service: *VerifyService ready for use
+func NewVerifyService() *VerifyService {
+	return &VerifyService{}
+}
+
+// Verify checks a manifest signature, then the digest of every listed file,
+// and writes one line per file.
+//
+// Parameters:
+//   - w: io.Writer destination of the report
+//   - path: string manifest location
+//   - opts: VerifyOptions signature file and public key
+//   - format: utils.OutputFormat text, json or yaml
+//
+// Returns:
+//   - err: error if the manifest cannot be loaded or verified, ErrIntegrityFailed when a file fails
+func (s *VerifyService) Verify(w io.Writer, path string, opts VerifyOptions, format utils.OutputFormat) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+	if (opts.Signature == "") != (opts.PublicKey == "") {
+		return errors.New("--signature and --public-key go together")
+	}
+
+	var key ed25519.PublicKey
+	if opts.PublicKey != "" {
+		var err error
+		if key, err = providers.ParsePublicKey(opts.PublicKey); err != nil {
+			return err
+		}
+	}
+	manifest, err := integrity.LoadManifest(path, opts.Signature, key)
+	if err != nil {
+		return err
+	}
+
+	report := integrity.Check(&providers.IntegrityConfig{Files: manifest.Files})
+	if format == utils.OutputText {
+		if _, err := io.WriteString(w, report.Format()); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	} else if err := utils.EncodeOutput(w, format, report); err != nil {
+		return err
+	}
+
+	if failed := len(report.Failed()); failed > 0 {
+		return fmt.Errorf("%w: %d of %d files", ErrIntegrityFailed, failed, len(report.Results))
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeVerifyManifest writes a file and a signed manifest listing it, and
+// returns the manifest, signature and base64 public key
+func writeVerifyManifest(t *testing.T) (string, string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "vault"), []byte("vault"), 0o600))
+	sum := sha256.Sum256([]byte("vault"))
+	content := "version: 1\nfiles:\n  - path: vault\n    sha256: " + hex.EncodeToString(sum[:]) + "\n"
+
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	manifest := filepath.Join(dir, "manifest.yaml")
+	signature := manifest + ".sig"
+	require.NoError(t, os.WriteFile(manifest, []byte(content), 0o600))
+	require.NoError(t, os.WriteFile(signature, []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte(content)))), 0o600))
+	return manifest, signature, base64.StdEncoding.EncodeToString(public)
+}
+
+func TestVerifyService_Verify(t *testing.T) {
+	manifest, signature, key := writeVerifyManifest(t)
+	service := NewVerifyService()
+
+	var out bytes.Buffer
+	require.NoError(t, service.Verify(&out, manifest, VerifyOptions{}, utils.OutputText))
+	assert.Equal(t, filepath.Join(filepath.Dir(manifest), "vault")+": ok\n1 checked, 0 failed\n", out.String())
+
+	out.Reset()
+	require.NoError(t, service.Verify(&out, manifest, VerifyOptions{Signature: signature, PublicKey: key}, utils.OutputJSON))
+	var report struct {
+		Results []struct {
+			Status string `json:"status"`
+		} `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, "ok", report.Results[0].Status)
+
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(manifest), "vault"), []byte("tampered"), 0o600))
+	out.Reset()
+	err := service.Verify(&out, manifest, VerifyOptions{Signature: signature, PublicKey: key}, utils.OutputText)
+	require.ErrorIs(t, err, ErrIntegrityFailed)
+	assert.EqualError(t, err, "integrity check failed: 1 of 1 files")
+	assert.Contains(t, out.String(), "vault: sha256 mismatch")
+}
+
+func TestVerifyService_Errors(t *testing.T) {
+	manifest, signature, _ := writeVerifyManifest(t)
+	service := NewVerifyService()
+
+	require.ErrorIs(t, service.Verify(nil, manifest, VerifyOptions{}, utils.OutputText), ErrNilWriter)
+
+	err := service.Verify(&bytes.Buffer{}, manifest, VerifyOptions{Signature: signature}, utils.OutputText)
+	assert.EqualError(t, err, "--signature and --public-key go together")
+
+	err = service.Verify(&bytes.Buffer{}, manifest, VerifyOptions{Signature: signature, PublicKey: "AAAA"}, utils.OutputText)
+	assert.ErrorContains(t, err, "invalid public key")
+
+	other, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	var out bytes.Buffer
+	err = service.Verify(&out, manifest, VerifyOptions{Signature: signature, PublicKey: base64.StdEncoding.EncodeToString(other)}, utils.OutputText)
+	assert.EqualError(t, err, "manifest signature verification failed for "+manifest)
+	assert.Empty(t, out.String(), "no file is checked against an unverified manifest")
+
+	err = service.Verify(&bytes.Buffer{}, manifest+".missing", VerifyOptions{}, utils.OutputText)
+	assert.ErrorContains(t, err, "failed to read manifest")
+
+	err = service.Verify(&bytes.Buffer{}, manifest, VerifyOptions{}, utils.OutputFormat("xml"))
+	assert.Error(t, err)
+}
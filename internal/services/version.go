@@ -2,9 +2,17 @@
 package services
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io"
+	"os"
+	"time"
 
 	"github.com/kodflow/superviz.io/internal/providers"
+	"github.com/kodflow/superviz.io/internal/utils"
 )
 
 // VersionService handles version-related operations and formatting.
@@ -14,6 +22,26 @@ import (
 type VersionService struct {
 	// provider supplies version information data
 	provider providers.VersionProvider
+	// releases reports the published releases for update checks
+	releases ReleaseSource
+	// build reads the build information embedded by the Go toolchain
+	build func() (providers.BuildProvenance, bool)
+	// executable returns the path of the binary an SBOM describes
+	executable func() (string, error)
+	// now returns the SBOM creation time
+	now func() time.Time
+}
+
+// VersionServiceOptions contains options for creating a VersionService.
+type VersionServiceOptions struct {
+	// Provider supplies version information, nil for the build-time values
+	Provider providers.VersionProvider
+	// Releases reports published releases, nil for the public release index
+	Releases ReleaseSource
+	// Build overrides providers.ReadBuildProvenance
+	Build func() (providers.BuildProvenance, bool)
+	// Executable overrides the path of the binary hashed into SBOMs
+	Executable string
 }
 
 // NewVersionService creates a new version service with the given provider.
@@ -27,12 +55,42 @@ type VersionService struct {
 // Returns:
 //   - VersionService instance ready for use
 func NewVersionService(provider providers.VersionProvider) *VersionService {
-	if provider == nil {
-		provider = providers.DefaultVersionProvider() // Uses the singleton
+	return NewVersionServiceWithOptions(&VersionServiceOptions{Provider: provider})
+}
+
+// NewVersionServiceWithOptions creates a new version service with the given options.
+//
+// Parameters:
+//   - opts: *VersionServiceOptions overrides, nil for defaults
+//
+// Returns:
+//   - VersionService instance ready for use
+func NewVersionServiceWithOptions(opts *VersionServiceOptions) *VersionService {
+	if opts == nil {
+		opts = &VersionServiceOptions{}
+	}
+
+	s := &VersionService{
+		provider:   opts.Provider,
+		releases:   opts.Releases,
+		build:      opts.Build,
+		executable: os.Executable,
+		now:        time.Now,
+	}
+	if s.provider == nil {
+		s.provider = providers.DefaultVersionProvider() // Uses the singleton
 	}
-	return &VersionService{
-		provider: provider,
+	if s.releases == nil {
+		s.releases = NewSelfUpdateService(nil)
 	}
+	if s.build == nil {
+		s.build = providers.ReadBuildProvenance
+	}
+	if opts.Executable != "" {
+		path := opts.Executable
+		s.executable = func() (string, error) { return path, nil }
+	}
+	return s
 }
 
 // GetVersionInfo retrieves version information through the configured provider.
@@ -66,6 +124,27 @@ func (s *VersionService) DisplayVersion(w io.Writer) error {
 	return err
 }
 
+// DisplayVersionAs writes version information in the requested output format.
+//
+// DisplayVersionAs renders the text table for utils.OutputText and encodes
+// the VersionInfo structure for JSON and YAML so automation can parse it.
+//
+// Parameters:
+//   - w: Writer to output the version information
+//   - format: Output format (text, json or yaml)
+//
+// Returns:
+//   - Error if writing or encoding fails or writer is nil
+func (s *VersionService) DisplayVersionAs(w io.Writer, format utils.OutputFormat) error {
+	if format == utils.OutputText || format == "" {
+		return s.DisplayVersion(w)
+	}
+	if w == nil {
+		return ErrNilWriter
+	}
+	return utils.EncodeOutput(w, format, s.provider.GetVersionInfo())
+}
+
 // DisplayVersionString returns the formatted version as a string.
 //
 // DisplayVersionString provides the formatted version information as a string
@@ -76,3 +155,270 @@ func (s *VersionService) DisplayVersion(w io.Writer) error {
 func (s *VersionService) DisplayVersionString() string {
 	return s.provider.GetVersionInfo().Format()
 }
+
+// SemVer parses the version of the running CLI.
+//
+// Returns:
+//   - SemVer parsed CLI version
+//   - Error if the binary is a development build without a semantic version
+func (s *VersionService) SemVer() (providers.SemVer, error) {
+	return providers.ParseSemVer(s.provider.GetVersionInfo().Version)
+}
+
+// CompatibilityWindow returns the agent versions the running CLI supports.
+//
+// Returns:
+//   - CompatibilityWindow supported agent versions
+//   - Error if the binary is a development build, which supports any agent
+func (s *VersionService) CompatibilityWindow() (providers.CompatibilityWindow, error) {
+	cli, err := s.SemVer()
+	if err != nil {
+		return providers.CompatibilityWindow{}, err
+	}
+	return providers.CompatibilityWindowFor(cli), nil
+}
+
+// CheckAgentVersion verifies that an agent version can be managed by this CLI.
+//
+// Development builds of the CLI accept any agent version.
+//
+// Parameters:
+//   - agent: Agent package version, e.g. 1.3.0 or 1.3.0-1
+//
+// Returns:
+//   - Error wrapping ErrIncompatibleVersion if the version is malformed or outside the window
+func (s *VersionService) CheckAgentVersion(agent string) error {
+	window, err := s.CompatibilityWindow()
+	if err != nil {
+		return nil
+	}
+
+	v, err := providers.ParsePackageVersion(agent)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrIncompatibleVersion, err)
+	}
+	if !window.Contains(v) {
+		return fmt.Errorf("%w: agent %s is outside the %s range supported by svz %s",
+			ErrIncompatibleVersion, v, window, s.provider.GetVersionInfo().Version)
+	}
+	return nil
+}
+
+// VersionCheck reports whether a newer release than the running CLI exists.
+type VersionCheck struct {
+	// Current is the version of the running CLI
+	Current string `json:"current" yaml:"current"`
+	// Latest is the newest release of the channel
+	Latest string `json:"latest" yaml:"latest"`
+	// Channel is the release channel checked
+	Channel string `json:"channel" yaml:"channel"`
+	// UpdateAvailable is true when Latest is newer than Current
+	UpdateAvailable bool `json:"update_available" yaml:"update_available"`
+	// AgentCompatibility is the supported agent range, empty for development builds
+	AgentCompatibility string `json:"agent_compatibility,omitempty" yaml:"agent_compatibility,omitempty"`
+}
+
+// Format returns a human-readable summary of the check.
+//
+// Returns:
+//   - One or two lines ending with a newline
+func (c *VersionCheck) Format() string {
+	var out string
+	switch {
+	case c.UpdateAvailable:
+		out = fmt.Sprintf("svz %s is available on the %s channel (running %s), run svz self-update\n", c.Latest, c.Channel, c.Current)
+	case c.AgentCompatibility == "":
+		out = fmt.Sprintf("svz %s is a development build, the latest %s release is %s\n", c.Current, c.Channel, c.Latest)
+	default:
+		out = fmt.Sprintf("svz %s is up to date on the %s channel\n", c.Current, c.Channel)
+	}
+	if c.AgentCompatibility != "" {
+		out += "Supported agent versions: " + c.AgentCompatibility + "\n"
+	}
+	return out
+}
+
+// CheckForUpdate compares the running CLI with the latest release of a channel.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - channel: Release channel, empty for stable
+//
+// Returns:
+//   - VersionCheck describing the available release
+//   - Error if the latest release cannot be determined
+func (s *VersionService) CheckForUpdate(ctx context.Context, channel string) (*VersionCheck, error) {
+	if channel == "" {
+		channel = providers.ChannelStable
+	}
+	latest, err := s.releases.LatestRelease(ctx, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &VersionCheck{
+		Current: s.provider.GetVersionInfo().Version,
+		Latest:  latest,
+		Channel: channel,
+	}
+	current, err := s.SemVer()
+	if err != nil {
+		return check, nil
+	}
+	check.AgentCompatibility = providers.CompatibilityWindowFor(current).String()
+	if v, err := providers.ParseSemVer(latest); err == nil {
+		check.UpdateAvailable = v.Compare(current) > 0
+	}
+	return check, nil
+}
+
+// DisplayUpdateCheck writes the result of CheckForUpdate in the requested output format.
+//
+// Parameters:
+//   - ctx: context.Context for timeout and cancellation
+//   - w: Writer to output the check result
+//   - channel: Release channel, empty for stable
+//   - format: Output format (text, json or yaml)
+//
+// Returns:
+//   - Error if the check, writing or encoding fails or writer is nil
+func (s *VersionService) DisplayUpdateCheck(ctx context.Context, w io.Writer, channel string, format utils.OutputFormat) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+
+	check, err := s.CheckForUpdate(ctx, channel)
+	if err != nil {
+		return err
+	}
+	if format == utils.OutputText || format == "" {
+		_, err := io.WriteString(w, check.Format())
+		return err
+	}
+	return utils.EncodeOutput(w, format, check)
+}
+
+// VerboseVersionInfo is the version information extended with the build provenance.
+type VerboseVersionInfo struct {
+	providers.VersionInfo `yaml:",inline"`
+	// Build is the toolchain build information, nil if the binary carries none
+	Build *providers.BuildProvenance `json:"build,omitempty" yaml:"build,omitempty"`
+}
+
+// Format returns the version table followed by the build provenance.
+//
+// Returns:
+//   - Multi-line string ending with a newline
+func (v VerboseVersionInfo) Format() string {
+	if v.Build == nil {
+		return v.VersionInfo.Format() + "Build information unavailable\n"
+	}
+	return v.VersionInfo.Format() + v.Build.Format()
+}
+
+// GetVerboseVersionInfo returns the version information with the build provenance.
+//
+// Returns:
+//   - VerboseVersionInfo combining ldflags values and debug.ReadBuildInfo data
+func (s *VersionService) GetVerboseVersionInfo() VerboseVersionInfo {
+	info := VerboseVersionInfo{VersionInfo: s.provider.GetVersionInfo()}
+	if build, ok := s.build(); ok {
+		info.Build = &build
+	}
+	return info
+}
+
+// DisplayVerboseVersion writes the version information and build provenance.
+//
+// Parameters:
+//   - w: Writer to output the version information
+//   - format: Output format (text, json or yaml)
+//
+// Returns:
+//   - Error if writing or encoding fails or writer is nil
+func (s *VersionService) DisplayVerboseVersion(w io.Writer, format utils.OutputFormat) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+
+	info := s.GetVerboseVersionInfo()
+	if format == utils.OutputText || format == "" {
+		_, err := io.WriteString(w, info.Format())
+		return err
+	}
+	return utils.EncodeOutput(w, format, info)
+}
+
+// WriteSBOM writes a software bill of materials describing the running binary.
+//
+// WriteSBOM lists every linked module with its package URL and records the
+// SHA-256 digest of the executable so the document matches one exact binary.
+// The digest is omitted when the executable cannot be read.
+//
+// Parameters:
+//   - w: Writer receiving the JSON document
+//   - format: SBOM standard (CycloneDX or SPDX)
+//
+// Returns:
+//   - Error if the build information is unavailable, the format is unknown or writing fails
+func (s *VersionService) WriteSBOM(w io.Writer, format providers.SBOMFormat) error {
+	if w == nil {
+		return ErrNilWriter
+	}
+
+	build, ok := s.build()
+	if !ok {
+		return fmt.Errorf("cannot generate an SBOM: the binary carries no build information")
+	}
+	id, err := newUUID()
+	if err != nil {
+		return err
+	}
+	subject := providers.SBOMSubject{
+		Version: s.provider.GetVersionInfo(),
+		Build:   build,
+		SHA256:  s.executableDigest(),
+	}
+
+	var doc any
+	switch format {
+	case providers.SBOMCycloneDX:
+		doc = providers.NewCycloneDXBOM(subject, id, s.now())
+	case providers.SBOMSPDX:
+		doc = providers.NewSPDXDocument(subject, id, s.now())
+	default:
+		return fmt.Errorf("unsupported SBOM format %q", format)
+	}
+	return utils.EncodeOutput(w, utils.OutputJSON, doc)
+}
+
+// executableDigest returns the hex SHA-256 of the running binary, or "" if it cannot be read
+func (s *VersionService) executableDigest() string {
+	path, err := s.executable()
+	if err != nil {
+		return ""
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close() //nolint:errcheck
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// newUUID returns a random RFC 4122 version 4 UUID
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate document identifier: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+}
@@ -2,10 +2,19 @@ package services_test
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
 	"testing"
 
 	"github.com/kodflow/superviz.io/internal/providers"
 	"github.com/kodflow/superviz.io/internal/services"
+	"github.com/kodflow/superviz.io/internal/utils"
 	"github.com/stretchr/testify/require"
 )
 
@@ -95,6 +104,46 @@ func TestVersionService_DisplayVersion_NilWriter(t *testing.T) {
 	require.Equal(t, services.ErrNilWriter, err)
 }
 
+func TestVersionService_DisplayVersionAs_JSON(t *testing.T) {
+
+	service := services.NewVersionService(newMockProvider())
+
+	var buf bytes.Buffer
+	err := service.DisplayVersionAs(&buf, utils.OutputJSON)
+
+	require.NoError(t, err)
+
+	var decoded map[string]string
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Equal(t, "test-version", decoded["version"])
+	require.Equal(t, "test-commit", decoded["commit"])
+	require.Equal(t, "linux/amd64", decoded["os_arch"])
+}
+
+func TestVersionService_DisplayVersionAs_YAML(t *testing.T) {
+
+	service := services.NewVersionService(newMockProvider())
+
+	var buf bytes.Buffer
+	err := service.DisplayVersionAs(&buf, utils.OutputYAML)
+
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "version: test-version\n")
+	require.Contains(t, buf.String(), "go_version: go1.21.0\n")
+}
+
+func TestVersionService_DisplayVersionAs_Text(t *testing.T) {
+
+	service := services.NewVersionService(newMockProvider())
+
+	var buf bytes.Buffer
+	err := service.DisplayVersionAs(&buf, utils.OutputText)
+
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "Version:")
+	require.ErrorIs(t, service.DisplayVersionAs(nil, utils.OutputJSON), services.ErrNilWriter)
+}
+
 func TestVersionService_DisplayVersionString(t *testing.T) {
 
 	mockProvider := newMockProvider()
@@ -122,3 +171,198 @@ func TestVersionService_Performance(t *testing.T) {
 		require.NotEmpty(t, output)
 	}
 }
+
+// fakeReleaseSource returns fixed channel versions.
+type fakeReleaseSource map[string]string
+
+func (f fakeReleaseSource) LatestRelease(_ context.Context, channel string) (string, error) {
+	version, ok := f[channel]
+	if !ok {
+		return "", errors.New("unknown release channel")
+	}
+	return version, nil
+}
+
+func newReleasedVersionService(version string) *services.VersionService {
+	provider := newMockProvider()
+	provider.info.Version = version
+	return services.NewVersionServiceWithOptions(&services.VersionServiceOptions{
+		Provider: provider,
+		Releases: fakeReleaseSource{"stable": "1.4.0", "beta": "1.5.0-rc.1"},
+	})
+}
+
+func TestVersionService_SemVer(t *testing.T) {
+	v, err := newReleasedVersionService("v1.3.2").SemVer()
+	require.NoError(t, err)
+	require.Equal(t, "1.3.2", v.String())
+
+	_, err = newReleasedVersionService("dev").SemVer()
+	require.Error(t, err)
+}
+
+func TestVersionService_CheckAgentVersion(t *testing.T) {
+	service := newReleasedVersionService("1.3.2")
+
+	window, err := service.CompatibilityWindow()
+	require.NoError(t, err)
+	require.Equal(t, "1.2.x to 1.4.x", window.String())
+
+	for _, agent := range []string{"1.2.0", "1.3.0-1", "1:1.4.9-r0", "1.4.0~rc.1-1"} {
+		require.NoError(t, service.CheckAgentVersion(agent), agent)
+	}
+	for _, agent := range []string{"1.1.9", "1.5.0", "2.3.0", "latest"} {
+		require.ErrorIs(t, service.CheckAgentVersion(agent), services.ErrIncompatibleVersion, agent)
+	}
+	require.ErrorContains(t, service.CheckAgentVersion("1.5.0-1"), "agent 1.5.0 is outside the 1.2.x to 1.4.x range supported by svz 1.3.2")
+
+	dev := newReleasedVersionService("dev")
+	_, err = dev.CompatibilityWindow()
+	require.Error(t, err)
+	require.NoError(t, dev.CheckAgentVersion("0.1.0"), "development builds accept any agent")
+}
+
+func TestVersionService_CheckForUpdate(t *testing.T) {
+	ctx := context.Background()
+
+	check, err := newReleasedVersionService("1.3.2").CheckForUpdate(ctx, "")
+	require.NoError(t, err)
+	require.Equal(t, &services.VersionCheck{
+		Current:            "1.3.2",
+		Latest:             "1.4.0",
+		Channel:            "stable",
+		UpdateAvailable:    true,
+		AgentCompatibility: "1.2.x to 1.4.x",
+	}, check)
+	require.Equal(t, "svz 1.4.0 is available on the stable channel (running 1.3.2), run svz self-update\nSupported agent versions: 1.2.x to 1.4.x\n", check.Format())
+
+	check, err = newReleasedVersionService("1.5.0-rc.1").CheckForUpdate(ctx, "stable")
+	require.NoError(t, err)
+	require.False(t, check.UpdateAvailable)
+	require.Contains(t, check.Format(), "is up to date on the stable channel")
+
+	check, err = newReleasedVersionService("1.5.0-beta.3").CheckForUpdate(ctx, "beta")
+	require.NoError(t, err)
+	require.True(t, check.UpdateAvailable, "rc.1 is newer than beta.3")
+
+	check, err = newReleasedVersionService("dev").CheckForUpdate(ctx, "stable")
+	require.NoError(t, err)
+	require.False(t, check.UpdateAvailable)
+	require.Empty(t, check.AgentCompatibility)
+	require.Equal(t, "svz dev is a development build, the latest stable release is 1.4.0\n", check.Format())
+
+	_, err = newReleasedVersionService("1.3.2").CheckForUpdate(ctx, "nightly")
+	require.Error(t, err)
+}
+
+func TestVersionService_DisplayUpdateCheck(t *testing.T) {
+	service := newReleasedVersionService("1.4.0")
+
+	var buf bytes.Buffer
+	require.NoError(t, service.DisplayUpdateCheck(context.Background(), &buf, "stable", utils.OutputJSON))
+	var check services.VersionCheck
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &check))
+	require.Equal(t, "1.4.0", check.Latest)
+	require.False(t, check.UpdateAvailable)
+
+	buf.Reset()
+	require.NoError(t, service.DisplayUpdateCheck(context.Background(), &buf, "stable", utils.OutputText))
+	require.Contains(t, buf.String(), "svz 1.4.0 is up to date")
+
+	require.ErrorIs(t, service.DisplayUpdateCheck(context.Background(), nil, "stable", utils.OutputText), services.ErrNilWriter)
+}
+
+func testBuildProvenance() (providers.BuildProvenance, bool) {
+	return providers.BuildProvenance{
+		GoVersion:    "go1.24.3",
+		Main:         providers.Module{Path: "github.com/kodflow/superviz.io", Version: "(devel)"},
+		Dependencies: []providers.Module{{Path: "github.com/spf13/cobra", Version: "v1.9.1", Sum: "h1:cobra="}},
+		VCS:          providers.VCSInfo{System: "git", Revision: "1a2b3c4", Modified: true},
+		Tags:         []string{"netgo"},
+		GOOS:         "linux",
+		GOARCH:       "amd64",
+		GOAMD64:      "v1",
+	}, true
+}
+
+func newBuildVersionService(t *testing.T) (*services.VersionService, string) {
+	t.Helper()
+	exe := filepath.Join(t.TempDir(), "svz")
+	require.NoError(t, os.WriteFile(exe, []byte("svz binary"), 0o755))
+	sum := sha256.Sum256([]byte("svz binary"))
+
+	return services.NewVersionServiceWithOptions(&services.VersionServiceOptions{
+		Provider:   newMockProvider(),
+		Build:      testBuildProvenance,
+		Executable: exe,
+	}), hex.EncodeToString(sum[:])
+}
+
+func TestVersionService_DisplayVerboseVersion_Text(t *testing.T) {
+	service, _ := newBuildVersionService(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, service.DisplayVerboseVersion(&buf, utils.OutputText))
+
+	output := buf.String()
+	require.Contains(t, output, "Version:       test-version\n")
+	require.Contains(t, output, "VCS:           git 1a2b3c4 (modified)\n")
+	require.Contains(t, output, "Build tags:    netgo\n")
+	require.Contains(t, output, "GOAMD64:       v1\n")
+	require.Contains(t, output, "  github.com/spf13/cobra v1.9.1\n")
+}
+
+func TestVersionService_DisplayVerboseVersion_JSON(t *testing.T) {
+	service, _ := newBuildVersionService(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, service.DisplayVerboseVersion(&buf, utils.OutputJSON))
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Equal(t, "test-version", decoded["version"], "version fields stay at the top level")
+	build := decoded["build"].(map[string]any)
+	require.Equal(t, true, build["vcs"].(map[string]any)["modified"])
+	require.Equal(t, false, build["cgo_enabled"])
+	require.Len(t, build["dependencies"], 1)
+
+	require.ErrorIs(t, service.DisplayVerboseVersion(nil, utils.OutputText), services.ErrNilWriter)
+}
+
+func TestVersionService_DisplayVerboseVersion_NoBuildInfo(t *testing.T) {
+	service := services.NewVersionServiceWithOptions(&services.VersionServiceOptions{
+		Provider: newMockProvider(),
+		Build:    func() (providers.BuildProvenance, bool) { return providers.BuildProvenance{}, false },
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, service.DisplayVerboseVersion(&buf, utils.OutputText))
+	require.Contains(t, buf.String(), "Build information unavailable\n")
+
+	require.ErrorContains(t, service.WriteSBOM(&buf, providers.SBOMCycloneDX), "carries no build information")
+}
+
+func TestVersionService_WriteSBOM(t *testing.T) {
+	service, digest := newBuildVersionService(t)
+	uuid := regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	var buf bytes.Buffer
+	require.NoError(t, service.WriteSBOM(&buf, providers.SBOMCycloneDX))
+	var bom providers.CycloneDXBOM
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &bom))
+	require.Equal(t, "CycloneDX", bom.BOMFormat)
+	require.Regexp(t, uuid, bom.SerialNumber)
+	require.Equal(t, []providers.CycloneDXHash{{Alg: "SHA-256", Content: digest}}, bom.Metadata.Component.Hashes)
+	require.Equal(t, "pkg:golang/github.com/spf13/cobra@v1.9.1", bom.Components[0].PURL)
+
+	buf.Reset()
+	require.NoError(t, service.WriteSBOM(&buf, providers.SBOMSPDX))
+	var doc providers.SPDXDocument
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	require.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	require.Regexp(t, uuid, doc.DocumentNamespace)
+	require.Equal(t, digest, doc.Packages[0].Checksums[0].ChecksumValue)
+
+	require.Error(t, service.WriteSBOM(&buf, "swid"))
+	require.ErrorIs(t, service.WriteSBOM(nil, providers.SBOMSPDX), services.ErrNilWriter)
+}
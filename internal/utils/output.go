@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// OutputFormat identifies how commands render their results.
+type OutputFormat string
+
+// Supported output formats.
+const (
+	// OutputText renders human-readable text
+	OutputText OutputFormat = "text"
+	// OutputJSON renders JSON (newline-delimited for event streams)
+	OutputJSON OutputFormat = "json"
+	// OutputYAML renders YAML (one document per event for event streams)
+	OutputYAML OutputFormat = "yaml"
+)
+
+// OutputFlag is the name of the global output format flag.
+const OutputFlag = "output"
+
+// ParseOutputFormat validates and converts a flag value to an OutputFormat.
+//
+// Example:
+//
+//	format, err := ParseOutputFormat("json")
+//	// format == OutputJSON, err == nil
+//
+// Parameters:
+//   - value: string raw flag value
+//
+// Returns:
+//   - format: OutputFormat parsed format
+//   - err: error if the value is not text, json or yaml
+func ParseOutputFormat(value string) (OutputFormat, error) {
+	switch OutputFormat(value) {
+	case OutputText, OutputJSON, OutputYAML:
+		return OutputFormat(value), nil
+	default:
+		return "", fmt.Errorf("invalid output format %q, expected text, json or yaml", value)
+	}
+}
+
+// OutputFormatFromCommand returns the output format selected for cmd.
+//
+// OutputFormatFromCommand reads the persistent --output flag inherited from
+// the root command and falls back to text when the command is used standalone.
+//
+// Parameters:
+//   - cmd: *cobra.Command command being executed
+//
+// Returns:
+//   - format: OutputFormat selected format
+//   - err: error if the flag value is invalid
+func OutputFormatFromCommand(cmd *cobra.Command) (OutputFormat, error) {
+	flag := cmd.Flag(OutputFlag)
+	if flag == nil {
+		return OutputText, nil
+	}
+	return ParseOutputFormat(flag.Value.String())
+}
+
+// Encoder encodes successive values to an underlying stream.
+type Encoder interface {
+	// Encode writes v to the stream.
+	Encode(v any) error
+}
+
+// NewEncoder returns a stream encoder for a structured format.
+//
+// JSON encoders write one compact object per line (NDJSON); YAML encoders
+// write one document per value separated by "---".
+//
+// Parameters:
+//   - w: io.Writer destination stream
+//   - format: OutputFormat OutputJSON or OutputYAML
+//
+// Returns:
+//   - enc: Encoder for the format
+//   - err: error if the format is not structured
+func NewEncoder(w io.Writer, format OutputFormat) (Encoder, error) {
+	switch format {
+	case OutputJSON:
+		return json.NewEncoder(w), nil
+	case OutputYAML:
+		return yaml.NewEncoder(w), nil
+	default:
+		return nil, fmt.Errorf("output format %q is not a structured format", format)
+	}
+}
+
+// EncodeOutput writes a single value in the requested structured format.
+//
+// Example:
+//
+//	err := EncodeOutput(os.Stdout, OutputJSON, info)
+//
+// Parameters:
+//   - w: io.Writer destination
+//   - format: OutputFormat OutputJSON (indented) or OutputYAML
+//   - v: any value to encode
+//
+// Returns:
+//   - err: error if the format is not structured or encoding fails
+func EncodeOutput(w io.Writer, format OutputFormat, v any) error {
+	switch format {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("failed to encode JSON output: %w", err)
+		}
+		return nil
+	case OutputYAML:
+		enc := yaml.NewEncoder(w)
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("failed to encode YAML output: %w", err)
+		}
+		if err := enc.Close(); err != nil {
+			return fmt.Errorf("failed to encode YAML output: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("output format %q is not a structured format", format)
+	}
+}
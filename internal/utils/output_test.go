@@ -0,0 +1,96 @@
+package utils_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/kodflow/superviz.io/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOutputFormat(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected utils.OutputFormat
+		wantErr  bool
+	}{
+		{value: "text", expected: utils.OutputText},
+		{value: "json", expected: utils.OutputJSON},
+		{value: "yaml", expected: utils.OutputYAML},
+		{value: "xml", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			format, err := utils.ParseOutputFormat(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, format)
+		})
+	}
+}
+
+func TestOutputFormatFromCommand(t *testing.T) {
+	standalone := &cobra.Command{Use: "standalone"}
+	format, err := utils.OutputFormatFromCommand(standalone)
+	require.NoError(t, err)
+	assert.Equal(t, utils.OutputText, format)
+
+	root := &cobra.Command{Use: "root"}
+	root.PersistentFlags().String(utils.OutputFlag, "text", "")
+	child := &cobra.Command{Use: "child"}
+	root.AddCommand(child)
+
+	require.NoError(t, root.PersistentFlags().Set(utils.OutputFlag, "yaml"))
+	format, err = utils.OutputFormatFromCommand(child)
+	require.NoError(t, err)
+	assert.Equal(t, utils.OutputYAML, format)
+
+	require.NoError(t, root.PersistentFlags().Set(utils.OutputFlag, "xml"))
+	_, err = utils.OutputFormatFromCommand(child)
+	assert.Error(t, err)
+}
+
+func TestNewEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc, err := utils.NewEncoder(&buf, utils.OutputJSON)
+	require.NoError(t, err)
+	require.NoError(t, enc.Encode(map[string]int{"a": 1}))
+	require.NoError(t, enc.Encode(map[string]int{"b": 2}))
+	assert.Equal(t, "{\"a\":1}\n{\"b\":2}\n", buf.String())
+
+	buf.Reset()
+	enc, err = utils.NewEncoder(&buf, utils.OutputYAML)
+	require.NoError(t, err)
+	require.NoError(t, enc.Encode(map[string]int{"a": 1}))
+	require.NoError(t, enc.Encode(map[string]int{"b": 2}))
+	assert.Equal(t, "a: 1\n---\nb: 2\n", buf.String())
+
+	_, err = utils.NewEncoder(&buf, utils.OutputText)
+	assert.Error(t, err)
+}
+
+func TestEncodeOutput(t *testing.T) {
+	value := struct {
+		Name string `json:"name" yaml:"name"`
+	}{Name: "svz"}
+
+	var buf bytes.Buffer
+	require.NoError(t, utils.EncodeOutput(&buf, utils.OutputJSON, value))
+	var decoded map[string]string
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "svz", decoded["name"])
+
+	buf.Reset()
+	require.NoError(t, utils.EncodeOutput(&buf, utils.OutputYAML, value))
+	assert.Equal(t, "name: svz\n", buf.String())
+
+	assert.Error(t, utils.EncodeOutput(&buf, utils.OutputText, value))
+}